@@ -0,0 +1,162 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package asm provides an assembler and a disassembler for EVM byte code. It
+// is intended to be used for writing readable test code and for inspecting
+// contracts in tools, not for developing production contracts.
+//
+// The assembly language accepted by Assemble lists one instruction per line.
+// Instructions are given by their op-code names, followed by an argument for
+// PUSH instructions. Arguments may be hex numbers (0x2a), decimal numbers
+// (42), or references to labels (@loop), resolving to the position of the
+// label in the code. Labels are defined by a name followed by a colon, either
+// on a line of its own or preceding an instruction. Comments start with a
+// semicolon and extend to the end of the line. For example:
+//
+//	loop: JUMPDEST
+//	      PUSH1 @loop ; jump back to the start
+//	      JUMP
+package asm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// Assemble translates the given assembly source into EVM byte code.
+func Assemble(source string) ([]byte, error) {
+	return AssembleLines(strings.Split(source, "\n")...)
+}
+
+// MustAssemble is like Assemble but panics on errors. It is intended to be
+// used for initializing test data.
+func MustAssemble(source string) []byte {
+	code, err := Assemble(source)
+	if err != nil {
+		panic(err)
+	}
+	return code
+}
+
+// AssembleLines translates the given assembly lines into EVM byte code.
+func AssembleLines(lines ...string) ([]byte, error) {
+	// The first pass parses instructions and determines label positions.
+	var instructions []instruction
+	labels := map[string]int{}
+	pos := 0
+	for i, line := range lines {
+		if comment := strings.IndexByte(line, ';'); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		for len(fields) > 0 && strings.HasSuffix(fields[0], ":") {
+			label := strings.TrimSuffix(fields[0], ":")
+			if label == "" {
+				return nil, fmt.Errorf("line %d: empty label", i+1)
+			}
+			if _, found := labels[label]; found {
+				return nil, fmt.Errorf("line %d: duplicate label %q", i+1, label)
+			}
+			labels[label] = pos
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		op, found := opCodesByName[strings.ToUpper(fields[0])]
+		if !found {
+			return nil, fmt.Errorf("line %d: unknown instruction %q", i+1, fields[0])
+		}
+		width := op.Width() - 1
+		switch {
+		case width == 0 && len(fields) > 1:
+			return nil, fmt.Errorf("line %d: unexpected argument for %v", i+1, op)
+		case width > 0 && len(fields) == 1:
+			return nil, fmt.Errorf("line %d: missing argument for %v", i+1, op)
+		case len(fields) > 2:
+			return nil, fmt.Errorf("line %d: too many arguments for %v", i+1, op)
+		}
+		instruction := instruction{line: i + 1, op: op}
+		if width > 0 {
+			instruction.argument = fields[1]
+		}
+		instructions = append(instructions, instruction)
+		pos += op.Width()
+	}
+
+	// The second pass produces the code, resolving label references.
+	code := make([]byte, 0, pos)
+	for _, instruction := range instructions {
+		code = append(code, byte(instruction.op))
+		width := instruction.op.Width() - 1
+		if width == 0 {
+			continue
+		}
+		value, err := parseArgument(instruction.argument, labels)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", instruction.line, err)
+		}
+		data := value.Bytes()
+		if len(data) > width {
+			return nil, fmt.Errorf("line %d: argument %s exceeds %d bytes", instruction.line, instruction.argument, width)
+		}
+		code = append(code, make([]byte, width-len(data))...)
+		code = append(code, data...)
+	}
+	return code, nil
+}
+
+// instruction is a parsed line of assembly code.
+type instruction struct {
+	line     int
+	op       vm.OpCode
+	argument string
+}
+
+func parseArgument(argument string, labels map[string]int) (*big.Int, error) {
+	if label, isLabel := strings.CutPrefix(argument, "@"); isLabel {
+		pos, found := labels[label]
+		if !found {
+			return nil, fmt.Errorf("undefined label %q", label)
+		}
+		return big.NewInt(int64(pos)), nil
+	}
+	if digits, isHex := strings.CutPrefix(argument, "0x"); isHex {
+		if len(digits)%2 != 0 {
+			digits = "0" + digits
+		}
+		data, err := hex.DecodeString(digits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex argument %s", argument)
+		}
+		return new(big.Int).SetBytes(data), nil
+	}
+	value, ok := new(big.Int).SetString(argument, 10)
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid argument %s", argument)
+	}
+	return value, nil
+}
+
+var opCodesByName = func() map[string]vm.OpCode {
+	res := map[string]vm.OpCode{}
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		if name := op.String(); !strings.HasPrefix(name, "op(") {
+			res[name] = op
+		}
+	}
+	return res
+}()
@@ -0,0 +1,127 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package asm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestAssemble_ProducesByteCode(t *testing.T) {
+	tests := map[string]struct {
+		source string
+		want   []byte
+	}{
+		"empty": {
+			source: "",
+			want:   []byte{},
+		},
+		"simple instructions": {
+			source: "ADD\nstop",
+			want:   []byte{byte(vm.ADD), byte(vm.STOP)},
+		},
+		"hex argument": {
+			source: "PUSH2 0x123",
+			want:   []byte{byte(vm.PUSH2), 0x01, 0x23},
+		},
+		"decimal argument": {
+			source: "PUSH1 42",
+			want:   []byte{byte(vm.PUSH1), 42},
+		},
+		"padded argument": {
+			source: "PUSH4 0x01",
+			want:   []byte{byte(vm.PUSH4), 0, 0, 0, 1},
+		},
+		"comments and blank lines": {
+			source: "; a comment\n\n  ADD ; another comment\n",
+			want:   []byte{byte(vm.ADD)},
+		},
+		"backward label": {
+			source: "PUSH1 0\nloop: JUMPDEST\nPUSH1 @loop\nJUMP",
+			want: []byte{
+				byte(vm.PUSH1), 0,
+				byte(vm.JUMPDEST),
+				byte(vm.PUSH1), 2,
+				byte(vm.JUMP),
+			},
+		},
+		"forward label": {
+			source: "PUSH2 @end\nJUMP\nINVALID\nend:\nJUMPDEST",
+			want: []byte{
+				byte(vm.PUSH2), 0, 5,
+				byte(vm.JUMP),
+				byte(vm.INVALID),
+				byte(vm.JUMPDEST),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Assemble(test.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(test.want, got) {
+				t.Errorf("unexpected code, wanted %x, got %x", test.want, got)
+			}
+		})
+	}
+}
+
+func TestAssemble_DetectsErrors(t *testing.T) {
+	tests := map[string]struct {
+		source string
+		issue  string
+	}{
+		"unknown instruction": {"FOO", "unknown instruction"},
+		"missing argument":    {"PUSH1", "missing argument"},
+		"unexpected argument": {"ADD 1", "unexpected argument"},
+		"too many arguments":  {"PUSH1 1 2", "too many arguments"},
+		"invalid hex":         {"PUSH1 0xzz", "invalid hex"},
+		"invalid decimal":     {"PUSH1 -1", "invalid argument"},
+		"argument too large":  {"PUSH1 256", "exceeds 1 bytes"},
+		"undefined label":     {"PUSH1 @foo", "undefined label"},
+		"duplicate label":     {"foo:\nfoo:", "duplicate label"},
+		"empty label":         {": ADD", "empty label"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Assemble(test.source)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.issue) {
+				t.Errorf("unexpected error, wanted %q, got %v", test.issue, err)
+			}
+		})
+	}
+}
+
+func TestAssemble_ErrorsReportLineNumbers(t *testing.T) {
+	_, err := Assemble("ADD\nADD\nFOO")
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3:") {
+		t.Errorf("unexpected error, got %v", err)
+	}
+}
+
+func TestMustAssemble_PanicsOnError(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic")
+		}
+	}()
+	MustAssemble("FOO")
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package asm
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// ControlFlowGraph is the control flow graph of EVM code, consisting of the
+// basic blocks of the code and the possible transitions between them. It is
+// intended to be used for visualizing contracts and as input for code
+// optimizations, like the combination of instructions into super-
+// instructions.
+type ControlFlowGraph struct {
+	Blocks []Block // the basic blocks of the code, ordered by position
+}
+
+// Block is a basic block of EVM code, a sequence of instructions which is
+// only entered at its first instruction and only left after its last one.
+type Block struct {
+	Start        int           // the position of the first instruction
+	End          int           // the position following the last instruction
+	Instructions []Instruction // the instructions of the block
+	// Successors lists the start positions of the blocks which may be
+	// executed after this block, reached by a jump or by falling through.
+	Successors []int
+	// DynamicJump is true if the block ends with a jump whose target could
+	// not be resolved statically. Any jump destination of the code may thus
+	// be a successor in addition to the listed ones.
+	DynamicJump bool
+}
+
+// NewControlFlowGraph builds the control flow graph of the given code based
+// on the basic blocks and jump targets determined by Disassemble.
+func NewControlFlowGraph(code []byte) *ControlFlowGraph {
+	instructions := Disassemble(code)
+	graph := &ControlFlowGraph{}
+	for _, instruction := range instructions {
+		if instruction.BlockStart {
+			graph.Blocks = append(graph.Blocks, Block{Start: instruction.Pc})
+		}
+		block := &graph.Blocks[len(graph.Blocks)-1]
+		block.Instructions = append(block.Instructions, instruction)
+		block.End = min(instruction.Pc+instruction.OpCode.Width(), len(code))
+	}
+
+	for i := range graph.Blocks {
+		block := &graph.Blocks[i]
+		last := block.Instructions[len(block.Instructions)-1]
+		if last.Target != nil {
+			block.Successors = append(block.Successors, *last.Target)
+		}
+		isJump := last.OpCode == vm.JUMP || last.OpCode == vm.JUMPI
+		if isJump && last.Target == nil {
+			block.DynamicJump = true
+		}
+		if !endsBlock(last.OpCode) || last.OpCode == vm.JUMPI {
+			if i+1 < len(graph.Blocks) && !slices.Contains(block.Successors, graph.Blocks[i+1].Start) {
+				block.Successors = append(block.Successors, graph.Blocks[i+1].Start)
+			}
+		}
+	}
+	return graph
+}
+
+// Block returns the block starting at the given position, or nil if there is
+// no such block.
+func (g *ControlFlowGraph) Block(start int) *Block {
+	for i := range g.Blocks {
+		if g.Blocks[i].Start == start {
+			return &g.Blocks[i]
+		}
+	}
+	return nil
+}
+
+// Dot produces a representation of the graph in the DOT language of
+// Graphviz. Blocks ending with unresolved jumps are connected to a node
+// representing all jump destinations.
+func (g *ControlFlowGraph) Dot() string {
+	var builder strings.Builder
+	builder.WriteString("digraph cfg {\n")
+	builder.WriteString("\tnode [shape=box, fontname=monospace];\n")
+	dynamic := false
+	for _, block := range g.Blocks {
+		var label strings.Builder
+		for _, instruction := range block.Instructions {
+			fmt.Fprintf(&label, "0x%04x: %v\\l", instruction.Pc, instruction)
+		}
+		fmt.Fprintf(&builder, "\tb%04x [label=\"%s\"];\n", block.Start, label.String())
+		for _, successor := range block.Successors {
+			fmt.Fprintf(&builder, "\tb%04x -> b%04x;\n", block.Start, successor)
+		}
+		if block.DynamicJump {
+			fmt.Fprintf(&builder, "\tb%04x -> dynamic [style=dashed];\n", block.Start)
+			dynamic = true
+		}
+	}
+	if dynamic {
+		builder.WriteString("\tdynamic [shape=diamond, label=\"?\"];\n")
+	}
+	builder.WriteString("}\n")
+	return builder.String()
+}
+
+// MarshalJSON encodes the graph as a list of blocks, listing instructions in
+// their assembly representation.
+func (g *ControlFlowGraph) MarshalJSON() ([]byte, error) {
+	type jsonBlock struct {
+		Start        int      `json:"start"`
+		End          int      `json:"end"`
+		Instructions []string `json:"instructions"`
+		Successors   []int    `json:"successors"`
+		DynamicJump  bool     `json:"dynamicJump,omitempty"`
+	}
+	blocks := make([]jsonBlock, 0, len(g.Blocks))
+	for _, block := range g.Blocks {
+		instructions := make([]string, 0, len(block.Instructions))
+		for _, instruction := range block.Instructions {
+			instructions = append(instructions, instruction.String())
+		}
+		successors := block.Successors
+		if successors == nil {
+			successors = []int{}
+		}
+		blocks = append(blocks, jsonBlock{
+			Start:        block.Start,
+			End:          block.End,
+			Instructions: instructions,
+			Successors:   successors,
+			DynamicJump:  block.DynamicJump,
+		})
+	}
+	return json.Marshal(struct {
+		Blocks []jsonBlock `json:"blocks"`
+	}{blocks})
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package asm
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestControlFlowGraph_ConnectsBlocks(t *testing.T) {
+	code := MustAssemble(`
+		      CALLER
+		      PUSH1 @else
+		      JUMPI
+		      PUSH1 @end
+		      JUMP
+		else: JUMPDEST
+		      POP
+		end:  JUMPDEST
+		      CALLVALUE
+		      JUMP
+	`)
+	graph := NewControlFlowGraph(code)
+
+	type block struct {
+		start, end  int
+		successors  []int
+		dynamicJump bool
+	}
+	want := []block{
+		{start: 0, end: 4, successors: []int{7, 4}},
+		{start: 4, end: 7, successors: []int{9}},
+		{start: 7, end: 9, successors: []int{9}},
+		{start: 9, end: 12, dynamicJump: true},
+	}
+	var got []block
+	for _, b := range graph.Blocks {
+		got = append(got, block{b.Start, b.End, b.Successors, b.DynamicJump})
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected blocks, wanted %v, got %v", want, got)
+	}
+	if block := graph.Block(7); block == nil || len(block.Instructions) != 2 {
+		t.Errorf("unexpected block at 7: %v", block)
+	}
+	if block := graph.Block(8); block != nil {
+		t.Errorf("unexpected block at 8: %v", block)
+	}
+}
+
+func TestControlFlowGraph_TerminalInstructionsHaveNoSuccessors(t *testing.T) {
+	for _, terminal := range []string{"STOP", "RETURN", "REVERT", "INVALID", "SELFDESTRUCT", "JUMP"} {
+		graph := NewControlFlowGraph(MustAssemble(terminal + "\nJUMPDEST"))
+		if len(graph.Blocks) != 2 {
+			t.Fatalf("unexpected number of blocks for %s: %d", terminal, len(graph.Blocks))
+		}
+		if successors := graph.Blocks[0].Successors; len(successors) != 0 {
+			t.Errorf("unexpected successors of %s: %v", terminal, successors)
+		}
+	}
+}
+
+func TestControlFlowGraph_EmptyCodeHasNoBlocks(t *testing.T) {
+	if blocks := NewControlFlowGraph(nil).Blocks; len(blocks) != 0 {
+		t.Errorf("unexpected blocks: %v", blocks)
+	}
+}
+
+func TestControlFlowGraph_Dot(t *testing.T) {
+	graph := NewControlFlowGraph(MustAssemble("PUSH1 @end\nJUMP\nend: JUMPDEST\nCALLER\nJUMP"))
+	want := strings.Join([]string{
+		"digraph cfg {",
+		"\tnode [shape=box, fontname=monospace];",
+		"\tb0000 [label=\"0x0000: PUSH1 0x03\\l0x0002: JUMP\\l\"];",
+		"\tb0000 -> b0003;",
+		"\tb0003 [label=\"0x0003: JUMPDEST\\l0x0004: CALLER\\l0x0005: JUMP\\l\"];",
+		"\tb0003 -> dynamic [style=dashed];",
+		"\tdynamic [shape=diamond, label=\"?\"];",
+		"}",
+		"",
+	}, "\n")
+	if got := graph.Dot(); want != got {
+		t.Errorf("unexpected DOT output, wanted\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestControlFlowGraph_MarshalJSON(t *testing.T) {
+	graph := NewControlFlowGraph(MustAssemble("PUSH1 @end\nJUMPI\nend: JUMPDEST\nSTOP"))
+	got, err := json.Marshal(graph)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `{"blocks":[` +
+		`{"start":0,"end":3,"instructions":["PUSH1 0x03","JUMPI"],"successors":[3]},` +
+		`{"start":3,"end":5,"instructions":["JUMPDEST","STOP"],"successors":[]}]}`
+	if string(got) != want {
+		t.Errorf("unexpected JSON, wanted\n%s\ngot\n%s", want, got)
+	}
+}
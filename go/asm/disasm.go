@@ -0,0 +1,179 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package asm
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// Instruction is a decoded instruction of EVM byte code.
+type Instruction struct {
+	Pc         int       // the position of the instruction in the code
+	OpCode     vm.OpCode // the op-code of the instruction
+	Data       []byte    // the immediate data of PUSH instructions
+	Truncated  bool      // true if the code ends within the immediate data
+	BlockStart bool      // true if the instruction starts a basic block
+	// Target is the destination of JUMP and JUMPI instructions if it could
+	// be resolved statically and is a valid jump destination. It is nil for
+	// all other instructions.
+	Target *int
+}
+
+// Disassemble decodes the given code into a list of instructions. Immediate
+// data of PUSH instructions is consumed by the respective instruction. If the
+// code ends within the data of a PUSH instruction, the data is shortened and
+// the instruction is marked as truncated.
+//
+// Instructions are annotated with the results of a static analysis of the
+// code. Basic blocks start at the beginning of the code, at jump
+// destinations, and after instructions ending the sequential execution of
+// the code, like jumps, terminal, and invalid instructions. Jump targets
+// are resolved by tracking constants on the stack symbolically within basic
+// blocks, covering targets pushed by a block and moved around using stack
+// manipulating instructions before the jump.
+func Disassemble(code []byte) []Instruction {
+	var res []Instruction
+	jumpDests := map[int]bool{}
+	for pc := 0; pc < len(code); {
+		op := vm.OpCode(code[pc])
+		instruction := Instruction{Pc: pc, OpCode: op}
+		if width := op.Width() - 1; width > 0 {
+			end := pc + 1 + width
+			if end > len(code) {
+				end = len(code)
+				instruction.Truncated = true
+			}
+			instruction.Data = code[pc+1 : end]
+		}
+		if op == vm.JUMPDEST {
+			jumpDests[pc] = true
+		}
+		res = append(res, instruction)
+		pc += op.Width()
+	}
+
+	var stack symbolicStack
+	for i := range res {
+		instruction := &res[i]
+		instruction.BlockStart = i == 0 || instruction.OpCode == vm.JUMPDEST || endsBlock(res[i-1].OpCode)
+		if instruction.BlockStart {
+			stack = stack[:0]
+		}
+		if op := instruction.OpCode; op == vm.JUMP || op == vm.JUMPI {
+			if target := stack.get(0); target != nil && target.IsInt64() && jumpDests[int(target.Int64())] {
+				pos := int(target.Int64())
+				instruction.Target = &pos
+			}
+		}
+		stack.apply(instruction)
+	}
+	return res
+}
+
+// symbolicStack tracks the values on the stack within a basic block. Values
+// are nil if they are not statically known. Elements below the bottom of the
+// slice are produced by previous blocks and are thus unknown.
+type symbolicStack []*big.Int
+
+// get returns the n-th element from the top of the stack, starting with 0.
+func (s symbolicStack) get(n int) *big.Int {
+	if n >= len(s) {
+		return nil
+	}
+	return s[len(s)-1-n]
+}
+
+// apply updates the stack according to the effect of the given instruction.
+func (s *symbolicStack) apply(instruction *Instruction) {
+	op := instruction.OpCode
+	switch {
+	case vm.PUSH0 <= op && op <= vm.PUSH32 && !instruction.Truncated:
+		*s = append(*s, new(big.Int).SetBytes(instruction.Data))
+	case vm.DUP1 <= op && op <= vm.DUP16:
+		*s = append(*s, s.get(int(op-vm.DUP1)))
+	case vm.SWAP1 <= op && op <= vm.SWAP16:
+		n := int(op-vm.SWAP1) + 1
+		for len(*s) <= n {
+			*s = append(symbolicStack{nil}, *s...)
+		}
+		top, other := len(*s)-1, len(*s)-1-n
+		(*s)[top], (*s)[other] = (*s)[other], (*s)[top]
+	default:
+		info, _ := op.Info()
+		*s = (*s)[:max(len(*s)-info.Inputs, 0)]
+		for i := 0; i < info.Outputs; i++ {
+			*s = append(*s, nil)
+		}
+	}
+}
+
+// endsBlock determines whether the given operation ends a basic block.
+func endsBlock(op vm.OpCode) bool {
+	info, found := op.Info()
+	return !found || info.Terminal || op == vm.JUMP || op == vm.JUMPI
+}
+
+// String produces the assembly representation of the instruction.
+func (i Instruction) String() string {
+	if i.Data == nil && !i.Truncated {
+		return i.OpCode.String()
+	}
+	return fmt.Sprintf("%v 0x%x", i.OpCode, i.Data)
+}
+
+// Listing produces an annotated listing of the given code. Each line lists the
+// position of an instruction, the instruction itself, and annotations on
+// jump destinations, jump targets pushed on the stack, resolved jumps,
+// invalid op-codes, and truncated instructions. Basic blocks are separated
+// by empty lines.
+func Listing(code []byte) string {
+	instructions := Disassemble(code)
+	jumpDests := map[int]bool{}
+	for _, instruction := range instructions {
+		if instruction.OpCode == vm.JUMPDEST {
+			jumpDests[instruction.Pc] = true
+		}
+	}
+
+	var builder strings.Builder
+	for i, instruction := range instructions {
+		if instruction.BlockStart && i > 0 {
+			builder.WriteString("\n")
+		}
+		var notes []string
+		switch {
+		case instruction.Target != nil:
+			notes = append(notes, fmt.Sprintf("jumps to 0x%04x", *instruction.Target))
+		case instruction.Truncated:
+			notes = append(notes, "truncated, code ends within push data")
+		case instruction.OpCode == vm.JUMPDEST:
+			notes = append(notes, "jump destination")
+		case !vm.IsValid(instruction.OpCode):
+			notes = append(notes, "invalid instruction")
+		case len(instruction.Data) > 0:
+			target := new(big.Int).SetBytes(instruction.Data)
+			if target.IsInt64() && jumpDests[int(target.Int64())] {
+				notes = append(notes, fmt.Sprintf("jump target 0x%04x", target.Int64()))
+			}
+		}
+		line := fmt.Sprintf("0x%04x: %v", instruction.Pc, instruction)
+		if len(notes) > 0 {
+			line = fmt.Sprintf("%-40s ; %s", line, strings.Join(notes, ", "))
+		}
+		builder.WriteString(line)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package asm
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestDisassemble_DecodesInstructions(t *testing.T) {
+	code := []byte{byte(vm.PUSH2), 1, 2, byte(vm.ADD), byte(vm.PUSH3), 3}
+	want := []Instruction{
+		{Pc: 0, OpCode: vm.PUSH2, Data: []byte{1, 2}, BlockStart: true},
+		{Pc: 3, OpCode: vm.ADD},
+		{Pc: 4, OpCode: vm.PUSH3, Data: []byte{3}, Truncated: true},
+	}
+	if got := Disassemble(code); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected instructions, wanted %v, got %v", want, got)
+	}
+}
+
+func TestDisassemble_AnnotatesBasicBlocksAndJumpTargets(t *testing.T) {
+	code := MustAssemble(`
+		      PUSH1 @loop
+		      JUMPI
+		      PUSH0
+		      JUMP
+		loop: JUMPDEST
+		      CALLER
+		      JUMP
+		      STOP
+		      PUSH1 @loop
+		      POP
+		      PUSH0
+		      JUMP
+	`)
+	target := 5
+	want := []Instruction{
+		{Pc: 0, OpCode: vm.PUSH1, Data: []byte{5}, BlockStart: true},
+		{Pc: 2, OpCode: vm.JUMPI, Target: &target},
+		{Pc: 3, OpCode: vm.PUSH0, BlockStart: true},
+		{Pc: 4, OpCode: vm.JUMP},
+		{Pc: 5, OpCode: vm.JUMPDEST, BlockStart: true},
+		{Pc: 6, OpCode: vm.CALLER},
+		{Pc: 7, OpCode: vm.JUMP},
+		{Pc: 8, OpCode: vm.STOP, BlockStart: true},
+		{Pc: 9, OpCode: vm.PUSH1, Data: []byte{5}, BlockStart: true},
+		{Pc: 11, OpCode: vm.POP},
+		{Pc: 12, OpCode: vm.PUSH0},
+		{Pc: 13, OpCode: vm.JUMP},
+	}
+	if got := Disassemble(code); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected instructions, wanted %v, got %v", want, got)
+	}
+}
+
+func TestDisassemble_ResolvesJumpTargetsSymbolically(t *testing.T) {
+	tests := map[string]struct {
+		source string
+		target *int
+	}{
+		"pushed before jump":   {source: "PUSH1 @dest\nJUMP", target: new(int)},
+		"moved by swap":        {source: "PUSH1 @dest\nPUSH1 7\nSWAP1\nJUMP", target: new(int)},
+		"duplicated":           {source: "PUSH1 @dest\nDUP1\nPOP\nJUMP", target: new(int)},
+		"below arguments":      {source: "PUSH1 @dest\nCALLER\nPUSH1 1\nADD\nPOP\nJUMP", target: new(int)},
+		"computed":             {source: "PUSH1 @dest\nPUSH1 0\nADD\nJUMP"},
+		"from previous block":  {source: "PUSH1 @dest\nJUMPDEST\nJUMP"},
+		"swapped with unknown": {source: "PUSH1 @dest\nJUMPDEST\nSWAP1\nJUMP"},
+		"no jump destination":  {source: "PUSH1 1\nJUMP"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			code := MustAssemble("dest: JUMPDEST\n" + test.source)
+			instructions := Disassemble(code)
+			got := instructions[len(instructions)-1].Target
+			if (test.target == nil) != (got == nil) || (got != nil && *got != *test.target) {
+				t.Errorf("unexpected target, wanted %v, got %v", test.target, got)
+			}
+		})
+	}
+}
+
+func TestDisassemble_IsInverseOfAssemble(t *testing.T) {
+	source := []string{
+		"PUSH1 0x04",
+		"JUMP",
+		"INVALID",
+		"JUMPDEST",
+		"PUSH32 0x0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20",
+		"POP",
+		"STOP",
+	}
+	code, err := AssembleLines(source...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, instruction := range Disassemble(code) {
+		got = append(got, instruction.String())
+	}
+	if !reflect.DeepEqual(source, got) {
+		t.Errorf("unexpected disassembly, wanted %v, got %v", source, got)
+	}
+
+	reassembled, err := AssembleLines(got...)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(code, reassembled) {
+		t.Errorf("reassembled code differs, wanted %x, got %x", code, reassembled)
+	}
+}
+
+func TestListing_AnnotatesInstructions(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 4,
+		byte(vm.JUMP),
+		0x0c,
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH2), 1,
+	}
+	want := []string{
+		"0x0000: PUSH1 0x04                       ; jump target 0x0004",
+		"0x0002: JUMP                             ; jumps to 0x0004",
+		"",
+		"0x0003: op(0x0C)                         ; invalid instruction",
+		"",
+		"0x0004: JUMPDEST                         ; jump destination",
+		"0x0005: PUSH2 0x01                       ; truncated, code ends within push data",
+		"",
+	}
+	if got := Listing(code); got != strings.Join(want, "\n") {
+		t.Errorf("unexpected listing, wanted\n%s\ngot\n%s", strings.Join(want, "\n"), got)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package bench provides a suite of reproducible workloads for comparing the
+// performance of interpreters. Workloads model typical hot paths of real-world
+// contracts, like ERC20 transfers and Uniswap swaps, as well as stress tests
+// of individual features, like hashing and memory expansion. All workloads
+// can be run on every registered interpreter, and the resulting measurements
+// can be summarized in a comparison table.
+package bench
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Workload is a contract execution used for benchmarking interpreters.
+type Workload struct {
+	Name    string
+	Code    []byte
+	Input   []byte
+	Storage map[tosca.Key]tosca.Word // initial storage of the contract
+}
+
+// contract is the address of the contract executed by workloads.
+var contract = tosca.Address{0xc0, 0xde}
+
+// Run executes the workload once on the given interpreter. An error is
+// returned if the interpreter fails or the execution is not successful.
+func (w Workload) Run(interpreter tosca.Interpreter) (tosca.Result, error) {
+	return w.newRunner().run(interpreter)
+}
+
+// Benchmark runs the workload b.N times on the given interpreter, reporting
+// the time and allocations per execution.
+func (w Workload) Benchmark(b *testing.B, interpreter tosca.Interpreter) {
+	runner := w.newRunner()
+	// A first run warms up the context as well as caches of the interpreter.
+	if _, err := runner.run(interpreter); err != nil {
+		b.Fatalf("failed to run workload %s: %v", w.Name, err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := runner.run(interpreter); err != nil {
+			b.Fatalf("failed to run workload %s: %v", w.Name, err)
+		}
+	}
+}
+
+// runner runs a workload repeatedly, reusing the parameters and the context
+// of the execution to exclude their setup from measurements.
+type runner struct {
+	name    string
+	params  tosca.Parameters
+	context *runContext
+}
+
+func (w Workload) newRunner() *runner {
+	codeHash := tosca.Hash(crypto.Keccak256Hash(w.Code))
+	context := newRunContext(w.Storage)
+	return &runner{
+		name: w.Name,
+		params: tosca.Parameters{
+			BlockParameters: tosca.BlockParameters{
+				Revision: tosca.R13_Cancun,
+			},
+			TransactionParameters: tosca.TransactionParameters{
+				Origin: sender,
+			},
+			Context:   context,
+			Kind:      tosca.Call,
+			Gas:       10_000_000,
+			Recipient: contract,
+			Sender:    sender,
+			Input:     w.Input,
+			CodeHash:  &codeHash,
+			Code:      w.Code,
+		},
+		context: context,
+	}
+}
+
+func (r *runner) run(interpreter tosca.Interpreter) (tosca.Result, error) {
+	r.context.reset()
+	result, err := interpreter.Run(r.params)
+	if err != nil {
+		return result, err
+	}
+	if !result.Success {
+		return result, fmt.Errorf("execution of workload %s failed", r.name)
+	}
+	return result, nil
+}
+
+// Measurement is the outcome of benchmarking a workload on an interpreter.
+type Measurement struct {
+	Workload    string
+	Interpreter string
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// Compare benchmarks each of the given workloads on each of the given
+// interpreters, which are referenced by their registered names. Measurements
+// are reported in the order of the workloads, and for each workload in the
+// order of the interpreters.
+func Compare(workloads []Workload, interpreters []string) ([]Measurement, error) {
+	instances := make([]tosca.Interpreter, 0, len(interpreters))
+	for _, name := range interpreters {
+		interpreter, err := tosca.NewInterpreter(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create interpreter %s: %w", name, err)
+		}
+		instances = append(instances, interpreter)
+	}
+
+	res := make([]Measurement, 0, len(workloads)*len(interpreters))
+	for _, workload := range workloads {
+		for i, interpreter := range instances {
+			// Failures are checked before benchmarking since testing.Benchmark
+			// is not reporting failures of benchmarks to the caller.
+			if _, err := workload.Run(interpreter); err != nil {
+				return nil, fmt.Errorf("failed to run workload %s on %s: %w", workload.Name, interpreters[i], err)
+			}
+			result := testing.Benchmark(func(b *testing.B) {
+				workload.Benchmark(b, interpreter)
+			})
+			res = append(res, Measurement{
+				Workload:    workload.Name,
+				Interpreter: interpreters[i],
+				NsPerOp:     result.NsPerOp(),
+				AllocsPerOp: result.AllocsPerOp(),
+				BytesPerOp:  result.AllocedBytesPerOp(),
+			})
+		}
+	}
+	return res, nil
+}
+
+// WriteTable writes the given measurements as a table to the given writer.
+func WriteTable(out io.Writer, measurements []Measurement) error {
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if _, err := fmt.Fprintln(writer, "workload\tinterpreter\tns/op\tallocs/op\tB/op\t"); err != nil {
+		return err
+	}
+	for _, m := range measurements {
+		_, err := fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t\n",
+			m.Workload, m.Interpreter, m.NsPerOp, m.AllocsPerOp, m.BytesPerOp)
+		if err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bench
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/holiman/uint256"
+	"golang.org/x/exp/maps"
+)
+
+func getInterpretersForTests() []string {
+	res := slices.DeleteFunc(
+		maps.Keys(tosca.GetAllRegisteredInterpreters()),
+		func(s string) bool { return strings.Contains(s, "logging") },
+	)
+	slices.Sort(res)
+	return res
+}
+
+func TestWorkloads_ProduceSameResultsOnAllInterpreters(t *testing.T) {
+	for _, workload := range GetWorkloads() {
+		var reference *tosca.Result
+		for _, name := range getInterpretersForTests() {
+			t.Run(workload.Name+"/"+name, func(t *testing.T) {
+				interpreter, err := tosca.NewInterpreter(name)
+				if err != nil {
+					t.Fatalf("failed to create interpreter: %v", err)
+				}
+				result, err := workload.Run(interpreter)
+				if err != nil {
+					t.Fatalf("failed to run workload: %v", err)
+				}
+				if reference == nil {
+					reference = &result
+					return
+				}
+				if !bytes.Equal(reference.Output, result.Output) {
+					t.Errorf("unexpected output, wanted %x, got %x", reference.Output, result.Output)
+				}
+				if want, got := reference.GasLeft, result.GasLeft; want != got {
+					t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+				}
+				if want, got := reference.GasRefund, result.GasRefund; want != got {
+					t.Errorf("unexpected gas refund, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestWorkloads_ProduceExpectedOutputs(t *testing.T) {
+	interpreter, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	tests := map[string]*uint256.Int{
+		"erc20-transfer": uint256.NewInt(1),
+		// 1000 * 997 * 7_000_000 / (5_000_000 * 1000 + 1000 * 997)
+		"uniswap-swap":     uint256.NewInt(1395),
+		"memory-expansion": uint256.NewInt(0),
+	}
+	for _, workload := range GetWorkloads() {
+		want, found := tests[workload.Name]
+		if !found {
+			continue
+		}
+		t.Run(workload.Name, func(t *testing.T) {
+			result, err := workload.Run(interpreter)
+			if err != nil {
+				t.Fatalf("failed to run workload: %v", err)
+			}
+			if got := new(uint256.Int).SetBytes(result.Output); !want.Eq(got) {
+				t.Errorf("unexpected output, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestWorkload_ERC20TransferUpdatesBalancesAndEmitsEvent(t *testing.T) {
+	interpreter, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	runner := erc20Transfer().newRunner()
+	if _, err := runner.run(interpreter); err != nil {
+		t.Fatalf("failed to run workload: %v", err)
+	}
+	balance := func(address tosca.Address) uint64 {
+		value := runner.context.GetStorage(contract, balanceSlot(address))
+		return new(uint256.Int).SetBytes(value[:]).Uint64()
+	}
+	if want, got := uint64(999_000), balance(sender); want != got {
+		t.Errorf("unexpected sender balance, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(2_001_000), balance(recipient); want != got {
+		t.Errorf("unexpected recipient balance, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, len(runner.context.GetLogs()); want != got {
+		t.Fatalf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	if want, got := 3, len(runner.context.GetLogs()[0].Topics); want != got {
+		t.Errorf("unexpected number of topics, wanted %d, got %d", want, got)
+	}
+}
+
+func TestWorkload_RunsAreIndependentOfEachOther(t *testing.T) {
+	interpreter, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	runner := uniswapSwap().newRunner()
+	first, err := runner.run(interpreter)
+	if err != nil {
+		t.Fatalf("failed to run workload: %v", err)
+	}
+	second, err := runner.run(interpreter)
+	if err != nil {
+		t.Fatalf("failed to run workload: %v", err)
+	}
+	if !bytes.Equal(first.Output, second.Output) || first.GasLeft != second.GasLeft {
+		t.Errorf("repeated runs differ, first %v, second %v", first, second)
+	}
+}
+
+func TestWriteTable_ListsAllMeasurements(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteTable(&out, []Measurement{
+		{Workload: "a", Interpreter: "x", NsPerOp: 12, AllocsPerOp: 1, BytesPerOp: 32},
+		{Workload: "b", Interpreter: "y", NsPerOp: 3456, AllocsPerOp: 0, BytesPerOp: 0},
+	})
+	if err != nil {
+		t.Fatalf("failed to write table: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	want := [][]string{
+		{"workload", "interpreter", "ns/op", "allocs/op", "B/op"},
+		{"a", "x", "12", "1", "32"},
+		{"b", "y", "3456", "0", "0"},
+	}
+	if len(want) != len(lines) {
+		t.Fatalf("unexpected number of lines, wanted %d, got %d", len(want), len(lines))
+	}
+	for i, line := range lines {
+		if got := strings.Fields(line); !slices.Equal(want[i], got) {
+			t.Errorf("unexpected line %d, wanted %v, got %v", i, want[i], got)
+		}
+	}
+}
+
+func BenchmarkWorkloads(b *testing.B) {
+	for _, workload := range GetWorkloads() {
+		for _, name := range getInterpretersForTests() {
+			interpreter, err := tosca.NewInterpreter(name)
+			if err != nil {
+				b.Fatalf("failed to create interpreter: %v", err)
+			}
+			b.Run(workload.Name+"/"+name, func(b *testing.B) {
+				workload.Benchmark(b, interpreter)
+			})
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bench
+
+import (
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// runContext is a minimal tosca.RunContext providing the storage of a single
+// contract. It is reset before each run of a workload, such that every run
+// observes the same initial state. To not distort measurements, resetting
+// the context does not allocate memory once the context has been warmed up.
+type runContext struct {
+	initial   map[tosca.Key]tosca.Word
+	storage   map[tosca.Key]tosca.Word
+	transient map[tosca.Key]tosca.Word
+	accessed  map[tosca.Key]bool
+	logs      []tosca.Log
+}
+
+func newRunContext(initial map[tosca.Key]tosca.Word) *runContext {
+	res := &runContext{
+		initial:   initial,
+		storage:   map[tosca.Key]tosca.Word{},
+		transient: map[tosca.Key]tosca.Word{},
+		accessed:  map[tosca.Key]bool{},
+	}
+	res.reset()
+	return res
+}
+
+func (c *runContext) reset() {
+	clear(c.storage)
+	for key, value := range c.initial {
+		c.storage[key] = value
+	}
+	clear(c.transient)
+	clear(c.accessed)
+	c.logs = c.logs[:0]
+}
+
+func (c *runContext) Call(tosca.CallKind, tosca.CallParameters) (tosca.CallResult, error) {
+	// Workloads are not performing calls, thus any call is reported as failed.
+	return tosca.CallResult{}, nil
+}
+
+func (c *runContext) AccountExists(tosca.Address) bool {
+	return true
+}
+
+func (c *runContext) GetBalance(tosca.Address) tosca.Value {
+	return tosca.Value{}
+}
+
+func (c *runContext) SetBalance(tosca.Address, tosca.Value) {}
+
+func (c *runContext) GetNonce(tosca.Address) uint64 {
+	return 0
+}
+
+func (c *runContext) SetNonce(tosca.Address, uint64) {}
+
+func (c *runContext) GetCode(tosca.Address) tosca.Code {
+	return nil
+}
+
+func (c *runContext) GetCodeHash(tosca.Address) tosca.Hash {
+	return tosca.Hash{}
+}
+
+func (c *runContext) GetCodeSize(tosca.Address) int {
+	return 0
+}
+
+func (c *runContext) SetCode(tosca.Address, tosca.Code) {}
+
+func (c *runContext) GetStorage(_ tosca.Address, key tosca.Key) tosca.Word {
+	return c.storage[key]
+}
+
+func (c *runContext) SetStorage(_ tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	current := c.storage[key]
+	c.storage[key] = value
+	return tosca.GetStorageStatus(c.initial[key], current, value)
+}
+
+func (c *runContext) GetCommittedStorage(_ tosca.Address, key tosca.Key) tosca.Word {
+	return c.initial[key]
+}
+
+func (c *runContext) SelfDestruct(tosca.Address, tosca.Address) bool {
+	return false
+}
+
+func (c *runContext) HasSelfDestructed(tosca.Address) bool {
+	return false
+}
+
+func (c *runContext) CreateSnapshot() tosca.Snapshot {
+	return 0
+}
+
+func (c *runContext) RestoreSnapshot(tosca.Snapshot) {}
+
+func (c *runContext) GetTransientStorage(_ tosca.Address, key tosca.Key) tosca.Word {
+	return c.transient[key]
+}
+
+func (c *runContext) SetTransientStorage(_ tosca.Address, key tosca.Key, value tosca.Word) {
+	c.transient[key] = value
+}
+
+func (c *runContext) AccessAccount(tosca.Address) tosca.AccessStatus {
+	// The only account touched by workloads is the executed contract.
+	return tosca.WarmAccess
+}
+
+func (c *runContext) AccessStorage(_ tosca.Address, key tosca.Key) tosca.AccessStatus {
+	if c.accessed[key] {
+		return tosca.WarmAccess
+	}
+	c.accessed[key] = true
+	return tosca.ColdAccess
+}
+
+func (c *runContext) IsAddressInAccessList(tosca.Address) bool {
+	return true
+}
+
+func (c *runContext) IsSlotInAccessList(_ tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	return true, c.accessed[key]
+}
+
+func (c *runContext) EmitLog(log tosca.Log) {
+	c.logs = append(c.logs, log)
+}
+
+func (c *runContext) GetLogs() []tosca.Log {
+	return c.logs
+}
+
+func (c *runContext) GetBlockHash(int64) tosca.Hash {
+	return tosca.Hash{}
+}
@@ -0,0 +1,284 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bench
+
+import (
+	"encoding/binary"
+
+	"github.com/Fantom-foundation/Tosca/go/asm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+var (
+	// sender is the caller of all workloads.
+	sender = tosca.Address{0x5e, 0x4d}
+	// recipient is the receiver of ERC20 transfers.
+	recipient = tosca.Address{0x4e, 0xc1}
+)
+
+// GetWorkloads returns the canonical workloads of the benchmark suite.
+func GetWorkloads() []Workload {
+	return []Workload{
+		erc20Transfer(),
+		uniswapSwap(),
+		sha3Loop(1000),
+		memoryExpansion(64 * 1024),
+	}
+}
+
+// erc20Transfer transfers a token between two accounts like the transfer
+// function of an ERC20 contract, including the update of the balances, which
+// are stored in a mapping in slot 0, and the emission of a Transfer event.
+func erc20Transfer() Workload {
+	code := asm.MustAssemble(`
+		PUSH1 0x04
+		CALLDATALOAD       ; to
+		PUSH1 0x24
+		CALLDATALOAD       ; amount, to
+		CALLER
+		PUSH1 0
+		MSTORE
+		PUSH1 0
+		PUSH1 32
+		MSTORE
+		PUSH1 64
+		PUSH1 0
+		SHA3               ; senderSlot, amount, to
+		DUP1
+		SLOAD              ; senderBalance, senderSlot, amount, to
+		DUP3
+		DUP2
+		LT
+		PUSH2 @fail
+		JUMPI
+		DUP3
+		SWAP1
+		SUB
+		SWAP1
+		SSTORE             ; amount, to
+		DUP2
+		PUSH1 0
+		MSTORE
+		PUSH1 64
+		PUSH1 0
+		SHA3               ; toSlot, amount, to
+		DUP1
+		SLOAD
+		DUP3
+		ADD
+		SWAP1
+		SSTORE             ; amount, to
+		PUSH1 0
+		MSTORE             ; to
+		CALLER
+		PUSH32 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef
+		PUSH1 32
+		PUSH1 0
+		LOG3
+		PUSH1 1
+		PUSH1 0
+		MSTORE
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	fail:
+		JUMPDEST
+		PUSH1 0
+		DUP1
+		REVERT
+	`)
+
+	// transfer(address,uint256)
+	input := encodeCall(0xa9059cbb, new(uint256.Int).SetBytes(recipient[:]), uint256.NewInt(1000))
+
+	return Workload{
+		Name:  "erc20-transfer",
+		Code:  code,
+		Input: input,
+		Storage: map[tosca.Key]tosca.Word{
+			balanceSlot(sender):    tosca.Word(uint256.NewInt(1_000_000).Bytes32()),
+			balanceSlot(recipient): tosca.Word(uint256.NewInt(2_000_000).Bytes32()),
+		},
+	}
+}
+
+// balanceSlot computes the slot of the given account in a mapping in slot 0.
+func balanceSlot(address tosca.Address) tosca.Key {
+	var data [64]byte
+	copy(data[12:], address[:])
+	return tosca.Key(crypto.Keccak256(data[:]))
+}
+
+// uniswapSwap conducts the reserve updates of a swap of a Uniswap V2 pair,
+// computing the output amount using the constant product formula including
+// the 0.3% fee, and emits a Swap event. Token transfers are not included.
+func uniswapSwap() Workload {
+	code := asm.MustAssemble(`
+		PUSH1 0x04
+		CALLDATALOAD       ; in
+		PUSH1 0
+		SLOAD              ; r0, in
+		PUSH1 1
+		SLOAD              ; r1, r0, in
+		DUP3
+		PUSH2 997
+		MUL                ; inWithFee, r1, r0, in
+		DUP1
+		DUP3
+		MUL                ; numerator, inWithFee, r1, r0, in
+		SWAP1
+		DUP4
+		PUSH2 1000
+		MUL
+		ADD                ; denominator, numerator, r1, r0, in
+		SWAP1
+		DIV                ; out, r1, r0, in
+		DUP2
+		DUP2
+		LT
+		ISZERO
+		PUSH2 @fail
+		JUMPI
+		DUP1
+		DUP3
+		SUB
+		PUSH1 1
+		SSTORE             ; r1 -= out
+		DUP4
+		DUP4
+		ADD
+		PUSH1 0
+		SSTORE             ; r0 += in
+		DUP4
+		PUSH1 0
+		MSTORE
+		DUP1
+		PUSH1 32
+		MSTORE
+		CALLER
+		PUSH32 0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822
+		PUSH1 64
+		PUSH1 0
+		LOG2
+		PUSH1 0
+		MSTORE
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	fail:
+		JUMPDEST
+		PUSH1 0
+		DUP1
+		REVERT
+	`)
+
+	// swap(uint256)
+	input := encodeCall(0x022c0d9f, uint256.NewInt(1_000))
+
+	return Workload{
+		Name:  "uniswap-swap",
+		Code:  code,
+		Input: input,
+		Storage: map[tosca.Key]tosca.Word{
+			tosca.Key(uint256.NewInt(0).Bytes32()): tosca.Word(uint256.NewInt(5_000_000).Bytes32()),
+			tosca.Key(uint256.NewInt(1).Bytes32()): tosca.Word(uint256.NewInt(7_000_000).Bytes32()),
+		},
+	}
+}
+
+// sha3Loop repeatedly hashes a 64 byte memory segment, storing the result in
+// the first word of the segment.
+func sha3Loop(iterations uint64) Workload {
+	code := asm.MustAssemble(`
+		PUSH1 0x04
+		CALLDATALOAD       ; n
+	loop:
+		JUMPDEST
+		DUP1
+		ISZERO
+		PUSH2 @done
+		JUMPI
+		PUSH1 64
+		PUSH1 0
+		SHA3
+		PUSH1 0
+		MSTORE
+		PUSH1 1
+		SWAP1
+		SUB
+		PUSH2 @loop
+		JUMP
+	done:
+		JUMPDEST
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	`)
+
+	input := encodeCall(0, uint256.NewInt(iterations))
+
+	return Workload{
+		Name:  "sha3-loop",
+		Code:  code,
+		Input: input,
+	}
+}
+
+// memoryExpansion writes words to consecutive memory locations, expanding
+// the memory word by word up to the given size in bytes.
+func memoryExpansion(size uint64) Workload {
+	code := asm.MustAssemble(`
+		PUSH1 0x04
+		CALLDATALOAD       ; n
+		PUSH1 0            ; i, n
+	loop:
+		JUMPDEST
+		DUP2
+		DUP2
+		LT
+		ISZERO
+		PUSH2 @done
+		JUMPI
+		DUP1
+		DUP1
+		MSTORE
+		PUSH1 32
+		ADD
+		PUSH2 @loop
+		JUMP
+	done:
+		JUMPDEST
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	`)
+
+	input := encodeCall(0, uint256.NewInt(size))
+
+	return Workload{
+		Name:  "memory-expansion",
+		Code:  code,
+		Input: input,
+	}
+}
+
+// encodeCall encodes the input of a call of the function with the given
+// selector using the given arguments.
+func encodeCall(selector uint32, arguments ...*uint256.Int) []byte {
+	res := binary.BigEndian.AppendUint32(nil, selector)
+	for _, argument := range arguments {
+		word := argument.Bytes32()
+		res = append(res, word[:]...)
+	}
+	return res
+}
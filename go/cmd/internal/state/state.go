@@ -0,0 +1,284 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package state provides an in-memory world state for command line tools
+// executing transactions on states given in the JSON format produced by the
+// prestate tracer.
+package state
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+type account struct {
+	balance tosca.Value
+	nonce   uint64
+	code    tosca.Code
+	storage map[tosca.Key]tosca.Word
+}
+
+type slot struct {
+	address tosca.Address
+	key     tosca.Key
+}
+
+// WorldState is an in-memory tosca.TransactionContext used for replaying
+// transactions. Modifications are recorded in an undo log, such that
+// snapshots can be restored. Transactions executed on the state need to be
+// concluded by a call to EndTransaction.
+type WorldState struct {
+	accounts   map[tosca.Address]*account
+	committed  map[slot]tosca.Word // values of slots modified by the current transaction
+	transient  map[slot]tosca.Word
+	accessed   map[tosca.Address]map[tosca.Key]bool
+	destructed map[tosca.Address]bool
+	logs       []tosca.Log
+	undo       []func()
+}
+
+// NewWorldState creates a state containing the given accounts, as produced by
+// the prestate tracer.
+func NewWorldState(accounts prestate.State) *WorldState {
+	state := &WorldState{accounts: map[tosca.Address]*account{}}
+	for address, acc := range accounts {
+		storage := map[tosca.Key]tosca.Word{}
+		for key, value := range acc.Storage {
+			storage[tosca.Key(key)] = tosca.Word(value)
+		}
+		var balance tosca.Value
+		if acc.Balance != nil {
+			balance = tosca.ValueFromUint256(uint256.MustFromBig(acc.Balance.ToInt()))
+		}
+		state.accounts[address] = &account{
+			balance: balance,
+			nonce:   acc.Nonce,
+			code:    tosca.Code(acc.Code),
+			storage: storage,
+		}
+	}
+	state.EndTransaction()
+	return state
+}
+
+// EndTransaction commits the modifications of the current transaction and
+// resets the transaction-local state.
+func (s *WorldState) EndTransaction() {
+	for address := range s.destructed {
+		delete(s.accounts, address)
+	}
+	s.committed = map[slot]tosca.Word{}
+	s.transient = map[slot]tosca.Word{}
+	s.accessed = map[tosca.Address]map[tosca.Key]bool{}
+	s.destructed = map[tosca.Address]bool{}
+	s.logs = nil
+	s.undo = nil
+}
+
+// getOrCreate returns the given account, creating it if it does not exist.
+func (s *WorldState) getOrCreate(address tosca.Address) *account {
+	acc, found := s.accounts[address]
+	if !found {
+		acc = &account{storage: map[tosca.Key]tosca.Word{}}
+		s.accounts[address] = acc
+		s.undo = append(s.undo, func() { delete(s.accounts, address) })
+	}
+	return acc
+}
+
+func (s *WorldState) AccountExists(address tosca.Address) bool {
+	_, found := s.accounts[address]
+	return found
+}
+
+// RemoveAccount removes the given account, including its storage, from the
+// state. It implements tosca.AccountRemover.
+func (s *WorldState) RemoveAccount(address tosca.Address) {
+	acc, found := s.accounts[address]
+	if !found {
+		return
+	}
+	delete(s.accounts, address)
+	s.undo = append(s.undo, func() { s.accounts[address] = acc })
+}
+
+func (s *WorldState) GetBalance(address tosca.Address) tosca.Value {
+	if acc, found := s.accounts[address]; found {
+		return acc.balance
+	}
+	return tosca.Value{}
+}
+
+func (s *WorldState) SetBalance(address tosca.Address, value tosca.Value) {
+	acc := s.getOrCreate(address)
+	previous := acc.balance
+	acc.balance = value
+	s.undo = append(s.undo, func() { acc.balance = previous })
+}
+
+func (s *WorldState) GetNonce(address tosca.Address) uint64 {
+	if acc, found := s.accounts[address]; found {
+		return acc.nonce
+	}
+	return 0
+}
+
+func (s *WorldState) SetNonce(address tosca.Address, nonce uint64) {
+	acc := s.getOrCreate(address)
+	previous := acc.nonce
+	acc.nonce = nonce
+	s.undo = append(s.undo, func() { acc.nonce = previous })
+}
+
+func (s *WorldState) GetCode(address tosca.Address) tosca.Code {
+	if acc, found := s.accounts[address]; found {
+		return acc.code
+	}
+	return nil
+}
+
+func (s *WorldState) GetCodeHash(address tosca.Address) tosca.Hash {
+	acc, found := s.accounts[address]
+	if !found {
+		return tosca.Hash{}
+	}
+	return tosca.Hash(crypto.Keccak256Hash(acc.code))
+}
+
+func (s *WorldState) GetCodeSize(address tosca.Address) int {
+	return len(s.GetCode(address))
+}
+
+func (s *WorldState) SetCode(address tosca.Address, code tosca.Code) {
+	acc := s.getOrCreate(address)
+	previous := acc.code
+	acc.code = bytes.Clone(code)
+	s.undo = append(s.undo, func() { acc.code = previous })
+}
+
+func (s *WorldState) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if acc, found := s.accounts[address]; found {
+		return acc.storage[key]
+	}
+	return tosca.Word{}
+}
+
+func (s *WorldState) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if value, found := s.committed[slot{address, key}]; found {
+		return value
+	}
+	return s.GetStorage(address, key)
+}
+
+func (s *WorldState) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	original := s.GetCommittedStorage(address, key)
+	if _, found := s.committed[slot{address, key}]; !found {
+		s.committed[slot{address, key}] = original
+	}
+	acc := s.getOrCreate(address)
+	current := acc.storage[key]
+	acc.storage[key] = value
+	s.undo = append(s.undo, func() { acc.storage[key] = current })
+	return tosca.GetStorageStatus(original, current, value)
+}
+
+func (s *WorldState) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	balance := s.GetBalance(address)
+	s.SetBalance(address, tosca.Value{})
+	s.SetBalance(beneficiary, tosca.Add(s.GetBalance(beneficiary), balance))
+	if s.destructed[address] {
+		return false
+	}
+	s.destructed[address] = true
+	s.undo = append(s.undo, func() { delete(s.destructed, address) })
+	return true
+}
+
+func (s *WorldState) HasSelfDestructed(address tosca.Address) bool {
+	return s.destructed[address]
+}
+
+func (s *WorldState) CreateSnapshot() tosca.Snapshot {
+	return tosca.Snapshot(len(s.undo))
+}
+
+func (s *WorldState) RestoreSnapshot(snapshot tosca.Snapshot) {
+	for len(s.undo) > int(snapshot) {
+		s.undo[len(s.undo)-1]()
+		s.undo = s.undo[:len(s.undo)-1]
+	}
+}
+
+func (s *WorldState) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return s.transient[slot{address, key}]
+}
+
+func (s *WorldState) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	previous, found := s.transient[slot{address, key}]
+	s.transient[slot{address, key}] = value
+	s.undo = append(s.undo, func() {
+		if found {
+			s.transient[slot{address, key}] = previous
+		} else {
+			delete(s.transient, slot{address, key})
+		}
+	})
+}
+
+func (s *WorldState) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	if s.IsAddressInAccessList(address) {
+		return tosca.WarmAccess
+	}
+	s.accessed[address] = map[tosca.Key]bool{}
+	s.undo = append(s.undo, func() { delete(s.accessed, address) })
+	return tosca.ColdAccess
+}
+
+func (s *WorldState) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	if _, present := s.IsSlotInAccessList(address, key); present {
+		return tosca.WarmAccess
+	}
+	if !s.IsAddressInAccessList(address) {
+		s.AccessAccount(address)
+	}
+	s.accessed[address][key] = true
+	s.undo = append(s.undo, func() { delete(s.accessed[address], key) })
+	return tosca.ColdAccess
+}
+
+func (s *WorldState) IsAddressInAccessList(address tosca.Address) bool {
+	_, found := s.accessed[address]
+	return found
+}
+
+func (s *WorldState) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	keys, found := s.accessed[address]
+	return found, keys[key]
+}
+
+func (s *WorldState) EmitLog(log tosca.Log) {
+	length := len(s.logs)
+	s.logs = append(s.logs, log)
+	s.undo = append(s.undo, func() { s.logs = s.logs[:length] })
+}
+
+func (s *WorldState) GetLogs() []tosca.Log {
+	return slices.Clone(s.logs)
+}
+
+func (s *WorldState) GetBlockHash(int64) tosca.Hash {
+	// Block hashes are not part of recorded states.
+	return tosca.Hash{}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func TestWorldState_RestoreSnapshotRevertsModifications(t *testing.T) {
+	address := tosca.Address{1}
+	state := NewWorldState(prestate.State{
+		address: {Balance: (*hexutil.Big)(big.NewInt(5)), Storage: map[common.Hash]common.Hash{{1}: {2}}},
+	})
+
+	snapshot := state.CreateSnapshot()
+	state.SetBalance(address, tosca.NewValue(7))
+	state.SetStorage(address, tosca.Key{1}, tosca.Word{3})
+	state.SetNonce(tosca.Address{2}, 1)
+	state.SetTransientStorage(address, tosca.Key{1}, tosca.Word{4})
+	state.EmitLog(tosca.Log{Address: address})
+	state.AccessStorage(tosca.Address{3}, tosca.Key{1})
+	if want, got := (tosca.Word{2}), state.GetCommittedStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected committed value, wanted %v, got %v", want, got)
+	}
+	state.RestoreSnapshot(snapshot)
+
+	if want, got := tosca.NewValue(5), state.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{2}), state.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	if state.AccountExists(tosca.Address{2}) {
+		t.Errorf("created account was not removed")
+	}
+	if want, got := (tosca.Word{}), state.GetTransientStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected transient value, wanted %v, got %v", want, got)
+	}
+	if len(state.GetLogs()) != 0 {
+		t.Errorf("logs were not removed")
+	}
+	if addressPresent, slotPresent := state.IsSlotInAccessList(tosca.Address{3}, tosca.Key{1}); addressPresent || slotPresent {
+		t.Errorf("accesses were not reverted")
+	}
+}
+
+func TestWorldState_RemoveAccountDropsAccountAndCanBeReverted(t *testing.T) {
+	address := tosca.Address{1}
+	state := NewWorldState(prestate.State{
+		address: {Storage: map[common.Hash]common.Hash{{1}: {2}}},
+	})
+
+	snapshot := state.CreateSnapshot()
+	state.RemoveAccount(address)
+	if state.AccountExists(address) {
+		t.Errorf("account was not removed")
+	}
+	if want, got := (tosca.Word{}), state.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage of removed account, wanted %v, got %v", want, got)
+	}
+
+	state.RestoreSnapshot(snapshot)
+	if !state.AccountExists(address) {
+		t.Errorf("removal of account was not reverted")
+	}
+	if want, got := (tosca.Word{2}), state.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+}
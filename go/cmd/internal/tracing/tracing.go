@@ -0,0 +1,52 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package tracing provides the execution traces offered by the command line
+// tools, collected as JSON documents.
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/calltracer"
+	"github.com/Fantom-foundation/Tosca/go/tracers/sink"
+	"github.com/Fantom-foundation/Tosca/go/tracers/structlog"
+)
+
+// New creates a tracer of the given kind, either "struct" or "call", and a
+// function producing the collected trace once the execution has finished.
+// For the empty kind, no tracer is created and both results are nil.
+func New(kind string) (tosca.Tracer, func() (json.RawMessage, error), error) {
+	switch kind {
+	case "":
+		return nil, nil, nil
+	case "struct":
+		var buffer bytes.Buffer
+		logs := sink.NewJSONSink(&buffer, 0)
+		tracer := structlog.New(logs, structlog.Config{})
+		return tracer, func() (json.RawMessage, error) {
+			if err := tracer.Err(); err != nil {
+				return nil, err
+			}
+			if err := logs.Close(); err != nil {
+				return nil, err
+			}
+			return buffer.Bytes(), nil
+		}, nil
+	case "call":
+		tracer := calltracer.New(calltracer.Config{WithLog: true})
+		return tracer, tracer.GetResult, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trace kind: %s", kind)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package txjson provides the JSON descriptions of blocks, transactions, and
+// receipts used by command line tools. Quantities are hex encoded, following
+// the conventions of Ethereum's JSON-RPC API.
+package txjson
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+// Block describes the parameters of the block a transaction is executed in.
+// All fields are optional.
+type Block struct {
+	ChainId     *hexutil.Big    `json:"chainId"`
+	Number      hexutil.Uint64  `json:"number"`
+	Timestamp   hexutil.Uint64  `json:"timestamp"`
+	Coinbase    common.Address  `json:"coinbase"`
+	GasLimit    *hexutil.Uint64 `json:"gasLimit"` // < unlimited if missing
+	PrevRandao  common.Hash     `json:"prevRandao"`
+	BaseFee     *hexutil.Big    `json:"baseFee"`
+	BlobBaseFee *hexutil.Big    `json:"blobBaseFee"`
+	Revision    *tosca.Revision `json:"revision"` // < the newest revision if missing
+}
+
+// Transaction describes a transaction to be executed. All fields except the
+// sender are optional.
+type Transaction struct {
+	From       common.Address   `json:"from"`
+	To         *common.Address  `json:"to"`    // < a contract is created if missing
+	Nonce      *hexutil.Uint64  `json:"nonce"` // < the sender's nonce if missing
+	Gas        *hexutil.Uint64  `json:"gas"`   // < the block gas limit if missing
+	GasPrice   *hexutil.Big     `json:"gasPrice"`
+	Value      *hexutil.Big     `json:"value"`
+	Input      hexutil.Bytes    `json:"input"`
+	AccessList types.AccessList `json:"accessList"`
+}
+
+// Receipt describes the result of the execution of a transaction.
+type Receipt struct {
+	Success          bool            `json:"success"`
+	GasUsed          hexutil.Uint64  `json:"gasUsed"`
+	BlobGasUsed      hexutil.Uint64  `json:"blobGasUsed"`
+	CalldataFloorGas hexutil.Uint64  `json:"calldataFloorGas,omitempty"`
+	Output           hexutil.Bytes   `json:"output"`
+	ContractAddress  *common.Address `json:"contractAddress,omitempty"`
+	Logs             []Log           `json:"logs"`
+}
+
+// Log describes a log emitted by a transaction.
+type Log struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// ToBlockParameters converts the block description to its Tosca counterpart.
+func (b Block) ToBlockParameters() (tosca.BlockParameters, error) {
+	if b.Number > math.MaxInt64 || b.Timestamp > math.MaxInt64 {
+		return tosca.BlockParameters{}, fmt.Errorf("block number or timestamp overflow")
+	}
+	revisions := tosca.GetAllKnownRevisions()
+	res := tosca.BlockParameters{
+		BlockNumber: int64(b.Number),
+		Timestamp:   int64(b.Timestamp),
+		Coinbase:    tosca.Address(b.Coinbase),
+		GasLimit:    math.MaxInt64,
+		PrevRandao:  tosca.Hash(b.PrevRandao),
+		Revision:    revisions[len(revisions)-1],
+	}
+	if b.GasLimit != nil {
+		if *b.GasLimit > math.MaxInt64 {
+			return tosca.BlockParameters{}, fmt.Errorf("block gas limit overflow")
+		}
+		res.GasLimit = tosca.Gas(*b.GasLimit)
+	}
+	if b.Revision != nil {
+		res.Revision = *b.Revision
+	}
+	var err error
+	if res.ChainID, err = toWord(b.ChainId); err != nil {
+		return tosca.BlockParameters{}, fmt.Errorf("chain ID: %w", err)
+	}
+	if res.BaseFee, err = toValue(b.BaseFee); err != nil {
+		return tosca.BlockParameters{}, fmt.Errorf("base fee: %w", err)
+	}
+	if res.BlobBaseFee, err = toValue(b.BlobBaseFee); err != nil {
+		return tosca.BlockParameters{}, fmt.Errorf("blob base fee: %w", err)
+	}
+	return res, nil
+}
+
+// ToTransaction converts the transaction description to its Tosca
+// counterpart. Missing fields are filled in from the given block and state.
+func (t Transaction) ToTransaction(block tosca.BlockParameters, world tosca.WorldState) (tosca.Transaction, error) {
+	res := tosca.Transaction{
+		Sender:    tosca.Address(t.From),
+		Recipient: (*tosca.Address)(t.To),
+		Nonce:     world.GetNonce(tosca.Address(t.From)),
+		Input:     tosca.Data(t.Input),
+		GasLimit:  block.GasLimit,
+	}
+	if t.Nonce != nil {
+		res.Nonce = uint64(*t.Nonce)
+	}
+	if t.Gas != nil {
+		if *t.Gas > math.MaxInt64 {
+			return tosca.Transaction{}, fmt.Errorf("gas limit overflow")
+		}
+		res.GasLimit = tosca.Gas(*t.Gas)
+	}
+	var err error
+	if res.Value, err = toValue(t.Value); err != nil {
+		return tosca.Transaction{}, fmt.Errorf("value: %w", err)
+	}
+	if res.GasPrice, err = toValue(t.GasPrice); err != nil {
+		return tosca.Transaction{}, fmt.Errorf("gas price: %w", err)
+	}
+	for _, tuple := range t.AccessList {
+		keys := make([]tosca.Key, len(tuple.StorageKeys))
+		for i, key := range tuple.StorageKeys {
+			keys[i] = tosca.Key(key)
+		}
+		res.AccessList = append(res.AccessList, tosca.AccessTuple{Address: tosca.Address(tuple.Address), Keys: keys})
+	}
+	return res, nil
+}
+
+func toValue(value *hexutil.Big) (tosca.Value, error) {
+	word, err := toWord(value)
+	return tosca.Value(word), err
+}
+
+func toWord(value *hexutil.Big) (tosca.Word, error) {
+	if value == nil {
+		return tosca.Word{}, nil
+	}
+	res, overflow := uint256.FromBig(value.ToInt())
+	if overflow || value.ToInt().Sign() < 0 {
+		return tosca.Word{}, fmt.Errorf("value out of range: %v", value)
+	}
+	return res.Bytes32(), nil
+}
+
+// NewReceipt creates the description of the given receipt.
+func NewReceipt(receipt tosca.Receipt) Receipt {
+	logs := make([]Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		topics := make([]common.Hash, len(log.Topics))
+		for i, topic := range log.Topics {
+			topics[i] = common.Hash(topic)
+		}
+		logs = append(logs, Log{
+			Address: common.Address(log.Address),
+			Topics:  topics,
+			Data:    hexutil.Bytes(log.Data),
+		})
+	}
+	return Receipt{
+		Success:          receipt.Success,
+		GasUsed:          hexutil.Uint64(receipt.GasUsed),
+		BlobGasUsed:      hexutil.Uint64(receipt.BlobGasUsed),
+		CalldataFloorGas: hexutil.Uint64(receipt.CalldataFloorGas),
+		Output:           hexutil.Bytes(receipt.Output),
+		ContractAddress:  (*common.Address)(receipt.ContractAddress),
+		Logs:             logs,
+	}
+}
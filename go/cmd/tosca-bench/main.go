@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-bench runs the workloads of the benchmark suite on a selection of
+// interpreters and prints a table comparing the time and allocations per
+// execution of each workload.
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/bench"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/exp/maps"
+)
+
+var (
+	interpreterFlag = &cli.StringSliceFlag{
+		Name:  "interpreter",
+		Usage: "interpreters to be benchmarked, all registered interpreters if not set",
+	}
+	workloadFlag = &cli.StringFlag{
+		Name:  "workload",
+		Usage: "regular expression selecting the workloads to be run",
+		Value: ".*",
+	}
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-bench",
+		Usage:     "Tosca Interpreter Benchmark Suite",
+		Copyright: "(c) 2024 Fantom Foundation",
+		Flags:     []cli.Flag{interpreterFlag, workloadFlag},
+		Action:    doBench,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func doBench(context *cli.Context) error {
+	filter, err := regexp.Compile(context.String(workloadFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid workload filter: %w", err)
+	}
+	workloads := slices.DeleteFunc(bench.GetWorkloads(), func(w bench.Workload) bool {
+		return !filter.MatchString(w.Name)
+	})
+
+	interpreters := context.StringSlice(interpreterFlag.Name)
+	if len(interpreters) == 0 {
+		// Logging variants are excluded since they are writing to stdout.
+		interpreters = slices.DeleteFunc(
+			maps.Keys(tosca.GetAllRegisteredInterpreters()),
+			func(s string) bool { return strings.Contains(s, "logging") },
+		)
+		slices.Sort(interpreters)
+	}
+
+	measurements, err := bench.Compare(workloads, interpreters)
+	if err != nil {
+		return err
+	}
+	return bench.WriteTable(context.App.Writer, measurements)
+}
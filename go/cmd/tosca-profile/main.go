@@ -0,0 +1,44 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-profile replays recorded transactions through a chosen interpreter
+// and reports where execution time is spent. It prints a histogram of the
+// executed operations and may store a CPU profile in the pprof format as well
+// as the executed operations per contract in the folded stack format used by
+// flamegraph tools.
+//
+// Transactions are read from files containing RLP encoded transactions. The
+// state they are executed on may be provided in the JSON format produced by
+// the prestate tracer. If multiple transactions are given, they are executed
+// sequentially, each on the state left behind by its predecessor.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-profile",
+		Usage:     "Tosca Opcode Profiler",
+		Copyright: "(c) 2024 Fantom Foundation",
+		ArgsUsage: "<transaction file>...",
+		Flags:     profileCmd.Flags,
+		Action:    profileCmd.Action,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
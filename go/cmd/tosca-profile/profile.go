@@ -0,0 +1,242 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/state"
+	cliUtils "github.com/Fantom-foundation/Tosca/go/ct/driver/cli"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/floria"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/processor/senders"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+	"github.com/urfave/cli/v2"
+)
+
+var profileCmd = cliUtils.AddCommonFlags(cli.Command{
+	Action: doProfile,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "interpreter",
+			Usage: "the interpreter to be profiled",
+			Value: "lfvm",
+		},
+		&cli.StringFlag{
+			Name:  "processor",
+			Usage: "the processor executing the transactions",
+			Value: "floria",
+		},
+		&cli.StringFlag{
+			Name:      "prestate",
+			Usage:     "JSON file containing the state in the format of the prestate tracer",
+			TakesFile: true,
+		},
+		&cli.Uint64Flag{
+			Name:  "chain-id",
+			Usage: "the chain ID used for recovering the senders of transactions",
+			Value: 250,
+		},
+		&cli.StringFlag{
+			Name:  "revision",
+			Usage: "the revision transactions are executed with",
+			Value: tosca.R13_Cancun.String(),
+		},
+		&cli.BoolFlag{
+			Name:  "no-balance-check",
+			Usage: "if set, senders are not charged for gas, such that balances need not be covered by the prestate",
+		},
+		&cli.StringFlag{
+			Name:      "flamegraph",
+			Usage:     "store the executed operations per contract as folded stacks in the provided filename",
+			TakesFile: true,
+		},
+	},
+})
+
+func doProfile(ctx *cli.Context) error {
+	if ctx.Args().Len() == 0 {
+		return fmt.Errorf("missing transaction file")
+	}
+
+	interpreter, err := tosca.NewInterpreter(ctx.String("interpreter"))
+	if err != nil {
+		return err
+	}
+	processor, ok := tosca.GetProcessor(ctx.String("processor"), interpreter).(tosca.SimulatingProcessor)
+	if !ok {
+		return fmt.Errorf("processor not found or not supporting tracing: %s", ctx.String("processor"))
+	}
+	revision, err := parseRevision(ctx.String("revision"))
+	if err != nil {
+		return err
+	}
+
+	accounts := prestate.State{}
+	if filename := ctx.String("prestate"); filename != "" {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &accounts); err != nil {
+			return fmt.Errorf("failed to parse prestate: %w", err)
+		}
+	}
+
+	var transactions []*types.Transaction
+	for _, filename := range ctx.Args().Slice() {
+		txs, err := readTransactions(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		transactions = append(transactions, txs...)
+	}
+
+	chainId := new(big.Int).SetUint64(ctx.Uint64("chain-id"))
+	recoverer, err := senders.NewRecoverer(senders.Config{})
+	if err != nil {
+		return err
+	}
+	sendersOfTransactions, err := recoverer.Recover(ctx.Context, types.LatestSignerForChainID(chainId), transactions)
+	if err != nil {
+		return err
+	}
+
+	blockParameters := tosca.BlockParameters{
+		ChainID:  tosca.Word(uint256.MustFromBig(chainId).Bytes32()),
+		GasLimit: math.MaxInt64,
+		Revision: revision,
+	}
+	options := tosca.SimulationOptions{
+		NoBalanceCheck: ctx.Bool("no-balance-check"),
+	}
+
+	world := state.NewWorldState(accounts)
+	profiler := newProfiler()
+	options.Tracer = profiler
+	for i, tx := range transactions {
+		transaction, err := toToscaTransaction(tx, sendersOfTransactions[i])
+		if err != nil {
+			return fmt.Errorf("transaction %v: %w", tx.Hash(), err)
+		}
+		result, err := processor.Simulate(context.Background(), blockParameters, transaction, world, options)
+		world.EndTransaction()
+		if err != nil {
+			return fmt.Errorf("transaction %v: %w", tx.Hash(), err)
+		}
+		fmt.Fprintf(ctx.App.ErrWriter, "transaction %v: success %t, gas used %d\n", tx.Hash(), result.Success, result.GasUsed)
+	}
+
+	if err := profiler.writeHistogram(ctx.App.Writer); err != nil {
+		return err
+	}
+	if filename := ctx.String("flamegraph"); filename != "" {
+		file, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if err := profiler.writeFoldedStacks(file); err != nil {
+			return err
+		}
+		return file.Close()
+	}
+	return nil
+}
+
+func parseRevision(name string) (tosca.Revision, error) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		if revision.String() == name {
+			return revision, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown revision: %s", name)
+}
+
+// readTransactions reads the transactions stored in the given file. The file
+// may contain a single transaction or an RLP encoded list of transactions,
+// either in binary form or hex encoded.
+func readTransactions(filename string) ([]*types.Transaction, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	if text := bytes.TrimSpace(data); bytes.HasPrefix(text, []byte("0x")) {
+		if data, err = hexutil.Decode(string(text)); err != nil {
+			return nil, err
+		}
+	}
+	var transactions types.Transactions
+	if err := rlp.DecodeBytes(data, &transactions); err == nil {
+		return transactions, nil
+	}
+	transaction := new(types.Transaction)
+	if err := transaction.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return []*types.Transaction{transaction}, nil
+}
+
+// toToscaTransaction converts the given transaction to its Tosca counterpart.
+// Since the base fee of blocks is not recorded, transactions are executed
+// with a zero base fee, paying the priority fee only.
+func toToscaTransaction(tx *types.Transaction, sender tosca.Address) (tosca.Transaction, error) {
+	var recipient *tosca.Address
+	if to := tx.To(); to != nil {
+		recipient = (*tosca.Address)(to)
+	}
+	value, overflow := uint256.FromBig(tx.Value())
+	if overflow {
+		return tosca.Transaction{}, fmt.Errorf("value overflow")
+	}
+	gasPrice, err := tx.EffectiveGasTip(new(big.Int))
+	if err != nil {
+		return tosca.Transaction{}, err
+	}
+	price, overflow := uint256.FromBig(gasPrice)
+	if overflow {
+		return tosca.Transaction{}, fmt.Errorf("gas price overflow")
+	}
+	if tx.Gas() > math.MaxInt64 {
+		return tosca.Transaction{}, fmt.Errorf("gas limit overflow")
+	}
+	accessList := []tosca.AccessTuple{}
+	for _, tuple := range tx.AccessList() {
+		keys := make([]tosca.Key, len(tuple.StorageKeys))
+		for i, key := range tuple.StorageKeys {
+			keys[i] = tosca.Key(key)
+		}
+		accessList = append(accessList, tosca.AccessTuple{Address: tosca.Address(tuple.Address), Keys: keys})
+	}
+	return tosca.Transaction{
+		Sender:     sender,
+		Recipient:  recipient,
+		Nonce:      tx.Nonce(),
+		Input:      tx.Data(),
+		Value:      tosca.ValueFromUint256(value),
+		GasLimit:   tosca.Gas(tx.Gas()),
+		GasPrice:   tosca.ValueFromUint256(price),
+		AccessList: accessList,
+	}, nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/urfave/cli/v2"
+)
+
+func TestProfiler_CountsOperationsPerCallStack(t *testing.T) {
+	profiler := newProfiler()
+	profiler.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: tosca.Address{1}})
+	profiler.OnOpcode(tosca.OpCodeState{OpCode: vm.PUSH1})
+	profiler.OnOpcode(tosca.OpCodeState{OpCode: vm.PUSH1})
+	profiler.OnEnter(1, tosca.DelegateCall, tosca.CallParameters{Recipient: tosca.Address{1}, CodeAddress: tosca.Address{2}})
+	profiler.OnOpcode(tosca.OpCodeState{OpCode: vm.PUSH1})
+	profiler.OnExit(1, tosca.CallResult{}, nil)
+	profiler.OnEnter(1, tosca.Create, tosca.CallParameters{})
+	profiler.OnOpcode(tosca.OpCodeState{OpCode: vm.STOP})
+	profiler.OnExit(1, tosca.CallResult{}, nil)
+	profiler.OnOpcode(tosca.OpCodeState{OpCode: vm.STOP})
+	profiler.OnExit(0, tosca.CallResult{}, nil)
+
+	var folded bytes.Buffer
+	if err := profiler.writeFoldedStacks(&folded); err != nil {
+		t.Fatalf("failed to write folded stacks: %v", err)
+	}
+	first, second := tosca.Address{1}.String(), tosca.Address{2}.String()
+	want := first + ";" + second + ";PUSH1 1\n" +
+		first + ";PUSH1 2\n" +
+		first + ";STOP 1\n" +
+		first + ";create;STOP 1\n"
+	if want != folded.String() {
+		t.Errorf("unexpected folded stacks,\nwanted\n%s\ngot\n%s", want, folded.String())
+	}
+
+	var histogram bytes.Buffer
+	if err := profiler.writeHistogram(&histogram); err != nil {
+		t.Fatalf("failed to write histogram: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(histogram.String()), "\n")
+	if want, got := 2, len(lines); want != got {
+		t.Fatalf("unexpected number of lines, wanted %d, got %d", want, got)
+	}
+	if want, got := []string{"PUSH1", "3", "60.00%"}, strings.Fields(lines[0]); strings.Join(want, " ") != strings.Join(got, " ") {
+		t.Errorf("unexpected histogram entry, wanted %v, got %v", want, got)
+	}
+}
+
+func TestReadTransactions_AcceptsSingleTransactionsAndLists(t *testing.T) {
+	tx := types.NewTx(&types.DynamicFeeTx{Nonce: 1, Gas: 21_000})
+	single, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	list, err := rlp.EncodeToBytes(types.Transactions{tx, tx})
+	if err != nil {
+		t.Fatalf("failed to encode transactions: %v", err)
+	}
+
+	tests := map[string]struct {
+		content []byte
+		count   int
+	}{
+		"single":     {single, 1},
+		"single-hex": {[]byte(hexutil.Encode(single) + "\n"), 1},
+		"list":       {list, 2},
+		"list-hex":   {[]byte(hexutil.Encode(list)), 2},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "txs")
+			if err := os.WriteFile(filename, test.content, 0600); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			txs, err := readTransactions(filename)
+			if err != nil {
+				t.Fatalf("failed to read transactions: %v", err)
+			}
+			if want, got := test.count, len(txs); want != got {
+				t.Fatalf("unexpected number of transactions, wanted %d, got %d", want, got)
+			}
+			if want, got := tx.Hash(), txs[0].Hash(); want != got {
+				t.Errorf("unexpected transaction, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestProfile_ReplaysTransactionsOnPrestate(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	sender := tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+	contract := tosca.Address{1}
+
+	// increments the value of slot 0
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.SLOAD),
+		byte(vm.PUSH1), 1,
+		byte(vm.ADD),
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+		byte(vm.STOP),
+	}
+	state, err := json.Marshal(prestate.State{
+		sender:   {Balance: (*hexutil.Big)(big.NewInt(1e18))},
+		contract: {Code: code},
+	})
+	if err != nil {
+		t.Fatalf("failed to encode prestate: %v", err)
+	}
+
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	txs := types.Transactions{}
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(250),
+			Nonce:     nonce,
+			To:        (*common.Address)(&contract),
+			Gas:       100_000,
+			GasFeeCap: big.NewInt(2),
+			GasTipCap: big.NewInt(1),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		txs = append(txs, tx)
+	}
+	encoded, err := rlp.EncodeToBytes(txs)
+	if err != nil {
+		t.Fatalf("failed to encode transactions: %v", err)
+	}
+
+	dir := t.TempDir()
+	prestateFile := filepath.Join(dir, "prestate.json")
+	txsFile := filepath.Join(dir, "txs.rlp")
+	flamegraphFile := filepath.Join(dir, "profile.folded")
+	if err := os.WriteFile(prestateFile, state, 0600); err != nil {
+		t.Fatalf("failed to write prestate: %v", err)
+	}
+	if err := os.WriteFile(txsFile, encoded, 0600); err != nil {
+		t.Fatalf("failed to write transactions: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	app := &cli.App{Flags: profileCmd.Flags, Action: profileCmd.Action, Writer: &out, ErrWriter: &errOut}
+	args := []string{"tosca-profile", "--prestate", prestateFile, "--flamegraph", flamegraphFile, txsFile}
+	if err := app.Run(args); err != nil {
+		t.Fatalf("failed to run profiler: %v", err)
+	}
+
+	if want, got := 2, strings.Count(errOut.String(), "success true"); want != got {
+		t.Errorf("unexpected number of successful transactions, wanted %d, got %d:\n%s", want, got, errOut.String())
+	}
+	if !strings.Contains(out.String(), "SSTORE") {
+		t.Errorf("histogram is missing SSTORE:\n%s", out.String())
+	}
+	folded, err := os.ReadFile(flamegraphFile)
+	if err != nil {
+		t.Fatalf("failed to read flamegraph: %v", err)
+	}
+	if want := contract.String() + ";SSTORE 2\n"; !strings.Contains(string(folded), want) {
+		t.Errorf("flamegraph is missing %q:\n%s", want, folded)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"golang.org/x/exp/maps"
+)
+
+// profiler is a tosca.Tracer counting the executed instructions. Counts are
+// collected per call stack, where each frame is identified by the contract
+// whose code is executed, such that they can be rendered as flamegraphs.
+type profiler struct {
+	tosca.NoOpTracer
+	frames []string          // the contracts of the currently active calls
+	stacks map[string]uint64 // instruction counts by folded call stack
+	ops    map[vm.OpCode]uint64
+}
+
+func newProfiler() *profiler {
+	return &profiler{
+		stacks: map[string]uint64{},
+		ops:    map[vm.OpCode]uint64{},
+	}
+}
+
+func (p *profiler) OnEnter(_ int, kind tosca.CallKind, parameters tosca.CallParameters) {
+	var frame string
+	switch kind {
+	case tosca.Call, tosca.StaticCall:
+		frame = parameters.Recipient.String()
+	case tosca.CallCode, tosca.DelegateCall:
+		frame = parameters.CodeAddress.String()
+	default:
+		// The address of created contracts is not known before the init
+		// code has completed, thus the kind is used instead.
+		frame = kind.String()
+	}
+	p.frames = append(p.frames, frame)
+}
+
+func (p *profiler) OnExit(int, tosca.CallResult, error) {
+	if len(p.frames) > 0 {
+		p.frames = p.frames[:len(p.frames)-1]
+	}
+}
+
+func (p *profiler) OnOpcode(state tosca.OpCodeState) {
+	p.ops[state.OpCode]++
+	stack := append(slices.Clone(p.frames), state.OpCode.String())
+	p.stacks[strings.Join(stack, ";")]++
+}
+
+// writeHistogram writes the number of executions of each operation, starting
+// with the most frequent one.
+func (p *profiler) writeHistogram(out io.Writer) error {
+	total := uint64(0)
+	for _, count := range p.ops {
+		total += count
+	}
+	ops := maps.Keys(p.ops)
+	slices.SortFunc(ops, func(a, b vm.OpCode) int {
+		if p.ops[a] != p.ops[b] {
+			return cmp.Compare(p.ops[b], p.ops[a])
+		}
+		return cmp.Compare(a, b)
+	})
+	for _, op := range ops {
+		count := p.ops[op]
+		percentage := 100 * float64(count) / float64(total)
+		if _, err := fmt.Fprintf(out, "%-16v %12d %6.2f%%\n", op, count, percentage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFoldedStacks writes the collected call stacks in the folded format
+// consumed by flamegraph tools like flamegraph.pl, inferno, or speedscope.
+// Stacks are rooted in the called contracts, with the executed operations
+// as leaves, weighted by their number of executions.
+func (p *profiler) writeFoldedStacks(out io.Writer) error {
+	stacks := maps.Keys(p.stacks)
+	slices.Sort(stacks)
+	for _, stack := range stacks {
+		if _, err := fmt.Fprintf(out, "%s %d\n", stack, p.stacks[stack]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/txjson"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/maps"
+)
+
+// compareReceipts lists the differences between the recorded and the obtained
+// receipt of a transaction. Since not all recordings include the output of
+// transactions, outputs are only compared if recorded.
+func compareReceipts(want, got txjson.Receipt) []string {
+	var res []string
+	if want.Success != got.Success {
+		res = append(res, fmt.Sprintf("unexpected success, wanted %t, got %t", want.Success, got.Success))
+	}
+	if want.GasUsed != got.GasUsed {
+		res = append(res, fmt.Sprintf("unexpected gas used, wanted %d, got %d", want.GasUsed, got.GasUsed))
+	}
+	if want.Output != nil && !bytes.Equal(want.Output, got.Output) {
+		res = append(res, fmt.Sprintf("unexpected output, wanted %v, got %v", want.Output, got.Output))
+	}
+	if !equalAddresses(want.ContractAddress, got.ContractAddress) {
+		res = append(res, fmt.Sprintf("unexpected contract address, wanted %v, got %v", want.ContractAddress, got.ContractAddress))
+	}
+	if len(want.Logs) != len(got.Logs) {
+		res = append(res, fmt.Sprintf("unexpected number of logs, wanted %d, got %d", len(want.Logs), len(got.Logs)))
+		return res
+	}
+	for i := range want.Logs {
+		if !equalLogs(want.Logs[i], got.Logs[i]) {
+			res = append(res, fmt.Sprintf("unexpected log %d, wanted %+v, got %+v", i, want.Logs[i], got.Logs[i]))
+		}
+	}
+	return res
+}
+
+func equalAddresses(a, b *common.Address) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func equalLogs(a, b txjson.Log) bool {
+	return a.Address == b.Address &&
+		slices.Equal(a.Topics, b.Topics) &&
+		bytes.Equal(a.Data, b.Data)
+}
+
+// compareStates lists the differences between the recorded state after a
+// transaction and the given state. Accounts listed in the state before the
+// transaction but not after it are expected to be deleted.
+func compareStates(before, after prestate.State, got tosca.WorldState) []string {
+	var res []string
+	for _, address := range sortedAddresses(before) {
+		if _, found := after[address]; !found && got.AccountExists(address) {
+			res = append(res, fmt.Sprintf("account %v should not exist", address))
+		}
+	}
+	for _, address := range sortedAddresses(after) {
+		want := after[address]
+		if !got.AccountExists(address) {
+			res = append(res, fmt.Sprintf("account %v is missing", address))
+			continue
+		}
+		wantBalance := new(big.Int)
+		if want.Balance != nil {
+			wantBalance = want.Balance.ToInt()
+		}
+		if gotBalance := got.GetBalance(address).ToBig(); wantBalance.Cmp(gotBalance) != 0 {
+			res = append(res, fmt.Sprintf("unexpected balance of %v, wanted %v, got %v", address, wantBalance, gotBalance))
+		}
+		if gotNonce := got.GetNonce(address); want.Nonce != gotNonce {
+			res = append(res, fmt.Sprintf("unexpected nonce of %v, wanted %d, got %d", address, want.Nonce, gotNonce))
+		}
+		if gotCode := got.GetCode(address); !bytes.Equal(want.Code, gotCode) {
+			res = append(res, fmt.Sprintf("unexpected code of %v, wanted %x, got %x", address, []byte(want.Code), []byte(gotCode)))
+		}
+		for key, value := range want.Storage {
+			if gotValue := got.GetStorage(address, tosca.Key(key)); tosca.Word(value) != gotValue {
+				res = append(res, fmt.Sprintf("unexpected value of slot %v of %v, wanted %v, got %v", key, address, value, gotValue))
+			}
+		}
+	}
+	return res
+}
+
+func sortedAddresses(state prestate.State) []tosca.Address {
+	res := maps.Keys(state)
+	slices.SortFunc(res, func(a, b tosca.Address) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return res
+}
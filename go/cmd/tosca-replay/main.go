@@ -0,0 +1,54 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-replay replays recorded transaction substates through a selection of
+// interpreters and compares the results with the recorded ones. It reports
+// every divergence as well as the throughput achieved by each interpreter and
+// thus serves as an end-to-end regression test on real-world transactions.
+//
+// A substate comprises a transaction, the block environment it was executed
+// in, the state of all accounts it touched before and after its execution,
+// and its result. Substates are read from JSON files, each containing a
+// sequence of substates, or from directories containing such files. The
+// format follows the structure of the substates recorded by Aida:
+//
+//	{
+//	  "block": 1234, "transaction": 5,
+//	  "inputAlloc": {...}, "outputAlloc": {...},
+//	  "env": {...}, "message": {...}, "result": {...}
+//	}
+//
+// where the allocations are given in the format of the prestate tracer and
+// the environment, message, and result follow the block, transaction, and
+// receipt formats of tosca-run.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-replay",
+		Usage:     "Tosca Substate Replay",
+		Copyright: "(c) 2024 Fantom Foundation",
+		ArgsUsage: "<substate file or directory>...",
+		Flags:     replayCmd.Flags,
+		Action:    replayCmd.Action,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/state"
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/txjson"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/floria"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/urfave/cli/v2"
+)
+
+var replayCmd = cli.Command{
+	Action: doReplay,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "interpreter",
+			Usage: "the interpreters substates are replayed with",
+			Value: cli.NewStringSlice("lfvm"),
+		},
+		&cli.StringFlag{
+			Name:  "processor",
+			Usage: "the processor executing the transactions",
+			Value: "floria",
+		},
+		&cli.BoolFlag{
+			Name:  "skip-state",
+			Usage: "if set, only receipts are compared, not the resulting states",
+		},
+	},
+}
+
+// substate is a recorded transaction together with the state it got executed
+// on and its results.
+type substate struct {
+	Block       uint64             `json:"block"`
+	Transaction int                `json:"transaction"`
+	InputAlloc  prestate.State     `json:"inputAlloc"`
+	OutputAlloc prestate.State     `json:"outputAlloc"`
+	Env         txjson.Block       `json:"env"`
+	Message     txjson.Transaction `json:"message"`
+	Result      txjson.Receipt     `json:"result"`
+}
+
+// statistics summarizes the replay of substates with a single interpreter.
+type statistics struct {
+	transactions int
+	divergences  int
+	gas          tosca.Gas
+	duration     time.Duration
+}
+
+type replayer struct {
+	name      string
+	processor tosca.Processor
+	stats     statistics
+}
+
+func doReplay(ctx *cli.Context) error {
+	if ctx.Args().Len() == 0 {
+		return fmt.Errorf("missing substate file")
+	}
+
+	var replayers []*replayer
+	for _, name := range ctx.StringSlice("interpreter") {
+		interpreter, err := tosca.NewInterpreter(name)
+		if err != nil {
+			return err
+		}
+		processor := tosca.GetProcessor(ctx.String("processor"), interpreter)
+		if processor == nil {
+			return fmt.Errorf("processor not found: %s", ctx.String("processor"))
+		}
+		replayers = append(replayers, &replayer{name: name, processor: processor})
+	}
+
+	files, err := getSubstateFiles(ctx.Args().Slice())
+	if err != nil {
+		return err
+	}
+	compareState := !ctx.Bool("skip-state")
+	for _, filename := range files {
+		err := forEachSubstate(filename, func(substate *substate) error {
+			for _, replayer := range replayers {
+				divergences, err := replayer.replay(substate, compareState)
+				if err != nil {
+					return fmt.Errorf("block %d, transaction %d: %w", substate.Block, substate.Transaction, err)
+				}
+				for _, divergence := range divergences {
+					fmt.Fprintf(ctx.App.ErrWriter, "block %d, transaction %d, %s: %s\n", substate.Block, substate.Transaction, replayer.name, divergence)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+	}
+
+	divergences := 0
+	for _, replayer := range replayers {
+		stats := replayer.stats
+		seconds := stats.duration.Seconds()
+		if seconds == 0 {
+			seconds = 1e-9
+		}
+		fmt.Fprintf(ctx.App.Writer, "%s: %d transactions, %d divergences, %v, %.1f tx/s, %.2f Mgas/s\n",
+			replayer.name,
+			stats.transactions,
+			stats.divergences,
+			stats.duration.Round(time.Millisecond),
+			float64(stats.transactions)/seconds,
+			float64(stats.gas)/seconds/1e6,
+		)
+		divergences += stats.divergences
+	}
+	if divergences > 0 {
+		return fmt.Errorf("found %d divergences", divergences)
+	}
+	return nil
+}
+
+// replay executes the given substate and returns the differences between the
+// recorded and the obtained results.
+func (r *replayer) replay(substate *substate, compareState bool) ([]string, error) {
+	world := state.NewWorldState(substate.InputAlloc)
+	blockParameters, err := substate.Env.ToBlockParameters()
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := substate.Message.ToTransaction(blockParameters, world)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	receipt, err := r.processor.Run(context.Background(), blockParameters, transaction, world)
+	r.stats.duration += time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	world.EndTransaction()
+
+	divergences := compareReceipts(substate.Result, txjson.NewReceipt(receipt))
+	if compareState {
+		divergences = append(divergences, compareStates(substate.InputAlloc, substate.OutputAlloc, world)...)
+	}
+	r.stats.transactions++
+	r.stats.gas += receipt.GasUsed
+	r.stats.divergences += len(divergences)
+	return divergences, nil
+}
+
+// getSubstateFiles lists the given files and the JSON files contained in the
+// given directories, including their sub-directories, in lexical order.
+func getSubstateFiles(paths []string) ([]string, error) {
+	var res []string
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				return nil
+			}
+			// Explicitly listed files are accepted regardless of their name.
+			if path == root || strings.HasSuffix(entry.Name(), ".json") {
+				res = append(res, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// forEachSubstate decodes the substates in the given file one after another
+// and calls the given function for each of them.
+func forEachSubstate(filename string, consume func(*substate) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	decoder := json.NewDecoder(file)
+	for {
+		substate := new(substate)
+		err := decoder.Decode(substate)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse substate: %w", err)
+		}
+		if err := consume(substate); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+// storeCode sets storage slot 0 to 1.
+var storeCode = hexutil.Encode([]byte{
+	byte(vm.PUSH1), 1,
+	byte(vm.PUSH1), 0,
+	byte(vm.SSTORE),
+	byte(vm.STOP),
+})
+
+// makeSubstate produces a substate of a transaction calling storeCode, with
+// the given recorded gas usage and value of the modified slot.
+func makeSubstate(block int, gasUsed string, slotValue string) string {
+	return `{
+  "block": ` + strconv.Itoa(block) + `, "transaction": 0,
+  "inputAlloc": {
+    "0x0000000000000000000000000000000000000001": {"balance": "0x10"},
+    "0x0000000000000000000000000000000000000100": {"code": "` + storeCode + `"}
+  },
+  "outputAlloc": {
+    "0x0000000000000000000000000000000000000001": {"balance": "0x10", "nonce": 1},
+    "0x0000000000000000000000000000000000000100": {"code": "` + storeCode + `", "storage": {
+      "0x0000000000000000000000000000000000000000000000000000000000000000": "` + slotValue + `"
+    }}
+  },
+  "env": {"chainId": "0xfa", "revision": "Cancun"},
+  "message": {
+    "from": "0x0000000000000000000000000000000000000001",
+    "to": "0x0000000000000000000000000000000000000100",
+    "gas": "0x186a0"
+  },
+  "result": {"success": true, "gasUsed": "` + gasUsed + `", "logs": []}
+}
+`
+}
+
+const slotOne = "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+// 21000 intrinsic gas + 2 * 3 for the pushes + 2100 for the cold slot access
+// + 20000 for setting the slot = 43106, plus 10% of the remaining 56894 gas
+// charged by Sonic for unused gas.
+const gasUsed = "0xbe9b"
+
+func runReplay(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	app := &cli.App{Flags: replayCmd.Flags, Action: replayCmd.Action, Writer: &out, ErrWriter: &errOut}
+	err := app.Run(append([]string{"tosca-replay"}, args...))
+	return out.String(), errOut.String(), err
+}
+
+func TestReplay_MatchingSubstatesProduceNoDivergences(t *testing.T) {
+	dir := t.TempDir()
+	// Two substates in one file and one in a nested directory.
+	content := makeSubstate(1, gasUsed, slotOne) + makeSubstate(2, gasUsed, slotOne)
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write substates: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested"), 0700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "nested", "b.json"), []byte(makeSubstate(3, gasUsed, slotOne)), 0600); err != nil {
+		t.Fatalf("failed to write substates: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a substate"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	out, errOut, err := runReplay(t, "--interpreter", "lfvm", "--interpreter", "geth", dir)
+	if err != nil {
+		t.Fatalf("replay failed: %v\n%s", err, errOut)
+	}
+	if errOut != "" {
+		t.Errorf("unexpected divergences:\n%s", errOut)
+	}
+	for _, interpreter := range []string{"lfvm", "geth"} {
+		if want := interpreter + ": 3 transactions, 0 divergences"; !strings.Contains(out, want) {
+			t.Errorf("summary is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReplay_ReportsDivergences(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "substates")
+	content := makeSubstate(1, "0x1", slotOne) + makeSubstate(2, gasUsed, "0x0000000000000000000000000000000000000000000000000000000000000002")
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write substates: %v", err)
+	}
+
+	out, errOut, err := runReplay(t, filename)
+	if err == nil || !strings.Contains(err.Error(), "found 2 divergences") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if want := "block 1, transaction 0, lfvm: unexpected gas used"; !strings.Contains(errOut, want) {
+		t.Errorf("missing divergence %q:\n%s", want, errOut)
+	}
+	if want := "block 2, transaction 0, lfvm: unexpected value of slot"; !strings.Contains(errOut, want) {
+		t.Errorf("missing divergence %q:\n%s", want, errOut)
+	}
+	if want := "lfvm: 2 transactions, 2 divergences"; !strings.Contains(out, want) {
+		t.Errorf("summary is missing %q:\n%s", want, out)
+	}
+
+	// Without comparing states, only the receipt divergence remains.
+	_, errOut, err = runReplay(t, "--skip-state", filename)
+	if err == nil || !strings.Contains(err.Error(), "found 1 divergences") {
+		t.Errorf("unexpected error: %v\n%s", err, errOut)
+	}
+}
+
+func TestReplay_RejectsMalformedSubstates(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "substates.json")
+	if err := os.WriteFile(filename, []byte("{"), 0600); err != nil {
+		t.Fatalf("failed to write substates: %v", err)
+	}
+	if _, _, err := runReplay(t, filename); err == nil {
+		t.Errorf("expected an error")
+	}
+	if _, _, err := runReplay(t); err == nil {
+		t.Errorf("expected an error for missing arguments")
+	}
+}
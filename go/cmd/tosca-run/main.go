@@ -0,0 +1,52 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-run executes a single transaction through a chosen interpreter and
+// processor, similar to geth's `evm run` command. The transaction, the block
+// it is included in, and the state it is executed on are described by a JSON
+// file. The resulting receipt, including the emitted logs, is printed as JSON
+// and may optionally be accompanied by a trace of the execution.
+//
+// The input has the following format, where the state is given in the format
+// produced by the prestate tracer and all block and transaction fields are
+// optional:
+//
+//	{
+//	  "alloc": {"0x...": {"balance": "0x...", "code": "0x...", "nonce": 1, "storage": {...}}},
+//	  "block": {"chainId": "0x...", "number": "0x...", "timestamp": "0x...", "coinbase": "0x...",
+//	            "gasLimit": "0x...", "prevRandao": "0x...", "baseFee": "0x...", "blobBaseFee": "0x...",
+//	            "revision": "Cancun"},
+//	  "transaction": {"from": "0x...", "to": "0x...", "nonce": "0x...", "gas": "0x...",
+//	                  "gasPrice": "0x...", "value": "0x...", "input": "0x...", "accessList": [...]}
+//	}
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-run",
+		Usage:     "Tosca Transaction Runner",
+		Copyright: "(c) 2024 Fantom Foundation",
+		ArgsUsage: "<input file>",
+		Flags:     runCmd.Flags,
+		Action:    runCmd.Action,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
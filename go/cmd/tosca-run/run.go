@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/state"
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/tracing"
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/txjson"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/floria"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/urfave/cli/v2"
+)
+
+var runCmd = cli.Command{
+	Action: doRun,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "interpreter",
+			Usage: "the interpreter executing the transaction",
+			Value: "lfvm",
+		},
+		&cli.StringFlag{
+			Name:  "processor",
+			Usage: "the processor executing the transaction",
+			Value: "floria",
+		},
+		&cli.StringFlag{
+			Name:  "trace",
+			Usage: "the kind of trace to be included in the output, either 'struct' or 'call'",
+		},
+		&cli.BoolFlag{
+			Name:  "no-balance-check",
+			Usage: "if set, the sender is not charged for gas, such that its balance need not cover the costs",
+		},
+	},
+}
+
+// runInput is the JSON description of a transaction to be executed.
+type runInput struct {
+	Alloc       prestate.State     `json:"alloc"`
+	Block       txjson.Block       `json:"block"`
+	Transaction txjson.Transaction `json:"transaction"`
+}
+
+// runOutput is the JSON encoding of the result of an execution.
+type runOutput struct {
+	Receipt txjson.Receipt  `json:"receipt"`
+	Trace   json.RawMessage `json:"trace,omitempty"`
+}
+
+func doRun(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return fmt.Errorf("expected exactly one input file")
+	}
+
+	interpreter, err := tosca.NewInterpreter(ctx.String("interpreter"))
+	if err != nil {
+		return err
+	}
+	processor, ok := tosca.GetProcessor(ctx.String("processor"), interpreter).(tosca.SimulatingProcessor)
+	if !ok {
+		return fmt.Errorf("processor not found or not supporting tracing: %s", ctx.String("processor"))
+	}
+
+	input, err := readInput(ctx.Args().First(), ctx.App.Reader)
+	if err != nil {
+		return err
+	}
+	world := state.NewWorldState(input.Alloc)
+	blockParameters, err := input.Block.ToBlockParameters()
+	if err != nil {
+		return err
+	}
+	transaction, err := input.Transaction.ToTransaction(blockParameters, world)
+	if err != nil {
+		return err
+	}
+
+	options := tosca.SimulationOptions{
+		NoBalanceCheck: ctx.Bool("no-balance-check"),
+	}
+	tracer, getTrace, err := tracing.New(ctx.String("trace"))
+	if err != nil {
+		return err
+	}
+	options.Tracer = tracer
+
+	result, err := processor.Simulate(context.Background(), blockParameters, transaction, world, options)
+	if err != nil {
+		return err
+	}
+
+	output := runOutput{Receipt: txjson.NewReceipt(result.Receipt)}
+	if getTrace != nil {
+		if output.Trace, err = getTrace(); err != nil {
+			return fmt.Errorf("failed to collect trace: %w", err)
+		}
+	}
+	encoder := json.NewEncoder(ctx.App.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// readInput parses the input in the given file, or in the given reader if
+// the filename is "-".
+func readInput(filename string, stdin io.Reader) (runInput, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return runInput{}, err
+	}
+	var input runInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		return runInput{}, fmt.Errorf("failed to parse input: %w", err)
+	}
+	return input, nil
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/address"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/urfave/cli/v2"
+)
+
+// runApp runs the command with the given input and arguments and returns the
+// decoded output.
+func runApp(t *testing.T, input string, args ...string) (runOutput, error) {
+	t.Helper()
+	var out bytes.Buffer
+	app := &cli.App{
+		Flags:     runCmd.Flags,
+		Action:    runCmd.Action,
+		Reader:    strings.NewReader(input),
+		Writer:    &out,
+		ErrWriter: &out,
+	}
+	if err := app.Run(append(append([]string{"tosca-run"}, args...), "-")); err != nil {
+		return runOutput{}, err
+	}
+	var output runOutput
+	if err := json.Unmarshal(out.Bytes(), &output); err != nil {
+		t.Fatalf("failed to decode output %q: %v", out.String(), err)
+	}
+	return output, nil
+}
+
+// logAndReturnCode emits a log with topic 0x07 and returns a single byte.
+var logAndReturnCode = hexutil.Encode([]byte{
+	byte(vm.PUSH1), 0x2a,
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE8),
+	byte(vm.PUSH1), 0x07,
+	byte(vm.PUSH1), 1,
+	byte(vm.PUSH1), 0,
+	byte(vm.LOG1),
+	byte(vm.PUSH1), 1,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+})
+
+var callInput = `{
+  "alloc": {
+    "0x0000000000000000000000000000000000000001": {"balance": "0xde0b6b3a7640000"},
+    "0x0000000000000000000000000000000000000100": {"code": "` + logAndReturnCode + `"}
+  },
+  "block": {"chainId": "0xfa", "revision": "Cancun"},
+  "transaction": {
+    "from": "0x0000000000000000000000000000000000000001",
+    "to": "0x0000000000000000000000000000000000000100",
+    "gas": "0x186a0",
+    "gasPrice": "0x1"
+  }
+}`
+
+func TestRun_ReportsReceiptAndLogs(t *testing.T) {
+	output, err := runApp(t, callInput)
+	if err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	receipt := output.Receipt
+	if !receipt.Success {
+		t.Fatalf("transaction failed")
+	}
+	if want, got := "0x2a", receipt.Output.String(); want != got {
+		t.Errorf("unexpected output, wanted %s, got %s", want, got)
+	}
+	if receipt.GasUsed <= 21_000 {
+		t.Errorf("unexpected gas used, got %d", receipt.GasUsed)
+	}
+	if want, got := 1, len(receipt.Logs); want != got {
+		t.Fatalf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	log := receipt.Logs[0]
+	if want, got := "0x0000000000000000000000000000000000000100", log.Address.Hex(); want != got {
+		t.Errorf("unexpected log address, wanted %s, got %s", want, got)
+	}
+	if len(log.Topics) != 1 || log.Topics[0][31] != 0x07 {
+		t.Errorf("unexpected log topics: %v", log.Topics)
+	}
+	if want, got := "0x2a", log.Data.String(); want != got {
+		t.Errorf("unexpected log data, wanted %s, got %s", want, got)
+	}
+	if output.Trace != nil {
+		t.Errorf("unexpected trace: %s", output.Trace)
+	}
+}
+
+func TestRun_IncludesRequestedTrace(t *testing.T) {
+	tests := map[string]string{
+		"struct": `"op":"LOG1"`,
+		"call":   `"type":"CALL"`,
+	}
+	for kind, want := range tests {
+		t.Run(kind, func(t *testing.T) {
+			output, err := runApp(t, callInput, "--trace", kind)
+			if err != nil {
+				t.Fatalf("failed to run transaction: %v", err)
+			}
+			var trace bytes.Buffer
+			if err := json.Compact(&trace, output.Trace); err != nil {
+				t.Fatalf("failed to decode trace: %v", err)
+			}
+			if !strings.Contains(trace.String(), want) {
+				t.Errorf("trace does not contain %s: %s", want, trace.String())
+			}
+		})
+	}
+}
+
+func TestRun_ReportsAddressOfCreatedContract(t *testing.T) {
+	input := `{
+  "alloc": {"0x0000000000000000000000000000000000000001": {"nonce": 3}},
+  "transaction": {"from": "0x0000000000000000000000000000000000000001", "gas": "0x186a0"}
+}`
+	output, err := runApp(t, input, "--no-balance-check")
+	if err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	if !output.Receipt.Success {
+		t.Fatalf("transaction failed")
+	}
+	want := address.Create(tosca.Address{19: 1}, 3)
+	if got := output.Receipt.ContractAddress; got == nil || tosca.Address(*got) != want {
+		t.Errorf("unexpected contract address, wanted %v, got %v", want, got)
+	}
+}
+
+func TestRun_ReadsInputFromFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "input.json")
+	if err := os.WriteFile(filename, []byte(callInput), 0600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	var out bytes.Buffer
+	app := &cli.App{Flags: runCmd.Flags, Action: runCmd.Action, Writer: &out}
+	if err := app.Run([]string{"tosca-run", filename}); err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	if !strings.Contains(out.String(), `"success": true`) {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRun_RejectsInvalidArguments(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		args  []string
+	}{
+		"unknown trace":       {callInput, []string{"--trace", "unknown"}},
+		"unknown interpreter": {callInput, []string{"--interpreter", "unknown"}},
+		"malformed input":     {"{", nil},
+		"negative value":      {`{"transaction": {"value": "-0x1"}}`, nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := runApp(t, test.input, test.args...); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// getChecks returns the checks of the self-test suite. The checks cover the
+// major groups of instructions and are valid in all supported revisions.
+func getChecks() []check {
+	return []check{
+		{
+			name:   "stop",
+			code:   code(vm.STOP),
+			verify: expectReturn(nil, 0),
+		},
+		{
+			name: "arithmetic",
+			code: code(
+				vm.PUSH1, 2,
+				vm.PUSH1, 3,
+				vm.ADD,
+				vm.PUSH1, 0,
+				vm.MSTORE,
+				vm.PUSH1, 32,
+				vm.PUSH1, 0,
+				vm.RETURN,
+			),
+			verify: expectReturn(word(5), 24),
+		},
+		{
+			name: "keccak",
+			code: code(
+				vm.PUSH1, 0,
+				vm.PUSH1, 0,
+				vm.SHA3,
+				vm.PUSH1, 0,
+				vm.MSTORE,
+				vm.PUSH1, 32,
+				vm.PUSH1, 0,
+				vm.RETURN,
+			),
+			verify: expectReturn(crypto.Keccak256(nil), 51),
+		},
+		{
+			name: "loop",
+			code: code(
+				vm.PUSH1, 5,
+				vm.JUMPDEST, // < loop head at position 2
+				vm.PUSH1, 1,
+				vm.SWAP1,
+				vm.SUB,
+				vm.DUP1,
+				vm.PUSH1, 2,
+				vm.JUMPI,
+				vm.STOP,
+			),
+			verify: expectReturn(nil, 3+5*26),
+		},
+		{
+			name: "calldata",
+			code: code(
+				vm.CALLDATASIZE,
+				vm.PUSH1, 0,
+				vm.PUSH1, 0,
+				vm.CALLDATACOPY,
+				vm.CALLDATASIZE,
+				vm.PUSH1, 0,
+				vm.RETURN,
+			),
+			input:  []byte("tosca"),
+			verify: expectReturn([]byte("tosca"), -1),
+		},
+		{
+			name: "environment",
+			code: code(
+				vm.CALLER,
+				vm.PUSH1, 0,
+				vm.MSTORE,
+				vm.ADDRESS,
+				vm.PUSH1, 32,
+				vm.MSTORE,
+				vm.PUSH1, 64,
+				vm.PUSH1, 0,
+				vm.RETURN,
+			),
+			verify: expectReturn(append(addressWord(sender), addressWord(recipient)...), -1),
+		},
+		{
+			name: "storage",
+			code: code(
+				vm.PUSH1, 42,
+				vm.PUSH1, 1,
+				vm.SSTORE,
+				vm.PUSH1, 1,
+				vm.SLOAD,
+				vm.PUSH1, 0,
+				vm.MSTORE,
+				vm.PUSH1, 32,
+				vm.PUSH1, 0,
+				vm.RETURN,
+			),
+			verify: allOf(expectReturn(word(42), -1), expectStorage(tosca.Key{31: 1}, tosca.Word{31: 42})),
+		},
+		{
+			name: "log",
+			code: code(
+				vm.PUSH1, 42,
+				vm.PUSH1, 0,
+				vm.MSTORE8,
+				vm.PUSH1, 7,
+				vm.PUSH1, 1,
+				vm.PUSH1, 0,
+				vm.LOG1,
+				vm.STOP,
+			),
+			verify: allOf(expectReturn(nil, -1), expectLog(tosca.Log{
+				Address: recipient,
+				Topics:  []tosca.Hash{{31: 7}},
+				Data:    []byte{42},
+			})),
+		},
+		{
+			name: "revert",
+			code: code(
+				vm.PUSH1, 42,
+				vm.PUSH1, 0,
+				vm.MSTORE8,
+				vm.PUSH1, 1,
+				vm.PUSH1, 0,
+				vm.REVERT,
+			),
+			verify: expectRevert([]byte{42}, 18),
+		},
+		{
+			name:   "invalid instruction",
+			code:   code(vm.INVALID),
+			verify: expectFailure,
+		},
+		{
+			name:   "stack underflow",
+			code:   code(vm.ADD),
+			verify: expectFailure,
+		},
+		{
+			name: "invalid jump",
+			code: code(
+				vm.PUSH1, 3,
+				vm.JUMP,
+				vm.STOP,
+			),
+			verify: expectFailure,
+		},
+		{
+			name: "out of gas",
+			code: code(
+				vm.JUMPDEST,
+				vm.PUSH1, 0,
+				vm.JUMP,
+			),
+			gas:    1000,
+			verify: expectFailure,
+		},
+	}
+}
+
+// code assembles the given sequence of op-codes and immediate arguments.
+func code(ops ...any) []byte {
+	res := make([]byte, 0, len(ops))
+	for _, op := range ops {
+		switch op := op.(type) {
+		case vm.OpCode:
+			res = append(res, byte(op))
+		case int:
+			res = append(res, byte(op))
+		default:
+			panic(fmt.Sprintf("unsupported element %v in code", op))
+		}
+	}
+	return res
+}
+
+// word returns the 32-byte big-endian encoding of the given value.
+func word(value byte) []byte {
+	res := make([]byte, 32)
+	res[31] = value
+	return res
+}
+
+// addressWord returns the given address left-padded to 32 bytes.
+func addressWord(address tosca.Address) []byte {
+	return append(make([]byte, 12), address[:]...)
+}
+
+// expectStorage verifies that the given slot of the executed contract holds
+// the given value after the execution.
+func expectStorage(key tosca.Key, value tosca.Word) verifier {
+	return func(_ tosca.Result, _ tosca.Gas, context *runContext) error {
+		if got := context.GetStorage(recipient, key); got != value {
+			return fmt.Errorf("unexpected value in storage slot %v, wanted %v, got %v", key, value, got)
+		}
+		return nil
+	}
+}
+
+// expectLog verifies that the given log was the only one emitted.
+func expectLog(log tosca.Log) verifier {
+	return func(_ tosca.Result, _ tosca.Gas, context *runContext) error {
+		logs := context.GetLogs()
+		if len(logs) != 1 {
+			return fmt.Errorf("expected a single log, got %d", len(logs))
+		}
+		got := logs[0]
+		equal := got.Address == log.Address &&
+			len(got.Topics) == len(log.Topics) &&
+			bytes.Equal(got.Data, log.Data)
+		for i := 0; equal && i < len(log.Topics); i++ {
+			equal = got.Topics[i] == log.Topics[i]
+		}
+		if !equal {
+			return fmt.Errorf("unexpected log, wanted %v, got %v", log, got)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-selftest runs a quick built-in smoke suite of contract executions
+// against an interpreter and prints a pass/fail report. It is intended for
+// node operators validating a deployment before enabling a new VM. The
+// interpreter is either selected by its registered name or loaded from an
+// EVMC library. The command exits with a non-zero status if any check fails.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-selftest",
+		Usage:     "Tosca Interpreter Self-Test",
+		Copyright: "(c) 2024 Fantom Foundation",
+		Flags:     selfTestCmd.Flags,
+		Action:    selfTestCmd.Action,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
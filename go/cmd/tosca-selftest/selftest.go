@@ -0,0 +1,234 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/state"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/evmc"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/urfave/cli/v2"
+)
+
+var selfTestCmd = cli.Command{
+	Action: doSelfTest,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "interpreter",
+			Usage: "the registered interpreter to be tested",
+			Value: "lfvm",
+		},
+		&cli.StringFlag{
+			Name:  "library",
+			Usage: "path to an EVMC library providing the interpreter to be tested, overrides --interpreter",
+		},
+		&cli.StringFlag{
+			Name:  "revision",
+			Usage: "the revision the checks are executed in, the newest known revision if not set",
+		},
+	},
+}
+
+func doSelfTest(ctx *cli.Context) error {
+	interpreter, name, err := loadInterpreter(ctx.String("interpreter"), ctx.String("library"))
+	if err != nil {
+		return err
+	}
+	revision, err := parseRevision(ctx.String("revision"))
+	if err != nil {
+		return err
+	}
+
+	out := ctx.App.Writer
+	fmt.Fprintf(out, "Testing interpreter %s in revision %v\n", name, revision)
+	failed := 0
+	for _, check := range getChecks() {
+		if err := check.run(interpreter, revision); err != nil {
+			failed++
+			fmt.Fprintf(out, "FAIL  %s: %v\n", check.name, err)
+		} else {
+			fmt.Fprintf(out, "PASS  %s\n", check.name)
+		}
+	}
+	total := len(getChecks())
+	fmt.Fprintf(out, "%d of %d checks passed\n", total-failed, total)
+	if failed > 0 {
+		return fmt.Errorf("self-test of interpreter %s failed: %d of %d checks failed", name, failed, total)
+	}
+	return nil
+}
+
+// loadInterpreter loads the interpreter from the given EVMC library if one is
+// given, and otherwise instantiates the registered interpreter of the given
+// name. The name of the selected interpreter is returned for the report.
+func loadInterpreter(name, library string) (tosca.Interpreter, string, error) {
+	if library != "" {
+		interpreter, err := evmc.LoadEvmcInterpreter(library)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load EVMC library %s: %w", library, err)
+		}
+		return interpreter, library, nil
+	}
+	interpreter, err := tosca.NewInterpreter(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return interpreter, name, nil
+}
+
+// parseRevision resolves the name of a revision, where an empty name selects
+// the newest known revision.
+func parseRevision(name string) (tosca.Revision, error) {
+	revisions := tosca.GetAllKnownRevisions()
+	if name == "" {
+		return revisions[len(revisions)-1], nil
+	}
+	names := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		if strings.EqualFold(revision.String(), name) {
+			return revision, nil
+		}
+		names = append(names, revision.String())
+	}
+	return 0, fmt.Errorf("unknown revision %q, supported are %s", name, strings.Join(names, ", "))
+}
+
+// check is a single contract execution of the self-test suite.
+type check struct {
+	name   string
+	code   []byte
+	input  []byte
+	gas    tosca.Gas // < the gas limit of the execution, a default limit if zero
+	verify verifier
+}
+
+// verifier checks the result of an execution consuming the given amount of
+// gas in the given context.
+type verifier func(result tosca.Result, gasUsed tosca.Gas, context *runContext) error
+
+// recipient is the address of the contract executed by the checks.
+var recipient = tosca.Address{0xc0, 0xde}
+
+// sender is the address of the account calling the contract.
+var sender = tosca.Address{0x5e, 0x4d}
+
+// runContext is the context of executions of the checks. Nested calls are not
+// supported and fail.
+type runContext struct {
+	*state.WorldState
+}
+
+func (runContext) Call(tosca.CallKind, tosca.CallParameters) (tosca.CallResult, error) {
+	return tosca.CallResult{}, nil
+}
+
+// run executes the check on the given interpreter and verifies its result.
+// Panics of the interpreter are reported as errors.
+func (c check) run(interpreter tosca.Interpreter, revision tosca.Revision) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("interpreter panicked: %v", r)
+		}
+	}()
+
+	gas := c.gas
+	if gas == 0 {
+		gas = 100_000
+	}
+	codeHash := tosca.Hash(crypto.Keccak256Hash(c.code))
+	context := &runContext{state.NewWorldState(nil)}
+	// Like processors, the accounts involved in the call are accessed before
+	// the execution starts (see EIP-2929).
+	context.AccessAccount(sender)
+	context.AccessAccount(recipient)
+	result, err := interpreter.Run(tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{
+			Revision: revision,
+		},
+		TransactionParameters: tosca.TransactionParameters{
+			Origin: sender,
+		},
+		Context:   context,
+		Kind:      tosca.Call,
+		Gas:       gas,
+		Recipient: recipient,
+		Sender:    sender,
+		Input:     c.input,
+		CodeHash:  &codeHash,
+		Code:      c.code,
+	})
+	if err != nil {
+		return fmt.Errorf("interpreter failed: %w", err)
+	}
+	if result.GasLeft < 0 || result.GasLeft > gas {
+		return fmt.Errorf("invalid gas left %d for gas limit %d", result.GasLeft, gas)
+	}
+	return c.verify(result, gas-result.GasLeft, context)
+}
+
+// expectReturn verifies that the execution succeeded with the given output
+// and gas usage. A negative gas usage is not verified.
+func expectReturn(output []byte, gasUsed tosca.Gas) verifier {
+	return expectOutcome(true, output, gasUsed)
+}
+
+// expectRevert verifies that the execution got reverted with the given output
+// and gas usage.
+func expectRevert(output []byte, gasUsed tosca.Gas) verifier {
+	return expectOutcome(false, output, gasUsed)
+}
+
+func expectOutcome(success bool, output []byte, gasUsed tosca.Gas) verifier {
+	return func(result tosca.Result, used tosca.Gas, _ *runContext) error {
+		if result.Success != success {
+			return fmt.Errorf("unexpected success, wanted %t, got %t", success, result.Success)
+		}
+		if !bytes.Equal(result.Output, output) {
+			return fmt.Errorf("unexpected output, wanted 0x%x, got 0x%x", output, result.Output)
+		}
+		if gasUsed >= 0 && used != gasUsed {
+			return fmt.Errorf("unexpected gas usage, wanted %d, got %d", gasUsed, used)
+		}
+		return nil
+	}
+}
+
+// expectFailure verifies that the execution failed, consuming all gas.
+func expectFailure(result tosca.Result, _ tosca.Gas, _ *runContext) error {
+	if result.Success {
+		return fmt.Errorf("expected execution to fail")
+	}
+	if result.GasLeft != 0 {
+		return fmt.Errorf("expected all gas to be consumed, %d gas left", result.GasLeft)
+	}
+	if len(result.Output) != 0 {
+		return fmt.Errorf("unexpected output 0x%x", result.Output)
+	}
+	return nil
+}
+
+// allOf combines the given verifiers, reporting the first failing one.
+func allOf(verifiers ...verifier) verifier {
+	return func(result tosca.Result, gasUsed tosca.Gas, context *runContext) error {
+		for _, verify := range verifiers {
+			if err := verify(result, gasUsed, context); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
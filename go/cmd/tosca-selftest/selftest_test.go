@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/urfave/cli/v2"
+	"go.uber.org/mock/gomock"
+)
+
+// runApp runs the command with the given arguments and returns its output.
+func runApp(args ...string) (string, error) {
+	var out bytes.Buffer
+	app := &cli.App{
+		Flags:     selfTestCmd.Flags,
+		Action:    selfTestCmd.Action,
+		Writer:    &out,
+		ErrWriter: &out,
+	}
+	err := app.Run(append([]string{"tosca-selftest"}, args...))
+	return out.String(), err
+}
+
+func TestSelfTest_ReferenceInterpretersPassAllChecks(t *testing.T) {
+	for _, interpreter := range []string{"lfvm", "geth"} {
+		for _, revision := range tosca.GetAllKnownRevisions() {
+			t.Run(fmt.Sprintf("%s/%v", interpreter, revision), func(t *testing.T) {
+				out, err := runApp("--interpreter", interpreter, "--revision", revision.String())
+				if err != nil {
+					t.Fatalf("self-test failed: %v\n%s", err, out)
+				}
+				want := fmt.Sprintf("%d of %d checks passed", len(getChecks()), len(getChecks()))
+				if !strings.Contains(out, want) {
+					t.Errorf("unexpected report, wanted %q in\n%s", want, out)
+				}
+				if strings.Contains(out, "FAIL") {
+					t.Errorf("unexpected failure in report\n%s", out)
+				}
+			})
+		}
+	}
+}
+
+func TestSelfTest_FailingChecksAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	// A broken interpreter succeeding without consuming any gas.
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params tosca.Parameters) (tosca.Result, error) {
+		return tosca.Result{Success: true, GasLeft: params.Gas}, nil
+	}).AnyTimes()
+
+	revision := tosca.GetAllKnownRevisions()[0]
+	failed := 0
+	for _, check := range getChecks() {
+		if check.run(interpreter, revision) != nil {
+			failed++
+		}
+	}
+	if failed == 0 || failed == len(getChecks()) {
+		t.Errorf("expected some but not all checks to fail, got %d failures", failed)
+	}
+}
+
+func TestSelfTest_InterpreterPanicsAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(tosca.Parameters) (tosca.Result, error) {
+		panic("broken")
+	})
+
+	err := getChecks()[0].run(interpreter, tosca.R13_Cancun)
+	if err == nil || !strings.Contains(err.Error(), "panicked") {
+		t.Errorf("expected panic to be reported, got %v", err)
+	}
+}
+
+func TestSelfTest_InterpreterErrorsAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{}, fmt.Errorf("injected"))
+
+	err := getChecks()[0].run(interpreter, tosca.R13_Cancun)
+	if err == nil || !strings.Contains(err.Error(), "injected") {
+		t.Errorf("expected interpreter error to be reported, got %v", err)
+	}
+}
+
+func TestSelfTest_InvalidGasLeftIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params tosca.Parameters) (tosca.Result, error) {
+		return tosca.Result{Success: true, GasLeft: params.Gas + 1}, nil
+	})
+
+	err := getChecks()[0].run(interpreter, tosca.R13_Cancun)
+	if err == nil || !strings.Contains(err.Error(), "invalid gas left") {
+		t.Errorf("expected invalid gas to be reported, got %v", err)
+	}
+}
+
+func TestSelfTest_InvalidArgumentsAreRejected(t *testing.T) {
+	tests := map[string][]string{
+		"unknown interpreter": {"--interpreter", "unknown"},
+		"unknown revision":    {"--revision", "Frontier"},
+		"missing library":     {"--library", "/non/existing/library.so"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := runApp(args...); err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestParseRevision_DefaultsToNewestRevision(t *testing.T) {
+	revisions := tosca.GetAllKnownRevisions()
+	got, err := parseRevision("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := revisions[len(revisions)-1]; got != want {
+		t.Errorf("unexpected revision, wanted %v, got %v", want, got)
+	}
+	got, err = parseRevision("berlin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := tosca.R09_Berlin; got != want {
+		t.Errorf("unexpected revision, wanted %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// tosca-server exposes the execution of transactions through registered
+// processors as a JSON API over HTTP, enabling tools written in other
+// languages to use Tosca out of process. The following endpoints are offered,
+// all accepting POST requests with a JSON body:
+//
+//	/run-transaction  executes a single transaction and returns its receipt
+//	/run-block        executes a list of transactions in order on a shared
+//	                  state and returns their receipts
+//	/trace            executes a single transaction and returns its receipt
+//	                  and a trace of the given kind, either "struct" or "call"
+//
+// The world state is part of each request and given in the format produced by
+// the prestate tracer. Blocks and transactions use the format of tosca-run:
+//
+//	{
+//	  "alloc": {"0x...": {"balance": "0x...", "code": "0x...", "nonce": 1, "storage": {...}}},
+//	  "block": {"chainId": "0x...", "number": "0x...", "revision": "Cancun", ...},
+//	  "transaction": {"from": "0x...", "to": "0x...", "gas": "0x...", ...},
+//	  "transactions": [...],
+//	  "tracer": "call",
+//	  "noBalanceCheck": false,
+//	  "unlimitedGas": false
+//	}
+//
+// With unlimitedGas set, single transactions are executed as view calls with
+// an effectively unlimited gas limit, reporting the gas they would consume.
+//
+// Failed requests are answered with a non-200 status code and a JSON object
+// containing an "error" field.
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/urfave/cli/v2"
+)
+
+func main() {
+	app := &cli.App{
+		Name:      "tosca-server",
+		Usage:     "Tosca Transaction Execution Server",
+		Copyright: "(c) 2024 Fantom Foundation",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "listen",
+				Usage: "the address the server is listening on",
+				Value: "localhost:8080",
+			},
+			&cli.StringFlag{
+				Name:  "interpreter",
+				Usage: "the interpreter executing transactions",
+				Value: "lfvm",
+			},
+			&cli.StringFlag{
+				Name:  "processor",
+				Usage: "the processor executing transactions",
+				Value: "floria",
+			},
+		},
+		Action: doServe,
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func doServe(ctx *cli.Context) error {
+	server, err := newServer(ctx.String("interpreter"), ctx.String("processor"))
+	if err != nil {
+		return err
+	}
+	log.Printf("serving %s/%s on %s", ctx.String("processor"), ctx.String("interpreter"), ctx.String("listen"))
+	return http.ListenAndServe(ctx.String("listen"), server.handler())
+}
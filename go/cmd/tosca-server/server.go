@@ -0,0 +1,223 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/state"
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/tracing"
+	"github.com/Fantom-foundation/Tosca/go/cmd/internal/txjson"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/floria"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+)
+
+// maxRequestSize limits the size of request bodies accepted by the server.
+const maxRequestSize = 64 << 20
+
+// server executes the transactions of incoming requests using a processor.
+// Each request carries its own world state, such that requests are
+// independent and may be served in parallel.
+type server struct {
+	processor tosca.SimulatingProcessor
+}
+
+func newServer(interpreterName, processorName string) (*server, error) {
+	interpreter, err := tosca.NewInterpreter(interpreterName)
+	if err != nil {
+		return nil, err
+	}
+	processor, ok := tosca.GetProcessor(processorName, interpreter).(tosca.SimulatingProcessor)
+	if !ok {
+		return nil, fmt.Errorf("processor not found or not supporting tracing: %s", processorName)
+	}
+	return &server{processor: processor}, nil
+}
+
+// handler returns the HTTP handler serving the API of the server.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /run-transaction", s.handleRunTransaction)
+	mux.HandleFunc("POST /run-block", s.handleRunBlock)
+	mux.HandleFunc("POST /trace", s.handleTrace)
+	return mux
+}
+
+// transactionRequest describes a single transaction to be executed.
+type transactionRequest struct {
+	Alloc          prestate.State     `json:"alloc"`
+	Block          txjson.Block       `json:"block"`
+	Transaction    txjson.Transaction `json:"transaction"`
+	NoBalanceCheck bool               `json:"noBalanceCheck"`
+	UnlimitedGas   bool               `json:"unlimitedGas"`
+}
+
+// traceRequest describes a single transaction to be executed with the given
+// kind of tracer, either "struct" or "call".
+type traceRequest struct {
+	transactionRequest
+	Tracer string `json:"tracer"`
+}
+
+// blockRequest describes a list of transactions to be executed in order.
+type blockRequest struct {
+	Alloc          prestate.State       `json:"alloc"`
+	Block          txjson.Block         `json:"block"`
+	Transactions   []txjson.Transaction `json:"transactions"`
+	NoBalanceCheck bool                 `json:"noBalanceCheck"`
+}
+
+// transactionResponse is the result of executing a single transaction.
+type transactionResponse struct {
+	Receipt txjson.Receipt  `json:"receipt"`
+	Trace   json.RawMessage `json:"trace,omitempty"`
+}
+
+// blockResponse lists the receipts of the transactions of a block request.
+type blockResponse struct {
+	Receipts []txjson.Receipt `json:"receipts"`
+}
+
+// errorResponse is the body of responses to failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *server) handleRunTransaction(w http.ResponseWriter, r *http.Request) {
+	var request transactionRequest
+	if !readRequest(w, r, &request) {
+		return
+	}
+	response, err := s.runTransaction(r.Context(), request, "")
+	writeResponse(w, response, err)
+}
+
+func (s *server) handleTrace(w http.ResponseWriter, r *http.Request) {
+	var request traceRequest
+	if !readRequest(w, r, &request) {
+		return
+	}
+	if request.Tracer == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing tracer"))
+		return
+	}
+	response, err := s.runTransaction(r.Context(), request.transactionRequest, request.Tracer)
+	writeResponse(w, response, err)
+}
+
+func (s *server) handleRunBlock(w http.ResponseWriter, r *http.Request) {
+	var request blockRequest
+	if !readRequest(w, r, &request) {
+		return
+	}
+	response, err := s.runBlock(r.Context(), request)
+	writeResponse(w, response, err)
+}
+
+// runTransaction executes the transaction of the given request, collecting a
+// trace of the given kind if it is not empty.
+func (s *server) runTransaction(ctx context.Context, request transactionRequest, traceKind string) (transactionResponse, error) {
+	world := state.NewWorldState(request.Alloc)
+	blockParameters, err := request.Block.ToBlockParameters()
+	if err != nil {
+		return transactionResponse{}, err
+	}
+	transaction, err := request.Transaction.ToTransaction(blockParameters, world)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+	tracer, getTrace, err := tracing.New(traceKind)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	options := tosca.SimulationOptions{
+		NoBalanceCheck: request.NoBalanceCheck,
+		UnlimitedGas:   request.UnlimitedGas,
+		Tracer:         tracer,
+	}
+	result, err := s.processor.Simulate(ctx, blockParameters, transaction, world, options)
+	if err != nil {
+		return transactionResponse{}, err
+	}
+
+	response := transactionResponse{Receipt: txjson.NewReceipt(result.Receipt)}
+	if getTrace != nil {
+		if response.Trace, err = getTrace(); err != nil {
+			return transactionResponse{}, fmt.Errorf("failed to collect trace: %w", err)
+		}
+	}
+	return response, nil
+}
+
+// runBlock executes the transactions of the given request in order, each on
+// the state resulting from its predecessors.
+func (s *server) runBlock(ctx context.Context, request blockRequest) (blockResponse, error) {
+	world := state.NewWorldState(request.Alloc)
+	blockParameters, err := request.Block.ToBlockParameters()
+	if err != nil {
+		return blockResponse{}, err
+	}
+
+	options := tosca.SimulationOptions{NoBalanceCheck: request.NoBalanceCheck}
+	response := blockResponse{Receipts: []txjson.Receipt{}}
+	for i, tx := range request.Transactions {
+		transaction, err := tx.ToTransaction(blockParameters, world)
+		if err != nil {
+			return blockResponse{}, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		result, err := s.processor.Simulate(ctx, blockParameters, transaction, world, options)
+		if err != nil {
+			return blockResponse{}, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		world.EndTransaction()
+		response.Receipts = append(response.Receipts, txjson.NewReceipt(result.Receipt))
+	}
+	return response, nil
+}
+
+// readRequest decodes the body of the given request into the given value. On
+// failure, an error is written to the response and false is returned.
+func readRequest(w http.ResponseWriter, r *http.Request, request any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse request: %w", err))
+		return false
+	}
+	return true
+}
+
+// writeResponse writes the given response, or the given error if it is not nil.
+func writeResponse(w http.ResponseWriter, response any, err error) {
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
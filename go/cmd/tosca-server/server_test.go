@@ -0,0 +1,187 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// post sends the given body to the given path of a server using the default
+// interpreter and processor, and decodes the response into the given value.
+func post(t *testing.T, path string, body string, response any) int {
+	t.Helper()
+	server, err := newServer("lfvm", "floria")
+	if err != nil {
+		t.Fatalf("failed to create server: %v", err)
+	}
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	server.handler().ServeHTTP(recorder, request)
+	if err := json.Unmarshal(recorder.Body.Bytes(), response); err != nil {
+		t.Fatalf("failed to decode response %q: %v", recorder.Body.String(), err)
+	}
+	return recorder.Code
+}
+
+// incrementCode increments the value of storage slot 0 and returns the new
+// value as a 32-byte word.
+var incrementCode = hexutil.Encode([]byte{
+	byte(vm.PUSH1), 0,
+	byte(vm.SLOAD),
+	byte(vm.PUSH1), 1,
+	byte(vm.ADD),
+	byte(vm.DUP1),
+	byte(vm.PUSH1), 0,
+	byte(vm.SSTORE),
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+})
+
+var incrementState = `
+  "alloc": {
+    "0x0000000000000000000000000000000000000001": {"balance": "0xde0b6b3a7640000"},
+    "0x0000000000000000000000000000000000000100": {"code": "` + incrementCode + `"}
+  },
+  "block": {"chainId": "0xfa", "revision": "Cancun"}`
+
+const incrementTransaction = `{
+    "from": "0x0000000000000000000000000000000000000001",
+    "to": "0x0000000000000000000000000000000000000100",
+    "gas": "0x186a0",
+    "gasPrice": "0x1"
+  }`
+
+var (
+	transactionInput = `{` + incrementState + `, "transaction": ` + incrementTransaction + `}`
+	blockInput       = `{` + incrementState + `, "transactions": [` + incrementTransaction + `, ` + incrementTransaction + `]}`
+)
+
+func TestServer_RunTransactionReportsReceipt(t *testing.T) {
+	var response transactionResponse
+	if status := post(t, "/run-transaction", transactionInput, &response); status != http.StatusOK {
+		t.Fatalf("unexpected status, wanted %d, got %d", http.StatusOK, status)
+	}
+	receipt := response.Receipt
+	if !receipt.Success {
+		t.Fatalf("transaction failed")
+	}
+	if want, got := byte(1), receipt.Output[31]; want != got {
+		t.Errorf("unexpected output, wanted %d, got %d", want, got)
+	}
+	if receipt.GasUsed <= 21_000 {
+		t.Errorf("unexpected gas used, got %d", receipt.GasUsed)
+	}
+	if response.Trace != nil {
+		t.Errorf("unexpected trace: %s", response.Trace)
+	}
+}
+
+func TestServer_RunBlockExecutesTransactionsOnSharedState(t *testing.T) {
+	var response blockResponse
+	if status := post(t, "/run-block", blockInput, &response); status != http.StatusOK {
+		t.Fatalf("unexpected status, wanted %d, got %d", http.StatusOK, status)
+	}
+	if want, got := 2, len(response.Receipts); want != got {
+		t.Fatalf("unexpected number of receipts, wanted %d, got %d", want, got)
+	}
+	for i, receipt := range response.Receipts {
+		if !receipt.Success {
+			t.Fatalf("transaction %d failed", i)
+		}
+		if want, got := byte(i+1), receipt.Output[31]; want != got {
+			t.Errorf("unexpected output of transaction %d, wanted %d, got %d", i, want, got)
+		}
+	}
+}
+
+func TestServer_TraceIncludesRequestedTrace(t *testing.T) {
+	tests := map[string]string{
+		"struct": `"op":"SSTORE"`,
+		"call":   `"type":"CALL"`,
+	}
+	for kind, want := range tests {
+		t.Run(kind, func(t *testing.T) {
+			input := strings.Replace(transactionInput, "{", `{"tracer": "`+kind+`", `, 1)
+			var response transactionResponse
+			if status := post(t, "/trace", input, &response); status != http.StatusOK {
+				t.Fatalf("unexpected status, wanted %d, got %d", http.StatusOK, status)
+			}
+			var trace bytes.Buffer
+			if err := json.Compact(&trace, response.Trace); err != nil {
+				t.Fatalf("failed to decode trace: %v", err)
+			}
+			if !strings.Contains(trace.String(), want) {
+				t.Errorf("trace does not contain %s: %s", want, trace.String())
+			}
+		})
+	}
+}
+
+func TestServer_RejectsInvalidRequests(t *testing.T) {
+	tests := map[string]struct {
+		path   string
+		input  string
+		status int
+	}{
+		"malformed input": {"/run-transaction", "{", http.StatusBadRequest},
+		"unknown field":   {"/run-transaction", `{"unknown": 1}`, http.StatusBadRequest},
+		"missing tracer":  {"/trace", transactionInput, http.StatusBadRequest},
+		"unknown tracer":  {"/trace", `{"tracer": "unknown"}`, http.StatusUnprocessableEntity},
+		"negative value":  {"/run-transaction", `{"transaction": {"value": "-0x1"}}`, http.StatusBadRequest},
+		"invalid block":   {"/run-block", `{"transactions": [{"value": "-0x1"}]}`, http.StatusBadRequest},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var response errorResponse
+			if want, got := test.status, post(t, test.path, test.input, &response); want != got {
+				t.Errorf("unexpected status, wanted %d, got %d", want, got)
+			}
+			if response.Error == "" {
+				t.Errorf("missing error message")
+			}
+		})
+	}
+}
+
+func TestNewServer_RejectsUnknownComponents(t *testing.T) {
+	if _, err := newServer("unknown", "floria"); err == nil {
+		t.Errorf("expected an error for an unknown interpreter")
+	}
+	if _, err := newServer("lfvm", "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown processor")
+	}
+}
+
+func TestServer_UnlimitedGasIgnoresGasLimit(t *testing.T) {
+	input := strings.Replace(transactionInput, `"gas": "0x186a0"`, `"gas": "0x5208"`, 1)
+	for _, unlimited := range []bool{false, true} {
+		request := strings.Replace(input, "{", fmt.Sprintf(`{"unlimitedGas": %t, `, unlimited), 1)
+		var response transactionResponse
+		if status := post(t, "/run-transaction", request, &response); status != http.StatusOK {
+			t.Fatalf("unexpected status, wanted %d, got %d", http.StatusOK, status)
+		}
+		if want, got := unlimited, response.Receipt.Success; want != got {
+			t.Errorf("unexpected success for unlimited gas %t, wanted %t, got %t", unlimited, want, got)
+		}
+	}
+}
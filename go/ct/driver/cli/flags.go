@@ -72,6 +72,22 @@ func (f *seedFlagType) Fetch(context *cli.Context) uint64 {
 	return context.Uint64(f.Name)
 }
 
+type corpusFlagType struct {
+	cli.StringFlag
+}
+
+var CorpusFlag = &corpusFlagType{
+	cli.StringFlag{
+		Name:      "corpus",
+		Usage:     "directory to which input states exposing issues are exported, to be re-run with the regressions command",
+		TakesFile: true,
+	},
+}
+
+func (f *corpusFlagType) Fetch(context *cli.Context) string {
+	return context.String(f.Name)
+}
+
 type cpuProfileType struct {
 	cli.StringFlag
 }
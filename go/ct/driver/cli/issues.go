@@ -11,6 +11,8 @@
 package cliUtils
 
 import (
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -84,3 +86,60 @@ func (c *IssuesCollector) ExportIssues() error {
 	}
 	return nil
 }
+
+// ExportCorpus exports the input states of all collected issues into the given
+// corpus directory, which is created if needed. Files are named by the hash
+// of their content, such that states already present in the corpus are not
+// duplicated. The corpus can be re-run using the regressions command. The
+// result lists the files added to the corpus.
+func (c *IssuesCollector) ExportCorpus(dir string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create corpus directory: %w", err)
+	}
+	var added []string
+	var errs []error
+	for _, issue := range c.issues {
+		if issue.input == nil {
+			continue
+		}
+		path, isNew, err := exportToCorpus(issue.input, dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if isNew {
+			added = append(added, path)
+		}
+	}
+	return added, errors.Join(errs...)
+}
+
+// exportToCorpus writes the given state into the corpus directory using a
+// file name derived from the serialized state. It reports whether the state
+// was not yet part of the corpus.
+func exportToCorpus(state *st.State, dir string) (string, bool, error) {
+	tmp, err := os.CreateTemp(dir, "export_*.tmp")
+	if err != nil {
+		return "", false, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := st.ExportStateJSON(state, tmp.Name()); err != nil {
+		return "", false, err
+	}
+	data, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return "", false, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("state_%x.json", sha256.Sum256(data)))
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
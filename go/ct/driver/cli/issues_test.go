@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package cliUtils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestIssuesCollector_ExportCorpusWritesInputStates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "corpus")
+	state := st.NewState(st.NewCode([]byte{byte(vm.ADD)}))
+	state.Gas = 42
+
+	collector := IssuesCollector{}
+	collector.AddIssue(state, errors.New("some issue"))
+	collector.AddIssue(nil, errors.New("issue without input"))
+
+	added, err := collector.ExportCorpus(dir)
+	if err != nil {
+		t.Fatalf("failed to export corpus: %v", err)
+	}
+	if len(added) != 1 {
+		t.Fatalf("unexpected number of exported states, wanted 1, got %d", len(added))
+	}
+
+	restored, err := st.ImportStateJSON(added[0])
+	if err != nil {
+		t.Fatalf("failed to import exported state: %v", err)
+	}
+	if !state.Eq(restored) {
+		t.Errorf("unexpected exported state, differences: %v", state.Diff(restored))
+	}
+}
+
+func TestIssuesCollector_ExportCorpusSkipsKnownStates(t *testing.T) {
+	dir := t.TempDir()
+	state := st.NewState(st.NewCode([]byte{byte(vm.ADD)}))
+
+	collector := IssuesCollector{}
+	collector.AddIssue(state, errors.New("some issue"))
+	collector.AddIssue(state, errors.New("same issue again"))
+
+	added, err := collector.ExportCorpus(dir)
+	if err != nil {
+		t.Fatalf("failed to export corpus: %v", err)
+	}
+	if len(added) != 1 {
+		t.Errorf("unexpected number of exported states, wanted 1, got %d", len(added))
+	}
+
+	added, err = collector.ExportCorpus(dir)
+	if err != nil {
+		t.Fatalf("failed to export corpus: %v", err)
+	}
+	if len(added) != 0 {
+		t.Errorf("states already in the corpus should not be added again, got %v", added)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read corpus directory: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("unexpected number of files in corpus, wanted 1, got %d", len(entries))
+	}
+}
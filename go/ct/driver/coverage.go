@@ -0,0 +1,42 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	cliUtils "github.com/Fantom-foundation/Tosca/go/ct/driver/cli"
+	"github.com/Fantom-foundation/Tosca/go/ct/spc"
+	"github.com/urfave/cli/v2"
+)
+
+var CoverageCmd = cli.Command{
+	Action: doCoverage,
+	Name:   "coverage",
+	Usage:  "Prints a JSON matrix of the operations and revisions covered by rules",
+	Flags: []cli.Flag{
+		cliUtils.FilterFlag,
+	},
+}
+
+func doCoverage(context *cli.Context) error {
+
+	filter, err := cliUtils.FilterFlag.Fetch(context)
+	if err != nil {
+		return err
+	}
+
+	rules := spc.FilterRules(spc.Spec.GetRules(), filter)
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(spc.GetCoverage(rules))
+}
@@ -17,6 +17,10 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// optionalCommands lists commands only available in builds using specific
+// build tags.
+var optionalCommands []*cli.Command
+
 func main() {
 	app := &cli.App{
 		Name:      "driver",
@@ -24,6 +28,7 @@ func main() {
 		Copyright: "(c) 2023 Fantom Foundation",
 		Flags:     []cli.Flag{},
 		Commands: []*cli.Command{
+			&CoverageCmd,
 			&GeneratorInfoCmd,
 			&ListCmd,
 			&ProbeCmd,
@@ -33,6 +38,7 @@ func main() {
 			&TestCmd,
 		},
 	}
+	app.Commands = append(app.Commands, optionalCommands...)
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build mutation
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	cliUtils "github.com/Fantom-foundation/Tosca/go/ct/driver/cli"
+	"github.com/Fantom-foundation/Tosca/go/ct/rlz"
+	"github.com/Fantom-foundation/Tosca/go/ct/spc"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/urfave/cli/v2"
+)
+
+// The mutate command is only available in builds using the "mutation" build
+// tag, enabling the fault injection hooks of the LFVM:
+//
+//	go run -tags mutation ./go/ct/driver mutate
+var MutateCmd = cliUtils.AddCommonFlags(cli.Command{
+	Action: doMutate,
+	Name:   "mutate",
+	Usage:  "Measures the strength of the conformance rules by checking that they detect faults injected into the LFVM",
+	Flags: []cli.Flag{
+		cliUtils.FilterFlag, // < filters mutations, not rules
+		cliUtils.JobsFlag,
+		cliUtils.SeedFlag,
+		cliUtils.FullModeFlag,
+	},
+})
+
+func init() {
+	optionalCommands = append(optionalCommands, &MutateCmd)
+}
+
+func doMutate(context *cli.Context) error {
+	filter, err := cliUtils.FilterFlag.Fetch(context)
+	if err != nil {
+		return err
+	}
+	jobCount := cliUtils.JobsFlag.Fetch(context)
+	seed := cliUtils.SeedFlag.Fetch(context)
+	fullMode := cliUtils.FullModeFlag.Fetch(context)
+
+	evm := lfvm.NewConformanceTestingTarget()
+	defer lfvm.SetMutation(nil)
+
+	var total, killed int
+	survivors := []string{}
+	for _, mutation := range lfvm.GetMutations() {
+		if filter != nil && !filter.MatchString(mutation.Name) {
+			continue
+		}
+
+		// A mutation is killed as soon as one test fails.
+		var detected atomic.Bool
+		opRun := func(state *st.State) (result rlz.ConsumerResult) {
+			defer func() {
+				if r := recover(); r != nil {
+					detected.Store(true)
+					result = rlz.ConsumeAbort
+				}
+			}()
+			if !state.Code.IsCode(int(state.Pc)) {
+				return rlz.ConsumeContinue
+			}
+			if err := runTest(state, evm, nil); err != nil {
+				targetError := &tosca.ErrUnsupportedRevision{}
+				if errors.As(err, &targetError) {
+					return rlz.ConsumeContinue
+				}
+				detected.Store(true)
+				return rlz.ConsumeAbort
+			}
+			return rlz.ConsumeContinue
+		}
+
+		lfvm.SetMutation(&mutation)
+		rules := spc.FilterRulesForOperation(spc.Spec.GetRules(), mutation.Op)
+		noProgress := func(time.Duration, float64, int64) {}
+		err := spc.ForEachState(rules, opRun, noProgress, jobCount, seed, fullMode)
+		lfvm.SetMutation(nil)
+		if err != nil {
+			return fmt.Errorf("error generating States: %w", err)
+		}
+
+		total++
+		if detected.Load() {
+			killed++
+			fmt.Printf("killed   %v\n", mutation)
+		} else {
+			survivors = append(survivors, mutation.Name)
+			fmt.Printf("SURVIVED %v\n", mutation)
+		}
+	}
+
+	if total == 0 {
+		return fmt.Errorf("no mutations selected")
+	}
+	fmt.Printf("Mutation score: %d of %d mutations killed (%.1f%%)\n",
+		killed, total, 100*float64(killed)/float64(total))
+	if len(survivors) > 0 {
+		return fmt.Errorf("%d mutations survived: %v", len(survivors), survivors)
+	}
+	return nil
+}
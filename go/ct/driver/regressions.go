@@ -31,7 +31,7 @@ var RegressionsCmd = cliUtils.AddCommonFlags(cli.Command{
 	Flags: []cli.Flag{
 		&cli.StringSliceFlag{
 			Name:  "input",
-			Usage: "run given input file, or all files in the given directory (recursively)",
+			Usage: "run given input file, or all files in the given directory (recursively), e.g. a corpus exported by the run command; files with a .pb extension are read in the binary state format",
 			Value: cli.NewStringSlice("./regression_inputs"),
 		},
 	},
@@ -94,10 +94,12 @@ func doRegressionTests(context *cli.Context) error {
 		return err
 	}
 
+	numFailures := 0
 	for _, input := range inputs {
-		state, err := st.ImportStateJSON(input)
+		state, err := st.ImportState(input)
 		if err != nil {
 			fmt.Printf("Failed to import state from %v: %v\n", input, err)
+			numFailures++
 			continue
 		}
 
@@ -126,11 +128,13 @@ func doRegressionTests(context *cli.Context) error {
 			result, err := evm.StepN(input.Clone(), 1)
 			if err != nil {
 				fmt.Printf("Failed to evaluate rule %v: %v\n", rule, err)
+				numFailures++
 				continue
 			}
 
 			if !result.Eq(expected) {
 				fmt.Printf("Failed to evaluate rule %v: %v\n", rule, formatDiffForUser(input, result, expected, rule.Name))
+				numFailures++
 				continue
 			}
 
@@ -140,5 +144,8 @@ func doRegressionTests(context *cli.Context) error {
 		}
 	}
 
+	if numFailures > 0 {
+		return fmt.Errorf("failed to pass %d regression tests", numFailures)
+	}
 	return nil
 }
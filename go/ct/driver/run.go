@@ -46,11 +46,15 @@ var RunCmd = cliUtils.AddCommonFlags(cli.Command{
 		cliUtils.JobsFlag,
 		cliUtils.SeedFlag,
 		cliUtils.FullModeFlag, // < TODO: make every run a full mode once tests pass
+		cliUtils.CorpusFlag,
 		&cli.IntFlag{
 			Name:  "max-errors",
 			Usage: "aborts testing after the given number of issues",
 			Value: 100,
 		},
+		durationFlag,
+		maxHeapGrowthFlag,
+		maxRssGrowthFlag,
 	},
 })
 
@@ -68,6 +72,7 @@ func doRun(context *cli.Context) error {
 	jobCount := cliUtils.JobsFlag.Fetch(context)
 	seed := cliUtils.SeedFlag.Fetch(context)
 	fullMode := cliUtils.FullModeFlag.Fetch(context)
+	corpus := cliUtils.CorpusFlag.Fetch(context)
 	filter, err := cliUtils.FilterFlag.Fetch(context)
 	if err != nil {
 		return err
@@ -78,6 +83,16 @@ func doRun(context *cli.Context) error {
 		maxErrors = math.MaxInt
 	}
 
+	// In soak mode, rounds of tests are run until the deadline is reached.
+	duration := context.Duration(durationFlag.Name)
+	deadline := time.Now().Add(duration)
+	var monitor *soakMonitor
+	if duration > 0 {
+		if monitor, err = newSoakMonitor(context); err != nil {
+			return err
+		}
+	}
+
 	var evmIdentifier string
 	if context.Args().Len() >= 1 {
 		evmIdentifier = context.Args().Get(0)
@@ -112,6 +127,10 @@ func doRun(context *cli.Context) error {
 			return rlz.ConsumeAbort
 		}
 
+		if monitor != nil && time.Now().After(deadline) {
+			return rlz.ConsumeAbort
+		}
+
 		// TODO: do not only skip state but change 'pc_on_data_is_ignored' rule to anyEffect, see #954
 		// Pc on data is not supported
 		if !state.Code.IsCode(int(state.Pc)) {
@@ -133,9 +152,22 @@ func doRun(context *cli.Context) error {
 
 	rules := spc.FilterRules(spc.Spec.GetRules(), filter)
 
-	err = spc.ForEachState(rules, opRun, printIssueCounts, jobCount, seed, fullMode)
-	if err != nil {
-		return fmt.Errorf("error generating States: %w", err)
+	if monitor == nil {
+		err = spc.ForEachState(rules, opRun, printIssueCounts, jobCount, seed, fullMode)
+		if err != nil {
+			return fmt.Errorf("error generating States: %w", err)
+		}
+	} else {
+		// Each round uses a new seed, derived from the given one.
+		err = soak(os.Stdout, deadline, seed, monitor, measureMemoryUsage, func(seed uint64) (bool, error) {
+			if err := spc.ForEachState(rules, opRun, printIssueCounts, jobCount, seed, fullMode); err != nil {
+				return true, fmt.Errorf("error generating States: %w", err)
+			}
+			return issuesCollector.NumIssues() >= maxErrors, nil
+		})
+		if err != nil {
+			return err
+		}
 	}
 	issues := issuesCollector.GetIssues()
 
@@ -154,6 +186,14 @@ func doRun(context *cli.Context) error {
 		return err
 	}
 
+	if corpus != "" {
+		added, err := issuesCollector.ExportCorpus(corpus)
+		fmt.Printf("Added %d input states to corpus %s\n", len(added), corpus)
+		if err != nil {
+			return fmt.Errorf("failed to export corpus: %w", err)
+		}
+	}
+
 	return fmt.Errorf("failed to pass %d test cases", len(issues))
 }
 
@@ -0,0 +1,176 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dsnet/golib/unitconv"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	durationFlag = &cli.DurationFlag{
+		Name:  "duration",
+		Usage: "enables the soak mode, continuously generating and running states for the given duration",
+	}
+	maxHeapGrowthFlag = &cli.StringFlag{
+		Name:  "max-heap-growth",
+		Usage: "in soak mode, the tolerated growth of the live Go heap after the first round, e.g. 64Mi",
+		Value: "64Mi",
+	}
+	maxRssGrowthFlag = &cli.StringFlag{
+		Name:  "max-rss-growth",
+		Usage: "in soak mode, the tolerated growth of the resident set size after the first round, e.g. 256Mi",
+		Value: "256Mi",
+	}
+)
+
+// memoryUsage is a sample of the memory used by the process.
+type memoryUsage struct {
+	heap uint64 // < bytes of live heap objects after a garbage collection
+	rss  uint64 // < resident set size of the process, zero if unknown
+}
+
+func (u memoryUsage) String() string {
+	rss := "unknown"
+	if u.rss > 0 {
+		rss = formatBytes(u.rss)
+	}
+	return fmt.Sprintf("heap %s, rss %s", formatBytes(u.heap), rss)
+}
+
+// measureMemoryUsage samples the current memory usage of the process. A
+// garbage collection is triggered first, such that only live objects are
+// accounted for in the heap size.
+func measureMemoryUsage() memoryUsage {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return memoryUsage{heap: stats.HeapAlloc, rss: readRss()}
+}
+
+// readRss returns the resident set size of the process, which includes
+// memory allocated by interpreters implemented in C++ or Rust. It is only
+// supported on Linux, zero is returned on other systems.
+func readRss() uint64 {
+	data, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) < 2 {
+		return 0
+	}
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return pages * uint64(os.Getpagesize())
+}
+
+// soakMonitor detects growing memory usage during a soak test. The usage
+// after the first round, in which caches of interpreters are warmed up, is
+// the baseline for all following rounds.
+type soakMonitor struct {
+	maxHeapGrowth uint64
+	maxRssGrowth  uint64
+	baseline      *memoryUsage
+}
+
+func newSoakMonitor(context *cli.Context) (*soakMonitor, error) {
+	maxHeapGrowth, err := parseBytes(context.String(maxHeapGrowthFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --%s: %w", maxHeapGrowthFlag.Name, err)
+	}
+	maxRssGrowth, err := parseBytes(context.String(maxRssGrowthFlag.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for --%s: %w", maxRssGrowthFlag.Name, err)
+	}
+	return &soakMonitor{maxHeapGrowth: maxHeapGrowth, maxRssGrowth: maxRssGrowth}, nil
+}
+
+// check records the memory usage observed after a round and returns an error
+// if it grew beyond the tolerated limits compared to the baseline.
+func (m *soakMonitor) check(usage memoryUsage) error {
+	if m.baseline == nil {
+		m.baseline = &usage
+		return nil
+	}
+	if growth := grownBy(m.baseline.heap, usage.heap); growth > m.maxHeapGrowth {
+		return fmt.Errorf("heap grew by %s from %s to %s, exceeding the limit of %s",
+			formatBytes(growth), formatBytes(m.baseline.heap), formatBytes(usage.heap), formatBytes(m.maxHeapGrowth))
+	}
+	if m.baseline.rss == 0 || usage.rss == 0 {
+		return nil
+	}
+	if growth := grownBy(m.baseline.rss, usage.rss); growth > m.maxRssGrowth {
+		return fmt.Errorf("resident set size grew by %s from %s to %s, exceeding the limit of %s",
+			formatBytes(growth), formatBytes(m.baseline.rss), formatBytes(usage.rss), formatBytes(m.maxRssGrowth))
+	}
+	return nil
+}
+
+func grownBy(before, after uint64) uint64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// soak runs rounds of tests with a new seed each until the given deadline is
+// reached or a round requests to stop. After each round, the memory usage is
+// measured and checked by the given monitor.
+func soak(
+	out io.Writer,
+	deadline time.Time,
+	seed uint64,
+	monitor *soakMonitor,
+	measure func() memoryUsage,
+	round func(seed uint64) (stop bool, err error),
+) error {
+	for i := uint64(0); time.Now().Before(deadline); i++ {
+		stop, err := round(seed + i)
+		if err != nil {
+			return err
+		}
+		usage := measure()
+		fmt.Fprintf(out, "Soak round %d completed, %v\n", i+1, usage)
+		if err := monitor.check(usage); err != nil {
+			return fmt.Errorf("memory leak detected in soak round %d: %w", i+1, err)
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+func parseBytes(value string) (uint64, error) {
+	res, err := unitconv.ParsePrefix(value, unitconv.IEC)
+	if err != nil {
+		return 0, err
+	}
+	if res < 0 {
+		return 0, fmt.Errorf("negative size %s", value)
+	}
+	return uint64(res), nil
+}
+
+func formatBytes(value uint64) string {
+	return unitconv.FormatPrefix(float64(value), unitconv.IEC, 1) + "B"
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSoakMonitor_FirstUsageIsBaseline(t *testing.T) {
+	monitor := soakMonitor{}
+	if err := monitor.check(memoryUsage{heap: 1 << 30, rss: 1 << 31}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := monitor.check(memoryUsage{heap: 1 << 30, rss: 1 << 31}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSoakMonitor_GrowthBeyondLimitIsDetected(t *testing.T) {
+	tests := map[string]struct {
+		usage memoryUsage
+		err   string
+	}{
+		"unchanged":        {memoryUsage{heap: 100, rss: 1000}, ""},
+		"shrunk":           {memoryUsage{heap: 50, rss: 500}, ""},
+		"heap within":      {memoryUsage{heap: 110, rss: 1000}, ""},
+		"heap exceeding":   {memoryUsage{heap: 111, rss: 1000}, "heap grew"},
+		"rss within":       {memoryUsage{heap: 100, rss: 1100}, ""},
+		"rss exceeding":    {memoryUsage{heap: 100, rss: 1101}, "resident set size grew"},
+		"rss unknown":      {memoryUsage{heap: 100, rss: 0}, ""},
+		"both exceeding":   {memoryUsage{heap: 200, rss: 2000}, "heap grew"},
+		"heap grew, rss 0": {memoryUsage{heap: 200, rss: 0}, "heap grew"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			monitor := soakMonitor{maxHeapGrowth: 10, maxRssGrowth: 100}
+			if err := monitor.check(memoryUsage{heap: 100, rss: 1000}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			err := monitor.check(test.usage)
+			if test.err == "" && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)) {
+				t.Errorf("expected error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestSoak_RoundsUseConsecutiveSeedsUntilStopped(t *testing.T) {
+	seeds := []uint64{}
+	round := func(seed uint64) (bool, error) {
+		seeds = append(seeds, seed)
+		return len(seeds) == 3, nil
+	}
+	measure := func() memoryUsage { return memoryUsage{heap: 100} }
+
+	var out bytes.Buffer
+	deadline := time.Now().Add(time.Hour)
+	if err := soak(&out, deadline, 42, &soakMonitor{}, measure, round); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := fmt.Sprint([]uint64{42, 43, 44}), fmt.Sprint(seeds); want != got {
+		t.Errorf("unexpected seeds, wanted %s, got %s", want, got)
+	}
+	if want, got := 3, strings.Count(out.String(), "Soak round"); want != got {
+		t.Errorf("unexpected number of reported rounds, wanted %d, got %d", want, got)
+	}
+}
+
+func TestSoak_NoRoundIsRunAfterDeadline(t *testing.T) {
+	round := func(uint64) (bool, error) {
+		t.Fatalf("unexpected round")
+		return true, nil
+	}
+	measure := func() memoryUsage { return memoryUsage{} }
+	deadline := time.Now().Add(-time.Second)
+	if err := soak(&bytes.Buffer{}, deadline, 0, &soakMonitor{}, measure, round); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSoak_GrowingMemoryUsageIsReported(t *testing.T) {
+	heap := uint64(100)
+	measure := func() memoryUsage {
+		heap += 10
+		return memoryUsage{heap: heap}
+	}
+	round := func(uint64) (bool, error) { return false, nil }
+
+	deadline := time.Now().Add(time.Hour)
+	monitor := &soakMonitor{maxHeapGrowth: 25}
+	err := soak(&bytes.Buffer{}, deadline, 0, monitor, measure, round)
+	if err == nil || !strings.Contains(err.Error(), "soak round 4") {
+		t.Errorf("expected memory leak to be detected in round 4, got %v", err)
+	}
+}
+
+func TestSoak_RoundErrorsAreForwarded(t *testing.T) {
+	injected := fmt.Errorf("injected")
+	round := func(uint64) (bool, error) { return false, injected }
+	measure := func() memoryUsage { return memoryUsage{} }
+
+	deadline := time.Now().Add(time.Hour)
+	if err := soak(&bytes.Buffer{}, deadline, 0, &soakMonitor{}, measure, round); err != injected {
+		t.Errorf("unexpected error, wanted %v, got %v", injected, err)
+	}
+}
+
+func TestParseBytes_AcceptsBinaryPrefixes(t *testing.T) {
+	tests := map[string]uint64{
+		"0":     0,
+		"512":   512,
+		"4Ki":   4 << 10,
+		"64Mi":  64 << 20,
+		"1.5Gi": 3 << 29,
+	}
+	for value, want := range tests {
+		got, err := parseBytes(value)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", value, err)
+		}
+		if got != want {
+			t.Errorf("unexpected result for %q, wanted %d, got %d", value, want, got)
+		}
+	}
+	for _, value := range []string{"", "many", "-1Mi"} {
+		if _, err := parseBytes(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
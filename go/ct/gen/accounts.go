@@ -28,6 +28,7 @@ type AccountsGenerator struct {
 	minBalance    []balanceConstraint
 	maxBalance    []balanceConstraint
 	warmCold      []warmColdConstraint
+	nonSelf       []Variable
 }
 
 func NewAccountGenerator() *AccountsGenerator {
@@ -40,6 +41,7 @@ func (g *AccountsGenerator) Clone() *AccountsGenerator {
 		warmCold:      slices.Clone(g.warmCold),
 		minBalance:    slices.Clone(g.minBalance),
 		maxBalance:    slices.Clone(g.maxBalance),
+		nonSelf:       slices.Clone(g.nonSelf),
 	}
 }
 
@@ -51,6 +53,7 @@ func (g *AccountsGenerator) Restore(other *AccountsGenerator) {
 	g.warmCold = slices.Clone(other.warmCold)
 	g.minBalance = slices.Clone(other.minBalance)
 	g.maxBalance = slices.Clone(other.maxBalance)
+	g.nonSelf = slices.Clone(other.nonSelf)
 }
 
 func (g *AccountsGenerator) BindToAddressOfEmptyAccount(address Variable) {
@@ -95,6 +98,14 @@ func (g *AccountsGenerator) BindCold(address Variable) {
 	}
 }
 
+// BindToNonSelfAddress constraints the given variable to be mapped to an
+// address different from the address of the account executing the code.
+func (g *AccountsGenerator) BindToNonSelfAddress(address Variable) {
+	if !slices.Contains(g.nonSelf, address) {
+		g.nonSelf = append(g.nonSelf, address)
+	}
+}
+
 func (g *AccountsGenerator) String() string {
 	var parts []string
 
@@ -132,6 +143,11 @@ func (g *AccountsGenerator) String() string {
 		parts = append(parts, fmt.Sprintf("balance(%v) ≤ %v", con.address, con.value.DecimalString()))
 	}
 
+	sort.Slice(g.nonSelf, func(i, j int) bool { return g.nonSelf[i] < g.nonSelf[j] })
+	for _, address := range g.nonSelf {
+		parts = append(parts, fmt.Sprintf("!self(%v)", address))
+	}
+
 	return "{" + strings.Join(parts, ",") + "}"
 }
 
@@ -230,6 +246,13 @@ func (g *AccountsGenerator) Generate(assignment Assignment, rnd *rand.Rand, acco
 		}
 	}
 
+	// Process non-self constraints.
+	for _, v := range g.nonSelf {
+		if getBoundOrBindNewAddress(v) == accountAddress {
+			return nil, fmt.Errorf("%w, address %v bound to self address", ErrUnsatisfiable, v)
+		}
+	}
+
 	// Apply balance constraints.
 	if err := g.processBalanceConstraints(
 		getBoundOrBindNewAddress,
@@ -252,11 +275,15 @@ func (g *AccountsGenerator) Generate(assignment Assignment, rnd *rand.Rand, acco
 		}
 	}
 
-	// Add balance and code for the account executing the code.
-	if !accountsBuilder.Exists(accountAddress) {
-		accountsBuilder.SetBalance(accountAddress, RandU256(rnd))
+	// Add balance and code for the account executing the code, unless it is
+	// required to be empty, as it is the case while running the init code of
+	// the account.
+	if _, isEmpty := emptyAccounts[accountAddress]; !isEmpty {
+		if !accountsBuilder.Exists(accountAddress) {
+			accountsBuilder.SetBalance(accountAddress, RandU256(rnd))
+		}
+		accountsBuilder.SetCode(accountAddress, RandomBytesOfSize(rnd, 42))
 	}
-	accountsBuilder.SetCode(accountAddress, RandomBytesOfSize(rnd, 42))
 
 	return accountsBuilder.Build(), nil
 }
@@ -375,3 +375,41 @@ func BenchmarkAccountGenWithOutConstraint(b *testing.B) {
 		}
 	}
 }
+
+func TestAccountsGenerator_NonSelfAddressesDifferFromSelfAddress(t *testing.T) {
+	v1 := Variable("v1")
+	self := tosca.Address{1}
+
+	generator := NewAccountGenerator()
+	generator.BindToNonSelfAddress(v1)
+
+	assignment := Assignment{}
+	if _, err := generator.Generate(assignment, rand.New(0), self); err != nil {
+		t.Fatalf("Unexpected error during account generation: %v", err)
+	}
+	if NewAddress(assignment[v1]) == self {
+		t.Errorf("Expected address different from self address, got %v", self)
+	}
+
+	assignment = Assignment{v1: AddressToU256(self)}
+	if _, err := generator.Generate(assignment, rand.New(0), self); !errors.Is(err, ErrUnsatisfiable) {
+		t.Errorf("Expected unsatisfiable constraint, got %v", err)
+	}
+}
+
+func TestAccountsGenerator_SelfAccountCanBeEmpty(t *testing.T) {
+	v1 := Variable("v1")
+	self := tosca.Address{1}
+
+	generator := NewAccountGenerator()
+	generator.BindToAddressOfEmptyAccount(v1)
+
+	assignment := Assignment{v1: AddressToU256(self)}
+	accounts, err := generator.Generate(assignment, rand.New(0), self)
+	if err != nil {
+		t.Fatalf("Unexpected error during account generation: %v", err)
+	}
+	if !accounts.IsEmpty(self) {
+		t.Errorf("Expected self account to be empty, got %v", accounts.GetAccount(self))
+	}
+}
@@ -283,6 +283,11 @@ func (g *StateGenerator) AddBalanceUpperBound(address Variable, value U256) {
 	g.accountsGen.AddBalanceUpperBound(address, value)
 }
 
+// BindToNonSelfAddress wraps AccountsGenerator.BindToNonSelfAddress.
+func (g *StateGenerator) BindToNonSelfAddress(address Variable) {
+	g.accountsGen.BindToNonSelfAddress(address)
+}
+
 // BindToWarmAddress wraps AccountsGenerator.BindWarm.
 func (g *StateGenerator) BindToWarmAddress(key Variable) {
 	g.accountsGen.BindWarm(key)
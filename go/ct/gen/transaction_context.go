@@ -38,36 +38,35 @@ func (t *TransactionContextGenerator) Generate(assignment Assignment, rnd *rand.
 		return nil, ErrUnsatisfiable
 	}
 
-	maxMustHaveBlobHash := uint64(0)
-	minMustNotHaveBlobHash := uint64(math.MaxUint64)
-
-	// process all the bounded variables to find the maximum present and minimum absent values
+	// Derive the range of valid numbers of blob hashes from the bound
+	// variables and the protocol limit.
+	minBlobHashesCount := uint64(0)
+	maxBlobHashesCount := uint64(st.MaxBlobHashes)
 	for variable, hasBlobHash := range t.blobHashVariables {
-		if assignedValue, isBound := assignment[variable]; isBound {
-			if hasBlobHash {
-				if !assignedValue.IsUint64() {
-					return nil, ErrUnsatisfiable
-				}
-				if assignedValue.Uint64() > maxMustHaveBlobHash {
-					maxMustHaveBlobHash = assignedValue.Uint64()
-				}
-			} else {
-				if assignedValue.IsUint64() && assignedValue.Uint64() < minMustNotHaveBlobHash {
-					minMustNotHaveBlobHash = assignedValue.Uint64()
-				}
+		assignedValue, isBound := assignment[variable]
+		if hasBlobHash {
+			if !isBound {
+				minBlobHashesCount = max(minBlobHashesCount, 1)
+				continue
 			}
+			if !assignedValue.IsUint64() || assignedValue.Uint64() >= st.MaxBlobHashes {
+				return nil, ErrUnsatisfiable
+			}
+			minBlobHashesCount = max(minBlobHashesCount, assignedValue.Uint64()+1)
+		} else if isBound && assignedValue.IsUint64() {
+			maxBlobHashesCount = min(maxBlobHashesCount, assignedValue.Uint64())
 		}
 	}
 
-	if maxMustHaveBlobHash > minMustNotHaveBlobHash ||
-		maxMustHaveBlobHash == math.MaxUint64 {
+	if minBlobHashesCount > maxBlobHashesCount {
 		return nil, ErrUnsatisfiable
 	}
 
-	blobHashesCount := maxMustHaveBlobHash
-	if minMustNotHaveBlobHash != 0 {
-		blobHashesCount += 1
+	blobHashesCount, err := NewRangeSolver(minBlobHashesCount, maxBlobHashesCount).Generate(rnd)
+	if err != nil {
+		return nil, err
 	}
+
 	for variable, hasBlobHash := range t.blobHashVariables {
 		// the bounded variables are dealt with above
 		if _, isBound := assignment[variable]; !isBound {
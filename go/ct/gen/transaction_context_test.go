@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
 	"pgregory.net/rand"
 )
@@ -250,6 +251,12 @@ func TestTransactionContextGenerator_GenerateUnsatisfiable(t *testing.T) {
 				txCtxGen.IsPresentBlobHashIndex(Variable("v1"))
 			},
 		},
+		"assigned-beyond-limit-present": {
+			setup: func(txCtxGen *TransactionContextGenerator, assignment Assignment) {
+				assignment[Variable("v1")] = common.NewU256(st.MaxBlobHashes)
+				txCtxGen.IsPresentBlobHashIndex(Variable("v1"))
+			},
+		},
 		"assigned-max-present": {
 			setup: func(txCtxGen *TransactionContextGenerator, assignment Assignment) {
 				assignment[Variable("v1")] = common.NewU256(math.MaxUint64)
@@ -277,3 +284,23 @@ func TestTransactionContextGenerator_GenerateUnsatisfiable(t *testing.T) {
 		})
 	}
 }
+
+func TestTransactionContextGenerator_NumberOfBlobHashesIsLimited(t *testing.T) {
+	rnd := rand.New(0)
+	counts := map[int]bool{}
+	for i := 0; i < 1000; i++ {
+		txCtxGen := NewTransactionContextGenerator()
+		txCtxGen.IsAbsentBlobHashIndex(Variable("v1"))
+		txCtx, err := txCtxGen.Generate(Assignment{}, rnd)
+		if err != nil {
+			t.Fatalf("Error generating transaction context: %v", err)
+		}
+		if len(txCtx.BlobHashes) > st.MaxBlobHashes {
+			t.Fatalf("Too many blob hashes: %d", len(txCtx.BlobHashes))
+		}
+		counts[len(txCtx.BlobHashes)] = true
+	}
+	if want, got := st.MaxBlobHashes+1, len(counts); want != got {
+		t.Errorf("Expected all %d possible numbers of blob hashes to be generated, got %v", want, counts)
+	}
+}
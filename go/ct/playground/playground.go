@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package playground provides utilities for executing individual instructions
+// in a synthetic context on any EVM implementation supporting the stepping
+// interface of the conformance tests. It is intended for exploring the effects
+// of instructions, debugging interpreters, and querying gas costs.
+package playground
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/ct"
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// Setup describes the context in which an instruction is executed. Any part
+// of the execution environment not covered by the setup, like accounts or
+// block parameters, is initialized with the defaults of st.NewState. Only the
+// timestamp of the block is set to the fork time of the revision, such that
+// implementations enabling revisions by time consider the revision active.
+type Setup struct {
+	Revision tosca.Revision
+	// Stack lists the values on the stack, starting with the top element.
+	Stack []U256
+	// Memory is the initial content of the memory. It is zero-padded to a
+	// multiple of 32 bytes to match the word-granular memory of the EVM.
+	Memory []byte
+	// Gas is the amount of gas available for the execution.
+	Gas tosca.Gas
+	// Data is placed in the code after the instruction and provides, for
+	// instance, the value pushed by PUSH instructions. Missing data is treated
+	// like a truncated code.
+	Data []byte
+	// ReadOnly marks the execution as part of a static call.
+	ReadOnly bool
+}
+
+// Result summarizes the execution of a single instruction.
+type Result struct {
+	// State is the state after the execution of the instruction.
+	State *st.State
+	// GasUsed is the amount of gas charged for the instruction. Failing
+	// instructions consume all of the available gas.
+	GasUsed tosca.Gas
+}
+
+// Run executes exactly the given instruction with the given setup on the
+// given EVM implementation and reports the resulting state.
+func Run(evm ct.Evm, op vm.OpCode, setup Setup) (Result, error) {
+	if len(setup.Stack) > st.MaxStackSize {
+		return Result{}, fmt.Errorf("stack size %d exceeds maximum of %d", len(setup.Stack), st.MaxStackSize)
+	}
+	if setup.Gas < 0 || setup.Gas > st.MaxGasUsedByCt {
+		return Result{}, fmt.Errorf("gas %d out of range [0, %d]", setup.Gas, st.MaxGasUsedByCt)
+	}
+
+	code := append([]byte{byte(op)}, setup.Data...)
+	state := st.NewState(st.NewCode(code))
+	state.Revision = setup.Revision
+	state.BlockContext.TimeStamp = GetForkTime(setup.Revision)
+	state.ReadOnly = setup.ReadOnly
+	state.Gas = setup.Gas
+
+	stack := st.NewStackWithSize(0)
+	for i := len(setup.Stack) - 1; i >= 0; i-- {
+		stack.Push(setup.Stack[i])
+	}
+	state.Stack = stack
+
+	memory := make([]byte, (len(setup.Memory)+31)/32*32)
+	copy(memory, setup.Memory)
+	state.Memory = st.NewMemory(memory...)
+
+	res, err := evm.StepN(state, 1)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		State:   res,
+		GasUsed: setup.Gas - res.Gas,
+	}, nil
+}
+
+// GasCost returns the amount of gas charged for executing the given
+// instruction with the given setup. An error is reported if the instruction
+// fails, since the costs of failing instructions are not meaningful.
+func GasCost(evm ct.Evm, op vm.OpCode, setup Setup) (tosca.Gas, error) {
+	res, err := Run(evm, op, setup)
+	if err != nil {
+		return 0, err
+	}
+	if res.State.Status == st.Failed {
+		return 0, fmt.Errorf("execution of %v failed", op)
+	}
+	return res.GasUsed, nil
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package playground
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/ct"
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+var evms = map[string]ct.Evm{
+	"lfvm": lfvm.NewConformanceTestingTarget(),
+	"geth": geth.NewConformanceTestingTarget(),
+}
+
+func TestRun_ExecutesSingleInstruction(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			res, err := Run(evm, vm.SUB, Setup{
+				Revision: tosca.R13_Cancun,
+				Stack:    []U256{NewU256(5), NewU256(3), NewU256(7)},
+				Gas:      100,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := st.Running, res.State.Status; want != got {
+				t.Errorf("unexpected status, wanted %v, got %v", want, got)
+			}
+			if want, got := uint16(1), res.State.Pc; want != got {
+				t.Errorf("unexpected pc, wanted %d, got %d", want, got)
+			}
+			if want, got := 2, res.State.Stack.Size(); want != got {
+				t.Fatalf("unexpected stack size, wanted %d, got %d", want, got)
+			}
+			if want, got := NewU256(2), res.State.Stack.Get(0); want != got {
+				t.Errorf("unexpected top of stack, wanted %v, got %v", want, got)
+			}
+			if want, got := NewU256(7), res.State.Stack.Get(1); want != got {
+				t.Errorf("unexpected second stack element, wanted %v, got %v", want, got)
+			}
+			if want, got := tosca.Gas(3), res.GasUsed; want != got {
+				t.Errorf("unexpected gas usage, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestRun_MemoryIsInitializedAndPadded(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			res, err := Run(evm, vm.MLOAD, Setup{
+				Revision: tosca.R13_Cancun,
+				Stack:    []U256{NewU256(1)},
+				Memory:   []byte{1, 2},
+				Gas:      100,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want := NewU256FromBytes(append([]byte{2}, make([]byte, 31)...)...)
+			if got := res.State.Stack.Get(0); want != got {
+				t.Errorf("unexpected loaded value, wanted %v, got %v", want, got)
+			}
+			// Loading from offset 1 expands the memory by one word.
+			if want, got := 64, res.State.Memory.Size(); want != got {
+				t.Errorf("unexpected memory size, wanted %d, got %d", want, got)
+			}
+			if want, got := tosca.Gas(3+3), res.GasUsed; want != got {
+				t.Errorf("unexpected gas usage, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestRun_DataIsPlacedAfterInstruction(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			res, err := Run(evm, vm.PUSH2, Setup{
+				Revision: tosca.R13_Cancun,
+				Gas:      100,
+				Data:     []byte{0x12, 0x34},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := NewU256(0x1234), res.State.Stack.Get(0); want != got {
+				t.Errorf("unexpected pushed value, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestRun_RejectsInvalidSetups(t *testing.T) {
+	tests := map[string]Setup{
+		"negative gas":    {Gas: -1},
+		"excessive gas":   {Gas: st.MaxGasUsedByCt + 1},
+		"excessive stack": {Stack: make([]U256, st.MaxStackSize+1)},
+	}
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Run(evms["lfvm"], vm.STOP, setup); err == nil {
+				t.Errorf("expected setup to be rejected")
+			}
+		})
+	}
+}
+
+func TestRun_ForwardsErrorsOfEvm(t *testing.T) {
+	injectedErr := errors.New("injected error")
+	if _, err := Run(failingEvm{injectedErr}, vm.STOP, Setup{}); !errors.Is(err, injectedErr) {
+		t.Errorf("unexpected error, wanted %v, got %v", injectedErr, err)
+	}
+}
+
+func TestGasCost_ReportsCostsOfInstructions(t *testing.T) {
+	tests := map[string]struct {
+		op    vm.OpCode
+		setup Setup
+		want  tosca.Gas
+	}{
+		"add": {vm.ADD, Setup{Stack: []U256{NewU256(1), NewU256(2)}}, 3},
+		"exp": {vm.EXP, Setup{Stack: []U256{NewU256(2), NewU256(256)}}, 10 + 2*50},
+		"cold balance": {
+			vm.BALANCE, Setup{Revision: tosca.R09_Berlin, Stack: []U256{NewU256(42)}}, 2600,
+		},
+		"balance before Berlin": {
+			vm.BALANCE, Setup{Revision: tosca.R07_Istanbul, Stack: []U256{NewU256(42)}}, 700,
+		},
+	}
+	for evmName, evm := range evms {
+		for name, test := range tests {
+			t.Run(evmName+"/"+name, func(t *testing.T) {
+				test.setup.Gas = 10_000
+				got, err := GasCost(evm, test.op, test.setup)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if test.want != got {
+					t.Errorf("unexpected gas costs, wanted %d, got %d", test.want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestGasCost_FailingInstructionsAreReported(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			_, err := GasCost(evm, vm.ADD, Setup{Revision: tosca.R13_Cancun, Gas: 100})
+			if err == nil {
+				t.Errorf("expected stack underflow to be reported")
+			}
+		})
+	}
+}
+
+func TestRun_TimeBasedRevisionsAreEnabled(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			// PUSH0 is introduced by Shanghai, which is enabled by time.
+			res, err := Run(evm, vm.PUSH0, Setup{Revision: tosca.R12_Shanghai, Gas: 100})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := st.Running, res.State.Status; want != got {
+				t.Errorf("unexpected status, wanted %v, got %v", want, got)
+			}
+			if want, got := GetForkTime(tosca.R12_Shanghai), res.State.BlockContext.TimeStamp; want != got {
+				t.Errorf("unexpected timestamp, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+type failingEvm struct {
+	err error
+}
+
+func (e failingEvm) StepN(*st.State, int) (*st.State, error) {
+	return nil, e.err
+}
@@ -333,6 +333,32 @@ func IsRevisionCondition(condition Condition) bool {
 	return ok
 }
 
+// GetRevisionBounds computes the range of revisions the given condition is
+// restricted to by its revision conditions. Conditions without a revision
+// condition are reported to cover all revisions, including the unknown next
+// revision. If revision conditions contradict each other, the resulting min
+// is greater than max.
+func GetRevisionBounds(condition Condition) (min, max tosca.Revision) {
+	switch c := condition.(type) {
+	case *revisionBounds:
+		return c.min, c.max
+	case *conjunction:
+		min, max = tosca.Revision(0), R99_UnknownNextRevision
+		for _, cur := range c.conditions {
+			curMin, curMax := GetRevisionBounds(cur)
+			if curMin > min {
+				min = curMin
+			}
+			if curMax < max {
+				max = curMax
+			}
+		}
+		return min, max
+	default:
+		return tosca.Revision(0), R99_UnknownNextRevision
+	}
+}
+
 // AnyKnownRevision restricts the revision to any revision covered by the CT specification.
 func AnyKnownRevision() Condition {
 	return RevisionBounds(MinRevision, NewestSupportedRevision)
@@ -887,6 +913,84 @@ func (c *hasNotSelfDestructed) String() string {
 	return "hasNotSelfDestructed()"
 }
 
+////////////////////////////////////////////////////////////
+// Is Self Address
+
+type isSelfAddress struct {
+	address BindableExpression[U256]
+}
+
+// IsSelfAddress is satisfied if the given address refers to the account
+// executing the current code. Together with other conditions on the current
+// account, like HasSelfDestructed, it enables the specification of
+// interactions between operations within a transaction.
+func IsSelfAddress(address BindableExpression[U256]) Condition {
+	return &isSelfAddress{address}
+}
+
+func (c *isSelfAddress) Check(s *st.State) (bool, error) {
+	address, err := c.address.Eval(s)
+	if err != nil {
+		return false, err
+	}
+	return NewAddress(address) == s.CallContext.AccountAddress, nil
+}
+
+func (c *isSelfAddress) Restrict(generator *gen.StateGenerator) {
+	address := c.address.GetVariable()
+	c.address.BindTo(generator)
+	generator.BindToSelfAddress(address)
+}
+
+func (c *isSelfAddress) GetTestValues() []TestValue {
+	property := Property(fmt.Sprintf("isSelf(%v)", c.address))
+	restrict := func(generator *gen.StateGenerator, isSelf bool) {
+		if isSelf {
+			IsSelfAddress(c.address).Restrict(generator)
+		} else {
+			IsNotSelfAddress(c.address).Restrict(generator)
+		}
+	}
+	return []TestValue{
+		NewTestValue(property, boolDomain{}, true, restrict),
+		NewTestValue(property, boolDomain{}, false, restrict),
+	}
+}
+
+func (c *isSelfAddress) String() string {
+	return fmt.Sprintf("isSelf(%v)", c.address)
+}
+
+////////////////////////////////////////////////////////////
+// Is Not Self Address
+
+type isNotSelfAddress struct {
+	address BindableExpression[U256]
+}
+
+func IsNotSelfAddress(address BindableExpression[U256]) Condition {
+	return &isNotSelfAddress{address}
+}
+
+func (c *isNotSelfAddress) Check(s *st.State) (bool, error) {
+	res, err := IsSelfAddress(c.address).Check(s)
+	return !res, err
+}
+
+func (c *isNotSelfAddress) Restrict(generator *gen.StateGenerator) {
+	address := c.address.GetVariable()
+	c.address.BindTo(generator)
+	generator.BindToNonSelfAddress(address)
+}
+
+func (c *isNotSelfAddress) GetTestValues() []TestValue {
+	return IsSelfAddress(c.address).GetTestValues()
+}
+
+func (c *isNotSelfAddress) String() string {
+	return fmt.Sprintf("!isSelf(%v)", c.address)
+}
+
 ////////////////////////////////////////////////////////////
 // In Range 256 From Current Block
 
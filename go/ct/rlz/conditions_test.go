@@ -325,6 +325,30 @@ func TestHasSelfDestructedCondition_CheckSelfDestructed(t *testing.T) {
 	}
 }
 
+func TestIsSelfAddressCondition_CheckSelfAddress(t *testing.T) {
+	state := st.NewState(st.NewCode([]byte{}))
+	state.CallContext.AccountAddress = NewAddress(NewU256(0x01))
+	state.Stack = st.NewStack(NewU256(0x01))
+
+	isSelf, err := IsSelfAddress(Param(0)).Check(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSelf {
+		t.Fatal("isSelfAddress check failed")
+	}
+
+	state.Stack = st.NewStack(NewU256(0x02))
+
+	isNotSelf, err := IsNotSelfAddress(Param(0)).Check(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isNotSelf {
+		t.Fatal("isNotSelfAddress check failed")
+	}
+}
+
 func TestCondition_InOutRange256FromCurrentBlock_Check(t *testing.T) {
 	gen := gen.NewStateGenerator()
 	rnd := rand.New(0)
@@ -576,6 +600,39 @@ func TestCondition_IsRevisionCondition(t *testing.T) {
 	}
 }
 
+func TestCondition_GetRevisionBounds(t *testing.T) {
+	tests := map[string]struct {
+		condition Condition
+		min, max  tosca.Revision
+	}{
+		"IsRevision":        {IsRevision(tosca.R10_London), tosca.R10_London, tosca.R10_London},
+		"RevisionBounds":    {RevisionBounds(tosca.R09_Berlin, tosca.R11_Paris), tosca.R09_Berlin, tosca.R11_Paris},
+		"no revision":       {IsCode(Param(0)), 0, R99_UnknownNextRevision},
+		"empty conjunction": {And(), 0, R99_UnknownNextRevision},
+		"conjunction": {
+			And(IsCode(Param(0)), RevisionBounds(tosca.R09_Berlin, tosca.R11_Paris)),
+			tosca.R09_Berlin, tosca.R11_Paris,
+		},
+		"intersection": {
+			And(AnyKnownRevision(), RevisionBounds(tosca.R09_Berlin, R99_UnknownNextRevision)),
+			tosca.R09_Berlin, NewestSupportedRevision,
+		},
+		"contradiction": {
+			And(IsRevision(tosca.R12_Shanghai), IsRevision(tosca.R09_Berlin)),
+			tosca.R12_Shanghai, tosca.R09_Berlin,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			min, max := GetRevisionBounds(test.condition)
+			if min != test.min || max != test.max {
+				t.Errorf("unexpected revision bounds, wanted %v-%v, got %v-%v", test.min, test.max, min, max)
+			}
+		})
+	}
+}
+
 func TestCondition_GetTestValues(t *testing.T) {
 
 	inOutofRangeTestValues := []any{math.MinInt64, -1, 0, 1, 255, 256, 257, math.MaxInt64}
@@ -599,6 +656,8 @@ func TestCondition_GetTestValues(t *testing.T) {
 		{IsAddressCold(Param(0)), []any{false}},
 		{HasSelfDestructed(), []any{true, false}},
 		{HasNotSelfDestructed(), []any{true, false}},
+		{IsSelfAddress(Param(0)), []any{true, false}},
+		{IsNotSelfAddress(Param(0)), []any{true, false}},
 	}
 
 	for _, test := range tests {
@@ -616,6 +675,8 @@ func TestCondition_RestricAndCheck(t *testing.T) {
 		IsRevision(tosca.R10_London),
 		HasSelfDestructed(),
 		HasNotSelfDestructed(),
+		IsSelfAddress(Param(0)),
+		IsNotSelfAddress(Param(0)),
 		InRange256FromCurrentBlock(Param(0)),
 		OutOfRange256FromCurrentBlock(Param(0)),
 		BindTransientStorageToNonZero(Param(0)),
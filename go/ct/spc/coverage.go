@@ -0,0 +1,104 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package spc
+
+import (
+	"slices"
+
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/rlz"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// CoverageReport is a matrix listing the rules covering each combination of
+// a valid operation and a revision supported by the specification. It is
+// intended to be exported as JSON for the consumption by other tools.
+type CoverageReport struct {
+	// Revisions lists the names of the revisions covered by the report.
+	Revisions []string `json:"revisions"`
+	// Operations lists the coverage of each valid operation.
+	Operations []OperationCoverage `json:"operations"`
+	// GenericRules lists the rules not bound to a specific operation.
+	GenericRules []string `json:"generic_rules"`
+	// Covered is the number of operation/revision combinations with rules.
+	Covered int `json:"covered"`
+	// Total is the number of operation/revision combinations.
+	Total int `json:"total"`
+}
+
+// OperationCoverage describes the rules covering a single operation.
+type OperationCoverage struct {
+	// Operation is the name of the covered operation.
+	Operation string `json:"operation"`
+	// Rules lists the names of the rules for the operation by revision.
+	Rules map[string][]string `json:"rules"`
+	// Missing lists the revisions without any rule for the operation.
+	Missing []string `json:"missing"`
+}
+
+// GetCoverage maps the given rules to the operations and revisions they are
+// applicable to. Operations are identified through the conditions on the
+// operation at the program counter, revisions through the revision bounds of
+// the rule conditions.
+func GetCoverage(rules []rlz.Rule) CoverageReport {
+	revisions := []tosca.Revision{}
+	for revision := MinRevision; revision <= NewestSupportedRevision; revision++ {
+		revisions = append(revisions, revision)
+	}
+
+	report := CoverageReport{GenericRules: []string{}}
+	for _, revision := range revisions {
+		report.Revisions = append(report.Revisions, revision.String())
+	}
+
+	rulesByOp := map[string][]rlz.Rule{}
+	for _, rule := range rules {
+		op := ruleToOpString(rule)
+		if op == "noOp" {
+			report.GenericRules = append(report.GenericRules, rule.Name)
+			continue
+		}
+		rulesByOp[op] = append(rulesByOp[op], rule)
+	}
+	slices.Sort(report.GenericRules)
+
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		if !vm.IsValid(op) {
+			continue
+		}
+		coverage := OperationCoverage{
+			Operation: op.String(),
+			Rules:     map[string][]string{},
+			Missing:   []string{},
+		}
+		for _, revision := range revisions {
+			names := []string{}
+			for _, rule := range rulesByOp[op.String()] {
+				min, max := rlz.GetRevisionBounds(rule.Condition)
+				if min <= revision && revision <= max {
+					names = append(names, rule.Name)
+				}
+			}
+			report.Total++
+			if len(names) == 0 {
+				coverage.Missing = append(coverage.Missing, revision.String())
+				continue
+			}
+			slices.Sort(names)
+			coverage.Rules[revision.String()] = names
+			report.Covered++
+		}
+		report.Operations = append(report.Operations, coverage)
+	}
+	return report
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package spc
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	. "github.com/Fantom-foundation/Tosca/go/ct/rlz"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestGetCoverage_MapsRulesToOperationsAndRevisions(t *testing.T) {
+	rules := []Rule{
+		{
+			Name:      "add",
+			Condition: And(AnyKnownRevision(), Eq(Op(Pc()), vm.ADD)),
+		},
+		{
+			Name:      "push0",
+			Condition: And(RevisionBounds(tosca.R12_Shanghai, NewestSupportedRevision), Eq(Op(Pc()), vm.PUSH0)),
+		},
+		{
+			Name:      "stopped",
+			Condition: Eq(Status(), st.Stopped),
+		},
+	}
+
+	report := GetCoverage(rules)
+
+	if want, got := int(NewestSupportedRevision-MinRevision+1), len(report.Revisions); want != got {
+		t.Fatalf("unexpected number of revisions, wanted %d, got %d", want, got)
+	}
+	if want, got := []string{"stopped"}, report.GenericRules; !slices.Equal(want, got) {
+		t.Errorf("unexpected generic rules, wanted %v, got %v", want, got)
+	}
+
+	coverage := map[string]OperationCoverage{}
+	for _, cur := range report.Operations {
+		coverage[cur.Operation] = cur
+	}
+	if want, got := len(report.Revisions), len(coverage["ADD"].Rules); want != got {
+		t.Errorf("unexpected number of revisions covered for ADD, wanted %d, got %d", want, got)
+	}
+	if want, got := []string{"add"}, coverage["ADD"].Rules[tosca.R10_London.String()]; !slices.Equal(want, got) {
+		t.Errorf("unexpected rules for ADD, wanted %v, got %v", want, got)
+	}
+	if len(coverage["ADD"].Missing) != 0 {
+		t.Errorf("unexpected missing revisions for ADD: %v", coverage["ADD"].Missing)
+	}
+
+	push0 := coverage["PUSH0"]
+	if _, found := push0.Rules[tosca.R11_Paris.String()]; found {
+		t.Errorf("PUSH0 should not be covered in Paris")
+	}
+	if !slices.Contains(push0.Missing, tosca.R11_Paris.String()) {
+		t.Errorf("Paris should be reported missing for PUSH0, got %v", push0.Missing)
+	}
+	if want, got := []string{"push0"}, push0.Rules[tosca.R12_Shanghai.String()]; !slices.Equal(want, got) {
+		t.Errorf("unexpected rules for PUSH0, wanted %v, got %v", want, got)
+	}
+
+	if _, found := coverage["MUL"]; !found || len(coverage["MUL"].Rules) != 0 {
+		t.Errorf("MUL should be listed without any rules")
+	}
+	wantCovered := len(report.Revisions) + int(NewestSupportedRevision-tosca.R12_Shanghai+1)
+	if want, got := wantCovered, report.Covered; want != got {
+		t.Errorf("unexpected number of covered combinations, wanted %d, got %d", want, got)
+	}
+	if want, got := len(report.Operations)*len(report.Revisions), report.Total; want != got {
+		t.Errorf("unexpected total number of combinations, wanted %d, got %d", want, got)
+	}
+}
+
+func TestGetCoverage_SpecificationCoversAllOperationsInAllRevisions(t *testing.T) {
+	report := GetCoverage(Spec.GetRules())
+	for _, coverage := range report.Operations {
+		if len(coverage.Missing) != 0 {
+			t.Errorf("operation %v is not covered in revisions %v", coverage.Operation, coverage.Missing)
+		}
+	}
+	if report.Covered != report.Total {
+		t.Errorf("unexpected coverage, %d of %d combinations covered", report.Covered, report.Total)
+	}
+}
+
+func TestCoverageReport_CanBeEncodedAsJson(t *testing.T) {
+	report := GetCoverage(Spec.GetRules())
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to encode report: %v", err)
+	}
+	var restored CoverageReport
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if want, got := report.Covered, restored.Covered; want != got {
+		t.Errorf("unexpected number of covered combinations, wanted %d, got %d", want, got)
+	}
+	if want, got := len(report.Operations), len(restored.Operations); want != got {
+		t.Errorf("unexpected number of operations, wanted %d, got %d", want, got)
+	}
+}
@@ -18,6 +18,7 @@ import (
 
 	"github.com/Fantom-foundation/Tosca/go/ct/rlz"
 	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 	"pgregory.net/rand"
 )
 
@@ -172,3 +173,15 @@ func FilterRules(rules []rlz.Rule, filter *regexp.Regexp) []rlz.Rule {
 	}
 	return res
 }
+
+// FilterRulesForOperation returns the rules bound to the given operation
+// through a condition on the operation at the program counter.
+func FilterRulesForOperation(rules []rlz.Rule, op vm.OpCode) []rlz.Rule {
+	res := make([]rlz.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if ruleToOpString(rule) == op.String() {
+			res = append(res, rule)
+		}
+	}
+	return res
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/Fantom-foundation/Tosca/go/ct/gen"
 	"github.com/Fantom-foundation/Tosca/go/ct/rlz"
 	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
 // Test condition used for state enumeration tests
@@ -196,6 +197,20 @@ func TestEnumeration_FilterRules(t *testing.T) {
 	}
 }
 
+func TestEnumeration_FilterRulesForOperation(t *testing.T) {
+	for _, op := range []vm.OpCode{vm.ADD, vm.SSTORE, vm.BLOBHASH} {
+		rules := FilterRulesForOperation(Spec.GetRules(), op)
+		if len(rules) == 0 {
+			t.Errorf("no rules found for %v", op)
+		}
+		for _, rule := range rules {
+			if !strings.Contains(rule.Condition.String(), "code[PC] = "+op.String()) {
+				t.Errorf("rule %v is not bound to %v", rule.Name, op)
+			}
+		}
+	}
+}
+
 func TestEnumeration_EmptyRules(t *testing.T) {
 	numJobs := 1
 	seed := 0
@@ -285,65 +285,70 @@ func getAllRules() []Rule {
 
 	// --- BALANCE ---
 
-	// cold
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.BALANCE,
-		staticGas: 0 + 2600, // 2600 dynamic cost for cold address
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressCold(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			s.Stack.Push(s.Accounts.GetBalance(address))
-			s.Accounts.MarkWarm(address)
-		},
-		name: "_cold",
-	})...)
-
-	// warm
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.BALANCE,
-		staticGas: 0 + 100, // 100 dynamic cost for warm address
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressWarm(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			s.Stack.Push(s.Accounts.GetBalance(address))
-		},
-		name: "_warm",
-	})...)
-
-	// pre Berlin
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.BALANCE,
-		staticGas: 700,
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			IsRevision(tosca.R07_Istanbul),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			s.Stack.Push(s.Accounts.GetBalance(address))
-		},
-		name: "_preBerlin",
-	})...)
+	for _, target := range getTargetAccounts(Param(0)) {
+		// cold
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.BALANCE,
+			staticGas: 0 + 2600, // 2600 dynamic cost for cold address
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressCold(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				s.Stack.Push(s.Accounts.GetBalance(address))
+				s.Accounts.MarkWarm(address)
+			},
+			name: "_cold" + target.name,
+		})...)
+
+		// warm
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.BALANCE,
+			staticGas: 0 + 100, // 100 dynamic cost for warm address
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressWarm(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				s.Stack.Push(s.Accounts.GetBalance(address))
+			},
+			name: "_warm" + target.name,
+		})...)
+
+		// pre Berlin
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.BALANCE,
+			staticGas: 700,
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				IsRevision(tosca.R07_Istanbul),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				s.Stack.Push(s.Accounts.GetBalance(address))
+			},
+			name: "_preBerlin" + target.name,
+		})...)
+	}
 
 	// --- MLOAD ---
 
@@ -1022,6 +1027,9 @@ func getAllRules() []Rule {
 
 	// --- BLOCKHASH ---
 
+	// The block hashes stored in the history storage contract by EIP-2935
+	// are not consulted by BLOCKHASH, which retains its 256-block window.
+
 	rules = append(rules, rulesFor(instruction{
 		op:        vm.BLOCKHASH,
 		staticGas: 20,
@@ -1105,132 +1113,142 @@ func getAllRules() []Rule {
 
 	// --- EXTCODESIZE ---
 
-	// cold
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODESIZE,
-		staticGas: 0 + 2600, // 2600 dynamic cost for cold address
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressCold(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			size := s.Accounts.GetCode(address).Length()
-			s.Stack.Push(NewU256(uint64(size)))
-			s.Accounts.MarkWarm(address)
-		},
-		name: "_cold",
-	})...)
-
-	// warm
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODESIZE,
-		staticGas: 0 + 100, // 100 dynamic cost for warm address
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressWarm(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			size := s.Accounts.GetCode(address).Length()
-			s.Stack.Push(NewU256(uint64(size)))
-		},
-		name: "_warm",
-	})...)
-
-	// pre Berlin
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODESIZE,
-		staticGas: 700,
-		pops:      1,
-		pushes:    1,
-		conditions: []Condition{
-			IsRevision(tosca.R07_Istanbul),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-		},
-		effect: func(s *st.State) {
-			address := NewAddress(s.Stack.Pop())
-			size := s.Accounts.GetCode(address).Length()
-			s.Stack.Push(NewU256(uint64(size)))
-		},
-		name: "_preBerlin",
-	})...)
+	for _, target := range getTargetAccounts(Param(0)) {
+		// cold
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODESIZE,
+			staticGas: 0 + 2600, // 2600 dynamic cost for cold address
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressCold(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				size := s.Accounts.GetCode(address).Length()
+				s.Stack.Push(NewU256(uint64(size)))
+				s.Accounts.MarkWarm(address)
+			},
+			name: "_cold" + target.name,
+		})...)
+
+		// warm
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODESIZE,
+			staticGas: 0 + 100, // 100 dynamic cost for warm address
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressWarm(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				size := s.Accounts.GetCode(address).Length()
+				s.Stack.Push(NewU256(uint64(size)))
+			},
+			name: "_warm" + target.name,
+		})...)
+
+		// pre Berlin
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODESIZE,
+			staticGas: 700,
+			pops:      1,
+			pushes:    1,
+			conditions: []Condition{
+				IsRevision(tosca.R07_Istanbul),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+			},
+			effect: func(s *st.State) {
+				address := NewAddress(s.Stack.Pop())
+				size := s.Accounts.GetCode(address).Length()
+				s.Stack.Push(NewU256(uint64(size)))
+			},
+			name: "_preBerlin" + target.name,
+		})...)
+	}
 
 	// --- EXTCODECOPY ---
 
-	// cold
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODECOPY,
-		staticGas: 2600,
-		pops:      4,
-		pushes:    0,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressCold(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-			MemoryOffsetParameter{},
-			DataOffsetParameter{},
-			SizeParameter{}},
-		effect: func(s *st.State) {
-			extCodeCopyEffect(s, true)
-		},
-		name: "_cold",
-	})...)
-
-	// warm
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODECOPY,
-		staticGas: 100,
-		pops:      4,
-		pushes:    0,
-		conditions: []Condition{
-			RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
-			IsAddressWarm(Param(0)),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-			MemoryOffsetParameter{},
-			DataOffsetParameter{},
-			SizeParameter{}},
-		effect: func(s *st.State) {
-			extCodeCopyEffect(s, false)
-		},
-		name: "_warm",
-	})...)
-
-	// pre Berlin
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.EXTCODECOPY,
-		staticGas: 700,
-		pops:      4,
-		pushes:    0,
-		conditions: []Condition{
-			IsRevision(tosca.R07_Istanbul),
-		},
-		parameters: []Parameter{
-			AddressParameter{},
-			MemoryOffsetParameter{},
-			DataOffsetParameter{},
-			SizeParameter{}},
-		effect: func(s *st.State) {
-			extCodeCopyEffect(s, false)
-		},
-		name: "_preBerlin",
-	})...)
+	for _, target := range getTargetAccounts(Param(0)) {
+		// cold
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODECOPY,
+			staticGas: 2600,
+			pops:      4,
+			pushes:    0,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressCold(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+				MemoryOffsetParameter{},
+				DataOffsetParameter{},
+				SizeParameter{}},
+			effect: func(s *st.State) {
+				extCodeCopyEffect(s, true)
+			},
+			name: "_cold" + target.name,
+		})...)
+
+		// warm
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODECOPY,
+			staticGas: 100,
+			pops:      4,
+			pushes:    0,
+			conditions: []Condition{
+				RevisionBounds(tosca.R09_Berlin, NewestSupportedRevision),
+				IsAddressWarm(Param(0)),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+				MemoryOffsetParameter{},
+				DataOffsetParameter{},
+				SizeParameter{}},
+			effect: func(s *st.State) {
+				extCodeCopyEffect(s, false)
+			},
+			name: "_warm" + target.name,
+		})...)
+
+		// pre Berlin
+		rules = append(rules, rulesFor(instruction{
+			op:        vm.EXTCODECOPY,
+			staticGas: 700,
+			pops:      4,
+			pushes:    0,
+			conditions: []Condition{
+				IsRevision(tosca.R07_Istanbul),
+				target.condition,
+			},
+			parameters: []Parameter{
+				AddressParameter{},
+				MemoryOffsetParameter{},
+				DataOffsetParameter{},
+				SizeParameter{}},
+			effect: func(s *st.State) {
+				extCodeCopyEffect(s, false)
+			},
+			name: "_preBerlin" + target.name,
+		})...)
+	}
 
 	// --- TIMESTAMP ---
 
@@ -1344,7 +1362,7 @@ func getAllRules() []Rule {
 
 	for _, revision := range tosca.GetAllKnownRevisions() {
 		for _, warm := range []bool{true, false} {
-			for _, isEmpty := range []bool{true, false} {
+			for _, target := range getExtCodeHashTargetAccounts(Param(0)) {
 				name := "_" + revision.String()
 				staticGas := tosca.Gas(100) // warm access
 				conditions := []Condition{IsRevision(revision)}
@@ -1362,13 +1380,8 @@ func getAllRules() []Rule {
 					staticGas = 700
 				}
 
-				if isEmpty {
-					name += "_empty"
-					conditions = append(conditions, AccountIsEmpty(Param(0)))
-				} else {
-					name += "_not_empty"
-					conditions = append(conditions, AccountIsNotEmpty(Param(0)))
-				}
+				name += target.name
+				conditions = append(conditions, target.condition)
 
 				rules = append(rules, rulesFor(instruction{
 					op:         vm.EXTCODEHASH,
@@ -1534,17 +1547,29 @@ func getAllRules() []Rule {
 
 	// --- SELFBALANCE ---
 
-	rules = append(rules, rulesFor(instruction{
-		op:        vm.SELFBALANCE,
-		staticGas: 5,
-		pops:      0,
-		pushes:    1,
-		effect: func(s *st.State) {
-			address := s.CallContext.AccountAddress
-			balance := s.Accounts.GetBalance(address)
-			s.Stack.Push(balance)
-		},
-	})...)
+	// The balance of self-destructed accounts remains accessible until the end
+	// of the transaction.
+	for _, selfDestructed := range []bool{false, true} {
+		name := ""
+		condition := HasNotSelfDestructed()
+		if selfDestructed {
+			name = "_self_destructed"
+			condition = HasSelfDestructed()
+		}
+		rules = append(rules, rulesFor(instruction{
+			op:         vm.SELFBALANCE,
+			name:       name,
+			staticGas:  5,
+			pops:       0,
+			pushes:     1,
+			conditions: []Condition{condition},
+			effect: func(s *st.State) {
+				address := s.CallContext.AccountAddress
+				balance := s.Accounts.GetBalance(address)
+				s.Stack.Push(balance)
+			},
+		})...)
+	}
 
 	// --- RETURNDATASIZE ---
 
@@ -2195,6 +2220,47 @@ func logOp(n int) []Rule {
 	return rules
 }
 
+// targetAccount is a class of accounts targeted by an operation accessing
+// the state of an account, like BALANCE or EXTCODESIZE.
+type targetAccount struct {
+	name      string
+	condition Condition
+}
+
+// getTargetAccounts partitions the accounts targeted by the given address into
+// other accounts and classes of the account executing the current code. The
+// latter cover interactions with preceding operations within the same
+// transaction: accounts that have self-destructed retain their balance and code
+// until the end of the transaction, and accounts running their init code have no
+// code until the init code has returned.
+func getTargetAccounts(address BindableExpression[U256]) []targetAccount {
+	return append(
+		[]targetAccount{{"", IsNotSelfAddress(address)}},
+		getSelfTargetAccounts(address)...,
+	)
+}
+
+// getExtCodeHashTargetAccounts is like getTargetAccounts, but additionally
+// distinguishes empty and non-empty other accounts, since the hash of empty
+// accounts is zero.
+func getExtCodeHashTargetAccounts(address BindableExpression[U256]) []targetAccount {
+	return append(
+		[]targetAccount{
+			{"_empty", And(IsNotSelfAddress(address), AccountIsEmpty(address))},
+			{"_not_empty", And(IsNotSelfAddress(address), AccountIsNotEmpty(address))},
+		},
+		getSelfTargetAccounts(address)...,
+	)
+}
+
+func getSelfTargetAccounts(address BindableExpression[U256]) []targetAccount {
+	return []targetAccount{
+		{"_self", And(IsSelfAddress(address), AccountIsNotEmpty(address), HasNotSelfDestructed())},
+		{"_self_destructed", And(IsSelfAddress(address), AccountIsNotEmpty(address), HasSelfDestructed())},
+		{"_self_in_creation", And(IsSelfAddress(address), AccountIsEmpty(address))},
+	}
+}
+
 func makeSelfDestructRules(
 	revision tosca.Revision,
 	originatorHasFunds bool,
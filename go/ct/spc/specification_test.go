@@ -442,3 +442,137 @@ func TestSpecification_OpsWithDynamicCostHandleOverflowSizes(t *testing.T) {
 		}
 	}
 }
+
+func TestSpecification_AccountAccessCostsMatchSharedDefinitionInBoundaryCases(t *testing.T) {
+	// The generator has no notion of precompiled contracts. Thus, their
+	// address is placed on the stack after generating the state.
+	precompile := tosca.Address{19: 1}
+	targets := map[string]struct {
+		condition rlz.Condition
+		setup     func(*st.State, tosca.AccessStatus)
+	}{
+		"self": {
+			condition: rlz.IsSelfAddress(rlz.Param(0)),
+			setup:     func(*st.State, tosca.AccessStatus) {},
+		},
+		"precompile": {
+			condition: rlz.IsNotSelfAddress(rlz.Param(0)),
+			setup: func(s *st.State, status tosca.AccessStatus) {
+				s.Stack.Set(0, common.AddressToU256(precompile))
+				if status == tosca.WarmAccess {
+					s.Accounts.MarkWarm(precompile)
+				}
+			},
+		},
+	}
+	ops := []vm.OpCode{vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODEHASH}
+	rnd := rand.New(0)
+
+	for _, op := range ops {
+		for revision := tosca.R09_Berlin; revision <= common.NewestSupportedRevision; revision++ {
+			for targetName, target := range targets {
+				for _, status := range []tosca.AccessStatus{tosca.WarmAccess, tosca.ColdAccess} {
+					name := fmt.Sprintf("%v/%v/%s/warm=%t", op, revision, targetName, status == tosca.WarmAccess)
+					t.Run(name, func(t *testing.T) {
+						access := rlz.IsAddressCold(rlz.Param(0))
+						if status == tosca.WarmAccess {
+							access = rlz.IsAddressWarm(rlz.Param(0))
+						}
+						condition := rlz.And(
+							rlz.IsRevision(revision),
+							rlz.Eq(rlz.Status(), st.Running),
+							rlz.Eq(rlz.Op(rlz.Pc()), op),
+							rlz.Ge(rlz.Gas(), tosca.Gas(10_000)),
+							target.condition,
+							access,
+						)
+						generator := gen.NewStateGenerator()
+						condition.Restrict(generator)
+						state, err := generator.Generate(rnd)
+						if err != nil {
+							t.Fatalf("failed to generate a constrained state: %v", err)
+						}
+						defer state.Release()
+						target.setup(state, status)
+
+						address := common.NewAddress(state.Stack.Get(0))
+						if want, got := status == tosca.WarmAccess, state.Accounts.IsWarm(address); want != got {
+							t.Fatalf("unexpected access status in generated state, wanted warm=%t, got %t", want, got)
+						}
+
+						rules := Spec.GetRulesFor(state)
+						if len(rules) == 0 {
+							t.Fatalf("no rule for state %v", state)
+						}
+						result := state.Clone()
+						defer result.Release()
+						rules[0].Effect.Apply(result)
+
+						if want, got := st.Running, result.Status; want != got {
+							t.Fatalf("unexpected status, wanted %v, got %v", want, got)
+						}
+						if want, got := tosca.AccountAccessCost(status), state.Gas-result.Gas; want != got {
+							t.Errorf("unexpected gas costs, wanted %d, got %d", want, got)
+						}
+						if !result.Accounts.IsWarm(address) {
+							t.Errorf("accessed account is not warm")
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+func TestSpecification_Create2IntoOccupiedAddressConsumesForwardedGas(t *testing.T) {
+	// A creation into an address occupied by an account with a nonce or code
+	// fails consuming all forwarded gas, as reported by the call journal.
+	rnd := rand.New(0)
+	for revision := tosca.R07_Istanbul; revision <= common.NewestSupportedRevision; revision++ {
+		t.Run(revision.String(), func(t *testing.T) {
+			condition := rlz.And(
+				rlz.IsRevision(revision),
+				rlz.Eq(rlz.Status(), st.Running),
+				rlz.Eq(rlz.Op(rlz.Pc()), vm.CREATE2),
+				rlz.Eq(rlz.ReadOnly(), false),
+				rlz.Ge(rlz.StackSize(), 4),
+				rlz.Ge(rlz.Gas(), tosca.Gas(100_000)),
+			)
+			generator := gen.NewStateGenerator()
+			condition.Restrict(generator)
+			state, err := generator.Generate(rnd)
+			if err != nil {
+				t.Fatalf("failed to generate a constrained state: %v", err)
+			}
+			defer state.Release()
+
+			// Create an empty contract without value using salt 1.
+			state.Stack.Set(0, common.NewU256(0))
+			state.Stack.Set(1, common.NewU256(0))
+			state.Stack.Set(2, common.NewU256(0))
+			state.Stack.Set(3, common.NewU256(1))
+			state.CallJournal.Future = []st.FutureCall{{Success: false, GasCosts: st.MaxGasUsedByCt}}
+
+			rules := Spec.GetRulesFor(state)
+			if len(rules) == 0 {
+				t.Fatalf("no rule for state %v", state)
+			}
+			result := state.Clone()
+			defer result.Release()
+			rules[0].Effect.Apply(result)
+
+			if want, got := st.Running, result.Status; want != got {
+				t.Fatalf("unexpected status, wanted %v, got %v", want, got)
+			}
+			if want, got := common.NewU256(0), result.Stack.Get(0); want != got {
+				t.Errorf("unexpected created address, wanted %v, got %v", want, got)
+			}
+			if want, got := (state.Gas-32000)/64, result.Gas; want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+			}
+			if result.LastCallReturnData.Length() != 0 {
+				t.Errorf("unexpected return data: %v", result.LastCallReturnData)
+			}
+		})
+	}
+}
@@ -52,10 +52,26 @@ func (a *Accounts) Exists(address tosca.Address) bool {
 }
 
 func (a *Accounts) IsEmpty(address tosca.Address) bool {
-	// By definition, an account is empty if it has an empty balance,
-	// a nonce that is 0, and an empty code. However, we do not model
-	// nonces in this state, so we only check the balance and code.
-	return a.GetAccount(address) == Account{}
+	return tosca.IsEmptyAccount(accountState{a}, address)
+}
+
+// accountState adapts Accounts to the tosca.AccountState interface, such that
+// the definitions shared with the processors can be applied to it. Nonces are
+// not modeled in this state and are thus reported as 0.
+type accountState struct {
+	accounts *Accounts
+}
+
+func (s accountState) GetBalance(address tosca.Address) tosca.Value {
+	return s.accounts.GetBalance(address).Bytes32be()
+}
+
+func (s accountState) GetNonce(tosca.Address) uint64 {
+	return 0
+}
+
+func (s accountState) GetCodeSize(address tosca.Address) int {
+	return s.accounts.GetCode(address).Length()
 }
 
 func (a *Accounts) GetAccount(address tosca.Address) Account {
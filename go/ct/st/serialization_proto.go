@@ -0,0 +1,706 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package st
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The binary format of states is defined by the protobuf schema in
+// state.proto. Encoding and decoding is implemented directly on the wire
+// format to avoid the need for generated code. Encodings are deterministic,
+// such that equal states are encoded into equal byte strings.
+
+// ExportStateProto exports the given state in the binary format defined by
+// state.proto to the given file path. If the file already exists, it will be
+// overwritten.
+func ExportStateProto(state *State, filePath string) error {
+	return os.WriteFile(filePath, MarshalStateProto(state), 0644)
+}
+
+// ImportStateProto imports a state from the given file in the binary format
+// defined by state.proto.
+func ImportStateProto(filePath string) (*State, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalStateProto(data)
+}
+
+// ImportState imports a state from the given file, which is expected to be in
+// the binary format if its extension is .pb and in json format otherwise.
+func ImportState(filePath string) (*State, error) {
+	if filepath.Ext(filePath) == ".pb" {
+		return ImportStateProto(filePath)
+	}
+	return ImportStateJSON(filePath)
+}
+
+// MarshalStateProto encodes the given state in the binary format defined by
+// state.proto.
+func MarshalStateProto(state *State) []byte {
+	s := newStateSerializableFromState(state)
+	var b []byte
+	b = appendVarintField(b, 1, uint64(s.Status))
+	b = appendVarintField(b, 2, uint64(s.Revision))
+	b = appendBoolField(b, 3, s.ReadOnly)
+	b = appendVarintField(b, 4, uint64(s.Pc))
+	b = appendVarintField(b, 5, uint64(s.Gas))
+	b = appendVarintField(b, 6, uint64(s.GasRefund))
+	b = appendBytesField(b, 7, s.Code.ToBytes())
+	for _, value := range s.Stack {
+		b = appendRepeatedBytes(b, 8, u256ToBytes(value))
+	}
+	b = appendBytesField(b, 9, s.Memory.ToBytes())
+	b = appendMessageField(b, 10, encodeStorage(s.Storage))
+	for _, key := range sortedU256Keys(s.TransientStorage.Storage) {
+		b = appendRepeatedMessage(b, 11, encodeStorageEntry(key, s.TransientStorage.Storage[key]))
+	}
+	for _, address := range sortedAddressKeys(s.Accounts.Balance) {
+		b = appendRepeatedMessage(b, 12, encodeAccount(address, s.Accounts.Balance[address], s.Accounts.Code[address]))
+	}
+	for _, address := range sortedAddressKeys(s.Accounts.Warm) {
+		b = appendRepeatedBytes(b, 13, address[:])
+	}
+	for _, entry := range s.Logs.Entries {
+		b = appendRepeatedMessage(b, 14, encodeLog(entry))
+	}
+	b = appendMessageField(b, 15, encodeCallContext(&s.CallContext))
+	b = appendMessageField(b, 16, encodeBlockContext(&s.BlockContext))
+	b = appendBytesField(b, 17, s.CallData.ToBytes())
+	b = appendBytesField(b, 18, s.LastCallReturnData.ToBytes())
+	b = appendBytesField(b, 19, s.ReturnData.ToBytes())
+	if s.CallJournal != nil {
+		for i := range s.CallJournal.Past {
+			b = appendRepeatedMessage(b, 20, encodePastCall(&s.CallJournal.Past[i]))
+		}
+		for i := range s.CallJournal.Future {
+			b = appendRepeatedMessage(b, 21, encodeFutureCall(&s.CallJournal.Future[i]))
+		}
+	}
+	b = appendBoolField(b, 22, s.HasSelfDestructed)
+	for _, entry := range s.SelfDestructedJournal {
+		var e []byte
+		e = appendBytesField(e, 1, nonZero(entry.Account[:]))
+		e = appendBytesField(e, 2, nonZero(entry.Beneficiary[:]))
+		b = appendRepeatedMessage(b, 23, e)
+	}
+	if !s.RecentBlockHashes.Equal(ImmutableHashArray{}) {
+		hashes := make([]byte, 0, 256*len(tosca.Hash{}))
+		for i := uint64(0); i < 256; i++ {
+			hash := s.RecentBlockHashes.Get(i)
+			hashes = append(hashes, hash[:]...)
+		}
+		b = appendBytesField(b, 24, hashes)
+	}
+	if s.TransactionContext != nil {
+		var e []byte
+		e = appendBytesField(e, 1, nonZero(s.TransactionContext.OriginAddress[:]))
+		for _, hash := range s.TransactionContext.BlobHashes {
+			e = appendRepeatedBytes(e, 2, hash[:])
+		}
+		b = appendMessageField(b, 25, e)
+	}
+	return b
+}
+
+// UnmarshalStateProto decodes a state from the binary format defined by
+// state.proto. Unknown fields are ignored to support states produced by
+// newer versions of the schema.
+func UnmarshalStateProto(data []byte) (*State, error) {
+	s := &stateSerializable{
+		Storage: &storageSerializable{
+			Current:  map[U256]U256{},
+			Original: map[U256]U256{},
+			Warm:     map[U256]bool{},
+		},
+		TransientStorage: &transientSerializable{Storage: map[U256]U256{}},
+		Accounts: &accountsSerializable{
+			Balance: map[tosca.Address]U256{},
+			Code:    map[tosca.Address]Bytes{},
+			Warm:    map[tosca.Address]bool{},
+		},
+		Logs:        &logsSerializable{},
+		CallJournal: NewCallJournal(),
+	}
+	err := decodeFields(data, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			var status uint64
+			status, err = f.varint()
+			if err == nil && status >= uint64(NumStatusCodes) {
+				err = fmt.Errorf("invalid status code %d", status)
+			}
+			s.Status = StatusCode(status)
+		case 2:
+			s.Revision, err = decodeInt[tosca.Revision](f)
+		case 3:
+			s.ReadOnly, err = f.bool()
+		case 4:
+			var pc uint64
+			pc, err = f.varint()
+			if err == nil && pc > 0xFFFF {
+				err = fmt.Errorf("pc out of range: %d", pc)
+			}
+			s.Pc = uint16(pc)
+		case 5:
+			s.Gas, err = decodeInt[tosca.Gas](f)
+		case 6:
+			s.GasRefund, err = decodeInt[tosca.Gas](f)
+		case 7:
+			s.Code, err = f.bytesValue()
+		case 8:
+			var value U256
+			value, err = f.u256()
+			s.Stack = append(s.Stack, value)
+		case 9:
+			s.Memory, err = f.bytesValue()
+		case 10:
+			err = decodeStorage(f, s.Storage)
+		case 11:
+			err = decodeStorageEntry(f, s.TransientStorage.Storage)
+		case 12:
+			err = decodeAccount(f, s.Accounts)
+		case 13:
+			var address tosca.Address
+			address, err = f.address()
+			s.Accounts.Warm[address] = true
+		case 14:
+			err = decodeLog(f, s.Logs)
+		case 15:
+			err = decodeCallContext(f, &s.CallContext)
+		case 16:
+			err = decodeBlockContext(f, &s.BlockContext)
+		case 17:
+			s.CallData, err = f.bytesValue()
+		case 18:
+			s.LastCallReturnData, err = f.bytesValue()
+		case 19:
+			s.ReturnData, err = f.bytesValue()
+		case 20:
+			err = decodePastCall(f, s.CallJournal)
+		case 21:
+			err = decodeFutureCall(f, s.CallJournal)
+		case 22:
+			s.HasSelfDestructed, err = f.bool()
+		case 23:
+			err = decodeSelfDestructEntry(f, &s.SelfDestructedJournal)
+		case 24:
+			s.RecentBlockHashes, err = decodeRecentBlockHashes(f)
+		case 25:
+			s.TransactionContext, err = decodeTransactionContext(f)
+		}
+		if err != nil {
+			return fmt.Errorf("field %d: %w", f.num, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid state encoding: %w", err)
+	}
+	return s.deserialize(), nil
+}
+
+// --- encoding ---
+
+func appendVarintField(b []byte, num protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendBoolField(b []byte, num protowire.Number, value bool) []byte {
+	return appendVarintField(b, num, protowire.EncodeBool(value))
+}
+
+// appendBytesField appends the given bytes unless they are empty, which is
+// the default value of the field.
+func appendBytesField(b []byte, num protowire.Number, value []byte) []byte {
+	if len(value) == 0 {
+		return b
+	}
+	return appendRepeatedBytes(b, num, value)
+}
+
+// appendRepeatedBytes appends the given bytes as an element of a repeated
+// field, which needs to be encoded even if empty.
+func appendRepeatedBytes(b []byte, num protowire.Number, value []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, value)
+}
+
+func appendMessageField(b []byte, num protowire.Number, message []byte) []byte {
+	return appendBytesField(b, num, message)
+}
+
+func appendRepeatedMessage(b []byte, num protowire.Number, message []byte) []byte {
+	return appendRepeatedBytes(b, num, message)
+}
+
+// u256ToBytes returns the minimal big-endian encoding of the given value.
+func u256ToBytes(value U256) []byte {
+	bytes := value.Bytes32be()
+	return nonZero(bytes[:])
+}
+
+// nonZero strips leading zeros of the given big-endian value, which results
+// in an empty slice for zero values.
+func nonZero(value []byte) []byte {
+	return bytes.TrimLeft(value, "\x00")
+}
+
+func encodeStorageEntry(key, value U256) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, u256ToBytes(key))
+	b = appendBytesField(b, 2, u256ToBytes(value))
+	return b
+}
+
+func encodeStorage(storage *storageSerializable) []byte {
+	var b []byte
+	for _, key := range sortedU256Keys(storage.Current) {
+		b = appendRepeatedMessage(b, 1, encodeStorageEntry(key, storage.Current[key]))
+	}
+	for _, key := range sortedU256Keys(storage.Original) {
+		b = appendRepeatedMessage(b, 2, encodeStorageEntry(key, storage.Original[key]))
+	}
+	for _, key := range sortedU256Keys(storage.Warm) {
+		var e []byte
+		e = appendBytesField(e, 1, u256ToBytes(key))
+		e = appendBoolField(e, 2, storage.Warm[key])
+		b = appendRepeatedMessage(b, 3, e)
+	}
+	return b
+}
+
+func encodeAccount(address tosca.Address, balance U256, code Bytes) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, address[:])
+	b = appendBytesField(b, 2, u256ToBytes(balance))
+	b = appendBytesField(b, 3, code.ToBytes())
+	return b
+}
+
+func encodeLog(entry logEntrySerializable) []byte {
+	var b []byte
+	for _, topic := range entry.Topics {
+		b = appendRepeatedBytes(b, 1, u256ToBytes(topic))
+	}
+	b = appendBytesField(b, 2, entry.Data.ToBytes())
+	return b
+}
+
+func encodeCallContext(context *CallContext) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, nonZero(context.AccountAddress[:]))
+	b = appendBytesField(b, 2, nonZero(context.CallerAddress[:]))
+	b = appendBytesField(b, 3, u256ToBytes(context.Value))
+	return b
+}
+
+func encodeBlockContext(context *BlockContext) []byte {
+	var b []byte
+	b = appendBytesField(b, 1, u256ToBytes(context.BaseFee))
+	b = appendBytesField(b, 2, u256ToBytes(context.BlobBaseFee))
+	b = appendVarintField(b, 3, context.BlockNumber)
+	b = appendBytesField(b, 4, u256ToBytes(context.ChainID))
+	b = appendBytesField(b, 5, nonZero(context.CoinBase[:]))
+	b = appendVarintField(b, 6, context.GasLimit)
+	b = appendBytesField(b, 7, u256ToBytes(context.GasPrice))
+	b = appendBytesField(b, 8, u256ToBytes(context.PrevRandao))
+	b = appendVarintField(b, 9, context.TimeStamp)
+	return b
+}
+
+func encodePastCall(call *PastCall) []byte {
+	var b []byte
+	b = appendVarintField(b, 1, uint64(call.Kind))
+	b = appendBytesField(b, 2, nonZero(call.Recipient[:]))
+	b = appendBytesField(b, 3, nonZero(call.Sender[:]))
+	b = appendBytesField(b, 4, call.Input.ToBytes())
+	b = appendBytesField(b, 5, nonZero(call.Value[:]))
+	b = appendVarintField(b, 6, uint64(call.Gas))
+	b = appendBytesField(b, 7, nonZero(call.CodeAddress[:]))
+	return b
+}
+
+func encodeFutureCall(call *FutureCall) []byte {
+	var b []byte
+	b = appendBoolField(b, 1, call.Success)
+	b = appendBytesField(b, 2, call.Output.ToBytes())
+	b = appendVarintField(b, 3, uint64(call.GasCosts))
+	b = appendVarintField(b, 4, uint64(call.GasRefund))
+	b = appendBytesField(b, 5, nonZero(call.CreatedAccount[:]))
+	return b
+}
+
+func sortedU256Keys[V any](m map[U256]V) []U256 {
+	keys := maps.Keys(m)
+	slices.SortFunc(keys, func(a, b U256) int {
+		if a.Lt(b) {
+			return -1
+		}
+		if a.Gt(b) {
+			return 1
+		}
+		return 0
+	})
+	return keys
+}
+
+func sortedAddressKeys[V any](m map[tosca.Address]V) []tosca.Address {
+	keys := maps.Keys(m)
+	slices.SortFunc(keys, func(a, b tosca.Address) int {
+		return bytes.Compare(a[:], b[:])
+	})
+	return keys
+}
+
+// --- decoding ---
+
+// protoField is a single field of a protobuf message on the wire.
+type protoField struct {
+	num   protowire.Number
+	typ   protowire.Type
+	value uint64 // < the value of varint fields
+	data  []byte // < the content of length-delimited fields
+}
+
+func (f protoField) varint() (uint64, error) {
+	if f.typ != protowire.VarintType {
+		return 0, fmt.Errorf("expected varint, got wire type %d", f.typ)
+	}
+	return f.value, nil
+}
+
+func (f protoField) bool() (bool, error) {
+	value, err := f.varint()
+	return protowire.DecodeBool(value), err
+}
+
+func (f protoField) bytes() ([]byte, error) {
+	if f.typ != protowire.BytesType {
+		return nil, fmt.Errorf("expected bytes, got wire type %d", f.typ)
+	}
+	return f.data, nil
+}
+
+func (f protoField) bytesValue() (Bytes, error) {
+	data, err := f.bytes()
+	return NewBytes(data), err
+}
+
+func (f protoField) u256() (U256, error) {
+	data, err := f.bytes()
+	if err != nil {
+		return U256{}, err
+	}
+	if len(data) > 32 {
+		return U256{}, fmt.Errorf("value of %d bytes exceeds 256 bits", len(data))
+	}
+	return NewU256FromBytes(data...), nil
+}
+
+// fixed decodes a big-endian value into the given array, which must be at
+// least as long as the value. Shorter values are padded with leading zeros.
+func (f protoField) fixed(target []byte) error {
+	data, err := f.bytes()
+	if err != nil {
+		return err
+	}
+	if len(data) > len(target) {
+		return fmt.Errorf("value of %d bytes exceeds %d bytes", len(data), len(target))
+	}
+	copy(target[len(target)-len(data):], data)
+	return nil
+}
+
+func (f protoField) address() (tosca.Address, error) {
+	var res tosca.Address
+	return res, f.fixed(res[:])
+}
+
+func (f protoField) hash() (tosca.Hash, error) {
+	var res tosca.Hash
+	return res, f.fixed(res[:])
+}
+
+func decodeInt[T ~int | ~int64](f protoField) (T, error) {
+	value, err := f.varint()
+	return T(int64(value)), err
+}
+
+// decodeFields calls the given function for every field of the encoded
+// message, in the order of their occurrence.
+func decodeFields(data []byte, consume func(protoField) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		field := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			field.value, n = protowire.ConsumeVarint(data)
+		case protowire.BytesType:
+			field.data, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if err := consume(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeMessage decodes the fields of the message embedded in the given field.
+func decodeMessage(f protoField, consume func(protoField) error) error {
+	data, err := f.bytes()
+	if err != nil {
+		return err
+	}
+	return decodeFields(data, consume)
+}
+
+func decodeStorageEntry(f protoField, target map[U256]U256) error {
+	var key, value U256
+	err := decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			key, err = f.u256()
+		case 2:
+			value, err = f.u256()
+		}
+		return err
+	})
+	target[key] = value
+	return err
+}
+
+func decodeStorage(f protoField, storage *storageSerializable) error {
+	return decodeMessage(f, func(f protoField) error {
+		switch f.num {
+		case 1:
+			return decodeStorageEntry(f, storage.Current)
+		case 2:
+			return decodeStorageEntry(f, storage.Original)
+		case 3:
+			var key U256
+			var warm bool
+			err := decodeMessage(f, func(f protoField) (err error) {
+				switch f.num {
+				case 1:
+					key, err = f.u256()
+				case 2:
+					warm, err = f.bool()
+				}
+				return err
+			})
+			storage.Warm[key] = warm
+			return err
+		}
+		return nil
+	})
+}
+
+func decodeAccount(f protoField, accounts *accountsSerializable) error {
+	var address tosca.Address
+	var balance U256
+	var code Bytes
+	err := decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			address, err = f.address()
+		case 2:
+			balance, err = f.u256()
+		case 3:
+			code, err = f.bytesValue()
+		}
+		return err
+	})
+	accounts.Balance[address] = balance
+	accounts.Code[address] = code
+	return err
+}
+
+func decodeLog(f protoField, logs *logsSerializable) error {
+	var topics []U256
+	var data Bytes
+	err := decodeMessage(f, func(f protoField) error {
+		switch f.num {
+		case 1:
+			topic, err := f.u256()
+			topics = append(topics, topic)
+			return err
+		case 2:
+			var err error
+			data, err = f.bytesValue()
+			return err
+		}
+		return nil
+	})
+	logs.addLog(data, topics...)
+	return err
+}
+
+func decodeCallContext(f protoField, context *CallContext) error {
+	return decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			context.AccountAddress, err = f.address()
+		case 2:
+			context.CallerAddress, err = f.address()
+		case 3:
+			context.Value, err = f.u256()
+		}
+		return err
+	})
+}
+
+func decodeBlockContext(f protoField, context *BlockContext) error {
+	return decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			context.BaseFee, err = f.u256()
+		case 2:
+			context.BlobBaseFee, err = f.u256()
+		case 3:
+			context.BlockNumber, err = f.varint()
+		case 4:
+			context.ChainID, err = f.u256()
+		case 5:
+			context.CoinBase, err = f.address()
+		case 6:
+			context.GasLimit, err = f.varint()
+		case 7:
+			context.GasPrice, err = f.u256()
+		case 8:
+			context.PrevRandao, err = f.u256()
+		case 9:
+			context.TimeStamp, err = f.varint()
+		}
+		return err
+	})
+}
+
+func decodePastCall(f protoField, journal *CallJournal) error {
+	var call PastCall
+	err := decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			call.Kind, err = decodeInt[tosca.CallKind](f)
+		case 2:
+			call.Recipient, err = f.address()
+		case 3:
+			call.Sender, err = f.address()
+		case 4:
+			call.Input, err = f.bytesValue()
+		case 5:
+			err = f.fixed(call.Value[:])
+		case 6:
+			call.Gas, err = decodeInt[tosca.Gas](f)
+		case 7:
+			call.CodeAddress, err = f.address()
+		}
+		return err
+	})
+	journal.Past = append(journal.Past, call)
+	return err
+}
+
+func decodeFutureCall(f protoField, journal *CallJournal) error {
+	var call FutureCall
+	err := decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			call.Success, err = f.bool()
+		case 2:
+			call.Output, err = f.bytesValue()
+		case 3:
+			call.GasCosts, err = decodeInt[tosca.Gas](f)
+		case 4:
+			call.GasRefund, err = decodeInt[tosca.Gas](f)
+		case 5:
+			call.CreatedAccount, err = f.address()
+		}
+		return err
+	})
+	journal.Future = append(journal.Future, call)
+	return err
+}
+
+func decodeSelfDestructEntry(f protoField, journal *[]serializableSelfDestructEntry) error {
+	var entry serializableSelfDestructEntry
+	err := decodeMessage(f, func(f protoField) (err error) {
+		switch f.num {
+		case 1:
+			entry.Account, err = f.address()
+		case 2:
+			entry.Beneficiary, err = f.address()
+		}
+		return err
+	})
+	*journal = append(*journal, entry)
+	return err
+}
+
+func decodeRecentBlockHashes(f protoField) (ImmutableHashArray, error) {
+	data, err := f.bytes()
+	if err != nil {
+		return ImmutableHashArray{}, err
+	}
+	const hashSize = len(tosca.Hash{})
+	if len(data) != 256*hashSize {
+		return ImmutableHashArray{}, fmt.Errorf("expected %d bytes of block hashes, got %d", 256*hashSize, len(data))
+	}
+	hashes := make([]tosca.Hash, 256)
+	for i := range hashes {
+		copy(hashes[i][:], data[i*hashSize:])
+	}
+	return NewImmutableHashArray(hashes...), nil
+}
+
+func decodeTransactionContext(f protoField) (*TransactionContext, error) {
+	context := NewTransactionContext()
+	err := decodeMessage(f, func(f protoField) error {
+		switch f.num {
+		case 1:
+			var err error
+			context.OriginAddress, err = f.address()
+			return err
+		case 2:
+			hash, err := f.hash()
+			context.BlobHashes = append(context.BlobHashes, hash)
+			return err
+		}
+		return nil
+	})
+	return context, err
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package st
+
+import (
+	"bytes"
+	"path"
+	"testing"
+
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestSerializationProto_RoundTrip(t *testing.T) {
+	states := map[string]*State{
+		"empty":  NewState(NewCode(nil)),
+		"filled": getNewFilledState(),
+	}
+	for name, test := range getTestChanges() {
+		state := getNewFilledState()
+		test.change(state)
+		states[name] = state
+	}
+	full := getNewFilledState()
+	full.BlockContext = BlockContext{
+		BaseFee:     NewU256(1),
+		BlobBaseFee: NewU256(2),
+		BlockNumber: 3,
+		ChainID:     NewU256(4),
+		CoinBase:    tosca.Address{5},
+		GasLimit:    6,
+		GasPrice:    NewU256(7),
+		PrevRandao:  MaxU256(),
+		TimeStamp:   9,
+	}
+	full.CallJournal.Past = []PastCall{{
+		Kind: tosca.Create2, Recipient: tosca.Address{1}, Sender: tosca.Address{2},
+		Input: NewBytes([]byte{3}), Value: tosca.Value{4}, Gas: -5, CodeAddress: tosca.Address{6},
+	}}
+	full.CallJournal.Future = []FutureCall{{
+		Success: true, Output: NewBytes([]byte{1}), GasCosts: 2, GasRefund: 3, CreatedAccount: tosca.Address{4},
+	}}
+	full.Stack.Push(NewU256())
+	states["full"] = full
+
+	for name, state := range states {
+		t.Run(name, func(t *testing.T) {
+			restored, err := UnmarshalStateProto(MarshalStateProto(state))
+			if err != nil {
+				t.Fatalf("failed to decode state: %v", err)
+			}
+			if !state.Eq(restored) {
+				t.Errorf("invalid deserialization, differences: %v", state.Diff(restored))
+			}
+		})
+	}
+}
+
+func TestSerializationProto_EncodingIsDeterministic(t *testing.T) {
+	state := getNewFilledState()
+	state.Storage = NewStorageBuilder().
+		SetCurrent(NewU256(1), NewU256(1)).
+		SetCurrent(NewU256(2), NewU256(2)).
+		SetCurrent(NewU256(3), NewU256(3)).
+		Build()
+	want := MarshalStateProto(state)
+	for i := 0; i < 10; i++ {
+		if got := MarshalStateProto(state.Clone()); !bytes.Equal(want, got) {
+			t.Fatalf("encoding of equal states differs")
+		}
+	}
+}
+
+func TestSerializationProto_ExportAndImport(t *testing.T) {
+	state := getNewFilledState()
+	filePath := path.Join(t.TempDir(), "state.pb")
+	if err := ExportStateProto(state, filePath); err != nil {
+		t.Fatalf("failed to export state: %v", err)
+	}
+	restored, err := ImportStateProto(filePath)
+	if err != nil {
+		t.Fatalf("failed to import state: %v", err)
+	}
+	if !state.Eq(restored) {
+		t.Errorf("invalid deserialization, differences: %v", state.Diff(restored))
+	}
+}
+
+func TestSerializationProto_ImportStateSelectsFormatByExtension(t *testing.T) {
+	state := getNewFilledState()
+	dir := t.TempDir()
+	protoPath := path.Join(dir, "state.pb")
+	jsonPath := path.Join(dir, "state.json")
+	if err := ExportStateProto(state, protoPath); err != nil {
+		t.Fatalf("failed to export state: %v", err)
+	}
+	if err := ExportStateJSON(state, jsonPath); err != nil {
+		t.Fatalf("failed to export state: %v", err)
+	}
+	for _, filePath := range []string{protoPath, jsonPath} {
+		restored, err := ImportState(filePath)
+		if err != nil {
+			t.Fatalf("failed to import %s: %v", filePath, err)
+		}
+		if !state.Eq(restored) {
+			t.Errorf("invalid deserialization of %s, differences: %v", filePath, state.Diff(restored))
+		}
+	}
+}
+
+func TestSerializationProto_UnknownFieldsAreIgnored(t *testing.T) {
+	encoded := MarshalStateProto(getNewFilledState())
+	encoded = protowire.AppendTag(encoded, 1000, protowire.BytesType)
+	encoded = protowire.AppendBytes(encoded, []byte{1, 2, 3})
+	encoded = protowire.AppendTag(encoded, 1001, protowire.Fixed64Type)
+	encoded = protowire.AppendFixed64(encoded, 42)
+
+	restored, err := UnmarshalStateProto(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode state: %v", err)
+	}
+	if want := getNewFilledState(); !want.Eq(restored) {
+		t.Errorf("invalid deserialization, differences: %v", want.Diff(restored))
+	}
+}
+
+func TestSerializationProto_InvalidEncodingsAreRejected(t *testing.T) {
+	field := func(num protowire.Number, typ protowire.Type, value []byte) []byte {
+		return append(protowire.AppendTag(nil, num, typ), value...)
+	}
+	tests := map[string][]byte{
+		"truncated":          {0x0A},
+		"wrong wire type":    field(7, protowire.VarintType, []byte{1}),
+		"invalid status":     field(1, protowire.VarintType, []byte{byte(NumStatusCodes)}),
+		"pc out of range":    field(4, protowire.VarintType, protowire.AppendVarint(nil, 1<<16)),
+		"oversized value":    field(8, protowire.BytesType, protowire.AppendBytes(nil, make([]byte, 33))),
+		"oversized address":  field(13, protowire.BytesType, protowire.AppendBytes(nil, make([]byte, 21))),
+		"short block hashes": field(24, protowire.BytesType, protowire.AppendBytes(nil, make([]byte, 32))),
+	}
+	for name, encoded := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := UnmarshalStateProto(encoded); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
@@ -17,6 +17,10 @@ import (
 	"github.com/Fantom-foundation/Tosca/go/tosca"
 )
 
+// MaxBlobHashes is the maximum number of blob hashes of a transaction. It is
+// implied by the maximum blob gas per block introduced by EIP-4844.
+const MaxBlobHashes = 6
+
 // TransactionContext holds all transaction data
 type TransactionContext struct {
 	OriginAddress tosca.Address // Address of execution origination
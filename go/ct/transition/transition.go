@@ -0,0 +1,259 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package transition tests the behavior of EVM implementations at hard-fork
+// boundaries. Identical instructions are executed in a revision and in its
+// successor, and the observed differences are compared to the ones justified
+// by the EIPs activated by the successor, as described by the revisions
+// package. This way, unintended changes of the behavior of an implementation
+// at a fork boundary, for instance a gas table applied one revision too early,
+// are detected.
+package transition
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Fantom-foundation/Tosca/go/ct"
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/playground"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// DeltaKind classifies the differences between the executions of an
+// instruction in consecutive revisions.
+type DeltaKind int
+
+const (
+	Availability DeltaKind = iota // the instruction fails in only one of the revisions
+	Gas                           // the gas costs of the instruction differ
+	Refund                        // the gas refund granted by the instruction differs
+	Behavior                      // any other observable effect of the instruction differs
+)
+
+func (k DeltaKind) String() string {
+	switch k {
+	case Availability:
+		return "availability"
+	case Gas:
+		return "gas"
+	case Refund:
+		return "refund"
+	case Behavior:
+		return "behavior"
+	default:
+		return fmt.Sprintf("DeltaKind(%d)", int(k))
+	}
+}
+
+// Delta is a difference between the executions of an instruction in the
+// revision From and its successor.
+type Delta struct {
+	Op      vm.OpCode
+	From    tosca.Revision
+	Kind    DeltaKind
+	Details []string
+}
+
+func (d Delta) String() string {
+	return fmt.Sprintf("%v: %v changed from %v to %v: %s",
+		d.Op, d.Kind, d.From, d.From+1, strings.Join(d.Details, "; "))
+}
+
+// Compare executes the given instruction with the given setup in the given
+// revision and in its successor and lists the differences between the
+// resulting states. The revision of the setup is ignored.
+func Compare(evm ct.Evm, op vm.OpCode, setup playground.Setup, from tosca.Revision) ([]Delta, error) {
+	setup.Revision = from
+	before, err := playground.Run(evm, op, setup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %v in %v: %w", op, from, err)
+	}
+	setup.Revision = from + 1
+	after, err := playground.Run(evm, op, setup)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %v in %v: %w", op, from+1, err)
+	}
+
+	newDelta := func(kind DeltaKind, details ...string) Delta {
+		return Delta{Op: op, From: from, Kind: kind, Details: details}
+	}
+
+	// If the instruction fails in only one of the revisions, all other
+	// differences are consequences of this.
+	beforeFailed := before.State.Status == st.Failed
+	afterFailed := after.State.Status == st.Failed
+	if beforeFailed != afterFailed {
+		return []Delta{newDelta(Availability, fmt.Sprintf(
+			"status %v vs %v", before.State.Status, after.State.Status,
+		))}, nil
+	}
+	if beforeFailed {
+		return nil, nil
+	}
+
+	res := []Delta{}
+	if before.GasUsed != after.GasUsed {
+		res = append(res, newDelta(Gas, fmt.Sprintf("%d vs %d", before.GasUsed, after.GasUsed)))
+	}
+	if before.State.GasRefund != after.State.GasRefund {
+		res = append(res, newDelta(Refund, fmt.Sprintf("%d vs %d", before.State.GasRefund, after.State.GasRefund)))
+	}
+
+	// The remaining differences are compared on states aligned in the
+	// properties covered above.
+	a, b := before.State.Clone(), after.State.Clone()
+	b.Revision = a.Revision
+	b.BlockContext = a.BlockContext
+	b.Gas = a.Gas
+	b.GasRefund = a.GasRefund
+	if diff := a.Diff(b); len(diff) > 0 {
+		res = append(res, newDelta(Behavior, diff...))
+	}
+	return res, nil
+}
+
+// IsExpected determines whether the given delta is justified by the EIPs
+// activated by the successor of the revision the delta starts from.
+func IsExpected(delta Delta) bool {
+	info, found := delta.Op.Info()
+	if !found {
+		return false
+	}
+	to := delta.From + 1
+
+	switch delta.Kind {
+	case Availability:
+		return info.Introduced == vm.Revision(to)
+	case Gas:
+		if info.StaticGas(vm.Revision(delta.From)) != info.StaticGas(vm.Revision(to)) {
+			return true
+		}
+		return slices.Contains(getAffectedOperations(delta.From, to), info.Name)
+	case Refund:
+		return slices.Contains(getAffectedOperations(delta.From, to), info.Name+" refund")
+	case Behavior:
+		if slices.Contains(blockContextOps, delta.Op) {
+			return true
+		}
+		for _, info := range revisions.GetEIPs(to) {
+			if slices.Contains(behaviorChanges[info.EIP], delta.Op) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getAffectedOperations lists the names of the operations and charges with
+// gas costs changed by the revisions following from up to and including to.
+// Changes of charges not bound to a single instruction are attributed to the
+// instructions subject to the charge.
+func getAffectedOperations(from, to tosca.Revision) []string {
+	res := []string{}
+	for _, change := range revisions.GetGasChanges(from, to) {
+		ops, found := chargedOps[change.Operation]
+		if !found {
+			res = append(res, change.Operation)
+			continue
+		}
+		for _, op := range ops {
+			res = append(res, op.String())
+		}
+	}
+	return res
+}
+
+// chargedOps lists the instructions subject to charges not bound to a single
+// instruction.
+var chargedOps = map[string][]vm.OpCode{
+	"account access": accountAccessingOps,
+	"init code":      {vm.CREATE, vm.CREATE2},
+}
+
+// accountAccessingOps lists the instructions charging for accessing accounts.
+var accountAccessingOps = []vm.OpCode{
+	vm.BALANCE, vm.EXTCODESIZE, vm.EXTCODECOPY, vm.EXTCODEHASH,
+	vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.SELFDESTRUCT,
+}
+
+// blockContextOps lists the instructions reporting properties of the block
+// context differing between executions in consecutive revisions. Executions
+// take place at the fork time of their revision (see playground.Setup).
+var blockContextOps = []vm.OpCode{vm.TIMESTAMP}
+
+// behaviorChanges lists the instructions whose effects, beyond their gas
+// costs, are changed by an EIP.
+var behaviorChanges = map[revisions.EIP][]vm.OpCode{
+	// The access lists introduced by EIP-2929 are part of the state.
+	revisions.EIP2929: append([]vm.OpCode{vm.SLOAD, vm.SSTORE}, accountAccessingOps...),
+	revisions.EIP4399: {vm.PREVRANDAO},
+	revisions.EIP6780: {vm.SELFDESTRUCT},
+}
+
+// Report summarizes the outcome of a transition test.
+type Report struct {
+	Expected   []Delta // < deltas justified by the activated EIPs
+	Unexpected []Delta // < deltas not justified by any activated EIP
+}
+
+// Run executes all valid instructions in all pairs of consecutive revisions
+// on the given EVM and classifies the observed deltas.
+func Run(evm ct.Evm) (Report, error) {
+	res := Report{}
+	known := tosca.GetAllKnownRevisions()
+	for _, from := range known[:len(known)-1] {
+		for op := 0; op < 256; op++ {
+			op := vm.OpCode(op)
+			if !vm.IsValid(op) {
+				continue
+			}
+			for _, setup := range getSetups(op) {
+				deltas, err := Compare(evm, op, setup, from)
+				if err != nil {
+					return Report{}, err
+				}
+				for _, delta := range deltas {
+					if IsExpected(delta) {
+						res.Expected = append(res.Expected, delta)
+					} else {
+						res.Unexpected = append(res.Unexpected, delta)
+					}
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// getSetups returns the setups the given instruction is executed with. The
+// stack is filled with the number of elements consumed by the instruction,
+// once with zeros and once with ones, such that storage slots, accounts and
+// memory regions are accessed in different ways.
+func getSetups(op vm.OpCode) []playground.Setup {
+	info, _ := op.Info()
+	res := []playground.Setup{}
+	for _, value := range []uint64{0, 1} {
+		stack := make([]U256, info.Inputs)
+		for i := range stack {
+			stack[i] = NewU256(value)
+		}
+		res = append(res, playground.Setup{
+			Stack: stack,
+			Gas:   1_000_000,
+			Data:  make([]byte, 32),
+		})
+	}
+	return res
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package transition
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/ct"
+	. "github.com/Fantom-foundation/Tosca/go/ct/common"
+	"github.com/Fantom-foundation/Tosca/go/ct/playground"
+	"github.com/Fantom-foundation/Tosca/go/ct/st"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	"github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+var evms = map[string]ct.Evm{
+	"lfvm": lfvm.NewConformanceTestingTarget(),
+	"geth": geth.NewConformanceTestingTarget(),
+}
+
+func TestRun_InterpretersShowOnlyExpectedDeltas(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			report, err := Run(evm)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, delta := range report.Unexpected {
+				t.Errorf("unexpected delta: %v", delta)
+			}
+			if len(report.Expected) == 0 {
+				t.Errorf("expected deltas at fork boundaries, got none")
+			}
+		})
+	}
+}
+
+// prematureGasChange is an EVM charging an additional unit of gas for ADD
+// starting with Shanghai, a change not justified by any EIP.
+type prematureGasChange struct {
+	ct.Evm
+}
+
+func (e prematureGasChange) StepN(state *st.State, numSteps int) (*st.State, error) {
+	isAdd := false
+	if op, err := state.Code.GetOperation(int(state.Pc)); err == nil {
+		isAdd = op == vm.ADD
+	}
+	res, err := e.Evm.StepN(state, numSteps)
+	if err == nil && isAdd && res.Revision >= tosca.R12_Shanghai {
+		res.Gas--
+	}
+	return res, err
+}
+
+func TestRun_UnexpectedGasChangeIsDetected(t *testing.T) {
+	report, err := Run(prematureGasChange{lfvm.NewConformanceTestingTarget()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Unexpected) == 0 {
+		t.Fatalf("expected unexpected deltas, got none")
+	}
+	for _, delta := range report.Unexpected {
+		if delta.Op != vm.ADD || delta.Kind != Gas || delta.From != tosca.R11_Paris {
+			t.Errorf("unexpected delta reported: %v", delta)
+		}
+	}
+}
+
+func TestCompare_ReportsIntroducedInstructions(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			deltas, err := Compare(evm, vm.PUSH0, playground.Setup{Gas: 100}, tosca.R11_Paris)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(deltas) != 1 || deltas[0].Kind != Availability {
+				t.Fatalf("expected a single availability delta, got %v", deltas)
+			}
+		})
+	}
+}
+
+func TestCompare_ReportsNoDeltasForUnchangedInstructions(t *testing.T) {
+	for name, evm := range evms {
+		t.Run(name, func(t *testing.T) {
+			setup := playground.Setup{Stack: []U256{NewU256(1), NewU256(2)}, Gas: 100}
+			for _, from := range []tosca.Revision{tosca.R07_Istanbul, tosca.R12_Shanghai} {
+				deltas, err := Compare(evm, vm.ADD, setup, from)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(deltas) != 0 {
+					t.Errorf("unexpected deltas: %v", deltas)
+				}
+			}
+		})
+	}
+}
+
+func TestIsExpected_ClassifiesDeltas(t *testing.T) {
+	tests := map[string]struct {
+		delta Delta
+		want  bool
+	}{
+		"PUSH0 introduced in Shanghai": {
+			Delta{Op: vm.PUSH0, From: tosca.R11_Paris, Kind: Availability}, true,
+		},
+		"PUSH0 introduced in Cancun": {
+			Delta{Op: vm.PUSH0, From: tosca.R12_Shanghai, Kind: Availability}, false,
+		},
+		"SLOAD repriced in Berlin": {
+			Delta{Op: vm.SLOAD, From: tosca.R07_Istanbul, Kind: Gas}, true,
+		},
+		"SLOAD repriced in London": {
+			Delta{Op: vm.SLOAD, From: tosca.R09_Berlin, Kind: Gas}, false,
+		},
+		"BALANCE repriced by account access costs": {
+			Delta{Op: vm.BALANCE, From: tosca.R07_Istanbul, Kind: Gas}, true,
+		},
+		"CREATE charging init code in Shanghai": {
+			Delta{Op: vm.CREATE, From: tosca.R11_Paris, Kind: Gas}, true,
+		},
+		"SELFDESTRUCT refund removed in London": {
+			Delta{Op: vm.SELFDESTRUCT, From: tosca.R09_Berlin, Kind: Refund}, true,
+		},
+		"SSTORE refund changed in Cancun": {
+			Delta{Op: vm.SSTORE, From: tosca.R12_Shanghai, Kind: Refund}, false,
+		},
+		"SELFDESTRUCT changed in Cancun": {
+			Delta{Op: vm.SELFDESTRUCT, From: tosca.R12_Shanghai, Kind: Behavior}, true,
+		},
+		"ADD changed in Cancun": {
+			Delta{Op: vm.ADD, From: tosca.R12_Shanghai, Kind: Behavior}, false,
+		},
+		"invalid op code": {
+			Delta{Op: vm.OpCode(0x0c), From: tosca.R12_Shanghai, Kind: Availability}, false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := IsExpected(test.delta); got != test.want {
+				t.Errorf("unexpected classification of %v, wanted %t, got %t", test.delta, test.want, got)
+			}
+		})
+	}
+}
+
+func TestDeltaKind_String(t *testing.T) {
+	tests := map[DeltaKind]string{
+		Availability: "availability",
+		Gas:          "gas",
+		Refund:       "refund",
+		Behavior:     "behavior",
+		DeltaKind(7): "DeltaKind(7)",
+	}
+	for kind, want := range tests {
+		if got := kind.String(); got != want {
+			t.Errorf("unexpected name, wanted %q, got %q", want, got)
+		}
+	}
+}
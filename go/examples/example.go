@@ -11,6 +11,7 @@
 package examples
 
 import (
+	"context"
 	"fmt"
 	"math"
 
@@ -81,7 +82,7 @@ func (e *Example) RunOnProcessor(processor tosca.Processor, argument int,
 	blockParameters := tosca.BlockParameters{}
 	transaction.Input = encodeArgument(e.function, argument)
 
-	receipt, err := processor.Run(blockParameters, transaction, transactionContext)
+	receipt, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
 	if err != nil {
 		return Result{}, err
 	}
@@ -32,10 +32,17 @@ import (
 )
 
 func NewGethInterpreterFactory(interpreter tosca.Interpreter) geth.InterpreterFactory {
+	return NewGethInterpreterFactoryWithTracer(interpreter, nil)
+}
+
+// NewGethInterpreterFactoryWithTracer is like NewGethInterpreterFactory but
+// forwards the given tracer, if not nil, to the adapted interpreter.
+func NewGethInterpreterFactoryWithTracer(interpreter tosca.Interpreter, tracer tosca.Tracer) geth.InterpreterFactory {
 	return func(evm *geth.EVM) geth.Interpreter {
 		return &gethInterpreterAdapter{
 			interpreter: interpreter,
 			evm:         evm,
+			tracer:      tracer,
 		}
 	}
 }
@@ -45,6 +52,7 @@ const adapterDebug = false
 type gethInterpreterAdapter struct {
 	interpreter tosca.Interpreter
 	evm         *geth.EVM
+	tracer      tosca.Tracer
 }
 
 func (a *gethInterpreterAdapter) Run(contract *geth.Contract, input []byte, readOnly bool) (ret []byte, err error) {
@@ -144,6 +152,7 @@ func (a *gethInterpreterAdapter) Run(contract *geth.Contract, input []byte, read
 		Origin:     tosca.Address(a.evm.Origin),
 		GasPrice:   gasPrice,
 		BlobHashes: blobHashes,
+		Tracer:     a.tracer,
 	}
 
 	params := tosca.Parameters{
@@ -160,12 +169,14 @@ func (a *gethInterpreterAdapter) Run(contract *geth.Contract, input []byte, read
 		Value:                 value,
 		CodeHash:              codeHash,
 		Code:                  contract.Code,
+		InitCode:              contract.IsDeployment,
 	}
 
 	result, err = a.interpreter.Run(params)
 	if err != nil {
 		return nil, fmt.Errorf("internal interpreter error: %v", err)
 	}
+	tosca.FinalizeSelfDestruction(params.Context, params.Recipient, result.SelfDestruction)
 
 	// Update gas levels.
 	if result.GasLeft > 0 {
@@ -11,9 +11,12 @@
 package interpreter_test
 
 import (
+	"encoding/binary"
 	"fmt"
+	"math"
 	"testing"
 
+	"github.com/Fantom-foundation/Tosca/go/tosca"
 	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
@@ -49,6 +52,59 @@ func TestPushWithMissingDataIsIgnored(t *testing.T) {
 	}
 }
 
+func TestPushWithMissingDataEndsInImplicitStop(t *testing.T) {
+	for _, variant := range getAllInterpreterVariantsForTests() {
+		evm := GetCleanEVM(Istanbul, variant, nil)
+		for i := 1; i <= 32; i++ {
+			op := vm.OpCode(int(vm.PUSH1) - 1 + i)
+			t.Run(fmt.Sprintf("%s-%s", variant, op), func(t *testing.T) {
+				for j := 0; j < i; j++ {
+					code := make([]byte, 1+j)
+					code[0] = byte(op)
+					result, err := evm.Run(code, []byte{})
+					if err != nil {
+						t.Fatalf("unexpected failure in VM execution: %v", err)
+					}
+					if !result.Success || len(result.Output) != 0 {
+						t.Errorf("expected implicit stop with %d data bytes, got %v", j, result)
+					}
+					if want, got := tosca.Gas(3), result.GasUsed; want != got {
+						t.Errorf("unexpected gas usage with %d data bytes, wanted %d, got %d", j, want, got)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestDetectsJumpBeyondProgramCounterRange(t *testing.T) {
+	destinations := []uint64{
+		math.MaxInt32,
+		math.MaxInt32 + 1,
+		math.MaxUint32,
+		math.MaxUint32 + 1,
+		math.MaxUint64,
+	}
+	for _, variant := range getAllInterpreterVariantsForTests() {
+		evm := GetCleanEVM(Istanbul, variant, nil)
+		for _, destination := range destinations {
+			t.Run(fmt.Sprintf("%s-%d", variant, destination), func(t *testing.T) {
+				code := []byte{byte(vm.PUSH8)}
+				code = binary.BigEndian.AppendUint64(code, destination)
+				code = append(code, byte(vm.JUMP), byte(vm.JUMPDEST))
+
+				result, err := evm.Run(code, []byte{})
+				if err != nil {
+					t.Fatalf("unexpected failure in VM execution: %v", err)
+				}
+				if result.Success {
+					t.Errorf("expected VM to fail, got %v", result)
+				}
+			})
+		}
+	}
+}
+
 func TestDetectsJumpOutOfCode(t *testing.T) {
 	for _, variant := range getAllInterpreterVariantsForTests() {
 		evm := GetCleanEVM(Istanbul, variant, nil)
@@ -0,0 +1,206 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package interpreter_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"go.uber.org/mock/gomock"
+)
+
+// The revision matrix runs a fixed corpus of contracts on every supported
+// revision and compares the consumed gas with a golden file. Since the gas
+// usage of these contracts is defined by the EVM specification, all
+// interpreters must produce the same results. To check an interpreter other
+// than the default, or to re-generate the golden file after an intentional
+// change, run
+//
+//	go test -run TestRevisionMatrix -revision-matrix-interpreter=<name> [-update-revision-matrix]
+var (
+	revisionMatrixInterpreter = flag.String("revision-matrix-interpreter", "lfvm", "the interpreter checked by the revision matrix test")
+	updateRevisionMatrix      = flag.Bool("update-revision-matrix", false, "re-generate the golden file of the revision matrix test")
+)
+
+const revisionMatrixGoldenFile = "testdata/revision_matrix.golden"
+
+// revisionMatrixRevisions lists the revisions covered by the revision matrix.
+func revisionMatrixRevisions() []tosca.Revision {
+	res := []tosca.Revision{}
+	for revision := tosca.R07_Istanbul; revision <= tosca.R13_Cancun; revision++ {
+		res = append(res, revision)
+	}
+	return res
+}
+
+type revisionMatrixContract struct {
+	name string
+	code []byte
+}
+
+// revisionMatrixCorpus lists contracts exercising operations whose gas costs
+// differ between revisions. Contracts must only be appended to keep the
+// golden file stable.
+func revisionMatrixCorpus() []revisionMatrixContract {
+	push1 := func(value byte) []byte { return []byte{byte(vm.PUSH1), value} }
+	code := func(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+	op := func(ops ...vm.OpCode) []byte {
+		res := make([]byte, len(ops))
+		for i, op := range ops {
+			res[i] = byte(op)
+		}
+		return res
+	}
+	return []revisionMatrixContract{
+		{"arithmetic", code(push1(3), push1(4), op(vm.ADD), push1(2), op(vm.MUL, vm.POP, vm.STOP))},
+		{"exp", code(push1(0xff), push1(2), op(vm.EXP, vm.POP))},
+		{"memory-expansion", code(push1(1), push1(0xf0), op(vm.MSTORE), push1(0), op(vm.MLOAD, vm.POP))},
+		{"keccak", code(push1(0x40), push1(0), op(vm.SHA3, vm.POP))},
+		{"sload-repeated", code(push1(1), op(vm.SLOAD, vm.POP), push1(1), op(vm.SLOAD, vm.POP))},
+		{"sstore-set-and-reset", code(push1(1), push1(1), op(vm.SSTORE), push1(0), push1(1), op(vm.SSTORE))},
+		{"sstore-update", code(push1(3), push1(2), op(vm.SSTORE))},
+		{"balance-repeated", code(push1(7), op(vm.BALANCE, vm.POP), push1(7), op(vm.BALANCE, vm.POP))},
+		{"extcodesize", code(push1(7), op(vm.EXTCODESIZE, vm.POP))},
+		{"extcodehash", code(push1(7), op(vm.EXTCODEHASH, vm.POP))},
+		{"selfbalance", op(vm.SELFBALANCE, vm.POP)},
+		{"chainid", op(vm.CHAINID, vm.POP)},
+		{"basefee", op(vm.BASEFEE, vm.POP)},
+		{"push0", op(vm.PUSH0, vm.POP)},
+		{"log2", code(push1(2), push1(1), push1(0x20), push1(0), op(vm.LOG2))},
+		{"transient-storage", code(push1(1), push1(1), op(vm.TSTORE), push1(1), op(vm.TLOAD, vm.POP))},
+		{"mcopy", code(push1(0x20), push1(0), push1(0x20), op(vm.MCOPY))},
+		{"blobbasefee", op(vm.BLOBBASEFEE, vm.POP)},
+		{"return-data", code(push1(0x20), push1(0), op(vm.RETURN))},
+		{"revert", code(push1(0x20), push1(0), op(vm.REVERT))},
+	}
+}
+
+func TestRevisionMatrix_GasUsageMatchesGoldenFile(t *testing.T) {
+	got := runRevisionMatrix(t, *revisionMatrixInterpreter)
+
+	if *updateRevisionMatrix {
+		if err := os.MkdirAll(filepath.Dir(revisionMatrixGoldenFile), 0755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := os.WriteFile(revisionMatrixGoldenFile, []byte(got), 0644); err != nil {
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(revisionMatrixGoldenFile)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(got, "\n")
+	if len(wantLines) != len(gotLines) {
+		t.Fatalf("unexpected number of results, wanted %d, got %d", len(wantLines), len(gotLines))
+	}
+	for i := range wantLines {
+		if wantLines[i] != gotLines[i] {
+			t.Errorf("unexpected result of %s, wanted\n\t%s\ngot\n\t%s", *revisionMatrixInterpreter, wantLines[i], gotLines[i])
+		}
+	}
+}
+
+// runRevisionMatrix runs the contract corpus on all revisions using the given
+// interpreter and renders the results in the format of the golden file, one
+// line per contract and revision.
+func runRevisionMatrix(t *testing.T, variant string) string {
+	t.Helper()
+	var res strings.Builder
+	for _, contract := range revisionMatrixCorpus() {
+		for _, revision := range revisionMatrixRevisions() {
+			interpreter, err := tosca.NewInterpreter(variant)
+			if err != nil {
+				t.Fatalf("failed to load interpreter %s: %v", variant, err)
+			}
+			evm := TestEVM{
+				interpreter: interpreter,
+				revision:    revision,
+				state:       newRevisionMatrixState(gomock.NewController(t)),
+			}
+			result, err := evm.Run(contract.code, nil)
+			if err != nil {
+				t.Fatalf("failed to run %s in %v: %v", contract.name, revision, err)
+			}
+			outcome := "success"
+			if !result.Success {
+				outcome = "failure"
+			}
+			fmt.Fprintf(&res, "%-22s %-10v %-7s %d\n", contract.name, revision, outcome, result.GasUsed)
+		}
+	}
+	return res.String()
+}
+
+// newRevisionMatrixState creates a state in which all accounts are funded and
+// a single storage slot is set. Like at the start of a transaction, every
+// account other than the executed contract and every storage slot is cold
+// when accessed for the first time.
+func newRevisionMatrixState(ctrl *gomock.Controller) StateDB {
+	state := NewMockStateDB(ctrl)
+	original := map[tosca.Key]tosca.Word{{31: 2}: {31: 1}}
+	storage := map[tosca.Key]tosca.Word{{31: 2}: {31: 1}}
+	transient := map[tosca.Key]tosca.Word{}
+	accounts := map[tosca.Address]bool{{}: true} // the executed contract is warm
+	slots := map[tosca.Key]bool{}
+
+	access := func(seen bool) tosca.AccessStatus {
+		if seen {
+			return tosca.WarmAccess
+		}
+		return tosca.ColdAccess
+	}
+
+	state.EXPECT().AccountExists(gomock.Any()).AnyTimes().Return(true)
+	state.EXPECT().GetBalance(gomock.Any()).AnyTimes().Return(tosca.NewValue(1))
+	state.EXPECT().GetNonce(gomock.Any()).AnyTimes().Return(uint64(1))
+	state.EXPECT().GetCodeSize(gomock.Any()).AnyTimes().Return(0)
+	state.EXPECT().GetCodeHash(gomock.Any()).AnyTimes().Return(tosca.Hash{})
+	state.EXPECT().GetBlockHash(gomock.Any()).AnyTimes().Return(tosca.Hash{})
+	state.EXPECT().EmitLog(gomock.Any()).AnyTimes()
+	state.EXPECT().GetStorage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ tosca.Address, key tosca.Key) tosca.Word { return storage[key] })
+	state.EXPECT().GetCommittedStorage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ tosca.Address, key tosca.Key) tosca.Word { return original[key] })
+	state.EXPECT().SetStorage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Do(
+		func(_ tosca.Address, key tosca.Key, value tosca.Word) { storage[key] = value })
+	state.EXPECT().GetTransientStorage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ tosca.Address, key tosca.Key) tosca.Word { return transient[key] })
+	state.EXPECT().SetTransientStorage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Do(
+		func(_ tosca.Address, key tosca.Key, value tosca.Word) { transient[key] = value })
+	state.EXPECT().AccessAccount(gomock.Any()).AnyTimes().DoAndReturn(
+		func(address tosca.Address) tosca.AccessStatus {
+			res := access(accounts[address])
+			accounts[address] = true
+			return res
+		})
+	state.EXPECT().AccessStorage(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ tosca.Address, key tosca.Key) tosca.AccessStatus {
+			res := access(slots[key])
+			slots[key] = true
+			return res
+		})
+	state.EXPECT().IsAddressInAccessList(gomock.Any()).AnyTimes().DoAndReturn(
+		func(address tosca.Address) bool { return accounts[address] })
+	state.EXPECT().IsSlotInAccessList(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(address tosca.Address, key tosca.Key) (bool, bool) { return accounts[address], slots[key] })
+	return state
+}
@@ -11,6 +11,7 @@
 package interpreter_test
 
 import (
+	"github.com/Fantom-foundation/Tosca/go/ct/common"
 	_ "github.com/Fantom-foundation/Tosca/go/interpreter/evmone"
 	_ "github.com/Fantom-foundation/Tosca/go/interpreter/evmzero"
 	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
@@ -111,6 +112,9 @@ func (e *TestEVM) runInternal(code []byte, input []byte, gas tosca.Gas, readOnly
 	params := tosca.Parameters{
 		BlockParameters: tosca.BlockParameters{
 			Revision: e.revision,
+			// Time-based forks are only enabled by geth if the block time
+			// is in the range of the revision.
+			Timestamp: int64(common.GetForkTime(e.revision)),
 		},
 		Context: &runContextAdapter{
 			StateDB: e.state,
@@ -123,7 +127,11 @@ func (e *TestEVM) runInternal(code []byte, input []byte, gas tosca.Gas, readOnly
 		Static: readOnly,
 	}
 
-	return e.interpreter.Run(params)
+	result, err := e.interpreter.Run(params)
+	if err == nil {
+		tosca.FinalizeSelfDestruction(params.Context, params.Recipient, result.SelfDestruction)
+	}
+	return result, err
 }
 
 // --- adapter ---
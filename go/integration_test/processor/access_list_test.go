@@ -12,6 +12,8 @@ package processor
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
 	"slices"
 	"testing"
 
@@ -32,7 +34,7 @@ func TestProcessor_AccessListIsHandledCorrectly(t *testing.T) {
 	}{
 		"empty_access_list": {
 			accessList:      []tosca.AccessTuple{},
-			expectedGasUsed: tosca.Gas(140704),
+			expectedGasUsed: tosca.Gas(138904),
 		},
 		"account_access": {
 			accessList: []tosca.AccessTuple{
@@ -41,7 +43,7 @@ func TestProcessor_AccessListIsHandledCorrectly(t *testing.T) {
 					Keys:    nil,
 				},
 			},
-			expectedGasUsed: tosca.Gas(142864),
+			expectedGasUsed: tosca.Gas(141064),
 		},
 		"storage_access": {
 			accessList: []tosca.AccessTuple{
@@ -50,7 +52,7 @@ func TestProcessor_AccessListIsHandledCorrectly(t *testing.T) {
 					Keys:    []tosca.Key{accessedKey},
 				},
 			},
-			expectedGasUsed: tosca.Gas(144574),
+			expectedGasUsed: tosca.Gas(140884),
 		},
 	}
 
@@ -87,11 +89,11 @@ func TestProcessor_AccessListIsHandledCorrectly(t *testing.T) {
 					Nonce:      0,
 					AccessList: test.accessList,
 				}
-				scenario := getScenarioContext(sender, *receiver, code, gas)
+				scenario := getScenarioContext(t, sender, *receiver, code, gas)
 				transactionContext := newScenarioContext(scenario.Before)
 
 				// Run the processor
-				result, err := processor.Run(blockParams, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), blockParams, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution failed with error: %v and success %v", err, result.Success)
 				}
@@ -105,3 +107,91 @@ func TestProcessor_AccessListIsHandledCorrectly(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessor_AccessesOfRevertedCallsAreRolledBack(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	callee := tosca.Address{3}
+	target := tosca.Address{4}
+
+	// The callee touches the target and then either returns or reverts.
+	calleeCode := func(opEnd vm.OpCode) []byte {
+		code := []byte{byte(vm.PUSH20)}
+		code = append(code, target[:]...)
+		return append(code,
+			byte(vm.BALANCE),
+			byte(vm.PUSH1), 0,
+			byte(vm.PUSH1), 0,
+			byte(opEnd),
+		)
+	}
+
+	// The receiver calls the callee and returns the gas costs of a BALANCE
+	// operation on the target performed afterwards.
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH20),
+	}
+	code = append(code, callee[:]...)
+	code = append(code,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.POP),
+		byte(vm.GAS),
+		byte(vm.PUSH20),
+	)
+	code = append(code, target[:]...)
+	code = append(code,
+		byte(vm.BALANCE),
+		byte(vm.POP),
+		byte(vm.GAS),
+		byte(vm.SWAP1),
+		byte(vm.SUB),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	)
+
+	// Costs of PUSH20, POP, and GAS in addition to the BALANCE operation.
+	const overhead = 3 + 2 + 2
+	tests := map[string]struct {
+		calleeEnd vm.OpCode
+		wantCosts tosca.Gas
+	}{
+		"successful call": {calleeEnd: vm.RETURN, wantCosts: tosca.WarmAccessCost + overhead},
+		"reverted call":   {calleeEnd: vm.REVERT, wantCosts: tosca.ColdAccountAccessCost + overhead},
+	}
+
+	for processorName, processor := range getProcessors() {
+		for testName, test := range tests {
+			t.Run(processorName+"/"+testName, func(t *testing.T) {
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: code},
+					callee:   Account{Code: calleeCode(test.calleeEnd)},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  1000000,
+				}
+				blockParams := tosca.BlockParameters{Revision: tosca.R09_Berlin}
+
+				result, err := processor.Run(context.Background(), blockParams, transaction, newScenarioContext(state))
+				if err != nil || !result.Success {
+					t.Fatalf("execution failed with error: %v and success %v", err, result.Success)
+				}
+				costs := tosca.Gas(binary.BigEndian.Uint64(result.Output[24:]))
+				if want, got := test.wantCosts, costs; want != got {
+					t.Errorf("unexpected costs of BALANCE operation, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
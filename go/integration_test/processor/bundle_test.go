@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/asm"
+	"github.com/Fantom-foundation/Tosca/go/processor/bundle"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestBundle_TransactionsDependOnPredecessorsAndAreRolledBack(t *testing.T) {
+	for processorName, processor := range getProcessors() {
+		t.Run(processorName, func(t *testing.T) {
+			first := tosca.Address{1}
+			second := tosca.Address{2}
+			third := tosca.Address{3}
+			state := WorldState{
+				first: Account{Balance: tosca.NewValue(100)},
+			}
+			transactions := []tosca.Transaction{
+				// The second sender is only funded by the first transaction.
+				{Sender: first, Recipient: &second, Value: tosca.NewValue(60), GasLimit: sufficientGas},
+				{Sender: second, Recipient: &third, Value: tosca.NewValue(50), GasLimit: sufficientGas},
+			}
+
+			transactionContext := newScenarioContext(state)
+			blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+			result, err := bundle.Run(context.Background(), processor, blockParameters, transactions, transactionContext)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := len(transactions), len(result.Receipts); want != got {
+				t.Fatalf("unexpected number of receipts, wanted %d, got %d", want, got)
+			}
+			for i, receipt := range result.Receipts {
+				if !receipt.Success {
+					t.Errorf("transaction %d failed", i)
+				}
+			}
+
+			wantBalances := map[tosca.Address]tosca.Value{
+				first:  tosca.NewValue(40),
+				second: tosca.NewValue(10),
+				third:  tosca.NewValue(50),
+			}
+			for address, want := range wantBalances {
+				if got := result.StateDiff[address].Post.Balance; want != got {
+					t.Errorf("unexpected balance of %v in diff, wanted %v, got %v", address, want, got)
+				}
+			}
+			if want, got := uint64(1), result.StateDiff[second].Post.Nonce; want != got {
+				t.Errorf("unexpected nonce in diff, wanted %d, got %d", want, got)
+			}
+
+			for address := range wantBalances {
+				if want, got := state[address].Balance, transactionContext.GetBalance(address); want != got {
+					t.Errorf("state of %v got modified, wanted balance %v, got %v", address, want, got)
+				}
+			}
+		})
+	}
+}
+
+// storageTracer records the storage updates reported to it.
+type storageTracer struct {
+	tosca.NoOpTracer
+	updates [][2]tosca.Word
+}
+
+func (t *storageTracer) OnStorageChange(_ tosca.Address, _ tosca.Key, previous, current tosca.Word) {
+	t.updates = append(t.updates, [2]tosca.Word{previous, current})
+}
+
+func TestTrace_TransactionsAreTracedConcurrentlyOnSharedArchiveState(t *testing.T) {
+	// Increments the counter in slot 0.
+	code := asm.MustAssemble(`
+		PUSH1 0
+		SLOAD
+		PUSH1 1
+		ADD
+		PUSH1 0
+		SSTORE
+		STOP
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			const numTransactions = 16
+			counter := tosca.Address{0xff}
+			state := WorldState{
+				counter: Account{Code: code, Storage: Storage{{}: tosca.Word{31: 41}}},
+			}
+			for i := 0; i < numTransactions; i++ {
+				state[tosca.Address{byte(i)}] = Account{Balance: tosca.NewValue(1_000_000)}
+			}
+			archive := tosca.NewArchiveContext(newScenarioContext(state))
+			blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+
+			var wg sync.WaitGroup
+			for i := 0; i < numTransactions; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					transaction := tosca.Transaction{
+						Sender:    tosca.Address{byte(i)},
+						Recipient: &counter,
+						GasLimit:  sufficientGas,
+					}
+					tracer := &storageTracer{}
+					receipt, err := bundle.Trace(context.Background(), processor, blockParameters, transaction, archive, tracer)
+					if err != nil {
+						t.Errorf("transaction %d: unexpected error: %v", i, err)
+						return
+					}
+					if !receipt.Success {
+						t.Errorf("transaction %d failed", i)
+					}
+					// Every transaction observes the shared state, unaffected
+					// by the transactions traced in parallel.
+					want := [2]tosca.Word{{31: 41}, {31: 42}}
+					if len(tracer.updates) != 1 || tracer.updates[0] != want {
+						t.Errorf("transaction %d: unexpected storage updates, wanted %v, got %v", i, want, tracer.updates)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if want, got := (tosca.Word{31: 41}), archive.GetStorage(counter, tosca.Key{}); want != got {
+				t.Errorf("shared state got modified, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
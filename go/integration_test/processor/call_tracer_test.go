@@ -0,0 +1,124 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/calltracer"
+)
+
+func TestCallTracer_ProducesSameFramesForAllProcessors(t *testing.T) {
+	for callName, call := range callTypesAndProperties() {
+		t.Run(callName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver0 := tosca.Address{2}
+			receiver1 := tosca.Address{3}
+
+			// call the second contract and forward its result
+			code0 := pushCallArguments(call, 100_000, tosca.NewValue(1), receiver1)
+			code0 = append(code0, []byte{
+				byte(call.callType),
+				byte(vm.PUSH1), byte(32),
+				byte(vm.PUSH1), byte(0),
+				byte(vm.RETURN),
+			}...)
+
+			// revert with the caller address as output
+			code1 := []byte{
+				byte(vm.CALLER),
+				byte(vm.PUSH1), byte(0),
+				byte(vm.MSTORE),
+				byte(vm.PUSH1), byte(32),
+				byte(vm.PUSH1), byte(0),
+				byte(vm.REVERT),
+			}
+
+			state := WorldState{
+				sender:    Account{Balance: tosca.NewValue(1_000_000)},
+				receiver0: Account{Balance: tosca.NewValue(1), Code: code0},
+				receiver1: Account{Code: code1},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver0,
+				Input:     []byte{1, 2, 3},
+				GasLimit:  200_000,
+				GasPrice:  tosca.NewValue(1),
+			}
+
+			results := map[string]*calltracer.Frame{}
+			for processorName, processor := range getSimulatingProcessors(t) {
+				tracer := calltracer.New(calltracer.Config{})
+				_, err := processor.Simulate(
+					context.Background(), tosca.BlockParameters{}, transaction,
+					newScenarioContext(state), tosca.SimulationOptions{Tracer: tracer},
+				)
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", processorName, err)
+				}
+				frame := tracer.Result()
+				if frame == nil {
+					t.Fatalf("%s: no call frame recorded", processorName)
+				}
+				if want, got := 1, len(frame.Calls); want != got {
+					t.Fatalf("%s: unexpected number of nested calls, wanted %d, got %d", processorName, want, got)
+				}
+				results[processorName] = frame
+			}
+
+			nested := func(frame *calltracer.Frame) calltracer.Frame { return frame.Calls[0] }
+			for processorName, frame := range results {
+				if want, got := sender, frame.From; want != got {
+					t.Errorf("%s: unexpected sender of top-level call, wanted %v, got %v", processorName, want, got)
+				}
+				if want, got := uint64(transaction.GasLimit), uint64(frame.Gas); want != got {
+					t.Errorf("%s: unexpected gas of top-level call, wanted %d, got %d", processorName, want, got)
+				}
+				if want, got := fmt.Sprintf("%v", call.callType), nested(frame).Type; want != got {
+					t.Errorf("%s: unexpected type of nested call, wanted %s, got %s", processorName, want, got)
+				}
+				if want, got := receiver0, nested(frame).From; want != got {
+					t.Errorf("%s: unexpected source of nested call, wanted %v, got %v", processorName, want, got)
+				}
+				if to := nested(frame).To; to == nil || *to != receiver1 {
+					t.Errorf("%s: unexpected target of nested call, wanted %v, got %v", processorName, receiver1, to)
+				}
+				if want, got := "execution reverted", nested(frame).Error; want != got {
+					t.Errorf("%s: unexpected error of nested call, wanted %q, got %q", processorName, want, got)
+				}
+			}
+
+			for processorName, frame := range results {
+				for otherName, other := range results {
+					if a, b := encodeFrame(t, frame), encodeFrame(t, other); !bytes.Equal(a, b) {
+						t.Errorf("frames differ between %s and %s:\n%s\n%s", processorName, otherName, a, b)
+					}
+				}
+			}
+		})
+	}
+}
+
+func encodeFrame(t *testing.T, frame *calltracer.Frame) []byte {
+	t.Helper()
+	res, err := json.Marshal(frame)
+	if err != nil {
+		t.Fatalf("failed to encode frame: %v", err)
+	}
+	return res
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/floria"
+	opera "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestProcessor_CalldataFloorIsCharged(t *testing.T) {
+	nonZeros := bytes.Repeat([]byte{1}, 10)
+	zeros := make([]byte, 10)
+
+	// Transfers to accounts without code only pay for their intrinsic gas and
+	// Sonic's penalty of 10% of the unused gas.
+	used := func(intrinsic, limit tosca.Gas) tosca.Gas {
+		return intrinsic + (limit-intrinsic)/10
+	}
+
+	nonZeroIntrinsic := tosca.Gas(floria.TxGas + 10*floria.TxDataNonZeroGasEIP2028)
+	nonZeroFloor := tosca.Gas(21_000 + 10*4*10)
+	zeroIntrinsic := tosca.Gas(floria.TxGas + 10*floria.TxDataZeroGasEIP2028)
+	zeroFloor := tosca.Gas(21_000 + 10*10)
+
+	tests := map[string]struct {
+		input    []byte
+		gasLimit tosca.Gas
+		revision tosca.Revision
+		rejected bool
+		gasUsed  tosca.Gas
+		floor    tosca.Gas
+	}{
+		"limit below floor": {
+			input:    nonZeros,
+			gasLimit: nonZeroFloor - 1,
+			revision: tosca.R14_Prague,
+			rejected: true,
+		},
+		"limit at floor": {
+			input:    nonZeros,
+			gasLimit: nonZeroFloor,
+			revision: tosca.R14_Prague,
+			gasUsed:  nonZeroFloor,
+			floor:    nonZeroFloor,
+		},
+		"limit at floor with zero bytes": {
+			input:    zeros,
+			gasLimit: zeroFloor,
+			revision: tosca.R14_Prague,
+			gasUsed:  zeroFloor,
+			floor:    zeroFloor,
+		},
+		"floor exceeded by gas used": {
+			input:    nonZeros,
+			gasLimit: 100_000,
+			revision: tosca.R14_Prague,
+			gasUsed:  used(nonZeroIntrinsic, 100_000),
+			floor:    nonZeroFloor,
+		},
+		"no floor before Prague": {
+			input:    nonZeros,
+			gasLimit: nonZeroFloor,
+			revision: tosca.R13_Cancun,
+			gasUsed:  used(nonZeroIntrinsic, nonZeroFloor),
+		},
+		"no floor before Prague below floor": {
+			input:    zeros,
+			gasLimit: zeroFloor - 1,
+			revision: tosca.R13_Cancun,
+			gasUsed:  used(zeroIntrinsic, zeroFloor-1),
+		},
+	}
+
+	newProcessors := map[string]func(tosca.Interpreter) tosca.Processor{
+		"floria": func(interpreter tosca.Interpreter) tosca.Processor {
+			return floria.NewProcessor(interpreter, floria.Config{})
+		},
+		"opera": func(interpreter tosca.Interpreter) tosca.Processor {
+			return opera.NewProcessor(interpreter, opera.Config{})
+		},
+	}
+
+	for processorName, newProcessor := range newProcessors {
+		for interpreterName, interpreterFactory := range tosca.GetAllRegisteredInterpreters() {
+			for testName, test := range tests {
+				t.Run(fmt.Sprintf("%s/%s/%s", processorName, interpreterName, testName), func(t *testing.T) {
+					interpreter, err := interpreterFactory(nil)
+					if err != nil {
+						t.Fatalf("failed to create interpreter: %v", err)
+					}
+					processor := newProcessor(interpreter)
+
+					sender := tosca.Address{1}
+					recipient := tosca.Address{2}
+					state := WorldState{
+						sender: Account{Balance: tosca.NewValue(1 << 40)},
+					}
+					transaction := tosca.Transaction{
+						Sender:    sender,
+						Recipient: &recipient,
+						GasLimit:  test.gasLimit,
+						GasPrice:  tosca.NewValue(1),
+						Input:     test.input,
+					}
+					blockParameters := tosca.BlockParameters{Revision: test.revision}
+					txContext := newScenarioContext(state)
+					receipt, err := processor.Run(context.Background(), blockParameters, transaction, txContext)
+
+					if test.rejected {
+						// Opera reports invalid transactions as errors, floria
+						// charges them the full gas limit without executing them.
+						if err == nil && (receipt.Success || receipt.GasUsed != test.gasLimit) {
+							t.Errorf("transaction below the calldata floor was accepted, got %+v", receipt)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if !receipt.Success {
+						t.Fatalf("transaction failed")
+					}
+					if want, got := test.gasUsed, receipt.GasUsed; want != got {
+						t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+					}
+					if want, got := test.floor, receipt.CalldataFloorGas; want != got {
+						t.Errorf("unexpected calldata floor, wanted %d, got %d", want, got)
+					}
+					balance := txContext.GetBalance(sender)
+					if want, got := tosca.NewValue(1<<40-uint64(test.gasUsed)), balance; want != got {
+						t.Errorf("unexpected balance of sender, wanted %v, got %v", want, got)
+					}
+				})
+			}
+		}
+	}
+}
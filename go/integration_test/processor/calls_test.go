@@ -12,6 +12,7 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 	"slices"
@@ -107,11 +108,11 @@ func TestProcessor_MaximalCallDepthIsEnforced(t *testing.T) {
 				Nonce:     0,
 				Input:     tosca.Data{},
 			}
-			scenario := getScenarioContext(sender, *receiver, code, sufficientGas)
+			scenario := getScenarioContext(t, sender, *receiver, code, sufficientGas)
 			transactionContext := newScenarioContext(scenario.Before)
 
 			// Run the processor
-			result, err := processor.Run(blockParams, transaction, transactionContext)
+			result, err := processor.Run(context.Background(), blockParams, transaction, transactionContext)
 
 			// Check the result.
 			if err != nil || !result.Success {
@@ -176,7 +177,7 @@ func TestProcessor_DifferentCallTypesAccessStorage(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -239,7 +240,7 @@ func TestProcessor_DifferentCallTypesHandleValueCorrectly(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -303,7 +304,7 @@ func TestProcessor_DifferentCallTypesSetTheCorrectSender(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -384,7 +385,7 @@ func TestProcessor_RecursiveCallsAfterAStaticCallAreStatic(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -438,7 +439,7 @@ func TestProcessor_CallingNonExistentAccountIsHandledCorrectly(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil {
 					t.Errorf("execution failed with error %v", err)
 				}
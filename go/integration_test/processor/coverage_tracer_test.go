@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/coverage"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCoverageTracer_ProducesSameCoverageForAllProcessors(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+
+	// skip the instructions between the jump and its destination
+	code := []byte{
+		byte(vm.PUSH1), 6, // 0
+		byte(vm.JUMP),     // 2
+		byte(vm.PUSH1), 1, // 3
+		byte(vm.STOP),     // 5
+		byte(vm.JUMPDEST), // 6
+		byte(vm.STOP),     // 7
+	}
+	codeHash := tosca.Hash(crypto.Keccak256(code))
+
+	state := WorldState{
+		sender:   Account{Balance: tosca.NewValue(1_000_000)},
+		receiver: Account{Code: code},
+	}
+	transaction := tosca.Transaction{
+		Sender:    sender,
+		Recipient: &receiver,
+		GasLimit:  100_000,
+		GasPrice:  tosca.NewValue(1),
+	}
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		ctxt := newScenarioContext(state)
+		tracer := coverage.New(ctxt)
+		_, err := processor.Simulate(
+			context.Background(), tosca.BlockParameters{}, transaction,
+			ctxt, tosca.SimulationOptions{Tracer: tracer},
+		)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", processorName, err)
+		}
+
+		covered := tracer.Transaction()[codeHash]
+		if covered == nil {
+			t.Fatalf("%s: code was not covered", processorName)
+		}
+		if want, got := []int{0, 2, 6, 7}, covered.List(); !slices.Equal(want, got) {
+			t.Errorf("%s: unexpected coverage, wanted %v, got %v", processorName, want, got)
+		}
+	}
+}
@@ -12,7 +12,9 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"math"
 	"math/big"
 	"slices"
 	"testing"
@@ -136,7 +138,7 @@ func TestProcessor_CreateAndCallContract(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -286,7 +288,7 @@ func TestProcessor_CreateInitCodeIsExecutedInRightContext(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -327,7 +329,7 @@ func TestProcessor_EmptyReceiverCreatesAccount(t *testing.T) {
 		transactionContext := newScenarioContext(state)
 
 		// Run the processor
-		result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+		result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 		if err != nil || !result.Success {
 			t.Errorf("execution was not successful or failed with error %v", err)
 		}
@@ -450,7 +452,7 @@ func TestProcessor_CorrectAddressIsCreated(t *testing.T) {
 				}
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -530,7 +532,7 @@ func TestProcessor_CreateExistingAccountFails(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(tosca.BlockParameters{}, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
 				if err != nil {
 					t.Errorf("execution failed with error %v", err)
 				}
@@ -563,6 +565,65 @@ func TestProcessor_CreateExistingAccountFails(t *testing.T) {
 	}
 }
 
+func TestProcessor_Create2IntoOccupiedAddressFails(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	salt := tosca.Hash{31: 7}
+
+	// CREATE2 with an empty init code, returning the created address
+	code := []byte{
+		byte(vm.PUSH1), salt[31],
+		byte(vm.PUSH1), 0, // size
+		byte(vm.PUSH1), 0, // offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.CREATE2),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	target := tosca.ComputeCreate2Address(receiver, salt, tosca.Hash(crypto.Keccak256(nil)))
+
+	tests := map[string]struct {
+		account Account
+		success bool
+	}{
+		"empty":     {account: Account{Balance: tosca.NewValue(1)}, success: true},
+		"withNonce": {account: Account{Nonce: 1}, success: false},
+		"withCode":  {account: Account{Code: []byte{byte(vm.STOP)}}, success: false},
+	}
+
+	for processorName, processor := range getProcessors() {
+		for testName, test := range tests {
+			t.Run(processorName+"/"+testName, func(t *testing.T) {
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: code},
+					target:   test.account,
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  sufficientGas,
+				}
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, newScenarioContext(state))
+				if err != nil || !result.Success {
+					t.Fatalf("execution failed with error: %v and success %v", err, result.Success)
+				}
+
+				want := tosca.Address{}
+				if test.success {
+					want = target
+				}
+				if got := tosca.Address(result.Output[12:]); want != got {
+					t.Errorf("unexpected created address, wanted %v, got %v", want, got)
+				}
+			})
+		}
+	}
+}
+
 func TestProcessor_CodeStartingWith0xEFCanNotBeCreated(t *testing.T) {
 	tests := map[string]struct {
 		revision         tosca.Revision
@@ -629,7 +690,7 @@ func TestProcessor_CodeStartingWith0xEFCanNotBeCreated(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(blockParameters, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
@@ -695,7 +756,7 @@ func TestProcessor_CodeSizeIsLimited(t *testing.T) {
 				transactionContext := newScenarioContext(state)
 
 				// Run the processor
-				result, err := processor.Run(blockParameters, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
@@ -719,6 +780,229 @@ func TestProcessor_CodeSizeIsLimited(t *testing.T) {
 	}
 }
 
+func TestProcessor_InitCodeSizeIsLimited(t *testing.T) {
+	maxInitCodeSize := 49152
+	tests := map[string]struct {
+		revision tosca.Revision
+		length   int
+		accepted bool
+	}{
+		"threshold": {
+			revision: tosca.R12_Shanghai,
+			length:   maxInitCodeSize,
+			accepted: true,
+		},
+		"exceedingThreshold": {
+			revision: tosca.R12_Shanghai,
+			length:   maxInitCodeSize + 1,
+			accepted: false,
+		},
+		"exceedingThresholdBeforeShanghai": {
+			revision: tosca.R11_Paris,
+			length:   maxInitCodeSize + 1,
+			accepted: true,
+		},
+	}
+
+	for processorName, processor := range getProcessors() {
+		for testName, test := range tests {
+			t.Run(processorName+"/"+testName, func(t *testing.T) {
+				sender := tosca.Address{1}
+				addressToBeCreated := tosca.Address(crypto.CreateAddress(common.Address(sender), 0))
+
+				// The init code deploys a single byte, padded to the tested length.
+				initCode := []byte{
+					byte(vm.PUSH1), byte(1),
+					byte(vm.PUSH1), byte(0),
+					byte(vm.RETURN),
+				}
+				initCode = append(initCode, make([]byte, test.length-len(initCode))...)
+
+				gasPrice := uint64(2)
+				gasLimit := tosca.Gas(1_000_000)
+				senderBalance := tosca.NewValue(uint64(gasLimit) * gasPrice)
+				state := WorldState{
+					sender: Account{Balance: senderBalance},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: nil,
+					GasLimit:  gasLimit,
+					GasPrice:  tosca.NewValue(gasPrice),
+					Input:     initCode,
+				}
+
+				blockParameters := tosca.BlockParameters{Revision: test.revision}
+				transactionContext := newScenarioContext(state)
+
+				// Rejected transactions are reported as errors by some processors.
+				result, _ := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
+				if result.Success != test.accepted {
+					t.Errorf("execution success was %v, expected %v", result.Success, test.accepted)
+				}
+				code := transactionContext.GetCode(addressToBeCreated)
+				if test.accepted {
+					if len(code) == 0 {
+						t.Errorf("code has not been set correctly")
+					}
+					return
+				}
+				if len(code) != 0 {
+					t.Errorf("code should have not been set but got %v", code)
+				}
+				if result.GasUsed != 0 {
+					t.Errorf("rejected transaction should not use gas, used %d", result.GasUsed)
+				}
+				if want, got := senderBalance, transactionContext.GetBalance(sender); want != got {
+					t.Errorf("unexpected sender balance, wanted %v, got %v", want, got)
+				}
+				if want, got := uint64(0), transactionContext.GetNonce(sender); want != got {
+					t.Errorf("unexpected sender nonce, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestProcessor_InitCodeIsChargedPerWord(t *testing.T) {
+	const (
+		length          = 33 // 2 words of zero bytes, each a STOP instruction
+		creationGas     = 53_000
+		zeroByteGas     = 4
+		initCodeWordGas = 2
+	)
+	tests := map[string]struct {
+		revision tosca.Revision
+		gasUsed  tosca.Gas
+	}{
+		"beforeShanghai": {
+			revision: tosca.R11_Paris,
+			gasUsed:  creationGas + length*zeroByteGas,
+		},
+		"sinceShanghai": {
+			revision: tosca.R12_Shanghai,
+			gasUsed:  creationGas + length*zeroByteGas + 2*initCodeWordGas,
+		},
+	}
+
+	for processorName, processor := range getProcessors() {
+		for testName, test := range tests {
+			t.Run(processorName+"/"+testName, func(t *testing.T) {
+				sender := tosca.Address{1}
+				state := WorldState{
+					sender: Account{},
+				}
+				// The gas limit covers exactly the intrinsic gas since
+				// running the init code is free.
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: nil,
+					GasLimit:  test.gasUsed,
+					Input:     make([]byte, length),
+				}
+
+				blockParameters := tosca.BlockParameters{Revision: test.revision}
+				transactionContext := newScenarioContext(state)
+
+				result, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Errorf("execution was not successful")
+				}
+				if want, got := test.gasUsed, result.GasUsed; want != got {
+					t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestProcessor_CreateFailsForCreatorsWithMaximumNonce(t *testing.T) {
+	// Creates an empty contract and returns the created address.
+	code := []byte{
+		byte(vm.PUSH1), byte(0), // size
+		byte(vm.PUSH1), byte(0), // offset
+		byte(vm.PUSH1), byte(0), // value
+		byte(vm.CREATE),
+		byte(vm.PUSH1), byte(0),
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), byte(32),
+		byte(vm.PUSH1), byte(0),
+		byte(vm.RETURN),
+	}
+
+	for processorName, processor := range getProcessors() {
+		for _, nonce := range []uint64{math.MaxUint64 - 1, math.MaxUint64} {
+			t.Run(fmt.Sprintf("%s/nonce=%d", processorName, nonce), func(t *testing.T) {
+				sender := tosca.Address{1}
+				creator := tosca.Address{2}
+				state := WorldState{
+					sender:  Account{},
+					creator: Account{Code: code, Nonce: nonce},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &creator,
+					GasLimit:  sufficientGas,
+				}
+
+				transactionContext := newScenarioContext(state)
+				result, err := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Fatalf("execution was not successful")
+				}
+
+				created := tosca.Address(crypto.CreateAddress(common.Address(creator), nonce))
+				wantOutput := make([]byte, 32)
+				wantNonce := nonce
+				if nonce < math.MaxUint64 {
+					copy(wantOutput[12:], created[:])
+					wantNonce = nonce + 1
+				}
+				if !bytes.Equal(wantOutput, result.Output) {
+					t.Errorf("unexpected output, wanted %x, got %x", wantOutput, result.Output)
+				}
+				if want, got := wantNonce, transactionContext.GetNonce(creator); want != got {
+					t.Errorf("unexpected nonce of creator, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestProcessor_TransactionsOfSendersWithMaximumNonceAreRejected(t *testing.T) {
+	for processorName, processor := range getProcessors() {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender: Account{Nonce: math.MaxUint64},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  sufficientGas,
+				Nonce:     math.MaxUint64,
+			}
+
+			transactionContext := newScenarioContext(state)
+			// Rejected transactions are reported as errors by some processors.
+			result, _ := processor.Run(context.Background(), tosca.BlockParameters{}, transaction, transactionContext)
+			if result.Success || result.GasUsed != 0 {
+				t.Errorf("transaction should have been rejected, got %v", result)
+			}
+			if want, got := uint64(math.MaxUint64), transactionContext.GetNonce(sender); want != got {
+				t.Errorf("unexpected nonce of sender, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
 func saveCodeFromAccountToMemory(account tosca.Address, length byte, offset byte) []byte {
 	addressPush := vm.PUSH1 + vm.OpCode(len(tosca.Address{0})-1)
 	code := []byte{}
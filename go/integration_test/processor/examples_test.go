@@ -38,7 +38,7 @@ func TestProcessor_Examples(t *testing.T) {
 			for i := 0; i < 10; i++ {
 				t.Run(fmt.Sprintf("%s-%s-%d", example.Name, processorName, i), func(t *testing.T) {
 					want := example.RunReference(i)
-					scenario := getScenarioContext(tosca.Address{1}, tosca.Address{2}, example.Code, tosca.Gas(1000000))
+					scenario := getScenarioContext(t, tosca.Address{1}, tosca.Address{2}, example.Code, tosca.Gas(1000000))
 					transactionContext := newScenarioContext(scenario.Before)
 
 					got, err := example.RunOnProcessor(processor, i, scenario.Transaction, transactionContext)
@@ -54,22 +54,10 @@ func TestProcessor_Examples(t *testing.T) {
 	}
 }
 
-func getScenarioContext(sender, receiver tosca.Address, code []byte, gasLimit tosca.Gas) Scenario {
-	scenario := Scenario{
-		Before: WorldState{
-			sender:   Account{},
-			receiver: Account{Code: code},
-		},
-		Transaction: tosca.Transaction{
-			Sender:    sender,
-			Recipient: &receiver,
-			GasLimit:  gasLimit,
-		},
-		After: WorldState{
-			sender:   Account{},
-			receiver: Account{Code: code},
-		},
-	}
-
-	return scenario
+func getScenarioContext(t *testing.T, sender, receiver tosca.Address, code []byte, gasLimit tosca.Gas) Scenario {
+	return NewScenario().
+		Account(sender).
+		Account(receiver, Code(code)).
+		Transaction(Call(sender, receiver, gasLimit)).
+		Build(t)
 }
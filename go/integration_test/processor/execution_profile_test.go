@@ -0,0 +1,113 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/floria"
+	opera "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestProcessor_ProfilerRecordsTransactionsAndContracts(t *testing.T) {
+	newProcessors := map[string]func(tosca.Interpreter, *tosca.ExecutionProfiler) tosca.Processor{
+		"floria": func(interpreter tosca.Interpreter, profiler *tosca.ExecutionProfiler) tosca.Processor {
+			return floria.NewProcessor(interpreter, floria.Config{Profiler: profiler})
+		},
+		"opera": func(interpreter tosca.Interpreter, profiler *tosca.ExecutionProfiler) tosca.Processor {
+			return opera.NewProcessor(interpreter, opera.Config{Profiler: profiler})
+		},
+	}
+
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	callee := tosca.Address{3}
+
+	// The receiver calls the callee, which stores a value.
+	code := []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH20),
+	}
+	code = append(code, callee[:]...)
+	code = append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.STOP))
+	calleeCode := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 0, byte(vm.SSTORE)}
+
+	for processorName, newProcessor := range newProcessors {
+		for interpreterName, interpreterFactory := range tosca.GetAllRegisteredInterpreters() {
+			t.Run(fmt.Sprintf("%s/%s", processorName, interpreterName), func(t *testing.T) {
+				interpreter, err := interpreterFactory(nil)
+				if err != nil {
+					t.Fatalf("failed to create interpreter: %v", err)
+				}
+				profiler := tosca.NewExecutionProfiler()
+				processor := newProcessor(interpreter, profiler)
+
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: code},
+					callee:   Account{Code: calleeCode},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  100_000,
+				}
+				blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+				receipt, err := processor.Run(context.Background(), blockParameters, transaction, newScenarioContext(state))
+				if err != nil || !receipt.Success {
+					t.Fatalf("execution failed with error: %v and success %v", err, receipt.Success)
+				}
+
+				transactions := profiler.Transactions()
+				if want, got := 1, len(transactions); want != got {
+					t.Fatalf("unexpected number of transactions, wanted %d, got %d", want, got)
+				}
+				if want, got := receipt.GasUsed, transactions[0].GasUsed; want != got {
+					t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+				}
+
+				profiles := map[tosca.Address]tosca.ContractProfile{}
+				for _, profile := range profiler.Contracts() {
+					profiles[profile.Address] = profile
+				}
+				// The geth interpreter conducts nested calls internally, which
+				// are thus attributed to the calling contract.
+				if interpreterName == "geth" {
+					if _, found := profiles[receiver]; !found {
+						t.Fatalf("missing profile of %v", receiver)
+					}
+					return
+				}
+				for _, address := range []tosca.Address{receiver, callee} {
+					profile, found := profiles[address]
+					if !found {
+						t.Fatalf("missing profile of %v", address)
+					}
+					if profile.Calls != 1 || profile.GasUsed <= 0 {
+						t.Errorf("unexpected profile of %v, got %+v", address, profile)
+					}
+				}
+				// The callee is dominated by the costs of its SSTORE.
+				if profiles[callee].GasUsed < tosca.Gas(20_000) {
+					t.Errorf("unexpected gas used by callee, got %d", profiles[callee].GasUsed)
+				}
+			})
+		}
+	}
+}
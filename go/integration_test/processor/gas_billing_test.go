@@ -15,18 +15,16 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
-	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
-	op "github.com/ethereum/go-ethereum/core/vm"
 	"go.uber.org/mock/gomock"
 
 	_ "github.com/Fantom-foundation/Tosca/go/processor/opera" // < registers opera processor for testing
 )
 
 func TestProcessor_GasBillingEndToEnd(t *testing.T) {
-	senderBalance := tosca.NewValue(1000000)
+	senderBalance := uint64(1000000)
 	gasLimit := tosca.Gas(100000)
 	gasRefund := tosca.Gas(3000)
-	gasPrice := tosca.NewValue(5)
+	gasPrice := uint64(5)
 	gasLeftSuccess := tosca.Gas(5000)
 
 	tests := map[string]struct {
@@ -59,47 +57,31 @@ func TestProcessor_GasBillingEndToEnd(t *testing.T) {
 
 	sender := tosca.Address{1}
 	recipient := tosca.Address{2}
-	before := WorldState{
-		sender: Account{Balance: senderBalance, Nonce: 4},
-		recipient: Account{Balance: tosca.NewValue(0),
-			Code: tosca.Code{
-				byte(vm.PUSH1), byte(0), // < push 0
-				byte(vm.PUSH1), byte(0), // < push 0
-				byte(op.RETURN),
-			},
-		},
-	}
-
 	transaction := tosca.Transaction{
 		Sender:    sender,
 		Recipient: &recipient,
 		GasLimit:  gasLimit,
-		GasPrice:  gasPrice,
+		GasPrice:  tosca.NewValue(gasPrice),
 		Nonce:     4,
 	}
 
 	for name, test := range tests {
 		for processorName, processor := range processorsWithInterpreter("mockInterpreter", interpreter) {
 			t.Run(fmt.Sprintf("%s/%s", processorName, name), func(t *testing.T) {
-
-				after := before.Clone()
-				afterBalance := tosca.Sub(senderBalance, gasPrice.Scale(uint64(test.gasUsed)))
-				after[sender] = Account{Balance: afterBalance, Nonce: after[sender].Nonce + 1}
-
-				receipt := tosca.Receipt{
-					Success: test.success,
-					GasUsed: test.gasUsed,
-				}
-
-				scenario := Scenario{
-					Before:      before,
-					Transaction: transaction,
-					After:       after,
-					Receipt:     receipt,
-				}
-
 				interpreter.EXPECT().Run(gomock.Any()).Return(test.result, nil)
-				scenario.Run(t, processor)
+				NewScenario().
+					Account(sender, Balance(senderBalance), Nonce(4)).
+					Account(recipient, CodeFromMnemonics("PUSH1 0", "PUSH1 0", "RETURN")).
+					Transaction(transaction).
+					ExpectReceipt(tosca.Receipt{
+						Success: test.success,
+						GasUsed: test.gasUsed,
+					}).
+					ExpectAccount(sender,
+						Balance(senderBalance-gasPrice*uint64(test.gasUsed)),
+						Nonce(5),
+					).
+					Run(t, processor)
 			})
 		}
 	}
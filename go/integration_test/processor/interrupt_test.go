@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestProcessor_InterruptedExecutionIsAborted(t *testing.T) {
+	tests := map[string]struct {
+		interrupt func() (context.Context, context.CancelFunc)
+		want      error
+	}{
+		"cancelled": {
+			interrupt: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			want: context.Canceled,
+		},
+		"timeout": {
+			interrupt: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			want: context.DeadlineExceeded,
+		},
+	}
+
+	// An endless loop, only stopped by running out of gas.
+	code := []byte{
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH1), byte(0),
+		byte(vm.JUMP),
+	}
+
+	for processorName, processor := range getProcessors() {
+		for name, test := range tests {
+			t.Run(processorName+"/"+name, func(t *testing.T) {
+				sender := tosca.Address{1}
+				receiver := tosca.Address{2}
+
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: code},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  10_000_000,
+				}
+
+				ctx, cancel := test.interrupt()
+				defer cancel()
+				<-ctx.Done()
+
+				transactionContext := newScenarioContext(state)
+				_, err := processor.Run(ctx, tosca.BlockParameters{}, transaction, transactionContext)
+				if !errors.Is(err, test.want) {
+					t.Errorf("unexpected error, wanted %v, got %v", test.want, err)
+				}
+			})
+		}
+	}
+}
@@ -12,6 +12,7 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/big"
 	"slices"
@@ -94,7 +95,7 @@ func TestProcessor_PreCompiledContractsCanBeProcessed(t *testing.T) {
 				blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
 
 				// Run the processor
-				result, err := processor.Run(blockParameters, transaction, transactionContext)
+				result, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
 				if err != nil || !result.Success {
 					t.Errorf("execution was not successful or failed with error %v", err)
 				}
@@ -178,7 +179,7 @@ func TestPrecompiled_StatePrecompiledContractSetBalance(t *testing.T) {
 			blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
 
 			// Run the processor
-			result, err := processor.Run(blockParameters, transaction, transactionContext)
+			result, err := processor.Run(context.Background(), blockParameters, transaction, transactionContext)
 			if err != nil || !result.Success {
 				t.Errorf("execution was not successful or failed with error %v", err)
 			}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestPrestateTracer_ProducesSameStateForAllProcessors(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver0 := tosca.Address{2}
+	receiver1 := tosca.Address{3}
+
+	// store the balance of the second contract in slot 1 and read slot 2
+	code := append([]byte{byte(vm.PUSH20)}, receiver1[:]...)
+	code = append(code, []byte{
+		byte(vm.BALANCE),
+		byte(vm.PUSH1), byte(1),
+		byte(vm.SSTORE),
+		byte(vm.PUSH1), byte(2),
+		byte(vm.SLOAD),
+		byte(vm.STOP),
+	}...)
+
+	state := WorldState{
+		sender:    Account{Balance: tosca.NewValue(1_000_000)},
+		receiver0: Account{Code: code, Storage: Storage{tosca.Key(tosca.NewValue(2)): tosca.Word{3}}},
+		receiver1: Account{Balance: tosca.NewValue(7)},
+	}
+	transaction := tosca.Transaction{
+		Sender:    sender,
+		Recipient: &receiver0,
+		Value:     tosca.NewValue(10),
+		GasLimit:  100_000,
+		GasPrice:  tosca.NewValue(1),
+	}
+
+	for _, diffMode := range []bool{false, true} {
+		results := map[string]string{}
+		for processorName, processor := range getSimulatingProcessors(t) {
+			ctxt := newScenarioContext(state)
+			tracer := prestate.New(ctxt, prestate.Config{DiffMode: diffMode})
+			_, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				ctxt, tosca.SimulationOptions{Tracer: tracer},
+			)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", processorName, err)
+			}
+
+			pre := tracer.Result()
+			if diff, ok := pre.(prestate.Diff); ok {
+				pre = diff.Pre
+				if post := diff.Post[receiver0]; post == nil || post.Storage[common.Hash(tosca.NewValue(1))] != common.Hash(tosca.NewValue(7)) {
+					t.Errorf("%s: unexpected post-state of receiver: %v", processorName, post)
+				}
+			}
+			if account := pre.(prestate.State)[sender]; account == nil || account.Balance.ToInt().Uint64() != 1_000_000 {
+				t.Errorf("%s: unexpected pre-state of sender: %v", processorName, account)
+			}
+
+			result, err := tracer.GetResult()
+			if err != nil {
+				t.Fatalf("%s: failed to get result: %v", processorName, err)
+			}
+			results[processorName] = string(result)
+		}
+
+		for processorName, result := range results {
+			for otherName, other := range results {
+				if result != other {
+					t.Errorf("diff mode %t: states differ between %s and %s:\n%s\n%s", diffMode, processorName, otherName, result, other)
+				}
+			}
+		}
+	}
+}
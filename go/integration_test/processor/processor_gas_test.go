@@ -17,140 +17,94 @@ import (
 
 	"github.com/Fantom-foundation/Tosca/go/processor/floria"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
-	op "github.com/ethereum/go-ethereum/core/vm"
 )
 
-func gasTestScenarios() map[string]Scenario {
-	exactTestCases := map[string]Scenario{
-		"ValueTransfer": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
+func gasTestScenarios(t *testing.T) map[string]Scenario {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	exactTestCases := map[string]*ScenarioBuilder{
+		"ValueTransfer": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas,
 				Value:     tosca.NewValue(3),
 				Nonce:     4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(97), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(3)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas,
-			},
-		},
-		"InputZeros": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas).
+			ExpectAccount(sender, Balance(97), Nonce(5)).
+			ExpectAccount(receiver, Balance(3)),
+		"InputZeros": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas + floria.TxDataZeroGasEIP2028*10,
 				Nonce:     4,
 				Input:     []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas + floria.TxDataZeroGasEIP2028*10,
-			},
-		},
-		"InputNonZeros": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas+floria.TxDataZeroGasEIP2028*10).
+			ExpectAccount(sender, Nonce(5)),
+		"InputNonZeros": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas + floria.TxDataNonZeroGasEIP2028*10,
 				Nonce:     4,
 				Input:     []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas + floria.TxDataNonZeroGasEIP2028*10,
-			},
-		},
-		"AccessListOnlyAddresses": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas+floria.TxDataNonZeroGasEIP2028*10).
+			ExpectAccount(sender, Nonce(5)),
+		"AccessListOnlyAddresses": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas + floria.TxAccessListAddressGas*2,
 				Nonce:     4,
 				AccessList: []tosca.AccessTuple{
-					{Address: tosca.Address{1},
-						Keys: []tosca.Key{},
-					},
-					{Address: tosca.Address{2},
-						Keys: []tosca.Key{},
-					},
+					{Address: sender, Keys: []tosca.Key{}},
+					{Address: receiver, Keys: []tosca.Key{}},
 				},
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas + floria.TxAccessListAddressGas*2,
-			},
-		},
-		"AccessList": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas+floria.TxAccessListAddressGas*2).
+			ExpectAccount(sender, Nonce(5)),
+		"AccessList": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas + floria.TxAccessListAddressGas*2 + floria.TxAccessListStorageKeyGas*5,
 				Nonce:     4,
 				AccessList: []tosca.AccessTuple{
-					{Address: tosca.Address{1},
-						Keys: []tosca.Key{{1}, {2}},
-					},
-					{Address: tosca.Address{2},
-						Keys: []tosca.Key{{1}, {2}, {3}},
-					},
+					{Address: sender, Keys: []tosca.Key{{1}, {2}}},
+					{Address: receiver, Keys: []tosca.Key{{1}, {2}, {3}}},
 				},
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas + floria.TxAccessListAddressGas*2 + floria.TxAccessListStorageKeyGas*5,
-			},
-		},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas+floria.TxAccessListAddressGas*2+floria.TxAccessListStorageKeyGas*5).
+			ExpectAccount(sender, Nonce(5)),
 	}
 
 	testCases := make(map[string]Scenario)
 	for name, exactScenario := range exactTestCases {
-		gasTests := exactSufficientAndInsufficientScenarios(exactScenario, name)
+		gasTests := exactSufficientAndInsufficientScenarios(exactScenario.Build(t), name)
 		maps.Copy(testCases, gasTests)
 	}
 	return testCases
 }
 
-func gasLimitTestCases() map[string]Scenario {
+func gasLimitTestCases(t *testing.T) map[string]Scenario {
 	// cost for 2 PUSH1 operations
 	const executionGasCost = 3 + 3
 
@@ -183,73 +137,60 @@ func gasLimitTestCases() map[string]Scenario {
 		},
 	}
 
-	code := tosca.Code{
-		byte(op.PUSH1), byte(0), // < PUSH 0
-		byte(op.PUSH1), byte(0), // < PUSH 0
-		byte(op.RETURN),
-	}
-	before := WorldState{
-		{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-		{2}: Account{Balance: tosca.NewValue(0),
-			Code: code,
-		},
-	}
-	after := WorldState{
-		{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-		{2}: Account{Balance: tosca.NewValue(0),
-			Code: code,
-		},
-	}
-
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
 	testCases := make(map[string]Scenario, len(cases))
 	for name, test := range cases {
-		transaction := tosca.Transaction{
-			Sender:    tosca.Address{1},
-			Recipient: &tosca.Address{2},
-			GasLimit:  test.gasLimit,
-			Nonce:     4,
-		}
-
-		testCases[name] = Scenario{
-			Before:      before,
-			Transaction: transaction,
-			After:       after,
-			Receipt:     test.receipt,
-			OperaError:  test.OperaError,
-		}
+		testCases[name] = NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver, CodeFromMnemonics("PUSH1 0", "PUSH1 0", "RETURN")).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  test.gasLimit,
+				Nonce:     4,
+			}).
+			ExpectReceipt(test.receipt).
+			ExpectOperaError(test.OperaError).
+			ExpectAccount(sender, Nonce(5)).
+			Build(t)
 	}
 
 	return testCases
 }
 
-func gasPricingTestCases() map[string]Scenario {
+func gasPricingTestCases(t *testing.T) map[string]Scenario {
 	gasPrice := uint64(10)
 	sender := tosca.Address{1}
 	tests := map[string]struct {
-		Before     Account
-		After      Account
-		Receipt    tosca.Receipt
-		OperaError error
+		balanceBefore uint64
+		balanceAfter  uint64
+		nonceAfter    uint64
+		Receipt       tosca.Receipt
+		OperaError    error
 	}{
 		"GasPriceCalculation": {
-			Before: Account{Balance: tosca.NewValue(floria.TxGas * gasPrice), Nonce: 4},
-			After:  Account{Balance: tosca.NewValue(0), Nonce: 5},
+			balanceBefore: floria.TxGas * gasPrice,
+			balanceAfter:  0,
+			nonceAfter:    5,
 			Receipt: tosca.Receipt{
 				Success: true,
 				GasUsed: floria.TxGas,
 			},
 		},
 		"GasPriceCalculationExcessBalance": {
-			Before: Account{Balance: tosca.NewValue(floria.TxGas*gasPrice + 100), Nonce: 4},
-			After:  Account{Balance: tosca.NewValue(100), Nonce: 5},
+			balanceBefore: floria.TxGas*gasPrice + 100,
+			balanceAfter:  100,
+			nonceAfter:    5,
 			Receipt: tosca.Receipt{
 				Success: true,
 				GasUsed: floria.TxGas,
 			},
 		},
 		"GasPriceCalculationInsufficientBalance": {
-			Before: Account{Balance: tosca.NewValue(floria.TxGas*gasPrice - 1), Nonce: 4},
-			After:  Account{Balance: tosca.NewValue(floria.TxGas*gasPrice - 1), Nonce: 4},
+			balanceBefore: floria.TxGas*gasPrice - 1,
+			balanceAfter:  floria.TxGas*gasPrice - 1,
+			nonceAfter:    4,
 			Receipt: tosca.Receipt{
 				Success: false,
 				GasUsed: 0,
@@ -268,54 +209,49 @@ func gasPricingTestCases() map[string]Scenario {
 
 	testCases := make(map[string]Scenario, len(tests))
 	for name, test := range tests {
-		testCases[name] = Scenario{
-			Before:      WorldState{sender: test.Before},
-			Transaction: transaction,
-			After:       WorldState{sender: test.After},
-			Receipt:     test.Receipt,
-			OperaError:  test.OperaError,
-		}
+		testCases[name] = NewScenario().
+			Account(sender, Balance(test.balanceBefore), Nonce(4)).
+			Transaction(transaction).
+			ExpectReceipt(test.Receipt).
+			ExpectOperaError(test.OperaError).
+			ExpectAccount(sender, Balance(test.balanceAfter), Nonce(test.nonceAfter)).
+			Build(t)
 	}
 
 	return testCases
 }
 
-func gasSpecificTestCases() map[string]Scenario {
+func gasSpecificTestCases(t *testing.T) map[string]Scenario {
+	sender := tosca.Address{}
+	receiver := tosca.Address{2}
 	cases := map[string]Scenario{
-		"InternalCallDoesNotConsume10PercentOfRemainingGas": {
-			Before: WorldState{
-				{}:  Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{},
-				Recipient: &tosca.Address{2},
+		"InternalCallDoesNotConsume10PercentOfRemainingGas": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver).
+			Transaction(tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
 				GasLimit:  floria.TxGas + 100,
 				Nonce:     4,
-			},
-			After: WorldState{
-				{}:  Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: floria.TxGas,
-			},
-		},
+			}).
+			ExpectSuccess().
+			ExpectGasUsed(floria.TxGas).
+			ExpectAccount(sender, Nonce(5)).
+			Build(t),
 	}
 	return cases
 }
 
-func getGasTestScenarios() map[string]Scenario {
-	testCases := gasTestScenarios()
+func getGasTestScenarios(t *testing.T) map[string]Scenario {
+	testCases := gasTestScenarios(t)
 
-	specificCases := gasLimitTestCases()
+	specificCases := gasLimitTestCases(t)
 	maps.Copy(testCases, specificCases)
 
-	refundCases := gasPricingTestCases()
+	refundCases := gasPricingTestCases(t)
 	maps.Copy(testCases, refundCases)
 
-	specificCases = gasSpecificTestCases()
+	specificCases = gasSpecificTestCases(t)
 	maps.Copy(testCases, specificCases)
 
 	return testCases
@@ -324,7 +260,7 @@ func getGasTestScenarios() map[string]Scenario {
 func TestProcessor_GasSpecificScenarios(t *testing.T) {
 	for name, processor := range getProcessors() {
 		t.Run(name, func(t *testing.T) {
-			for name, s := range getGasTestScenarios() {
+			for name, s := range getGasTestScenarios(t) {
 				t.Run(name, func(t *testing.T) {
 					s.Run(t, processor)
 				})
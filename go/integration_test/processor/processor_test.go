@@ -16,9 +16,7 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
-	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 	"github.com/ethereum/go-ethereum/common"
-	op "github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"golang.org/x/exp/maps"
 
@@ -40,148 +38,74 @@ import (
 // - test recursive calls
 // - test roll-back on revert
 
-func getScenarios() map[string]Scenario {
+func getScenarios() map[string]*ScenarioBuilder {
 
 	// TODO: improve organization of test scenarios
 
-	createdAddress := tosca.Address(crypto.CreateAddress(common.Address{1}, 4))
-	return map[string]Scenario{
-		"SuccessfulValueTransfer": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
-				GasLimit:  21_000,
-				Value:     tosca.NewValue(3),
-				Nonce:     4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(97), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(3)},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: 21_000,
-			},
-		},
-		"FailedValueTransfer": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(10), Nonce: 4},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
-				GasLimit:  21_000,
-				Value:     tosca.NewValue(20),
-				Nonce:     4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(10), Nonce: 5},
-			},
-			Receipt: tosca.Receipt{
-				Success: false,
-				GasUsed: 21_000,
-			},
-		},
-		"SuccessfulContractCall": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0),
-					Code: tosca.Code{
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(op.RETURN),
-					},
-				},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
-				GasLimit:  21_000 + 2*3, // < value transfer + 2 push instructions (return is free)
-				Value:     tosca.NewValue(3),
-				Nonce:     4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(97), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(3),
-					Code: tosca.Code{
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(op.RETURN),
-					},
-				},
-			},
-			Receipt: tosca.Receipt{
-				Success: true,
-				GasUsed: 21_000 + 2*3,
-			},
-		},
-		"RevertingContractCall": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-				{2}: Account{Balance: tosca.NewValue(0),
-					Code: tosca.Code{
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(op.REVERT),
-					},
-				},
-			},
-			Transaction: tosca.Transaction{
-				Sender:    tosca.Address{1},
-				Recipient: &tosca.Address{2},
-				GasLimit:  21_000 + 2*3, // < value transfer + 2 push instructions (return is free)
-				Value:     tosca.NewValue(3),
-				Nonce:     4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 5},
-				{2}: Account{Balance: tosca.NewValue(0),
-					Code: tosca.Code{
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(vm.PUSH1), byte(0), // < push 0
-						byte(op.REVERT),
-					},
-				},
-			},
-			Receipt: tosca.Receipt{
-				Success: false,
-				GasUsed: 21_000 + 2*3,
-			},
-		},
-		"SuccessfulContractCreation": {
-			Before: WorldState{
-				{1}: Account{Balance: tosca.NewValue(100), Nonce: 4},
-			},
-			Transaction: tosca.Transaction{
-				Sender:   tosca.Address{1},
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	createdAddress := tosca.Address(crypto.CreateAddress(common.Address(sender), 4))
+	valueTransfer := tosca.Transaction{
+		Sender:    sender,
+		Recipient: &receiver,
+		GasLimit:  21_000,
+		Value:     tosca.NewValue(3),
+		Nonce:     4,
+	}
+	failedTransfer := valueTransfer
+	failedTransfer.Value = tosca.NewValue(20)
+	contractCall := valueTransfer
+	contractCall.GasLimit = 21_000 + 2*3 // < value transfer + 2 push instructions (return is free)
+
+	return map[string]*ScenarioBuilder{
+		"SuccessfulValueTransfer": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Transaction(valueTransfer).
+			ExpectSuccess().
+			ExpectGasUsed(21_000).
+			ExpectAccount(sender, Balance(97), Nonce(5)).
+			ExpectAccount(receiver, Balance(3)),
+		"FailedValueTransfer": NewScenario().
+			Account(sender, Balance(10), Nonce(4)).
+			Transaction(failedTransfer).
+			ExpectGasUsed(21_000).
+			ExpectAccount(sender, Nonce(5)),
+		"SuccessfulContractCall": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver, CodeFromMnemonics("PUSH1 0", "PUSH1 0", "RETURN")).
+			Transaction(contractCall).
+			ExpectSuccess().
+			ExpectGasUsed(21_000+2*3).
+			ExpectAccount(sender, Balance(97), Nonce(5)).
+			ExpectAccount(receiver, Balance(3)),
+		"RevertingContractCall": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Account(receiver, CodeFromMnemonics("PUSH1 0", "PUSH1 0", "REVERT")).
+			Transaction(contractCall).
+			ExpectGasUsed(21_000+2*3).
+			ExpectAccount(sender, Nonce(5)),
+		"SuccessfulContractCreation": NewScenario().
+			Account(sender, Balance(100), Nonce(4)).
+			Transaction(tosca.Transaction{
+				Sender:   sender,
 				GasLimit: 53_000,
 				Value:    tosca.NewValue(3),
 				Nonce:    4,
-			},
-			After: WorldState{
-				{1}: Account{Balance: tosca.NewValue(97), Nonce: 5},
-				createdAddress: Account{
-					Balance: tosca.NewValue(3),
-					Nonce:   1,
-					Code:    tosca.Code{},
-				},
-			},
-			Receipt: tosca.Receipt{
+			}).
+			ExpectReceipt(tosca.Receipt{
 				Success:         true,
 				GasUsed:         53_000,
 				ContractAddress: &createdAddress,
-			},
-		},
+			}).
+			ExpectAccount(sender, Balance(97), Nonce(5)).
+			ExpectAccount(createdAddress, Balance(3), Nonce(1), Code([]byte{})),
 	}
 }
 
 func RunProcessorTests(t *testing.T, processor tosca.Processor) {
-	for name, s := range getScenarios() {
+	for name, scenario := range getScenarios() {
 		t.Run(name, func(t *testing.T) {
-			s.Run(t, processor)
+			scenario.Run(t, processor)
 		})
 	}
 }
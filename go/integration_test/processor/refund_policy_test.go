@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/floria"
+	opera "github.com/Fantom-foundation/Tosca/go/processor/opera"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessor_RefundPolicyIsHonored(t *testing.T) {
+	const gasLimit = tosca.Gas(100000)
+	const gasRefund = tosca.Gas(3000)
+
+	newProcessors := map[string]func(tosca.Interpreter, tosca.RefundPolicy) tosca.Processor{
+		"floria": func(interpreter tosca.Interpreter, policy tosca.RefundPolicy) tosca.Processor {
+			return floria.NewProcessor(interpreter, floria.Config{RefundPolicy: policy})
+		},
+		"opera": func(interpreter tosca.Interpreter, policy tosca.RefundPolicy) tosca.Processor {
+			return opera.NewProcessor(interpreter, opera.Config{RefundPolicy: policy})
+		},
+	}
+
+	for name, newProcessor := range newProcessors {
+		t.Run(name, func(t *testing.T) {
+			gasUsed := map[uint64]tosca.Gas{}
+			for _, quotient := range []uint64{0, 1} {
+				ctrl := gomock.NewController(t)
+				interpreter := tosca.NewMockInterpreter(ctrl)
+				interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{
+					GasLeft:   gasLimit / 2,
+					Success:   true,
+					GasRefund: gasRefund,
+				}, nil)
+
+				policy := tosca.RefundPolicyFunc(func(tosca.Revision) uint64 { return quotient })
+				processor := newProcessor(interpreter, policy)
+
+				sender := tosca.Address{1}
+				recipient := tosca.Address{2}
+				state := WorldState{
+					sender:    Account{Balance: tosca.NewValue(1 << 40)},
+					recipient: Account{Code: tosca.Code{0}},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &recipient,
+					GasLimit:  gasLimit,
+					GasPrice:  tosca.NewValue(1),
+				}
+				blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+				receipt, err := processor.Run(context.Background(), blockParameters, transaction, newScenarioContext(state))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !receipt.Success {
+					t.Fatalf("transaction failed")
+				}
+				gasUsed[quotient] = receipt.GasUsed
+			}
+
+			// Without refunds, the full refund is additionally charged.
+			if want, got := gasRefund, gasUsed[0]-gasUsed[1]; want != got {
+				t.Errorf("unexpected difference in gas used, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
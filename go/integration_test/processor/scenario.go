@@ -12,6 +12,7 @@ package processor
 
 import (
 	"bytes"
+	"context"
 	"slices"
 	"strings"
 	"testing"
@@ -34,8 +35,8 @@ type Scenario struct {
 
 func (s *Scenario) Run(t *testing.T, processor tosca.Processor) {
 
-	context := newScenarioContext(s.Before)
-	receipt, err := processor.Run(s.Parameters, s.Transaction, context)
+	transactionContext := newScenarioContext(s.Before)
+	receipt, err := processor.Run(context.Background(), s.Parameters, s.Transaction, transactionContext)
 	if err != nil && s.OperaError == nil {
 		t.Fatalf("failed to run transaction: %v", err)
 	}
@@ -44,7 +45,7 @@ func (s *Scenario) Run(t *testing.T, processor tosca.Processor) {
 	}
 
 	// check the world state after the operation
-	if want, got := s.After, context.current; !want.Equal(got) {
+	if want, got := s.After, transactionContext.current; !want.Equal(got) {
 		diff := strings.Join(got.Diff(want), "\n\t")
 		t.Fatalf("unexpected world state after the operation: \n\t%v", diff)
 	}
@@ -59,6 +60,9 @@ func (s *Scenario) Run(t *testing.T, processor tosca.Processor) {
 	if want, got := s.Receipt.BlobGasUsed, receipt.BlobGasUsed; want != got {
 		t.Errorf("unexpected blob gas used, want %v, got %v", want, got)
 	}
+	if want, got := s.Receipt.CalldataFloorGas, receipt.CalldataFloorGas; want != got {
+		t.Errorf("unexpected calldata floor gas, want %v, got %v", want, got)
+	}
 	if want, got := s.Receipt.Output, receipt.Output; !bytes.Equal(want, got) {
 		t.Errorf("unexpected output used, want %x, got %x", want, got)
 	}
@@ -223,31 +227,39 @@ func (c *scenarioContext) SetTransientStorage(tosca.Address, tosca.Key, tosca.Wo
 }
 
 func (c *scenarioContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
-	for _, tuple := range c.accessList {
-		if tuple.Address == address {
-			return tosca.WarmAccess
-		}
+	if c.IsAddressInAccessList(address) {
+		return tosca.WarmAccess
 	}
-	c.accessList = append(c.accessList, tosca.AccessTuple{Address: address})
+	c.addToAccessList(address)
 	return tosca.ColdAccess
 }
 
 func (c *scenarioContext) AccessStorage(addr tosca.Address, key tosca.Key) tosca.AccessStatus {
-	for _, tuple := range c.accessList {
-		if tuple.Address == addr {
-			for _, k := range tuple.Keys {
-				if k == key {
-					return tosca.WarmAccess
-				}
-			}
-			tuple.Keys = append(tuple.Keys, key)
-			return tosca.ColdAccess
-		}
+	addressPresent, slotPresent := c.IsSlotInAccessList(addr, key)
+	if slotPresent {
+		return tosca.WarmAccess
 	}
-	c.accessList = append(c.accessList, tosca.AccessTuple{Address: addr, Keys: []tosca.Key{key}})
+	if !addressPresent {
+		c.addToAccessList(addr)
+	}
+	i := slices.IndexFunc(c.accessList, func(tuple tosca.AccessTuple) bool {
+		return tuple.Address == addr
+	})
+	c.accessList[i].Keys = append(c.accessList[i].Keys, key)
+	c.undo = append(c.undo, func() {
+		c.accessList[i].Keys = c.accessList[i].Keys[:len(c.accessList[i].Keys)-1]
+	})
 	return tosca.ColdAccess
 }
 
+// addToAccessList adds the given address to the access list. Like any other
+// modification, the addition is reverted when restoring an earlier snapshot,
+// as required by EIP-2929.
+func (c *scenarioContext) addToAccessList(address tosca.Address) {
+	c.accessList = append(c.accessList, tosca.AccessTuple{Address: address})
+	c.undo = append(c.undo, func() { c.accessList = c.accessList[:len(c.accessList)-1] })
+}
+
 func (c *scenarioContext) EmitLog(log tosca.Log) {
 	len := len(c.logs)
 	c.logs = append(c.logs, log)
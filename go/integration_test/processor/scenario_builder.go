@@ -0,0 +1,234 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/asm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// ScenarioBuilder provides a builder-style DSL for defining test scenarios
+// for transaction processors. A typical use looks like
+//
+//	NewScenario().
+//		Account(sender, Balance(1000)).
+//		Account(receiver, CodeFromMnemonics("PUSH1 0x2a", "PUSH1 0", "SSTORE")).
+//		Transaction(Call(sender, receiver, sufficientGas)).
+//		ExpectSuccess().
+//		ExpectAccount(receiver, Slot(tosca.Key{}, tosca.Word{31: 0x2a})).
+//		Run(t, processor)
+//
+// Unless modified by expectations, the state after the transaction is
+// expected to be equal to the state before the transaction.
+type ScenarioBuilder struct {
+	before      WorldState
+	after       WorldState
+	parameters  tosca.BlockParameters
+	transaction tosca.Transaction
+	receipt     tosca.Receipt
+	operaError  error
+	errors      []error
+}
+
+// NewScenario starts the definition of a new scenario.
+func NewScenario() *ScenarioBuilder {
+	return &ScenarioBuilder{
+		before: WorldState{},
+		after:  WorldState{},
+	}
+}
+
+// AccountOption is a modification of an account in a scenario.
+type AccountOption func(*Account) error
+
+// Balance sets the balance of an account.
+func Balance(balance uint64) AccountOption {
+	return func(a *Account) error {
+		a.Balance = tosca.NewValue(balance)
+		return nil
+	}
+}
+
+// Nonce sets the nonce of an account.
+func Nonce(nonce uint64) AccountOption {
+	return func(a *Account) error {
+		a.Nonce = nonce
+		return nil
+	}
+}
+
+// Code sets the code of an account.
+func Code(code []byte) AccountOption {
+	return func(a *Account) error {
+		a.Code = tosca.Code(code)
+		return nil
+	}
+}
+
+// CodeFromHex sets the code of an account to the given hex encoded bytes.
+// An optional 0x prefix is ignored.
+func CodeFromHex(code string) AccountOption {
+	return func(a *Account) error {
+		decoded, err := hex.DecodeString(strings.TrimPrefix(code, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid hex code: %w", err)
+		}
+		a.Code = tosca.Code(decoded)
+		return nil
+	}
+}
+
+// CodeFromMnemonics sets the code of an account to the given lines of
+// assembly code, in the format accepted by the asm package.
+func CodeFromMnemonics(lines ...string) AccountOption {
+	return func(a *Account) error {
+		code, err := asm.AssembleLines(lines...)
+		if err != nil {
+			return err
+		}
+		a.Code = tosca.Code(code)
+		return nil
+	}
+}
+
+// Slot sets a storage slot of an account.
+func Slot(key tosca.Key, value tosca.Word) AccountOption {
+	return func(a *Account) error {
+		if a.Storage == nil {
+			a.Storage = Storage{}
+		}
+		a.Storage[key] = value
+		return nil
+	}
+}
+
+// Account adds the given account to the state before and after the
+// transaction. Options are applied in order on top of existing definitions
+// of the same account.
+func (b *ScenarioBuilder) Account(address tosca.Address, options ...AccountOption) *ScenarioBuilder {
+	b.before[address] = b.applyOptions(b.before[address], options)
+	b.after[address] = b.applyOptions(b.after[address], options)
+	return b
+}
+
+// Revision sets the revision of the block the transaction is executed in.
+func (b *ScenarioBuilder) Revision(revision tosca.Revision) *ScenarioBuilder {
+	b.parameters.Revision = revision
+	return b
+}
+
+// BlockParameters sets the parameters of the block the transaction is
+// executed in.
+func (b *ScenarioBuilder) BlockParameters(parameters tosca.BlockParameters) *ScenarioBuilder {
+	b.parameters = parameters
+	return b
+}
+
+// Transaction sets the transaction to be executed.
+func (b *ScenarioBuilder) Transaction(transaction tosca.Transaction) *ScenarioBuilder {
+	b.transaction = transaction
+	return b
+}
+
+// Call produces a transaction calling the given receiver.
+func Call(sender, receiver tosca.Address, gasLimit tosca.Gas) tosca.Transaction {
+	return tosca.Transaction{
+		Sender:    sender,
+		Recipient: &receiver,
+		GasLimit:  gasLimit,
+	}
+}
+
+// Create produces a transaction creating a contract with the given init code.
+func Create(sender tosca.Address, initCode []byte, gasLimit tosca.Gas) tosca.Transaction {
+	return tosca.Transaction{
+		Sender:   sender,
+		Input:    initCode,
+		GasLimit: gasLimit,
+	}
+}
+
+// ExpectReceipt sets the expected receipt of the transaction.
+func (b *ScenarioBuilder) ExpectReceipt(receipt tosca.Receipt) *ScenarioBuilder {
+	b.receipt = receipt
+	return b
+}
+
+// ExpectSuccess sets the expected receipt to report a successful execution.
+func (b *ScenarioBuilder) ExpectSuccess() *ScenarioBuilder {
+	b.receipt.Success = true
+	return b
+}
+
+// ExpectGasUsed sets the expected amount of gas used by the transaction.
+func (b *ScenarioBuilder) ExpectGasUsed(gas tosca.Gas) *ScenarioBuilder {
+	b.receipt.GasUsed = gas
+	return b
+}
+
+// ExpectOutput sets the expected output of the transaction.
+func (b *ScenarioBuilder) ExpectOutput(output []byte) *ScenarioBuilder {
+	b.receipt.Output = output
+	return b
+}
+
+// ExpectOperaError sets the error the opera processor is expected to report
+// for the transaction, which other processors report as a failed receipt.
+func (b *ScenarioBuilder) ExpectOperaError(err error) *ScenarioBuilder {
+	b.operaError = err
+	return b
+}
+
+// ExpectAccount applies the given options to the account in the state
+// expected after the transaction.
+func (b *ScenarioBuilder) ExpectAccount(address tosca.Address, options ...AccountOption) *ScenarioBuilder {
+	b.after[address] = b.applyOptions(b.after[address], options)
+	return b
+}
+
+// Build produces the defined scenario, failing the test if any of the
+// definitions was invalid.
+func (b *ScenarioBuilder) Build(t *testing.T) Scenario {
+	t.Helper()
+	for _, err := range b.errors {
+		t.Fatalf("invalid scenario: %v", err)
+	}
+	return Scenario{
+		Before:      b.before.Clone(),
+		After:       b.after.Clone(),
+		Parameters:  b.parameters,
+		Transaction: b.transaction,
+		Receipt:     b.receipt,
+		OperaError:  b.operaError,
+	}
+}
+
+// Run builds the scenario and runs it on the given processor.
+func (b *ScenarioBuilder) Run(t *testing.T, processor tosca.Processor) {
+	t.Helper()
+	scenario := b.Build(t)
+	scenario.Run(t, processor)
+}
+
+func (b *ScenarioBuilder) applyOptions(account Account, options []AccountOption) Account {
+	account = account.Clone()
+	for _, option := range options {
+		if err := option(&account); err != nil {
+			b.errors = append(b.errors, err)
+		}
+	}
+	return account
+}
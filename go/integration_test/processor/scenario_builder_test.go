@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/floria"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestScenarioBuilder_StorageUpdatesAreChecked(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	const gasLimit = 100_000
+	for processorName, processor := range getProcessors() {
+		t.Run(processorName, func(t *testing.T) {
+			NewScenario().
+				Account(sender, Balance(1000)).
+				Account(receiver, CodeFromMnemonics(
+					"PUSH1 0x2a",
+					"PUSH1 0",
+					"SSTORE",
+				)).
+				Transaction(Call(sender, receiver, gasLimit)).
+				ExpectSuccess().
+				ExpectGasUsed(getChargedGas(gasLimit, floria.TxGas+3+3+20_000)).
+				ExpectAccount(sender, Nonce(1)).
+				ExpectAccount(receiver, Slot(tosca.Key{}, tosca.Word{31: 0x2a})).
+				Run(t, processor)
+		})
+	}
+}
+
+func TestScenarioBuilder_OutputIsChecked(t *testing.T) {
+	sender := tosca.Address{1}
+	receiver := tosca.Address{2}
+	const gasLimit = 100_000
+	for processorName, processor := range getProcessors() {
+		t.Run(processorName, func(t *testing.T) {
+			NewScenario().
+				Account(sender).
+				Account(receiver, CodeFromHex("0x602a60005260206000f3")).
+				Transaction(Call(sender, receiver, gasLimit)).
+				ExpectSuccess().
+				ExpectGasUsed(getChargedGas(gasLimit, floria.TxGas+5*3+3)).
+				ExpectOutput(append(bytes.Repeat([]byte{0}, 31), 0x2a)).
+				ExpectAccount(sender, Nonce(1)).
+				Run(t, processor)
+		})
+	}
+}
+
+// getChargedGas computes the gas charged for a transaction with the given gas
+// limit consuming the given amount of gas, including the penalty of 10% of the
+// unused gas.
+func getChargedGas(gasLimit, gasUsed tosca.Gas) tosca.Gas {
+	gasLeft := gasLimit - gasUsed
+	return gasLimit - (gasLeft - gasLeft/10)
+}
@@ -260,3 +260,32 @@ func TestScenario_Clone(t *testing.T) {
 		})
 	}
 }
+
+func TestScenarioContext_RestoreSnapshotRevertsAccessListAndStorage(t *testing.T) {
+	addr := tosca.Address{1}
+	key := tosca.Key{2}
+	context := newScenarioContext(WorldState{})
+	context.AccessStorage(addr, tosca.Key{1})
+
+	snapshot := context.CreateSnapshot()
+	context.AccessAccount(tosca.Address{3})
+	context.AccessStorage(addr, key)
+	context.SetStorage(addr, key, tosca.Word{1})
+	context.RestoreSnapshot(snapshot)
+
+	if context.IsAddressInAccessList(tosca.Address{3}) {
+		t.Errorf("account access was not reverted")
+	}
+	if addressPresent, slotPresent := context.IsSlotInAccessList(addr, key); !addressPresent || slotPresent {
+		t.Errorf("storage access was not reverted")
+	}
+	if _, slotPresent := context.IsSlotInAccessList(addr, tosca.Key{1}); !slotPresent {
+		t.Errorf("access before snapshot was reverted")
+	}
+	if want, got := (tosca.Word{}), context.GetStorage(addr, key); want != got {
+		t.Errorf("unexpected storage, want %v, got %v", want, got)
+	}
+	if want, got := tosca.ColdAccess, context.AccessStorage(addr, key); want != got {
+		t.Errorf("unexpected access status, want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,573 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/asm"
+	"github.com/Fantom-foundation/Tosca/go/processor/floria"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func getSimulatingProcessors(t *testing.T) map[string]tosca.SimulatingProcessor {
+	res := map[string]tosca.SimulatingProcessor{}
+	for name, processor := range getProcessors() {
+		simulator, ok := processor.(tosca.SimulatingProcessor)
+		if !ok {
+			t.Fatalf("processor %s does not support simulations", name)
+		}
+		res[name] = simulator
+	}
+	return res
+}
+
+func TestSimulation_NoBalanceCheckAllowsSendersWithoutFunds(t *testing.T) {
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender:   Account{},
+				receiver: Account{},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  sufficientGas,
+				GasPrice:  tosca.NewValue(10),
+			}
+
+			transactionContext := newScenarioContext(state)
+			result, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{NoBalanceCheck: true},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Aborted || !result.Success {
+				t.Fatalf("simulation was not successful: %v", result)
+			}
+			if want, got := (tosca.Value{}), transactionContext.GetBalance(sender); want != got {
+				t.Errorf("unexpected sender balance, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulation_GasLimitIsCapped(t *testing.T) {
+	// An endless loop, consuming all available gas.
+	code := asm.MustAssemble(`
+		JUMPDEST
+		PUSH1 0
+		JUMP
+	`)
+
+	const gasCap = 50_000
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender:   Account{},
+				receiver: Account{Code: code},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  10 * gasCap,
+			}
+
+			transactionContext := newScenarioContext(state)
+			result, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{GasCap: gasCap},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success {
+				t.Errorf("endless loop was not expected to succeed")
+			}
+			if want, got := tosca.Gas(gasCap), result.GasUsed; want != got {
+				t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulation_UnlimitedGasReportsConsumedGas(t *testing.T) {
+	// Code consuming 5 gas, on top of the 21,000 gas of the transaction.
+	code := asm.MustAssemble(`
+		PUSH1 0
+		POP
+		STOP
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender:   Account{},
+				receiver: Account{Code: code},
+			}
+			// Neither the gas limit nor the sender's balance suffice.
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  21_000,
+				GasPrice:  tosca.NewValue(10),
+			}
+
+			transactionContext := newScenarioContext(state)
+			result, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{UnlimitedGas: true},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Aborted || !result.Success {
+				t.Fatalf("simulation was not successful: %v", result)
+			}
+			if want, got := tosca.Gas(21_005), result.GasUsed; want != got {
+				t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+			}
+			if want, got := (tosca.Value{}), transactionContext.GetBalance(sender); want != got {
+				t.Errorf("unexpected sender balance, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulation_NoBaseFeeHidesBaseFeeFromContracts(t *testing.T) {
+	code := asm.MustAssemble(`
+		BASEFEE
+		PUSH1 0
+		MSTORE
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		for _, noBaseFee := range []bool{false, true} {
+			t.Run(processorName, func(t *testing.T) {
+				sender := tosca.Address{1}
+				receiver := tosca.Address{2}
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: code},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  sufficientGas,
+				}
+				blockParameters := tosca.BlockParameters{
+					BaseFee:  tosca.NewValue(10),
+					Revision: tosca.R10_London,
+				}
+
+				transactionContext := newScenarioContext(state)
+				result, err := processor.Simulate(
+					context.Background(), blockParameters, transaction,
+					transactionContext, tosca.SimulationOptions{NoBaseFee: noBaseFee},
+				)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Fatalf("simulation was not successful: %v", result)
+				}
+
+				want := blockParameters.BaseFee
+				if noBaseFee {
+					want = tosca.Value{}
+				}
+				if got := tosca.Value(result.Output); want != got {
+					t.Errorf("unexpected base fee, wanted %v, got %v", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestSimulation_AbortedExecutionIsReported(t *testing.T) {
+	// An endless loop, only stopped by running out of gas.
+	code := asm.MustAssemble(`
+		JUMPDEST
+		PUSH1 0
+		JUMP
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender:   Account{},
+				receiver: Account{Code: code},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  10_000_000,
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			transactionContext := newScenarioContext(state)
+			result, err := processor.Simulate(
+				ctx, tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Aborted {
+				t.Errorf("simulation was not reported as aborted")
+			}
+		})
+	}
+}
+
+func TestSimulation_StateOverridesAreApplied(t *testing.T) {
+	// Returns the balance of the caller plus the value of storage slot 1.
+	code := asm.MustAssemble(`
+		CALLER
+		BALANCE
+		PUSH1 1
+		SLOAD
+		ADD
+		PUSH1 0
+		MSTORE
+		PUSH1 32
+		PUSH1 0
+		RETURN
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			state := WorldState{
+				sender: Account{Balance: tosca.NewValue(1)},
+				receiver: Account{Storage: Storage{
+					{1}:     {31: 2},
+					{31: 1}: {31: 3},
+				}},
+			}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  sufficientGas,
+			}
+
+			balance := tosca.NewValue(10)
+			overrides := tosca.StateOverrides{
+				sender: {Balance: &balance},
+				receiver: {
+					Code:  code,
+					State: map[tosca.Key]tosca.Word{{31: 1}: {31: 20}},
+				},
+			}
+
+			transactionContext := newScenarioContext(state)
+			result, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{StateOverrides: overrides},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("simulation was not successful: %v", result)
+			}
+			if want, got := tosca.NewValue(30), tosca.Value(result.Output); want != got {
+				t.Errorf("unexpected result, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulation_ConflictingStateOverridesAreRejected(t *testing.T) {
+	for processorName, processor := range getSimulatingProcessors(t) {
+		t.Run(processorName, func(t *testing.T) {
+			sender := tosca.Address{1}
+			receiver := tosca.Address{2}
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &receiver,
+				GasLimit:  sufficientGas,
+			}
+			overrides := tosca.StateOverrides{
+				receiver: {
+					State:     map[tosca.Key]tosca.Word{},
+					StateDiff: map[tosca.Key]tosca.Word{},
+				},
+			}
+
+			transactionContext := newScenarioContext(WorldState{})
+			_, err := processor.Simulate(
+				context.Background(), tosca.BlockParameters{}, transaction,
+				transactionContext, tosca.SimulationOptions{StateOverrides: overrides},
+			)
+			if err == nil {
+				t.Errorf("expected conflicting overrides to be rejected")
+			}
+		})
+	}
+}
+
+func TestSimulation_GasBreakdownIsReportedOnRequest(t *testing.T) {
+	// Clears a storage slot, resulting in a refund.
+	code := asm.MustAssemble(`
+		PUSH1 0
+		PUSH1 1
+		SSTORE
+		STOP
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		for _, requested := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/requested=%t", processorName, requested), func(t *testing.T) {
+				sender := tosca.Address{1}
+				receiver := tosca.Address{2}
+				state := WorldState{
+					sender: Account{},
+					receiver: Account{
+						Code:    code,
+						Storage: Storage{tosca.Key(tosca.NewValue(1)): tosca.Word{31: 1}},
+					},
+				}
+				transaction := tosca.Transaction{
+					Sender:     sender,
+					Recipient:  &receiver,
+					GasLimit:   sufficientGas,
+					AccessList: []tosca.AccessTuple{},
+				}
+				blockParameters := tosca.BlockParameters{Revision: tosca.R10_London}
+
+				transactionContext := newScenarioContext(state)
+				result, err := processor.Simulate(
+					context.Background(), blockParameters, transaction,
+					transactionContext, tosca.SimulationOptions{GasBreakdown: requested},
+				)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Fatalf("simulation was not successful: %v", result)
+				}
+
+				breakdown := result.GasBreakdown
+				if !requested {
+					if breakdown != nil {
+						t.Errorf("gas breakdown was not requested, got %v", breakdown)
+					}
+					return
+				}
+				if breakdown == nil {
+					t.Fatalf("gas breakdown is missing")
+				}
+				if want, got := tosca.Gas(floria.TxGas), breakdown.IntrinsicGas; want != got {
+					t.Errorf("unexpected intrinsic gas, wanted %d, got %d", want, got)
+				}
+				if breakdown.ExecutionGas == 0 {
+					t.Errorf("execution gas is missing")
+				}
+				if breakdown.Refund == 0 {
+					t.Errorf("refund is missing")
+				}
+				used := breakdown.IntrinsicGas + breakdown.ExecutionGas +
+					breakdown.UnusedGasPenalty - breakdown.Refund
+				if want, got := result.GasUsed, used; want != got {
+					t.Errorf("unexpected gas used by breakdown, wanted %d, got %d", want, got)
+				}
+				if want, got := transaction.GasLimit-result.GasUsed, breakdown.Returned; want != got {
+					t.Errorf("unexpected returned gas, wanted %d, got %d", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestSimulation_AccessStatisticsAreReportedOnRequest(t *testing.T) {
+	// Reads slot 1, writes slot 2, and checks the code size of a third account.
+	code := asm.MustAssemble(`
+		PUSH1 1
+		SLOAD
+		PUSH1 2
+		SSTORE
+		PUSH1 3
+		EXTCODESIZE
+		STOP
+	`)
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		for _, requested := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/requested=%t", processorName, requested), func(t *testing.T) {
+				sender := tosca.Address{1}
+				receiver := tosca.Address{2}
+				state := WorldState{
+					sender: Account{},
+					receiver: Account{
+						Code:    code,
+						Storage: Storage{tosca.Key(tosca.NewValue(1)): tosca.Word{31: 1}},
+					},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  sufficientGas,
+				}
+				blockParameters := tosca.BlockParameters{Revision: tosca.R10_London}
+
+				transactionContext := newScenarioContext(state)
+				result, err := processor.Simulate(
+					context.Background(), blockParameters, transaction,
+					transactionContext, tosca.SimulationOptions{AccessStatistics: requested},
+				)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Fatalf("simulation was not successful: %v", result)
+				}
+
+				statistics := result.AccessStatistics
+				if !requested {
+					if statistics != nil {
+						t.Errorf("access statistics were not requested, got %v", statistics)
+					}
+					return
+				}
+				if statistics == nil {
+					t.Fatalf("access statistics are missing")
+				}
+				if want, got := 1, statistics.SlotsWritten; want != got {
+					t.Errorf("unexpected number of written slots, wanted %d, got %d", want, got)
+				}
+				if statistics.SlotsRead < 1 {
+					t.Errorf("the read of slot 1 is not counted")
+				}
+				if statistics.AccountsRead < 3 {
+					t.Errorf("unexpected number of read accounts, wanted at least 3, got %d", statistics.AccountsRead)
+				}
+				if statistics.AccountsWritten < 1 {
+					t.Errorf("the nonce update of the sender is not counted")
+				}
+				if want, got := len(code), statistics.CodeBytesLoaded; want != got {
+					t.Errorf("unexpected number of loaded code bytes, wanted %d, got %d", want, got)
+				}
+				if want, got := 2, statistics.ColdSlotAccesses; want != got {
+					t.Errorf("unexpected number of cold slot accesses, wanted %d, got %d", want, got)
+				}
+				if statistics.ColdAccountAccesses < 1 {
+					t.Errorf("the cold access of the third account is not counted")
+				}
+			})
+		}
+	}
+}
+
+func TestSimulation_ExceededLimitsAreReported(t *testing.T) {
+	tests := map[string]struct {
+		code   string
+		limits tosca.SimulationLimits
+		limit  string
+	}{
+		"logs": {
+			// An endless loop emitting logs.
+			code: `
+				JUMPDEST
+				PUSH1 0
+				PUSH1 0
+				LOG0
+				PUSH1 0
+				JUMP
+			`,
+			limits: tosca.SimulationLimits{MaxLogs: 10},
+			limit:  tosca.LimitLogs,
+		},
+		"call depth": {
+			// An endless recursion of the contract calling itself.
+			code: `
+				PUSH1 0
+				PUSH1 0
+				PUSH1 0
+				PUSH1 0
+				PUSH1 0
+				ADDRESS
+				GAS
+				CALL
+				STOP
+			`,
+			limits: tosca.SimulationLimits{MaxCallDepth: 3},
+			limit:  tosca.LimitCallDepth,
+		},
+		"state writes": {
+			// An endless loop writing to increasing storage slots.
+			code: `
+				PUSH1 0
+				JUMPDEST
+				PUSH1 1
+				ADD
+				DUP1
+				DUP1
+				SSTORE
+				PUSH1 2
+				JUMP
+			`,
+			limits: tosca.SimulationLimits{MaxStateWrites: 10},
+			limit:  tosca.LimitStateWrites,
+		},
+	}
+
+	for processorName, processor := range getSimulatingProcessors(t) {
+		for testName, test := range tests {
+			t.Run(processorName+"/"+testName, func(t *testing.T) {
+				sender := tosca.Address{1}
+				receiver := tosca.Address{2}
+				state := WorldState{
+					sender:   Account{},
+					receiver: Account{Code: asm.MustAssemble(test.code)},
+				}
+				transaction := tosca.Transaction{
+					Sender:    sender,
+					Recipient: &receiver,
+					GasLimit:  10_000_000,
+				}
+
+				transactionContext := newScenarioContext(state)
+				result, err := processor.Simulate(
+					context.Background(), tosca.BlockParameters{}, transaction,
+					transactionContext, tosca.SimulationOptions{Limits: test.limits},
+				)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Aborted {
+					t.Errorf("simulation was not reported as aborted")
+				}
+				if result.LimitExceeded == nil || result.LimitExceeded.Limit != test.limit {
+					t.Errorf("unexpected exceeded limit, wanted %q, got %v", test.limit, result.LimitExceeded)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package common provides data structures shared by the interpreter
+// implementations in this repository.
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/holiman/uint256"
+)
+
+const MaxStackSize = 1024 // Maximum size of VM stack allowed.
+
+// stackMask is used to map indices into the range of the stack. Since
+// MaxStackSize is a power of two, masking an index makes it provably
+// in-bounds for the compiler, which thus omits bounds checks on accesses.
+const stackMask = MaxStackSize - 1
+
+// Stack is the 1024-element 256-bit word-wide stack used by the VM.
+// It is a fixed-size stack to prevent memory reallocation during execution.
+//
+// Boundaries are not checked. Users of the stack must prevent over- and
+// underflow situations, typically by a static analysis of the stack usage of
+// the executed code performed before running an instruction. Accesses beyond
+// the boundaries of the stack do not panic, but wrap around and produce
+// undefined results.
+//
+// Each stack consumes 1024 * 32 bytes = 32KB of memory. Thus, creating and
+// destroying stacks could incur significant overhead. To mitigate this, a
+// stack pool is provided to reuse stack instances. To obtain an empty stack
+// from the pool, use NewStack(). To return a stack to the pool, use
+// ReturnStack(s).
+//
+// Example usage:
+//
+//	s := NewStack()
+//	defer ReturnStack(s)
+//	<use the stack in your local scope>
+//
+// The stack is not thread-safe. NewStack() and ReturnStack() are thread-safe.
+type Stack struct {
+	data         [MaxStackSize]uint256.Int
+	stackPointer int
+}
+
+// Push adds a copy of the given value to the top of the stack.
+func (s *Stack) Push(data *uint256.Int) {
+	s.data[s.stackPointer&stackMask] = *data
+	s.stackPointer++
+}
+
+// PushUndefined adds a value with an undefined value to the top of the stack
+// and returns a pointer to this element. Use this function if the element on
+// the top stack should be modified directly using the returned pointer.
+func (s *Stack) PushUndefined() *uint256.Int {
+	s.stackPointer++
+	return &s.data[(s.stackPointer-1)&stackMask]
+}
+
+// Pop removes the top element from the stack and returns a pointer to it. The
+// obtained pointer is only valid until the next push operation. The pointer
+// can be used to obtain the popped element without the need to copy it.
+func (s *Stack) Pop() *uint256.Int {
+	s.stackPointer--
+	return &s.data[s.stackPointer&stackMask]
+}
+
+// Peek returns a pointer to the top element of the stack without removing it.
+// The returned pointer is only valid until the next operation on the stack.
+func (s *Stack) Peek() *uint256.Int {
+	return &s.data[(s.stackPointer-1)&stackMask]
+}
+
+// PeekN returns a pointer to the n-th element from the top of the stack
+// without removing it. The top element is at index 0 Thus, PeekN(0) is
+// equivalent to Peek().
+func (s *Stack) PeekN(n int) *uint256.Int {
+	return &s.data[(s.stackPointer-n-1)&stackMask]
+}
+
+// Len returns the number of elements on the stack.
+func (s *Stack) Len() int {
+	return s.stackPointer
+}
+
+// SetLen changes the number of elements on the stack to the given value.
+// Elements added this way have undefined values. The new length must be in
+// the range [0, MaxStackSize].
+func (s *Stack) SetLen(n int) {
+	s.stackPointer = n
+}
+
+// Swap exchanges the top element with the n-th element from the top. The top
+// element is at index 0. Thus, Swap(0) is a no-op.
+func (s *Stack) Swap(n int) {
+	top := &s.data[(s.stackPointer-1)&stackMask]
+	other := &s.data[(s.stackPointer-n-1)&stackMask]
+	*top, *other = *other, *top
+}
+
+// Dup duplicates the n-th element from the top and pushes it to the top of
+// the stack. The top element is at index 0. Thus, Dup(0) duplicates the top
+// element.
+func (s *Stack) Dup(n int) {
+	s.data[s.stackPointer&stackMask] = s.data[(s.stackPointer-n-1)&stackMask]
+	s.stackPointer++
+}
+
+// Get returns the element at the given index. The bottom element is at
+// index 0.
+func (s *Stack) Get(i int) *uint256.Int {
+	return &s.data[i&stackMask]
+}
+
+func (s *Stack) String() string {
+	toHex := func(z *uint256.Int) string {
+		b := strings.Builder{}
+		b.WriteString("0x")
+		bytes := z.Bytes32()
+		for i, cur := range bytes {
+			b.WriteString(fmt.Sprintf("%02x", cur))
+			if (i+1)%8 == 0 {
+				b.WriteString(" ")
+			}
+		}
+		return b.String()
+	}
+
+	b := strings.Builder{}
+	for i := 0; i < s.Len(); i++ {
+		b.WriteString(fmt.Sprintf("    [%4d] %v\n", s.Len()-i-1, toHex(s.PeekN(i))))
+	}
+	return b.String()
+}
+
+// ------------------ Stack Pool ------------------
+
+var stackPool = sync.Pool{
+	New: func() interface{} {
+		return &Stack{}
+	},
+}
+
+// NewStack returns a new stack instance from the a reuse pool. Heavy stack
+// users should use this function to prevent memory reallocation overhead.
+// This function is thread-safe.
+func NewStack() *Stack {
+	return stackPool.Get().(*Stack)
+}
+
+// ReturnStack returns the stack to the reuse pool. Any stack may only be
+// returned once to avoid concurrent re-use. This is not checked internally.
+// This function is thread-safe.
+func ReturnStack(s *Stack) {
+	s.stackPointer = 0
+	stackPool.Put(s)
+}
@@ -8,7 +8,7 @@
 // On the date above, in accordance with the Business Source License, use of
 // this software will be governed by the GNU Lesser General Public License v3.
 
-package lfvm
+package common
 
 import (
 	"fmt"
@@ -20,33 +20,33 @@ import (
 )
 
 func TestStack_ZeroStackIsEmpty(t *testing.T) {
-	var stack stack
-	if want, got := 0, stack.len(); want != got {
+	var stack Stack
+	if want, got := 0, stack.Len(); want != got {
 		t.Errorf("expected stack to be empty, but got %d elements", got)
 	}
 }
 
 func TestStack_pushAndPop_CanUseFullCapacity(t *testing.T) {
-	var stack stack
+	var stack Stack
 
-	for i := 0; i < maxStackSize; i++ {
-		if want, got := i, stack.len(); want != got {
+	for i := 0; i < MaxStackSize; i++ {
+		if want, got := i, stack.Len(); want != got {
 			t.Errorf("expected stack to have %d elements, but got %d", want, got)
 		}
 		val := uint256.NewInt(uint64(i))
-		stack.push(val)
+		stack.Push(val)
 	}
 
-	if want, got := maxStackSize, stack.len(); want != got {
+	if want, got := MaxStackSize, stack.Len(); want != got {
 		t.Errorf("expected stack to have %d elements, but got %d", want, got)
 	}
 
-	for i := maxStackSize - 1; i >= 0; i-- {
-		val := stack.pop()
+	for i := MaxStackSize - 1; i >= 0; i-- {
+		val := stack.Pop()
 		if want, got := uint256.NewInt(uint64(i)), val; want.Cmp(got) != 0 {
 			t.Errorf("expected popped value to be %d, but got %d", want, got)
 		}
-		if want, got := i, stack.len(); want != got {
+		if want, got := i, stack.Len(); want != got {
 			t.Errorf("expected stack to have %d elements, but got %d", want, got)
 		}
 	}
@@ -65,8 +65,8 @@ func TestStack_push_AddsProvidedElementToStack(t *testing.T) {
 	defer ReturnStack(stack)
 
 	for _, val := range values {
-		stack.push(val)
-		if want, got := val, stack.peek(); want.Cmp(got) != 0 {
+		stack.Push(val)
+		if want, got := val, stack.Peek(); want.Cmp(got) != 0 {
 			t.Errorf("expected top element to be %d, but got %d", want, got)
 		}
 	}
@@ -85,9 +85,9 @@ func TestStack_pushUndefined_ResultCanBeUsedToManipulatePeek(t *testing.T) {
 	defer ReturnStack(stack)
 
 	for _, val := range values {
-		peek := stack.pushUndefined()
+		peek := stack.PushUndefined()
 		peek.Set(val)
-		if want, got := val, stack.peek(); want.Cmp(got) != 0 {
+		if want, got := val, stack.Peek(); want.Cmp(got) != 0 {
 			t.Errorf("expected top element to be %d, but got %d", want, got)
 		}
 	}
@@ -99,16 +99,16 @@ func TestStack_peekN_ObtainsNthElementFromTop(t *testing.T) {
 
 	for i := 0; i < 10; i++ {
 		val := uint256.NewInt(uint64(i))
-		stack.push(val)
+		stack.Push(val)
 	}
 
-	if want, got := stack.peek(), stack.peekN(0); want != got {
+	if want, got := stack.Peek(), stack.PeekN(0); want != got {
 		t.Errorf("expected peekN(0) to be the same as peek(), but got %d and %d", want, got)
 	}
 
 	for i := 0; i < 10; i++ {
 		want := uint256.NewInt(uint64(9 - i))
-		got := stack.peekN(i)
+		got := stack.PeekN(i)
 		if want.Cmp(got) != 0 {
 			t.Errorf("expected %d-th element from top to be %d, but got %d", i, want, got)
 		}
@@ -131,13 +131,13 @@ func TestStack_swap_ExchangesTopElementWithSelectedElement(t *testing.T) {
 			defer ReturnStack(stack)
 
 			for i := 4; i >= 0; i-- {
-				stack.push(uint256.NewInt(uint64(i)))
+				stack.Push(uint256.NewInt(uint64(i)))
 			}
 
-			stack.swap(n)
+			stack.Swap(n)
 
 			for i, want := range result {
-				got := stack.peekN(i).Uint64()
+				got := stack.PeekN(i).Uint64()
 				if want != got {
 					t.Errorf("expected %d-th element to be %d, but got %d", i, want, got)
 				}
@@ -147,19 +147,19 @@ func TestStack_swap_ExchangesTopElementWithSelectedElement(t *testing.T) {
 }
 
 func TestStack_swap_WorksForAnyIntegerValue(t *testing.T) {
-	for _, size := range []int{2, 128, maxStackSize - 1} {
+	for _, size := range []int{2, 128, MaxStackSize - 1} {
 		for i := 0; i < size; i++ {
 			t.Run(fmt.Sprintf("size=%d_swap%d", size, i), func(t *testing.T) {
 				stack := NewStack()
 				defer ReturnStack(stack)
 
 				for i := 0; i < size; i++ {
-					stack.push(uint256.NewInt(uint64(i)))
+					stack.Push(uint256.NewInt(uint64(i)))
 				}
 
-				want := stack.peekN(i).Uint64()
-				stack.swap(i)
-				got := stack.peek().Uint64()
+				want := stack.PeekN(i).Uint64()
+				stack.Swap(i)
+				got := stack.Peek().Uint64()
 
 				if want != got {
 					t.Errorf("expected top element to be %d, but got %d", want, got)
@@ -185,13 +185,13 @@ func TestStack_dup_DuplicatesSelectedElementFromStack(t *testing.T) {
 			defer ReturnStack(stack)
 
 			for i := 4; i >= 0; i-- {
-				stack.push(uint256.NewInt(uint64(i)))
+				stack.Push(uint256.NewInt(uint64(i)))
 			}
 
-			stack.dup(n)
+			stack.Dup(n)
 
 			for i, want := range result {
-				got := stack.peekN(i).Uint64()
+				got := stack.PeekN(i).Uint64()
 				if want != got {
 					t.Errorf("expected %d-th element to be %d, but got %d", i, want, got)
 				}
@@ -201,19 +201,19 @@ func TestStack_dup_DuplicatesSelectedElementFromStack(t *testing.T) {
 }
 
 func TestStack_dup_WorksForAnyIntegerValue(t *testing.T) {
-	for _, size := range []int{2, 128, maxStackSize - 1} {
+	for _, size := range []int{2, 128, MaxStackSize - 1} {
 		for i := 0; i < size; i++ {
 			t.Run(fmt.Sprintf("size=%d_dup%d", size, i), func(t *testing.T) {
 				stack := NewStack()
 				defer ReturnStack(stack)
 
 				for i := 0; i < size; i++ {
-					stack.push(uint256.NewInt(uint64(i)))
+					stack.Push(uint256.NewInt(uint64(i)))
 				}
 
-				want := stack.peekN(i).Uint64()
-				stack.dup(i)
-				got := stack.peek().Uint64()
+				want := stack.PeekN(i).Uint64()
+				stack.Dup(i)
+				got := stack.Peek().Uint64()
 
 				if want != got {
 					t.Errorf("expected top element to be %d, but got %d", want, got)
@@ -227,13 +227,13 @@ func TestStack_get_IndexesElementsBottomUp(t *testing.T) {
 	stack := NewStack()
 	defer ReturnStack(stack)
 
-	for i := 0; i < maxStackSize; i++ {
-		stack.push(uint256.NewInt(uint64(i)))
+	for i := 0; i < MaxStackSize; i++ {
+		stack.Push(uint256.NewInt(uint64(i)))
 	}
 
-	for i := 0; i < maxStackSize; i++ {
+	for i := 0; i < MaxStackSize; i++ {
 		want := uint256.NewInt(uint64(i))
-		got := stack.get(i)
+		got := stack.Get(i)
 		if want.Cmp(got) != 0 {
 			t.Errorf("expected %d-th element to be %d, but got %d", i, want, got)
 		}
@@ -245,7 +245,7 @@ func TestStack_String_PrintsContentUsingFormattedHex(t *testing.T) {
 	defer ReturnStack(stack)
 
 	for i := 0; i < 256; i++ {
-		top := stack.pushUndefined()
+		top := stack.PushUndefined()
 		top.Lsh(uint256.NewInt(1), uint(i))
 	}
 
@@ -272,7 +272,7 @@ func TestStack_NewStack_IsEmpty(t *testing.T) {
 	stack := NewStack()
 	defer ReturnStack(stack)
 
-	if want, got := 0, stack.len(); want != got {
+	if want, got := 0, stack.Len(); want != got {
 		t.Errorf("expected stack to be empty, but got %d elements", got)
 	}
 }
@@ -295,3 +295,68 @@ func TestStack_NewStackAndReturnStack_AreThreadSafe(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestStack_SetLen_ChangesNumberOfElements(t *testing.T) {
+	var stack Stack
+	stack.Push(uint256.NewInt(1))
+	stack.Push(uint256.NewInt(2))
+
+	stack.SetLen(5)
+	if want, got := 5, stack.Len(); want != got {
+		t.Errorf("expected stack to have %d elements, but got %d", want, got)
+	}
+	stack.SetLen(1)
+	if want, got := 1, stack.Len(); want != got {
+		t.Errorf("expected stack to have %d elements, but got %d", want, got)
+	}
+	if want, got := uint256.NewInt(1), stack.Peek(); want.Cmp(got) != 0 {
+		t.Errorf("expected top element to be %d, but got %d", want, got)
+	}
+}
+
+// The following benchmarks cover the stack operations used in the hot paths
+// of the interpreters. To verify that the accessors are free of bounds checks,
+// compile the package using
+//
+//	go build -gcflags=-d=ssa/check_bce ./interpreter/common
+//
+// which lists all remaining bounds checks.
+
+func BenchmarkStack_Swap(b *testing.B) {
+	var stack Stack
+	stack.SetLen(MaxStackSize)
+	for i := 0; i < b.N; i++ {
+		stack.Swap(i % 16)
+	}
+}
+
+func BenchmarkStack_Dup(b *testing.B) {
+	var stack Stack
+	stack.SetLen(16)
+	for i := 0; i < b.N; i++ {
+		stack.Dup(i % 16)
+		stack.Pop()
+	}
+}
+
+func BenchmarkStack_PushPop(b *testing.B) {
+	var stack Stack
+	value := uint256.NewInt(42)
+	for i := 0; i < b.N; i++ {
+		stack.Push(value)
+		stack.Pop()
+	}
+}
+
+func BenchmarkStack_Add(b *testing.B) {
+	var stack Stack
+	one := uint256.NewInt(1)
+	stack.Push(one)
+	for i := 0; i < b.N; i++ {
+		// Mirrors the access pattern of binary arithmetic operations.
+		stack.Push(one)
+		a := stack.Pop()
+		trg := stack.Peek()
+		trg.Add(a, trg)
+	}
+}
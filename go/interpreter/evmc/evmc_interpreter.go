@@ -28,18 +28,23 @@ import (
 // LoadEvmcInterpreter attempts to load an Interpreter implementation from a
 // given library. The `library` parameter should name the library file, while
 // the actual path to the library should be enforced using an rpath (see evmone
-// implementation for an example).
+// implementation for an example) or located using FindLibrary.
 func LoadEvmcInterpreter(library string) (*EvmcInterpreter, error) {
 	vm, err := evmc.Load(library)
 	if err != nil {
 		return nil, err
 	}
+	if !vm.HasCapability(evmc.CapabilityEVM1) {
+		vm.Destroy()
+		return nil, fmt.Errorf("library %s does not provide an EVM1 interpreter", library)
+	}
 	return &EvmcInterpreter{vm: vm}, nil
 }
 
 // EvmcInterpreter is an Interpreter implementation accessible through the EVMC library.
 type EvmcInterpreter struct {
-	vm *evmc.VM
+	vm          *evmc.VM
+	hostQueries hostQueryCounters
 }
 
 // SetOption enables the configuration of implementation specific options.
@@ -48,9 +53,14 @@ func (e *EvmcInterpreter) SetOption(property string, value string) error {
 }
 
 func (e *EvmcInterpreter) Run(params tosca.Parameters) (tosca.Result, error) {
+	// Read-only queries are cached for the duration of this frame since every
+	// host query needs to cross the cgo boundary.
+	cache := newFrameCache(params.Context)
+	defer func() { e.hostQueries.add(cache.queries, cache.hits) }()
+
 	host_ctx := hostContext{
 		params:  params,
-		context: params.Context,
+		context: cache,
 	}
 
 	host_ctx.evmcBlobHashes = make([]evmc.Hash, 0, len(params.BlobHashes))
@@ -113,16 +123,59 @@ func (e *EvmcInterpreter) Run(params tosca.Parameters) (tosca.Result, error) {
 		evmc.Error(C.EVMC_INVALID_MEMORY_ACCESS),
 		evmc.Error(C.EVMC_STATIC_MODE_VIOLATION),
 		evmc.Error(C.EVMC_STACK_OVERFLOW),
-		evmc.Error(C.EVMC_STACK_UNDERFLOW):
+		evmc.Error(C.EVMC_STACK_UNDERFLOW),
+		evmc.Error(C.EVMC_CALL_DEPTH_EXCEEDED),
+		evmc.Error(C.EVMC_ARGUMENT_OUT_OF_RANGE),
+		evmc.Failure:
 		// These are errors in the executed contract, but not VM errors.
 		// The result is thus marked as not successful, and all gas is
 		// removed. Also, all refunds are removed and no data is returned.
 		return tosca.Result{Success: false}, nil
+	case evmc.Error(C.EVMC_REJECTED):
+		return tosca.Result{}, fmt.Errorf("execution rejected by EVMC interpreter: %w", err)
 	default:
 		return tosca.Result{}, fmt.Errorf("unexpected EVMC execution error: %w", err)
 	}
 }
 
+// GetHostQueryStatistics returns the statistics of the read-only host
+// queries of all executions conducted by this interpreter so far.
+func (e *EvmcInterpreter) GetHostQueryStatistics() HostQueryStatistics {
+	return e.hostQueries.get()
+}
+
+// SupportedRevisions lists the revisions known to Tosca supported by this
+// interpreter in ascending order. Since EVMC provides no means to query the
+// supported revisions, they are determined by probing the interpreter with an
+// execution of a single STOP instruction for each revision.
+func (e *EvmcInterpreter) SupportedRevisions() []tosca.Revision {
+	var res []tosca.Revision
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		evmcRevision, err := toEvmcRevision(revision)
+		if err != nil {
+			continue
+		}
+		_, err = e.vm.Execute(
+			&hostContext{},
+			evmcRevision,
+			evmc.Call,
+			false,
+			0,
+			0,
+			evmc.Address{},
+			evmc.Address{},
+			nil,
+			evmc.Hash{},
+			nil,
+			[]byte{0x00}, // < STOP
+		)
+		if err == nil {
+			res = append(res, revision)
+		}
+	}
+	return res
+}
+
 // GetEvmcVM provides direct access to the Evmc VM connected through the EVMC library.
 func (e *EvmcInterpreter) GetEvmcVM() *evmc.VM {
 	return e.vm
@@ -174,9 +227,7 @@ func (ctx *hostContext) AccountExists(addr evmc.Address) bool {
 }
 
 func (ctx *hostContext) isEmpty(addr evmc.Address) bool {
-	return (ctx.context.GetNonce(tosca.Address(addr)) == 0 &&
-		ctx.context.GetBalance(tosca.Address(addr)) == tosca.Value{} &&
-		ctx.context.GetCodeSize(tosca.Address(addr)) == 0)
+	return tosca.IsEmptyAccount(ctx.context, tosca.Address(addr))
 }
 
 func (ctx *hostContext) GetStorage(addr evmc.Address, key evmc.Hash) evmc.Hash {
@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package evmc
+
+import (
+	"sync/atomic"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// HostQueryStatistics summarizes the read-only host queries issued by EVMC
+// interpreters and how many of them got served by the per-frame cache instead
+// of the underlying run context.
+type HostQueryStatistics struct {
+	Queries uint64 // < the number of balance, storage, and code size queries
+	Hits    uint64 // < the number of queries answered from the cache
+}
+
+// hostQueryCounters accumulates HostQueryStatistics of concurrent executions.
+type hostQueryCounters struct {
+	queries atomic.Uint64
+	hits    atomic.Uint64
+}
+
+func (c *hostQueryCounters) add(queries, hits uint64) {
+	c.queries.Add(queries)
+	c.hits.Add(hits)
+}
+
+func (c *hostQueryCounters) get() HostQueryStatistics {
+	return HostQueryStatistics{
+		Queries: c.queries.Load(),
+		Hits:    c.hits.Load(),
+	}
+}
+
+type storageLocation struct {
+	address tosca.Address
+	key     tosca.Key
+}
+
+// frameCache is a run context caching the results of read-only queries for
+// the duration of a single message frame, sparing repeated lookups in the
+// underlying context for values accessed by the interpreter over and over
+// again. Values which may be modified are kept up-to-date by the cache's
+// write operations. Since nested calls may modify any part of the state, the
+// cache is cleared whenever a call is made.
+//
+// A frameCache is not thread-safe and must only be used by a single execution.
+type frameCache struct {
+	tosca.RunContext
+	balances  map[tosca.Address]tosca.Value
+	codeSizes map[tosca.Address]int
+	storage   map[storageLocation]tosca.Word
+	queries   uint64
+	hits      uint64
+}
+
+func newFrameCache(context tosca.RunContext) *frameCache {
+	return &frameCache{RunContext: context}
+}
+
+func (c *frameCache) GetBalance(address tosca.Address) tosca.Value {
+	c.queries++
+	if value, found := c.balances[address]; found {
+		c.hits++
+		return value
+	}
+	value := c.RunContext.GetBalance(address)
+	if c.balances == nil {
+		c.balances = map[tosca.Address]tosca.Value{}
+	}
+	c.balances[address] = value
+	return value
+}
+
+func (c *frameCache) GetCodeSize(address tosca.Address) int {
+	c.queries++
+	if size, found := c.codeSizes[address]; found {
+		c.hits++
+		return size
+	}
+	size := c.RunContext.GetCodeSize(address)
+	if c.codeSizes == nil {
+		c.codeSizes = map[tosca.Address]int{}
+	}
+	c.codeSizes[address] = size
+	return size
+}
+
+func (c *frameCache) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	c.queries++
+	location := storageLocation{address, key}
+	if value, found := c.storage[location]; found {
+		c.hits++
+		return value
+	}
+	value := c.RunContext.GetStorage(address, key)
+	if c.storage == nil {
+		c.storage = map[storageLocation]tosca.Word{}
+	}
+	c.storage[location] = value
+	return value
+}
+
+func (c *frameCache) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	status := c.RunContext.SetStorage(address, key, value)
+	if c.storage != nil {
+		c.storage[storageLocation{address, key}] = value
+	}
+	return status
+}
+
+func (c *frameCache) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	// Balances are moved and, depending on the revision, the account may be
+	// cleared; all cached balances are thus dropped.
+	c.balances = nil
+	return c.RunContext.SelfDestruct(address, beneficiary)
+}
+
+func (c *frameCache) Call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	c.clear()
+	return c.RunContext.Call(kind, parameters)
+}
+
+func (c *frameCache) RestoreSnapshot(snapshot tosca.Snapshot) {
+	c.clear()
+	c.RunContext.RestoreSnapshot(snapshot)
+}
+
+func (c *frameCache) clear() {
+	c.balances = nil
+	c.codeSizes = nil
+	c.storage = nil
+}
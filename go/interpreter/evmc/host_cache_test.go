@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package evmc
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFrameCache_RepeatedQueriesAreServedFromCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockRunContext(ctrl)
+
+	address := tosca.Address{1}
+	key := tosca.Key{2}
+	context.EXPECT().GetBalance(address).Return(tosca.Value{3}).Times(1)
+	context.EXPECT().GetCodeSize(address).Return(4).Times(1)
+	context.EXPECT().GetStorage(address, key).Return(tosca.Word{5}).Times(1)
+
+	cache := newFrameCache(context)
+	for i := 0; i < 3; i++ {
+		if want, got := (tosca.Value{3}), cache.GetBalance(address); want != got {
+			t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+		}
+		if want, got := 4, cache.GetCodeSize(address); want != got {
+			t.Errorf("unexpected code size, wanted %v, got %v", want, got)
+		}
+		if want, got := (tosca.Word{5}), cache.GetStorage(address, key); want != got {
+			t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+		}
+	}
+	if want, got := uint64(9), cache.queries; want != got {
+		t.Errorf("unexpected number of queries, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(6), cache.hits; want != got {
+		t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+	}
+}
+
+func TestFrameCache_StorageUpdatesAreReflected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockRunContext(ctrl)
+
+	address := tosca.Address{1}
+	key := tosca.Key{2}
+	context.EXPECT().GetStorage(address, key).Return(tosca.Word{1})
+	context.EXPECT().SetStorage(address, key, tosca.Word{2}).Return(tosca.StorageModified)
+
+	cache := newFrameCache(context)
+	cache.GetStorage(address, key)
+	if want, got := tosca.StorageModified, cache.SetStorage(address, key, tosca.Word{2}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{2}), cache.GetStorage(address, key); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+}
+
+func TestFrameCache_CallsAndSelfDestructsInvalidateCachedValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockRunContext(ctrl)
+
+	address := tosca.Address{1}
+	key := tosca.Key{2}
+	gomock.InOrder(
+		context.EXPECT().GetBalance(address).Return(tosca.Value{1}),
+		context.EXPECT().SelfDestruct(address, tosca.Address{2}).Return(true),
+		context.EXPECT().GetBalance(address).Return(tosca.Value{}),
+	)
+	gomock.InOrder(
+		context.EXPECT().GetStorage(address, key).Return(tosca.Word{1}),
+		context.EXPECT().Call(tosca.Call, gomock.Any()),
+		context.EXPECT().GetStorage(address, key).Return(tosca.Word{2}),
+		context.EXPECT().RestoreSnapshot(tosca.Snapshot(1)),
+		context.EXPECT().GetStorage(address, key).Return(tosca.Word{1}),
+	)
+
+	cache := newFrameCache(context)
+	cache.GetBalance(address)
+	cache.SelfDestruct(address, tosca.Address{2})
+	if want, got := (tosca.Value{}), cache.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+
+	cache.GetStorage(address, key)
+	if _, err := cache.Call(tosca.Call, tosca.CallParameters{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := (tosca.Word{2}), cache.GetStorage(address, key); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	cache.RestoreSnapshot(tosca.Snapshot(1))
+	if want, got := (tosca.Word{1}), cache.GetStorage(address, key); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+}
+
+func TestHostQueryCounters_AccumulateStatistics(t *testing.T) {
+	var counters hostQueryCounters
+	counters.add(5, 2)
+	counters.add(3, 1)
+	if want, got := (HostQueryStatistics{Queries: 8, Hits: 3}), counters.get(); want != got {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package evmc
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// LibraryPathEnv names the environment variable listing directories searched
+// for EVMC libraries by FindLibrary. Directories are separated by the path
+// list separator of the operating system (':' on Unix systems).
+const LibraryPathEnv = "TOSCA_EVMC_LIBRARY_PATH"
+
+// FindLibrary locates the given EVMC library file. The directories listed by
+// the LibraryPathEnv environment variable are searched first, followed by the
+// given directories. If the library is not found in any of them, the plain
+// library name is returned, leaving the lookup to the dynamic linker, which
+// considers the rpath of the binary and the LD_LIBRARY_PATH.
+func FindLibrary(library string, dirs ...string) string {
+	candidates := append(filepath.SplitList(os.Getenv(LibraryPathEnv)), dirs...)
+	for _, dir := range candidates {
+		if dir == "" {
+			continue
+		}
+		path := filepath.Join(dir, library)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return library
+}
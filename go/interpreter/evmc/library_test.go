@@ -0,0 +1,40 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package evmc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLibrary_SearchesConfiguredDirectories(t *testing.T) {
+	const library = "libtest.so"
+	env, given, empty := t.TempDir(), t.TempDir(), t.TempDir()
+	for _, dir := range []string{env, given} {
+		if err := os.WriteFile(filepath.Join(dir, library), nil, 0600); err != nil {
+			t.Fatalf("failed to create library file: %v", err)
+		}
+	}
+
+	t.Setenv(LibraryPathEnv, "")
+	if want, got := library, FindLibrary(library, empty); want != got {
+		t.Errorf("unexpected fallback, wanted %s, got %s", want, got)
+	}
+	if want, got := filepath.Join(given, library), FindLibrary(library, empty, given); want != got {
+		t.Errorf("unexpected library, wanted %s, got %s", want, got)
+	}
+
+	t.Setenv(LibraryPathEnv, empty+string(os.PathListSeparator)+env)
+	if want, got := filepath.Join(env, library), FindLibrary(library, given); want != got {
+		t.Errorf("environment should take precedence, wanted %s, got %s", want, got)
+	}
+}
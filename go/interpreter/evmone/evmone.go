@@ -27,21 +27,28 @@ func init() {
 	// of the evmone project is added to the rpath of the resulting library.
 	// This way, the libevmone.so file can be found during runtime, even if
 	// the LD_LIBRARY_PATH is not set accordingly.
-	evmone, err := evmc.LoadEvmcInterpreter("libevmone.so")
+	// The library may also be provided through the directories listed by
+	// the evmc.LibraryPathEnv environment variable.
+	library := evmc.FindLibrary("libevmone.so")
+	evmone, err := evmc.LoadEvmcInterpreter(library)
 	if err != nil {
 		panic(fmt.Errorf("failed to load evmone library: %s", err))
 	}
+	metadata, err = getMetadata(evmone)
+	if err != nil {
+		panic(err)
+	}
 	// This instance remains in its basic configuration and is registered
 	// as the default "evmone" VM and as the "evmone-basic" tosca.
 	tosca.MustRegisterInterpreterFactory("evmone", func(any) (tosca.Interpreter, error) {
 		return &evmoneInstance{evmone}, nil
-	})
+	}, metadata)
 	tosca.MustRegisterInterpreterFactory("evmone-basic", func(any) (tosca.Interpreter, error) {
 		return &evmoneInstance{evmone}, nil
-	})
+	}, metadata)
 
 	// A second instance is configured to use the advanced execution mode.
-	evmone, err = evmc.LoadEvmcInterpreter("libevmone.so")
+	evmone, err = evmc.LoadEvmcInterpreter(library)
 	if err != nil {
 		panic(fmt.Errorf("failed to load evmone library: %s", err))
 	}
@@ -50,17 +57,34 @@ func init() {
 	}
 	tosca.MustRegisterInterpreterFactory("evmone-advanced", func(any) (tosca.Interpreter, error) {
 		return &evmoneInstance{evmone}, nil
-	})
+	}, metadata)
 }
 
 type evmoneInstance struct {
 	e *evmc.EvmcInterpreter
 }
 
-const newestSupportedRevision = tosca.R13_Cancun
+// metadata describes all evmone configurations in the interpreter registry.
+// The supported revisions are negotiated with the loaded library.
+var metadata tosca.ImplementationMetadata
+
+// getMetadata derives the registry metadata from the revisions supported by
+// the given evmone instance.
+func getMetadata(evmone *evmc.EvmcInterpreter) (tosca.ImplementationMetadata, error) {
+	revisions := evmone.SupportedRevisions()
+	if len(revisions) == 0 {
+		return tosca.ImplementationMetadata{}, fmt.Errorf("evmone library supports none of the revisions known to Tosca")
+	}
+	return tosca.ImplementationMetadata{
+		Language:       "C++",
+		RequiresCgo:    true,
+		OldestRevision: revisions[0],
+		NewestRevision: revisions[len(revisions)-1],
+	}, nil
+}
 
 func (e *evmoneInstance) Run(params tosca.Parameters) (tosca.Result, error) {
-	if params.Revision > newestSupportedRevision {
+	if params.Revision < metadata.OldestRevision || params.Revision > metadata.NewestRevision {
 		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
 	}
 	return e.e.Run(params)
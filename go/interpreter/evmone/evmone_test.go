@@ -78,3 +78,22 @@ func benchmarkFib(b *testing.B, arg int) {
 		})
 	}
 }
+
+func TestEvmone_MetadataCoversRevisionsSupportedByLibrary(t *testing.T) {
+	for _, info := range tosca.ListInterpreters() {
+		if info.Name != "evmone" {
+			continue
+		}
+		if info.Metadata == nil {
+			t.Fatalf("missing metadata of evmone")
+		}
+		if want, got := tosca.R07_Istanbul, info.Metadata.OldestRevision; want != got {
+			t.Errorf("unexpected oldest revision, wanted %v, got %v", want, got)
+		}
+		if info.Metadata.NewestRevision < tosca.R13_Cancun {
+			t.Errorf("evmone should support Cancun, newest revision is %v", info.Metadata.NewestRevision)
+		}
+		return
+	}
+	t.Errorf("evmone is not registered")
+}
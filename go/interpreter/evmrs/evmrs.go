@@ -35,7 +35,7 @@ func init() {
 		// This instance remains in its basic configuration.
 		tosca.MustRegisterInterpreterFactory("evmrs", func(any) (tosca.Interpreter, error) {
 			return &evmrsInstance{evm}, nil
-		})
+		}, metadata)
 	}
 
 	{
@@ -48,7 +48,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmrs-logging", func(any) (tosca.Interpreter, error) {
 			return &evmrsInstance{evm}, nil
-		})
+		}, metadata)
 	}
 }
 
@@ -58,6 +58,14 @@ type evmrsInstance struct {
 
 const newestSupportedRevision = tosca.R13_Cancun
 
+// metadata describes all evmrs configurations in the interpreter registry.
+var metadata = tosca.ImplementationMetadata{
+	Language:       "Rust",
+	RequiresCgo:    true,
+	OldestRevision: tosca.R07_Istanbul,
+	NewestRevision: newestSupportedRevision,
+}
+
 func (e *evmrsInstance) Run(params tosca.Parameters) (tosca.Result, error) {
 	if params.Revision > newestSupportedRevision {
 		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
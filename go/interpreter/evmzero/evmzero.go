@@ -38,7 +38,7 @@ func init() {
 		// This instance remains in its basic configuration.
 		tosca.MustRegisterInterpreterFactory("evmzero", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstance{evm}, nil
-		})
+		}, metadata)
 	}
 
 	// We create a second instance in which we enable logging.
@@ -52,7 +52,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmzero-logging", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstance{evm}, nil
-		})
+		}, metadata)
 	}
 
 	// A third instance without analysis cache.
@@ -66,7 +66,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmzero-no-analysis-cache", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstance{evm}, nil
-		})
+		}, metadata)
 	}
 
 	// Another instance without SHA3 cache.
@@ -80,7 +80,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmzero-no-sha3-cache", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstance{evm}, nil
-		})
+		}, metadata)
 	}
 
 	// Another instance in which we enable profiling.
@@ -94,7 +94,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmzero-profiling", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstanceWithProfiler{&evmzeroInstance{evm}}, nil
-		})
+		}, metadata)
 	}
 
 	// Another instance in which we enable profiling external.
@@ -108,7 +108,7 @@ func init() {
 		}
 		tosca.MustRegisterInterpreterFactory("evmzero-profiling-external", func(any) (tosca.Interpreter, error) {
 			return &evmzeroInstanceWithProfiler{&evmzeroInstance{evm}}, nil
-		})
+		}, metadata)
 	}
 }
 
@@ -118,6 +118,14 @@ type evmzeroInstance struct {
 
 const newestSupportedRevision = tosca.R13_Cancun
 
+// metadata describes all evmzero configurations in the interpreter registry.
+var metadata = tosca.ImplementationMetadata{
+	Language:       "C++",
+	RequiresCgo:    true,
+	OldestRevision: tosca.R07_Istanbul,
+	NewestRevision: newestSupportedRevision,
+}
+
 func (e *evmzeroInstance) Run(params tosca.Parameters) (tosca.Result, error) {
 	if params.Revision > newestSupportedRevision {
 		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
@@ -17,6 +17,8 @@ import (
 
 	ct "github.com/Fantom-foundation/Tosca/go/ct/common"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/tracing"
@@ -31,6 +33,10 @@ import (
 func init() {
 	tosca.MustRegisterInterpreterFactory("geth", func(any) (tosca.Interpreter, error) {
 		return &gethVm{}, nil
+	}, tosca.ImplementationMetadata{
+		Language:       "Go",
+		OldestRevision: tosca.R07_Istanbul,
+		NewestRevision: newestSupportedRevision,
 	})
 }
 
@@ -43,6 +49,11 @@ func (m *gethVm) Run(parameters tosca.Parameters) (tosca.Result, error) {
 	if parameters.Revision > newestSupportedRevision {
 		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: parameters.Revision}
 	}
+	if interrupt := parameters.Interrupt; interrupt != nil {
+		if err := interrupt.Err(); err != nil {
+			return tosca.Result{}, err
+		}
+	}
 	evm, contract, stateDb := createGethInterpreterContext(parameters)
 
 	output, err := evm.Interpreter().Run(contract, parameters.Input, false)
@@ -117,13 +128,13 @@ func MakeChainConfig(baseline params.ChainConfig, chainId *big.Int, targetRevisi
 	chainConfig.BerlinBlock = big.NewInt(0).SetUint64(berlinBlock)
 	chainConfig.LondonBlock = big.NewInt(0).SetUint64(londonBlock)
 
-	if targetRevision >= tosca.R11_Paris {
+	if revisions.IsActive(targetRevision, revisions.EIP3675) {
 		chainConfig.MergeNetsplitBlock = big.NewInt(0).SetUint64(parisBlock)
 	}
-	if targetRevision >= tosca.R12_Shanghai {
+	if revisions.IsActive(targetRevision, revisions.EIP3855) {
 		chainConfig.ShanghaiTime = &shanghaiTime
 	}
-	if targetRevision >= tosca.R13_Cancun {
+	if revisions.IsActive(targetRevision, revisions.EIP1153) {
 		chainConfig.CancunTime = &cancunTime
 	}
 
@@ -178,6 +189,9 @@ func createGethInterpreterContext(parameters tosca.Parameters) (*geth.EVM, *geth
 
 	// Set interpreter variant for this VM
 	config := geth.Config{}
+	if parameters.Tracer != nil {
+		config.Tracer = newTracerAdapter(parameters)
+	}
 
 	stateDb := &stateDbAdapter{context: parameters.Context}
 	evm := geth.NewEVM(blockCtx, txCtx, stateDb, &chainConfig, config)
@@ -195,12 +209,43 @@ func createGethInterpreterContext(parameters tosca.Parameters) (*geth.EVM, *geth
 	contract.Code = parameters.Code
 	contract.CodeHash = crypto.Keccak256Hash(parameters.Code)
 	contract.Input = parameters.Input
+	contract.IsDeployment = parameters.InitCode
 
 	return evm, contract, stateDb
 }
 
 // --- Adapter ---
 
+// newTracerAdapter creates geth tracing hooks forwarding the execution of
+// instructions and nested calls to the tracer of the given parameters.
+func newTracerAdapter(parameters tosca.Parameters) *tracing.Hooks {
+	hooks := NewTracerHooks(parameters.Tracer, parameters.Depth, parameters.Sender)
+	var stack []tosca.Word
+	hooks.OnOpcode = func(pc uint64, op byte, gas, _ uint64, scope tracing.OpContext, _ []byte, depth int, _ error) {
+		stack = stack[:0]
+		for _, value := range scope.StackData() {
+			stack = append(stack, value.Bytes32())
+		}
+		initCode := false
+		if scope, ok := scope.(*geth.ScopeContext); ok {
+			initCode = scope.Contract.IsDeployment
+		}
+		// The depth of the geth EVM starts at 1 for the code run by this
+		// interpreter, and is increased for calls executed by geth.
+		parameters.Tracer.OnOpcode(tosca.OpCodeState{
+			Pc:       int(pc),
+			OpCode:   vm.OpCode(op),
+			Gas:      tosca.Gas(gas),
+			Depth:    parameters.Depth + depth - 1,
+			Address:  tosca.Address(scope.Address()),
+			Stack:    stack,
+			Memory:   scope.MemoryData(),
+			InitCode: initCode,
+		})
+	}
+	return hooks
+}
+
 // transferFunc subtracts amount from sender and adds amount to recipient using the given Db
 // Now is doing nothing as this is not changing gas computation
 func transferFunc(stateDB geth.StateDB, callerAddress common.Address, to common.Address, value *uint256.Int) {
@@ -219,14 +264,27 @@ func canTransferFunc(stateDB geth.StateDB, callerAddress common.Address, value *
 // conformance testing for the geth interpreter.
 type stateDbAdapter struct {
 	context         tosca.TransactionContext
+	preimages       tosca.PreimageRecorder // < nil if preimages are not recorded
 	refund          uint64
 	lastBeneficiary tosca.Address
 	refundBackups   map[tosca.Snapshot]uint64
+	touched         []tosca.Address // < journal of touched accounts
+	touchedBackups  map[tosca.Snapshot]int
+	ripemdTouched   bool // < true if RIPEMD-160 got touched by a reverted call
+	pointCache      *utils.PointCache
 }
 
+// NewStateDbAdapter creates an adapter for the given context. Preimages of
+// hashes are forwarded to the context if it provides the respective
+// capability, and are dropped otherwise.
 func NewStateDbAdapter(context tosca.TransactionContext) *stateDbAdapter {
+	var preimages tosca.PreimageRecorder
+	if tosca.GetCapabilities(context).Has(tosca.CapabilityPreimages) {
+		preimages = context.(tosca.PreimageRecorder)
+	}
 	return &stateDbAdapter{
-		context: context,
+		context:   context,
+		preimages: preimages,
 	}
 }
 
@@ -246,6 +304,7 @@ func (s *stateDbAdapter) SubBalance(addr common.Address, diff *uint256.Int, _ tr
 	account := tosca.Address(addr)
 	cur := s.context.GetBalance(account)
 	s.context.SetBalance(account, tosca.Sub(cur, tosca.ValueFromUint256(diff)))
+	s.Touch(addr)
 }
 
 func (s *stateDbAdapter) AddBalance(addr common.Address, diff *uint256.Int, _ tracing.BalanceChangeReason) {
@@ -256,6 +315,7 @@ func (s *stateDbAdapter) AddBalance(addr common.Address, diff *uint256.Int, _ tr
 	account := tosca.Address(addr)
 	cur := s.context.GetBalance(account)
 	s.context.SetBalance(account, tosca.Add(cur, tosca.ValueFromUint256(diff)))
+	s.Touch(addr)
 
 	// we save this address to be used as the beneficiary in a selfdestruct case.
 	s.lastBeneficiary = tosca.Address(addr)
@@ -346,7 +406,7 @@ func (s *stateDbAdapter) Exist(addr common.Address) bool {
 }
 
 func (s *stateDbAdapter) Empty(addr common.Address) bool {
-	return s.GetBalance(addr).IsZero() && s.GetNonce(addr) == 0 && s.GetCodeSize(addr) == 0
+	return tosca.IsEmptyAccount(s.context, tosca.Address(addr))
 }
 
 func (s *stateDbAdapter) PrepareAccessList(sender common.Address, dest *common.Address, precompiles []common.Address, txAccesses types.AccessList) {
@@ -391,17 +451,50 @@ func (s *stateDbAdapter) Prepare(rules params.Rules, sender, coinbase common.Add
 func (s *stateDbAdapter) RevertToSnapshot(snapshot int) {
 	s.context.RestoreSnapshot(tosca.Snapshot(snapshot))
 	s.refund = s.refundBackups[tosca.Snapshot(snapshot)]
+	if mark, found := s.touchedBackups[tosca.Snapshot(snapshot)]; found && mark < len(s.touched) {
+		for _, address := range s.touched[mark:] {
+			if address == ripemdAddress {
+				s.ripemdTouched = true
+			}
+		}
+		s.touched = s.touched[:mark]
+	}
 }
 
 func (s *stateDbAdapter) Snapshot() int {
 	id := s.context.CreateSnapshot()
 	if s.refundBackups == nil {
 		s.refundBackups = make(map[tosca.Snapshot]uint64)
+		s.touchedBackups = make(map[tosca.Snapshot]int)
 	}
 	s.refundBackups[id] = s.refund
+	s.touchedBackups[id] = len(s.touched)
 	return int(id)
 }
 
+// ripemdAddress is the address of the RIPEMD-160 precompiled contract.
+var ripemdAddress = tosca.Address{19: 3}
+
+// Touch marks the given account as touched, such that it is reported by
+// GetTouchedAccounts unless the touch is reverted. Like in geth, touches of
+// the RIPEMD-160 precompiled contract survive reverts, reproducing the
+// resolution of a consensus issue on Ethereum's mainnet in 2016.
+func (s *stateDbAdapter) Touch(addr common.Address) {
+	s.touched = append(s.touched, tosca.Address(addr))
+}
+
+// GetTouchedAccounts returns the accounts touched so far and not reverted.
+func (s *stateDbAdapter) GetTouchedAccounts() tosca.TouchedAccounts {
+	res := tosca.TouchedAccounts{}
+	for _, address := range s.touched {
+		res.Touch(address)
+	}
+	if s.ripemdTouched {
+		res.Touch(ripemdAddress)
+	}
+	return res
+}
+
 func (s *stateDbAdapter) AddLog(log *types.Log) {
 	topics := make([]tosca.Hash, 0, len(log.Topics))
 	for _, cur := range log.Topics {
@@ -418,19 +511,32 @@ func (s *stateDbAdapter) GetLogs() []tosca.Log {
 	return s.context.GetLogs()
 }
 
-func (s *stateDbAdapter) AddPreimage(common.Hash, []byte) {
-	panic("should not be needed in test environments")
+func (s *stateDbAdapter) AddPreimage(hash common.Hash, preimage []byte) {
+	// Preimages are only reported if their recording is enabled in the EVM
+	// configuration, which is not the case for any supported setup.
+	if s.preimages != nil {
+		s.preimages.AddPreimage(tosca.Hash(hash), preimage)
+	}
 }
 
 func (s *stateDbAdapter) ForEachStorage(common.Address, func(common.Hash, common.Hash) bool) error {
-	panic("should not be needed in test environments")
+	return errStorageIterationNotSupported
 }
 
+var errStorageIterationNotSupported = errors.New("iterating storage is not supported by transaction contexts")
+
 func (s *stateDbAdapter) PointCache() *utils.PointCache {
-	// see https://eips.ethereum.org/EIPS/eip-4762
-	panic("should not be needed by revisions up to Cancun")
+	// The cache is only used for stateless execution (EIP-4762), which is not
+	// part of any supported revision. It is merely provided for completeness.
+	if s.pointCache == nil {
+		s.pointCache = utils.NewPointCache(pointCacheSize)
+	}
+	return s.pointCache
 }
 
+// pointCacheSize is the number of entries of point caches, matching geth.
+const pointCacheSize = 4096
+
 func (s *stateDbAdapter) Witness() *stateless.Witness {
 	// this should not be relevant for revisions up to Cancun
 	return nil
@@ -0,0 +1,204 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package geth
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGethVm_IsRegisteredAsInterpreter(t *testing.T) {
+	interpreter, err := tosca.NewInterpreter("geth")
+	if err != nil {
+		t.Fatalf("failed to create geth interpreter: %v", err)
+	}
+	if _, ok := interpreter.(*gethVm); !ok {
+		t.Errorf("unexpected interpreter type %T", interpreter)
+	}
+	for _, info := range tosca.ListInterpreters() {
+		if info.Name != "geth" {
+			continue
+		}
+		if info.Metadata == nil {
+			t.Fatalf("missing metadata of geth interpreter")
+		}
+		if want, got := newestSupportedRevision, info.Metadata.NewestRevision; want != got {
+			t.Errorf("unexpected newest revision, wanted %v, got %v", want, got)
+		}
+		return
+	}
+	t.Errorf("geth interpreter is not listed")
+}
+
+func TestGethVm_RunProducesResultsOfExecutions(t *testing.T) {
+	tests := map[string]struct {
+		code    []byte
+		success bool
+		output  []byte
+		gasLeft tosca.Gas
+	}{
+		"stop": {
+			code:    []byte{byte(vm.STOP)},
+			success: true,
+			gasLeft: 100,
+		},
+		"return": {
+			code: []byte{
+				byte(vm.PUSH1), 0x2a,
+				byte(vm.PUSH1), 0,
+				byte(vm.MSTORE8),
+				byte(vm.PUSH1), 1,
+				byte(vm.PUSH1), 0,
+				byte(vm.RETURN),
+			},
+			success: true,
+			output:  []byte{0x2a},
+			gasLeft: 100 - 4*3 - 3 - 3, // < pushes, MSTORE8, memory expansion
+		},
+		"revert": {
+			code: []byte{
+				byte(vm.PUSH1), 0,
+				byte(vm.PUSH1), 0,
+				byte(vm.REVERT),
+			},
+			success: false,
+			gasLeft: 100 - 2*3,
+		},
+		"stack underflow": {
+			code:    []byte{byte(vm.ADD)},
+			success: false,
+		},
+		"invalid instruction": {
+			code:    []byte{byte(vm.INVALID)},
+			success: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockRunContext(ctrl)
+
+			interpreter := &gethVm{}
+			result, err := interpreter.Run(tosca.Parameters{
+				BlockParameters: tosca.BlockParameters{Revision: tosca.R13_Cancun},
+				Context:         context,
+				Code:            test.code,
+				Gas:             100,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := test.success, result.Success; want != got {
+				t.Errorf("unexpected success, wanted %t, got %t", want, got)
+			}
+			if want, got := test.output, result.Output; !bytes.Equal(want, got) {
+				t.Errorf("unexpected output, wanted %x, got %x", want, got)
+			}
+			if want, got := test.gasLeft, result.GasLeft; want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestGethVm_RunRejectsUnsupportedRevisions(t *testing.T) {
+	interpreter := &gethVm{}
+	_, err := interpreter.Run(tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{Revision: newestSupportedRevision + 1},
+		Code:            []byte{byte(vm.STOP)},
+	})
+	var unsupported *tosca.ErrUnsupportedRevision
+	if !errors.As(err, &unsupported) {
+		t.Errorf("unexpected error, wanted ErrUnsupportedRevision, got %v", err)
+	}
+}
+
+type preimageRecordingContext struct {
+	tosca.TransactionContext
+	preimages map[tosca.Hash][]byte
+}
+
+func (c *preimageRecordingContext) Capabilities() tosca.Capabilities {
+	return tosca.CapabilityPreimages
+}
+
+func (c *preimageRecordingContext) AddPreimage(hash tosca.Hash, preimage []byte) {
+	c.preimages[hash] = preimage
+}
+
+func TestStateDbAdapter_AddPreimageIsForwardedToRecordingContexts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := &preimageRecordingContext{
+		TransactionContext: tosca.NewMockTransactionContext(ctrl),
+		preimages:          map[tosca.Hash][]byte{},
+	}
+
+	adapter := NewStateDbAdapter(context)
+	adapter.AddPreimage(common.Hash{1}, []byte{1, 2, 3})
+
+	if want, got := []byte{1, 2, 3}, context.preimages[tosca.Hash{1}]; !bytes.Equal(want, got) {
+		t.Errorf("unexpected preimage, wanted %v, got %v", want, got)
+	}
+}
+
+func TestStateDbAdapter_AddPreimageIsIgnoredByPlainContexts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	adapter := NewStateDbAdapter(tosca.NewMockTransactionContext(ctrl))
+	adapter.AddPreimage(common.Hash{1}, []byte{1, 2, 3}) // < must not panic
+}
+
+func TestStateDbAdapter_UnsupportedFeaturesDoNotPanic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	adapter := NewStateDbAdapter(tosca.NewMockTransactionContext(ctrl))
+
+	if adapter.PointCache() == nil {
+		t.Errorf("point cache should be available")
+	}
+	if adapter.PointCache() != adapter.PointCache() {
+		t.Errorf("point cache should be reused")
+	}
+	err := adapter.ForEachStorage(common.Address{}, func(common.Hash, common.Hash) bool { return true })
+	if !errors.Is(err, errStorageIterationNotSupported) {
+		t.Errorf("unexpected error, wanted %v, got %v", errStorageIterationNotSupported, err)
+	}
+}
+
+func TestStateDbAdapter_RevertedTouchesAreDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	context.EXPECT().CreateSnapshot().Return(tosca.Snapshot(1))
+	context.EXPECT().RestoreSnapshot(tosca.Snapshot(1))
+
+	adapter := NewStateDbAdapter(context)
+	adapter.Touch(common.Address{1})
+	snapshot := adapter.Snapshot()
+	adapter.Touch(common.Address{2})
+	adapter.Touch(common.Address(ripemdAddress))
+	adapter.RevertToSnapshot(snapshot)
+
+	touched := adapter.GetTouchedAccounts()
+	if !touched.IsTouched(tosca.Address{1}) {
+		t.Errorf("touch before snapshot should be retained")
+	}
+	if touched.IsTouched(tosca.Address{2}) {
+		t.Errorf("reverted touch should be dropped")
+	}
+	if !touched.IsTouched(ripemdAddress) {
+		t.Errorf("reverted touch of RIPEMD-160 should be retained")
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package geth
+
+import (
+	"math/big"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	geth "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// NewTracerHooks creates geth tracing hooks forwarding the start and end of
+// calls observed by a geth EVM to the given tracer. Instructions are reported
+// by the interpreters, and state changes by the transaction context.
+//
+// The depth of reported calls is offset by the given depth, and the given
+// sender is used as the sender of delegate calls issued by code at this
+// depth. Both are only relevant for EVM instances started for nested calls.
+func NewTracerHooks(tracer tosca.Tracer, depth int, sender tosca.Address) *tracing.Hooks {
+	type frame struct {
+		kind    tosca.CallKind
+		gas     tosca.Gas
+		sender  tosca.Address
+		address tosca.Address
+	}
+	frames := []frame{{sender: sender}}
+	return &tracing.Hooks{
+		OnEnter: func(evmDepth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+			kind := callKindFromOpCode(geth.OpCode(typ))
+			parameters := tosca.CallParameters{
+				Sender: tosca.Address(from),
+				Input:  input,
+				Gas:    tosca.Gas(gas),
+			}
+			if value != nil {
+				parameters.Value = tosca.ValueFromUint256(uint256.MustFromBig(value))
+			}
+			// Geth reports the executing contract as the source and the
+			// code account as the target of calls, while Tosca reports the
+			// sender and recipient as seen by the executed code.
+			switch kind {
+			case tosca.Call, tosca.StaticCall:
+				parameters.Recipient = tosca.Address(to)
+				parameters.CodeAddress = tosca.Address(to)
+			case tosca.CallCode:
+				parameters.Recipient = tosca.Address(from)
+				parameters.CodeAddress = tosca.Address(to)
+			case tosca.DelegateCall:
+				parameters.Sender = frames[len(frames)-1].sender
+				parameters.Recipient = tosca.Address(from)
+				parameters.CodeAddress = tosca.Address(to)
+			}
+			// The addresses of created contracts are reported as the result
+			// of creates, in line with other processors, and are retained.
+			frames = append(frames, frame{
+				kind:    kind,
+				gas:     tosca.Gas(gas),
+				sender:  parameters.Sender,
+				address: tosca.Address(to),
+			})
+			tracer.OnEnter(depth+evmDepth, kind, parameters)
+		},
+		OnExit: func(evmDepth int, output []byte, gasUsed uint64, err error, _ bool) {
+			frame := frames[len(frames)-1]
+			frames = frames[:len(frames)-1]
+			result := tosca.CallResult{
+				Output:  output,
+				GasLeft: frame.gas - tosca.Gas(gasUsed),
+				Success: err == nil,
+			}
+			if result.Success && (frame.kind == tosca.Create || frame.kind == tosca.Create2) {
+				result.CreatedAddress = frame.address
+			}
+			// Execution failures are reported through the result, errors are
+			// reserved for issues of the processor.
+			tracer.OnExit(depth+evmDepth, result, nil)
+		},
+	}
+}
+
+func callKindFromOpCode(op geth.OpCode) tosca.CallKind {
+	switch op {
+	case geth.CALLCODE:
+		return tosca.CallCode
+	case geth.DELEGATECALL:
+		return tosca.DelegateCall
+	case geth.STATICCALL:
+		return tosca.StaticCall
+	case geth.CREATE:
+		return tosca.Create
+	case geth.CREATE2:
+		return tosca.Create2
+	default:
+		return tosca.Call
+	}
+}
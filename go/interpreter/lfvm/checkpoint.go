@@ -0,0 +1,169 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/holiman/uint256"
+)
+
+// Checkpoint captures the state of a suspended contract execution, enabling
+// it to be resumed later, possibly by another process. Checkpoints only
+// cover the state of the interpreter; the world state and the transaction
+// state, including the warm accounts and storage slots, are managed by the
+// transaction context and need to be preserved by its owner.
+//
+// Only executions of top-level calls can be suspended. Nested calls are
+// conducted through the run context, which keeps the frames of their callers
+// outside of the interpreter.
+type Checkpoint struct {
+	CodeHash   tosca.Hash    `json:"codeHash"`   // < the hash of the executed code
+	Pc         uint64        `json:"pc"`         // < the position of the next instruction in the EVM code
+	Gas        tosca.Gas     `json:"gas"`        // < the gas left
+	Refund     tosca.Gas     `json:"refund"`     // < the gas refund accumulated so far
+	Stack      []tosca.Value `json:"stack"`      // < the stack content, the bottom first
+	Memory     []byte        `json:"memory"`     // < the memory content
+	ReturnData []byte        `json:"returnData"` // < the result of the last nested call
+}
+
+// MarshalBinary encodes the checkpoint for storing it.
+func (c *Checkpoint) MarshalBinary() ([]byte, error) {
+	return json.Marshal(c)
+}
+
+// UnmarshalBinary decodes a checkpoint produced by MarshalBinary.
+func (c *Checkpoint) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, c)
+}
+
+// RunSuspendable executes the given call like Run. If the execution of a
+// top-level call is interrupted, its state is captured in a checkpoint, which
+// is returned together with the error of the interrupt. The execution may then
+// be resumed through Resume.
+//
+// Suspendable executions are run on uncached code without super instructions
+// and do not support tracing or resource usage tracking.
+func (v *lfvm) RunSuspendable(params tosca.Parameters) (tosca.Result, *Checkpoint, error) {
+	return v.runSuspendable(params, nil)
+}
+
+// Resume continues the execution captured by the given checkpoint. The
+// parameters need to describe the same call as the suspended execution; the
+// gas is taken from the checkpoint. If the execution gets interrupted again, a
+// new checkpoint is produced.
+func (v *lfvm) Resume(params tosca.Parameters, checkpoint *Checkpoint) (tosca.Result, *Checkpoint, error) {
+	if checkpoint == nil {
+		return tosca.Result{}, nil, fmt.Errorf("missing checkpoint")
+	}
+	return v.runSuspendable(params, checkpoint)
+}
+
+func (v *lfvm) runSuspendable(params tosca.Parameters, checkpoint *Checkpoint) (tosca.Result, *Checkpoint, error) {
+	if params.Revision > newestSupportedRevision {
+		return tosca.Result{}, nil, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
+	}
+	if len(params.Code) == 0 && checkpoint == nil {
+		return tosca.Result{Success: true, GasLeft: params.Gas}, nil, nil
+	}
+	// Program counters of suspended executions are mapped between the EVM
+	// and the LFVM code through 16-bit pc maps.
+	if len(params.Code) > math.MaxUint16 {
+		return tosca.Result{}, nil, errCodeTooLarge
+	}
+
+	codeHash := Keccak256(params.Code)
+	code := convert(params.Code, ConversionConfig{})
+	pcMap := genPcMap(params.Code)
+
+	var ctxt = context{
+		params:      params,
+		context:     params.Context,
+		gas:         params.Gas,
+		stack:       NewStack(),
+		memory:      NewMemory(),
+		code:        code,
+		shaCache:    v.config.getShaCache(),
+		gasSchedule: v.config.getGasSchedule(),
+		diagnostics: v.config.Diagnostics,
+	}
+	if params.Interrupt != nil {
+		ctxt.interrupt = params.Interrupt.Done()
+	}
+	// As in run, the output of RETURN and REVERT refers to the memory of the
+	// execution, which is handed over to the caller instead of being recycled.
+	outputInMemory := false
+	defer func() {
+		ReturnStack(ctxt.stack)
+		ReturnMemory(ctxt.memory, outputInMemory)
+	}()
+
+	if checkpoint != nil {
+		if err := restoreCheckpoint(&ctxt, checkpoint, codeHash, pcMap); err != nil {
+			return tosca.Result{}, nil, err
+		}
+	}
+
+	status := execute(&ctxt, false)
+	if status == statusInterrupted && params.Depth == 0 {
+		return tosca.Result{}, captureCheckpoint(&ctxt, codeHash, pcMap), params.Interrupt.Err()
+	}
+	result, err := generateResult(status, &ctxt)
+	outputInMemory = len(result.Output) > 0
+	return result, nil, err
+}
+
+func captureCheckpoint(c *context, codeHash tosca.Hash, pcMap *pcMap) *Checkpoint {
+	stack := make([]tosca.Value, c.stack.Len())
+	for i := range stack {
+		stack[i] = c.stack.Get(i).Bytes32()
+	}
+	return &Checkpoint{
+		CodeHash:   codeHash,
+		Pc:         uint64(pcMap.lfvmToEvm[c.pc]),
+		Gas:        c.gas,
+		Refund:     c.refund,
+		Stack:      stack,
+		Memory:     slices.Clone(c.memory.store),
+		ReturnData: slices.Clone(c.returnData),
+	}
+}
+
+func restoreCheckpoint(c *context, checkpoint *Checkpoint, codeHash tosca.Hash, pcMap *pcMap) error {
+	if checkpoint.CodeHash != codeHash {
+		return fmt.Errorf("checkpoint of code %v can not be resumed with code %v", checkpoint.CodeHash, codeHash)
+	}
+	if checkpoint.Pc >= uint64(len(pcMap.evmToLfvm)) {
+		return fmt.Errorf("invalid program counter %d in checkpoint", checkpoint.Pc)
+	}
+	if len(checkpoint.Stack) > maxStackSize {
+		return fmt.Errorf("invalid stack size %d in checkpoint", len(checkpoint.Stack))
+	}
+	if len(checkpoint.Memory)%32 != 0 || len(checkpoint.Memory) > maxMemoryExpansionSize {
+		return fmt.Errorf("invalid memory size %d in checkpoint", len(checkpoint.Memory))
+	}
+
+	c.pc = int32(pcMap.evmToLfvm[checkpoint.Pc])
+	c.gas = checkpoint.Gas
+	c.refund = checkpoint.Refund
+	for _, value := range checkpoint.Stack {
+		c.stack.Push(new(uint256.Int).SetBytes32(value[:]))
+	}
+	words := uint64(len(checkpoint.Memory)) / 32
+	c.memory.store = slices.Clone(checkpoint.Memory)
+	c.memory.currentMemoryCost = c.gasSchedule.MemoryCost(c.params.Revision, words)
+	c.returnData = slices.Clone(checkpoint.ReturnData)
+	return nil
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"bytes"
+	ctx "context"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"go.uber.org/mock/gomock"
+)
+
+// checkpointTestCode counts down from 300, loading a storage slot and storing
+// the counter in memory in each iteration, and returns the final counter.
+var checkpointTestCode = []byte{
+	byte(vm.PUSH2), 0x01, 0x2c,
+	byte(vm.JUMPDEST), // 3
+	byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+	byte(vm.DUP1), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+	byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB),
+	byte(vm.DUP1), byte(vm.PUSH1), 3, byte(vm.JUMPI),
+	byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+}
+
+func newCheckpointTestParameters(t *testing.T, onLoad func()) tosca.Parameters {
+	ctrl := gomock.NewController(t)
+	runContext := tosca.NewMockRunContext(ctrl)
+	runContext.EXPECT().GetStorage(gomock.Any(), gomock.Any()).DoAndReturn(func(tosca.Address, tosca.Key) tosca.Word {
+		onLoad()
+		return tosca.Word{}
+	}).AnyTimes()
+	return tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{Revision: tosca.R07_Istanbul},
+		Context:         runContext,
+		Gas:             1_000_000,
+		Code:            checkpointTestCode,
+	}
+}
+
+func TestLfvm_SuspendedExecutionsCanBeResumed(t *testing.T) {
+	interpreter, err := NewInterpreter(Config{})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+
+	want, err := interpreter.Run(newCheckpointTestParameters(t, func() {}))
+	if err != nil || !want.Success {
+		t.Fatalf("uninterrupted execution failed: %v", err)
+	}
+
+	// The execution is interrupted after a number of storage accesses,
+	// which is detected by the next periodic check of the interrupt.
+	interrupt, cancel := ctx.WithCancel(ctx.Background())
+	loads := 0
+	params := newCheckpointTestParameters(t, func() {
+		if loads++; loads == 10 {
+			cancel()
+		}
+	})
+	params.Interrupt = interrupt
+	_, checkpoint, err := interpreter.RunSuspendable(params)
+	if !errors.Is(err, ctx.Canceled) {
+		t.Fatalf("unexpected error, wanted %v, got %v", ctx.Canceled, err)
+	}
+	if checkpoint == nil {
+		t.Fatalf("missing checkpoint of interrupted execution")
+	}
+	if checkpoint.Pc == 0 || len(checkpoint.Stack) == 0 || len(checkpoint.Memory) != 32 {
+		t.Fatalf("checkpoint does not capture a state within the loop: %+v", checkpoint)
+	}
+
+	encoded, err := checkpoint.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode checkpoint: %v", err)
+	}
+	restored := &Checkpoint{}
+	if err := restored.UnmarshalBinary(encoded); err != nil {
+		t.Fatalf("failed to decode checkpoint: %v", err)
+	}
+
+	got, next, err := interpreter.Resume(newCheckpointTestParameters(t, func() {}), restored)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next != nil {
+		t.Errorf("completed execution should not produce a checkpoint")
+	}
+	if want.Success != got.Success || want.GasLeft != got.GasLeft || !bytes.Equal(want.Output, got.Output) {
+		t.Errorf("unexpected result of resumed execution, wanted %v, got %v", want, got)
+	}
+}
+
+func TestLfvm_NestedExecutionsAreNotSuspended(t *testing.T) {
+	interpreter, err := NewInterpreter(Config{})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	interrupt, cancel := ctx.WithCancel(ctx.Background())
+	cancel()
+
+	params := newCheckpointTestParameters(t, func() {})
+	params.Interrupt = interrupt
+	params.Depth = 1
+	_, checkpoint, err := interpreter.RunSuspendable(params)
+	if !errors.Is(err, ctx.Canceled) {
+		t.Errorf("unexpected error, wanted %v, got %v", ctx.Canceled, err)
+	}
+	if checkpoint != nil {
+		t.Errorf("nested execution should not be suspended")
+	}
+}
+
+func TestLfvm_OutputOfSuspendableExecutionsIsNotRecycled(t *testing.T) {
+	interpreter, err := NewInterpreter(Config{})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+
+	// The returned memory is handed over to the caller, such that later
+	// executions reusing pooled memory do not modify the output.
+	first, _, err := interpreter.RunSuspendable(newCheckpointTestParameters(t, func() {}))
+	if err != nil || !first.Success {
+		t.Fatalf("execution failed: %v", err)
+	}
+	want := bytes.Clone(first.Output)
+
+	params := newCheckpointTestParameters(t, func() {})
+	params.Code = []byte{
+		byte(vm.PUSH1), 0xff, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	for range 10 {
+		if _, _, err := interpreter.RunSuspendable(params); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if !bytes.Equal(want, first.Output) {
+		t.Errorf("output got modified, wanted %x, got %x", want, first.Output)
+	}
+}
+
+func TestLfvm_ResumeRejectsInvalidCheckpoints(t *testing.T) {
+	interpreter, err := NewInterpreter(Config{})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	codeHash := Keccak256(checkpointTestCode)
+
+	tests := map[string]*Checkpoint{
+		"missing":        nil,
+		"other code":     {CodeHash: tosca.Hash{1}},
+		"invalid pc":     {CodeHash: codeHash, Pc: 1 << 20},
+		"invalid stack":  {CodeHash: codeHash, Stack: make([]tosca.Value, maxStackSize+1)},
+		"invalid memory": {CodeHash: codeHash, Memory: make([]byte, 31)},
+	}
+	for name, checkpoint := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := newCheckpointTestParameters(t, func() {})
+			if _, _, err := interpreter.Resume(params, checkpoint); err == nil {
+				t.Errorf("invalid checkpoint was accepted")
+			}
+		})
+	}
+}
@@ -11,53 +11,92 @@
 package lfvm
 
 import (
+	"fmt"
+	"log/slog"
 	"math"
+	"sync/atomic"
 	"unsafe"
 
 	"github.com/Fantom-foundation/Tosca/go/ct/common"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
-	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 // ConversionConfig contains a set of configuration options for the code conversion.
 type ConversionConfig struct {
 	// CacheSize is the maximum size of the maintained code cache in bytes.
-	// If set to 0, a default size is used. If negative, no cache is used.
-	// Cache sizes are grown in increments of maxCachedCodeLength.
-	// Positive values larger than 0 but less than maxCachedCodeLength are
-	// reported as invalid cache sizes during initialization.
+	// If set to 0, the code cache shared by all interpreters is used. If
+	// positive, a dedicated cache of the given size is used. If negative, no
+	// cache is used. Positive values less than the size of the conversion
+	// result of a code of maxCachedCodeLength are reported as invalid cache
+	// sizes during initialization.
 	CacheSize int
 	// WithSuperInstructions enables the use of super instructions.
 	WithSuperInstructions bool
+	// Metrics, if set, receives the hits, misses, and size of the code cache.
+	Metrics tosca.Metrics
+	// Logger, if set, receives notes on codes bypassing the code cache.
+	Logger *slog.Logger
 }
 
 // Converter converts EVM code to LFVM code.
 type Converter struct {
-	config ConversionConfig
-	cache  *lru.Cache[tosca.Hash, Code]
+	config  ConversionConfig
+	cache   *tosca.CodeCache
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+	metrics *conversionMetrics // < nil if no metrics are configured
 }
 
+// conversionMetrics are the instruments a converter reports its cache usage to.
+type conversionMetrics struct {
+	hits   tosca.Counter
+	misses tosca.Counter
+	size   tosca.Gauge
+}
+
+func newConversionMetrics(metrics tosca.Metrics) *conversionMetrics {
+	if metrics == nil {
+		return nil
+	}
+	label := tosca.Label{Name: "cache", Value: "lfvm-conversion"}
+	return &conversionMetrics{
+		hits:   metrics.Counter(tosca.MetricCacheHits, label),
+		misses: metrics.Counter(tosca.MetricCacheMisses, label),
+		size:   metrics.Gauge(tosca.MetricCacheSize, label),
+	}
+}
+
+// ConversionCacheStatistics summarizes the usage of the cache retaining the
+// conversion results of a converter. If the cache is shared, the occupancy
+// covers the artifacts of all its users.
+type ConversionCacheStatistics struct {
+	Hits     uint64 // number of conversions served from the cache
+	Misses   uint64 // number of conversions not found in the cache
+	Entries  int    // number of artifacts retained by the cache
+	Size     int    // total size of the retained artifacts in bytes
+	Capacity int    // maximum total size of the retained artifacts in bytes
+}
+
+const instructionSize = int(unsafe.Sizeof(Instruction{}))
+
 // NewConverter creates a new code converter with the provided configuration.
 func NewConverter(config ConversionConfig) (*Converter, error) {
+	var cache *tosca.CodeCache
 	if config.CacheSize == 0 {
-		config.CacheSize = (1 << 30) // = 1GiB
-	}
-
-	var cache *lru.Cache[tosca.Hash, Code]
-	if config.CacheSize > 0 {
-		var err error
-		const instructionSize = int(unsafe.Sizeof(Instruction{}))
-		capacity := config.CacheSize / maxCachedCodeLength / instructionSize
-		cache, err = lru.New[tosca.Hash, Code](capacity)
-		if err != nil {
-			return nil, err
+		cache = tosca.GetSharedCodeCache()
+		config.CacheSize = cache.Capacity()
+	} else if config.CacheSize > 0 {
+		if minSize := maxCachedCodeLength * instructionSize; config.CacheSize < minSize {
+			return nil, fmt.Errorf("cache size of %d bytes is too small, need at least %d bytes", config.CacheSize, minSize)
 		}
+		cache = tosca.NewCodeCache(config.CacheSize)
 	}
 	return &Converter{
-		config: config,
-		cache:  cache,
+		config:  config,
+		cache:   cache,
+		metrics: newConversionMetrics(config.Metrics),
 	}, nil
 }
 
@@ -69,20 +108,77 @@ func (c *Converter) Convert(code []byte, codeHash *tosca.Hash) Code {
 		return convert(code, c.config)
 	}
 
-	res, exists := c.cache.Get(*codeHash)
-	if exists {
-		return res
+	if res, exists := c.cache.Get(*codeHash, c.cacheKind()); exists {
+		c.hits.Add(1)
+		if c.metrics != nil {
+			c.metrics.hits.Add(1)
+		}
+		return res.(Code)
+	}
+	c.misses.Add(1)
+	if c.metrics != nil {
+		c.metrics.misses.Add(1)
 	}
+	return c.convertAndCache(code, *codeHash)
+}
 
-	res = convert(code, c.config)
+// WarmUp converts the given codes and retains the results in the cache, such
+// that the first executions of those codes do not have to pay for their
+// conversion. This is intended to be used for frequently used contracts when
+// starting up a node. Codes already present in the cache are skipped. If the
+// converter does not use a cache, WarmUp has no effect.
+func (c *Converter) WarmUp(codes ...[]byte) {
+	if c.cache == nil {
+		return
+	}
+	for _, code := range codes {
+		hash := Keccak256(code)
+		if _, exists := c.cache.Get(hash, c.cacheKind()); !exists {
+			c.convertAndCache(code, hash)
+		}
+	}
+}
+
+// Statistics returns a summary of the usage of the cache of this converter.
+// The second result is false if the converter does not use a cache.
+func (c *Converter) Statistics() (ConversionCacheStatistics, bool) {
+	if c.cache == nil {
+		return ConversionCacheStatistics{}, false
+	}
+	return ConversionCacheStatistics{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Entries:  c.cache.Len(),
+		Size:     c.cache.Size(),
+		Capacity: c.cache.Capacity(),
+	}, true
+}
+
+func (c *Converter) convertAndCache(code []byte, codeHash tosca.Hash) Code {
+	res := convert(code, c.config)
 	if len(res) > maxCachedCodeLength {
+		if logger := c.config.Logger; logger != nil {
+			logger.Debug("code too long to be cached", "hash", codeHash, "length", len(code))
+		}
 		return res
 	}
-
-	c.cache.Add(*codeHash, res)
+	c.cache.Add(codeHash, c.cacheKind(), res, cap(res)*instructionSize)
+	if c.metrics != nil {
+		c.metrics.size.Set(float64(c.cache.Size()))
+	}
 	return res
 }
 
+// cacheKind returns the kind of artifacts produced by this converter in the
+// code cache. Conversions with and without super instructions differ and are
+// thus cached independently.
+func (c *Converter) cacheKind() string {
+	if c.config.WithSuperInstructions {
+		return "lfvm-si"
+	}
+	return "lfvm"
+}
+
 // maxCachedCodeLength is the maximum length of a code in bytes that are
 // retained in the cache. To avoid excessive memory usage, longer codes are not
 // cached. The defined limit is the current limit for codes stored on the chain.
@@ -12,13 +12,14 @@ package lfvm
 
 import (
 	"bytes"
+	"log/slog"
 	"math"
 	"math/rand"
 	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
-	"unsafe"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
 	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
@@ -82,44 +83,250 @@ func TestConverter_InputsAreCachedUsingHashAsKey(t *testing.T) {
 	}
 }
 
+func TestConverter_StatisticsReportCacheUsage(t *testing.T) {
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: maxCachedCodeLength * instructionSize,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	hash := tosca.Hash{byte(1)}
+	converter.Convert(code, &hash)
+	converter.Convert(code, &hash)
+	converter.Convert(code, &hash)
+	converter.Convert(code, nil)
+
+	stats, ok := converter.Statistics()
+	if !ok {
+		t.Fatalf("converter should report statistics of its cache")
+	}
+	want := ConversionCacheStatistics{
+		Hits:     2,
+		Misses:   1,
+		Entries:  1,
+		Size:     len(code) * instructionSize,
+		Capacity: maxCachedCodeLength * instructionSize,
+	}
+	if want != stats {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, stats)
+	}
+
+	noCache, err := NewConverter(ConversionConfig{CacheSize: -1})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	if _, ok := noCache.Statistics(); ok {
+		t.Errorf("converter without cache should not report statistics")
+	}
+}
+
+func TestConverter_CacheUsageIsReportedToMetrics(t *testing.T) {
+	metrics := &cacheMetrics{}
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: maxCachedCodeLength * instructionSize,
+		Metrics:   metrics,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	hash := tosca.Hash{byte(1)}
+	converter.Convert(code, &hash)
+	converter.Convert(code, &hash)
+	converter.Convert(code, &hash)
+
+	want := map[string]float64{
+		tosca.MetricCacheHits:   2,
+		tosca.MetricCacheMisses: 1,
+		tosca.MetricCacheSize:   float64(len(code) * instructionSize),
+	}
+	for name, value := range want {
+		if got := metrics.values[name]; value != got {
+			t.Errorf("unexpected value of %s, wanted %v, got %v", name, value, got)
+		}
+	}
+	if want, got := (tosca.Label{Name: "cache", Value: "lfvm-conversion"}), metrics.label; want != got {
+		t.Errorf("unexpected label, wanted %v, got %v", want, got)
+	}
+}
+
+// cacheMetrics records the values of the metrics reported by a converter,
+// which are all labeled by the same single label.
+type cacheMetrics struct {
+	values map[string]float64
+	label  tosca.Label
+}
+
+func (m *cacheMetrics) Counter(name string, labels ...tosca.Label) tosca.Counter {
+	return m.instrument(name, labels)
+}
+
+func (m *cacheMetrics) Gauge(name string, labels ...tosca.Label) tosca.Gauge {
+	return m.instrument(name, labels)
+}
+
+func (m *cacheMetrics) Histogram(name string, labels ...tosca.Label) tosca.Histogram {
+	return m.instrument(name, labels)
+}
+
+func (m *cacheMetrics) instrument(name string, labels []tosca.Label) cacheInstrument {
+	if m.values == nil {
+		m.values = map[string]float64{}
+	}
+	m.label = labels[0]
+	return cacheInstrument{m.values, name}
+}
+
+type cacheInstrument struct {
+	values map[string]float64
+	name   string
+}
+
+func (i cacheInstrument) Add(delta float64)     { i.values[i.name] += delta }
+func (i cacheInstrument) Set(value float64)     { i.values[i.name] = value }
+func (i cacheInstrument) Observe(value float64) { i.values[i.name] += value }
+
+func TestConverter_WarmUpRetainsConversionsInCache(t *testing.T) {
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: maxCachedCodeLength * instructionSize,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	codes := [][]byte{
+		{byte(vm.STOP)},
+		{byte(vm.PUSH1), 1, byte(vm.STOP)},
+	}
+	converter.WarmUp(codes...)
+	converter.WarmUp(codes...) // < already cached codes are skipped
+
+	stats, _ := converter.Statistics()
+	if want, got := len(codes), stats.Entries; want != got {
+		t.Errorf("unexpected number of cached conversions, wanted %d, got %d", want, got)
+	}
+	for _, code := range codes {
+		hash := Keccak256(code)
+		converter.Convert(code, &hash)
+	}
+	stats, _ = converter.Statistics()
+	if want, got := uint64(len(codes)), stats.Hits; want != got {
+		t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(0), stats.Misses; want != got {
+		t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+	}
+}
+
+func TestConverter_WarmUpWithoutCacheHasNoEffect(t *testing.T) {
+	converter, err := NewConverter(ConversionConfig{CacheSize: -1})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	converter.WarmUp([]byte{byte(vm.STOP)})
+}
+
+func TestNewConverter_UsesSharedCacheByDefault(t *testing.T) {
+	converter, err := NewConverter(ConversionConfig{})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	if want, got := tosca.GetSharedCodeCache(), converter.cache; want != got {
+		t.Errorf("Expected converter to use the shared code cache")
+	}
+}
+
+func TestNewConverter_PositiveCacheSizeLeadsToDedicatedCache(t *testing.T) {
+	size := maxCachedCodeLength * instructionSize
+	converter, err := NewConverter(ConversionConfig{CacheSize: size})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	if converter.cache == tosca.GetSharedCodeCache() {
+		t.Errorf("Expected converter to use a dedicated code cache")
+	}
+	if want, got := size, converter.cache.Capacity(); want != got {
+		t.Errorf("Expected cache capacity of %d, got %d", want, got)
+	}
+}
+
 func TestConverter_CacheSizeLimitIsEnforced(t *testing.T) {
-	for _, limit := range []int{10, 100, 1000} {
-		const instructionSize = int(unsafe.Sizeof(Instruction{}))
+	for _, limit := range []int{1, 10, 100} {
+		capacity := limit * maxCachedCodeLength * instructionSize
 		converter, err := NewConverter(ConversionConfig{
-			CacheSize: limit * maxCachedCodeLength * instructionSize,
+			CacheSize: capacity,
 		})
 		if err != nil {
 			t.Fatalf("failed to create converter: %v", err)
 		}
+		code := make([]byte, maxCachedCodeLength)
 		for i := 0; i < limit*10; i++ {
 			hash := tosca.Hash{byte(i), byte(i >> 8), byte(i >> 16)}
-			converter.Convert([]byte{0}, &hash)
+			converter.Convert(code, &hash)
+			if got := converter.cache.Size(); got > capacity {
+				t.Errorf("Conversion cache grew to %d bytes", got)
+			}
 		}
-		if got := len(converter.cache.Keys()); got > limit {
-			t.Errorf("Conversion cache grew to %d entries", got)
+		if want, got := limit, converter.cache.Len(); want != got {
+			t.Errorf("Expected %d entries in the cache, got %d", want, got)
 		}
 	}
 }
 
+func TestConverter_CacheSizeIsAccountedInBytes(t *testing.T) {
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: maxCachedCodeLength * instructionSize,
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	res := converter.Convert(code, &tosca.Hash{1})
+	if want, got := cap(res)*instructionSize, converter.cache.Size(); want != got {
+		t.Errorf("Expected cache size of %d bytes, got %d", want, got)
+	}
+}
+
 func TestConverter_ExceedinglyLongCodesAreNotCached(t *testing.T) {
-	converter, err := NewConverter(ConversionConfig{})
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: 10 * maxCachedCodeLength * instructionSize,
+	})
 	if err != nil {
 		t.Fatalf("failed to create converter: %v", err)
 	}
-	if want, got := 0, len(converter.cache.Keys()); want != got {
+	if want, got := 0, converter.cache.Len(); want != got {
 		t.Errorf("Expected %d entries in the cache, got %d", want, got)
 	}
 	converter.Convert([]byte{0}, &tosca.Hash{0})
-	if want, got := 1, len(converter.cache.Keys()); want != got {
+	if want, got := 1, converter.cache.Len(); want != got {
 		t.Errorf("Expected %d entries in the cache, got %d", want, got)
 	}
 	// Codes with an excessive length should not be cached.
 	converter.Convert(make([]byte, maxCachedCodeLength+1), &tosca.Hash{1})
-	if want, got := 1, len(converter.cache.Keys()); want != got {
+	if want, got := 1, converter.cache.Len(); want != got {
 		t.Errorf("Expected %d entries in the cache, got %d", want, got)
 	}
 }
 
+func TestConverter_ExceedinglyLongCodesAreLogged(t *testing.T) {
+	var buffer bytes.Buffer
+	converter, err := NewConverter(ConversionConfig{
+		CacheSize: 10 * maxCachedCodeLength * instructionSize,
+		Logger:    slog.New(slog.NewTextHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err != nil {
+		t.Fatalf("failed to create converter: %v", err)
+	}
+	converter.Convert([]byte{0}, &tosca.Hash{0})
+	if buffer.Len() != 0 {
+		t.Errorf("cached codes should not be logged, got %q", buffer.String())
+	}
+	converter.Convert(make([]byte, maxCachedCodeLength+1), &tosca.Hash{1})
+	if want, got := "code too long to be cached", buffer.String(); !strings.Contains(got, want) {
+		t.Errorf("log should contain %q, got %q", want, got)
+	}
+}
+
 func TestConverter_ResultsAreCached(t *testing.T) {
 	converter, err := NewConverter(ConversionConfig{})
 	if err != nil {
@@ -128,11 +335,30 @@ func TestConverter_ResultsAreCached(t *testing.T) {
 	code := []byte{byte(vm.STOP)}
 	hash := tosca.Hash{byte(1)}
 	want := converter.Convert(code, &hash)
-	if got, found := converter.cache.Get(hash); !found || !slices.Equal(want, got) {
+	if got, found := converter.cache.Get(hash, converter.cacheKind()); !found || !slices.Equal(want, got.(Code)) {
 		t.Errorf("converted code not added to cache")
 	}
 }
 
+func TestConverter_ConversionsWithAndWithoutSuperInstructionsAreCachedIndependently(t *testing.T) {
+	cache := tosca.NewCodeCache(maxCachedCodeLength * instructionSize)
+	plain := &Converter{config: ConversionConfig{}, cache: cache}
+	super := &Converter{config: ConversionConfig{WithSuperInstructions: true}, cache: cache}
+
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.PUSH1), 2, byte(vm.ADD)}
+	hash := tosca.Hash{1}
+	want := plain.Convert(code, &hash)
+	if got := super.Convert(code, &hash); slices.Equal(want, got) {
+		t.Errorf("conversion with super instructions should differ, got %v", got)
+	}
+	if got := plain.Convert(code, &hash); &want[0] != &got[0] {
+		t.Errorf("cached conversion result not returned")
+	}
+	if want, got := 2, cache.Len(); want != got {
+		t.Errorf("Expected %d entries in the cache, got %d", want, got)
+	}
+}
+
 func TestConverter_ConverterIsThreadSafe(t *testing.T) {
 	// This test is to be run with --race to detect concurrency issues.
 	const (
@@ -11,12 +11,13 @@
 package lfvm
 
 import (
+	"unsafe"
+
 	"github.com/Fantom-foundation/Tosca/go/ct"
 	"github.com/Fantom-foundation/Tosca/go/ct/common"
 	"github.com/Fantom-foundation/Tosca/go/ct/st"
 	"github.com/Fantom-foundation/Tosca/go/ct/utils"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
-	lru "github.com/hashicorp/golang-lru/v2"
 )
 
 func NewConformanceTestingTarget() ct.Evm {
@@ -24,20 +25,20 @@ func NewConformanceTestingTarget() ct.Evm {
 	// Can only fail for invalid configuration. Configuration is hardcoded.
 	sanctionedVm, _ := NewInterpreter(Config{})
 
-	// can only fail for non-positive size
-	cache, _ := lru.New[[32]byte, *pcMap](4096)
-
 	return &ctAdapter{
 		vm:         sanctionedVm,
-		pcMapCache: cache,
+		pcMapCache: tosca.NewCodeCache(pcMapCacheCapacity),
 	}
 }
 
 type ctAdapter struct {
 	vm         *lfvm
-	pcMapCache *lru.Cache[[32]byte, *pcMap]
+	pcMapCache *tosca.CodeCache
 }
 
+// pcMapCacheCapacity is the capacity of the cache for pc maps in bytes.
+const pcMapCacheCapacity = 1 << 28 // = 256 MiB
+
 func (a *ctAdapter) StepN(state *st.State, numSteps int) (*st.State, error) {
 	params := utils.ToVmParameters(state)
 	if params.Revision > newestSupportedRevision {
@@ -60,16 +61,17 @@ func (a *ctAdapter) StepN(state *st.State, numSteps int) (*st.State, error) {
 
 	// Set up execution context.
 	var ctxt = &context{
-		pc:           int32(pcMap.evmToLfvm[state.Pc]),
-		params:       params,
-		context:      params.Context,
-		gas:          params.Gas,
-		refund:       tosca.Gas(state.GasRefund),
-		stack:        convertCtStackToLfvmStack(state.Stack),
-		memory:       memory,
-		code:         converted,
-		returnData:   state.LastCallReturnData.ToBytes(),
-		withShaCache: a.vm.config.WithShaCache,
+		pc:          int32(pcMap.evmToLfvm[state.Pc]),
+		params:      params,
+		context:     params.Context,
+		gas:         params.Gas,
+		refund:      tosca.Gas(state.GasRefund),
+		stack:       convertCtStackToLfvmStack(state.Stack),
+		memory:      memory,
+		code:        converted,
+		returnData:  state.LastCallReturnData.ToBytes(),
+		shaCache:    a.vm.config.getShaCache(),
+		gasSchedule: a.vm.config.getGasSchedule(),
 	}
 
 	defer func() {
@@ -82,6 +84,10 @@ func (a *ctAdapter) StepN(state *st.State, numSteps int) (*st.State, error) {
 		status = execute(ctxt, true)
 	}
 
+	if status == statusSelfDestructed {
+		tosca.FinalizeSelfDestruction(ctxt.context, params.Recipient, ctxt.selfDestruction)
+	}
+
 	// Update the resulting state.
 	state.Status = convertLfvmStatusToCtStatus(status)
 
@@ -103,13 +109,12 @@ func (a *ctAdapter) StepN(state *st.State, numSteps int) (*st.State, error) {
 
 func (a *ctAdapter) getPcMap(code *st.Code) *pcMap {
 	hash := code.Hash()
-	pcMap, found := a.pcMapCache.Get(hash)
-	if found {
-		return pcMap
+	if cached, found := a.pcMapCache.Get(hash, "lfvm-pc-map"); found {
+		return cached.(*pcMap)
 	}
 	byteCode := code.Copy()
-	pcMap = genPcMap(byteCode)
-	a.pcMapCache.Add(hash, pcMap)
+	pcMap := genPcMap(byteCode)
+	a.pcMapCache.Add(hash, "lfvm-pc-map", pcMap, pcMap.size())
 	return pcMap
 }
 
@@ -119,6 +124,11 @@ type pcMap struct {
 	lfvmToEvm []uint16
 }
 
+// size returns the number of bytes occupied by the pc map.
+func (m *pcMap) size() int {
+	return int(unsafe.Sizeof(*m)) + 2*(cap(m.evmToLfvm)+cap(m.lfvmToEvm))
+}
+
 // genPcMap creates a bidirectional program counter map for a given code,
 // allowing mapping from a program counter in evm code to lfvm and vice versa.
 func genPcMap(code []byte) *pcMap {
@@ -178,16 +188,16 @@ func convertCtStackToLfvmStack(stack *st.Stack) *stack {
 	result := NewStack()
 	for i := stack.Size() - 1; i >= 0; i-- {
 		val := stack.Get(i).Uint256()
-		result.push(&val)
+		result.Push(&val)
 	}
 	return result
 }
 
 func convertLfvmStackToCtStack(stack *stack, result *st.Stack) *st.Stack {
-	len := stack.len()
+	len := stack.Len()
 	result.Resize(len)
 	for i := 0; i < len; i++ {
-		result.Set(len-i-1, common.NewU256FromUint256(stack.get(i)))
+		result.Set(len-i-1, common.NewU256FromUint256(stack.Get(i)))
 	}
 	return result
 }
@@ -148,6 +148,32 @@ func TestCtAdapter_FillsReturnDataOnResultingState(t *testing.T) {
 	}
 }
 
+func TestCtAdapter_AppliesSelfDestructionToResultingState(t *testing.T) {
+	beneficiary := tosca.Address{19: 0xbe}
+	s := st.NewState(st.NewCode([]byte{
+		byte(vm.PUSH1), beneficiary[19],
+		byte(vm.SELFDESTRUCT),
+	}))
+	s.Revision = tosca.R13_Cancun
+	s.Gas = 100_000
+	s.CallContext.AccountAddress = tosca.Address{1}
+	c := NewConformanceTestingTarget()
+	s2, err := c.StepN(s, 2)
+	if err != nil {
+		t.Fatalf("unexpected conversion error: %v", err)
+	}
+	if want, got := st.Stopped, s2.Status; want != got {
+		t.Fatalf("unexpected status, wanted %v, got %v", want, got)
+	}
+	if !s2.HasSelfDestructed {
+		t.Errorf("self-destruction was not applied")
+	}
+	want := []st.SelfDestructEntry{st.NewSelfDestructEntry(tosca.Address{1}, beneficiary)}
+	if got := s2.SelfDestructedJournal; !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected self-destruct journal, wanted %v, got %v", want, got)
+	}
+}
+
 ////////////////////////////////////////////////////////////
 // ct -> lfvm
 
@@ -423,7 +449,7 @@ func TestConvertToLfvm_Stack(t *testing.T) {
 		stack := NewStack()
 		for i := 0; i < len(values); i++ {
 			value := values[i].Uint256()
-			stack.push(&value)
+			stack.Push(&value)
 		}
 		return stack
 	}
@@ -449,12 +475,12 @@ func TestConvertToLfvm_Stack(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			stack := convertCtStackToLfvmStack(test.ctStack)
-			if want, got := test.lfvmStack.len(), stack.len(); want != got {
+			if want, got := test.lfvmStack.Len(), stack.Len(); want != got {
 				t.Fatalf("unexpected stack size, wanted %v, got %v", want, got)
 			}
-			for i := 0; i < stack.len(); i++ {
-				want := *test.lfvmStack.get(i)
-				got := *stack.get(i)
+			for i := 0; i < stack.Len(); i++ {
+				want := *test.lfvmStack.Get(i)
+				got := *stack.Get(i)
 				if want != got {
 					t.Errorf("unexpected stack value, wanted %v, got %v", want, got)
 				}
@@ -512,7 +538,7 @@ func TestConvertToCt_Stack(t *testing.T) {
 		stack := NewStack()
 		for i := 0; i < len(values); i++ {
 			value := values[i].Uint256()
-			stack.push(&value)
+			stack.Push(&value)
 		}
 		return stack
 	}
@@ -555,7 +581,7 @@ func TestConvertToCt_Stack(t *testing.T) {
 func BenchmarkLfvmStackToCtStack(b *testing.B) {
 	stack := NewStack()
 	for i := 0; i < MAX_STACK_SIZE/2; i++ {
-		stack.pushUndefined().SetUint64(uint64(i))
+		stack.PushUndefined().SetUint64(uint64(i))
 	}
 	ctStack := st.NewStack()
 	for i := 0; i < b.N; i++ {
@@ -21,6 +21,7 @@ const (
 	errStaticContextViolation = tosca.ConstError("static context violation")
 	errStackLimitsViolation   = tosca.ConstError("stack limits violation")
 	errInitCodeTooLarge       = tosca.ConstError("init code larger than allowed")
+	errCodeTooLarge           = tosca.ConstError("code exceeds program counter range")
 	errMaxMemoryExpansionSize = tosca.ConstError("max memory expansion size exceeded")
 	errStackUnderflow         = tosca.ConstError("stack underflow")
 	errStackOverflow          = tosca.ConstError("stack overflow")
@@ -19,15 +19,15 @@ const (
 	CallValueTransferGas tosca.Gas = 9000  // Paid for CALL when the value transfer is non-zero.
 	CallStipend          tosca.Gas = 2300  // Free gas given at beginning of call.
 
-	ColdSloadCostEIP2929         tosca.Gas = 2100 // Cost of cold SLOAD after EIP 2929
-	ColdAccountAccessCostEIP2929 tosca.Gas = 2600 // Cost of cold account access after EIP 2929
+	ColdSloadCostEIP2929         = tosca.ColdStorageAccessCost // Cost of cold SLOAD after EIP 2929
+	ColdAccountAccessCostEIP2929 = tosca.ColdAccountAccessCost // Cost of cold account access after EIP 2929
 
 	SloadGasEIP2200                   tosca.Gas = 800   // Cost of SLOAD after EIP 2200 (part of Istanbul)
 	SstoreClearsScheduleRefundEIP2200 tosca.Gas = 15000 // Once per SSTORE operation for clearing an originally existing storage slot
 
-	SstoreResetGasEIP2200      tosca.Gas = 5000  // Once per SSTORE operation from clean non-zero to something else
-	SstoreSetGasEIP2200        tosca.Gas = 20000 // Once per SSTORE operation from clean zero to non-zero
-	WarmStorageReadCostEIP2929 tosca.Gas = 100   // Cost of reading warm storage after EIP 2929
+	SstoreResetGasEIP2200      tosca.Gas = 5000                 // Once per SSTORE operation from clean non-zero to something else
+	SstoreSetGasEIP2200        tosca.Gas = 20000                // Once per SSTORE operation from clean zero to non-zero
+	WarmStorageReadCostEIP2929           = tosca.WarmAccessCost // Cost of reading warm storage after EIP 2929
 
 	UNKNOWN_GAS_PRICE = 999999
 )
@@ -234,63 +234,3 @@ func getStaticGasPriceInternal(op OpCode) tosca.Gas {
 
 	return UNKNOWN_GAS_PRICE
 }
-
-func getDynamicCostsForSstore(
-	revision tosca.Revision,
-	storageStatus tosca.StorageStatus,
-) tosca.Gas {
-	switch storageStatus {
-	case tosca.StorageAdded:
-		return 20000
-	case tosca.StorageModified,
-		tosca.StorageDeleted:
-		if revision >= tosca.R09_Berlin {
-			return 2900
-		} else {
-			return 5000
-		}
-	default:
-		if revision >= tosca.R09_Berlin {
-			return 100
-		}
-		return 800
-	}
-}
-
-func getRefundForSstore(
-	revision tosca.Revision,
-	storageStatus tosca.StorageStatus,
-) tosca.Gas {
-	switch storageStatus {
-	case tosca.StorageDeleted,
-		tosca.StorageModifiedDeleted:
-		if revision >= tosca.R10_London {
-			return 4800
-		}
-		return 15000
-	case tosca.StorageDeletedAdded:
-		if revision >= tosca.R10_London {
-			return -4800
-		}
-		return -15000
-	case tosca.StorageDeletedRestored:
-		if revision >= tosca.R10_London {
-			return -4800 + 5000 - 2100 - 100
-		} else if revision >= tosca.R09_Berlin {
-			return -15000 + 5000 - 2100 - 100
-		}
-		return -15000 + 4200
-	case tosca.StorageAddedDeleted:
-		if revision >= tosca.R09_Berlin {
-			return 19900
-		}
-		return 19200
-	case tosca.StorageModifiedRestored:
-		if revision >= tosca.R09_Berlin {
-			return 5000 - 2100 - 100
-		}
-		return 4200
-	default:
-		return 0
-	}
-}
@@ -14,11 +14,29 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
+func TestGas_StaticGasPricesMatchOpCodeInfo(t *testing.T) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		prices := getStaticGasPrices(revision)
+		for i := 0; i < 256; i++ {
+			info, found := vm.OpCode(i).Info()
+			if !found {
+				continue
+			}
+			op := OpCode(i)
+			want := tosca.Gas(info.StaticGas(vm.Revision(revision)))
+			if got := prices.get(op); want != got {
+				t.Errorf("unexpected static gas of %v in %v, wanted %d, got %d", op, revision, want, got)
+			}
+		}
+	}
+}
+
 // --- SStore ---
 
-func TestGas_getDynamicCostsForSstore_exhaustive(t *testing.T) {
+func TestGas_SstoreCostsOfDefaultSchedule_exhaustive(t *testing.T) {
 	// This test exhaustively checks the computation of the dynamic gas costs for
 	// the SSTORE instruction by enumerating every possible input combination
 	// and comparing the result with the specification found on
@@ -69,7 +87,7 @@ func TestGas_getDynamicCostsForSstore_exhaustive(t *testing.T) {
 		}
 		for storageStatus, example := range getStorageStateExamples() {
 			want := spec(example)
-			got := getDynamicCostsForSstore(revision, storageStatus)
+			got := tosca.EthereumGasSchedule{}.SstoreCost(revision, storageStatus)
 			if got != want {
 				t.Errorf(
 					"unexpected result for (%v,%v), wanted %d, got %d",
@@ -83,7 +101,7 @@ func TestGas_getDynamicCostsForSstore_exhaustive(t *testing.T) {
 	}
 }
 
-func TestGas_getRefundForSstore_exhaustive(t *testing.T) {
+func TestGas_SstoreRefundOfDefaultSchedule_exhaustive(t *testing.T) {
 	// This test exhaustively checks the computation of the refunds granted by
 	// the SSTORE instruction by enumerating every possible input combination
 	// and comparing the result with the specification found on
@@ -145,7 +163,7 @@ func TestGas_getRefundForSstore_exhaustive(t *testing.T) {
 		}
 		for storageStatus, example := range getStorageStateExamples() {
 			want := spec(example)
-			got := getRefundForSstore(revision, storageStatus)
+			got := tosca.EthereumGasSchedule{}.SstoreRefund(revision, storageStatus)
 			if got != want {
 				t.Errorf(
 					"unexpected result for (%v,%v), wanted %d, got %d",
@@ -0,0 +1,293 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"sync"
+	"unsafe"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// compactHashCache is an LRU governed cache for hashes of 64-byte inputs
+// operating on a memory budget instead of a fixed number of entries.
+//
+// The majority of 64-byte inputs hashed by contracts are the concatenation
+// of a key and a slot index, used for locating entries of Solidity mappings.
+// Both halves are heavily reused: the number of mappings in a contract is
+// small, and the same key (e.g. an account address) is typically used for
+// looking up entries in several mappings. To exploit this, the two 32-byte
+// halves of each input are interned in a reference-counted word table and
+// cache entries only refer to them by their compact IDs. Eviction is
+// size-aware: the costs of a new entry includes the costs of the words it
+// introduces to the word table, and entries are evicted in LRU order until
+// the new entry fits into the budget. Overall, this allows the cache to hold
+// more entries than an uncompacted cache using the same amount of memory.
+//
+// Interning is applied to inputs, not to resulting hashes: distinct inputs
+// have distinct hashes, barring collisions of Keccak-256, and identical inputs
+// already share a single entry. An intern table for hashes would thus never
+// be shared by two entries and only add to the memory usage.
+//
+// The cache is thread-safe.
+type compactHashCache struct {
+	hash       func([64]byte) tosca.Hash             // Hash function for the keys.
+	index      map[compactKey]*compactHashCacheEntry // Index of cache entries by key.
+	words      wordTable                             // Interned halves of keys.
+	head, tail *compactHashCacheEntry                // LRU order.
+	free       *compactHashCacheEntry                // Recycled entry, if available.
+	budget     int                                   // Memory budget in bytes.
+	used       int                                   // Memory in use in bytes.
+	lock       sync.Mutex                            // Lock for the cache.
+}
+
+// compactKey is the representation of a 64-byte input in a compactHashCache,
+// listing the IDs of the interned lower and upper 32-byte halves.
+type compactKey [2]wordId
+
+// compactHashCacheEntry is an entry of a compactHashCache.
+type compactHashCacheEntry struct {
+	// key is the compact input value cache entries are indexed by.
+	key compactKey
+	// hash is the cached (Sha3) hash of the key.
+	hash tosca.Hash
+	// pred/succ pointers are used for a double linked list for the LRU order.
+	pred, succ *compactHashCacheEntry
+}
+
+const (
+	// compactEntrySize is the estimated memory usage of an entry in a
+	// compactHashCache, including its index entry.
+	compactEntrySize = int(unsafe.Sizeof(compactHashCacheEntry{}) +
+		unsafe.Sizeof(compactKey{}) + unsafe.Sizeof(&compactHashCacheEntry{}))
+
+	// uncompactedEntrySize is the estimated memory usage of an entry in a
+	// hashCache for 64-byte inputs, including its index entry. It is used
+	// for deriving memory budgets from entry capacities.
+	uncompactedEntrySize = int(unsafe.Sizeof(hashCacheEntry[[64]byte]{}) +
+		unsafe.Sizeof([64]byte{}) + unsafe.Sizeof(&hashCacheEntry[[64]byte]{}))
+)
+
+// newCompactHashCache creates a compactHashCache with the given memory budget
+// in bytes. To be able to hold at least a few entries, the budget is raised to
+// the costs of two entries with unique words if it is less than that.
+func newCompactHashCache(budget int, hash func([64]byte) tosca.Hash) *compactHashCache {
+	if minBudget := 2 * (compactEntrySize + 2*wordSize); budget < minBudget {
+		budget = minBudget
+	}
+	return &compactHashCache{
+		hash:   hash,
+		index:  map[compactKey]*compactHashCacheEntry{},
+		words:  newWordTable(),
+		budget: budget,
+	}
+}
+
+func (h *compactHashCache) getHash(key [64]byte) tosca.Hash {
+	hash, _ := h.lookup(key)
+	return hash
+}
+
+// lookup fetches the hash of the given key from the cache or computes it if
+// it is not present. It also reports whether the hash was found in the cache.
+func (h *compactHashCache) lookup(key [64]byte) (tosca.Hash, bool) {
+	lower, upper := [32]byte(key[:32]), [32]byte(key[32:])
+
+	h.lock.Lock()
+	if entry, found := h.find(lower, upper); found {
+		h.moveToFront(entry)
+		h.lock.Unlock()
+		return entry.hash, true
+	}
+
+	// Compute the hash without holding the lock.
+	h.lock.Unlock()
+	hash := h.hash(key)
+	h.lock.Lock()
+
+	// We need to check that the key has not be added concurrently.
+	if _, found := h.find(lower, upper); found {
+		h.lock.Unlock()
+		return hash, false
+	}
+
+	// The key is still not present, so we add it. The words of the key are
+	// acquired before making space for the new entry to avoid them from
+	// being removed from the word table during the eviction.
+	var newWords int
+	var compact compactKey
+	for i, word := range [2][32]byte{lower, upper} {
+		id, added := h.words.acquire(word)
+		compact[i] = id
+		if added {
+			newWords++
+		}
+	}
+	h.used += compactEntrySize + newWords*wordSize
+	for h.used > h.budget && h.tail != nil {
+		h.evict()
+	}
+
+	entry := h.free
+	if entry != nil {
+		h.free = nil
+	} else {
+		entry = &compactHashCacheEntry{}
+	}
+	entry.key = compact
+	entry.hash = hash
+	h.addToFront(entry)
+	h.index[compact] = entry
+	h.lock.Unlock()
+	return hash, false
+}
+
+// memoryUsage returns the memory used by the entries of the cache in bytes,
+// including the words interned for them.
+func (h *compactHashCache) memoryUsage() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.used
+}
+
+// find locates the entry for the given input halves. The lock of the cache
+// must be held by the caller.
+func (h *compactHashCache) find(lower, upper [32]byte) (*compactHashCacheEntry, bool) {
+	lowerId, found := h.words.find(lower)
+	if !found {
+		return nil, false
+	}
+	upperId, found := h.words.find(upper)
+	if !found {
+		return nil, false
+	}
+	entry, found := h.index[compactKey{lowerId, upperId}]
+	return entry, found
+}
+
+// evict removes the least recently used entry from the cache and releases
+// the words referenced by it. The lock of the cache must be held by the
+// caller and the cache must not be empty.
+func (h *compactHashCache) evict() {
+	entry := h.tail
+	h.tail = entry.pred
+	if h.tail != nil {
+		h.tail.succ = nil
+	} else {
+		h.head = nil
+	}
+	delete(h.index, entry.key)
+	h.used -= compactEntrySize
+	for _, id := range entry.key {
+		if h.words.release(id) {
+			h.used -= wordSize
+		}
+	}
+	entry.pred = nil
+	entry.succ = nil
+	h.free = entry
+}
+
+func (h *compactHashCache) moveToFront(entry *compactHashCacheEntry) {
+	if entry == h.head {
+		return
+	}
+	// Remove from current place.
+	entry.pred.succ = entry.succ
+	if entry.succ != nil {
+		entry.succ.pred = entry.pred
+	} else {
+		h.tail = entry.pred
+	}
+	h.addToFront(entry)
+}
+
+func (h *compactHashCache) addToFront(entry *compactHashCacheEntry) {
+	entry.pred = nil
+	entry.succ = h.head
+	if h.head != nil {
+		h.head.pred = entry
+	} else {
+		h.tail = entry
+	}
+	h.head = entry
+}
+
+// wordId is the compact identifier of a word interned in a wordTable.
+type wordId uint32
+
+// wordTable is a reference-counted intern table for 32-byte words. It is not
+// thread-safe; synchronization has to be provided by the user.
+type wordTable struct {
+	ids   map[[32]byte]wordId // Index of interned words.
+	words []internedWord      // Interned words by their IDs.
+	free  []wordId            // IDs of unused slots in words.
+}
+
+// internedWord is an entry of a wordTable.
+type internedWord struct {
+	word       [32]byte
+	references uint32
+}
+
+// wordSize is the estimated memory usage of a word in a wordTable, including
+// its index entry.
+const wordSize = int(unsafe.Sizeof(internedWord{}) + unsafe.Sizeof([32]byte{}) + unsafe.Sizeof(wordId(0)))
+
+func newWordTable() wordTable {
+	return wordTable{ids: map[[32]byte]wordId{}}
+}
+
+// find returns the ID of the given word if it is present in the table.
+func (t *wordTable) find(word [32]byte) (wordId, bool) {
+	id, found := t.ids[word]
+	return id, found
+}
+
+// acquire returns the ID of the given word and increments its reference count.
+// If the word was not present before, it is added to the table, which is
+// signaled by the second result.
+func (t *wordTable) acquire(word [32]byte) (wordId, bool) {
+	if id, found := t.ids[word]; found {
+		t.words[id].references++
+		return id, false
+	}
+	var id wordId
+	if len(t.free) > 0 {
+		id = t.free[len(t.free)-1]
+		t.free = t.free[:len(t.free)-1]
+	} else {
+		id = wordId(len(t.words))
+		t.words = append(t.words, internedWord{})
+	}
+	t.words[id] = internedWord{word: word, references: 1}
+	t.ids[word] = id
+	return id, true
+}
+
+// release decrements the reference count of the word with the given ID. If
+// no references are left, the word is removed from the table, which is
+// signaled by the result.
+func (t *wordTable) release(id wordId) bool {
+	entry := &t.words[id]
+	entry.references--
+	if entry.references > 0 {
+		return false
+	}
+	delete(t.ids, entry.word)
+	t.free = append(t.free, id)
+	return true
+}
+
+// len returns the number of words in the table.
+func (t *wordTable) len() int {
+	return len(t.ids)
+}
@@ -0,0 +1,410 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestCompactHashCache_ProducesCorrectHashes(t *testing.T) {
+	cache := newCompactHashCache(0, func(key [64]byte) tosca.Hash {
+		return Keccak256(key[:])
+	})
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 100; i++ {
+		var key [64]byte
+		r.Read(key[:])
+		if want, got := Keccak256(key[:]), cache.getHash(key); want != got {
+			t.Errorf("expected hash to be %x, but got %x", want, got)
+		}
+		checkCompactHashCacheConsistency(t, cache)
+	}
+}
+
+func TestCompactHashCache_HashesAreCached(t *testing.T) {
+	calls := 0
+	cache := newCompactHashCache(1<<20, func(key [64]byte) tosca.Hash {
+		calls++
+		return tosca.Hash{key[0], key[32]}
+	})
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			key := getCompactTestKey(i, j)
+			if want, got := (tosca.Hash{key[0], key[32]}), cache.getHash(key); want != got {
+				t.Errorf("unexpected hash, wanted %x, got %x", want, got)
+			}
+		}
+	}
+	if want, got := 100, calls; want != got {
+		t.Errorf("unexpected number of hash computations, wanted %d, got %d", want, got)
+	}
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			cache.getHash(getCompactTestKey(i, j))
+		}
+	}
+	if want, got := 100, calls; want != got {
+		t.Errorf("cached hashes were recomputed, wanted %d computations, got %d", want, got)
+	}
+}
+
+func TestCompactHashCache_HalvesOfKeysAreShared(t *testing.T) {
+	cache := newCompactHashCache(1<<20, func([64]byte) tosca.Hash {
+		return tosca.Hash{}
+	})
+
+	cache.getHash(getCompactTestKey(1, 2))
+	cache.getHash(getCompactTestKey(1, 3))
+	cache.getHash(getCompactTestKey(4, 2))
+	cache.getHash(getCompactTestKey(5, 5))
+
+	if want, got := 4, len(cache.index); want != got {
+		t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+	}
+	// The slot index 5 is shared as well.
+	if want, got := 5, cache.words.len(); want != got {
+		t.Errorf("unexpected number of interned words, wanted %d, got %d", want, got)
+	}
+	checkCompactHashCacheConsistency(t, cache)
+}
+
+func TestCompactHashCache_RespectsBudget(t *testing.T) {
+	for _, entries := range []int{2, 10, 42, 123} {
+		t.Run(fmt.Sprintf("entries=%d", entries), func(t *testing.T) {
+			budget := entries * (compactEntrySize + 2*wordSize)
+			cache := newCompactHashCache(budget, func([64]byte) tosca.Hash {
+				return tosca.Hash{}
+			})
+			for i := 0; i < 4*entries; i++ {
+				cache.getHash(getCompactTestKey(i, -i-1))
+				if cache.used > cache.budget {
+					t.Fatalf("memory budget exceeded, budget %d, used %d", cache.budget, cache.used)
+				}
+				checkCompactHashCacheConsistency(t, cache)
+			}
+			if want, got := entries, len(cache.index); want != got {
+				t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestCompactHashCache_SharedWordsAllowForMoreEntries(t *testing.T) {
+	const entries = 10
+	budget := entries * (compactEntrySize + 2*wordSize)
+	cache := newCompactHashCache(budget, func([64]byte) tosca.Hash {
+		return tosca.Hash{}
+	})
+
+	// All keys use the same slot index, so only one new word per entry.
+	for i := 0; i < 4*entries; i++ {
+		cache.getHash(getCompactTestKey(i, 0))
+	}
+	if got := len(cache.index); got <= entries {
+		t.Errorf("expected more than %d entries in the cache, got %d", entries, got)
+	}
+	checkCompactHashCacheConsistency(t, cache)
+}
+
+func TestCompactHashCache_BudgetIsIncreasedToMinimum(t *testing.T) {
+	cache := newCompactHashCache(0, func([64]byte) tosca.Hash {
+		return tosca.Hash{}
+	})
+	for i := 0; i < 10; i++ {
+		cache.getHash(getCompactTestKey(i, -i-1))
+	}
+	if want, got := 2, len(cache.index); want != got {
+		t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+	}
+}
+
+func TestCompactHashCache_UsesLruReplacementOrder(t *testing.T) {
+	sequence := []struct {
+		touchedKey int
+		lruOrder   []int
+	}{
+		{1, []int{1}},
+		{2, []int{2, 1}},
+		{3, []int{3, 2, 1}},
+		{4, []int{4, 3, 2}},
+		{2, []int{2, 4, 3}},
+		{4, []int{4, 2, 3}},
+		{4, []int{4, 2, 3}},
+		{5, []int{5, 4, 2}},
+	}
+
+	budget := 3 * (compactEntrySize + 2*wordSize)
+	cache := newCompactHashCache(budget, func([64]byte) tosca.Hash {
+		return tosca.Hash{}
+	})
+
+	for i, step := range sequence {
+		cache.getHash(getCompactTestKey(step.touchedKey, -step.touchedKey))
+		got := checkCompactHashCacheConsistency(t, cache)
+		want := []compactKey{}
+		for _, key := range step.lruOrder {
+			input := getCompactTestKey(key, -key)
+			lower, _ := cache.words.find([32]byte(input[:32]))
+			upper, _ := cache.words.find([32]byte(input[32:]))
+			want = append(want, compactKey{lower, upper})
+		}
+		if !slices.Equal(want, got) {
+			t.Errorf("after step %d expected order to be %v, but got %v", i, want, got)
+		}
+	}
+}
+
+func TestCompactHashCache_AccessesAreThreadSafe(t *testing.T) {
+	// This test is designed to detect race conditions in cases in combination
+	// with Go's data race detection. It should be run with the -race flag.
+	cache := newCompactHashCache(0, func([64]byte) tosca.Hash {
+		return tosca.Hash{}
+	})
+
+	const (
+		threads  = 10
+		accesses = 1000
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(threads)
+	for i := 0; i < threads; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < accesses; j++ {
+				cache.getHash(getCompactTestKey(j%10, j%3))
+			}
+		}()
+	}
+	wg.Wait()
+
+	checkCompactHashCacheConsistency(t, cache)
+}
+
+func TestWordTable_ReferencesAreCounted(t *testing.T) {
+	table := newWordTable()
+
+	id1, added := table.acquire([32]byte{1})
+	if !added {
+		t.Errorf("new word was not reported as added")
+	}
+	id2, added := table.acquire([32]byte{1})
+	if added || id1 != id2 {
+		t.Errorf("existing word was not reused, got ids %d and %d", id1, id2)
+	}
+	if table.release(id1) {
+		t.Errorf("word with remaining references was removed")
+	}
+	if !table.release(id1) {
+		t.Errorf("word without remaining references was not removed")
+	}
+	if _, found := table.find([32]byte{1}); found {
+		t.Errorf("released word is still present")
+	}
+
+	// Released IDs are reused.
+	id3, _ := table.acquire([32]byte{2})
+	if id1 != id3 {
+		t.Errorf("released id was not reused, wanted %d, got %d", id1, id3)
+	}
+}
+
+// getCompactTestKey produces a 64-byte key with the given values encoded in
+// its lower and upper half.
+func getCompactTestKey(lower, upper int) [64]byte {
+	var key [64]byte
+	binary.BigEndian.PutUint64(key[24:32], uint64(lower))
+	binary.BigEndian.PutUint64(key[56:64], uint64(upper))
+	return key
+}
+
+// checkCompactHashCacheConsistency checks the internal invariants of the given
+// cache and returns the keys of the cache in LRU order.
+func checkCompactHashCacheConsistency(t *testing.T, h *compactHashCache) []compactKey {
+	t.Helper()
+
+	var forward []compactKey
+	for e := h.head; e != nil; e = e.succ {
+		forward = append(forward, e.key)
+	}
+	var backward []compactKey
+	for e := h.tail; e != nil; e = e.pred {
+		backward = append(backward, e.key)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(forward, backward) {
+		t.Errorf("expected forward and backward order to be identical but got %v and %v", forward, backward)
+	}
+
+	if want, got := len(forward), len(h.index); want != got {
+		t.Errorf("expected index to have %d entries, but got %d", want, got)
+	}
+
+	references := map[wordId]uint32{}
+	for _, key := range forward {
+		if _, found := h.index[key]; !found {
+			t.Errorf("expected key %v to be in the index, but it is not", key)
+		}
+		for _, id := range key {
+			references[id]++
+		}
+	}
+
+	if want, got := len(references), h.words.len(); want != got {
+		t.Errorf("expected word table to have %d entries, but got %d", want, got)
+	}
+	for id, count := range references {
+		if want, got := count, h.words.words[id].references; want != got {
+			t.Errorf("invalid reference count for word %d, wanted %d, got %d", id, want, got)
+		}
+	}
+
+	if want, got := len(forward)*compactEntrySize+len(references)*wordSize, h.used; want != got {
+		t.Errorf("invalid memory usage, wanted %d, got %d", want, got)
+	}
+	return forward
+}
+
+// getMainnetLikeSha3Corpus produces a sequence of 64-byte inputs resembling
+// the inputs hashed by SHA3 instructions on mainnet. Those are dominated by
+// the computation of storage locations of entries in Solidity mappings,
+// hashing a key (mostly addresses) concatenated with the slot index of the
+// mapping. The popularity of accounts and contracts follows a Zipf
+// distribution, while each contract uses a small number of mappings. It is a
+// synthetic stand-in for a recorded corpus, see sha3Corpus.
+func getMainnetLikeSha3Corpus(size int) [][64]byte {
+	const (
+		numAccounts          = 1 << 18
+		numContracts         = 1 << 12
+		maxMappingsPerToken  = 8
+		accountZipfExponent  = 1.2
+		contractZipfExponent = 1.5
+	)
+	r := rand.New(rand.NewSource(42))
+	accounts := rand.NewZipf(r, accountZipfExponent, 1, numAccounts-1)
+	contracts := rand.NewZipf(r, contractZipfExponent, 1, numContracts-1)
+
+	res := make([][64]byte, 0, size)
+	for len(res) < size {
+		contract := contracts.Uint64()
+		account := accounts.Uint64()
+		mapping := r.Intn(maxMappingsPerToken)
+
+		var key [64]byte
+		binary.BigEndian.PutUint64(key[12:20], account)
+		binary.BigEndian.PutUint64(key[56:64], uint64(mapping)+contract%3)
+		res = append(res, key)
+	}
+	return res
+}
+
+// sha3Corpus is the file of a recorded corpus of 64-byte SHA3 inputs used by
+// BenchmarkSha3HashCache_HitRate instead of a synthetic one. Run it using
+//
+//	go test -run none -bench Sha3HashCache_HitRate -sha3-corpus=<file>
+//
+// where the file is the concatenation of the inputs in the order they were
+// hashed, for instance as recorded from the SHA3 instructions of a range of
+// mainnet blocks.
+var sha3Corpus = flag.String("sha3-corpus", "", "file of recorded 64-byte SHA3 inputs used by the hash cache benchmarks")
+
+// loadSha3Corpus loads the corpus of 64-byte inputs from the given file.
+func loadSha3Corpus(path string) ([][64]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || len(data)%64 != 0 {
+		return nil, fmt.Errorf("invalid corpus size %d, must be a non-zero multiple of 64", len(data))
+	}
+	res := make([][64]byte, 0, len(data)/64)
+	for i := 0; i < len(data); i += 64 {
+		res = append(res, [64]byte(data[i:i+64]))
+	}
+	return res, nil
+}
+
+func TestLoadSha3Corpus_SplitsFileIntoInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "corpus")
+	data := make([]byte, 3*64)
+	for i := range data {
+		data[i] = byte(i / 64)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write corpus: %v", err)
+	}
+	corpus, err := loadSha3Corpus(path)
+	if err != nil {
+		t.Fatalf("failed to load corpus: %v", err)
+	}
+	if want, got := 3, len(corpus); want != got {
+		t.Fatalf("unexpected number of inputs, wanted %d, got %d", want, got)
+	}
+	for i, input := range corpus {
+		if want, got := [64]byte(data[i*64:(i+1)*64]), input; want != got {
+			t.Errorf("unexpected input %d, wanted %x, got %x", i, want, got)
+		}
+	}
+
+	if err := os.WriteFile(path, data[:100], 0600); err != nil {
+		t.Fatalf("failed to write corpus: %v", err)
+	}
+	if _, err := loadSha3Corpus(path); err == nil {
+		t.Errorf("expected truncated corpus to be rejected")
+	}
+}
+
+func BenchmarkSha3HashCache_HitRate(b *testing.B) {
+	corpus := getMainnetLikeSha3Corpus(1 << 20)
+	if *sha3Corpus != "" {
+		var err error
+		if corpus, err = loadSha3Corpus(*sha3Corpus); err != nil {
+			b.Fatalf("failed to load corpus: %v", err)
+		}
+	}
+	for _, capacity := range []int{1 << 10, 1 << 14, 1 << 18} {
+		b.Run(fmt.Sprintf("capacity=%d/uncompacted", capacity), func(b *testing.B) {
+			misses := 0
+			cache := newHashCache(capacity, func(key [64]byte) tosca.Hash {
+				misses++
+				return Keccak256(key[:])
+			})
+			for i := 0; i < b.N; i++ {
+				cache.getHash(corpus[i%len(corpus)])
+			}
+			b.ReportMetric(100*float64(b.N-misses)/float64(b.N), "hit-%")
+		})
+		b.Run(fmt.Sprintf("capacity=%d/compacted", capacity), func(b *testing.B) {
+			misses := 0
+			cache := newCompactHashCache(capacity*uncompactedEntrySize, func(key [64]byte) tosca.Hash {
+				misses++
+				return Keccak256(key[:])
+			})
+			for i := 0; i < b.N; i++ {
+				cache.getHash(corpus[i%len(corpus)])
+			}
+			b.ReportMetric(100*float64(b.N-misses)/float64(b.N), "hit-%")
+			b.ReportMetric(float64(len(cache.index)), "entries")
+		})
+	}
+}
@@ -48,6 +48,116 @@ func TestSha3HashCache_hash_ProducesCorrectHashesForInputs(t *testing.T) {
 	}
 }
 
+func TestSha3HashCache_getStatistics_CountsLookups(t *testing.T) {
+	cache := newSha3HashCache(10, 10)
+	for _, input := range [][]byte{
+		make([]byte, 32), make([]byte, 32), {1: 1, 31: 0},
+		make([]byte, 64), make([]byte, 64),
+		make([]byte, 12),
+	} {
+		cache.hash(input)
+	}
+
+	stats := cache.getStatistics()
+	if want, got := (ShaCacheInputStatistics{Hits: 2, Misses: 1, Enabled: true}), stats.Inputs32; want != got {
+		t.Errorf("unexpected statistics for 32-byte inputs, wanted %v, got %v", want, got)
+	}
+	if want, got := (ShaCacheInputStatistics{Hits: 1, Misses: 1, Enabled: true}), stats.Inputs64; want != got {
+		t.Errorf("unexpected statistics for 64-byte inputs, wanted %v, got %v", want, got)
+	}
+	if want, got := uint64(1), stats.Uncached; want != got {
+		t.Errorf("unexpected number of uncached inputs, wanted %d, got %d", want, got)
+	}
+	if stats.MemoryUsage <= 0 {
+		t.Errorf("unexpected memory usage: %d", stats.MemoryUsage)
+	}
+}
+
+func TestSha3HashCache_MemoryUsageGrowsWithEntries(t *testing.T) {
+	cache := newSha3HashCache(100, 100)
+	before := cache.getStatistics().MemoryUsage
+	for i := 0; i < 10; i++ {
+		cache.hash([]byte{31: byte(i) + 1})
+		cache.hash([]byte{63: byte(i) + 1})
+	}
+	if after := cache.getStatistics().MemoryUsage; after <= before {
+		t.Errorf("memory usage did not grow, before %d, after %d", before, after)
+	}
+}
+
+func TestSha3HashCache_CachingIsDisabledForLowHitRates(t *testing.T) {
+	cache := newSha3HashCacheWithConfig(ShaCacheConfig{
+		Capacity32:         10,
+		Capacity64:         10,
+		MinHitRate:         0.5,
+		EvaluationInterval: 10,
+	})
+
+	// Unique inputs never hit the cache, disabling it after one interval.
+	for i := 0; i < 10; i++ {
+		cache.hash([]byte{31: byte(i) + 1})
+	}
+	stats := cache.getStatistics()
+	if stats.Inputs32.Enabled {
+		t.Fatalf("caching of 32-byte inputs should be disabled")
+	}
+	if !stats.Inputs64.Enabled {
+		t.Errorf("caching of 64-byte inputs should not be affected")
+	}
+
+	// While disabled, only a fraction of the inputs are looked up.
+	for i := 0; i < shaCacheProbeInterval; i++ {
+		input := []byte{31: byte(i) + 1}
+		if want, got := Keccak256(input), cache.hash(input); want != got {
+			t.Errorf("unexpected hash, wanted %x, got %x", want, got)
+		}
+	}
+	stats = cache.getStatistics()
+	if want, got := uint64(shaCacheProbeInterval-1), stats.Inputs32.Bypassed; want != got {
+		t.Errorf("unexpected number of bypassed inputs, wanted %d, got %d", want, got)
+	}
+
+	// Probes with a high hit rate re-enable caching.
+	input := []byte{31: 1}
+	for i := 0; i < 10*shaCacheProbeInterval; i++ {
+		cache.hash(input)
+	}
+	if !cache.getStatistics().Inputs32.Enabled {
+		t.Errorf("caching of 32-byte inputs should be re-enabled")
+	}
+}
+
+func TestSha3HashCache_NegativeMinHitRateDisablesPolicy(t *testing.T) {
+	cache := newSha3HashCacheWithConfig(ShaCacheConfig{
+		MinHitRate:         -1,
+		EvaluationInterval: 10,
+	})
+	for i := 0; i < 100; i++ {
+		cache.hash([]byte{31: byte(i) + 1})
+	}
+	stats := cache.getStatistics().Inputs32
+	if !stats.Enabled || stats.Bypassed != 0 || stats.Misses != 100 {
+		t.Errorf("unexpected statistics: %v", stats)
+	}
+}
+
+func TestShaCacheInputStatistics_HitRate(t *testing.T) {
+	tests := []struct {
+		stats ShaCacheInputStatistics
+		want  float64
+	}{
+		{ShaCacheInputStatistics{}, 0},
+		{ShaCacheInputStatistics{Hits: 1}, 1},
+		{ShaCacheInputStatistics{Misses: 1}, 0},
+		{ShaCacheInputStatistics{Hits: 3, Misses: 1, Bypassed: 10}, 0.75},
+	}
+	for _, test := range tests {
+		if got := test.stats.HitRate(); test.want != got {
+			t.Errorf("unexpected hit rate for %v, wanted %v, got %v", test.stats, test.want, got)
+		}
+	}
+}
+
 func TestHashCache_UsesProvidedHashingFunction(t *testing.T) {
 	hash := func(i int) tosca.Hash {
 		return tosca.Hash{byte(i)}
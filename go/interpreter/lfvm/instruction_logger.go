@@ -34,8 +34,8 @@ func (l loggingRunner) run(c *context) (status, error) {
 		// log format: <op>, <gas>, <top-of-stack>\n
 		if int(c.pc) < len(c.code) {
 			top := "-empty-"
-			if c.stack.len() > 0 {
-				top = c.stack.peek().ToBig().String()
+			if c.stack.Len() > 0 {
+				top = c.stack.Peek().ToBig().String()
 			}
 			if l.log != nil {
 				_, err = l.log.Write([]byte(fmt.Sprintf("%v, %d, %v\n", c.code[c.pc].opcode, c.gas, top)))
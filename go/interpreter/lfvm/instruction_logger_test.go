@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -71,6 +72,9 @@ func TestInterpreter_Logger_ExecutesCodeAndLogs(t *testing.T) {
 }
 
 func TestInterpreter_Logger_RunsWithoutOutput(t *testing.T) {
+	if runtime.GOOS == "js" {
+		t.Skip("pipes are not supported on js")
+	}
 
 	// Get tosca.Parameters
 	params := tosca.Parameters{}
@@ -126,8 +126,9 @@ func TestStatisticsRunner_runInitializesNewStatsWhenUninitialized(t *testing.T)
 		stats: nil,
 	}
 	_, _ = statsRunner.run(&context{
-		code:  []Instruction{{STOP, 0}},
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        []Instruction{{STOP, 0}},
+		stack:       NewStack(),
 	})
 	if statsRunner.stats == nil {
 		t.Errorf("run should have initialized stats")
@@ -139,6 +140,7 @@ func TestStatisticsRunner_statisticsStopsWhenExecutionEncountersAnError(t *testi
 		stats: nil,
 	}
 	_, _ = statsRunner.run(&context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		// this code should not reach a STOP since MCOPY should fail because
 		// there are not enough items on the stack
 		code:  []Instruction{{MCOPY, 0}, {STOP, 0}},
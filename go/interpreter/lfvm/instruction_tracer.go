@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// tracingRunner is a runner reporting the execution of instructions to a
+// tosca.Tracer. Since tracers expect positions and instructions of the
+// original EVM byte code, the executed code has to be converted without
+// super instructions, and the mapping of code positions has to be provided.
+type tracingRunner struct {
+	tracer tosca.Tracer
+	evmPcs []int // EVM code positions by LFVM code positions, -1 if none
+}
+
+// newTracingRunner converts the given EVM code for the use with a tracing
+// runner and creates a runner for it.
+func newTracingRunner(tracer tosca.Tracer, code []byte) (tracingRunner, Code) {
+	evmPcs := make([]int, 0, len(code))
+	converted := convertWithObserver(code, ConversionConfig{}, func(evmPc int, lfvmPc int) {
+		for len(evmPcs) <= lfvmPc {
+			evmPcs = append(evmPcs, -1)
+		}
+		evmPcs[lfvmPc] = evmPc
+	})
+	return tracingRunner{tracer: tracer, evmPcs: evmPcs}, converted
+}
+
+func (r tracingRunner) run(c *context) (status, error) {
+	var stack []tosca.Word
+	status := statusRunning
+	for status == statusRunning {
+		pc := int(c.pc)
+		// Running off the end of the code is reported as an implicit STOP.
+		evmPc, op := len(c.params.Code), vm.STOP
+		if pc < len(c.code) {
+			evmPc, op = -1, vm.OpCode(c.code[pc].opcode)
+			if pc < len(r.evmPcs) {
+				evmPc = r.evmPcs[pc]
+			}
+		}
+		if evmPc >= 0 {
+			stack = stack[:0]
+			for i := 0; i < c.stack.Len(); i++ {
+				stack = append(stack, c.stack.Get(i).Bytes32())
+			}
+			r.tracer.OnOpcode(tosca.OpCodeState{
+				Pc:       evmPc,
+				OpCode:   op,
+				Gas:      c.gas,
+				Depth:    c.params.Depth,
+				Address:  c.params.Recipient,
+				Stack:    stack,
+				Memory:   c.memory.store,
+				InitCode: c.params.InitCode,
+			})
+		}
+		status = execute(c, true)
+		c.maxStackHeight = max(c.maxStackHeight, c.stack.Len())
+	}
+	return status, nil
+}
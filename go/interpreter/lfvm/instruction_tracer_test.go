@@ -0,0 +1,114 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// opCodeRecorder is a tracer recording the reported instructions.
+type opCodeRecorder struct {
+	tosca.NoOpTracer
+	states []tosca.OpCodeState
+}
+
+func (r *opCodeRecorder) OnOpcode(state tosca.OpCodeState) {
+	state.Stack = slices.Clone(state.Stack)
+	state.Memory = slices.Clone(state.Memory)
+	r.states = append(r.states, state)
+}
+
+func TestInterpreter_Tracer_ReportsEvmInstructions(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 4, // < candidates for super instructions
+		byte(vm.PUSH1), 1,
+		byte(vm.JUMPDEST),
+		byte(vm.PUSH3), 1, 2, 3,
+		byte(vm.ADD),
+	}
+
+	recorder := &opCodeRecorder{}
+	vm, err := newVm(config{ConversionConfig: ConversionConfig{WithSuperInstructions: true}})
+	if err != nil {
+		t.Fatalf("failed to create vm: %v", err)
+	}
+	result, err := vm.Run(tosca.Parameters{
+		TransactionParameters: tosca.TransactionParameters{Tracer: recorder},
+		Depth:                 2,
+		Recipient:             tosca.Address{1},
+		Gas:                   100,
+		Code:                  code,
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("execution failed: %v, %v", result, err)
+	}
+
+	type step struct {
+		pc    int
+		op    OpCode
+		gas   tosca.Gas
+		stack int
+	}
+	want := []step{
+		{0, PUSH1, 100, 0},
+		{2, PUSH1, 97, 1},
+		{4, JUMPDEST, 94, 2},
+		{5, PUSH3, 93, 2},
+		{9, ADD, 90, 3},
+		{10, STOP, 87, 2}, // < implicit stop at the end of the code
+	}
+	if len(want) != len(recorder.states) {
+		t.Fatalf("unexpected number of steps, wanted %d, got %d", len(want), len(recorder.states))
+	}
+	for i, state := range recorder.states {
+		if want, got := want[i], (step{state.Pc, OpCode(state.OpCode), state.Gas, len(state.Stack)}); want != got {
+			t.Errorf("unexpected step %d, wanted %v, got %v", i, want, got)
+		}
+		if state.Depth != 2 || state.Address != (tosca.Address{1}) {
+			t.Errorf("unexpected call information in step %d: %v, %v", i, state.Depth, state.Address)
+		}
+	}
+	if want, got := (tosca.Word{31: 4}), recorder.states[1].Stack[0]; want != got {
+		t.Errorf("unexpected stack content, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{29: 1, 30: 2, 31: 3}), recorder.states[4].Stack[2]; want != got {
+		t.Errorf("unexpected top of stack, wanted %v, got %v", want, got)
+	}
+}
+
+func TestInterpreter_Tracer_ReportsExecutionOfInitCode(t *testing.T) {
+	vm, err := newVm(config{})
+	if err != nil {
+		t.Fatalf("failed to create vm: %v", err)
+	}
+	for _, initCode := range []bool{false, true} {
+		recorder := &opCodeRecorder{}
+		_, err := vm.Run(tosca.Parameters{
+			TransactionParameters: tosca.TransactionParameters{Tracer: recorder},
+			Gas:                   100,
+			Code:                  []byte{byte(STOP)},
+			InitCode:              initCode,
+		})
+		if err != nil {
+			t.Fatalf("execution failed: %v", err)
+		}
+		if len(recorder.states) != 1 {
+			t.Fatalf("unexpected number of steps, wanted 1, got %d", len(recorder.states))
+		}
+		if want, got := initCode, recorder.states[0].InitCode; want != got {
+			t.Errorf("unexpected init code flag, wanted %t, got %t", want, got)
+		}
+	}
+}
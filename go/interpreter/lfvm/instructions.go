@@ -23,15 +23,15 @@ func opStop() status {
 }
 
 func opEndWithResult(c *context) error {
-	offset := c.stack.pop()
-	size := c.stack.pop()
+	offset := c.stack.Pop()
+	size := c.stack.Pop()
 	var err error
 	c.returnData, err = c.memory.getSlice(offset, size, c)
 	return err
 }
 
 func opPc(c *context) {
-	c.stack.pushUndefined().SetUint64(uint64(c.code[c.pc].arg))
+	c.stack.PushUndefined().SetUint64(uint64(c.code[c.pc].arg))
 }
 
 func checkJumpDest(c *context) error {
@@ -42,7 +42,7 @@ func checkJumpDest(c *context) error {
 }
 
 func opJump(c *context) error {
-	destination := c.stack.pop()
+	destination := c.stack.Pop()
 	// overflow check
 	if !destination.IsUint64() || destination.Uint64() > math.MaxInt32 {
 		return errInvalidJump
@@ -53,8 +53,8 @@ func opJump(c *context) error {
 }
 
 func opJumpi(c *context) error {
-	destination := c.stack.pop()
-	condition := c.stack.pop()
+	destination := c.stack.Pop()
+	condition := c.stack.Pop()
 	if !condition.IsZero() {
 		// overflow check
 		if !destination.IsUint64() || destination.Uint64() > math.MaxInt32 {
@@ -73,11 +73,11 @@ func opJumpTo(c *context) {
 }
 
 func opPop(c *context) {
-	c.stack.pop()
+	c.stack.Pop()
 }
 
 func opPush(c *context, n int) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	num_instructions := int32(n/2 + n%2)
 	data := c.code[c.pc : c.pc+num_instructions]
 
@@ -98,25 +98,25 @@ func opPush0(c *context) error {
 	if !c.isAtLeast(tosca.R12_Shanghai) {
 		return errInvalidRevision
 	}
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	z[3], z[2], z[1], z[0] = 0, 0, 0, 0
 	return nil
 }
 
 func opPush1(c *context) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	z[3], z[2], z[1] = 0, 0, 0
 	z[0] = uint64(c.code[c.pc].arg >> 8)
 }
 
 func opPush2(c *context) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	z[3], z[2], z[1] = 0, 0, 0
 	z[0] = uint64(c.code[c.pc].arg)
 }
 
 func opPush3(c *context) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	z[3], z[2], z[1] = 0, 0, 0
 	data := c.code[c.pc : c.pc+2]
 	_ = data[1]
@@ -125,7 +125,7 @@ func opPush3(c *context) {
 }
 
 func opPush4(c *context) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 	z[3], z[2], z[1] = 0, 0, 0
 
 	data := c.code[c.pc : c.pc+2]
@@ -135,7 +135,7 @@ func opPush4(c *context) {
 }
 
 func opPush32(c *context) {
-	z := c.stack.pushUndefined()
+	z := c.stack.PushUndefined()
 
 	data := c.code[c.pc : c.pc+16]
 	_ = data[15] // causes bound check to be performed only once (may become unneeded in the future)
@@ -147,23 +147,22 @@ func opPush32(c *context) {
 }
 
 func opDup(c *context, pos int) {
-	c.stack.dup(pos - 1)
+	c.stack.Dup(pos - 1)
 }
 
 func opSwap(c *context, pos int) {
-	c.stack.swap(pos)
+	c.stack.Swap(pos)
 }
 
 func opMstore(c *context) error {
-	var addr = c.stack.pop()
-	var value = c.stack.pop()
-	v := value.Bytes32()
-	return c.memory.set(addr, v[:], c)
+	var addr = c.stack.Pop()
+	var value = c.stack.Pop()
+	return c.memory.writeWord(addr, value, c)
 }
 
 func opMstore8(c *context) error {
-	var addr = c.stack.pop()
-	var value = c.stack.pop()
+	var addr = c.stack.Pop()
+	var value = c.stack.Pop()
 	return c.memory.set(addr, []byte{byte(value.Uint64())}, c)
 }
 
@@ -174,9 +173,9 @@ func opMcopy(c *context) error {
 	}
 
 	var (
-		destAddr = c.stack.pop()
-		srcAddr  = c.stack.pop()
-		size     = c.stack.pop()
+		destAddr = c.stack.Pop()
+		srcAddr  = c.stack.Pop()
+		size     = c.stack.Pop()
 	)
 
 	data, err := c.memory.getSlice(srcAddr, size, c)
@@ -184,7 +183,7 @@ func opMcopy(c *context) error {
 		return err
 	}
 
-	price := tosca.Gas(3 * tosca.SizeInWords(size.Uint64()))
+	price := c.gasSchedule.CopyCost(c.params.Revision, tosca.SizeInWords(size.Uint64()))
 	if err := c.useGas(price); err != nil {
 		return err
 	}
@@ -196,12 +195,12 @@ func opMcopy(c *context) error {
 }
 
 func opMload(c *context) error {
-	var addr = c.stack.peek()
+	var addr = c.stack.Peek()
 	return c.memory.readWord(addr, addr, c)
 }
 
 func opMsize(c *context) {
-	c.stack.pushUndefined().SetUint64(uint64(c.memory.length()))
+	c.stack.PushUndefined().SetUint64(uint64(c.memory.length()))
 }
 
 func opSstore(c *context) error {
@@ -216,38 +215,34 @@ func opSstore(c *context) error {
 		return errOutOfGas
 	}
 
-	var key = tosca.Key(c.stack.pop().Bytes32())
-	var value = tosca.Word(c.stack.pop().Bytes32())
+	var key = tosca.Key(c.stack.Pop().Bytes32())
+	var value = tosca.Word(c.stack.Pop().Bytes32())
 
 	cost := tosca.Gas(0)
-	if c.isAtLeast(tosca.R09_Berlin) &&
-		c.context.AccessStorage(c.params.Recipient, key) == tosca.ColdAccess {
-		cost += 2100
+	if tosca.AccessCostsApply(c.params.Revision) {
+		cost += tosca.ColdStorageAccessSurcharge(c.context.AccessStorage(c.params.Recipient, key))
 	}
 
 	storageStatus := c.context.SetStorage(c.params.Recipient, key, value)
 
-	cost += getDynamicCostsForSstore(c.params.Revision, storageStatus)
+	schedule := c.gasSchedule
+	cost += schedule.SstoreCost(c.params.Revision, storageStatus)
 	if err := c.useGas(cost); err != nil {
 		return err
 	}
 
-	c.refund += getRefundForSstore(c.params.Revision, storageStatus)
+	c.refund += schedule.SstoreRefund(c.params.Revision, storageStatus)
 	return nil
 }
 
 func opSload(c *context) error {
-	var top = c.stack.peek()
+	var top = c.stack.Peek()
 
 	addr := c.params.Recipient
 	slot := tosca.Key(top.Bytes32())
-	if c.isAtLeast(tosca.R09_Berlin) {
+	if tosca.AccessCostsApply(c.params.Revision) {
 		// charge costs for warm/cold slot access
-		costs := tosca.Gas(100)
-		if c.context.AccessStorage(addr, slot) == tosca.ColdAccess {
-			costs = 2100
-		}
-		if err := c.useGas(costs); err != nil {
+		if err := c.useGas(tosca.StorageAccessCost(c.context.AccessStorage(addr, slot))); err != nil {
 			return err
 		}
 	}
@@ -269,8 +264,8 @@ func opTstore(c *context) error {
 		return errStaticContextViolation
 	}
 
-	key := tosca.Key(c.stack.pop().Bytes32())
-	value := tosca.Word(c.stack.pop().Bytes32())
+	key := tosca.Key(c.stack.Pop().Bytes32())
+	value := tosca.Word(c.stack.Pop().Bytes32())
 	c.context.SetTransientStorage(c.params.Recipient, key, value)
 	return nil
 }
@@ -280,7 +275,7 @@ func opTload(c *context) error {
 		return errInvalidRevision
 	}
 
-	top := c.stack.peek()
+	top := c.stack.Peek()
 	key := tosca.Key(top.Bytes32())
 	value := c.context.GetTransientStorage(c.params.Recipient, key)
 	top.SetBytes32(value[:])
@@ -288,34 +283,34 @@ func opTload(c *context) error {
 }
 
 func opCaller(c *context) {
-	c.stack.pushUndefined().SetBytes20(c.params.Sender[:])
+	c.stack.PushUndefined().SetBytes20(c.params.Sender[:])
 }
 
 func opCallvalue(c *context) {
-	c.stack.pushUndefined().SetBytes32(c.params.Value[:])
+	c.stack.PushUndefined().SetBytes32(c.params.Value[:])
 }
 
 func opCallDatasize(c *context) {
 	size := len(c.params.Input)
-	c.stack.pushUndefined().SetUint64(uint64(size))
+	c.stack.PushUndefined().SetUint64(uint64(size))
 }
 
 func opCallDataload(c *context) {
-	top := c.stack.peek()
+	top := c.stack.Peek()
 	value := getData(c.params.Input, top, 32)
 	top.SetBytes(value)
 }
 
 func genericDataCopy(c *context, source []byte) error {
 	var (
-		memOffset  = c.stack.pop()
-		dataOffset = c.stack.pop()
-		length     = c.stack.pop()
+		memOffset  = c.stack.Pop()
+		dataOffset = c.stack.Pop()
+		length     = c.stack.Pop()
 	)
 
 	// Charge for the copy costs
 	words := tosca.SizeInWords(length.Uint64())
-	if err := c.useGas(tosca.Gas(3 * words)); err != nil {
+	if err := c.useGas(c.gasSchedule.CopyCost(c.params.Revision, words)); err != nil {
 		return err
 	}
 
@@ -331,29 +326,29 @@ func genericDataCopy(c *context, source []byte) error {
 }
 
 func opAnd(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.And(a, b)
 }
 
 func opOr(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Or(a, b)
 }
 
 func opNot(c *context) {
-	a := c.stack.peek()
+	a := c.stack.Peek()
 	a.Not(a)
 }
 func opXor(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Xor(a, b)
 }
 
 func opIszero(c *context) {
-	top := c.stack.peek()
+	top := c.stack.Peek()
 	if top.IsZero() {
 		top.SetOne()
 	} else {
@@ -362,8 +357,8 @@ func opIszero(c *context) {
 }
 
 func opEq(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	res := a.Cmp(b)
 	for i := range b {
 		b[i] = 0
@@ -376,8 +371,8 @@ func opEq(c *context) {
 }
 
 func opLt(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.Lt(b) {
 		b.SetOne()
 	} else {
@@ -386,8 +381,8 @@ func opLt(c *context) {
 }
 
 func opGt(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.Gt(b) {
 		b.SetOne()
 	} else {
@@ -396,8 +391,8 @@ func opGt(c *context) {
 }
 
 func opSlt(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.Slt(b) {
 		b.SetOne()
 	} else {
@@ -406,8 +401,8 @@ func opSlt(c *context) {
 }
 
 func opSgt(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.Sgt(b) {
 		b.SetOne()
 	} else {
@@ -416,8 +411,8 @@ func opSgt(c *context) {
 }
 
 func opShr(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.LtUint64(256) {
 		b.Rsh(b, uint(a.Uint64()))
 	} else {
@@ -426,8 +421,8 @@ func opShr(c *context) {
 }
 
 func opShl(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.LtUint64(256) {
 		b.Lsh(b, uint(a.Uint64()))
 	} else {
@@ -436,8 +431,8 @@ func opShl(c *context) {
 }
 
 func opSar(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	if a.GtUint64(256) {
 		if b.Sign() >= 0 {
 			b.Clear()
@@ -450,85 +445,86 @@ func opSar(c *context) {
 }
 
 func opSignExtend(c *context) {
-	back, num := c.stack.pop(), c.stack.peek()
+	back, num := c.stack.Pop(), c.stack.Peek()
 	num.ExtendSign(num, back)
 }
 
 func opByte(c *context) {
-	th, val := c.stack.pop(), c.stack.peek()
+	th, val := c.stack.Pop(), c.stack.Peek()
 	val.Byte(th)
 }
 
 func opAdd(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Add(a, b)
 }
 
 func opSub(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Sub(a, b)
 }
 
 func opMul(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Mul(a, b)
 }
 
 func opMulMod(c *context) {
-	a := c.stack.pop()
-	b := c.stack.pop()
-	n := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Pop()
+	n := c.stack.Peek()
 	n.MulMod(a, b, n)
 }
 
 func opDiv(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Div(a, b)
 }
 
 func opSDiv(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.SDiv(a, b)
 }
 
 func opMod(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.Mod(a, b)
 }
 
 func opAddMod(c *context) {
-	a := c.stack.pop()
-	b := c.stack.pop()
-	n := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Pop()
+	n := c.stack.Peek()
 	n.AddMod(a, b, n)
 }
 
 func opSMod(c *context) {
-	a := c.stack.pop()
-	b := c.stack.peek()
+	a := c.stack.Pop()
+	b := c.stack.Peek()
 	b.SMod(a, b)
 }
 
 func opExp(c *context) error {
-	base, exponent := c.stack.pop(), c.stack.peek()
-	if err := c.useGas(tosca.Gas(50 * exponent.ByteLen())); err != nil {
+	base, exponent := c.stack.Pop(), c.stack.Peek()
+	if err := c.useGas(c.gasSchedule.ExpCost(c.params.Revision, exponent.ByteLen())); err != nil {
 		return err
 	}
 	exponent.Exp(base, exponent)
 	return nil
 }
 
-// Evaluations show a 96% hit rate of this configuration.
-var sha3Cache = newSha3HashCache(1<<16, 1<<18)
+// sha3Cache is shared by all interpreter instances using the default
+// configuration of the SHA3 hash cache.
+var sha3Cache = newSha3HashCacheWithConfig(ShaCacheConfig{})
 
 func opSha3(c *context) error {
-	offset, size := c.stack.pop(), c.stack.peek()
+	offset, size := c.stack.Pop(), c.stack.Peek()
 
 	data, err := c.memory.getSlice(offset, size, c)
 	if err != nil {
@@ -536,15 +532,15 @@ func opSha3(c *context) error {
 	}
 
 	words := tosca.SizeInWords(size.Uint64())
-	price := tosca.Gas(6 * words)
+	price := c.gasSchedule.HashCost(c.params.Revision, words)
 	if err := c.useGas(price); err != nil {
 		return err
 	}
 
 	var hash tosca.Hash
-	if c.withShaCache {
+	if c.shaCache != nil {
 		// Cache hashes since identical values are frequently re-hashed.
-		hash = sha3Cache.hash(data)
+		hash = c.shaCache.hash(data)
 	} else {
 		hash = Keccak256(data)
 	}
@@ -554,45 +550,45 @@ func opSha3(c *context) error {
 }
 
 func opGas(c *context) {
-	c.stack.pushUndefined().SetUint64(uint64(c.gas))
+	c.stack.PushUndefined().SetUint64(uint64(c.gas))
 }
 
 // opPrevRandao / opDifficulty
 func opPrevRandao(c *context) {
 	prevRandao := c.params.PrevRandao
-	c.stack.pushUndefined().SetBytes32(prevRandao[:])
+	c.stack.PushUndefined().SetBytes32(prevRandao[:])
 }
 
 func opTimestamp(c *context) {
 	time := c.params.Timestamp
-	c.stack.pushUndefined().SetUint64(uint64(time))
+	c.stack.PushUndefined().SetUint64(uint64(time))
 }
 
 func opNumber(c *context) {
 	number := c.params.BlockNumber
-	c.stack.pushUndefined().SetUint64(uint64(number))
+	c.stack.PushUndefined().SetUint64(uint64(number))
 }
 
 func opCoinbase(c *context) {
 	coinbase := c.params.Coinbase
-	c.stack.pushUndefined().SetBytes20(coinbase[:])
+	c.stack.PushUndefined().SetBytes20(coinbase[:])
 }
 
 func opGasLimit(c *context) {
 	limit := c.params.GasLimit
-	c.stack.pushUndefined().SetUint64(uint64(limit))
+	c.stack.PushUndefined().SetUint64(uint64(limit))
 }
 
 func opGasPrice(c *context) {
 	price := c.params.GasPrice
-	c.stack.pushUndefined().SetBytes32(price[:])
+	c.stack.PushUndefined().SetBytes32(price[:])
 }
 
 func opBalance(c *context) error {
-	slot := c.stack.peek()
+	slot := c.stack.Peek()
 	address := tosca.Address(slot.Bytes20())
-	if c.isAtLeast(tosca.R09_Berlin) {
-		if err := c.useGas(getAccessCost(c.context.AccessAccount(address))); err != nil {
+	if tosca.AccessCostsApply(c.params.Revision) {
+		if err := c.useGas(tosca.AccountAccessCost(c.context.AccessAccount(address))); err != nil {
 			return err
 		}
 	}
@@ -603,7 +599,7 @@ func opBalance(c *context) error {
 
 func opSelfbalance(c *context) {
 	balance := c.context.GetBalance(c.params.Recipient)
-	c.stack.pushUndefined().SetBytes32(balance[:])
+	c.stack.PushUndefined().SetBytes32(balance[:])
 }
 
 func opBaseFee(c *context) error {
@@ -611,7 +607,7 @@ func opBaseFee(c *context) error {
 		return errInvalidRevision
 	}
 	fee := c.params.BaseFee
-	c.stack.pushUndefined().SetBytes32(fee[:])
+	c.stack.PushUndefined().SetBytes32(fee[:])
 	return nil
 }
 
@@ -620,12 +616,12 @@ func opBlobHash(c *context) error {
 		return errInvalidRevision
 	}
 
-	index := c.stack.pop()
+	index := c.stack.Pop()
 	blobHashesLength := uint64(len(c.params.BlobHashes))
 	if index.IsUint64() && index.Uint64() < blobHashesLength {
-		c.stack.pushUndefined().SetBytes32(c.params.BlobHashes[index.Uint64()][:])
+		c.stack.PushUndefined().SetBytes32(c.params.BlobHashes[index.Uint64()][:])
 	} else {
-		c.stack.push(uint256.NewInt(0))
+		c.stack.Push(uint256.NewInt(0))
 	}
 	return nil
 }
@@ -635,7 +631,7 @@ func opBlobBaseFee(c *context) error {
 		return errInvalidRevision
 	}
 	fee := c.params.BlobBaseFee
-	c.stack.pushUndefined().SetBytes32(fee[:])
+	c.stack.PushUndefined().SetBytes32(fee[:])
 	return nil
 }
 
@@ -646,37 +642,48 @@ func opSelfdestruct(c *context) (status, error) {
 		return statusStopped, errStaticContextViolation
 	}
 
-	beneficiary := tosca.Address(c.stack.pop().Bytes20())
+	beneficiary := tosca.Address(c.stack.Pop().Bytes20())
 	// Selfdestruct gas cost defined in EIP-105 (see https://eips.ethereum.org/EIPS/eip-150)
 	cost := tosca.Gas(0)
-	if c.isAtLeast(tosca.R09_Berlin) {
+	if tosca.AccessCostsApply(c.params.Revision) {
 		// as https://eips.ethereum.org/EIPS/eip-2929#selfdestruct-changes says,
 		// selfdestruct does not charge for warm access
-		if accessStatus := c.context.AccessAccount(beneficiary); accessStatus != tosca.WarmAccess {
-			cost += getAccessCost(accessStatus)
-		}
+		cost += tosca.ColdAccountAccessSurcharge(c.context.AccessAccount(beneficiary))
 	}
 
+	balance := c.context.GetBalance(c.params.Recipient)
 	cost += selfDestructNewAccountCost(
-		isEmpty(c.context, beneficiary),
-		c.context.GetBalance(c.params.Recipient),
+		c.gasSchedule,
+		c.params.Revision,
+		tosca.IsEmptyAccount(c.context, beneficiary),
+		balance,
 	)
 	// even death is not for free
 	if err := c.useGas(cost); err != nil {
 		return statusStopped, err
 	}
 
-	destructed := c.context.SelfDestruct(c.params.Recipient, beneficiary)
+	// The destruction is finalized by the caller, see tosca.Result.
+	destructed := !c.context.HasSelfDestructed(c.params.Recipient)
 	c.refund += selfDestructRefund(destructed, c.params.Revision)
+	c.selfDestruction = &tosca.SelfDestruction{
+		Beneficiary: beneficiary,
+		Value:       balance,
+	}
 	return statusSelfDestructed, nil
 }
 
-func selfDestructNewAccountCost(beneficiaryEmpty bool, balance tosca.Value) tosca.Gas {
+func selfDestructNewAccountCost(
+	schedule tosca.GasSchedule,
+	revision tosca.Revision,
+	beneficiaryEmpty bool,
+	balance tosca.Value,
+) tosca.Gas {
 	if beneficiaryEmpty && balance != (tosca.Value{}) {
 		// cost of creating an account defined in eip-150 (see https://eips.ethereum.org/EIPS/eip-150)
 		// CreateBySelfdestructGas is used when the refunded account is one that does
 		// not exist. This logic is similar to call.
-		return 25_000
+		return schedule.NewAccountCost(revision)
 	}
 	return 0
 }
@@ -691,11 +698,15 @@ func selfDestructRefund(destructed bool, revision tosca.Revision) tosca.Gas {
 
 func opChainId(c *context) {
 	id := c.params.ChainID
-	c.stack.pushUndefined().SetBytes32(id[:])
+	c.stack.PushUndefined().SetBytes32(id[:])
 }
 
+// opBlockhash looks up the hash of one of the 256 most recent blocks through
+// the run context. EIP-2935 stores block hashes in the history storage
+// contract starting with Prague, yet in its adopted form it leaves BLOCKHASH
+// unchanged. Hence, the history contract is not consulted here.
 func opBlockhash(c *context) {
-	top := c.stack.peek()
+	top := c.stack.Peek()
 
 	requestedBlockNumber := top.Uint64()
 	currentBlockNumber := uint64(c.params.BlockNumber)
@@ -719,24 +730,24 @@ func opBlockhash(c *context) {
 }
 
 func opAddress(c *context) {
-	c.stack.pushUndefined().SetBytes20(c.params.Recipient[:])
+	c.stack.PushUndefined().SetBytes20(c.params.Recipient[:])
 }
 
 func opOrigin(c *context) {
 	origin := c.params.Origin
-	c.stack.pushUndefined().SetBytes20(origin[:])
+	c.stack.PushUndefined().SetBytes20(origin[:])
 }
 
 func opCodeSize(c *context) {
 	size := len(c.params.Code)
-	c.stack.pushUndefined().SetUint64(uint64(size))
+	c.stack.PushUndefined().SetUint64(uint64(size))
 }
 
 func opExtcodesize(c *context) error {
-	top := c.stack.peek()
+	top := c.stack.Peek()
 	address := tosca.Address(top.Bytes20())
-	if c.isAtLeast(tosca.R09_Berlin) {
-		if err := c.useGas(getAccessCost(c.context.AccessAccount(address))); err != nil {
+	if tosca.AccessCostsApply(c.params.Revision) {
+		if err := c.useGas(tosca.AccountAccessCost(c.context.AccessAccount(address))); err != nil {
 			return err
 		}
 	}
@@ -745,15 +756,15 @@ func opExtcodesize(c *context) error {
 }
 
 func opExtcodehash(c *context) error {
-	slot := c.stack.peek()
+	slot := c.stack.Peek()
 	address := tosca.Address(slot.Bytes20())
-	if c.isAtLeast(tosca.R09_Berlin) {
-		if err := c.useGas(getAccessCost(c.context.AccessAccount(address))); err != nil {
+	if tosca.AccessCostsApply(c.params.Revision) {
+		if err := c.useGas(tosca.AccountAccessCost(c.context.AccessAccount(address))); err != nil {
 			return err
 		}
 	}
 
-	if isEmpty(c.context, address) {
+	if tosca.IsEmptyAccount(c.context, address) {
 		slot.Clear()
 	} else {
 		hash := c.context.GetCodeHash(address)
@@ -770,13 +781,13 @@ func genericCreate(c *context, kind tosca.CallKind) error {
 	}
 
 	var (
-		value  = c.stack.pop()
-		offset = c.stack.pop()
-		size   = c.stack.pop()
+		value  = c.stack.Pop()
+		offset = c.stack.Pop()
+		size   = c.stack.Pop()
 		salt   = tosca.Hash{}
 	)
 	if kind == tosca.Create2 {
-		salt = c.stack.pop().Bytes32() // pop salt value for Create2
+		salt = c.stack.Pop().Bytes32() // pop salt value for Create2
 	}
 
 	input, err := c.memory.getSlice(offset, size, c)
@@ -797,7 +808,7 @@ func genericCreate(c *context, kind tosca.CallKind) error {
 	if kind == tosca.Create2 {
 		// Charge for hashing the init code to compute the target address.
 		words := tosca.SizeInWords(size.Uint64())
-		if err := c.useGas(tosca.Gas(6 * words)); err != nil {
+		if err := c.useGas(c.gasSchedule.HashCost(c.params.Revision, words)); err != nil {
 			return err
 		}
 	}
@@ -807,7 +818,7 @@ func genericCreate(c *context, kind tosca.CallKind) error {
 		balanceU256 := new(uint256.Int).SetBytes(balance[:])
 
 		if value.Gt(balanceU256) {
-			c.stack.pushUndefined().Clear()
+			c.stack.PushUndefined().Clear()
 			c.returnData = nil
 			return nil
 		}
@@ -826,7 +837,7 @@ func genericCreate(c *context, kind tosca.CallKind) error {
 	})
 
 	// Push item on the stack based on the returned error.
-	success := c.stack.pushUndefined()
+	success := c.stack.PushUndefined()
 	if !res.Success || err != nil {
 		success.Clear()
 	} else {
@@ -849,10 +860,8 @@ func genericCreate(c *context, kind tosca.CallKind) error {
 // Returns the gas cost for the size of the init code and nil, or
 // zero and an error if size is greater than MaxInitCodeSize.
 func computeCodeSizeCost(size uint64) (tosca.Gas, error) {
-	const (
-		maxCodeSize     = 24576           // Maximum bytecode to permit for a contract
-		maxInitCodeSize = 2 * maxCodeSize // Maximum initcode to permit in a creation transaction and create instructions
-	)
+	// Maximum initcode to permit in a creation transaction and create instructions
+	const maxInitCodeSize = 2 * tosca.MaxCodeSize
 	if size > maxInitCodeSize {
 		return 0, errInitCodeTooLarge
 	}
@@ -898,10 +907,10 @@ func getData(data []byte, offset *uint256.Int, size uint64) []byte {
 
 func opExtCodeCopy(c *context) error {
 
-	address := c.stack.pop().Bytes20()
+	address := c.stack.Pop().Bytes20()
 
-	if c.isAtLeast(tosca.R09_Berlin) {
-		if err := c.useGas(getAccessCost(c.context.AccessAccount(address))); err != nil {
+	if tosca.AccessCostsApply(c.params.Revision) {
+		if err := c.useGas(tosca.AccountAccessCost(c.context.AccessAccount(address))); err != nil {
 			return err
 		}
 	}
@@ -909,25 +918,16 @@ func opExtCodeCopy(c *context) error {
 	return genericDataCopy(c, c.context.GetCode(address))
 }
 
-func getAccessCost(accessStatus tosca.AccessStatus) tosca.Gas {
-	// EIP-2929 says that cold access cost is 2600 and warm is 100.
-	// (https://eips.ethereum.org/EIPS/eip-2929)
-	if accessStatus == tosca.ColdAccess {
-		return tosca.Gas(2600)
-	}
-	return tosca.Gas(100)
-}
-
 func genericCall(c *context, kind tosca.CallKind) error {
 	stack := c.stack
 	value := uint256.NewInt(0)
 
 	// Pop call parameters.
-	provided_gas, addr := stack.pop(), stack.pop()
+	provided_gas, addr := stack.Pop(), stack.Pop()
 	if kind == tosca.Call || kind == tosca.CallCode {
-		value = stack.pop()
+		value = stack.Pop()
 	}
-	inOffset, inSize, retOffset, retSize := stack.pop(), stack.pop(), stack.pop(), stack.pop()
+	inOffset, inSize, retOffset, retSize := stack.Pop(), stack.Pop(), stack.Pop(), stack.Pop()
 
 	// We need to check the existence of the target account before removing
 	// the gas price for the other cost factors to make sure that the read
@@ -946,8 +946,8 @@ func genericCall(c *context, kind tosca.CallKind) error {
 	}
 
 	// from berlin onwards access cost changes depending on warm/cold access.
-	if c.isAtLeast(tosca.R09_Berlin) {
-		if err := c.useGas(getAccessCost(c.context.AccessAccount(toAddr))); err != nil {
+	if tosca.AccessCostsApply(c.params.Revision) {
+		if err := c.useGas(tosca.AccountAccessCost(c.context.AccessAccount(toAddr))); err != nil {
 			return err
 		}
 	}
@@ -962,8 +962,8 @@ func genericCall(c *context, kind tosca.CallKind) error {
 
 	// EIP158 states that non-zero value calls that create a new account should
 	// be charged an additional gas fee.
-	if kind == tosca.Call && !value.IsZero() && isEmpty(c.context, toAddr) {
-		if err := c.useGas(CallNewAccountGas); err != nil {
+	if kind == tosca.Call && !value.IsZero() && tosca.IsEmptyAccount(c.context, toAddr) {
+		if err := c.useGas(c.gasSchedule.NewAccountCost(c.params.Revision)); err != nil {
 			return err
 		}
 	}
@@ -989,7 +989,7 @@ func genericCall(c *context, kind tosca.CallKind) error {
 		balance := c.context.GetBalance(c.params.Recipient)
 		balanceU256 := new(uint256.Int).SetBytes32(balance[:])
 		if balanceU256.Lt(value) {
-			c.stack.pushUndefined().Clear()
+			c.stack.PushUndefined().Clear()
 			c.returnData = nil
 			c.gas += nestedCallGas // the gas send to the nested contract is returned
 			return nil
@@ -1034,7 +1034,7 @@ func genericCall(c *context, kind tosca.CallKind) error {
 		copy(output, ret.Output)
 	}
 
-	success := stack.pushUndefined()
+	success := stack.PushUndefined()
 	if err != nil || !ret.Success {
 		success.Clear()
 	} else {
@@ -1047,7 +1047,7 @@ func genericCall(c *context, kind tosca.CallKind) error {
 }
 
 func opCall(c *context) error {
-	value := c.stack.peekN(2)
+	value := c.stack.PeekN(2)
 	// In a static call, no value must be transferred.
 	if c.params.Static && !value.IsZero() {
 		return errStaticContextViolation
@@ -1068,14 +1068,14 @@ func opDelegateCall(c *context) error {
 }
 
 func opReturnDataSize(c *context) {
-	c.stack.pushUndefined().SetUint64(uint64(len(c.returnData)))
+	c.stack.PushUndefined().SetUint64(uint64(len(c.returnData)))
 }
 
 func opReturnDataCopy(c *context) error {
 	var (
-		memOffset  = c.stack.pop()
-		dataOffset = c.stack.pop()
-		length     = c.stack.pop()
+		memOffset  = c.stack.Pop()
+		dataOffset = c.stack.Pop()
+		length     = c.stack.Pop()
 	)
 
 	if !dataOffset.IsUint64() || !length.IsUint64() {
@@ -1083,7 +1083,7 @@ func opReturnDataCopy(c *context) error {
 	}
 
 	words := tosca.SizeInWords(length.Uint64())
-	if err := c.useGas(tosca.Gas(3 * words)); err != nil {
+	if err := c.useGas(c.gasSchedule.CopyCost(c.params.Revision, words)); err != nil {
 		return errOutOfGas
 	}
 
@@ -1106,13 +1106,13 @@ func opLog(c *context, n int) error {
 	}
 
 	var (
-		offset = c.stack.pop()
-		size   = c.stack.pop()
+		offset = c.stack.Pop()
+		size   = c.stack.Pop()
 	)
 
 	topics := make([]tosca.Hash, n)
 	for i := 0; i < n; i++ {
-		addr := c.stack.pop()
+		addr := c.stack.Pop()
 		topics[i] = addr.Bytes32()
 	}
 
@@ -1134,11 +1134,3 @@ func opLog(c *context, n int) error {
 	})
 	return nil
 }
-
-// isEmpty is a utility function that checks if an account is empty. An account
-// is considered empty if it has no nonce, no balance, and no code.
-func isEmpty(c tosca.RunContext, addr tosca.Address) bool {
-	return c.GetNonce(addr) == 0 &&
-		c.GetBalance(addr) == (tosca.Value{}) &&
-		c.GetCodeSize(addr) == 0
-}
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 	"github.com/holiman/uint256"
 	"go.uber.org/mock/gomock"
 )
@@ -36,15 +37,16 @@ func TestPushN(t *testing.T) {
 
 	for n := 1; n <= 32; n++ {
 		ctxt := context{
-			code:  code,
-			stack: NewStack(),
+			gasSchedule: tosca.EthereumGasSchedule{},
+			code:        code,
+			stack:       NewStack(),
 		}
 
 		opPush(&ctxt, n)
 		ctxt.pc++
 
-		if ctxt.stack.len() != 1 {
-			t.Errorf("expected stack size of 1, got %d", ctxt.stack.len())
+		if ctxt.stack.Len() != 1 {
+			t.Errorf("expected stack size of 1, got %d", ctxt.stack.Len())
 			return
 		}
 
@@ -52,7 +54,7 @@ func TestPushN(t *testing.T) {
 			t.Errorf("for PUSH%d program counter did not progress to %d, got %d", n, n/2+n%2, ctxt.pc)
 		}
 
-		got := ctxt.stack.peek().Bytes()
+		got := ctxt.stack.Peek().Bytes()
 		if len(got) != n {
 			t.Errorf("expected %d bytes on the stack, got %d with values %v", n, len(got), got)
 		}
@@ -71,15 +73,16 @@ func TestPush1(t *testing.T) {
 	}
 
 	ctxt := context{
-		code:  code,
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        code,
+		stack:       NewStack(),
 	}
 
 	opPush1(&ctxt)
 	ctxt.pc++
 
-	if ctxt.stack.len() != 1 {
-		t.Errorf("expected stack size of 1, got %d", ctxt.stack.len())
+	if ctxt.stack.Len() != 1 {
+		t.Errorf("expected stack size of 1, got %d", ctxt.stack.Len())
 		return
 	}
 
@@ -87,7 +90,7 @@ func TestPush1(t *testing.T) {
 		t.Errorf("program counter did not progress to %d, got %d", 1, ctxt.pc)
 	}
 
-	got := ctxt.stack.peek().Bytes()
+	got := ctxt.stack.Peek().Bytes()
 	if len(got) != 1 {
 		t.Errorf("expected 1 byte on the stack, got %d with values %v", len(got), got)
 	}
@@ -102,15 +105,16 @@ func TestPush2(t *testing.T) {
 	}
 
 	ctxt := context{
-		code:  code,
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        code,
+		stack:       NewStack(),
 	}
 
 	opPush2(&ctxt)
 	ctxt.pc++
 
-	if ctxt.stack.len() != 1 {
-		t.Errorf("expected stack size of 1, got %d", ctxt.stack.len())
+	if ctxt.stack.Len() != 1 {
+		t.Errorf("expected stack size of 1, got %d", ctxt.stack.Len())
 		return
 	}
 
@@ -118,7 +122,7 @@ func TestPush2(t *testing.T) {
 		t.Errorf("program counter did not progress to %d, got %d", 1, ctxt.pc)
 	}
 
-	got := ctxt.stack.peek().Bytes()
+	got := ctxt.stack.Peek().Bytes()
 	if len(got) != 2 {
 		t.Errorf("expected 2 byte on the stack, got %d with values %v", len(got), got)
 	}
@@ -137,15 +141,16 @@ func TestPush3(t *testing.T) {
 	}
 
 	ctxt := context{
-		code:  code,
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        code,
+		stack:       NewStack(),
 	}
 
 	opPush3(&ctxt)
 	ctxt.pc++
 
-	if ctxt.stack.len() != 1 {
-		t.Errorf("expected stack size of 1, got %d", ctxt.stack.len())
+	if ctxt.stack.Len() != 1 {
+		t.Errorf("expected stack size of 1, got %d", ctxt.stack.Len())
 		return
 	}
 
@@ -153,7 +158,7 @@ func TestPush3(t *testing.T) {
 		t.Errorf("program counter did not progress to %d, got %d", 2, ctxt.pc)
 	}
 
-	got := ctxt.stack.peek().Bytes()
+	got := ctxt.stack.Peek().Bytes()
 	if len(got) != 3 {
 		t.Errorf("expected 3 byte on the stack, got %d with values %v", len(got), got)
 	}
@@ -175,15 +180,16 @@ func TestPush4(t *testing.T) {
 	}
 
 	ctxt := context{
-		code:  code,
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        code,
+		stack:       NewStack(),
 	}
 
 	opPush4(&ctxt)
 	ctxt.pc++
 
-	if ctxt.stack.len() != 1 {
-		t.Errorf("expected stack size of 1, got %d", ctxt.stack.len())
+	if ctxt.stack.Len() != 1 {
+		t.Errorf("expected stack size of 1, got %d", ctxt.stack.Len())
 		return
 	}
 
@@ -191,7 +197,7 @@ func TestPush4(t *testing.T) {
 		t.Errorf("program counter did not progress to %d, got %d", 2, ctxt.pc)
 	}
 
-	got := ctxt.stack.peek().Bytes()
+	got := ctxt.stack.Peek().Bytes()
 	if len(got) != 4 {
 		t.Errorf("expected 3 byte on the stack, got %d with values %v", len(got), got)
 	}
@@ -216,6 +222,7 @@ func TestCallChecksBalances(t *testing.T) {
 	source := tosca.Address{1}
 	target := tosca.Address{2}
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			Recipient: source,
 		},
@@ -226,9 +233,9 @@ func TestCallChecksBalances(t *testing.T) {
 	}
 
 	// Prepare stack arguments.
-	ctxt.stack.stackPointer = 7
-	ctxt.stack.data[4].Set(uint256.NewInt(1)) // < the value to be transferred
-	ctxt.stack.data[5].SetBytes(target[:])    // < the target address for the call
+	ctxt.stack.SetLen(7)
+	ctxt.stack.Get(4).Set(uint256.NewInt(1)) // < the value to be transferred
+	ctxt.stack.Get(5).SetBytes(target[:])    // < the target address for the call
 
 	// The target account should exist and the source account without funds.
 	runContext.EXPECT().GetNonce(target).Return(uint64(1))
@@ -239,11 +246,11 @@ func TestCallChecksBalances(t *testing.T) {
 		t.Errorf("opCall failed: %v", err)
 	}
 
-	if want, got := 1, ctxt.stack.len(); want != got {
+	if want, got := 1, ctxt.stack.Len(); want != got {
 		t.Fatalf("unexpected stack size, wanted %d, got %d", want, got)
 	}
 
-	if want, got := *uint256.NewInt(0), ctxt.stack.data[0]; want != got {
+	if want, got := *uint256.NewInt(0), *ctxt.stack.Get(0); want != got {
 		t.Fatalf("unexpected value on top of stack, wanted %v, got %v", want, got)
 	}
 }
@@ -254,6 +261,7 @@ func TestCreateChecksBalance(t *testing.T) {
 
 	source := tosca.Address{1}
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			Recipient: source,
 		},
@@ -264,8 +272,8 @@ func TestCreateChecksBalance(t *testing.T) {
 	}
 
 	// Prepare stack arguments.
-	ctxt.stack.stackPointer = 3
-	ctxt.stack.data[2].Set(uint256.NewInt(1)) // < the value to be transferred
+	ctxt.stack.SetLen(3)
+	ctxt.stack.Get(2).Set(uint256.NewInt(1)) // < the value to be transferred
 
 	// The source account should have enough funds.
 	runContext.EXPECT().GetBalance(source).Return(tosca.Value{})
@@ -274,10 +282,10 @@ func TestCreateChecksBalance(t *testing.T) {
 	if err != nil {
 		t.Errorf("opCreate failed: %v", err)
 	}
-	if want, got := 1, ctxt.stack.len(); want != got {
+	if want, got := 1, ctxt.stack.Len(); want != got {
 		t.Fatalf("unexpected stack size, wanted %d, got %d", want, got)
 	}
-	if want, got := *uint256.NewInt(0), ctxt.stack.data[0]; want != got {
+	if want, got := *uint256.NewInt(0), *ctxt.stack.Get(0); want != got {
 		t.Fatalf("unexpected value on top of stack, wanted %v, got %v", want, got)
 	}
 }
@@ -291,20 +299,20 @@ func TestBlobHash_PushesCorrectValueOnStack(t *testing.T) {
 	}{
 		"regular": {
 			setup: func(params *tosca.Parameters, stack *stack) {
-				stack.push(uint256.NewInt(0))
+				stack.Push(uint256.NewInt(0))
 				params.BlobHashes = []tosca.Hash{hash}
 			},
 			want: hash,
 		},
 		"no-hashes": {
 			setup: func(params *tosca.Parameters, stack *stack) {
-				stack.push(uint256.NewInt(0))
+				stack.Push(uint256.NewInt(0))
 			},
 			want: tosca.Hash{},
 		},
 		"target-non-existent": {
 			setup: func(params *tosca.Parameters, stack *stack) {
-				stack.push(uint256.NewInt(1))
+				stack.Push(uint256.NewInt(1))
 			},
 			want: tosca.Hash{},
 		},
@@ -314,7 +322,8 @@ func TestBlobHash_PushesCorrectValueOnStack(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 
 			ctxt := context{
-				stack: NewStack(),
+				gasSchedule: tosca.EthereumGasSchedule{},
+				stack:       NewStack(),
 			}
 			ctxt.params.Revision = tosca.R13_Cancun
 			test.setup(&ctxt.params, ctxt.stack)
@@ -323,7 +332,7 @@ func TestBlobHash_PushesCorrectValueOnStack(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected return: %v", err)
 			}
-			if want, got := test.want, ctxt.stack.data[0]; tosca.Hash(got.Bytes32()) != want {
+			if want, got := test.want, *ctxt.stack.Get(0); tosca.Hash(got.Bytes32()) != want {
 				t.Fatalf("unexpected value on top of stack, wanted %v, got %v", want, got)
 			}
 		})
@@ -332,7 +341,8 @@ func TestBlobHash_PushesCorrectValueOnStack(t *testing.T) {
 
 func TestBlobBaseFee_ReturnsErrorWhenCalledWithUnsupportedRevision(t *testing.T) {
 	ctxt := context{
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		stack:       NewStack(),
 	}
 	ctxt.params.Revision = tosca.R12_Shanghai
 
@@ -427,6 +437,7 @@ func TestCreateShanghaiInitCodeSize(t *testing.T) {
 
 			source := tosca.Address{1}
 			ctxt := context{
+				gasSchedule: tosca.EthereumGasSchedule{},
 				params: tosca.Parameters{
 					BlockParameters: tosca.BlockParameters{
 						Revision: test.revision,
@@ -440,9 +451,9 @@ func TestCreateShanghaiInitCodeSize(t *testing.T) {
 			}
 
 			// Prepare stack arguments.
-			ctxt.stack.push(uint256.NewInt(test.init_code_size))
-			ctxt.stack.push(uint256.NewInt(0))
-			ctxt.stack.push(uint256.NewInt(0))
+			ctxt.stack.Push(uint256.NewInt(test.init_code_size))
+			ctxt.stack.Push(uint256.NewInt(0))
+			ctxt.stack.Push(uint256.NewInt(0))
 
 			if test.expecedErr == nil {
 				runContext.EXPECT().Call(tosca.Create, gomock.Any()).Return(tosca.CallResult{}, nil)
@@ -494,6 +505,7 @@ func TestCreateShanghaiDeploymentCost(t *testing.T) {
 
 		source := tosca.Address{1}
 		ctxt := context{
+			gasSchedule: tosca.EthereumGasSchedule{},
 			params: tosca.Parameters{
 				BlockParameters: tosca.BlockParameters{
 					Revision: test.revision,
@@ -507,9 +519,9 @@ func TestCreateShanghaiDeploymentCost(t *testing.T) {
 		}
 
 		// Prepare stack arguments.
-		ctxt.stack.push(uint256.NewInt(test.initCodeSize))
-		ctxt.stack.push(uint256.NewInt(0))
-		ctxt.stack.push(uint256.NewInt(0))
+		ctxt.stack.Push(uint256.NewInt(test.initCodeSize))
+		ctxt.stack.Push(uint256.NewInt(0))
+		ctxt.stack.Push(uint256.NewInt(0))
 
 		runContext.EXPECT().Call(tosca.Create, gomock.Any()).Return(tosca.CallResult{}, nil)
 
@@ -575,6 +587,7 @@ func TestTransientStorageOperations(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			ctxt := context{
+				gasSchedule: tosca.EthereumGasSchedule{},
 				params: tosca.Parameters{
 					BlockParameters: tosca.BlockParameters{
 						Revision: test.revision,
@@ -663,15 +676,6 @@ func TestGenericDataCopy_ReturnsErrorOn(t *testing.T) {
 	}
 }
 
-func TestGetAccessCost_RespondsWithProperGasPrice(t *testing.T) {
-	if want, got := tosca.Gas(100), getAccessCost(tosca.WarmAccess); want != got {
-		t.Errorf("unexpected gas cost, wanted %d, got %d", want, got)
-	}
-	if want, got := tosca.Gas(2600), getAccessCost(tosca.ColdAccess); want != got {
-		t.Errorf("unexpected gas cost, wanted %d, got %d", want, got)
-	}
-}
-
 func TestCall_ChargesNothingForColdAccessBeforeBerlin(t *testing.T) {
 	zero := *uint256.NewInt(0)
 	one := *uint256.NewInt(1)
@@ -679,6 +683,7 @@ func TestCall_ChargesNothingForColdAccessBeforeBerlin(t *testing.T) {
 	runContext := tosca.NewMockRunContext(ctrl)
 	runContext.EXPECT().Call(tosca.Call, gomock.Any()).Return(tosca.CallResult{}, nil)
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			BlockParameters: tosca.BlockParameters{
 				Revision: tosca.R07_Istanbul,
@@ -712,6 +717,7 @@ func TestCall_ChargesForAccessAfterBerlin(t *testing.T) {
 		runContext.EXPECT().Call(tosca.Call, gomock.Any()).Return(tosca.CallResult{}, nil)
 		delta := tosca.Gas(1)
 		ctxt := context{
+			gasSchedule: tosca.EthereumGasSchedule{},
 			params: tosca.Parameters{
 				BlockParameters: tosca.BlockParameters{
 					Revision: tosca.R09_Berlin,
@@ -799,7 +805,7 @@ func TestSelfDestruct_NewAccountCost(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			cost := selfDestructNewAccountCost(test.beneficiaryEmpty, test.balance)
+			cost := selfDestructNewAccountCost(tosca.EthereumGasSchedule{}, tosca.R13_Cancun, test.beneficiaryEmpty, test.balance)
 			if cost != test.cost {
 				t.Errorf("unexpected gas, wanted %d, got %d", test.cost, cost)
 			}
@@ -807,6 +813,60 @@ func TestSelfDestruct_NewAccountCost(t *testing.T) {
 	}
 }
 
+type cheapNewAccountSchedule struct {
+	tosca.EthereumGasSchedule
+}
+
+func (cheapNewAccountSchedule) NewAccountCost(tosca.Revision) tosca.Gas {
+	return 1000
+}
+
+func TestGenericCall_ValueTransfersToDeadAccountsChargeConfiguredNewAccountCost(t *testing.T) {
+	self := tosca.Address{1}
+	target := tosca.Address{2}
+	tests := map[string]struct {
+		schedule      tosca.GasSchedule
+		targetBalance tosca.Value
+		want          tosca.Gas
+	}{
+		"dead account":                      {schedule: tosca.EthereumGasSchedule{}, want: CallValueTransferGas + 25000},
+		"dead account with custom cost":     {schedule: cheapNewAccountSchedule{}, want: CallValueTransferGas + 1000},
+		"non-empty account":                 {schedule: tosca.EthereumGasSchedule{}, targetBalance: tosca.NewValue(1), want: CallValueTransferGas},
+		"non-empty account custom schedule": {schedule: cheapNewAccountSchedule{}, targetBalance: tosca.NewValue(1), want: CallValueTransferGas},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runContext := tosca.NewMockRunContext(gomock.NewController(t))
+			runContext.EXPECT().GetBalance(self).Return(tosca.NewValue(10)).AnyTimes()
+			runContext.EXPECT().GetBalance(target).Return(test.targetBalance).AnyTimes()
+			runContext.EXPECT().GetNonce(target).Return(uint64(0)).AnyTimes()
+			runContext.EXPECT().GetCodeSize(target).Return(0).AnyTimes()
+			runContext.EXPECT().Call(tosca.Call, gomock.Any()).Return(tosca.CallResult{Success: true}, nil)
+
+			const gas = tosca.Gas(50_000)
+			ctxt := getEmptyContext()
+			ctxt.context = runContext
+			ctxt.gasSchedule = test.schedule
+			ctxt.params.Revision = tosca.R07_Istanbul
+			ctxt.params.Recipient = self
+			ctxt.gas = gas
+
+			zero := *uint256.NewInt(0)
+			one := *uint256.NewInt(1)
+			address := *new(uint256.Int).SetBytes20(target[:])
+			ctxt.stack = fillStack(zero, address, one, zero, zero, zero, zero)
+
+			if err := genericCall(&ctxt, tosca.Call); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := test.want, gas-ctxt.gas; want != got {
+				t.Errorf("unexpected gas costs, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
 func TestSelfDestruct_ExistingAccountToNewBeneficiary(t *testing.T) {
 	// This tests produces the combination of context calls/results for the maximum dynamic gas cost possible.
 
@@ -822,9 +882,10 @@ func TestSelfDestruct_ExistingAccountToNewBeneficiary(t *testing.T) {
 	runContext.EXPECT().GetNonce(beneficiaryAddress)
 	runContext.EXPECT().GetCodeSize(beneficiaryAddress)
 	runContext.EXPECT().GetBalance(selfAddress).Return(tosca.Value{1})
-	runContext.EXPECT().SelfDestruct(selfAddress, beneficiaryAddress).Return(true)
+	runContext.EXPECT().HasSelfDestructed(selfAddress).Return(false)
 
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			BlockParameters: tosca.BlockParameters{
 				Revision: tosca.R13_Cancun,
@@ -837,7 +898,7 @@ func TestSelfDestruct_ExistingAccountToNewBeneficiary(t *testing.T) {
 		// 25_000 for new account, 2_600 for beneficiary access
 		gas: 27_600 + gasDelta,
 	}
-	ctxt.stack.push(new(uint256.Int).SetBytes(beneficiaryAddress[:]))
+	ctxt.stack.Push(new(uint256.Int).SetBytes(beneficiaryAddress[:]))
 
 	status, err := opSelfdestruct(&ctxt)
 	if err != nil {
@@ -849,6 +910,10 @@ func TestSelfDestruct_ExistingAccountToNewBeneficiary(t *testing.T) {
 	if ctxt.gas != gasDelta {
 		t.Errorf("unexpected remaining gas, wanted %v, got %d", gasDelta, ctxt.gas)
 	}
+	want := tosca.SelfDestruction{Beneficiary: beneficiaryAddress, Value: tosca.Value{1}}
+	if ctxt.selfDestruction == nil || *ctxt.selfDestruction != want {
+		t.Errorf("unexpected self-destruction, wanted %v, got %v", want, ctxt.selfDestruction)
+	}
 }
 
 func TestSelfDestruct_ProperlyReportsNotEnoughGas(t *testing.T) {
@@ -873,6 +938,7 @@ func TestSelfDestruct_ProperlyReportsNotEnoughGas(t *testing.T) {
 				runContext.EXPECT().GetBalance(selfAddress).Return(tosca.Value{1})
 
 				ctxt := context{
+					gasSchedule: tosca.EthereumGasSchedule{},
 					params: tosca.Parameters{
 						BlockParameters: tosca.BlockParameters{
 							Revision: tosca.R13_Cancun,
@@ -891,7 +957,7 @@ func TestSelfDestruct_ProperlyReportsNotEnoughGas(t *testing.T) {
 				}
 				ctxt.gas -= 1
 
-				ctxt.stack.push(new(uint256.Int).SetBytes(beneficiaryAddress[:]))
+				ctxt.stack.Push(new(uint256.Int).SetBytes(beneficiaryAddress[:]))
 
 				_, err := opSelfdestruct(&ctxt)
 				if err != errOutOfGas {
@@ -957,10 +1023,10 @@ func TestGenericCreate_ReportsErrors(t *testing.T) {
 			ctxt.params.Revision = test.revision
 			ctxt.gas = 3
 
-			ctxt.stack.push(uint256.NewInt(0)) // salt
-			ctxt.stack.push(&test.size)
-			ctxt.stack.push(&test.offset)
-			ctxt.stack.push(uint256.NewInt(0)) // value
+			ctxt.stack.Push(uint256.NewInt(0)) // salt
+			ctxt.stack.Push(&test.size)
+			ctxt.stack.Push(&test.offset)
+			ctxt.stack.Push(uint256.NewInt(0)) // value
 
 			err := genericCreate(&ctxt, test.kind)
 			if err != test.expectedError {
@@ -977,9 +1043,9 @@ func TestGenericCreate_ResultIsWrittenToStack(t *testing.T) {
 		runContext.EXPECT().Call(gomock.Any(), gomock.Any()).Return(tosca.CallResult{Success: success, CreatedAddress: CreatedAddress}, nil)
 		ctxt := getEmptyContext()
 		ctxt.context = runContext
-		ctxt.stack.push(uint256.NewInt(0))
-		ctxt.stack.push(uint256.NewInt(0))
-		ctxt.stack.push(uint256.NewInt(0))
+		ctxt.stack.Push(uint256.NewInt(0))
+		ctxt.stack.Push(uint256.NewInt(0))
+		ctxt.stack.Push(uint256.NewInt(0))
 		err := genericCreate(&ctxt, tosca.Create)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
@@ -988,16 +1054,47 @@ func TestGenericCreate_ResultIsWrittenToStack(t *testing.T) {
 		if success {
 			want = new(uint256.Int).SetBytes(CreatedAddress[:])
 		}
-		if got := ctxt.stack.peek(); !want.Eq(got) {
+		if got := ctxt.stack.Peek(); !want.Eq(got) {
 			t.Errorf("unexpected return value, wanted %v, got %v", want, got)
 		}
 	}
 }
 
+func TestGenericCreate_FailedCreationWithoutGasConsumptionKeepsGas(t *testing.T) {
+	// Creations failing before running the init code, for instance due to
+	// a nonce overflow of the creator (EIP-2681), return all the gas
+	// provided to the nested call.
+	for _, kind := range []tosca.CallKind{tosca.Create, tosca.Create2} {
+		runContext := tosca.NewMockRunContext(gomock.NewController(t))
+		runContext.EXPECT().Call(kind, gomock.Any()).DoAndReturn(
+			func(_ tosca.CallKind, params tosca.CallParameters) (tosca.CallResult, error) {
+				return tosca.CallResult{GasLeft: params.Gas}, nil
+			})
+		ctxt := getEmptyContext()
+		ctxt.context = runContext
+		ctxt.gas = 1000
+		for i := 0; i < 4; i++ {
+			ctxt.stack.Push(uint256.NewInt(0))
+		}
+		if err := genericCreate(&ctxt, kind); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := ctxt.stack.Peek(); !got.IsZero() {
+			t.Errorf("unexpected return value, wanted 0, got %v", got)
+		}
+		if want, got := tosca.Gas(1000), ctxt.gas; want != got {
+			t.Errorf("unexpected gas, wanted %d, got %d", want, got)
+		}
+		if ctxt.returnData != nil {
+			t.Errorf("unexpected return data: %v", ctxt.returnData)
+		}
+	}
+}
+
 func TestOpEndWithResult_ReturnsExpectedState(t *testing.T) {
 	c := getEmptyContext()
-	c.stack.push(uint256.NewInt(1))
-	c.stack.push(uint256.NewInt(1))
+	c.stack.Push(uint256.NewInt(1))
+	c.stack.Push(uint256.NewInt(1))
 	c.memory.store = []byte{0x1, 0xff, 0x2}
 
 	err := opEndWithResult(&c)
@@ -1012,8 +1109,8 @@ func TestOpEndWithResult_ReturnsExpectedState(t *testing.T) {
 func TestOpEndWithResult_ReportOverflow(t *testing.T) {
 	overflow64 := new(uint256.Int).Add(uint256.NewInt(math.MaxUint64), uint256.NewInt(math.MaxUint64))
 	c := getEmptyContext()
-	c.stack.push(overflow64)
-	c.stack.push(overflow64)
+	c.stack.Push(overflow64)
+	c.stack.Push(overflow64)
 	c.memory.store = []byte{0x1, 0xff, 0x2}
 	err := opEndWithResult(&c)
 	if err != errOverflow {
@@ -1063,6 +1160,7 @@ func TestInstructions_EIP2929_dynamicGasCostReportsOutOfGas(t *testing.T) {
 			for _, access := range []tosca.AccessStatus{tosca.WarmAccess, tosca.ColdAccess} {
 				t.Run(fmt.Sprintf("%v/%v/%v", op, revision, access), func(t *testing.T) {
 					ctxt := context{
+						gasSchedule: tosca.EthereumGasSchedule{},
 						params: tosca.Parameters{
 							BlockParameters: tosca.BlockParameters{
 								Revision: revision,
@@ -1082,7 +1180,7 @@ func TestInstructions_EIP2929_dynamicGasCostReportsOutOfGas(t *testing.T) {
 					mockRunContext.EXPECT().AccessStorage(gomock.Any(), gomock.Any()).Return(access).AnyTimes()
 					mockRunContext.EXPECT().AccessAccount(gomock.Any()).Return(access).AnyTimes()
 					ctxt.context = mockRunContext
-					ctxt.stack.stackPointer = 7
+					ctxt.stack.SetLen(7)
 
 					err := implementation(&ctxt)
 					if err != errOutOfGas {
@@ -1113,6 +1211,7 @@ func TestInstructions_EIP2929_SSTOREReportsOutOfGas(t *testing.T) {
 					t.Run(fmt.Sprintf("%v/%v/%v/%v", SSTORE, revision, access, storageStatus), func(t *testing.T) {
 
 						ctxt := context{
+							gasSchedule: tosca.EthereumGasSchedule{},
 							params: tosca.Parameters{
 								BlockParameters: tosca.BlockParameters{
 									Revision: revision,
@@ -1125,8 +1224,8 @@ func TestInstructions_EIP2929_SSTOREReportsOutOfGas(t *testing.T) {
 						mockRunContext.EXPECT().AccessStorage(gomock.Any(), gomock.Any()).Return(access).AnyTimes()
 						mockRunContext.EXPECT().SetStorage(gomock.Any(), gomock.Any(), gomock.Any()).Return(storageStatus).AnyTimes()
 						ctxt.context = mockRunContext
-						ctxt.stack.push(uint256.NewInt(1))
-						ctxt.stack.push(uint256.NewInt(1))
+						ctxt.stack.Push(uint256.NewInt(1))
+						ctxt.stack.Push(uint256.NewInt(1))
 
 						err := opSstore(&ctxt)
 						if err != errOutOfGas {
@@ -1192,7 +1291,7 @@ func TestInstructions_StorageOps_CallStorageContext(t *testing.T) {
 				}
 
 				if op == SLOAD {
-					if got := ctxt.stack.peek(); got.Cmp(new(uint256.Int).SetBytes(value[:])) != 0 {
+					if got := ctxt.stack.Peek(); got.Cmp(new(uint256.Int).SetBytes(value[:])) != 0 {
 						t.Errorf("unexpected return value, wanted %v, got %v", value, got)
 					}
 				}
@@ -1248,7 +1347,7 @@ func TestInstructions_JumpOpsCheckJUMPDEST(t *testing.T) {
 			ctxt := getEmptyContext()
 			ctxt.code = Code{{op, 0}}
 			for _, v := range test.stack {
-				ctxt.stack.push(uint256.NewInt(v))
+				ctxt.stack.Push(uint256.NewInt(v))
 			}
 
 			err := test.implementation(&ctxt)
@@ -1298,38 +1397,47 @@ func TestInstructions_ConditionalJumpOpsIgnoreDestinationWhenJumpNotTaken(t *tes
 }
 
 func TestInstructions_JumpOpsReturnErrorWithJumpDestinationOutOfBounds(t *testing.T) {
+	// Destinations at and beyond the limits of the 32-bit program counter
+	// must be rejected without wrapping around into the code.
+	destinations := []uint64{
+		math.MaxInt32,
+		math.MaxInt32 + 1,
+		math.MaxUint32,
+		math.MaxUint32 + 1,
+		math.MaxUint64,
+	}
 	tests := map[OpCode]struct {
 		implementation func(*context) error
-		stack          []uint256.Int
+		stack          func(destination uint64) []uint256.Int
 	}{
 		JUMP: {
 			implementation: opJump,
-			stack: []uint256.Int{
-				*uint256.NewInt(math.MaxInt32 + 1),
+			stack: func(destination uint64) []uint256.Int {
+				return []uint256.Int{*uint256.NewInt(destination)}
 			},
 		},
 		JUMPI: {
 			implementation: opJumpi,
-			stack: []uint256.Int{
-				*uint256.NewInt(math.MaxInt32 + 1),
-				*uint256.NewInt(1),
+			stack: func(destination uint64) []uint256.Int {
+				return []uint256.Int{*uint256.NewInt(destination), *uint256.NewInt(1)}
 			},
 		},
 	}
 
 	for op, test := range tests {
-		t.Run(op.String(), func(t *testing.T) {
-			ctxt := getEmptyContext()
-			ctxt.code = Code{{op, 0}}
-			ctxt.stack = fillStack(test.stack...)
+		for _, destination := range destinations {
+			t.Run(fmt.Sprintf("%v/%d", op, destination), func(t *testing.T) {
+				ctxt := getEmptyContext()
+				ctxt.code = Code{{op, 0}}
+				ctxt.stack = fillStack(test.stack(destination)...)
 
-			err := test.implementation(&ctxt)
-			if want, got := errInvalidJump, err; want != got {
-				t.Fatalf("unexpected error, wanted %v, got %v", want, got)
-			}
-		})
+				err := test.implementation(&ctxt)
+				if want, got := errInvalidJump, err; want != got {
+					t.Fatalf("unexpected error, wanted %v, got %v", want, got)
+				}
+			})
+		}
 	}
-
 }
 
 func TestGetData(t *testing.T) {
@@ -1443,13 +1551,13 @@ func TestGenericCall_ProperlyReportsErrors(t *testing.T) {
 				return i
 			}
 
-			ctxt.stack.push(getValueOrZeroOf(test.retSize))
-			ctxt.stack.push(getValueOrZeroOf(test.retOffset))
-			ctxt.stack.push(getValueOrZeroOf(test.inSize))
-			ctxt.stack.push(getValueOrZeroOf(test.inOffset))
-			ctxt.stack.push(getValueOrZeroOf(test.value))
-			ctxt.stack.push(uint256.NewInt(0).SetBytes20(address[:]))
-			ctxt.stack.push(getValueOrZeroOf(test.provided_gas))
+			ctxt.stack.Push(getValueOrZeroOf(test.retSize))
+			ctxt.stack.Push(getValueOrZeroOf(test.retOffset))
+			ctxt.stack.Push(getValueOrZeroOf(test.inSize))
+			ctxt.stack.Push(getValueOrZeroOf(test.inOffset))
+			ctxt.stack.Push(getValueOrZeroOf(test.value))
+			ctxt.stack.Push(uint256.NewInt(0).SetBytes20(address[:]))
+			ctxt.stack.Push(getValueOrZeroOf(test.provided_gas))
 
 			err := genericCall(&ctxt, tosca.Call)
 
@@ -1490,7 +1598,7 @@ func TestGenericCall_ResultIsWrittenToStack(t *testing.T) {
 		if success {
 			want = uint256.NewInt(1)
 		}
-		if got := ctxt.stack.data[0]; !want.Eq(&got) {
+		if got := *ctxt.stack.Get(0); !want.Eq(&got) {
 			t.Errorf("unexpected return value, wanted %v, got %v", want, got)
 		}
 	}
@@ -1511,7 +1619,7 @@ func TestGenericCall_HandlesBigProvidedGasValues(t *testing.T) {
 			nestedGas := tosca.Gas(gas - gas/64)
 			runContext := tosca.NewMockRunContext(gomock.NewController(t))
 			runContext.EXPECT().Call(tosca.Call, tosca.CallParameters{Gas: nestedGas}).Return(tosca.CallResult{}, nil)
-			ctxt := context{gas: gas}
+			ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: gas}
 			ctxt.context = runContext
 			ctxt.stack = fillStack(providedGas, zero, zero, zero, zero, zero, zero)
 
@@ -1697,11 +1805,12 @@ func TestInstructions_ComparisonAndShiftOperations(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			ctxt := context{
-				stack: test.stackInputs,
+				gasSchedule: tosca.EthereumGasSchedule{},
+				stack:       test.stackInputs,
 			}
 
 			test.opImplementation(&ctxt)
-			result := ctxt.stack.pop()
+			result := ctxt.stack.Pop()
 			if result.Cmp(&test.expectedOutput) != 0 {
 				t.Errorf("unexpected result, wanted %d, got %d", test.expectedOutput, result)
 			}
@@ -1750,14 +1859,14 @@ func TestInstructions_opExtcodesize_CallsContextAndWritesResultInStack(t *testin
 	ctxt := getEmptyContext()
 	ctxt.context = runContext
 
-	ctxt.stack.push(new(uint256.Int).SetBytes(address[:]))
+	ctxt.stack.Push(new(uint256.Int).SetBytes(address[:]))
 
 	err := opExtcodesize(&ctxt)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if want, got := uint256.NewInt(1234), ctxt.stack.pop(); want.Cmp(got) != 0 {
+	if want, got := uint256.NewInt(1234), ctxt.stack.Pop(); want.Cmp(got) != 0 {
 		t.Errorf("unexpected result, wanted %v, got %v", want, got)
 	}
 }
@@ -1829,7 +1938,7 @@ func TestOpBlockhash(t *testing.T) {
 
 					opBlockhash(&ctxt)
 
-					if want, got := new(uint256.Int).SetBytes(test.expectedValue[:]), ctxt.stack.pop(); want.Cmp(got) != 0 {
+					if want, got := new(uint256.Int).SetBytes(test.expectedValue[:]), ctxt.stack.Pop(); want.Cmp(got) != 0 {
 						t.Errorf("unexpected result, wanted %v, got %v", want, got)
 					}
 				})
@@ -1837,6 +1946,30 @@ func TestOpBlockhash(t *testing.T) {
 		})
 	}
 }
+
+func TestOpBlockhash_DoesNotReadHistoryStorageContract(t *testing.T) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		t.Run(revision.String(), func(t *testing.T) {
+			ctxt := getEmptyContext()
+			ctxt.params.Revision = revision
+			ctxt.params.BlockNumber = 5000
+			ctxt.stack = fillStack(*uint256.NewInt(4990))
+
+			// The strict mock fails on any unexpected GetStorage call.
+			runContext := tosca.NewMockRunContext(gomock.NewController(t))
+			runContext.EXPECT().GetBlockHash(int64(4990)).Return(tosca.Hash{1})
+			ctxt.context = runContext
+
+			opBlockhash(&ctxt)
+
+			hash := tosca.Hash{1}
+			if want, got := new(uint256.Int).SetBytes(hash[:]), ctxt.stack.Pop(); want.Cmp(got) != 0 {
+				t.Errorf("unexpected result, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
 func TestInstructions_ReturnDataCopy_ReturnsErrorOn(t *testing.T) {
 
 	zero := *uint256.NewInt(0)
@@ -1877,25 +2010,25 @@ func TestInstructions_ReturnDataCopy_ReturnsErrorOn(t *testing.T) {
 }
 
 func TestOpExp_ProducesCorrectResults(t *testing.T) {
-	ctxt := context{gas: tosca.Gas(uint256.NewInt(8).ByteLen() * 50)}
+	ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: tosca.Gas(uint256.NewInt(8).ByteLen() * 50)}
 	ctxt.stack = NewStack()
-	ctxt.stack.push(uint256.NewInt(8)) // exponent
-	ctxt.stack.push(uint256.NewInt(2)) // base
+	ctxt.stack.Push(uint256.NewInt(8)) // exponent
+	ctxt.stack.Push(uint256.NewInt(2)) // base
 	err := opExp(&ctxt)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 	expected := uint256.NewInt(256)
-	if got := ctxt.stack.pop(); got.Cmp(expected) != 0 {
+	if got := ctxt.stack.Pop(); got.Cmp(expected) != 0 {
 		t.Errorf("unexpected result, wanted %v, got %v", expected, got)
 	}
 }
 
 func TestOpExp_ReportsOutOfGas(t *testing.T) {
-	ctxt := context{gas: 3}
+	ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 3}
 	ctxt.stack = NewStack()
-	ctxt.stack.push(uint256.NewInt(256)) // exponent
-	ctxt.stack.push(uint256.NewInt(2))   // base
+	ctxt.stack.Push(uint256.NewInt(256)) // exponent
+	ctxt.stack.Push(uint256.NewInt(2))   // base
 	err := opExp(&ctxt)
 	if err != errOutOfGas {
 		t.Errorf("expected out of gas error, got %v", err)
@@ -1919,11 +2052,11 @@ func TestInstructions_Sha3_ReportsOutOfGas(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			ctxt := context{gas: 3}
+			ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 3}
 			ctxt.memory = NewMemory()
 			ctxt.stack = NewStack()
-			ctxt.stack.push(uint256.NewInt(test.size))
-			ctxt.stack.push(uint256.NewInt(0))
+			ctxt.stack.Push(uint256.NewInt(test.size))
+			ctxt.stack.Push(uint256.NewInt(0))
 			err := opSha3(&ctxt)
 			if err != test.expectedError {
 				t.Fatalf("unexpected error, wanted %v, got %v", test.expectedError, err)
@@ -1939,15 +2072,17 @@ func TestInstructions_Sha3_WritesCorrectHashInStack(t *testing.T) {
 	for _, withShaCache := range []bool{true, false} {
 		t.Run(fmt.Sprintf("withShaCache:%v", withShaCache), func(t *testing.T) {
 			ctxt := getEmptyContext()
-			ctxt.withShaCache = withShaCache
-			ctxt.stack.push(uint256.NewInt(1))
-			ctxt.stack.push(uint256.NewInt(0))
+			if withShaCache {
+				ctxt.shaCache = newSha3HashCache(10, 10)
+			}
+			ctxt.stack.Push(uint256.NewInt(1))
+			ctxt.stack.Push(uint256.NewInt(0))
 
 			err := opSha3(&ctxt)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			got := ctxt.stack.pop()
+			got := ctxt.stack.Pop()
 			if !bytes.Equal(got.Bytes(), want[:]) {
 				t.Errorf("unexpected hash wanted %x, got %x", want, got.Bytes())
 			}
@@ -1995,7 +2130,7 @@ func TestOpExtCodeHash_WritesHashOnStackIfAccountExists(t *testing.T) {
 			if test.accountEmpty {
 				want = []byte{}
 			}
-			if got := ctxt.stack.pop().Bytes(); !bytes.Equal(want, got) {
+			if got := ctxt.stack.Pop().Bytes(); !bytes.Equal(want, got) {
 				t.Errorf("unexpected result, wanted %v, got %v", want, got)
 			}
 		})
@@ -2028,17 +2163,17 @@ func TestInstructions_opLog(t *testing.T) {
 
 				ctxt := getEmptyContext()
 				for i := n - 1; i >= 0; i-- {
-					ctxt.stack.push(uint256.NewInt(uint64(i)))
+					ctxt.stack.Push(uint256.NewInt(uint64(i)))
 				}
-				ctxt.stack.push(test.size)
-				ctxt.stack.push(test.offset)
+				ctxt.stack.Push(test.size)
+				ctxt.stack.Push(test.offset)
 				ctxt.gas = tosca.Gas(test.size.Uint64()*8 - test.reduceGas)
 				ctxt.params.Recipient = tosca.Address{1}
 				// ignore the expansion error to focus on log operation
 				// this expansion is done to remove expansion costs (if any) and
 				// test word count cost only.
 				memoryContents := []byte{0, 1, 2, 3}
-				_ = ctxt.memory.set(uint256.NewInt(0), memoryContents, &context{gas: math.MaxInt64})
+				_ = ctxt.memory.set(uint256.NewInt(0), memoryContents, &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: math.MaxInt64})
 
 				if test.expectedError == nil {
 					runContext := tosca.NewMockRunContext(gomock.NewController(t))
@@ -2089,7 +2224,7 @@ func TestInstructions_MCopy_DoesNothingWithSizeZero(t *testing.T) {
 	err := ctxt.memory.set(
 		uint256.NewInt(0),
 		data[:],
-		&context{gas: 1 << 32},
+		&context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 1 << 32},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -2140,8 +2275,8 @@ func TestInstructions_MCopy_ReturnsErrorOnFailure(t *testing.T) {
 			// ignore memory setup errors, to focus on the mcopy operation
 			// expansion is done to accumulate memory cost and focus on the
 			// word count gas cost.
-			_ = ctxt.memory.expandMemory(test.destOffset, test.size, &context{gas: 1 << 32})
-			_ = ctxt.memory.expandMemory(test.offset, test.size, &context{gas: 1 << 32})
+			_ = ctxt.memory.expandMemory(test.destOffset, test.size, &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 1 << 32})
+			_ = ctxt.memory.expandMemory(test.offset, test.size, &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 1 << 32})
 			ctxt.gas = tosca.Gas(3*tosca.SizeInWords(test.size) - test.gasRemoved)
 
 			err := opMcopy(&ctxt)
@@ -2164,7 +2299,7 @@ func TestInstructions_MCopy_CopiesOverlappingRanges(t *testing.T) {
 	err := ctxt.memory.set(
 		uint256.NewInt(0),
 		[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
-		&context{gas: 1 << 32},
+		&context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 1 << 32},
 	)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
@@ -2184,13 +2319,150 @@ func TestInstructions_MCopy_CopiesOverlappingRanges(t *testing.T) {
 
 func TestInstructions_ReturnDataCopy_ReturnsOutOfGas(t *testing.T) {
 	zero := *uint256.NewInt(0)
-	ctxt := context{gas: 3, stack: fillStack(zero, zero, *uint256.NewInt(65))}
+	ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 3, stack: fillStack(zero, zero, *uint256.NewInt(65))}
 	err := opReturnDataCopy(&ctxt)
 	if err != errOutOfGas {
 		t.Fatalf("expected overflow error, got %v", err)
 	}
 }
 
+// arithmeticOperations lists the instructions operating on the top elements
+// of the stack only. Their results are computed in place, in the stack slot
+// of the last consumed operand.
+var arithmeticOperations = map[string]func(*context){
+	"ADD":        opAdd,
+	"SUB":        opSub,
+	"MUL":        opMul,
+	"DIV":        opDiv,
+	"SDIV":       opSDiv,
+	"MOD":        opMod,
+	"SMOD":       opSMod,
+	"ADDMOD":     opAddMod,
+	"MULMOD":     opMulMod,
+	"EXP":        func(c *context) { _ = opExp(c) },
+	"SIGNEXTEND": opSignExtend,
+	"LT":         opLt,
+	"GT":         opGt,
+	"SLT":        opSlt,
+	"SGT":        opSgt,
+	"EQ":         opEq,
+	"ISZERO":     opIszero,
+	"AND":        opAnd,
+	"OR":         opOr,
+	"XOR":        opXor,
+	"NOT":        opNot,
+	"BYTE":       opByte,
+	"SHL":        opShl,
+	"SHR":        opShr,
+	"SAR":        opSar,
+}
+
+// arithmeticOperands are large operands, exercising the slow paths of the
+// arithmetic operations.
+var arithmeticOperands = []*uint256.Int{
+	uint256.MustFromHex("0xfedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"),
+	uint256.MustFromHex("0x123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+	uint256.MustFromHex("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffff7fffffff"),
+}
+
+func TestInstructions_ArithmeticOperationsDoNotAllocate(t *testing.T) {
+	for name, op := range arithmeticOperations {
+		t.Run(name, func(t *testing.T) {
+			c := context{gasSchedule: tosca.EthereumGasSchedule{}, stack: NewStack(), gas: math.MaxInt64}
+			defer ReturnStack(c.stack)
+			allocs := testing.AllocsPerRun(100, func() {
+				c.stack.SetLen(0)
+				for _, operand := range arithmeticOperands {
+					c.stack.Push(operand)
+				}
+				op(&c)
+			})
+			if allocs != 0 {
+				t.Errorf("unexpected allocations, wanted 0, got %v", allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkArithmeticOperations(b *testing.B) {
+	for name, op := range arithmeticOperations {
+		b.Run(name, func(b *testing.B) {
+			c := context{gasSchedule: tosca.EthereumGasSchedule{}, stack: NewStack(), gas: math.MaxInt64}
+			defer ReturnStack(c.stack)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.stack.SetLen(0)
+				for _, operand := range arithmeticOperands {
+					c.stack.Push(operand)
+				}
+				op(&c)
+			}
+		})
+	}
+}
+
+func BenchmarkArithmeticHeavyCode(b *testing.B) {
+	code := convert(getExpMulModLoopCode(1000), ConversionConfig{})
+	params := tosca.Parameters{Gas: 1 << 40}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		result, err := run(config{}, params, code)
+		if err != nil || !result.Success {
+			b.Fatalf("execution failed, result %v, error %v", result, err)
+		}
+	}
+}
+
+// getExpMulModLoopCode returns EVM code running the given number of
+// iterations of a loop computing a MULMOD and an EXP of large values.
+func getExpMulModLoopCode(iterations uint16) []byte {
+	push32 := func(value *uint256.Int) []byte {
+		word := value.Bytes32()
+		return append([]byte{byte(vm.PUSH32)}, word[:]...)
+	}
+	code := []byte{byte(vm.PUSH2), byte(iterations >> 8), byte(iterations)}
+	loop := len(code)
+	code = append(code, byte(vm.JUMPDEST))
+	code = append(code, push32(arithmeticOperands[2])...) // < modulus
+	code = append(code, push32(arithmeticOperands[1])...)
+	code = append(code, push32(arithmeticOperands[0])...)
+	code = append(code, byte(vm.MULMOD))
+	code = append(code, push32(arithmeticOperands[0])...) // < base
+	code = append(code, byte(vm.EXP), byte(vm.POP))
+	// Decrement the counter and loop while it is not zero.
+	code = append(code,
+		byte(vm.PUSH1), 1, byte(vm.SWAP1), byte(vm.SUB),
+		byte(vm.DUP1), byte(vm.PUSH1), byte(loop), byte(vm.JUMPI),
+		byte(vm.STOP),
+	)
+	return code
+}
+
+func TestGetExpMulModLoopCode_RunsRequestedIterations(t *testing.T) {
+	code := convert(getExpMulModLoopCode(10), ConversionConfig{})
+	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        code,
+		stack:       NewStack(),
+		memory:      NewMemory(),
+		gas:         1 << 40,
+	}
+	defer ReturnStack(ctxt.stack)
+	if status := execute(&ctxt, false); status != statusStopped {
+		t.Fatalf("unexpected status, wanted %v, got %v", statusStopped, status)
+	}
+	// Each iteration costs 3 PUSH32 (3 gas), MULMOD (8), PUSH32, EXP with a
+	// 32-byte exponent (10+50*32), POP (2), and the loop control (26), which
+	// includes the JUMPDEST. The initial PUSH2 costs 3.
+	wantGas := tosca.Gas(3 + 10*(3*3+8+3+10+50*32+2+26))
+	if got := tosca.Gas(1<<40) - ctxt.gas; got != wantGas {
+		t.Errorf("unexpected gas usage, wanted %d, got %d", wantGas, got)
+	}
+	if ctxt.stack.Len() != 1 || !ctxt.stack.Peek().IsZero() {
+		t.Errorf("unexpected final stack, wanted a zero counter, got %v", ctxt.stack)
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Helper functions
 
@@ -2199,7 +2471,7 @@ func TestInstructions_ReturnDataCopy_ReturnsOutOfGas(t *testing.T) {
 func fillStack(values ...uint256.Int) *stack {
 	s := NewStack()
 	for i := len(values) - 1; i >= 0; i-- {
-		s.push(&values[i])
+		s.Push(&values[i])
 	}
 	return s
 }
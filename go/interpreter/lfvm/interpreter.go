@@ -12,8 +12,11 @@ package lfvm
 
 import (
 	"fmt"
+	"math"
+	"sync"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
 //go:generate mockgen -source interpreter.go -destination interpreter_mock.go -package lfvm
@@ -27,9 +30,18 @@ const (
 	statusReverted                     // < execution stopped with a REVERT
 	statusReturned                     // < execution stopped with a RETURN
 	statusSelfDestructed               // < execution stopped with a SELF-DESTRUCT
+	statusInterrupted                  // < execution was aborted through an interrupt
 	statusFailed                       // < execution stopped with a logic error
 )
 
+// interruptCheckInterval is the number of instructions executed between two
+// consecutive checks of the interrupt signal of an execution.
+const interruptCheckInterval = 1 << 10
+
+// maxCodeLength is the maximum number of instructions of a code that can be
+// executed, limited by the range of the 32-bit program counter.
+const maxCodeLength = math.MaxInt32
+
 // context is the execution environment of an interpreter run. It contains all
 // the necessary state to execute a contract, including input parameters, the
 // contract code, and internal execution state such as the program counter,
@@ -48,10 +60,19 @@ type context struct {
 	memory *Memory
 
 	// Intermediate data
-	returnData []byte // < the result of the last nested contract call
-
-	// Configuration flags
-	withShaCache bool
+	returnData      []byte                  // < the result of the last nested contract call
+	maxStackHeight  int                     // < only tracked if resource usage is requested
+	selfDestruction *tosca.SelfDestruction  // < set by a SELFDESTRUCT ending the execution
+	failure         *tosca.ExecutionFailure // < only recorded if diagnostics are enabled
+
+	// Interrupt handling
+	interrupt                <-chan struct{} // < closed if the execution is to be aborted, nil if not interruptible
+	stepsUntilInterruptCheck int             // < number of instructions to be executed before the next check
+
+	// Configuration
+	shaCache    *sha3HashCache    // < nil if SHA3 hashes are not to be cached
+	gasSchedule tosca.GasSchedule // < the schedule of dynamic gas costs, never nil
+	diagnostics bool              // < true if failures are to be recorded
 }
 
 // useGas reduces the gas level by the given amount. If the gas level drops
@@ -66,6 +87,26 @@ func (c *context) useGas(amount tosca.Gas) error {
 	return nil
 }
 
+// isInterrupted returns true if the execution of the current context is to be
+// aborted. To keep the overhead low, the interrupt signal is only checked in
+// intervals of interruptCheckInterval instructions.
+func (c *context) isInterrupted() bool {
+	if c.interrupt == nil {
+		return false
+	}
+	if c.stepsUntilInterruptCheck > 0 {
+		c.stepsUntilInterruptCheck--
+		return false
+	}
+	c.stepsUntilInterruptCheck = interruptCheckInterval
+	select {
+	case <-c.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
 // isAtLeast returns true if the interpreter is is running at least at the given
 // revision or newer, false otherwise.
 func (c *context) isAtLeast(revision tosca.Revision) bool {
@@ -97,38 +138,92 @@ func run(
 		}, nil
 	}
 
-	// Set up execution context.
-	var ctxt = context{
-		params:       params,
-		context:      params.Context,
-		gas:          params.Gas,
-		stack:        NewStack(),
-		memory:       NewMemory(),
-		code:         code,
-		withShaCache: config.WithShaCache,
+	if err := checkCodeLength(len(code)); err != nil {
+		return tosca.Result{}, err
+	}
+
+	// Set up execution context. Contexts are recycled, since a new one is
+	// needed for each nested call.
+	ctxt := contextPool.Get().(*context)
+	*ctxt = context{
+		params:      params,
+		context:     params.Context,
+		gas:         params.Gas,
+		stack:       NewStack(),
+		memory:      NewMemory(),
+		code:        code,
+		shaCache:    config.getShaCache(),
+		gasSchedule: config.getGasSchedule(),
+		diagnostics: config.Diagnostics,
+	}
+	if params.Interrupt != nil {
+		ctxt.interrupt = params.Interrupt.Done()
 	}
-	defer ReturnStack(ctxt.stack)
+	// The output of RETURN and REVERT refers to the memory of the execution.
+	// Instead of copying it, the memory buffer is handed over to the caller.
+	outputInMemory := false
+	defer func() { releaseContext(ctxt, outputInMemory) }()
 
 	if config.runner == nil {
 		config.runner = vanillaRunner{}
 	}
-	status, err := config.runner.run(&ctxt)
+	status, err := config.runner.run(ctxt)
 	if err != nil {
 		return tosca.Result{}, err
 	}
 
-	return generateResult(status, &ctxt)
+	if usage := params.ResourceUsage; usage != nil {
+		usage.Update(params.Depth, ctxt.maxStackHeight, ctxt.memory.length())
+	}
+
+	result, err := generateResult(status, ctxt)
+	outputInMemory = len(result.Output) > 0
+	return result, err
+}
+
+var contextPool = sync.Pool{
+	New: func() any {
+		return &context{}
+	},
+}
+
+// releaseContext returns the given context, including its stack and memory,
+// to the reuse pools. If outputInMemory is set, the memory buffer is still
+// referenced by the result of the execution and is not recycled.
+func releaseContext(c *context, outputInMemory bool) {
+	ReturnStack(c.stack)
+	ReturnMemory(c.memory, outputInMemory)
+	*c = context{} // < drop references to parameters and results
+	contextPool.Put(c)
+}
+
+// checkCodeLength verifies that all positions of a code of the given length
+// can be addressed by the program counter. Since the program counter is
+// incremented after the last instruction, it needs to be able to represent
+// the length of the code itself without overflowing.
+func checkCodeLength(length int) error {
+	if length > maxCodeLength {
+		return errCodeTooLarge
+	}
+	return nil
 }
 
 func generateResult(status status, ctxt *context) (tosca.Result, error) {
 	// Handle return status
 	switch status {
-	case statusStopped, statusSelfDestructed:
+	case statusStopped:
 		return tosca.Result{
 			Success:   true,
 			GasLeft:   ctxt.gas,
 			GasRefund: ctxt.refund,
 		}, nil
+	case statusSelfDestructed:
+		return tosca.Result{
+			Success:         true,
+			GasLeft:         ctxt.gas,
+			GasRefund:       ctxt.refund,
+			SelfDestruction: ctxt.selfDestruction,
+		}, nil
 	case statusReturned:
 		return tosca.Result{
 			Success:   true,
@@ -145,7 +240,10 @@ func generateResult(status status, ctxt *context) (tosca.Result, error) {
 	case statusFailed:
 		return tosca.Result{
 			Success: false,
+			Failure: ctxt.failure,
 		}, nil
+	case statusInterrupted:
+		return tosca.Result{}, ctxt.params.Interrupt.Err()
 	default:
 		return tosca.Result{}, fmt.Errorf("unexpected error in interpreter, unknown status: %v", status)
 	}
@@ -161,6 +259,20 @@ func (r vanillaRunner) run(c *context) (status, error) {
 	return execute(c, false), nil
 }
 
+// resourceUsageRunner is a runner tracking the maximum stack height of an
+// execution. To observe the stack between all EVM instructions, the executed
+// code has to be converted without super instructions.
+type resourceUsageRunner struct{}
+
+func (r resourceUsageRunner) run(c *context) (status, error) {
+	status := statusRunning
+	for status == statusRunning {
+		status = execute(c, true)
+		c.maxStackHeight = max(c.maxStackHeight, c.stack.Len())
+	}
+	return status, nil
+}
+
 // --- Execution ---
 
 // execute runs the contract code in the given context. If oneStepOnly is true,
@@ -170,11 +282,46 @@ func (r vanillaRunner) run(c *context) (status, error) {
 func execute(c *context, oneStepOnly bool) status {
 	status, error := steps(c, oneStepOnly)
 	if error != nil {
+		c.recordFailure(error)
 		return statusFailed
 	}
 	return status
 }
 
+// recordFailure captures the state of the execution at the instruction
+// failing with the given error if diagnostics are enabled. The program
+// counter is reported as a position in the original EVM code.
+func (c *context) recordFailure(err error) {
+	if !c.diagnostics {
+		return
+	}
+	failure := &tosca.ExecutionFailure{
+		Pc:        uint64(c.pc),
+		GasLeft:   c.gas,
+		StackSize: c.stack.Len(),
+		Cause:     err,
+	}
+	if int(c.pc) < len(c.code) {
+		failure.Operation = c.code[c.pc].opcode.String()
+	}
+	// Failures are rare and diagnostics only used for debugging, so the pc
+	// map is derived on demand instead of being kept with the converted code.
+	if evmCode := c.params.Code; len(evmCode) > 0 {
+		pcMap := genPcMap(evmCode)
+		if int(c.pc) < len(pcMap.lfvmToEvm) {
+			evmPc := pcMap.lfvmToEvm[c.pc]
+			failure.Pc = uint64(evmPc)
+			if int(evmPc) < len(evmCode) {
+				failure.Operation = vm.OpCode(evmCode[evmPc]).String()
+			}
+		}
+	}
+	for i := 0; i < min(c.stack.Len(), tosca.MaxFailureStackSize); i++ {
+		failure.Stack = append(failure.Stack, c.stack.PeekN(i).Bytes32())
+	}
+	c.failure = failure
+}
+
 // steps executes the contract code in the given context,
 // If oneStepOnly is true, only the instruction pointed to by the program
 // counter will be executed.
@@ -189,15 +336,24 @@ func steps(c *context, oneStepOnly bool) (status, error) {
 			return statusStopped, nil
 		}
 
+		if c.isInterrupted() {
+			return statusInterrupted, nil
+		}
+
 		op := c.code[c.pc].opcode
 
+		// Operations of later revisions are rejected before their dispatch.
+		if c.params.Revision < introducedIn.get(op) {
+			return status, errInvalidRevision
+		}
+
 		// Check stack boundary for every instruction
-		if err := checkStackLimits(c.stack.len(), op); err != nil {
+		if err := checkStackLimits(c.stack.Len(), op); err != nil {
 			return status, err
 		}
 
 		// Consume static gas price for instruction before execution
-		if err := c.useGas(staticGasPrices.get(op)); err != nil {
+		if err := c.useGas(mutateStaticGas(op, staticGasPrices.get(op))); err != nil {
 			return status, err
 		}
 
@@ -566,7 +722,7 @@ func steps(c *context, oneStepOnly bool) (status, error) {
 // checkStackLimits checks that the opCode will not make an out of bounds access
 // with the current stack size.
 func checkStackLimits(stackLen int, op OpCode) error {
-	limits := _precomputedStackLimits.get(op)
+	limits := mutateStackLimits(op, _precomputedStackLimits.get(op))
 	if stackLen < limits.min {
 		return errStackUnderflow
 	}
@@ -12,11 +12,13 @@ package lfvm
 
 import (
 	"bytes"
+	gocontext "context"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
 	"reflect"
 	"regexp"
@@ -25,6 +27,7 @@ import (
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 	"github.com/holiman/uint256"
 	"go.uber.org/mock/gomock"
 )
@@ -51,7 +54,8 @@ func TestContext_useGas_ReturnsErrorIfOutOfGasOrNegativeCost(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			ctx := context{
-				gas: test.available,
+				gasSchedule: tosca.EthereumGasSchedule{},
+				gas:         test.available,
 			}
 			err := ctx.useGas(test.required)
 
@@ -78,6 +82,7 @@ func TestContext_isAtLeast_RespectsOrderOfRevisions(t *testing.T) {
 
 	for _, is := range revisions {
 		context := context{
+			gasSchedule: tosca.EthereumGasSchedule{},
 			params: tosca.Parameters{
 				BlockParameters: tosca.BlockParameters{
 					Revision: is,
@@ -112,6 +117,7 @@ func getContext(code Code, data []byte, runContext tosca.RunContext, stackPtr in
 
 	// Create execution context.
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			BlockParameters: tosca.BlockParameters{
 				Revision: revision,
@@ -130,7 +136,7 @@ func getContext(code Code, data []byte, runContext tosca.RunContext, stackPtr in
 	// For the tests using the resulting context the actual
 	// stack content is not relevant. It is merely used for
 	// checking stack over- or under-flows.
-	ctxt.stack.stackPointer = stackPtr
+	ctxt.stack.SetLen(stackPtr)
 
 	return ctxt
 }
@@ -147,6 +153,7 @@ func TestInterpreter_step_DetectsLowerStackLimitViolation(t *testing.T) {
 
 		ctxt := getEmptyContext()
 		ctxt.code = []Instruction{{op, 0}}
+		ctxt.params.Revision = newestSupportedRevision
 
 		_, err := steps(&ctxt, false)
 		if want, got := errStackUnderflow, err; want != got {
@@ -166,7 +173,8 @@ func TestInterpreter_step_DetectsUpperStackLimitViolation(t *testing.T) {
 
 		ctxt := getEmptyContext()
 		ctxt.code = []Instruction{{op, 0}}
-		ctxt.stack.stackPointer = maxStackSize
+		ctxt.params.Revision = newestSupportedRevision
+		ctxt.stack.SetLen(maxStackSize)
 
 		_, err := steps(&ctxt, false)
 		if want, got := errStackOverflow, err; want != got {
@@ -195,11 +203,12 @@ func TestInterpreter_CanDispatchExecutableInstructions(t *testing.T) {
 				mock.EXPECT().SetStorage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 				mock.EXPECT().Call(gomock.Any(), gomock.Any()).AnyTimes()
 				mock.EXPECT().EmitLog(gomock.Any()).AnyTimes()
-				mock.EXPECT().SelfDestruct(gomock.Any(), gomock.Any()).AnyTimes()
+				mock.EXPECT().HasSelfDestructed(gomock.Any()).AnyTimes()
 				mock.EXPECT().GetTransientStorage(gomock.Any(), gomock.Any()).AnyTimes()
 				mock.EXPECT().SetTransientStorage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
 
 				ctx := context{
+					gasSchedule: tosca.EthereumGasSchedule{},
 					params: tosca.Parameters{
 						BlockParameters: tosca.BlockParameters{
 							Revision: revision,
@@ -244,14 +253,14 @@ func TestInterpreter_ExecutionTerminates(t *testing.T) {
 
 			ctxt := getEmptyContext()
 			ctxt.code = test.code
-			ctxt.stack.push(uint256.NewInt(1))
-			ctxt.stack.push(uint256.NewInt(2))
-			ctxt.stack.push(uint256.NewInt(3))
+			ctxt.stack.Push(uint256.NewInt(1))
+			ctxt.stack.Push(uint256.NewInt(2))
+			ctxt.stack.Push(uint256.NewInt(3))
 			// runcontext is needed for selfdestruct
 			mockContext := tosca.NewMockRunContext(gomock.NewController(t))
 			mockContext.EXPECT().GetBalance(gomock.Any()).Return(tosca.Value{1}).AnyTimes()
 			mockContext.EXPECT().GetNonce(gomock.Any()).Return(uint64(1)).AnyTimes()
-			mockContext.EXPECT().SelfDestruct(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+			mockContext.EXPECT().HasSelfDestructed(gomock.Any()).Return(false).AnyTimes()
 			ctxt.context = mockContext
 
 			status, err := steps(&ctxt, false)
@@ -334,11 +343,124 @@ func TestInterpreter_run_ReturnsErrorOnRuntimeError(t *testing.T) {
 	}
 }
 
+func TestInterpreter_checkCodeLength_RejectsCodeNotAddressableByPc(t *testing.T) {
+	tests := map[int]error{
+		0:                 nil,
+		1:                 nil,
+		math.MaxInt32 - 1: nil,
+		math.MaxInt32:     nil,
+		math.MaxInt32 + 1: errCodeTooLarge,
+		math.MaxUint32:    errCodeTooLarge,
+	}
+	for length, want := range tests {
+		if got := checkCodeLength(length); want != got {
+			t.Errorf("unexpected result for length %d, wanted %v, got %v", length, want, got)
+		}
+	}
+}
+
+func TestInterpreter_TruncatedPushIsZeroPaddedAndEndsInImplicitStop(t *testing.T) {
+	for n := 1; n <= 32; n++ {
+		for available := 0; available < n; available++ {
+			op := vm.PUSH1 + vm.OpCode(n-1)
+			t.Run(fmt.Sprintf("%v/%d", op, available), func(t *testing.T) {
+				code := append([]byte{byte(op)}, bytes.Repeat([]byte{0xff}, available)...)
+
+				ctxt := getEmptyContext()
+				ctxt.code = convert(code, ConversionConfig{})
+
+				status, err := steps(&ctxt, false)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if want, got := statusStopped, status; want != got {
+					t.Errorf("unexpected status, wanted %v, got %v", want, got)
+				}
+				if int(ctxt.pc) < len(ctxt.code) {
+					t.Errorf("program counter %d did not move beyond the end of the code", ctxt.pc)
+				}
+
+				want := make([]byte, n)
+				copy(want, bytes.Repeat([]byte{0xff}, available))
+				if ctxt.stack.Len() != 1 {
+					t.Fatalf("unexpected stack size, wanted 1, got %d", ctxt.stack.Len())
+				}
+				if got := ctxt.stack.Peek().Bytes32(); !bytes.Equal(want, got[32-n:]) {
+					t.Errorf("unexpected value pushed, wanted %x, got %x", want, got[32-n:])
+				}
+			})
+		}
+	}
+}
+
+func TestInterpreter_run_AbortsExecutionOnInterrupt(t *testing.T) {
+	interrupt, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+
+	// An endless loop not consuming any gas.
+	code := []Instruction{{JUMP_TO, 0}}
+	params := tosca.Parameters{Gas: 20}
+	params.Interrupt = interrupt
+
+	_, err := run(config{}, params, code)
+	if !errors.Is(err, gocontext.Canceled) {
+		t.Errorf("unexpected error, wanted %v, got %v", gocontext.Canceled, err)
+	}
+}
+
+func TestInterpreter_run_IgnoresInterruptThatIsNotDone(t *testing.T) {
+	interrupt, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+
+	code := []Instruction{{PUSH1, 1}, {STOP, 0}}
+	params := tosca.Parameters{Gas: 20}
+	params.Interrupt = interrupt
+
+	result, err := run(config{}, params, code)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("unexpected result, wanted success, got %v", result)
+	}
+}
+
+func TestContext_isInterrupted_ChecksInterruptInBoundedIntervals(t *testing.T) {
+	done := make(chan struct{})
+	ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, interrupt: done}
+
+	if ctxt.isInterrupted() {
+		t.Fatalf("interrupt reported before the interrupt was signaled")
+	}
+	close(done)
+
+	steps := 0
+	for !ctxt.isInterrupted() {
+		steps++
+		if steps > interruptCheckInterval {
+			t.Fatalf("interrupt not detected within %d steps", interruptCheckInterval)
+		}
+	}
+	if want, got := interruptCheckInterval, steps; want != got {
+		t.Errorf("unexpected number of steps before the interrupt was detected, wanted %d, got %d", want, got)
+	}
+}
+
+func TestContext_isInterrupted_ReturnsFalseWithoutInterrupt(t *testing.T) {
+	ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}}
+	for i := 0; i < 2*interruptCheckInterval; i++ {
+		if ctxt.isInterrupted() {
+			t.Fatalf("unexpected interrupt in step %d", i)
+		}
+	}
+}
+
 func TestRun_GenerateResult(t *testing.T) {
 
 	baseOutput := []byte{0x1, 0x2, 0x3}
 	baseGas := tosca.Gas(2)
 	baseRefund := tosca.Gas(3)
+	baseSelfDestruction := &tosca.SelfDestruction{Beneficiary: tosca.Address{1}, Value: tosca.Value{2}}
 
 	tests := map[string]struct {
 		status         status
@@ -375,10 +497,11 @@ func TestRun_GenerateResult(t *testing.T) {
 		"suicide": {
 			status: statusSelfDestructed,
 			expectedResult: tosca.Result{
-				Success:   true,
-				Output:    nil,
-				GasLeft:   baseGas,
-				GasRefund: baseRefund,
+				Success:         true,
+				Output:          nil,
+				GasLeft:         baseGas,
+				GasRefund:       baseRefund,
+				SelfDestruction: baseSelfDestruction,
 			},
 		},
 		"failure": {
@@ -397,10 +520,13 @@ func TestRun_GenerateResult(t *testing.T) {
 	for name, test := range tests {
 		t.Run(fmt.Sprintf("%v", name), func(t *testing.T) {
 
-			ctxt := context{}
+			ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}}
 			ctxt.refund = baseRefund
 			ctxt.gas = baseGas
 			ctxt.returnData = bytes.Clone(baseOutput)
+			if test.status == statusSelfDestructed {
+				ctxt.selfDestruction = baseSelfDestruction
+			}
 
 			res, err := generateResult(test.status, &ctxt)
 
@@ -510,11 +636,12 @@ func TestSteps_StaticContextViolation(t *testing.T) {
 
 			if len(test.stack) == 0 {
 				// add enough stack elements to pass stack bounds check
-				ctxt.stack.stackPointer = 50
+				ctxt.stack.SetLen(50)
 			} else {
 				// otherwise prefill the stack with provided data
-				copy(ctxt.stack.data[:len(test.stack)], test.stack)
-				ctxt.stack.stackPointer = len(test.stack)
+				for i := range test.stack {
+					ctxt.stack.Push(&test.stack[i])
+				}
 			}
 
 			_, err := steps(&ctxt, false)
@@ -538,7 +665,8 @@ func TestSteps_FailsWithLessGasThanStaticCost(t *testing.T) {
 
 				ctxt := getEmptyContext()
 				ctxt.code = []Instruction{{op, 0}}
-				ctxt.stack.stackPointer = 20
+				ctxt.params.Revision = revision
+				ctxt.stack.SetLen(20)
 				ctxt.gas = expectedGas - 1
 
 				_, err := steps(&ctxt, false)
@@ -558,7 +686,7 @@ func TestInterpreter_InstructionsFailWhenExecutedInRevisionsEarlierThanIntroduce
 				ctxt := getEmptyContext()
 				ctxt.code = []Instruction{{op, 0}}
 				ctxt.params.BlockParameters.Revision = revision
-				ctxt.stack.stackPointer = 20
+				ctxt.stack.SetLen(20)
 
 				_, err := steps(&ctxt, false)
 				if want, got := errInvalidRevision, err; want != got {
@@ -572,8 +700,9 @@ func TestInterpreter_InstructionsFailWhenExecutedInRevisionsEarlierThanIntroduce
 func TestInterpreter_ExecuteReturnsFailureOnExecutionError(t *testing.T) {
 
 	ctxt := context{
-		code:  generateCodeFor(INVALID),
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		code:        generateCodeFor(INVALID),
+		stack:       NewStack(),
 	}
 
 	status := execute(&ctxt, false)
@@ -595,12 +724,47 @@ func BenchmarkFib10_SI(b *testing.B) {
 
 func BenchmarkSatisfiesStackRequirements(b *testing.B) {
 	context := &context{
-		stack: NewStack(),
+		gasSchedule: tosca.EthereumGasSchedule{},
+		stack:       NewStack(),
 	}
 
 	opCodes := allOpCodes()
 	for i := 0; i < b.N; i++ {
-		_ = checkStackLimits(context.stack.len(), opCodes[i%len(opCodes)])
+		_ = checkStackLimits(context.stack.Len(), opCodes[i%len(opCodes)])
+	}
+}
+
+func TestInterpreter_run_NestedCallsReturnUncorruptedOutputs(t *testing.T) {
+	for _, depth := range []int{0, 1, 2, 10, 100} {
+		t.Run(fmt.Sprintf("depth=%d", depth), func(t *testing.T) {
+			// Recursions are repeated to re-use recycled call frames.
+			for i := 0; i < 3; i++ {
+				result, err := runRecursiveCalls(depth)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Success {
+					t.Fatalf("execution failed")
+				}
+				want := uint256.NewInt(uint64(depth + 1)).Bytes32()
+				if !bytes.Equal(result.Output, want[:]) {
+					t.Errorf("unexpected output, wanted %x, got %x", want, result.Output)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRecursiveCalls(b *testing.B) {
+	for _, depth := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := runRecursiveCalls(depth); err != nil {
+					b.Fatalf("unexpected error: %v", err)
+				}
+			}
+		})
 	}
 }
 
@@ -678,7 +842,7 @@ func generateCodeFor(op OpCode) Code {
 // For Jump instructions, it also encodes the PC for the the first jump destination found in code
 func fillStackFor(op OpCode, stack *stack, code Code) error {
 	limits := _precomputedStackLimits.get(op)
-	stack.stackPointer = limits.min
+	stack.SetLen(limits.min)
 
 	// jump instructions need a valid jump destination
 	if isJump(op) {
@@ -690,7 +854,7 @@ func fillStackFor(op OpCode, stack *stack, code Code) error {
 		}
 
 		for i := 0; i < limits.min; i++ {
-			stack.data[i] = *uint256.NewInt(uint64(counter))
+			*stack.Get(i) = *uint256.NewInt(uint64(counter))
 		}
 	}
 
@@ -738,6 +902,7 @@ func benchmarkFib(b *testing.B, arg int, with_super_instructions bool) {
 
 	// Create execution context.
 	ctxt := context{
+		gasSchedule: tosca.EthereumGasSchedule{},
 		params: tosca.Parameters{
 			Input:  data,
 			Static: true,
@@ -755,7 +920,7 @@ func benchmarkFib(b *testing.B, arg int, with_super_instructions bool) {
 		// Reset the context.
 		ctxt.pc = 0
 		ctxt.gas = 1 << 31
-		ctxt.stack.stackPointer = 0
+		ctxt.stack.SetLen(0)
 
 		// Run the code (actual benchmark).
 		status, err := vanillaRunner{}.run(&ctxt)
@@ -833,3 +998,86 @@ func forEachRevision(
 		})
 	}
 }
+
+func TestInterpreter_InstructionsOfLaterRevisionsAreRejectedBeforeDispatch(t *testing.T) {
+	// PUSH0 is executed with a full stack and without gas, which would fail
+	// differently if it was dispatched.
+	for revision := tosca.R07_Istanbul; revision < tosca.R12_Shanghai; revision++ {
+		t.Run(revision.String(), func(t *testing.T) {
+			ctxt := getEmptyContext()
+			ctxt.code = []Instruction{{PUSH0, 0}}
+			ctxt.params.BlockParameters.Revision = revision
+			ctxt.stack.SetLen(maxStackSize)
+			ctxt.gas = 0
+
+			_, err := steps(&ctxt, false)
+			if want, got := errInvalidRevision, err; want != got {
+				t.Errorf("unexpected error: want %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+// recursiveCallCode calls the address zero, loads the 32-byte word returned
+// by the call, and returns the word incremented by one.
+var recursiveCallCode = []byte{
+	byte(vm.PUSH1), 32, // < return data size
+	byte(vm.PUSH1), 0, // < return data offset
+	byte(vm.PUSH1), 0, // < input size
+	byte(vm.PUSH1), 0, // < input offset
+	byte(vm.PUSH1), 0, // < value
+	byte(vm.PUSH1), 0, // < address
+	byte(vm.GAS),
+	byte(vm.CALL),
+	byte(vm.POP),
+	byte(vm.PUSH1), 0,
+	byte(vm.MLOAD),
+	byte(vm.PUSH1), 1,
+	byte(vm.ADD),
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+}
+
+// runRecursiveCalls runs the recursiveCallCode with nested calls running
+// the same code up to the given depth. The result of a successful execution
+// is the number of nested executions, including the top-level one.
+func runRecursiveCalls(depth int) (tosca.Result, error) {
+	code := convert(recursiveCallCode, ConversionConfig{})
+	context := &recursiveCallContext{code: code, maxDepth: depth}
+	return context.run(tosca.Parameters{Gas: 1 << 40})
+}
+
+// recursiveCallContext is a run context handling calls by running the same
+// code recursively until the maximum depth is reached. Calls at the maximum
+// depth return a zero word.
+type recursiveCallContext struct {
+	tosca.RunContext
+	code     Code
+	depth    int
+	maxDepth int
+}
+
+func (c *recursiveCallContext) run(params tosca.Parameters) (tosca.Result, error) {
+	params.Context = c
+	params.Depth = c.depth
+	params.Revision = tosca.R07_Istanbul
+	return run(config{}, params, c.code)
+}
+
+func (c *recursiveCallContext) Call(kind tosca.CallKind, params tosca.CallParameters) (tosca.CallResult, error) {
+	if c.depth == c.maxDepth {
+		return tosca.CallResult{Output: make([]byte, 32), GasLeft: params.Gas, Success: true}, nil
+	}
+	c.depth++
+	defer func() { c.depth-- }()
+	result, err := c.run(tosca.Parameters{Gas: params.Gas, Input: params.Input})
+	return tosca.CallResult{
+		Output:    result.Output,
+		GasLeft:   result.GasLeft,
+		GasRefund: result.GasRefund,
+		Success:   result.Success,
+	}, err
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build !cgo
+
+package lfvm
+
+import "github.com/Fantom-foundation/Tosca/go/tosca"
+
+// Without cgo, as for instance on WebAssembly targets, hashes are computed
+// by the slower pure Go implementation.
+
+func Keccak256(data []byte) tosca.Hash {
+	return keccak256_Go(data)
+}
+
+func Keccak256For32byte(data [32]byte) tosca.Hash {
+	return keccak256_Go(data[:])
+}
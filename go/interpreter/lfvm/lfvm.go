@@ -12,6 +12,7 @@ package lfvm
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
@@ -19,16 +20,36 @@ import (
 
 // Config provides a set of user-definable options for the LFVM interpreter.
 type Config struct {
+	// ShaCache configures the cache for the results of SHA3 instructions.
+	// Interpreters using the default configuration share a single cache.
+	ShaCache ShaCacheConfig
+	// GasSchedule defines the dynamic gas costs of instructions. If nil, the
+	// costs defined by Ethereum are used.
+	GasSchedule tosca.GasSchedule
+	// Diagnostics enables the reporting of the state of failed executions
+	// through the Failure field of results. It slows down failing
+	// executions and is intended for debugging purposes.
+	Diagnostics bool
+	// Metrics, if set, receives the usage of the code cache. The runs of the
+	// interpreter may be measured using tosca.NewMeasuredInterpreter.
+	Metrics tosca.Metrics
+	// Logger, if set, receives notes on codes bypassing the code cache.
+	Logger *slog.Logger
 }
 
 // NewInterpreter creates a new LFVM interpreter instance with the official
 // configuration for production purposes.
-func NewInterpreter(Config) (*lfvm, error) {
+func NewInterpreter(cfg Config) (*lfvm, error) {
 	return newVm(config{
 		ConversionConfig: ConversionConfig{
 			WithSuperInstructions: false,
+			Metrics:               cfg.Metrics,
+			Logger:                cfg.Logger,
 		},
 		WithShaCache: true,
+		ShaCache:     cfg.ShaCache,
+		GasSchedule:  cfg.GasSchedule,
+		Diagnostics:  cfg.Diagnostics,
 	})
 }
 
@@ -36,7 +57,7 @@ func NewInterpreter(Config) (*lfvm, error) {
 func init() {
 	tosca.MustRegisterInterpreterFactory("lfvm", func(any) (tosca.Interpreter, error) {
 		return NewInterpreter(Config{})
-	})
+	}, metadata)
 }
 
 // RegisterExperimentalInterpreterConfigurations registers all experimental
@@ -82,12 +103,18 @@ func RegisterExperimentalInterpreterConfigurations() error {
 		ConversionConfig: ConversionConfig{CacheSize: -1},
 	}
 
+	configs["lfvm-jit"] = config{
+		WithShaCache:         true,
+		TranslationThreshold: defaultTranslationThreshold,
+	}
+
 	for name, config := range configs {
 		err := tosca.RegisterInterpreterFactory(
 			name,
 			func(any) (tosca.Interpreter, error) {
 				return newVm(config)
 			},
+			metadata,
 		)
 		if err != nil {
 			return fmt.Errorf("failed to register interpreter %q: %v", name, err)
@@ -100,12 +127,47 @@ func RegisterExperimentalInterpreterConfigurations() error {
 type config struct {
 	ConversionConfig
 	WithShaCache bool
-	runner       runner
+	// ShaCache configures the SHA3 hash cache used if WithShaCache is set.
+	// If it is the zero value, the cache shared by all instances is used.
+	ShaCache ShaCacheConfig
+	// GasSchedule defines the dynamic gas costs of instructions. If nil, the
+	// costs defined by Ethereum are used.
+	GasSchedule tosca.GasSchedule
+	// Diagnostics enables the reporting of failed executions in results.
+	Diagnostics bool
+	// TranslationThreshold is the number of invocations of a code after which
+	// it is executed by the translation tier. If zero, the tier is disabled.
+	// The tier requires a code cache and is not used with custom runners.
+	TranslationThreshold int
+	runner               runner
+	shaCache             *sha3HashCache // < nil if the shared cache is used
+}
+
+// getShaCache returns the cache to be used for SHA3 instructions, or nil if
+// hashes are not to be cached.
+func (c *config) getShaCache() *sha3HashCache {
+	if !c.WithShaCache {
+		return nil
+	}
+	if c.shaCache != nil {
+		return c.shaCache
+	}
+	return sha3Cache
+}
+
+// getGasSchedule returns the schedule of the dynamic gas costs of
+// instructions to be used by executions.
+func (c *config) getGasSchedule() tosca.GasSchedule {
+	if c.GasSchedule == nil {
+		return tosca.EthereumGasSchedule{}
+	}
+	return c.GasSchedule
 }
 
 type lfvm struct {
-	config    config
-	converter *Converter
+	config     config
+	converter  *Converter
+	translator *translator
 }
 
 func newVm(config config) (*lfvm, error) {
@@ -113,25 +175,103 @@ func newVm(config config) (*lfvm, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create converter: %v", err)
 	}
-	return &lfvm{config: config, converter: converter}, nil
+	var translator *translator
+	if config.TranslationThreshold > 0 && config.runner == nil && converter.cache != nil {
+		translator = newTranslator(
+			config.TranslationThreshold,
+			converter.cache,
+			config.WithSuperInstructions,
+		)
+	}
+	if config.WithShaCache && config.ShaCache != (ShaCacheConfig{}) {
+		config.shaCache = newSha3HashCacheWithConfig(config.ShaCache)
+	}
+	return &lfvm{config: config, converter: converter, translator: translator}, nil
 }
 
 // Defines the newest supported revision for this interpreter implementation
 const newestSupportedRevision = tosca.R13_Cancun
 
+// metadata describes all LFVM configurations in the interpreter registry.
+var metadata = tosca.ImplementationMetadata{
+	Language:       "Go",
+	OldestRevision: tosca.R07_Istanbul,
+	NewestRevision: newestSupportedRevision,
+}
+
 func (v *lfvm) Run(params tosca.Parameters) (tosca.Result, error) {
 	if params.Revision > newestSupportedRevision {
 		return tosca.Result{}, &tosca.ErrUnsupportedRevision{Revision: params.Revision}
 	}
 
+	// Traced executions are run on uncached code converted without super
+	// instructions to be able to report the original EVM instructions.
+	if params.Tracer != nil {
+		config := v.config
+		runner, converted := newTracingRunner(params.Tracer, params.Code)
+		config.runner = runner
+		return run(config, params, converted)
+	}
+
+	// Executions tracking their resource usage are run without super
+	// instructions to observe the stack height after each EVM instruction.
+	if params.ResourceUsage != nil {
+		config := v.config
+		config.runner = resourceUsageRunner{}
+		return run(config, params, convert(params.Code, ConversionConfig{}))
+	}
+
+	// Init codes are typically executed only once. Thus, they are neither
+	// cached nor translated, to not evict the artifacts of deployed codes.
+	codeHash := params.CodeHash
+	if params.InitCode {
+		codeHash = nil
+	}
+
 	converted := v.converter.Convert(
 		params.Code,
-		params.CodeHash,
+		codeHash,
 	)
 
+	if v.translator != nil && codeHash != nil {
+		translated := v.translator.getTranslation(converted, *codeHash, params.Revision)
+		if translated != nil {
+			config := v.config
+			config.runner = translatedRunner{code: translated}
+			return run(config, params, converted)
+		}
+	}
+
 	return run(v.config, params, converted)
 }
 
+// ShaCacheStatistics returns a summary of the usage of the SHA3 hash cache
+// of this interpreter. If the cache is shared, the summary covers the usage
+// by all interpreters sharing it. The second result is false if the
+// interpreter does not cache hashes.
+func (v *lfvm) ShaCacheStatistics() (ShaCacheStatistics, bool) {
+	cache := v.config.getShaCache()
+	if cache == nil {
+		return ShaCacheStatistics{}, false
+	}
+	return cache.getStatistics(), true
+}
+
+// ConversionCacheStatistics returns a summary of the usage of the cache
+// retaining the LFVM code converted by this interpreter. The second result is
+// false if the interpreter does not cache converted code.
+func (v *lfvm) ConversionCacheStatistics() (ConversionCacheStatistics, bool) {
+	return v.converter.Statistics()
+}
+
+// WarmUp converts the given contract codes in advance and retains the results
+// in the conversion cache of this interpreter. Nodes may use this at startup
+// for frequently called contracts to avoid the latency of their conversion on
+// the first calls after a restart.
+func (v *lfvm) WarmUp(codes ...[]byte) {
+	v.converter.WarmUp(codes...)
+}
+
 func (e *lfvm) DumpProfile() {
 	if statsRunner, ok := e.config.runner.(*statisticRunner); ok {
 		fmt.Print(statsRunner.getSummary())
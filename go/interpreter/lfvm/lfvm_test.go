@@ -11,10 +11,13 @@
 package lfvm
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
 func TestNewInterpreter_ProducesInstanceWithSanctionedProperties(t *testing.T) {
@@ -47,6 +50,25 @@ func TestLfvm_OfficialConfigurationHasSanctionedProperties(t *testing.T) {
 	}
 }
 
+func TestLfvm_RegisteredWithMetadata(t *testing.T) {
+	for _, info := range tosca.ListInterpreters() {
+		if info.Name != "lfvm" {
+			continue
+		}
+		if info.Metadata == nil {
+			t.Fatalf("lfvm is registered without metadata")
+		}
+		if want, got := metadata, *info.Metadata; want != got {
+			t.Errorf("unexpected metadata, wanted %v, got %v", want, got)
+		}
+		if info.Metadata.RequiresCgo {
+			t.Errorf("lfvm should not require cgo")
+		}
+		return
+	}
+	t.Errorf("lfvm is not listed in the interpreter registry")
+}
+
 func TestLfvm_InterpreterReturnsErrorWhenExecutingUnsupportedRevision(t *testing.T) {
 	vm, err := tosca.NewInterpreter("lfvm")
 	if err != nil {
@@ -71,3 +93,247 @@ func TestLfvm_newVm_returnsErrorWithWrongConfiguration(t *testing.T) {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestLfvm_ShaCacheStatistics_ReportsUsageOfConfiguredCache(t *testing.T) {
+	shared, err := NewInterpreter(Config{})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	if shared.config.getShaCache() != sha3Cache {
+		t.Errorf("default configuration should use the shared cache")
+	}
+
+	vm, err := NewInterpreter(Config{ShaCache: ShaCacheConfig{Capacity32: 10}})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	cache := vm.config.getShaCache()
+	if cache == nil || cache == sha3Cache {
+		t.Fatalf("custom configuration should use a dedicated cache")
+	}
+	cache.hash([]byte{31: 1})
+	stats, ok := vm.ShaCacheStatistics()
+	if !ok {
+		t.Fatalf("statistics should be available")
+	}
+	if want, got := uint64(1), stats.Inputs32.Misses; want != got {
+		t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+	}
+
+	noCache, err := newVm(config{})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	if _, ok := noCache.ShaCacheStatistics(); ok {
+		t.Errorf("statistics should not be available without a cache")
+	}
+}
+
+func TestLfvm_ReportsResourceUsageIfRequested(t *testing.T) {
+	instance, err := newVm(config{ConversionConfig: ConversionConfig{WithSuperInstructions: true}})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+
+	code := []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 2,
+		byte(vm.PUSH1), 3,
+		byte(vm.POP),
+		byte(vm.POP),
+		byte(vm.PUSH1), 40,
+		byte(vm.MSTORE8),
+		byte(vm.STOP),
+	}
+
+	usage := &tosca.ResourceUsage{}
+	params := tosca.Parameters{
+		Code: code,
+		Gas:  1000,
+		TransactionParameters: tosca.TransactionParameters{
+			ResourceUsage: usage,
+		},
+		Depth: 2,
+	}
+	result, err := instance.Run(params)
+	if err != nil {
+		t.Fatalf("failed to run code: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("execution failed")
+	}
+
+	want := tosca.ResourceUsage{MaxCallDepth: 3, MaxStackHeight: 3, PeakMemory: 64}
+	if want != *usage {
+		t.Errorf("unexpected resource usage, wanted %+v, got %+v", want, *usage)
+	}
+
+	// High-water marks are not lowered by subsequent calls.
+	params.Depth = 0
+	params.Code = []byte{byte(vm.STOP)}
+	if _, err := instance.Run(params); err != nil {
+		t.Fatalf("failed to run code: %v", err)
+	}
+	if want != *usage {
+		t.Errorf("unexpected resource usage, wanted %+v, got %+v", want, *usage)
+	}
+}
+
+type expensiveExpSchedule struct {
+	tosca.EthereumGasSchedule
+}
+
+func (expensiveExpSchedule) ExpCost(tosca.Revision, int) tosca.Gas {
+	return 1000
+}
+
+func TestLfvm_DynamicCostsAreTakenFromConfiguredGasSchedule(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 8,
+		byte(vm.PUSH1), 2,
+		byte(vm.EXP),
+		byte(vm.STOP),
+	}
+
+	tests := map[string]struct {
+		schedule tosca.GasSchedule
+		gasUsed  tosca.Gas
+	}{
+		"default":  {nil, 3 + 3 + 10 + 50},
+		"ethereum": {tosca.EthereumGasSchedule{}, 3 + 3 + 10 + 50},
+		"custom":   {expensiveExpSchedule{}, 3 + 3 + 10 + 1000},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			instance, err := NewInterpreter(Config{GasSchedule: test.schedule})
+			if err != nil {
+				t.Fatalf("failed to create LFVM instance: %v", err)
+			}
+			result, err := instance.Run(tosca.Parameters{Code: code, Gas: 2000})
+			if err != nil {
+				t.Fatalf("failed to run code: %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("execution failed")
+			}
+			if want, got := 2000-test.gasUsed, result.GasLeft; want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestLfvm_DiagnosticsDescribeFailedExecutions(t *testing.T) {
+	code := []byte{
+		byte(vm.PUSH1), 1,
+		byte(vm.PUSH1), 2,
+		byte(vm.ADD),
+		byte(vm.ADD), // < stack underflow
+	}
+
+	for _, diagnostics := range []bool{false, true} {
+		t.Run(fmt.Sprintf("diagnostics=%t", diagnostics), func(t *testing.T) {
+			instance, err := NewInterpreter(Config{Diagnostics: diagnostics})
+			if err != nil {
+				t.Fatalf("failed to create LFVM instance: %v", err)
+			}
+			result, err := instance.Run(tosca.Parameters{Code: code, Gas: 100})
+			if err != nil {
+				t.Fatalf("failed to run code: %v", err)
+			}
+			if result.Success {
+				t.Fatalf("execution should have failed")
+			}
+			if !diagnostics {
+				if result.Failure != nil {
+					t.Errorf("unexpected failure report: %v", result.Failure)
+				}
+				return
+			}
+
+			failure := result.Failure
+			if failure == nil {
+				t.Fatalf("missing failure report")
+			}
+			if want, got := uint64(5), failure.Pc; want != got {
+				t.Errorf("unexpected pc, wanted %d, got %d", want, got)
+			}
+			if want, got := "ADD", failure.Operation; want != got {
+				t.Errorf("unexpected operation, wanted %s, got %s", want, got)
+			}
+			if want, got := tosca.Gas(100-3-3-3), failure.GasLeft; want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+			}
+			if want, got := []tosca.Word{{31: 3}}, failure.Stack; !slices.Equal(want, got) {
+				t.Errorf("unexpected stack, wanted %v, got %v", want, got)
+			}
+			if !errors.Is(failure, errStackUnderflow) {
+				t.Errorf("unexpected cause, wanted %v, got %v", errStackUnderflow, failure.Cause)
+			}
+		})
+	}
+}
+
+func TestLfvm_WarmUpAvoidsConversionOnFirstExecution(t *testing.T) {
+	instance, err := newVm(config{
+		ConversionConfig: ConversionConfig{CacheSize: maxCachedCodeLength * instructionSize},
+	})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	instance.WarmUp(code)
+
+	hash := Keccak256(code)
+	if _, err := instance.Run(tosca.Parameters{Code: code, CodeHash: &hash, Gas: 10}); err != nil {
+		t.Fatalf("failed to run code: %v", err)
+	}
+	stats, ok := instance.ConversionCacheStatistics()
+	if !ok {
+		t.Fatalf("interpreter should report statistics of its conversion cache")
+	}
+	if want, got := uint64(1), stats.Hits; want != got {
+		t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(0), stats.Misses; want != got {
+		t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+	}
+}
+
+func TestLfvm_SupportsWarmUpsThroughRegistry(t *testing.T) {
+	instance, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	if _, ok := instance.(tosca.WarmUpTarget); !ok {
+		t.Errorf("LFVM should support warm-ups")
+	}
+}
+
+func TestLfvm_InitCodeIsNotCached(t *testing.T) {
+	instance, err := newVm(config{
+		ConversionConfig: ConversionConfig{CacheSize: maxCachedCodeLength * instructionSize},
+	})
+	if err != nil {
+		t.Fatalf("failed to create LFVM instance: %v", err)
+	}
+	code := []byte{byte(vm.PUSH1), 1, byte(vm.STOP)}
+	hash := Keccak256(code)
+	for _, initCode := range []bool{true, true, false} {
+		params := tosca.Parameters{Code: code, CodeHash: &hash, Gas: 10, InitCode: initCode}
+		if _, err := instance.Run(params); err != nil {
+			t.Fatalf("failed to run code: %v", err)
+		}
+	}
+	stats, ok := instance.ConversionCacheStatistics()
+	if !ok {
+		t.Fatalf("interpreter should report statistics of its conversion cache")
+	}
+	if want, got := uint64(0), stats.Hits; want != got {
+		t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(1), stats.Misses; want != got {
+		t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+	}
+}
@@ -11,6 +11,9 @@
 package lfvm
 
 import (
+	"encoding/binary"
+	"sync"
+
 	"github.com/Fantom-foundation/Tosca/go/tosca"
 	"github.com/holiman/uint256"
 )
@@ -20,21 +23,51 @@ type Memory struct {
 	currentMemoryCost tosca.Gas
 }
 
+// NewMemory returns an empty memory instance from a reuse pool.
 func NewMemory() *Memory {
-	return &Memory{}
+	return memoryPool.Get().(*Memory)
+}
+
+// ReturnMemory returns the memory to the reuse pool. Slices obtained from the
+// memory must not be used afterwards, unless shared is set. In this case the
+// buffer of the memory is still referenced, e.g. by the output of an
+// execution, and it is handed over to the referencing party instead of being
+// recycled. Any memory may only be returned once.
+func ReturnMemory(m *Memory, shared bool) {
+	if shared || cap(m.store) > maxPooledMemorySize {
+		m.store = nil
+	} else {
+		m.store = m.store[:0]
+	}
+	m.currentMemoryCost = 0
+	memoryPool.Put(m)
+}
+
+var memoryPool = sync.Pool{
+	New: func() any {
+		return &Memory{}
+	},
 }
 
 const (
 	// Maximum memory size allowed
 	// This magic number comes from 'core/vm/gas_table.go' 'memoryGasCost' in geth
 	maxMemoryExpansionSize = 0x1FFFFFFFE0
+
+	// Buffers of returned memory instances exceeding this capacity are
+	// released instead of being recycled, to limit the retained memory.
+	maxPooledMemorySize = 1 << 16
 )
 
 // getExpansionCostsAndSize returns the gas cost and the new memory size after
-// the expansion.
+// the expansion, priced by the given schedule for the given revision.
 // The function returns an error if the new size is greater than the maximum
 // memory size allowed, or an overflow happens when computing the costs.
-func (m *Memory) getExpansionCostsAndSize(size uint64) (tosca.Gas, uint64, error) {
+func (m *Memory) getExpansionCostsAndSize(
+	size uint64,
+	schedule tosca.GasSchedule,
+	revision tosca.Revision,
+) (tosca.Gas, uint64, error) {
 
 	// static assert
 	const (
@@ -57,7 +90,7 @@ func (m *Memory) getExpansionCostsAndSize(size uint64) (tosca.Gas, uint64, error
 		return 0, 0, errMaxMemoryExpansionSize
 	}
 
-	new_costs := tosca.Gas((words*words)/512 + (3 * words))
+	new_costs := schedule.MemoryCost(revision, words)
 	fee := new_costs - m.currentMemoryCost
 	return fee, validSize, nil
 }
@@ -76,7 +109,7 @@ func (m *Memory) expandMemory(offset, size uint64, c *context) error {
 		return errOverflow
 	}
 	if m.length() < needed {
-		fee, expandedSize, err := m.getExpansionCostsAndSize(needed)
+		fee, expandedSize, err := m.getExpansionCostsAndSize(needed, c.gasSchedule, c.params.Revision)
 		if err != nil {
 			return err
 		}
@@ -86,6 +119,8 @@ func (m *Memory) expandMemory(offset, size uint64, c *context) error {
 
 		currentSize := m.length()
 		m.currentMemoryCost += fee
+		// The capacity of recycled buffers is reused, the appended range is
+		// zeroed without allocating a temporary slice.
 		m.store = append(m.store, make([]byte, expandedSize-currentSize)...)
 	}
 
@@ -131,6 +166,23 @@ func (m *Memory) readWord(offset *uint256.Int, target *uint256.Int, c *context)
 	return nil
 }
 
+// writeWord writes the given value as a 32-byte big-endian word into the
+// memory at the given offset. The value is written in place, without an
+// intermediate copy.
+// Expands memory as needed and charges for it.
+// Returns an error in case of not enough gas or offset+32 overflow.
+func (m *Memory) writeWord(offset *uint256.Int, value *uint256.Int, c *context) error {
+	data, err := m.getSlice(offset, uint256.NewInt(32), c)
+	if err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint64(data[0:8], value[3])
+	binary.BigEndian.PutUint64(data[8:16], value[2])
+	binary.BigEndian.PutUint64(data[16:24], value[1])
+	binary.BigEndian.PutUint64(data[24:32], value[0])
+	return nil
+}
+
 // set copies the given value into memory at the given offset.
 // Expands the memory size as needed and charges for it.
 // Returns an error if there is not enough gas or offset+len(value) overflows.
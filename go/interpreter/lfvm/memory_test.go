@@ -79,7 +79,7 @@ func TestGetExpansionCostsAndSize(t *testing.T) {
 			for _, test := range test.tests {
 
 				m := NewMemory()
-				cost, size, err := m.getExpansionCostsAndSize(test.size)
+				cost, size, err := m.getExpansionCostsAndSize(test.size, tosca.EthereumGasSchedule{}, tosca.R13_Cancun)
 				if !errors.Is(err, expectedError) {
 					t.Errorf("unexpected error: want: %v but got: %v", expectedError, err)
 				}
@@ -112,18 +112,18 @@ func TestMemory_ExpansionsAndCostsAreIncremental(t *testing.T) {
 			m := NewMemory()
 
 			// Compute costs from 0 to target size.
-			costA, sizeA, errA := m.getExpansionCostsAndSize(a)
-			costB, sizeB, errB := m.getExpansionCostsAndSize(b)
+			costA, sizeA, errA := m.getExpansionCostsAndSize(a, tosca.EthereumGasSchedule{}, tosca.R13_Cancun)
+			costB, sizeB, errB := m.getExpansionCostsAndSize(b, tosca.EthereumGasSchedule{}, tosca.R13_Cancun)
 			if errA != nil || errB != nil {
 				t.Fatalf("unexpected error: %v, %v", errA, errB)
 			}
 
 			// Compute costs for increasing from size a to size b.
-			ctxt := &context{gas: math.MaxInt}
+			ctxt := &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: math.MaxInt}
 			if err := m.expandMemory(0, a, ctxt); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			delta, sizeAB, err := m.getExpansionCostsAndSize(b)
+			delta, sizeAB, err := m.getExpansionCostsAndSize(b, tosca.EthereumGasSchedule{}, tosca.R13_Cancun)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -174,11 +174,35 @@ func TestMemory_expandMemory_ErrorCases(t *testing.T) {
 			gas:      100,
 			expected: errOverflow,
 		},
+		"end at 2^64": {
+			size:     32,
+			offset:   math.MaxUint64 - 31,
+			gas:      100,
+			expected: errOverflow,
+		},
+		"end overflowing word alignment": {
+			size:     10,
+			offset:   math.MaxUint64 - 40,
+			gas:      100,
+			expected: errOverflow,
+		},
+		"word aligned end near 2^64": {
+			size:     32,
+			offset:   math.MaxUint64 - 63,
+			gas:      math.MaxInt64,
+			expected: errMaxMemoryExpansionSize,
+		},
+		"end beyond expansion limit": {
+			size:     1,
+			offset:   maxMemoryExpansionSize,
+			gas:      math.MaxInt64,
+			expected: errMaxMemoryExpansionSize,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			ctxt := context{gas: test.gas}
+			ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: test.gas}
 			m := NewMemory()
 
 			err := m.expandMemory(test.offset, test.size, &ctxt)
@@ -221,7 +245,7 @@ func TestMemory_expandMemory_expandsMemoryOnlyWhenNeeded(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			ctxt := context{gas: 3}
+			ctxt := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 3}
 			m := NewMemory()
 			m.store = make([]byte, test.initialMemorySize)
 
@@ -237,7 +261,7 @@ func TestMemory_expandMemory_expandsMemoryOnlyWhenNeeded(t *testing.T) {
 }
 
 func TestMemory_getSlice_ErrorCases(t *testing.T) {
-	c := context{gas: 0}
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 0}
 	m := NewMemory()
 	_, err := m.getSlice(uint256.NewInt(0), uint256.NewInt(1), &c)
 	if !errors.Is(err, errOutOfGas) {
@@ -276,7 +300,7 @@ func TestMemory_getSlice_ReturnsSliceOfRequestedSize(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			c := context{gas: 3}
+			c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 3}
 			m := NewMemory()
 			m.store = []byte{0x0, 0x01, 0x02, 0x03, 0x04}
 			slice, err := m.getSlice(test.offset, test.size, &c)
@@ -296,7 +320,7 @@ func TestMemory_getSlice_ExpandsMemoryIn32ByteChunks(t *testing.T) {
 			for size := uint64(1); size < 32; size++ {
 				offset256 := uint256.NewInt(offset)
 				size256 := uint256.NewInt(size)
-				c := &context{gas: 15}
+				c := &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 15}
 				m := NewMemory()
 				m.store = make([]byte, memSize)
 				_, err := m.getSlice(offset256, size256, c)
@@ -319,7 +343,7 @@ func TestMemory_getSlice_ExpandsMemoryIn32ByteChunks(t *testing.T) {
 func TestMemory_getSlice_DoesNotExpandWithSizeZero(t *testing.T) {
 	for memSize := 0; memSize < 128; memSize += 32 {
 		for offset := uint64(0); offset < 128; offset++ {
-			c := &context{gas: 1}
+			c := &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 1}
 			m := NewMemory()
 			m.store = make([]byte, memSize)
 			_, err := m.getSlice(uint256.NewInt(offset), uint256.NewInt(0), c)
@@ -337,7 +361,7 @@ func TestMemory_getSlice_DoesNotExpandWithSizeZero(t *testing.T) {
 }
 
 func TestMemory_getSlice_MemoryExpansionDoesNotOverwriteExistingMemory(t *testing.T) {
-	c := context{gas: 6}
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 6}
 	m := NewMemory()
 	m.store = []byte{0x0, 0x01, 0x02, 0x03, 0x04}
 	_, err := m.getSlice(uint256.NewInt(4), uint256.NewInt(29), &c)
@@ -353,7 +377,7 @@ func TestMemory_getSlice_MemoryExpansionDoesNotOverwriteExistingMemory(t *testin
 }
 
 func TestMemory_getSlice_ExpandsWithZeros(t *testing.T) {
-	c := context{gas: 6}
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 6}
 	m := NewMemory()
 	baseMemory := []byte{0x0, 0x01, 0x02, 0x03, 0x04}
 	m.store = bytes.Clone(baseMemory)
@@ -370,7 +394,7 @@ func TestMemory_getSlice_ExpandsWithZeros(t *testing.T) {
 }
 
 func TestMemory_readWord_ErrorCases(t *testing.T) {
-	c := context{gas: 0}
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 0}
 	m := NewMemory()
 	originalTarget := uint256.NewInt(1)
 	target := originalTarget.Clone()
@@ -390,6 +414,39 @@ func TestMemory_readWord_ErrorCases(t *testing.T) {
 	}
 }
 
+func TestMemory_writeWord_WritesBigEndianWordAtGivenOffset(t *testing.T) {
+	before := generateRandomBytes(96)
+	value := new(uint256.Int).SetBytes(generateRandomBytes(32))
+	for offset := uint64(0); offset <= 64; offset++ {
+		m := &Memory{store: bytes.Clone(before)}
+		if err := m.writeWord(uint256.NewInt(offset), value, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := bytes.Clone(before)
+		word := value.Bytes32()
+		copy(want[offset:], word[:])
+		if !bytes.Equal(m.store, want) {
+			t.Errorf("unexpected memory value after write, want: %x, got: %x", want, m.store)
+		}
+	}
+}
+
+func TestMemory_writeWord_ErrorCases(t *testing.T) {
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 0}
+	m := NewMemory()
+	err := m.writeWord(uint256.NewInt(math.MaxUint64-31), uint256.NewInt(1), &c)
+	if !errors.Is(err, errOverflow) {
+		t.Errorf("error should be errOverflow, instead is: %v", err)
+	}
+	err = m.writeWord(uint256.NewInt(0), uint256.NewInt(1), &c)
+	if !errors.Is(err, errOutOfGas) {
+		t.Errorf("error should be errOutOfGas, instead is: %v", err)
+	}
+	if m.length() != 0 {
+		t.Errorf("memory should not have been expanded, instead has length: %d", m.length())
+	}
+}
+
 func TestMemory_set_UpdatesDataInMemoryAtGivenOffset(t *testing.T) {
 	before := generateRandomBytes(128)
 	for offset := uint64(0); offset < 128; offset++ {
@@ -415,7 +472,7 @@ func TestMemory_set_UpdatesDataInMemoryAtGivenOffset(t *testing.T) {
 func TestMemory_set_ExpansionPreservesMemoryContentAndPadsWithZeroesToTheRight(t *testing.T) {
 	before := generateRandomBytes(32)
 	m := &Memory{store: bytes.Clone(before)}
-	if err := m.set(uint256.NewInt(64), []byte{0x1, 0x2}, &context{gas: 100}); err != nil {
+	if err := m.set(uint256.NewInt(64), []byte{0x1, 0x2}, &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 100}); err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 
@@ -445,7 +502,7 @@ func TestMemory_set_IgnoresEmptyData(t *testing.T) {
 }
 
 func TestMemory_set_FailsIfThereIsNotEnoughGasToGrow(t *testing.T) {
-	c := &context{gas: 2}
+	c := &context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 2}
 	m := &Memory{store: make([]byte, 32)}
 	if err := m.set(uint256.NewInt(64), []byte{0x1}, c); !errors.Is(err, errOutOfGas) {
 		t.Errorf("unexpected error %v, got %v", errOutOfGas, err)
@@ -475,3 +532,99 @@ func generateRandomBytes(size int) []byte {
 	_, _ = rand.Read(data)
 	return data
 }
+
+func TestMemory_ReturnMemory_RecyclesBuffer(t *testing.T) {
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 100}
+	m := NewMemory()
+	if err := m.set(uint256.NewInt(0), []byte{1, 2, 3}, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	buffer := m.store
+
+	ReturnMemory(m, false)
+	if m.length() != 0 || m.currentMemoryCost != 0 {
+		t.Fatalf("returned memory should be reset, got length %d and cost %d", m.length(), m.currentMemoryCost)
+	}
+	if cap(m.store) != cap(buffer) {
+		t.Errorf("buffer should be recycled, got capacity %d", cap(m.store))
+	}
+
+	// Recycled buffers are zeroed when the memory is expanded.
+	data, err := m.getSlice(uint256.NewInt(0), uint256.NewInt(32), &c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(data, make([]byte, 32)) {
+		t.Errorf("recycled memory should be zeroed, got %x", data)
+	}
+}
+
+func TestMemory_ReturnMemory_HandsOverSharedBuffer(t *testing.T) {
+	c := context{gasSchedule: tosca.EthereumGasSchedule{}, gas: 100}
+	m := NewMemory()
+	output, err := m.getSlice(uint256.NewInt(0), uint256.NewInt(3), &c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	copy(output, []byte{1, 2, 3})
+
+	ReturnMemory(m, true)
+	if m.store != nil {
+		t.Fatalf("shared buffer should not be recycled")
+	}
+	if err := m.set(uint256.NewInt(0), []byte{4, 5, 6}, &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(output, []byte{1, 2, 3}) {
+		t.Errorf("shared buffer was modified, got %x", output)
+	}
+}
+
+func TestMemory_ReturnMemory_ReleasesLargeBuffers(t *testing.T) {
+	m := NewMemory()
+	m.store = make([]byte, maxPooledMemorySize+1)
+	ReturnMemory(m, false)
+	if m.store != nil {
+		t.Errorf("large buffer should not be recycled")
+	}
+}
+
+var expansionCostsSink tosca.Gas
+
+// getFixedExpansionCostsAndSize is the implementation of
+// getExpansionCostsAndSize used before gas schedules were introduced, which
+// serves as the baseline of BenchmarkMemory_ExpansionCosts.
+func getFixedExpansionCostsAndSize(m *Memory, size uint64) (tosca.Gas, uint64, error) {
+	if m.length() >= size {
+		return 0, m.length(), nil
+	}
+	words := tosca.SizeInWords(size)
+	validSize := words * 32
+	if validSize < size {
+		return 0, 0, errOverflow
+	}
+	if validSize > maxMemoryExpansionSize {
+		return 0, 0, errMaxMemoryExpansionSize
+	}
+	newCosts := tosca.Gas((words*words)/512 + (3 * words))
+	return newCosts - m.currentMemoryCost, validSize, nil
+}
+
+func BenchmarkMemory_ExpansionCosts(b *testing.B) {
+	const maxSize = 1 << 16
+	b.Run("baseline", func(b *testing.B) {
+		m := NewMemory()
+		for i := 0; i < b.N; i++ {
+			costs, _, _ := getFixedExpansionCostsAndSize(m, uint64(i%maxSize)+1)
+			expansionCostsSink = costs
+		}
+	})
+	b.Run("schedule", func(b *testing.B) {
+		var schedule tosca.GasSchedule = tosca.EthereumGasSchedule{}
+		m := NewMemory()
+		for i := 0; i < b.N; i++ {
+			costs, _, _ := m.getExpansionCostsAndSize(uint64(i%maxSize)+1, schedule, tosca.R13_Cancun)
+			expansionCostsSink = costs
+		}
+	})
+}
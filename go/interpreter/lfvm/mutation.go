@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build mutation
+
+package lfvm
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// Mutation describes a systematic fault injected into the interpreter. By
+// verifying that the conformance tests detect each mutation, the strength
+// of the conformance rules can be measured. Mutations are only supported by
+// builds using the "mutation" build tag.
+type Mutation struct {
+	Name           string    // unique name of the mutation
+	Op             vm.OpCode // the operation affected by the mutation
+	StaticGasDelta tosca.Gas // added to the static gas price of Op
+	StackMinDelta  int       // added to the minimum stack size required by Op
+	StackMaxDelta  int       // added to the maximum stack size allowed by Op
+}
+
+func (m Mutation) String() string {
+	return m.Name
+}
+
+// activeMutation is the mutation currently applied to all interpreter
+// instances, nil if there is none.
+var activeMutation atomic.Pointer[Mutation]
+
+// SetMutation activates the given mutation for all LFVM instances. Passing
+// nil disables mutations. Codes translated while a mutation was active are
+// not updated, so mutations should only be used with configurations not
+// using the translation tier.
+func SetMutation(mutation *Mutation) {
+	activeMutation.Store(mutation)
+}
+
+// GetMutations lists mutations of the static gas prices and stack limits of
+// all valid operations. For each operation, the static gas price is
+// increased and decreased by one, if this results in a valid price, and the
+// checks for stack under- and overflows are weakened by one element.
+func GetMutations() []Mutation {
+	res := []Mutation{}
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		if !vm.IsValid(op) {
+			continue
+		}
+		res = append(res, Mutation{
+			Name:           fmt.Sprintf("%v_static_gas+1", op),
+			Op:             op,
+			StaticGasDelta: 1,
+		})
+		if static_gas_prices.get(OpCode(op)) > 0 {
+			res = append(res, Mutation{
+				Name:           fmt.Sprintf("%v_static_gas-1", op),
+				Op:             op,
+				StaticGasDelta: -1,
+			})
+		}
+		limits := _precomputedStackLimits.get(OpCode(op))
+		if limits.min > 0 {
+			res = append(res, Mutation{
+				Name:          fmt.Sprintf("%v_stack_underflow_check", op),
+				Op:            op,
+				StackMinDelta: -1,
+			})
+		}
+		if limits.max < maxStackSize {
+			res = append(res, Mutation{
+				Name:          fmt.Sprintf("%v_stack_overflow_check", op),
+				Op:            op,
+				StackMaxDelta: 1,
+			})
+		}
+	}
+	return res
+}
+
+func mutateStaticGas(op OpCode, gas tosca.Gas) tosca.Gas {
+	if mutation := activeMutation.Load(); mutation != nil && OpCode(mutation.Op) == op {
+		return gas + mutation.StaticGasDelta
+	}
+	return gas
+}
+
+func mutateStackLimits(op OpCode, limits stackLimits) stackLimits {
+	if mutation := activeMutation.Load(); mutation != nil && OpCode(mutation.Op) == op {
+		limits.min += mutation.StackMinDelta
+		limits.max += mutation.StackMaxDelta
+	}
+	return limits
+}
@@ -0,0 +1,28 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build !mutation
+
+package lfvm
+
+import "github.com/Fantom-foundation/Tosca/go/tosca"
+
+// The functions in this file are hooks for injecting faults into the
+// interpreter for mutation testing. In regular builds, they are no-ops
+// which are inlined by the compiler. Builds using the "mutation" build tag
+// use the implementations in mutation.go instead.
+
+func mutateStaticGas(_ OpCode, gas tosca.Gas) tosca.Gas {
+	return gas
+}
+
+func mutateStackLimits(_ OpCode, limits stackLimits) stackLimits {
+	return limits
+}
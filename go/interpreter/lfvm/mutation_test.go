@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build mutation
+
+package lfvm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestGetMutations_NamesAreUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for _, mutation := range GetMutations() {
+		if seen[mutation.Name] {
+			t.Errorf("duplicated mutation %v", mutation)
+		}
+		seen[mutation.Name] = true
+	}
+	if len(seen) == 0 {
+		t.Errorf("no mutations available")
+	}
+}
+
+func TestMutation_StaticGasIsMutated(t *testing.T) {
+	defer SetMutation(nil)
+	code := []Instruction{{ADD, 0}}
+
+	for _, delta := range []tosca.Gas{0, 1, -1} {
+		SetMutation(&Mutation{Op: vm.ADD, StaticGasDelta: delta})
+		ctxt := getContext(code, nil, nil, 2, 10, tosca.R07_Istanbul)
+		if _, err := steps(&ctxt, true); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want, got := 10-3-delta, ctxt.gas; want != got {
+			t.Errorf("unexpected gas for delta %d, wanted %d, got %d", delta, want, got)
+		}
+	}
+}
+
+func TestMutation_StackLimitsAreMutated(t *testing.T) {
+	defer SetMutation(nil)
+	code := []Instruction{{ADD, 0}}
+
+	ctxt := getContext(code, nil, nil, 1, 10, tosca.R07_Istanbul)
+	if _, err := steps(&ctxt, true); !errors.Is(err, errStackUnderflow) {
+		t.Fatalf("unexpected error without mutation: %v", err)
+	}
+
+	SetMutation(&Mutation{Op: vm.ADD, StackMinDelta: -1})
+	ctxt = getContext(code, nil, nil, 1, 10, tosca.R07_Istanbul)
+	if _, err := steps(&ctxt, true); err != nil {
+		t.Fatalf("unexpected error with mutation: %v", err)
+	}
+
+	SetMutation(&Mutation{Op: vm.MUL, StackMinDelta: -1})
+	ctxt = getContext(code, nil, nil, 1, 10, tosca.R07_Istanbul)
+	if _, err := steps(&ctxt, true); !errors.Is(err, errStackUnderflow) {
+		t.Fatalf("mutation of other operation should not affect ADD: %v", err)
+	}
+}
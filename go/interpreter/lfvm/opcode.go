@@ -13,6 +13,7 @@ package lfvm
 import (
 	"fmt"
 
+	"github.com/Fantom-foundation/Tosca/go/tosca"
 	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
@@ -401,6 +402,27 @@ func (o OpCode) decompose() []OpCode {
 	return nil
 }
 
+// introducedIn lists the first revision supporting each OpCode. It is derived
+// from the metadata of the vm package and consulted by the interpreter before
+// dispatching an instruction, such that operations of later revisions fail
+// independently of their implementation. Super instructions are available
+// once all of their components are, and all other OpCodes in all revisions.
+var introducedIn = newOpCodePropertyMap(getIntroducedInInternal)
+
+func getIntroducedInInternal(op OpCode) tosca.Revision {
+	if op.isBaseInstruction() {
+		if info, found := vm.OpCode(op).Info(); found {
+			return tosca.Revision(info.Introduced)
+		}
+		return tosca.R07_Istanbul
+	}
+	res := tosca.R07_Istanbul
+	for _, subOp := range op.decompose() {
+		res = max(res, getIntroducedInInternal(subOp))
+	}
+	return res
+}
+
 // opCodePropertyMap is a generic property map for precomputed values.
 // Its purpose is to provide a precomputed lookup table for OpCode properties
 // that can be generated from a function that takes an OpCode as input.
@@ -78,3 +78,21 @@ func allOpCodesWhere(predicate func(op OpCode) bool) []OpCode {
 func allOpCodes() []OpCode {
 	return allOpCodesWhere(func(op OpCode) bool { return true })
 }
+
+func TestOpCode_IntroducedInMatchesExpectations(t *testing.T) {
+	for _, op := range allOpCodes() {
+		if want, got := _introducedIn.get(op), introducedIn.get(op); want != got {
+			t.Errorf("unexpected revision introducing %v, wanted %v, got %v", op, want, got)
+		}
+	}
+}
+
+func TestOpCode_SuperInstructionsAreIntroducedWithTheirLatestComponent(t *testing.T) {
+	for _, op := range allOpCodesWhere(OpCode.isSuperInstruction) {
+		for _, subOp := range op.decompose() {
+			if introducedIn.get(subOp) > introducedIn.get(op) {
+				t.Errorf("%v is available before its component %v", op, subOp)
+			}
+		}
+	}
+}
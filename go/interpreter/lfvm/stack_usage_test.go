@@ -13,6 +13,8 @@ package lfvm
 import (
 	"fmt"
 	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
 )
 
 func TestComputeStackUsage_ProducesValidResultsForSingleOps(t *testing.T) {
@@ -41,6 +43,22 @@ func TestComputeStackUsage_ProducesValidResultsForSingleOps(t *testing.T) {
 	}
 }
 
+func TestComputeStackUsage_MatchesOpCodeInfo(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		info, found := vm.OpCode(i).Info()
+		if !found {
+			continue
+		}
+		op := OpCode(i)
+		if want, got := info.Outputs-info.Inputs, computeStackUsage(op).delta; want != got {
+			t.Errorf("unexpected stack delta of %v, wanted %d, got %d", op, want, got)
+		}
+		if want, got := -info.Inputs, computeStackUsage(op).from; want != got {
+			t.Errorf("unexpected lower end of stack usage of %v, wanted %d, got %d", op, want, got)
+		}
+	}
+}
+
 func TestCombineStackUsage(t *testing.T) {
 	tests := []struct {
 		ops   []OpCode
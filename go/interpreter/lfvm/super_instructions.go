@@ -12,33 +12,31 @@ package lfvm
 
 import (
 	"math/bits"
-
-	"github.com/holiman/uint256"
 )
 
 // ----------------------------- Super Instructions -----------------------------
 
 func opSwap1_Pop(c *context) {
-	a1 := c.stack.pop()
-	a2 := c.stack.peek()
+	a1 := c.stack.Pop()
+	a2 := c.stack.Peek()
 	*a2 = *a1
 }
 
 func opSwap2_Pop(c *context) {
-	a1 := c.stack.pop()
-	*c.stack.peekN(1) = *a1
+	a1 := c.stack.Pop()
+	*c.stack.PeekN(1) = *a1
 }
 
 func opPush1_Push1(c *context) {
 	arg := c.code[c.pc].arg
-	c.stack.stackPointer += 2
-	c.stack.peekN(0).SetUint64(uint64(arg & 0xFF))
-	c.stack.peekN(1).SetUint64(uint64(arg >> 8))
+	c.stack.SetLen(c.stack.Len() + 2)
+	c.stack.PeekN(0).SetUint64(uint64(arg & 0xFF))
+	c.stack.PeekN(1).SetUint64(uint64(arg >> 8))
 }
 
 func opPush1_Add(c *context) {
 	arg := c.code[c.pc].arg
-	trg := c.stack.peek()
+	trg := c.stack.Peek()
 	var carry uint64
 	trg[0], carry = bits.Add64(trg[0], uint64(arg), 0)
 	trg[1], carry = bits.Add64(trg[1], 0, carry)
@@ -48,15 +46,15 @@ func opPush1_Add(c *context) {
 
 func opPush1_Shl(c *context) {
 	arg := c.code[c.pc].arg
-	trg := c.stack.peek()
+	trg := c.stack.Peek()
 	trg.Lsh(trg, uint(arg))
 }
 
 func opPush1_Dup1(c *context) {
 	arg := c.code[c.pc].arg
-	c.stack.stackPointer += 2
-	c.stack.peekN(0).SetUint64(uint64(arg))
-	c.stack.peekN(1).SetUint64(uint64(arg))
+	c.stack.SetLen(c.stack.Len() + 2)
+	c.stack.PeekN(0).SetUint64(uint64(arg))
+	c.stack.PeekN(1).SetUint64(uint64(arg))
 }
 
 func opPush2_Jump(c *context) error {
@@ -67,7 +65,7 @@ func opPush2_Jump(c *context) error {
 
 func opPush2_Jumpi(c *context) error {
 	// Directly take pushed value and jump to destination.
-	condition := c.stack.pop()
+	condition := c.stack.Pop()
 	if !condition.IsZero() {
 		c.pc = int32(c.code[c.pc].arg) - 1
 		return checkJumpDest(c)
@@ -76,22 +74,21 @@ func opPush2_Jumpi(c *context) error {
 }
 
 func opSwap2_Swap1(c *context) {
-	a1 := c.stack.peekN(0)
-	a2 := c.stack.peekN(1)
-	a3 := c.stack.peekN(2)
+	a1 := c.stack.PeekN(0)
+	a2 := c.stack.PeekN(1)
+	a3 := c.stack.PeekN(2)
 	*a1, *a2, *a3 = *a2, *a3, *a1
 }
 
 func opDup2_Mstore(c *context) error {
-	var value = c.stack.pop()
-	var offset = c.stack.peek()
-	v := value.Bytes32()
-	return c.memory.set(offset, v[:], c)
+	var value = c.stack.Pop()
+	var offset = c.stack.Peek()
+	return c.memory.writeWord(offset, value, c)
 }
 
 func opDup2_Lt(c *context) {
-	b := c.stack.peekN(0)
-	a := c.stack.peekN(1)
+	b := c.stack.PeekN(0)
+	a := c.stack.PeekN(1)
 	if a.Lt(b) {
 		b.SetOne()
 	} else {
@@ -100,7 +97,7 @@ func opDup2_Lt(c *context) {
 }
 
 func opPopPop(c *context) {
-	c.stack.stackPointer -= 2
+	c.stack.SetLen(c.stack.Len() - 2)
 }
 
 func opPop_Jump(c *context) error {
@@ -109,7 +106,7 @@ func opPop_Jump(c *context) error {
 }
 
 func opIsZero_Push2_Jumpi(c *context) error {
-	condition := c.stack.pop()
+	condition := c.stack.Pop()
 	if condition.IsZero() {
 		c.pc = int32(c.code[c.pc].arg) - 1
 		return checkJumpDest(c)
@@ -118,27 +115,27 @@ func opIsZero_Push2_Jumpi(c *context) error {
 }
 
 func opSwap2_Swap1_Pop_Jump(c *context) error {
-	top := c.stack.pop()
-	c.stack.pop()
-	trg := c.stack.peek()
+	top := c.stack.Pop()
+	c.stack.Pop()
+	trg := c.stack.Peek()
 	c.pc = int32(trg.Uint64()) - 1
 	*trg = *top
 	return checkJumpDest(c)
 }
 
 func opSwap1_Pop_Swap2_Swap1(c *context) {
-	a1 := c.stack.pop()
-	a2 := c.stack.peekN(0)
-	a3 := c.stack.peekN(1)
-	a4 := c.stack.peekN(2)
+	a1 := c.stack.Pop()
+	a2 := c.stack.PeekN(0)
+	a3 := c.stack.PeekN(1)
+	a4 := c.stack.PeekN(2)
 	*a2, *a3, *a4 = *a3, *a4, *a1
 }
 
 func opPop_Swap2_Swap1_Pop(c *context) {
-	c.stack.pop()
-	a2 := c.stack.pop()
-	a3 := c.stack.peekN(0)
-	a4 := c.stack.peekN(1)
+	c.stack.Pop()
+	a2 := c.stack.Pop()
+	a3 := c.stack.PeekN(0)
+	a4 := c.stack.PeekN(1)
 	*a3, *a4 = *a4, *a2
 }
 
@@ -160,9 +157,9 @@ func opPush1_Push1_Push1_Shl_Sub(c *context) {
 	shift := uint8(arg2)
 	value := uint8(arg1 & 0xFF)
 	delta := uint8(arg1 >> 8)
-	trg := c.stack.pushUndefined()
+	trg := c.stack.PushUndefined()
 	trg.SetUint64(uint64(value))
 	trg.Lsh(trg, uint(shift))
-	trg.Sub(trg, uint256.NewInt(uint64(delta)))
+	trg.SubUint64(trg, uint64(delta))
 	c.pc++
 }
@@ -47,11 +47,11 @@ func TestSI_opDup2_Lt(t *testing.T) {
 
 			opDup2_Lt(&ctxt)
 
-			if want, got := 2, ctxt.stack.stackPointer; want != got {
+			if want, got := 2, ctxt.stack.Len(); want != got {
 				t.Errorf("unexpected stack size, got %v, want %v", got, want)
 			}
 
-			if want, got := test.result, ctxt.stack.peek(); want.Cmp(got) != 0 {
+			if want, got := test.result, ctxt.stack.Peek(); want.Cmp(got) != 0 {
 				t.Errorf("unexpected result, got %v, expected %v", got, want)
 			}
 		})
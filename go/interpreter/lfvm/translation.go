@@ -0,0 +1,253 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/holiman/uint256"
+)
+
+// The translation tier is an execution tier for frequently executed codes.
+// Once a code has been invoked a configurable number of times, its LFVM code
+// is translated into a pre-decoded instruction stream in which the values of
+// PUSH instructions are readily available and static gas costs are resolved
+// for the targeted revision. This removes the decoding of immediate values
+// and the gas price lookups from the hot path of the execution. Instructions
+// not supported by the translation tier are delegated to the interpreter.
+
+// defaultTranslationThreshold is the number of invocations of a code after
+// which it gets translated in the experimental translating configurations.
+const defaultTranslationThreshold = 16
+
+// errTruncatedPush is reported when translating a code whose last PUSH
+// instruction is missing some of its data instructions.
+const errTruncatedPush = tosca.ConstError("truncated push instruction")
+
+// translatedKind determines how a translated instruction is executed.
+type translatedKind byte
+
+const (
+	// translatedFallback instructions are executed by the interpreter. This is
+	// the zero value such that the data instructions of PUSH operations, which
+	// are never executed directly, default to the interpreter's behavior.
+	translatedFallback translatedKind = iota
+	translatedPush
+	translatedPop
+	translatedDup
+	translatedSwap
+	translatedJumpDest
+)
+
+// translatedInstruction is a single pre-decoded instruction.
+type translatedInstruction struct {
+	opcode    OpCode
+	kind      translatedKind
+	size      int32     // < number of LFVM instructions covered by this instruction
+	arg       int32     // < constant index for PUSH, stack position for DUP and SWAP
+	staticGas tosca.Gas // < static gas costs of the instruction in the targeted revision
+}
+
+// translatedCode is the result of translating an LFVM code. Instructions are
+// aligned with the positions of the LFVM code such that program counters and
+// jump targets remain valid in both representations.
+type translatedCode struct {
+	instructions []translatedInstruction
+	constants    []uint256.Int
+}
+
+// size returns the number of bytes occupied by the translated code.
+func (c *translatedCode) size() int {
+	res := int(unsafe.Sizeof(translatedCode{}))
+	if c == nil {
+		return res
+	}
+	return res +
+		cap(c.instructions)*int(unsafe.Sizeof(translatedInstruction{})) +
+		cap(c.constants)*int(unsafe.Sizeof(uint256.Int{}))
+}
+
+// translate produces the pre-decoded instruction stream for the given code
+// and revision. An error is reported for codes that cannot be translated.
+func translate(code Code, revision tosca.Revision) (*translatedCode, error) {
+	gasPrices := getStaticGasPrices(revision)
+	res := &translatedCode{
+		instructions: make([]translatedInstruction, len(code)),
+	}
+	for pc := 0; pc < len(code); {
+		op := code[pc].opcode
+		instruction := translatedInstruction{
+			opcode:    op,
+			size:      1,
+			staticGas: gasPrices.get(op),
+		}
+		switch {
+		case PUSH1 <= op && op <= PUSH32:
+			n := int(op-PUSH1) + 1
+			size := n/2 + n%2
+			if pc+size > len(code) {
+				return nil, errTruncatedPush
+			}
+			var value [32]byte
+			for i := 0; i < n; i++ {
+				arg := code[pc+i/2].arg
+				if i%2 == 0 {
+					value[i] = byte(arg >> 8)
+				} else {
+					value[i] = byte(arg)
+				}
+			}
+			res.constants = append(res.constants, *new(uint256.Int).SetBytes(value[:n]))
+			instruction.kind = translatedPush
+			instruction.arg = int32(len(res.constants) - 1)
+			instruction.size = int32(size)
+		case op == POP:
+			instruction.kind = translatedPop
+		case DUP1 <= op && op <= DUP16:
+			instruction.kind = translatedDup
+			instruction.arg = int32(op - DUP1)
+		case SWAP1 <= op && op <= SWAP16:
+			instruction.kind = translatedSwap
+			instruction.arg = int32(op-SWAP1) + 1
+		case op == JUMPDEST:
+			instruction.kind = translatedJumpDest
+		}
+		res.instructions[pc] = instruction
+		pc += int(instruction.size)
+	}
+	return res, nil
+}
+
+// translatedRunner executes translated code.
+type translatedRunner struct {
+	code *translatedCode
+}
+
+func (r translatedRunner) run(c *context) (status, error) {
+	status, err := stepsTranslated(c, r.code)
+	if err != nil {
+		c.recordFailure(err)
+		return statusFailed, nil
+	}
+	return status, nil
+}
+
+// stepsTranslated executes the given translated code in the given context,
+// which must have been set up for the LFVM code the translation is derived
+// from. Its results are equivalent to those of steps.
+func stepsTranslated(c *context, code *translatedCode) (status, error) {
+	instructions := code.instructions
+	for {
+		if int(c.pc) >= len(instructions) {
+			return statusStopped, nil
+		}
+
+		instruction := &instructions[c.pc]
+		if instruction.kind == translatedFallback {
+			status, err := steps(c, true)
+			if err != nil || status != statusRunning {
+				return status, err
+			}
+			continue
+		}
+
+		if c.isInterrupted() {
+			return statusInterrupted, nil
+		}
+		if err := checkStackLimits(c.stack.Len(), instruction.opcode); err != nil {
+			return statusRunning, err
+		}
+		if err := c.useGas(instruction.staticGas); err != nil {
+			return statusRunning, err
+		}
+
+		switch instruction.kind {
+		case translatedPush:
+			c.stack.Push(&code.constants[instruction.arg])
+		case translatedPop:
+			c.stack.Pop()
+		case translatedDup:
+			c.stack.Dup(int(instruction.arg))
+		case translatedSwap:
+			c.stack.Swap(int(instruction.arg))
+		case translatedJumpDest:
+			// nothing
+		}
+		c.pc += instruction.size
+	}
+}
+
+// translator tracks the number of invocations of codes and provides the
+// translations of codes which have been invoked frequently. Invocation
+// counters and translations are retained in a code cache.
+type translator struct {
+	threshold int32
+	cache     *tosca.CodeCache
+	kinds     [2]string // < artifact kinds of translations before and since Berlin
+}
+
+// invocationCounterKind is the artifact kind of invocation counters in the
+// code cache.
+const invocationCounterKind = "lfvm-invocations"
+
+func newTranslator(threshold int, cache *tosca.CodeCache, withSuperInstructions bool) *translator {
+	suffix := ""
+	if withSuperInstructions {
+		suffix = "-si"
+	}
+	return &translator{
+		threshold: int32(threshold),
+		cache:     cache,
+		kinds: [2]string{
+			"lfvm-translation" + suffix,
+			"lfvm-translation-berlin" + suffix,
+		},
+	}
+}
+
+// getTranslation registers an invocation of the given code and returns its
+// translation for the given revision if the code is frequently used. If the
+// code is not yet hot or can not be translated, nil is returned and the code
+// is to be executed by the interpreter.
+func (t *translator) getTranslation(code Code, hash tosca.Hash, revision tosca.Revision) *translatedCode {
+	kind := t.kinds[0]
+	if revision >= tosca.R09_Berlin {
+		kind = t.kinds[1]
+	}
+	if res, found := t.cache.Get(hash, kind); found {
+		return res.(*translatedCode)
+	}
+	if !t.isHot(hash) {
+		return nil
+	}
+	res, err := translate(code, revision)
+	if err != nil {
+		// The failure is cached to avoid repeated translation attempts.
+		res = nil
+	}
+	t.cache.Add(hash, kind, res, res.size())
+	return res
+}
+
+// isHot counts an invocation of the code with the given hash and reports
+// whether the threshold for translating it has been reached. Concurrent first
+// invocations may create competing counters, losing a few invocations, which
+// only delays the translation.
+func (t *translator) isHot(hash tosca.Hash) bool {
+	counter, found := t.cache.Get(hash, invocationCounterKind)
+	if !found {
+		counter = new(atomic.Int32)
+		t.cache.Add(hash, invocationCounterKind, counter, int(unsafe.Sizeof(atomic.Int32{})))
+	}
+	return counter.(*atomic.Int32).Add(1) >= t.threshold
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package lfvm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/holiman/uint256"
+)
+
+func TestTranslate_PushValuesAreDecoded(t *testing.T) {
+	for n := 1; n <= 32; n++ {
+		t.Run(fmt.Sprintf("PUSH%d", n), func(t *testing.T) {
+			code := []byte{byte(vm.PUSH1) + byte(n-1)}
+			for i := 0; i < n; i++ {
+				code = append(code, byte(i+1))
+			}
+			converted := convert(code, ConversionConfig{})
+			translated, err := translate(converted, tosca.R13_Cancun)
+			if err != nil {
+				t.Fatalf("failed to translate code: %v", err)
+			}
+			instruction := translated.instructions[0]
+			if want, got := translatedPush, instruction.kind; want != got {
+				t.Fatalf("unexpected kind, wanted %v, got %v", want, got)
+			}
+			if want, got := int32(n/2+n%2), instruction.size; want != got {
+				t.Errorf("unexpected size, wanted %d, got %d", want, got)
+			}
+			want := new(uint256.Int).SetBytes(code[1:])
+			if got := &translated.constants[instruction.arg]; !want.Eq(got) {
+				t.Errorf("unexpected value, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestTranslate_StaticGasIsResolvedForRevision(t *testing.T) {
+	converted := convert([]byte{byte(vm.BALANCE)}, ConversionConfig{})
+	for _, revision := range []tosca.Revision{tosca.R07_Istanbul, tosca.R13_Cancun} {
+		translated, err := translate(converted, revision)
+		if err != nil {
+			t.Fatalf("failed to translate code: %v", err)
+		}
+		want := getStaticGasPrices(revision).get(BALANCE)
+		if got := translated.instructions[0].staticGas; want != got {
+			t.Errorf("unexpected static gas in %v, wanted %d, got %d", revision, want, got)
+		}
+	}
+}
+
+func TestTranslate_TruncatedPushIsReported(t *testing.T) {
+	code := Code{{opcode: PUSH4, arg: 0x0102}}
+	if _, err := translate(code, tosca.R13_Cancun); !errors.Is(err, errTruncatedPush) {
+		t.Errorf("unexpected error, wanted %v, got %v", errTruncatedPush, err)
+	}
+}
+
+func TestStepsTranslated_ProducesSameResultsAsInterpreter(t *testing.T) {
+	codes := map[string][]byte{
+		"stack operations": {
+			byte(vm.PUSH1), 1, byte(vm.PUSH2), 2, 3, byte(vm.DUP2), byte(vm.SWAP2),
+			byte(vm.POP), byte(vm.ADD), byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		},
+		"jumps": {
+			byte(vm.PUSH1), 4, byte(vm.JUMP), byte(vm.INVALID), byte(vm.JUMPDEST),
+			byte(vm.PUSH1), 42, byte(vm.PUSH1), 0, byte(vm.MSTORE),
+			byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+		},
+		"stack underflow": {byte(vm.PUSH1), 1, byte(vm.SWAP1)},
+		"out of gas":      {byte(vm.JUMPDEST), byte(vm.PUSH1), 0, byte(vm.JUMP)},
+		"truncated push":  {byte(vm.PUSH1), 1, byte(vm.PUSH32), 1, 2},
+		"invalid":         {byte(vm.PUSH1), 1, byte(vm.INVALID)},
+		"long example":    longExampleCode,
+	}
+
+	for name, code := range codes {
+		for _, si := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/si=%t", name, si), func(t *testing.T) {
+				converted := convert(code, ConversionConfig{WithSuperInstructions: si})
+				translated, err := translate(converted, tosca.R13_Cancun)
+				if err != nil {
+					t.Fatalf("failed to translate code: %v", err)
+				}
+
+				params := tosca.Parameters{
+					BlockParameters: tosca.BlockParameters{Revision: tosca.R13_Cancun},
+					Gas:             10_000,
+					Code:            code,
+				}
+				want, err := run(config{}, params, converted)
+				if err != nil {
+					t.Fatalf("failed to run interpreter: %v", err)
+				}
+				got, err := run(config{runner: translatedRunner{translated}}, params, converted)
+				if err != nil {
+					t.Fatalf("failed to run translated code: %v", err)
+				}
+				if fmt.Sprint(want) != fmt.Sprint(got) {
+					t.Errorf("unexpected result, wanted %v, got %v", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestTranslator_CodesAreTranslatedOnceThresholdIsReached(t *testing.T) {
+	cache := tosca.NewCodeCache(1 << 20)
+	translator := newTranslator(3, cache, false)
+	code := convert([]byte{byte(vm.PUSH1), 1, byte(vm.STOP)}, ConversionConfig{})
+	hash := tosca.Hash{1}
+
+	for i := 1; i < 3; i++ {
+		if got := translator.getTranslation(code, hash, tosca.R13_Cancun); got != nil {
+			t.Fatalf("code should not be translated in invocation %d", i)
+		}
+	}
+	want := translator.getTranslation(code, hash, tosca.R13_Cancun)
+	if want == nil {
+		t.Fatalf("code should be translated once the threshold is reached")
+	}
+	if got := translator.getTranslation(code, hash, tosca.R13_Cancun); want != got {
+		t.Errorf("translation should be cached")
+	}
+}
+
+func TestTranslator_TranslationsAreRevisionSpecific(t *testing.T) {
+	cache := tosca.NewCodeCache(1 << 20)
+	translator := newTranslator(1, cache, false)
+	code := convert([]byte{byte(vm.BALANCE)}, ConversionConfig{})
+	hash := tosca.Hash{1}
+
+	istanbul := translator.getTranslation(code, hash, tosca.R07_Istanbul)
+	berlin := translator.getTranslation(code, hash, tosca.R09_Berlin)
+	if istanbul == nil || berlin == nil {
+		t.Fatalf("code should be translated")
+	}
+	if istanbul.instructions[0].staticGas == berlin.instructions[0].staticGas {
+		t.Errorf("translations should use revision specific static gas prices")
+	}
+}
+
+func TestTranslator_FailedTranslationsFallBackToInterpreter(t *testing.T) {
+	cache := tosca.NewCodeCache(1 << 20)
+	translator := newTranslator(1, cache, false)
+	code := Code{{opcode: PUSH4, arg: 0x0102}}
+	hash := tosca.Hash{1}
+
+	for i := 0; i < 2; i++ {
+		if got := translator.getTranslation(code, hash, tosca.R13_Cancun); got != nil {
+			t.Errorf("code with truncated push should not be translated")
+		}
+	}
+	if _, found := cache.Get(hash, translator.kinds[1]); !found {
+		t.Errorf("failed translation should be cached")
+	}
+}
+
+func TestLfvm_TranslationTierProducesSameResultsAsInterpreter(t *testing.T) {
+	example := getFibExample()
+	input := make([]byte, 4+32)
+	input[0], input[1], input[2], input[3] = 0xF9, 0xB7, 0xC7, 0xE5
+	input[4+31] = 10
+	hash := Keccak256(example.code)
+
+	reference, err := newVm(config{})
+	if err != nil {
+		t.Fatalf("failed to create VM: %v", err)
+	}
+	translating, err := newVm(config{
+		ConversionConfig:     ConversionConfig{CacheSize: 1 << 24},
+		TranslationThreshold: 2,
+	})
+	if err != nil {
+		t.Fatalf("failed to create VM: %v", err)
+	}
+
+	for _, revision := range []tosca.Revision{tosca.R07_Istanbul, tosca.R13_Cancun} {
+		params := tosca.Parameters{
+			BlockParameters: tosca.BlockParameters{Revision: revision},
+			Gas:             1 << 20,
+			Code:            example.code,
+			CodeHash:        &hash,
+			Input:           input,
+		}
+		want, err := reference.Run(params)
+		if err != nil || !want.Success {
+			t.Fatalf("failed to run reference: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			got, err := translating.Run(params)
+			if err != nil {
+				t.Fatalf("failed to run translating VM: %v", err)
+			}
+			if fmt.Sprint(want) != fmt.Sprint(got) {
+				t.Errorf("unexpected result in invocation %d, wanted %v, got %v", i, want, got)
+			}
+		}
+	}
+	if _, found := translating.converter.cache.Get(hash, translating.translator.kinds[1]); !found {
+		t.Errorf("code should have been translated")
+	}
+}
+
+func TestLfvm_TranslationTierIsDisabledForCustomRunners(t *testing.T) {
+	vm, err := newVm(config{
+		TranslationThreshold: 1,
+		runner:               loggingRunner{},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VM: %v", err)
+	}
+	if vm.translator != nil {
+		t.Errorf("translation tier should be disabled for custom runners")
+	}
+}
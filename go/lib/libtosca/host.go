@@ -0,0 +1,319 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+/*
+#define TOSCA_BUILDING_LIBRARY
+#include "tosca.h"
+#include <string.h>
+
+// The following functions forward calls to the host interface, since Go code
+// can not call C function pointers directly. Unset callbacks are treated as
+// operating on an empty state.
+
+static bool host_account_exists(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->account_exists ? h->account_exists(host, address) : false;
+}
+
+static void host_get_balance(const tosca_host_interface* h, void* host, const tosca_address* address, tosca_word* result) {
+  if (h && h->get_balance) h->get_balance(host, address, result);
+}
+
+static void host_set_balance(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* value) {
+  if (h && h->set_balance) h->set_balance(host, address, value);
+}
+
+static uint64_t host_get_nonce(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->get_nonce ? h->get_nonce(host, address) : 0;
+}
+
+static void host_set_nonce(const tosca_host_interface* h, void* host, const tosca_address* address, uint64_t nonce) {
+  if (h && h->set_nonce) h->set_nonce(host, address, nonce);
+}
+
+static tosca_bytes host_get_code(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  tosca_bytes empty = {0};
+  return h && h->get_code ? h->get_code(host, address) : empty;
+}
+
+static void host_get_code_hash(const tosca_host_interface* h, void* host, const tosca_address* address, tosca_word* result) {
+  if (h && h->get_code_hash) h->get_code_hash(host, address, result);
+}
+
+static size_t host_get_code_size(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->get_code_size ? h->get_code_size(host, address) : 0;
+}
+
+static void host_set_code(const tosca_host_interface* h, void* host, const tosca_address* address, const uint8_t* data, size_t size) {
+  tosca_bytes code = {data, size};
+  if (h && h->set_code) h->set_code(host, address, code);
+}
+
+static void host_get_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, tosca_word* result) {
+  if (h && h->get_storage) h->get_storage(host, address, key, result);
+}
+
+static int32_t host_set_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, const tosca_word* value) {
+  return h && h->set_storage ? h->set_storage(host, address, key, value) : 0;
+}
+
+static bool host_self_destruct(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_address* beneficiary) {
+  return h && h->self_destruct ? h->self_destruct(host, address, beneficiary) : false;
+}
+
+static int32_t host_create_snapshot(const tosca_host_interface* h, void* host) {
+  return h && h->create_snapshot ? h->create_snapshot(host) : 0;
+}
+
+static void host_restore_snapshot(const tosca_host_interface* h, void* host, int32_t snapshot) {
+  if (h && h->restore_snapshot) h->restore_snapshot(host, snapshot);
+}
+
+static void host_get_transient_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, tosca_word* result) {
+  if (h && h->get_transient_storage) h->get_transient_storage(host, address, key, result);
+}
+
+static void host_set_transient_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, const tosca_word* value) {
+  if (h && h->set_transient_storage) h->set_transient_storage(host, address, key, value);
+}
+
+static bool host_access_account(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->access_account ? h->access_account(host, address) : false;
+}
+
+static bool host_access_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key) {
+  return h && h->access_storage ? h->access_storage(host, address, key) : false;
+}
+
+static void host_emit_log(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* topics, size_t num_topics, const uint8_t* data, size_t size) {
+  tosca_bytes bytes = {data, size};
+  if (h && h->emit_log) h->emit_log(host, address, topics, num_topics, bytes);
+}
+
+static void host_get_block_hash(const tosca_host_interface* h, void* host, int64_t number, tosca_word* result) {
+  if (h && h->get_block_hash) h->get_block_hash(host, number, result);
+}
+
+static void host_get_committed_storage(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, tosca_word* result) {
+  if (h && h->get_committed_storage) h->get_committed_storage(host, address, key, result);
+}
+
+static bool host_is_address_in_access_list(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->is_address_in_access_list ? h->is_address_in_access_list(host, address) : false;
+}
+
+static void host_is_slot_in_access_list(const tosca_host_interface* h, void* host, const tosca_address* address, const tosca_word* key, bool* address_present, bool* slot_present) {
+  if (h && h->is_slot_in_access_list) h->is_slot_in_access_list(host, address, key, address_present, slot_present);
+}
+
+static bool host_has_self_destructed(const tosca_host_interface* h, void* host, const tosca_address* address) {
+  return h && h->has_self_destructed ? h->has_self_destructed(host, address) : false;
+}
+
+static void host_call(const tosca_host_interface* h, void* host, int32_t kind, const tosca_call_parameters* parameters, tosca_call_result* result) {
+  if (h && h->call) h->call(host, kind, parameters, result);
+}
+*/
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// hostContext is a tosca.RunContext forwarding all operations to the
+// callbacks of a host interface provided by a C client.
+type hostContext struct {
+	iface *C.tosca_host_interface
+	host  unsafe.Pointer
+}
+
+func (c *hostContext) AccountExists(address tosca.Address) bool {
+	return bool(C.host_account_exists(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) GetBalance(address tosca.Address) tosca.Value {
+	var res tosca.Value
+	C.host_get_balance(c.iface, c.host, cAddress(&address), cWord(&res))
+	return res
+}
+
+func (c *hostContext) SetBalance(address tosca.Address, value tosca.Value) {
+	C.host_set_balance(c.iface, c.host, cAddress(&address), cWord(&value))
+}
+
+func (c *hostContext) GetNonce(address tosca.Address) uint64 {
+	return uint64(C.host_get_nonce(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) SetNonce(address tosca.Address, nonce uint64) {
+	C.host_set_nonce(c.iface, c.host, cAddress(&address), C.uint64_t(nonce))
+}
+
+func (c *hostContext) GetCode(address tosca.Address) tosca.Code {
+	return goBytes(C.host_get_code(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	var res tosca.Hash
+	C.host_get_code_hash(c.iface, c.host, cAddress(&address), cWord(&res))
+	return res
+}
+
+func (c *hostContext) GetCodeSize(address tosca.Address) int {
+	return int(C.host_get_code_size(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) SetCode(address tosca.Address, code tosca.Code) {
+	C.host_set_code(c.iface, c.host, cAddress(&address), cData(code), C.size_t(len(code)))
+}
+
+func (c *hostContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	var res tosca.Word
+	C.host_get_storage(c.iface, c.host, cAddress(&address), cWord(&key), cWord(&res))
+	return res
+}
+
+func (c *hostContext) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	return tosca.StorageStatus(C.host_set_storage(c.iface, c.host, cAddress(&address), cWord(&key), cWord(&value)))
+}
+
+func (c *hostContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	return bool(C.host_self_destruct(c.iface, c.host, cAddress(&address), cAddress(&beneficiary)))
+}
+
+func (c *hostContext) CreateSnapshot() tosca.Snapshot {
+	return tosca.Snapshot(C.host_create_snapshot(c.iface, c.host))
+}
+
+func (c *hostContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	C.host_restore_snapshot(c.iface, c.host, C.int32_t(snapshot))
+}
+
+func (c *hostContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	var res tosca.Word
+	C.host_get_transient_storage(c.iface, c.host, cAddress(&address), cWord(&key), cWord(&res))
+	return res
+}
+
+func (c *hostContext) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	C.host_set_transient_storage(c.iface, c.host, cAddress(&address), cWord(&key), cWord(&value))
+}
+
+func (c *hostContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	return tosca.AccessStatus(C.host_access_account(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	return tosca.AccessStatus(C.host_access_storage(c.iface, c.host, cAddress(&address), cWord(&key)))
+}
+
+func (c *hostContext) EmitLog(log tosca.Log) {
+	var topics *C.tosca_word
+	if len(log.Topics) > 0 {
+		topics = (*C.tosca_word)(unsafe.Pointer(&log.Topics[0]))
+	}
+	C.host_emit_log(c.iface, c.host, cAddress(&log.Address), topics, C.size_t(len(log.Topics)), cData(log.Data), C.size_t(len(log.Data)))
+}
+
+func (c *hostContext) GetLogs() []tosca.Log {
+	// Logs are collected by the host, interpreters do not need to read them.
+	return nil
+}
+
+func (c *hostContext) GetBlockHash(number int64) tosca.Hash {
+	var res tosca.Hash
+	C.host_get_block_hash(c.iface, c.host, C.int64_t(number), cWord(&res))
+	return res
+}
+
+func (c *hostContext) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	var res tosca.Word
+	C.host_get_committed_storage(c.iface, c.host, cAddress(&address), cWord(&key), cWord(&res))
+	return res
+}
+
+func (c *hostContext) IsAddressInAccessList(address tosca.Address) bool {
+	return bool(C.host_is_address_in_access_list(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	var a, s C.bool
+	C.host_is_slot_in_access_list(c.iface, c.host, cAddress(&address), cWord(&key), &a, &s)
+	return bool(a), bool(s)
+}
+
+func (c *hostContext) HasSelfDestructed(address tosca.Address) bool {
+	return bool(C.host_has_self_destructed(c.iface, c.host, cAddress(&address)))
+}
+
+func (c *hostContext) Call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	// The input is referenced by the parameter struct passed to C and thus
+	// needs to be pinned for the duration of the call.
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	if len(parameters.Input) > 0 {
+		pinner.Pin(&parameters.Input[0])
+	}
+	params := C.tosca_call_parameters{
+		sender:       *cAddress(&parameters.Sender),
+		recipient:    *cAddress(&parameters.Recipient),
+		value:        *cWord(&parameters.Value),
+		input:        C.tosca_bytes{data: cData(parameters.Input), size: C.size_t(len(parameters.Input))},
+		gas:          C.int64_t(parameters.Gas),
+		salt:         *cWord(&parameters.Salt),
+		code_address: *cAddress(&parameters.CodeAddress),
+	}
+	var res C.tosca_call_result
+	C.host_call(c.iface, c.host, C.int32_t(kind), &params, &res)
+	return tosca.CallResult{
+		Success:        bool(res.success),
+		Output:         goBytes(res.output),
+		GasLeft:        tosca.Gas(res.gas_left),
+		GasRefund:      tosca.Gas(res.gas_refund),
+		CreatedAddress: goAddress(&res.created_address),
+	}, nil
+}
+
+// --- conversion helpers ---
+
+func cAddress(address *tosca.Address) *C.tosca_address {
+	return (*C.tosca_address)(unsafe.Pointer(address))
+}
+
+func cWord[T ~[32]byte](word *T) *C.tosca_word {
+	return (*C.tosca_word)(unsafe.Pointer(word))
+}
+
+func cData(data []byte) *C.uint8_t {
+	if len(data) == 0 {
+		return nil
+	}
+	return (*C.uint8_t)(unsafe.Pointer(&data[0]))
+}
+
+func goAddress(address *C.tosca_address) tosca.Address {
+	return *(*tosca.Address)(unsafe.Pointer(address))
+}
+
+func goWord[T ~[32]byte](word *C.tosca_word) T {
+	return *(*T)(unsafe.Pointer(word))
+}
+
+// goBytes copies the given C byte sequence into Go memory.
+func goBytes(bytes C.tosca_bytes) []byte {
+	if bytes.data == nil || bytes.size == 0 {
+		return nil
+	}
+	return C.GoBytes(unsafe.Pointer(bytes.data), C.int(bytes.size))
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package main provides libtosca, a shared library exposing the interpreters
+// registered in Tosca through the C interface declared in tosca.h. This way,
+// clients written in other languages (e.g. Rust through bindgen) can embed
+// Tosca's interpreters. To build the library, run
+//
+//	go build -buildmode=c-shared -o libtosca.so ./go/lib/libtosca
+//
+// or use the libtosca target of the Makefile.
+package main
+
+/*
+#define TOSCA_BUILDING_LIBRARY
+#include "tosca.h"
+#include <stdlib.h>
+#include <string.h>
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"unsafe"
+
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func main() {}
+
+//export tosca_list_interpreters
+func tosca_list_interpreters(buffer *C.char, size C.size_t) C.size_t {
+	list := listInterpreters()
+	if size > 0 {
+		n := min(len(list), int(size)-1)
+		dst := unsafe.Slice((*byte)(unsafe.Pointer(buffer)), int(size))
+		copy(dst, list[:n])
+		dst[n] = 0
+	}
+	return C.size_t(len(list))
+}
+
+//export tosca_new_interpreter
+func tosca_new_interpreter(name *C.char, interpreter *C.tosca_interpreter) C.int {
+	handle, err := interpreters.create(C.GoString(name))
+	if err != nil {
+		return C.TOSCA_ERROR_UNKNOWN_INTERPRETER
+	}
+	*interpreter = C.tosca_interpreter(handle)
+	return C.TOSCA_OK
+}
+
+//export tosca_release_interpreter
+func tosca_release_interpreter(interpreter C.tosca_interpreter) C.int {
+	if !interpreters.release(uintptr(interpreter)) {
+		return C.TOSCA_ERROR_INVALID_HANDLE
+	}
+	return C.TOSCA_OK
+}
+
+//export tosca_run
+func tosca_run(
+	interpreter C.tosca_interpreter,
+	parameters *C.tosca_parameters,
+	hostInterface *C.tosca_host_interface,
+	host unsafe.Pointer,
+	result *C.tosca_result,
+) C.int {
+	*result = C.tosca_result{}
+	params := goParameters(parameters)
+	params.Context = &hostContext{iface: hostInterface, host: host}
+	res, err := run(uintptr(interpreter), params)
+	switch {
+	case err == nil:
+		setResult(result, res)
+		return C.TOSCA_OK
+	case errors.Is(err, errInvalidHandle):
+		return C.TOSCA_ERROR_INVALID_HANDLE
+	case errors.As(err, new(*tosca.ErrUnsupportedRevision)):
+		return C.TOSCA_ERROR_UNSUPPORTED_REVISION
+	default:
+		result.error = C.CString(err.Error())
+		return C.TOSCA_ERROR_EXECUTION
+	}
+}
+
+//export tosca_release_result
+func tosca_release_result(result *C.tosca_result) {
+	C.free(unsafe.Pointer(result.output))
+	C.free(unsafe.Pointer(result.error))
+	*result = C.tosca_result{}
+}
+
+// listInterpreters returns the comma-separated names of all registered
+// interpreters in alphabetical order.
+func listInterpreters() string {
+	infos := tosca.ListInterpreters()
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+const errInvalidHandle = tosca.ConstError("invalid interpreter handle")
+
+// interpreterTable maps handles handed out to C clients to interpreter
+// instances. Unlike cgo.Handle, invalid handles are detected instead of
+// causing a panic.
+type interpreterTable struct {
+	mutex   sync.Mutex
+	next    uintptr
+	entries map[uintptr]tosca.Interpreter
+}
+
+var interpreters = interpreterTable{entries: map[uintptr]tosca.Interpreter{}}
+
+func (t *interpreterTable) create(name string) (uintptr, error) {
+	interpreter, err := tosca.NewInterpreter(name)
+	if err != nil {
+		return 0, err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.next++
+	t.entries[t.next] = interpreter
+	return t.next, nil
+}
+
+func (t *interpreterTable) get(handle uintptr) (tosca.Interpreter, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	interpreter, found := t.entries[handle]
+	return interpreter, found
+}
+
+func (t *interpreterTable) release(handle uintptr) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	_, found := t.entries[handle]
+	delete(t.entries, handle)
+	return found
+}
+
+// run executes the given parameters on the interpreter with the given handle.
+// Panics are reported as errors since they must not cross the C boundary.
+func run(handle uintptr, params tosca.Parameters) (result tosca.Result, err error) {
+	interpreter, found := interpreters.get(handle)
+	if !found {
+		return tosca.Result{}, errInvalidHandle
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("interpreter panicked: %v", r)
+		}
+	}()
+	result, err = interpreter.Run(params)
+	if err == nil {
+		// Hosts observe all self-destructions through the self_destruct
+		// callback, independent of the interpreter in use.
+		tosca.FinalizeSelfDestruction(params.Context, params.Recipient, result.SelfDestruction)
+	}
+	return result, err
+}
+
+func goParameters(p *C.tosca_parameters) tosca.Parameters {
+	params := tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{
+			ChainID:     goWord[tosca.Word](&p.chain_id),
+			BlockNumber: int64(p.block_number),
+			Timestamp:   int64(p.timestamp),
+			Coinbase:    goAddress(&p.coinbase),
+			GasLimit:    tosca.Gas(p.gas_limit),
+			PrevRandao:  goWord[tosca.Hash](&p.prev_randao),
+			BaseFee:     goWord[tosca.Value](&p.base_fee),
+			BlobBaseFee: goWord[tosca.Value](&p.blob_base_fee),
+			Revision:    tosca.Revision(p.revision),
+		},
+		TransactionParameters: tosca.TransactionParameters{
+			Origin:   goAddress(&p.origin),
+			GasPrice: goWord[tosca.Value](&p.gas_price),
+		},
+		Kind:      tosca.CallKind(p.kind),
+		Static:    bool(p.is_static),
+		Depth:     int(p.depth),
+		Gas:       tosca.Gas(p.gas),
+		Recipient: goAddress(&p.recipient),
+		Sender:    goAddress(&p.sender),
+		Input:     goBytes(p.input),
+		Value:     goWord[tosca.Value](&p.value),
+		Code:      goBytes(p.code),
+	}
+	if p.num_blob_hashes > 0 {
+		hashes := unsafe.Slice((*tosca.Hash)(unsafe.Pointer(p.blob_hashes)), int(p.num_blob_hashes))
+		params.BlobHashes = slices.Clone(hashes)
+	}
+	if p.code_hash != nil {
+		hash := goWord[tosca.Hash](p.code_hash)
+		params.CodeHash = &hash
+	}
+	return params
+}
+
+func setResult(result *C.tosca_result, res tosca.Result) {
+	result.success = C.bool(res.Success)
+	if len(res.Output) > 0 {
+		result.output = (*C.uint8_t)(C.CBytes(res.Output))
+		result.output_size = C.size_t(len(res.Output))
+	}
+	result.gas_left = C.int64_t(res.GasLeft)
+	result.gas_refund = C.int64_t(res.GasRefund)
+	if res.SelfDestruction != nil {
+		result.self_destructed = true
+		result.beneficiary = *cAddress(&res.SelfDestruction.Beneficiary)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListInterpreters_ContainsGoInterpreters(t *testing.T) {
+	list := strings.Split(listInterpreters(), ",")
+	for _, name := range []string{"geth", "lfvm"} {
+		found := false
+		for _, cur := range list {
+			found = found || cur == name
+		}
+		if !found {
+			t.Errorf("interpreter %s not listed in %v", name, list)
+		}
+	}
+}
+
+func TestInterpreterTable_HandlesCanBeCreatedAndReleased(t *testing.T) {
+	table := interpreterTable{entries: map[uintptr]tosca.Interpreter{}}
+	first, err := table.create("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	second, err := table.create("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	if first == 0 || second == 0 || first == second {
+		t.Errorf("invalid handles: %d, %d", first, second)
+	}
+	if _, found := table.get(first); !found {
+		t.Errorf("interpreter of handle %d not found", first)
+	}
+	if !table.release(first) {
+		t.Errorf("failed to release handle %d", first)
+	}
+	if _, found := table.get(first); found {
+		t.Errorf("released handle %d is still valid", first)
+	}
+	if table.release(first) {
+		t.Errorf("releasing handle %d twice should fail", first)
+	}
+	if _, found := table.get(second); !found {
+		t.Errorf("interpreter of handle %d not found", second)
+	}
+}
+
+func TestInterpreterTable_UnknownInterpretersAreRejected(t *testing.T) {
+	table := interpreterTable{entries: map[uintptr]tosca.Interpreter{}}
+	if _, err := table.create("unknown"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestRun_InvalidHandlesAreRejected(t *testing.T) {
+	if _, err := run(0, tosca.Parameters{}); !errors.Is(err, errInvalidHandle) {
+		t.Errorf("unexpected error, wanted %v, got %v", errInvalidHandle, err)
+	}
+}
+
+func TestRun_ExecutesCodeOnInterpreterOfHandle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockRunContext(ctrl)
+	context.EXPECT().GetStorage(tosca.Address{}, tosca.Key{}).Return(tosca.Word{31: 5})
+
+	handle, err := interpreters.create("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	defer interpreters.release(handle)
+
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.SLOAD),
+		byte(vm.PUSH1), 0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, byte(vm.PUSH1), 0, byte(vm.RETURN),
+	}
+	result, err := run(handle, tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{Revision: tosca.R07_Istanbul},
+		Context:         context,
+		Gas:             10_000,
+		Code:            code,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("execution failed")
+	}
+	if want, got := byte(5), result.Output[31]; want != got {
+		t.Errorf("unexpected output, wanted %d, got %d", want, got)
+	}
+}
+
+func TestRun_PanicsAreReportedAsErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(tosca.Parameters) (tosca.Result, error) {
+		panic("boom")
+	})
+
+	interpreters.mutex.Lock()
+	interpreters.next++
+	handle := interpreters.next
+	interpreters.entries[handle] = interpreter
+	interpreters.mutex.Unlock()
+	defer interpreters.release(handle)
+
+	if _, err := run(handle, tosca.Parameters{}); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("unexpected error, got %v", err)
+	}
+}
+
+func TestHostContext_UnsetCallbacksActLikeEmptyState(t *testing.T) {
+	var context tosca.RunContext = &hostContext{}
+	address := tosca.Address{1}
+	key := tosca.Key{2}
+
+	if context.AccountExists(address) {
+		t.Errorf("account should not exist")
+	}
+	if want, got := (tosca.Value{}), context.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{}), context.GetStorage(address, key); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	if got := context.GetCode(address); len(got) != 0 {
+		t.Errorf("unexpected code, got %v", got)
+	}
+	if want, got := tosca.ColdAccess, context.AccessAccount(address); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	context.SetStorage(address, key, tosca.Word{3})
+	context.EmitLog(tosca.Log{Address: address, Topics: []tosca.Hash{{1}}, Data: []byte{1}})
+	result, err := context.Call(tosca.Call, tosca.CallParameters{Input: []byte{1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Success {
+		t.Errorf("calls should fail without a host")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+#ifndef TOSCA_H
+#define TOSCA_H
+
+#include <stdbool.h>
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+// The C interface of libtosca, enabling non-Go clients to run the
+// interpreters registered in Tosca. It mirrors the Go interface defined in the
+// tosca package; see there for the semantics of individual values.
+
+// All multi-byte values (words, values, hashes) are big-endian.
+typedef struct { uint8_t bytes[20]; } tosca_address;
+typedef struct { uint8_t bytes[32]; } tosca_word;
+
+// A byte sequence, owned by the side providing it.
+typedef struct {
+  const uint8_t* data;
+  size_t size;
+} tosca_bytes;
+
+// Status codes of libtosca functions.
+enum {
+  TOSCA_OK = 0,
+  TOSCA_ERROR_UNKNOWN_INTERPRETER = 1,
+  TOSCA_ERROR_INVALID_HANDLE = 2,
+  TOSCA_ERROR_UNSUPPORTED_REVISION = 3,
+  TOSCA_ERROR_EXECUTION = 4,
+};
+
+typedef struct {
+  // Block parameters.
+  tosca_word chain_id;
+  int64_t block_number;
+  int64_t timestamp;
+  tosca_address coinbase;
+  int64_t gas_limit;
+  tosca_word prev_randao;
+  tosca_word base_fee;
+  tosca_word blob_base_fee;
+  int32_t revision;
+
+  // Transaction parameters.
+  tosca_address origin;
+  tosca_word gas_price;
+  const tosca_word* blob_hashes;
+  size_t num_blob_hashes;
+
+  // Call parameters.
+  int32_t kind;
+  bool is_static;
+  int32_t depth;
+  int64_t gas;
+  tosca_address recipient;
+  tosca_address sender;
+  tosca_bytes input;
+  tosca_word value;
+  const tosca_word* code_hash;  // may be NULL if unknown
+  tosca_bytes code;
+} tosca_parameters;
+
+// The result of an execution. Memory referenced by a result is allocated by
+// libtosca and must be freed using tosca_release_result.
+typedef struct {
+  bool success;
+  uint8_t* output;
+  size_t output_size;
+  int64_t gas_left;
+  int64_t gas_refund;
+  bool self_destructed;
+  tosca_address beneficiary;  // only set if self_destructed is true
+  char* error;                // a description of errors, NULL if none
+} tosca_result;
+
+typedef struct {
+  tosca_address sender;
+  tosca_address recipient;
+  tosca_word value;
+  tosca_bytes input;
+  int64_t gas;
+  tosca_word salt;
+  tosca_address code_address;
+} tosca_call_parameters;
+
+typedef struct {
+  bool success;
+  tosca_bytes output;
+  int64_t gas_left;
+  int64_t gas_refund;
+  tosca_address created_address;
+} tosca_call_result;
+
+// The callbacks through which interpreters access the state of the host. The
+// first argument of every callback is the host handle passed to tosca_run.
+// Byte sequences returned by the host must remain valid until the next
+// callback is made for the same run. Callbacks left NULL behave as if the
+// state is empty: queries return zero values and updates are ignored.
+typedef struct {
+  bool (*account_exists)(void* host, const tosca_address* address);
+  void (*get_balance)(void* host, const tosca_address* address, tosca_word* result);
+  void (*set_balance)(void* host, const tosca_address* address, const tosca_word* value);
+  uint64_t (*get_nonce)(void* host, const tosca_address* address);
+  void (*set_nonce)(void* host, const tosca_address* address, uint64_t nonce);
+  tosca_bytes (*get_code)(void* host, const tosca_address* address);
+  void (*get_code_hash)(void* host, const tosca_address* address, tosca_word* result);
+  size_t (*get_code_size)(void* host, const tosca_address* address);
+  void (*set_code)(void* host, const tosca_address* address, tosca_bytes code);
+  void (*get_storage)(void* host, const tosca_address* address, const tosca_word* key, tosca_word* result);
+  int32_t (*set_storage)(void* host, const tosca_address* address, const tosca_word* key, const tosca_word* value);
+  bool (*self_destruct)(void* host, const tosca_address* address, const tosca_address* beneficiary);
+  int32_t (*create_snapshot)(void* host);
+  void (*restore_snapshot)(void* host, int32_t snapshot);
+  void (*get_transient_storage)(void* host, const tosca_address* address, const tosca_word* key, tosca_word* result);
+  void (*set_transient_storage)(void* host, const tosca_address* address, const tosca_word* key, const tosca_word* value);
+  bool (*access_account)(void* host, const tosca_address* address);  // true if warm
+  bool (*access_storage)(void* host, const tosca_address* address, const tosca_word* key);  // true if warm
+  void (*emit_log)(void* host, const tosca_address* address, const tosca_word* topics, size_t num_topics, tosca_bytes data);
+  void (*get_block_hash)(void* host, int64_t number, tosca_word* result);
+  void (*get_committed_storage)(void* host, const tosca_address* address, const tosca_word* key, tosca_word* result);
+  bool (*is_address_in_access_list)(void* host, const tosca_address* address);
+  void (*is_slot_in_access_list)(void* host, const tosca_address* address, const tosca_word* key, bool* address_present, bool* slot_present);
+  bool (*has_self_destructed)(void* host, const tosca_address* address);
+  void (*call)(void* host, int32_t kind, const tosca_call_parameters* parameters, tosca_call_result* result);
+} tosca_host_interface;
+
+// Interpreters are referred to by handles. A handle of 0 is never valid.
+typedef uintptr_t tosca_interpreter;
+
+// The functions are implemented in Go, whose generated declarations differ
+// from the following ones in const qualifiers; they are thus skipped when
+// building the library itself.
+#ifndef TOSCA_BUILDING_LIBRARY
+
+// Writes the comma-separated, NUL-terminated names of the available
+// interpreters into the given buffer, truncating them if needed. Returns the
+// length of the full list excluding the terminating NUL.
+size_t tosca_list_interpreters(char* buffer, size_t size);
+
+// Creates an instance of the interpreter with the given name and stores its
+// handle in the given location.
+int tosca_new_interpreter(const char* name, tosca_interpreter* interpreter);
+
+// Releases an interpreter handle.
+int tosca_release_interpreter(tosca_interpreter interpreter);
+
+// Runs the code described by the parameters on the given interpreter. The host
+// interface and handle are used to access the state during the execution. The
+// result is only defined if TOSCA_OK or TOSCA_ERROR_EXECUTION is returned; in
+// the latter case, its error field describes the issue.
+int tosca_run(tosca_interpreter interpreter, const tosca_parameters* parameters,
+              const tosca_host_interface* host_interface, void* host,
+              tosca_result* result);
+
+// Frees the memory referenced by the given result.
+void tosca_release_result(tosca_result* result);
+
+#endif  // TOSCA_BUILDING_LIBRARY
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif  // TOSCA_H
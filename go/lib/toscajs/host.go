@@ -0,0 +1,331 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// host abstracts the JavaScript object providing the world state of a run.
+// Arguments and results are restricted to the types exchangeable with
+// JavaScript: strings, float64 numbers, booleans, []any, and map[string]any.
+// Addresses, words, byte sequences, and numbers of arbitrary size are
+// encoded as hex strings.
+type host interface {
+	// provides reports whether the host implements the given method.
+	provides(method string) bool
+	// call invokes the given method of the host and returns its result, or
+	// nil if the method is not implemented.
+	call(method string, args ...any) any
+}
+
+// hostError is raised as a panic when a host returns a malformed result.
+// It is recovered at the end of a run and reported as an error.
+type hostError struct {
+	method string
+	err    error
+}
+
+func (e hostError) Error() string {
+	return fmt.Sprintf("invalid result of host method %s: %v", e.method, e.err)
+}
+
+// hostContext is a tosca.RunContext forwarding all operations to a host.
+// Methods not implemented by the host are treated as operating on an empty
+// state, except for code hashes and sizes, which are derived from the code,
+// and committed storage, which falls back to the current storage.
+type hostContext struct {
+	host host
+}
+
+func (c *hostContext) AccountExists(address tosca.Address) bool {
+	return c.getBool("accountExists", address.String())
+}
+
+func (c *hostContext) GetBalance(address tosca.Address) tosca.Value {
+	return tosca.Value(c.getWord("getBalance", address.String()))
+}
+
+func (c *hostContext) SetBalance(address tosca.Address, value tosca.Value) {
+	c.host.call("setBalance", address.String(), hexWord(value))
+}
+
+func (c *hostContext) GetNonce(address tosca.Address) uint64 {
+	return c.getUint64("getNonce", address.String())
+}
+
+func (c *hostContext) SetNonce(address tosca.Address, nonce uint64) {
+	c.host.call("setNonce", address.String(), hexutil.EncodeUint64(nonce))
+}
+
+func (c *hostContext) GetCode(address tosca.Address) tosca.Code {
+	return tosca.Code(c.getBytes("getCode", address.String()))
+}
+
+func (c *hostContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	if c.host.provides("getCodeHash") {
+		return tosca.Hash(c.getWord("getCodeHash", address.String()))
+	}
+	if !c.AccountExists(address) {
+		return tosca.Hash{}
+	}
+	return tosca.Hash(crypto.Keccak256Hash(c.GetCode(address)))
+}
+
+func (c *hostContext) GetCodeSize(address tosca.Address) int {
+	if c.host.provides("getCodeSize") {
+		return int(c.getUint64("getCodeSize", address.String()))
+	}
+	return len(c.GetCode(address))
+}
+
+func (c *hostContext) SetCode(address tosca.Address, code tosca.Code) {
+	c.host.call("setCode", address.String(), hexutil.Encode(code))
+}
+
+func (c *hostContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return tosca.Word(c.getWord("getStorage", address.String(), hexWord(key)))
+}
+
+func (c *hostContext) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	// The storage status is derived here to spare hosts its computation.
+	original := c.GetCommittedStorage(address, key)
+	current := c.GetStorage(address, key)
+	c.host.call("setStorage", address.String(), hexWord(key), hexWord(value))
+	return tosca.GetStorageStatus(original, current, value)
+}
+
+func (c *hostContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	return c.getBool("selfDestruct", address.String(), beneficiary.String())
+}
+
+func (c *hostContext) CreateSnapshot() tosca.Snapshot {
+	return tosca.Snapshot(c.getUint64("createSnapshot"))
+}
+
+func (c *hostContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	c.host.call("restoreSnapshot", float64(snapshot))
+}
+
+func (c *hostContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return tosca.Word(c.getWord("getTransientStorage", address.String(), hexWord(key)))
+}
+
+func (c *hostContext) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	c.host.call("setTransientStorage", address.String(), hexWord(key), hexWord(value))
+}
+
+func (c *hostContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	return tosca.AccessStatus(c.getBool("accessAccount", address.String()))
+}
+
+func (c *hostContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	return tosca.AccessStatus(c.getBool("accessStorage", address.String(), hexWord(key)))
+}
+
+func (c *hostContext) EmitLog(log tosca.Log) {
+	topics := make([]any, 0, len(log.Topics))
+	for _, topic := range log.Topics {
+		topics = append(topics, hexWord(topic))
+	}
+	c.host.call("emitLog", map[string]any{
+		"address": log.Address.String(),
+		"topics":  topics,
+		"data":    hexutil.Encode(log.Data),
+	})
+}
+
+func (c *hostContext) GetLogs() []tosca.Log {
+	// Logs are collected by the host, interpreters do not need to read them.
+	return nil
+}
+
+func (c *hostContext) GetBlockHash(number int64) tosca.Hash {
+	return tosca.Hash(c.getWord("getBlockHash", hexutil.EncodeUint64(uint64(number))))
+}
+
+func (c *hostContext) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if !c.host.provides("getCommittedStorage") {
+		return c.GetStorage(address, key)
+	}
+	return tosca.Word(c.getWord("getCommittedStorage", address.String(), hexWord(key)))
+}
+
+func (c *hostContext) IsAddressInAccessList(address tosca.Address) bool {
+	return c.getBool("isAddressInAccessList", address.String())
+}
+
+func (c *hostContext) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	res := c.getObject("isSlotInAccessList", address.String(), hexWord(key))
+	return toBool(res["address"]), toBool(res["slot"])
+}
+
+func (c *hostContext) HasSelfDestructed(address tosca.Address) bool {
+	return c.getBool("hasSelfDestructed", address.String())
+}
+
+func (c *hostContext) Call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	res := c.getObject("call", kind.String(), map[string]any{
+		"sender":      parameters.Sender.String(),
+		"recipient":   parameters.Recipient.String(),
+		"value":       hexWord(parameters.Value),
+		"input":       hexutil.Encode(parameters.Input),
+		"gas":         hexutil.EncodeUint64(uint64(parameters.Gas)),
+		"salt":        hexWord(parameters.Salt),
+		"codeAddress": parameters.CodeAddress.String(),
+	})
+	result := tosca.CallResult{Success: toBool(res["success"])}
+	var err error
+	if result.Output, err = toBytes(res["output"]); err != nil {
+		panic(hostError{"call", fmt.Errorf("invalid output: %w", err)})
+	}
+	gasLeft, err := toUint64(res["gasLeft"])
+	if err != nil {
+		panic(hostError{"call", fmt.Errorf("invalid gasLeft: %w", err)})
+	}
+	gasRefund, err := toUint64(res["gasRefund"])
+	if err != nil {
+		panic(hostError{"call", fmt.Errorf("invalid gasRefund: %w", err)})
+	}
+	createdAddress, err := toWord(res["createdAddress"])
+	if err != nil {
+		panic(hostError{"call", fmt.Errorf("invalid createdAddress: %w", err)})
+	}
+	result.GasLeft = tosca.Gas(gasLeft)
+	result.GasRefund = tosca.Gas(gasRefund)
+	result.CreatedAddress = tosca.Address(common.BytesToAddress(createdAddress[:]))
+	return result, nil
+}
+
+// --- argument and result conversion ---
+
+// hexWord encodes a 32-byte value as a hex string.
+func hexWord[T ~[32]byte](word T) string {
+	return hexutil.Encode(word[:])
+}
+
+func (c *hostContext) getBool(method string, args ...any) bool {
+	return toBool(c.host.call(method, args...))
+}
+
+func (c *hostContext) getUint64(method string, args ...any) uint64 {
+	res, err := toUint64(c.host.call(method, args...))
+	if err != nil {
+		panic(hostError{method, err})
+	}
+	return res
+}
+
+func (c *hostContext) getWord(method string, args ...any) [32]byte {
+	res, err := toWord(c.host.call(method, args...))
+	if err != nil {
+		panic(hostError{method, err})
+	}
+	return res
+}
+
+func (c *hostContext) getBytes(method string, args ...any) []byte {
+	res, err := toBytes(c.host.call(method, args...))
+	if err != nil {
+		panic(hostError{method, err})
+	}
+	return res
+}
+
+func (c *hostContext) getObject(method string, args ...any) map[string]any {
+	switch res := c.host.call(method, args...).(type) {
+	case nil:
+		return map[string]any{}
+	case map[string]any:
+		return res
+	default:
+		panic(hostError{method, fmt.Errorf("expected an object, got %T", res)})
+	}
+}
+
+// toBool interprets missing values as false and any other value according
+// to JavaScript's notion of truthiness for the supported types.
+func toBool(value any) bool {
+	switch value := value.(type) {
+	case bool:
+		return value
+	case float64:
+		return value != 0
+	case string:
+		return value != ""
+	default:
+		return value != nil
+	}
+}
+
+// toUint64 accepts numbers and hex strings, where missing values are zero.
+func toUint64(value any) (uint64, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		if value < 0 || value != float64(uint64(value)) {
+			return 0, fmt.Errorf("not an unsigned integer: %v", value)
+		}
+		return uint64(value), nil
+	case string:
+		return hexutil.DecodeUint64(value)
+	default:
+		return 0, fmt.Errorf("expected a number, got %T", value)
+	}
+}
+
+// toWord accepts numbers and hex strings of up to 32 bytes, which are left
+// padded with zeros. Missing values are zero.
+func toWord(value any) ([32]byte, error) {
+	var res [32]byte
+	switch value := value.(type) {
+	case nil:
+		return res, nil
+	case float64:
+		number, err := toUint64(value)
+		if err != nil {
+			return res, err
+		}
+		new(big.Int).SetUint64(number).FillBytes(res[:])
+		return res, nil
+	case string:
+		number, ok := new(big.Int).SetString(value, 0)
+		if !ok || number.Sign() < 0 || number.BitLen() > 256 {
+			return res, fmt.Errorf("invalid 32-byte hex string: %q", value)
+		}
+		number.FillBytes(res[:])
+		return res, nil
+	default:
+		return res, fmt.Errorf("expected a hex string, got %T", value)
+	}
+}
+
+// toBytes accepts hex strings, where missing values are empty.
+func toBytes(value any) ([]byte, error) {
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if value == "" || value == "0x" {
+			return nil, nil
+		}
+		return hexutil.Decode(value)
+	default:
+		return nil, fmt.Errorf("expected a hex string, got %T", value)
+	}
+}
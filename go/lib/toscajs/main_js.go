@@ -0,0 +1,80 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+//go:build js && wasm
+
+package main
+
+import "syscall/js"
+
+// main registers the API of the module as the global object toscaWasm and
+// keeps the module alive to serve calls from JavaScript.
+func main() {
+	names := []any{}
+	for _, name := range listInterpreters() {
+		names = append(names, name)
+	}
+	js.Global().Set("toscaWasm", map[string]any{
+		"interpreters": names,
+		"run": js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) != 2 || args[0].Type() != js.TypeString {
+				return encodeResult(runResult{Error: "expected parameters as JSON string and a host object"})
+			}
+			return run(args[0].String(), jsHost{args[1]})
+		}),
+	})
+	select {}
+}
+
+// jsHost is a host implemented by a JavaScript object.
+type jsHost struct {
+	object js.Value
+}
+
+func (h jsHost) provides(method string) bool {
+	return h.object.Type() == js.TypeObject && h.object.Get(method).Type() == js.TypeFunction
+}
+
+func (h jsHost) call(method string, args ...any) any {
+	if !h.provides(method) {
+		return nil
+	}
+	return goValue(h.object.Call(method, args...))
+}
+
+// goValue converts the given JavaScript value into the Go types supported
+// by the host interface.
+func goValue(value js.Value) any {
+	switch value.Type() {
+	case js.TypeBoolean:
+		return value.Bool()
+	case js.TypeNumber:
+		return value.Float()
+	case js.TypeString:
+		return value.String()
+	case js.TypeObject:
+		if js.Global().Get("Array").Call("isArray", value).Bool() {
+			res := make([]any, value.Length())
+			for i := range res {
+				res[i] = goValue(value.Index(i))
+			}
+			return res
+		}
+		res := map[string]any{}
+		keys := js.Global().Get("Object").Call("keys", value)
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			res[key] = goValue(value.Get(key))
+		}
+		return res
+	default:
+		return nil
+	}
+}
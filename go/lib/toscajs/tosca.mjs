@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// A thin wrapper around the tosca.wasm module built from this directory. The
+// wasm_exec.js support file of the Go distribution used to build the module
+// needs to be loaded before, since it defines the global Go class.
+//
+// Usage:
+//
+//   const tosca = await loadTosca("tosca.wasm");
+//   const result = tosca.run({ revision: "Cancun", code: "0x...", gas: "0x186a0" }, host);
+//
+// Parameters and results use hex strings for addresses, words, byte
+// sequences, and numbers, like Ethereum's JSON-RPC API. The host is an object
+// providing the world state through methods like getBalance(address),
+// getStorage(address, key), setStorage(address, key, value), or
+// call(kind, parameters). Missing methods are treated as operating on an
+// empty state. See host.go for the full list of methods.
+
+/**
+ * Loads the Tosca WebAssembly module from the given source, which is either
+ * a URL fetched by the browser or the bytes of the module.
+ */
+export async function loadTosca(source) {
+  if (typeof Go === "undefined") {
+    throw new Error("wasm_exec.js needs to be loaded before tosca.mjs");
+  }
+  const go = new Go();
+  let instance;
+  if (source instanceof ArrayBuffer || ArrayBuffer.isView(source)) {
+    ({ instance } = await WebAssembly.instantiate(source, go.importObject));
+  } else {
+    ({ instance } = await WebAssembly.instantiateStreaming(fetch(source), go.importObject));
+  }
+  // The module registers its API and keeps running until the page is closed.
+  go.run(instance);
+  if (typeof globalThis.toscaWasm === "undefined") {
+    throw new Error("failed to initialize the Tosca module");
+  }
+  return new Tosca(globalThis.toscaWasm);
+}
+
+/** Tosca provides access to the interpreters of a loaded module. */
+export class Tosca {
+  constructor(module) {
+    this.module = module;
+  }
+
+  /** The names of the interpreters available in the module. */
+  get interpreters() {
+    return [...this.module.interpreters];
+  }
+
+  /**
+   * Runs the code described by the given parameters on the state provided
+   * by the given host and returns the result of the execution. Errors of the
+   * interpreter or the host are thrown as exceptions.
+   */
+  run(parameters, host = {}) {
+    const result = JSON.parse(this.module.run(JSON.stringify(parameters), host));
+    if (result.error) {
+      throw new Error(result.error);
+    }
+    return result;
+  }
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package main provides toscajs, a WebAssembly module exposing the Tosca
+// interpreters supporting WebAssembly targets to JavaScript. This enables the
+// simulation of transactions in the browser, where the world state, for
+// instance fetched from a Sonic node, is provided by a JavaScript host object.
+// To build the module, run
+//
+//	GOOS=js GOARCH=wasm go build -o tosca.wasm ./go/lib/toscajs
+//
+// or use the toscajs target of the Makefile. The module is loaded using the
+// tosca.mjs wrapper in this directory.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+// runParameters is the JSON encoding of the parameters of a run as provided
+// by JavaScript clients. Numbers of arbitrary size are encoded as hex strings.
+type runParameters struct {
+	Interpreter string          `json:"interpreter"` // < lfvm if missing
+	Revision    *tosca.Revision `json:"revision"`    // < the newest revision if missing
+	ChainID     *hexutil.Big    `json:"chainId"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+	Timestamp   hexutil.Uint64  `json:"timestamp"`
+	Coinbase    common.Address  `json:"coinbase"`
+	GasLimit    hexutil.Uint64  `json:"gasLimit"`
+	PrevRandao  common.Hash     `json:"prevRandao"`
+	BaseFee     *hexutil.Big    `json:"baseFee"`
+	BlobBaseFee *hexutil.Big    `json:"blobBaseFee"`
+	Origin      common.Address  `json:"origin"`
+	GasPrice    *hexutil.Big    `json:"gasPrice"`
+	BlobHashes  []common.Hash   `json:"blobHashes"`
+	Kind        tosca.CallKind  `json:"kind"`
+	Static      bool            `json:"static"`
+	Depth       int             `json:"depth"`
+	Gas         hexutil.Uint64  `json:"gas"`
+	Recipient   common.Address  `json:"recipient"`
+	Sender      common.Address  `json:"sender"`
+	Input       hexutil.Bytes   `json:"input"`
+	Value       *hexutil.Big    `json:"value"`
+	CodeHash    *common.Hash    `json:"codeHash"`
+	Code        hexutil.Bytes   `json:"code"`
+}
+
+// toParameters converts the JSON parameters into interpreter parameters.
+func (p runParameters) toParameters() (tosca.Parameters, error) {
+	revisions := tosca.GetAllKnownRevisions()
+	revision := revisions[len(revisions)-1]
+	if p.Revision != nil {
+		revision = *p.Revision
+	}
+	if p.Gas > hexutil.Uint64(1<<63-1) {
+		return tosca.Parameters{}, fmt.Errorf("invalid gas: %d", p.Gas)
+	}
+
+	params := tosca.Parameters{
+		BlockParameters: tosca.BlockParameters{
+			ChainID:     tosca.Word(toValue(p.ChainID)),
+			BlockNumber: int64(p.BlockNumber),
+			Timestamp:   int64(p.Timestamp),
+			Coinbase:    tosca.Address(p.Coinbase),
+			GasLimit:    tosca.Gas(p.GasLimit),
+			PrevRandao:  tosca.Hash(p.PrevRandao),
+			BaseFee:     toValue(p.BaseFee),
+			BlobBaseFee: toValue(p.BlobBaseFee),
+			Revision:    revision,
+		},
+		TransactionParameters: tosca.TransactionParameters{
+			Origin:   tosca.Address(p.Origin),
+			GasPrice: toValue(p.GasPrice),
+		},
+		Kind:      p.Kind,
+		Static:    p.Static,
+		Depth:     p.Depth,
+		Gas:       tosca.Gas(p.Gas),
+		Recipient: tosca.Address(p.Recipient),
+		Sender:    tosca.Address(p.Sender),
+		Input:     tosca.Data(p.Input),
+		Value:     toValue(p.Value),
+		Code:      tosca.Code(p.Code),
+	}
+	for _, hash := range p.BlobHashes {
+		params.BlobHashes = append(params.BlobHashes, tosca.Hash(hash))
+	}
+	if p.CodeHash != nil {
+		hash := tosca.Hash(*p.CodeHash)
+		params.CodeHash = &hash
+	}
+	return params, nil
+}
+
+// toValue converts the given number into a value, where nil is mapped to
+// zero. The JSON decoding of hexutil.Big ensures that the number is not
+// negative and fits into 256 bits.
+func toValue(value *hexutil.Big) tosca.Value {
+	if value == nil {
+		return tosca.Value{}
+	}
+	return tosca.ValueFromUint256(uint256.MustFromBig(value.ToInt()))
+}
+
+// runResult is the JSON encoding of the result of a run.
+type runResult struct {
+	Success        bool            `json:"success"`
+	Output         hexutil.Bytes   `json:"output"`
+	GasLeft        hexutil.Uint64  `json:"gasLeft"`
+	GasRefund      hexutil.Uint64  `json:"gasRefund"`
+	SelfDestructed bool            `json:"selfDestructed"`
+	Beneficiary    *common.Address `json:"beneficiary,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// newRunResult converts the given interpreter result into its JSON encoding.
+func newRunResult(result tosca.Result) runResult {
+	res := runResult{
+		Success:   result.Success,
+		Output:    hexutil.Bytes(result.Output),
+		GasLeft:   hexutil.Uint64(max(result.GasLeft, 0)),
+		GasRefund: hexutil.Uint64(max(result.GasRefund, 0)),
+	}
+	if result.SelfDestruction != nil {
+		beneficiary := common.Address(result.SelfDestruction.Beneficiary)
+		res.SelfDestructed = true
+		res.Beneficiary = &beneficiary
+	}
+	return res
+}
+
+// run executes the code described by the given JSON parameters on the state
+// provided by the given host and returns the JSON encoded result. Failures,
+// including panics of the interpreter, are reported through the error field
+// of the result, since they must not cross the JavaScript boundary.
+func run(parameters string, host host) (result string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(hostError); ok {
+				result = encodeResult(runResult{Error: err.Error()})
+			} else {
+				result = encodeResult(runResult{Error: fmt.Sprintf("interpreter panicked: %v", r)})
+			}
+		}
+	}()
+	res, err := doRun(parameters, host)
+	if err != nil {
+		return encodeResult(runResult{Error: err.Error()})
+	}
+	return encodeResult(newRunResult(res))
+}
+
+func doRun(parameters string, host host) (tosca.Result, error) {
+	var input runParameters
+	if err := json.Unmarshal([]byte(parameters), &input); err != nil {
+		return tosca.Result{}, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+	params, err := input.toParameters()
+	if err != nil {
+		return tosca.Result{}, err
+	}
+	name := input.Interpreter
+	if name == "" {
+		name = "lfvm"
+	}
+	interpreter, err := interpreters.get(name)
+	if err != nil {
+		return tosca.Result{}, err
+	}
+	params.Context = &hostContext{host: host}
+	result, err := interpreter.Run(params)
+	if err == nil {
+		// Hosts observe all self-destructions through the selfDestruct
+		// callback, independent of the interpreter in use.
+		tosca.FinalizeSelfDestruction(params.Context, params.Recipient, result.SelfDestruction)
+	}
+	return result, err
+}
+
+func encodeResult(result runResult) string {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(data)
+}
+
+// listInterpreters returns the names of all registered interpreters in
+// alphabetical order.
+func listInterpreters() []string {
+	infos := tosca.ListInterpreters()
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+// interpreterCache retains the interpreters used by runs, such that code
+// caches are preserved across runs. WebAssembly modules are single-threaded,
+// so no synchronization is needed.
+type interpreterCache map[string]tosca.Interpreter
+
+var interpreters = interpreterCache{}
+
+func (c interpreterCache) get(name string) (tosca.Interpreter, error) {
+	if interpreter, found := c[name]; found {
+		return interpreter, nil
+	}
+	interpreter, err := tosca.NewInterpreter(name)
+	if err != nil {
+		return nil, err
+	}
+	c[name] = interpreter
+	return interpreter, nil
+}
@@ -0,0 +1,260 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// fakeHost is a host implemented by a set of Go functions.
+type fakeHost map[string]func(args ...any) any
+
+func (h fakeHost) provides(method string) bool {
+	_, found := h[method]
+	return found
+}
+
+func (h fakeHost) call(method string, args ...any) any {
+	if f, found := h[method]; found {
+		return f(args...)
+	}
+	return nil
+}
+
+func runJSON(t *testing.T, parameters string, host host) runResult {
+	t.Helper()
+	var result runResult
+	if err := json.Unmarshal([]byte(run(parameters, host)), &result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	return result
+}
+
+// incrementCode increments the value of storage slot 0 and returns the new
+// value as a 32-byte word.
+var incrementCode = hexutil.Encode([]byte{
+	byte(vm.PUSH1), 0,
+	byte(vm.SLOAD),
+	byte(vm.PUSH1), 1,
+	byte(vm.ADD),
+	byte(vm.DUP1),
+	byte(vm.PUSH1), 0,
+	byte(vm.SSTORE),
+	byte(vm.PUSH1), 0,
+	byte(vm.MSTORE),
+	byte(vm.PUSH1), 32,
+	byte(vm.PUSH1), 0,
+	byte(vm.RETURN),
+})
+
+func TestRun_ExecutesCodeOnHostState(t *testing.T) {
+	storage := map[string]string{}
+	host := fakeHost{
+		"getStorage": func(args ...any) any {
+			if value, found := storage[args[0].(string)+args[1].(string)]; found {
+				return value
+			}
+			return nil
+		},
+		"setStorage": func(args ...any) any {
+			storage[args[0].(string)+args[1].(string)] = args[2].(string)
+			return nil
+		},
+	}
+	parameters := `{"revision": "Cancun", "gas": "0x186a0", "code": "` + incrementCode + `"}`
+	for i := byte(1); i <= 2; i++ {
+		result := runJSON(t, parameters, host)
+		if result.Error != "" {
+			t.Fatalf("unexpected error: %v", result.Error)
+		}
+		if !result.Success {
+			t.Fatalf("execution failed")
+		}
+		if want, got := i, result.Output[31]; want != got {
+			t.Errorf("unexpected output, wanted %d, got %d", want, got)
+		}
+		if result.GasLeft == 0 || result.GasLeft >= 100_000 {
+			t.Errorf("unexpected gas left: %d", result.GasLeft)
+		}
+	}
+}
+
+func TestRun_ForwardsCallsToHost(t *testing.T) {
+	code := hexutil.Encode([]byte{
+		byte(vm.PUSH1), 32, // output size
+		byte(vm.PUSH1), 0, // output offset
+		byte(vm.PUSH1), 0, // input size
+		byte(vm.PUSH1), 0, // input offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.PUSH1), 0x10, // address
+		byte(vm.PUSH2), 0xff, 0xff, // gas
+		byte(vm.CALL),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	var kind string
+	var parameters map[string]any
+	host := fakeHost{
+		"call": func(args ...any) any {
+			kind = args[0].(string)
+			parameters = args[1].(map[string]any)
+			return map[string]any{"success": true, "output": "0x2a", "gasLeft": float64(7)}
+		},
+	}
+	result := runJSON(t, `{"gas": "0x186a0", "recipient": "0x0000000000000000000000000000000000000100", "code": "`+code+`"}`, host)
+	if result.Error != "" || !result.Success {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if want, got := "call", kind; want != got {
+		t.Errorf("unexpected call kind, wanted %s, got %s", want, got)
+	}
+	if want, got := "0x0000000000000000000000000000000000000010", parameters["recipient"]; want != got {
+		t.Errorf("unexpected recipient, wanted %v, got %v", want, got)
+	}
+	if want, got := "0x0000000000000000000000000000000000000100", parameters["sender"]; want != got {
+		t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+	}
+	if want, got := byte(0x2a), result.Output[0]; want != got {
+		t.Errorf("unexpected output, wanted %d, got %d", want, got)
+	}
+}
+
+func TestRun_SelfDestructionsAreAppliedThroughHost(t *testing.T) {
+	code := hexutil.Encode([]byte{
+		byte(vm.PUSH1), 0xbe,
+		byte(vm.SELFDESTRUCT),
+	})
+	var destructed []any
+	host := fakeHost{
+		"selfDestruct": func(args ...any) any {
+			destructed = args
+			return true
+		},
+	}
+	result := runJSON(t, `{"gas": "0x186a0", "recipient": "0x0000000000000000000000000000000000000100", "code": "`+code+`"}`, host)
+	if result.Error != "" || !result.Success || !result.SelfDestructed {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := []any{
+		"0x0000000000000000000000000000000000000100",
+		"0x00000000000000000000000000000000000000be",
+	}
+	if !reflect.DeepEqual(want, destructed) {
+		t.Errorf("unexpected self-destruction, wanted %v, got %v", want, destructed)
+	}
+}
+
+func TestRun_ReportsErrors(t *testing.T) {
+	balanceCode := hexutil.Encode([]byte{byte(vm.PUSH1), 0, byte(vm.BALANCE)})
+	tests := map[string]struct {
+		parameters string
+		host       fakeHost
+		want       string
+	}{
+		"malformed parameters": {"{", nil, "failed to parse parameters"},
+		"negative value":       {`{"value": "-0x1"}`, nil, "failed to parse parameters"},
+		"oversized value":      {`{"value": "0x1` + strings.Repeat("0", 64) + `"}`, nil, "failed to parse parameters"},
+		"unknown interpreter":  {`{"interpreter": "unknown"}`, nil, "unknown"},
+		"invalid host result": {
+			`{"gas": "0xffff", "code": "` + balanceCode + `"}`,
+			fakeHost{"getBalance": func(...any) any { return "xyz" }},
+			"invalid result of host method getBalance",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := runJSON(t, test.parameters, test.host)
+			if !strings.Contains(result.Error, test.want) {
+				t.Errorf("unexpected error, wanted %q, got %q", test.want, result.Error)
+			}
+		})
+	}
+}
+
+func TestRunParameters_DefaultsToNewestRevision(t *testing.T) {
+	params, err := runParameters{}.toParameters()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	revisions := tosca.GetAllKnownRevisions()
+	if want, got := revisions[len(revisions)-1], params.Revision; want != got {
+		t.Errorf("unexpected revision, wanted %v, got %v", want, got)
+	}
+}
+
+func TestHostContext_MissingMethodsOperateOnEmptyState(t *testing.T) {
+	context := &hostContext{host: fakeHost{}}
+	address := tosca.Address{1}
+	if context.AccountExists(address) {
+		t.Errorf("unexpected account")
+	}
+	if want, got := (tosca.Value{}), context.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Hash{}), context.GetCodeHash(address); want != got {
+		t.Errorf("unexpected code hash, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.StorageAdded, context.SetStorage(address, tosca.Key{}, tosca.Word{1}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+}
+
+func TestHostContext_DerivesCodeHashAndSizeFromCode(t *testing.T) {
+	code := []byte{byte(vm.STOP)}
+	context := &hostContext{host: fakeHost{
+		"accountExists": func(...any) any { return true },
+		"getCode":       func(...any) any { return hexutil.Encode(code) },
+	}}
+	address := tosca.Address{1}
+	if want, got := 1, context.GetCodeSize(address); want != got {
+		t.Errorf("unexpected code size, wanted %d, got %d", want, got)
+	}
+	want := tosca.Hash{0xbc, 0x36, 0x78, 0x9e, 0x7a, 0x1e, 0x28, 0x14, 0x36, 0x46, 0x42, 0x29, 0x82, 0x8f, 0x81, 0x7d, 0x66, 0x12, 0xf7, 0xb4, 0x77, 0xd6, 0x65, 0x91, 0xff, 0x96, 0xa9, 0xe0, 0x64, 0xbc, 0xc9, 0x8a}
+	if got := context.GetCodeHash(address); want != got {
+		t.Errorf("unexpected code hash, wanted %v, got %v", want, got)
+	}
+}
+
+func TestToWord_AcceptsNumbersAndShortHexStrings(t *testing.T) {
+	tests := map[string]struct {
+		value any
+		want  byte
+	}{
+		"nil":        {nil, 0},
+		"number":     {float64(42), 42},
+		"short hex":  {"0x2a", 42},
+		"padded hex": {"0x" + strings.Repeat("0", 62) + "2a", 42},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := toWord(test.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want := ([32]byte{31: test.want}); want != got {
+				t.Errorf("unexpected word, wanted %x, got %x", want, got)
+			}
+		})
+	}
+	for _, value := range []any{"xyz", "-0x1", "0x1" + strings.Repeat("0", 64), float64(-1), true} {
+		if _, err := toWord(value); err == nil {
+			t.Errorf("expected an error for %v", value)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package aa provides helpers for bundlers of ERC-4337 user operations. It
+// simulates the validation and execution phases of user operations on top
+// of any simulating processor and checks the code executed during the
+// validation phase against the opcode and storage rules of ERC-7562.
+// Violations are reported in a structured form, such that bundlers can
+// decide whether to reject an operation or to throttle the entities
+// involved.
+//
+// The rules covering the reputation of entities and the interaction between
+// multiple operations of a bundle are not checked, since they depend on the
+// state of the bundler rather than on a single simulation.
+package aa
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+// Entity identifies the role of a contract in a user operation.
+type Entity string
+
+const (
+	Sender    Entity = "sender"
+	Factory   Entity = "factory"
+	Paymaster Entity = "paymaster"
+)
+
+// UserOperation summarizes the properties of a user operation relevant for
+// checking the validation rules.
+type UserOperation struct {
+	Sender    tosca.Address
+	Factory   *tosca.Address // < nil if the sender is already deployed
+	Paymaster *tosca.Address // < nil if the sender pays for the operation
+	Staked    []Entity       // < the entities having a stake in the entry point
+}
+
+// entityOf returns the role of the given address in the operation, or the
+// empty entity if it is not involved.
+func (op UserOperation) entityOf(address tosca.Address) Entity {
+	switch {
+	case address == op.Sender:
+		return Sender
+	case op.Factory != nil && address == *op.Factory:
+		return Factory
+	case op.Paymaster != nil && address == *op.Paymaster:
+		return Paymaster
+	}
+	return ""
+}
+
+// addressOf returns the address of the given entity of the operation.
+func (op UserOperation) addressOf(entity Entity) (tosca.Address, bool) {
+	switch {
+	case entity == Sender:
+		return op.Sender, true
+	case entity == Factory && op.Factory != nil:
+		return *op.Factory, true
+	case entity == Paymaster && op.Paymaster != nil:
+		return *op.Paymaster, true
+	}
+	return tosca.Address{}, false
+}
+
+func (op UserOperation) isStaked(entity Entity) bool {
+	for _, staked := range op.Staked {
+		if staked == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// Violation describes an instruction executed during the validation of a
+// user operation violating one of the rules of ERC-7562.
+type Violation struct {
+	Rule    string        // < the identifier of the rule in ERC-7562, e.g. OP-011
+	Entity  Entity        // < the entity whose validation violated the rule
+	Address tosca.Address // < the account whose code violated the rule
+	Pc      int           // < the position of the offending instruction
+	OpCode  vm.OpCode     // < the offending instruction
+	Reason  string        // < a description of the violation
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s validation at %v, pc %d, %v: %s", v.Rule, v.Entity, v.Address, v.Pc, v.OpCode, v.Reason)
+}
+
+// ValidationResult is the result of simulating the validation of a user
+// operation.
+type ValidationResult struct {
+	tosca.SimulationResult
+	Violations []Violation
+}
+
+// Valid reports whether the validation completed successfully without
+// violating any rules.
+func (r ValidationResult) Valid() bool {
+	return !r.Aborted && r.Success && len(r.Violations) == 0
+}
+
+// Result is the result of simulating both phases of a user operation.
+type Result struct {
+	Validation ValidationResult
+	Execution  *tosca.SimulationResult // < nil if the validation failed
+}
+
+// Simulator simulates user operations handled by an entry point contract.
+type Simulator struct {
+	processor  tosca.SimulatingProcessor
+	entryPoint tosca.Address
+}
+
+// NewSimulator creates a simulator running transactions on the given
+// processor, where the given address is the entry point handling the
+// simulated user operations.
+func NewSimulator(processor tosca.SimulatingProcessor, entryPoint tosca.Address) *Simulator {
+	return &Simulator{processor: processor, entryPoint: entryPoint}
+}
+
+// SimulateValidation simulates the given transaction, which is expected to
+// make the entry point validate the given user operation, for instance by
+// calling simulateValidation. Code executed on behalf of the entities of the
+// operation is checked against the validation rules, while the code of the
+// entry point itself is not restricted. The tracer of the given options is
+// not supported, since it is used for the checks.
+func (s *Simulator) SimulateValidation(
+	ctx context.Context,
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	transactionContext tosca.TransactionContext,
+	op UserOperation,
+	options tosca.SimulationOptions,
+) (ValidationResult, error) {
+	if options.Tracer != nil {
+		return ValidationResult{}, fmt.Errorf("tracers are not supported for validation simulations")
+	}
+	checker := newRuleChecker(op, s.entryPoint, transactionContext)
+	options.Tracer = checker
+	result, err := s.processor.Simulate(ctx, blockParameters, transaction, transactionContext, options)
+	if err != nil {
+		return ValidationResult{}, err
+	}
+	return ValidationResult{
+		SimulationResult: result,
+		Violations:       checker.violations,
+	}, nil
+}
+
+// SimulateUserOperation simulates the validation of a user operation by the
+// given validation transaction, as by SimulateValidation, followed by its
+// execution by the given execution transaction on the resulting state. The
+// execution phase is not restricted and only simulated if the validation
+// was successful and did not violate any rules.
+func (s *Simulator) SimulateUserOperation(
+	ctx context.Context,
+	blockParameters tosca.BlockParameters,
+	validation tosca.Transaction,
+	execution tosca.Transaction,
+	transactionContext tosca.TransactionContext,
+	op UserOperation,
+	options tosca.SimulationOptions,
+) (Result, error) {
+	validationResult, err := s.SimulateValidation(ctx, blockParameters, validation, transactionContext, op, options)
+	if err != nil || !validationResult.Valid() {
+		return Result{Validation: validationResult}, err
+	}
+	executionResult, err := s.processor.Simulate(ctx, blockParameters, execution, transactionContext, options)
+	if err != nil {
+		return Result{Validation: validationResult}, err
+	}
+	return Result{Validation: validationResult, Execution: &executionResult}, nil
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package aa
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"go.uber.org/mock/gomock"
+)
+
+// simulateValidationFrame returns a simulation of a transaction in which the
+// entry point calls the sender, which executes the given instruction.
+func simulateValidationFrame(op vm.OpCode) func(context.Context, tosca.BlockParameters, tosca.Transaction, tosca.TransactionContext, tosca.SimulationOptions) (tosca.SimulationResult, error) {
+	return func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, _ tosca.TransactionContext, options tosca.SimulationOptions) (tosca.SimulationResult, error) {
+		tracer := options.Tracer
+		tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: entryPoint})
+		tracer.OnEnter(1, tosca.Call, tosca.CallParameters{Sender: entryPoint, Recipient: sender})
+		tracer.OnOpcode(tosca.OpCodeState{OpCode: op, Address: sender, Depth: 1})
+		tracer.OnExit(1, tosca.CallResult{Success: true}, nil)
+		tracer.OnExit(0, tosca.CallResult{Success: true}, nil)
+		return tosca.SimulationResult{Receipt: tosca.Receipt{Success: true}}, nil
+	}
+}
+
+func TestSimulator_SimulateValidationReportsViolations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockSimulatingProcessor(ctrl)
+	processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(simulateValidationFrame(vm.TIMESTAMP))
+
+	simulator := NewSimulator(processor, entryPoint)
+	result, err := simulator.SimulateValidation(
+		context.Background(), tosca.BlockParameters{}, tosca.Transaction{}, nil,
+		UserOperation{Sender: sender}, tosca.SimulationOptions{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Valid() {
+		t.Errorf("validation with banned instruction reported as valid")
+	}
+	want := Violation{
+		Rule:    "OP-011",
+		Entity:  Sender,
+		Address: sender,
+		OpCode:  vm.TIMESTAMP,
+		Reason:  "TIMESTAMP is banned",
+	}
+	if len(result.Violations) != 1 || result.Violations[0] != want {
+		t.Errorf("unexpected violations, wanted %v, got %v", want, result.Violations)
+	}
+}
+
+func TestSimulator_SimulateValidationRejectsCustomTracers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockSimulatingProcessor(ctrl)
+	simulator := NewSimulator(processor, entryPoint)
+	_, err := simulator.SimulateValidation(
+		context.Background(), tosca.BlockParameters{}, tosca.Transaction{}, nil,
+		UserOperation{Sender: sender}, tosca.SimulationOptions{Tracer: tosca.NoOpTracer{}},
+	)
+	if err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestSimulator_SimulateUserOperationExecutesOnlyValidOperations(t *testing.T) {
+	tests := map[string]struct {
+		op       vm.OpCode
+		executed bool
+	}{
+		"valid":   {op: vm.ADD, executed: true},
+		"invalid": {op: vm.ORIGIN, executed: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			processor := tosca.NewMockSimulatingProcessor(ctrl)
+			validation := tosca.Transaction{Nonce: 1}
+			execution := tosca.Transaction{Nonce: 2}
+			processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), validation, gomock.Any(), gomock.Any()).
+				DoAndReturn(simulateValidationFrame(test.op))
+			if test.executed {
+				processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), execution, gomock.Any(), tosca.SimulationOptions{}).
+					Return(tosca.SimulationResult{Receipt: tosca.Receipt{Success: true}}, nil)
+			}
+
+			simulator := NewSimulator(processor, entryPoint)
+			result, err := simulator.SimulateUserOperation(
+				context.Background(), tosca.BlockParameters{}, validation, execution, nil,
+				UserOperation{Sender: sender}, tosca.SimulationOptions{},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := test.executed, result.Execution != nil; want != got {
+				t.Errorf("unexpected execution, wanted %t, got %t", want, got)
+			}
+			if want, got := test.executed, result.Validation.Valid(); want != got {
+				t.Errorf("unexpected validity, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestViolation_String(t *testing.T) {
+	violation := Violation{Rule: "OP-011", Entity: Paymaster, Address: paymaster, Pc: 12, OpCode: vm.NUMBER, Reason: "NUMBER is banned"}
+	want := "OP-011: paymaster validation at 0x0300000000000000000000000000000000000000, pc 12, NUMBER: NUMBER is banned"
+	if got := violation.String(); want != got {
+		t.Errorf("unexpected string, wanted %q, got %q", want, got)
+	}
+}
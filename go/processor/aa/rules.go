@@ -0,0 +1,217 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package aa
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// bannedOpCodes lists the instructions entities must not use during the
+// validation, as their results may differ between the simulation and the
+// inclusion of the operation in a block (OP-011).
+var bannedOpCodes = map[vm.OpCode]bool{
+	vm.GASPRICE:     true,
+	vm.GASLIMIT:     true,
+	vm.PREVRANDAO:   true,
+	vm.TIMESTAMP:    true,
+	vm.BASEFEE:      true,
+	vm.BLOCKHASH:    true,
+	vm.NUMBER:       true,
+	vm.ORIGIN:       true,
+	vm.CREATE:       true,
+	vm.COINBASE:     true,
+	vm.SELFDESTRUCT: true,
+	vm.INVALID:      true,
+	vm.BLOBHASH:     true,
+	vm.BLOBBASEFEE:  true,
+}
+
+// maxAssociatedOffset is the maximum offset of a slot from the hash of a key
+// starting with the sender's address for the slot to be associated with the
+// sender, covering structs stored in mappings (STO-021).
+const maxAssociatedOffset = 128
+
+// ruleChecker is a tracer checking the code executed on behalf of the
+// entities of a user operation against the validation rules of ERC-7562.
+type ruleChecker struct {
+	tosca.NoOpTracer
+	op         UserOperation
+	entryPoint tosca.Address
+	state      tosca.WorldState
+
+	frames     []Entity           // < the entity of each active call, empty if none
+	associated []uint256.Int      // < hashes of keys starting with the sender's address
+	created    bool               // < whether the factory used CREATE2
+	gas        *tosca.OpCodeState // < the last GAS instruction, if not yet followed by another
+	violations []Violation
+}
+
+func newRuleChecker(op UserOperation, entryPoint tosca.Address, state tosca.WorldState) *ruleChecker {
+	return &ruleChecker{op: op, entryPoint: entryPoint, state: state}
+}
+
+func (c *ruleChecker) OnEnter(depth int, kind tosca.CallKind, parameters tosca.CallParameters) {
+	// Code called by an entity, including libraries and other contracts,
+	// is executed on behalf of the calling entity.
+	var entity Entity
+	if len(c.frames) > 0 {
+		entity = c.frames[len(c.frames)-1]
+	}
+	if entity == "" && (kind == tosca.Call || kind == tosca.StaticCall) {
+		entity = c.op.entityOf(parameters.Recipient)
+	}
+	c.frames = append(c.frames, entity)
+}
+
+func (c *ruleChecker) OnExit(depth int, result tosca.CallResult, err error) {
+	if len(c.frames) > 0 {
+		c.frames = c.frames[:len(c.frames)-1]
+	}
+	c.gas = nil
+}
+
+func (c *ruleChecker) OnOpcode(state tosca.OpCodeState) {
+	if len(c.frames) == 0 {
+		return
+	}
+	entity := c.frames[len(c.frames)-1]
+	if entity == "" {
+		return
+	}
+
+	if gas := c.gas; gas != nil {
+		c.gas = nil
+		if !isCall(state.OpCode) {
+			c.report(entity, *gas, "OP-012", "GAS is only allowed if followed by a call")
+		}
+	}
+
+	op := state.OpCode
+	switch {
+	case bannedOpCodes[op]:
+		c.report(entity, state, "OP-011", fmt.Sprintf("%v is banned", op))
+	case op == vm.GAS:
+		gas := state
+		gas.Stack, gas.Memory = nil, nil // < only valid during this call
+		c.gas = &gas
+	case op == vm.BALANCE || op == vm.SELFBALANCE:
+		if !c.op.isStaked(entity) {
+			c.report(entity, state, "OP-080", fmt.Sprintf("%v is only allowed for staked entities", op))
+		}
+	case op == vm.CREATE2:
+		if entity != Factory || c.created {
+			c.report(entity, state, "OP-031", "CREATE2 is only allowed once by the factory")
+		}
+		c.created = true
+	case op == vm.SLOAD || op == vm.SSTORE || op == vm.TLOAD || op == vm.TSTORE:
+		c.checkStorageAccess(entity, state)
+	case op == vm.SHA3:
+		c.recordHash(state)
+	case op == vm.EXTCODEHASH || op == vm.EXTCODESIZE || op == vm.EXTCODECOPY:
+		address := toAddress(peek(state.Stack, 0))
+		if address != c.op.Sender && c.state.GetCodeSize(address) == 0 {
+			c.report(entity, state, "OP-041", fmt.Sprintf("%v of account %v without code", op, address))
+		}
+	case op == vm.CALL || op == vm.CALLCODE:
+		target := toAddress(peek(state.Stack, 1))
+		if peek(state.Stack, 2) != (tosca.Word{}) && target != c.entryPoint {
+			c.report(entity, state, "OP-061", fmt.Sprintf("%v with value to %v", op, target))
+		}
+	}
+}
+
+// checkStorageAccess checks that the given storage access is covered by the
+// storage rules (STO-010, STO-021, and STO-031).
+func (c *ruleChecker) checkStorageAccess(entity Entity, state tosca.OpCodeState) {
+	key := peek(state.Stack, 0)
+	if state.Address == c.op.Sender || c.isAssociated(key) {
+		return
+	}
+	if address, found := c.op.addressOf(entity); found && state.Address == address && c.op.isStaked(entity) {
+		return
+	}
+	c.report(entity, state, "STO-021", fmt.Sprintf("access to slot %v of %v not associated with the sender", key, state.Address))
+}
+
+// recordHash records the result of a SHA3 instruction hashing a key starting
+// with the sender's address, whose slots are associated with the sender.
+func (c *ruleChecker) recordHash(state tosca.OpCodeState) {
+	offset := toUint64(peek(state.Stack, 0))
+	size := toUint64(peek(state.Stack, 1))
+	if size < 32 || size > 1<<16 || offset > 1<<32 {
+		return
+	}
+	data := make([]byte, size)
+	if offset < uint64(len(state.Memory)) {
+		copy(data, state.Memory[offset:])
+	}
+	var prefix tosca.Word
+	copy(prefix[12:], c.op.Sender[:])
+	if tosca.Word(data[:32]) != prefix {
+		return
+	}
+	var hash uint256.Int
+	hash.SetBytes32(crypto.Keccak256(data))
+	c.associated = append(c.associated, hash)
+}
+
+func (c *ruleChecker) isAssociated(key tosca.Word) bool {
+	var slot, offset uint256.Int
+	slot.SetBytes32(key[:])
+	for i := range c.associated {
+		if offset.Sub(&slot, &c.associated[i]).LtUint64(maxAssociatedOffset + 1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *ruleChecker) report(entity Entity, state tosca.OpCodeState, rule string, reason string) {
+	c.violations = append(c.violations, Violation{
+		Rule:    rule,
+		Entity:  entity,
+		Address: state.Address,
+		Pc:      state.Pc,
+		OpCode:  state.OpCode,
+		Reason:  reason,
+	})
+}
+
+func isCall(op vm.OpCode) bool {
+	return op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL
+}
+
+// peek returns the n-th element from the top of the given stack, or zero if
+// the stack is not deep enough.
+func peek(stack []tosca.Word, n int) tosca.Word {
+	if n >= len(stack) {
+		return tosca.Word{}
+	}
+	return stack[len(stack)-1-n]
+}
+
+func toAddress(word tosca.Word) tosca.Address {
+	return tosca.Address(word[12:])
+}
+
+func toUint64(word tosca.Word) uint64 {
+	var value uint256.Int
+	value.SetBytes32(word[:])
+	if !value.IsUint64() {
+		return 1<<64 - 1
+	}
+	return value.Uint64()
+}
@@ -0,0 +1,253 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package aa
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+var (
+	entryPoint = tosca.Address{0xe0}
+	sender     = tosca.Address{0x01}
+	factory    = tosca.Address{0x02}
+	paymaster  = tosca.Address{0x03}
+	other      = tosca.Address{0x04}
+)
+
+// newCheckerInFrame creates a checker whose validation is currently running
+// the code of the given entity, called by the entry point.
+func newCheckerInFrame(op UserOperation, state tosca.WorldState, entity tosca.Address) *ruleChecker {
+	checker := newRuleChecker(op, entryPoint, state)
+	checker.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: entryPoint})
+	checker.OnEnter(1, tosca.Call, tosca.CallParameters{Sender: entryPoint, Recipient: entity})
+	return checker
+}
+
+func word(address tosca.Address) tosca.Word {
+	var res tosca.Word
+	copy(res[12:], address[:])
+	return res
+}
+
+func TestRuleChecker_BannedOpCodesAreReported(t *testing.T) {
+	for op := range bannedOpCodes {
+		checker := newCheckerInFrame(UserOperation{Sender: sender}, nil, sender)
+		checker.OnOpcode(tosca.OpCodeState{OpCode: op, Address: sender, Pc: 7})
+		if want, got := 1, len(checker.violations); want != got {
+			t.Fatalf("unexpected number of violations for %v, wanted %d, got %d", op, want, got)
+		}
+		violation := checker.violations[0]
+		if violation.Rule != "OP-011" || violation.Entity != Sender || violation.Pc != 7 || violation.OpCode != op {
+			t.Errorf("unexpected violation for %v: %v", op, violation)
+		}
+	}
+}
+
+func TestRuleChecker_CodeOfEntryPointIsNotRestricted(t *testing.T) {
+	checker := newRuleChecker(UserOperation{Sender: sender}, entryPoint, nil)
+	checker.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: entryPoint})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.TIMESTAMP, Address: entryPoint})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SLOAD, Address: entryPoint, Stack: []tosca.Word{{1}}})
+	if len(checker.violations) != 0 {
+		t.Errorf("unexpected violations: %v", checker.violations)
+	}
+}
+
+func TestRuleChecker_CalledContractsInheritTheEntity(t *testing.T) {
+	op := UserOperation{Sender: sender, Paymaster: &paymaster}
+	checker := newCheckerInFrame(op, nil, paymaster)
+	checker.OnEnter(2, tosca.StaticCall, tosca.CallParameters{Sender: paymaster, Recipient: other})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.NUMBER, Address: other})
+	checker.OnExit(2, tosca.CallResult{}, nil)
+	checker.OnExit(1, tosca.CallResult{}, nil)
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.NUMBER, Address: entryPoint})
+
+	if want, got := 1, len(checker.violations); want != got {
+		t.Fatalf("unexpected number of violations, wanted %d, got %d", want, got)
+	}
+	if want, got := Paymaster, checker.violations[0].Entity; want != got {
+		t.Errorf("unexpected entity, wanted %v, got %v", want, got)
+	}
+	if want, got := other, checker.violations[0].Address; want != got {
+		t.Errorf("unexpected address, wanted %v, got %v", want, got)
+	}
+}
+
+func TestRuleChecker_GasIsOnlyAllowedBeforeCalls(t *testing.T) {
+	tests := map[vm.OpCode]bool{
+		vm.CALL:         true,
+		vm.STATICCALL:   true,
+		vm.DELEGATECALL: true,
+		vm.CALLCODE:     true,
+		vm.POP:          false,
+		vm.STOP:         false,
+	}
+	for next, allowed := range tests {
+		checker := newCheckerInFrame(UserOperation{Sender: sender}, nil, sender)
+		checker.OnOpcode(tosca.OpCodeState{OpCode: vm.GAS, Address: sender})
+		checker.OnOpcode(tosca.OpCodeState{OpCode: next, Address: sender, Stack: []tosca.Word{{}, word(sender), {}}})
+		if want, got := allowed, len(checker.violations) == 0; want != got {
+			t.Errorf("unexpected result for GAS followed by %v, wanted allowed %t, got %v", next, want, checker.violations)
+		}
+	}
+}
+
+func TestRuleChecker_BalanceIsOnlyAllowedForStakedEntities(t *testing.T) {
+	for _, staked := range []bool{false, true} {
+		op := UserOperation{Sender: sender}
+		if staked {
+			op.Staked = []Entity{Sender}
+		}
+		checker := newCheckerInFrame(op, nil, sender)
+		checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SELFBALANCE, Address: sender})
+		if want, got := staked, len(checker.violations) == 0; want != got {
+			t.Errorf("unexpected result for staked %t: %v", staked, checker.violations)
+		}
+	}
+}
+
+func TestRuleChecker_Create2IsOnlyAllowedOnceForFactory(t *testing.T) {
+	op := UserOperation{Sender: sender, Factory: &factory}
+	checker := newCheckerInFrame(op, nil, factory)
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.CREATE2, Address: factory})
+	if len(checker.violations) != 0 {
+		t.Fatalf("unexpected violations: %v", checker.violations)
+	}
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.CREATE2, Address: factory})
+	if len(checker.violations) != 1 || checker.violations[0].Rule != "OP-031" {
+		t.Errorf("unexpected violations: %v", checker.violations)
+	}
+
+	checker = newCheckerInFrame(op, nil, sender)
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.CREATE2, Address: sender})
+	if len(checker.violations) != 1 || checker.violations[0].Rule != "OP-031" {
+		t.Errorf("unexpected violations: %v", checker.violations)
+	}
+}
+
+func TestRuleChecker_StorageAccessIsLimitedToSender(t *testing.T) {
+	tests := map[string]struct {
+		staked  bool
+		address tosca.Address
+		allowed bool
+	}{
+		"sender storage":             {address: sender, allowed: true},
+		"other storage":              {address: other, allowed: false},
+		"own storage of unstaked":    {address: paymaster, allowed: false},
+		"own storage of staked":      {address: paymaster, staked: true, allowed: true},
+		"other storage while staked": {address: other, staked: true, allowed: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, op := range []vm.OpCode{vm.SLOAD, vm.SSTORE, vm.TLOAD, vm.TSTORE} {
+				userOp := UserOperation{Sender: sender, Paymaster: &paymaster}
+				if test.staked {
+					userOp.Staked = []Entity{Paymaster}
+				}
+				checker := newCheckerInFrame(userOp, nil, paymaster)
+				checker.OnOpcode(tosca.OpCodeState{OpCode: op, Address: test.address, Stack: []tosca.Word{{1}}})
+				if want, got := test.allowed, len(checker.violations) == 0; want != got {
+					t.Errorf("unexpected result for %v, wanted allowed %t, got %v", op, want, checker.violations)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleChecker_AccessToAssociatedStorageIsAllowed(t *testing.T) {
+	// A key of a mapping, as hashed by Solidity: the padded address of the
+	// sender followed by the slot of the mapping.
+	key := make([]byte, 64)
+	copy(key[12:], sender[:])
+	key[63] = 5
+	base := tosca.Word(crypto.Keccak256(key))
+
+	tests := map[string]struct {
+		offset  byte
+		allowed bool
+	}{
+		"base slot":       {offset: 0, allowed: true},
+		"struct member":   {offset: 100, allowed: true},
+		"maximum offset":  {offset: maxAssociatedOffset, allowed: true},
+		"exceeded offset": {offset: maxAssociatedOffset + 1, allowed: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := newCheckerInFrame(UserOperation{Sender: sender}, nil, sender)
+			memory := append(make([]byte, 32), key...)
+			checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SHA3, Address: sender, Stack: []tosca.Word{{31: 64}, {31: 32}}, Memory: memory})
+
+			slot := base
+			if slot[31]+test.offset < slot[31] {
+				slot[30]++
+			}
+			slot[31] += test.offset
+			checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SLOAD, Address: other, Stack: []tosca.Word{slot}})
+			if want, got := test.allowed, len(checker.violations) == 0; want != got {
+				t.Errorf("unexpected result, wanted allowed %t, got %v", want, checker.violations)
+			}
+		})
+	}
+}
+
+func TestRuleChecker_HashesOfOtherKeysDoNotAssociateStorage(t *testing.T) {
+	key := make([]byte, 64)
+	copy(key[12:], other[:])
+	checker := newCheckerInFrame(UserOperation{Sender: sender}, nil, sender)
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SHA3, Address: sender, Stack: []tosca.Word{{31: 64}, {}}, Memory: key})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.SLOAD, Address: other, Stack: []tosca.Word{tosca.Word(crypto.Keccak256Hash(key))}})
+	if len(checker.violations) != 1 {
+		t.Errorf("unexpected violations: %v", checker.violations)
+	}
+}
+
+func TestRuleChecker_CodeAccessOfAccountsWithoutCodeIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockWorldState(ctrl)
+	state.EXPECT().GetCodeSize(other).Return(0)
+	state.EXPECT().GetCodeSize(paymaster).Return(10)
+
+	checker := newCheckerInFrame(UserOperation{Sender: sender}, state, sender)
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.EXTCODESIZE, Address: sender, Stack: []tosca.Word{word(other)}})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.EXTCODEHASH, Address: sender, Stack: []tosca.Word{word(paymaster)}})
+	checker.OnOpcode(tosca.OpCodeState{OpCode: vm.EXTCODECOPY, Address: sender, Stack: []tosca.Word{word(sender)}})
+	if len(checker.violations) != 1 || checker.violations[0].Rule != "OP-041" {
+		t.Errorf("unexpected violations: %v", checker.violations)
+	}
+}
+
+func TestRuleChecker_CallsWithValueAreOnlyAllowedToEntryPoint(t *testing.T) {
+	tests := map[string]struct {
+		target  tosca.Address
+		value   tosca.Word
+		allowed bool
+	}{
+		"no value":          {target: other, allowed: true},
+		"value to other":    {target: other, value: tosca.Word{31: 1}, allowed: false},
+		"value to entry":    {target: entryPoint, value: tosca.Word{31: 1}, allowed: true},
+		"no value to entry": {target: entryPoint, allowed: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			checker := newCheckerInFrame(UserOperation{Sender: sender}, nil, sender)
+			stack := []tosca.Word{test.value, word(test.target), {31: 100}}
+			checker.OnOpcode(tosca.OpCodeState{OpCode: vm.CALL, Address: sender, Stack: stack})
+			if want, got := test.allowed, len(checker.violations) == 0; want != got {
+				t.Errorf("unexpected result, wanted allowed %t, got %v", want, checker.violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package bundle provides the atomic simulation of bundles of transactions,
+// as needed by searchers and other simulation infrastructure evaluating
+// sequences of transactions ahead of their inclusion in a block.
+//
+// Transactions of a bundle are executed in order, each on the state produced
+// by its predecessors. All modifications are buffered in memory on top of
+// the state provided by the caller, which is never modified. Thus, once the
+// simulation is complete, everything is rolled back and only the receipts
+// and the aggregate state diff of the bundle remain.
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// Result summarizes the simulated execution of a bundle of transactions.
+type Result struct {
+	// Receipts lists the receipts of the transactions in the order of the
+	// bundle. Transactions rejected by the processor, e.g. due to a nonce
+	// mismatch, have an unsuccessful receipt without gas usage.
+	Receipts []tosca.Receipt
+	// StateDiff lists the accounts modified by the bundle as a whole.
+	StateDiff StateDiff
+}
+
+// StateDiff describes the modification of the world state by a bundle. It
+// is indexed by the addresses of the modified accounts. Accounts which are
+// only read, or which got modified and restored to their original state
+// within the bundle, are not listed.
+type StateDiff map[tosca.Address]AccountDiff
+
+// AccountDiff describes the modification of a single account by listing its
+// state before and after the execution of a bundle.
+type AccountDiff struct {
+	Pre  Account
+	Post Account
+	// StorageCleared is true if the account got destructed by the bundle,
+	// resetting all storage slots not listed in Post to zero. Since storage
+	// can not be enumerated, slots cleared this way are not listed in Pre.
+	StorageCleared bool
+}
+
+// Account is the state of an account. Storage is restricted to the slots
+// whose values got changed by a bundle and is nil if there are none.
+type Account struct {
+	Exists  bool
+	Balance tosca.Value
+	Nonce   uint64
+	Code    tosca.Code
+	Storage map[tosca.Key]tosca.Word
+}
+
+// Run executes the given transactions in order using the given processor on
+// top of the world state of the given context. Only the world state and the
+// block hashes of the given context are read, such that any TransactionContext
+// may be used without getting modified. Transaction-local state like
+// transient storage, access lists, and logs is reset between transactions of
+// the bundle.
+//
+// The given context.Context may be used to abort the simulation. If the
+// execution of any transaction fails with an error, the simulation of the
+// bundle is stopped and the error is returned.
+func Run(
+	ctx context.Context,
+	processor tosca.Processor,
+	blockParameters tosca.BlockParameters,
+	transactions []tosca.Transaction,
+	base tosca.ReadOnlyContext,
+) (Result, error) {
+	state := newBundleContext(base, blockParameters.Revision)
+	receipts := make([]tosca.Receipt, 0, len(transactions))
+	for i, transaction := range transactions {
+		receipt, err := processor.Run(ctx, blockParameters, transaction, state)
+		if err != nil {
+			return Result{}, fmt.Errorf("transaction %d: %w", i, err)
+		}
+		state.endTransaction()
+		receipts = append(receipts, receipt)
+	}
+	return Result{
+		Receipts:  receipts,
+		StateDiff: state.diff(),
+	}, nil
+}
+
+// diff computes the state diff of all transactions executed on this context
+// by comparing the buffered accounts to the underlying state.
+func (c *bundleContext) diff() StateDiff {
+	diff := StateDiff{}
+	for address, post := range c.accounts {
+		pre := Account{
+			Exists:  c.base.AccountExists(address),
+			Balance: c.base.GetBalance(address),
+			Nonce:   c.base.GetNonce(address),
+			Code:    c.base.GetCode(address),
+		}
+		res := AccountDiff{
+			Pre: pre,
+			Post: Account{
+				Exists:  post.exists,
+				Balance: post.balance,
+				Nonce:   post.nonce,
+				Code:    post.code,
+			},
+			StorageCleared: post.cleared && pre.Exists,
+		}
+		for key, value := range post.storage {
+			original := c.base.GetStorage(address, key)
+			if original == value {
+				continue
+			}
+			if res.Pre.Storage == nil {
+				res.Pre.Storage = map[tosca.Key]tosca.Word{}
+				res.Post.Storage = map[tosca.Key]tosca.Word{}
+			}
+			res.Pre.Storage[key] = original
+			res.Post.Storage[key] = value
+		}
+		if res.StorageCleared || res.Pre.Storage != nil || !accountsEqual(res.Pre, res.Post) {
+			diff[address] = res
+		}
+	}
+	return diff
+}
+
+func accountsEqual(a, b Account) bool {
+	return a.Exists == b.Exists &&
+		a.Balance == b.Balance &&
+		a.Nonce == b.Nonce &&
+		bytes.Equal(a.Code, b.Code) &&
+		maps.Equal(a.Storage, b.Storage)
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+// baseState is a read-only world state used as the base of bundles in tests.
+// Any attempt to modify it panics, since the embedded context is nil.
+type baseState struct {
+	tosca.TransactionContext
+	balances map[tosca.Address]tosca.Value
+	storage  map[tosca.Address]map[tosca.Key]tosca.Word
+}
+
+func (s *baseState) AccountExists(address tosca.Address) bool {
+	_, found := s.balances[address]
+	return found
+}
+
+func (s *baseState) GetBalance(address tosca.Address) tosca.Value {
+	return s.balances[address]
+}
+
+func (s *baseState) GetNonce(tosca.Address) uint64 {
+	return 0
+}
+
+func (s *baseState) GetCode(tosca.Address) tosca.Code {
+	return nil
+}
+
+func (s *baseState) GetCodeHash(address tosca.Address) tosca.Hash {
+	if !s.AccountExists(address) {
+		return tosca.Hash{}
+	}
+	return emptyCodeHash
+}
+
+func (s *baseState) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return s.storage[address][key]
+}
+
+func TestRun_ExecutesTransactionsInOrderOnSharedState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockProcessor(ctrl)
+
+	address := tosca.Address{1}
+	base := &baseState{
+		balances: map[tosca.Address]tosca.Value{address: tosca.NewValue(10)},
+		storage:  map[tosca.Address]map[tosca.Key]tosca.Word{address: {{1}: {1}}},
+	}
+
+	gomock.InOrder(
+		processor.EXPECT().Run(gomock.Any(), gomock.Any(), tosca.Transaction{Nonce: 0}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, state tosca.TransactionContext) (tosca.Receipt, error) {
+				state.SetBalance(address, tosca.NewValue(5))
+				state.SetStorage(address, tosca.Key{1}, tosca.Word{2})
+				return tosca.Receipt{Success: true, GasUsed: 1}, nil
+			}),
+		processor.EXPECT().Run(gomock.Any(), gomock.Any(), tosca.Transaction{Nonce: 1}, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, state tosca.TransactionContext) (tosca.Receipt, error) {
+				if want, got := tosca.NewValue(5), state.GetBalance(address); want != got {
+					t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+				}
+				if want, got := (tosca.Word{2}), state.GetCommittedStorage(address, tosca.Key{1}); want != got {
+					t.Errorf("unexpected committed storage, wanted %v, got %v", want, got)
+				}
+				state.SetStorage(address, tosca.Key{2}, tosca.Word{3})
+				return tosca.Receipt{Success: true, GasUsed: 2}, nil
+			}),
+	)
+
+	transactions := []tosca.Transaction{{Nonce: 0}, {Nonce: 1}}
+	result, err := Run(context.Background(), processor, tosca.BlockParameters{}, transactions, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantReceipts := []tosca.Receipt{{Success: true, GasUsed: 1}, {Success: true, GasUsed: 2}}
+	if !reflect.DeepEqual(wantReceipts, result.Receipts) {
+		t.Errorf("unexpected receipts, wanted %v, got %v", wantReceipts, result.Receipts)
+	}
+	wantDiff := StateDiff{
+		address: {
+			Pre: Account{
+				Exists:  true,
+				Balance: tosca.NewValue(10),
+				Storage: map[tosca.Key]tosca.Word{{1}: {1}, {2}: {}},
+			},
+			Post: Account{
+				Exists:  true,
+				Balance: tosca.NewValue(5),
+				Storage: map[tosca.Key]tosca.Word{{1}: {2}, {2}: {3}},
+			},
+		},
+	}
+	if !reflect.DeepEqual(wantDiff, result.StateDiff) {
+		t.Errorf("unexpected state diff, wanted %v, got %v", wantDiff, result.StateDiff)
+	}
+}
+
+func TestRun_ReportsErrorsOfTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockProcessor(ctrl)
+	injected := errors.New("injected error")
+	processor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tosca.Receipt{}, nil)
+	processor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tosca.Receipt{}, injected)
+
+	transactions := []tosca.Transaction{{}, {}, {}}
+	_, err := Run(context.Background(), processor, tosca.BlockParameters{}, transactions, &baseState{})
+	if !errors.Is(err, injected) {
+		t.Errorf("unexpected error, wanted %v, got %v", injected, err)
+	}
+}
+
+func TestRun_ModificationsRestoredWithinTheBundleAreNotListed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockProcessor(ctrl)
+
+	address := tosca.Address{1}
+	base := &baseState{balances: map[tosca.Address]tosca.Value{address: tosca.NewValue(10)}}
+	processor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, state tosca.TransactionContext) (tosca.Receipt, error) {
+			state.SetBalance(address, tosca.NewValue(5))
+			state.SetStorage(address, tosca.Key{1}, tosca.Word{1})
+			return tosca.Receipt{}, nil
+		})
+	processor.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, state tosca.TransactionContext) (tosca.Receipt, error) {
+			state.SetBalance(address, tosca.NewValue(10))
+			state.SetStorage(address, tosca.Key{1}, tosca.Word{})
+			state.GetBalance(tosca.Address{2})
+			return tosca.Receipt{}, nil
+		})
+
+	transactions := []tosca.Transaction{{}, {}}
+	result, err := Run(context.Background(), processor, tosca.BlockParameters{}, transactions, base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.StateDiff) != 0 {
+		t.Errorf("unexpected state diff: %v", result.StateDiff)
+	}
+}
+
+func TestBundleContext_TransactionLocalStateIsReset(t *testing.T) {
+	address := tosca.Address{1}
+	context := newBundleContext(&baseState{}, tosca.R13_Cancun)
+	context.SetTransientStorage(address, tosca.Key{1}, tosca.Word{1})
+	context.AccessStorage(address, tosca.Key{1})
+	context.EmitLog(tosca.Log{Address: address})
+	context.endTransaction()
+
+	if want, got := (tosca.Word{}), context.GetTransientStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected transient storage, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.ColdAccess, context.AccessStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	if logs := context.GetLogs(); len(logs) != 0 {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+}
+
+func TestBundleContext_RestoreSnapshotRevertsModifications(t *testing.T) {
+	address := tosca.Address{1}
+	context := newBundleContext(&baseState{}, tosca.R13_Cancun)
+	context.SetBalance(address, tosca.NewValue(1))
+	snapshot := context.CreateSnapshot()
+	context.SetBalance(address, tosca.NewValue(2))
+	context.SetCode(address, tosca.Code{1, 2, 3})
+	context.SetStorage(address, tosca.Key{1}, tosca.Word{1})
+	context.AccessStorage(tosca.Address{2}, tosca.Key{1})
+	context.RestoreSnapshot(snapshot)
+
+	if addressPresent, slotPresent := context.IsSlotInAccessList(tosca.Address{2}, tosca.Key{1}); addressPresent || slotPresent {
+		t.Errorf("accesses were not reverted")
+	}
+
+	if want, got := tosca.NewValue(1), context.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := emptyCodeHash, context.GetCodeHash(address); want != got {
+		t.Errorf("unexpected code hash, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{}), context.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage, wanted %v, got %v", want, got)
+	}
+}
+
+func TestBundleContext_DestructedAccountsAreRemovedAtEndOfTransaction(t *testing.T) {
+	address := tosca.Address{1}
+	tests := map[string]struct {
+		revision tosca.Revision
+		exists   bool
+		removed  bool
+	}{
+		"pre-Cancun existing account": {revision: tosca.R12_Shanghai, exists: true, removed: true},
+		"Cancun existing account":     {revision: tosca.R13_Cancun, exists: true, removed: false},
+		"Cancun created account":      {revision: tosca.R13_Cancun, exists: false, removed: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			base := &baseState{
+				balances: map[tosca.Address]tosca.Value{},
+				storage:  map[tosca.Address]map[tosca.Key]tosca.Word{address: {{1}: {1}}},
+			}
+			if test.exists {
+				base.balances[address] = tosca.NewValue(1)
+			}
+			context := newBundleContext(base, test.revision)
+			context.SetNonce(address, 1)
+			context.SelfDestruct(address, tosca.Address{2})
+			context.endTransaction()
+
+			if want, got := !test.removed, context.AccountExists(address); want != got {
+				t.Errorf("unexpected existence, wanted %t, got %t", want, got)
+			}
+			want := tosca.Word{1}
+			if test.removed {
+				want = tosca.Word{}
+			}
+			if got := context.GetStorage(address, tosca.Key{1}); want != got {
+				t.Errorf("unexpected storage, wanted %v, got %v", want, got)
+			}
+			if want, got := test.removed && test.exists, context.diff()[address].StorageCleared; want != got {
+				t.Errorf("unexpected storage cleared flag, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestBundleContext_RemoveAccountDropsAccountAndCanBeReverted(t *testing.T) {
+	address := tosca.Address{1}
+	base := &baseState{
+		balances: map[tosca.Address]tosca.Value{address: {}},
+		storage:  map[tosca.Address]map[tosca.Key]tosca.Word{address: {{1}: {1}}},
+	}
+	context := newBundleContext(base, tosca.R13_Cancun)
+	snapshot := context.CreateSnapshot()
+	context.RemoveAccount(address)
+	if context.AccountExists(address) {
+		t.Errorf("account was not removed")
+	}
+	if want, got := (tosca.Word{}), context.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage of removed account, wanted %v, got %v", want, got)
+	}
+
+	context.RestoreSnapshot(snapshot)
+	if !context.AccountExists(address) {
+		t.Errorf("removal of account was not reverted")
+	}
+	if want, got := (tosca.Word{1}), context.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage, wanted %v, got %v", want, got)
+	}
+}
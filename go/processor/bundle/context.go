@@ -0,0 +1,312 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bundle
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// account is the state of an account as seen by the transactions of a
+// bundle. It is loaded from the underlying state when first accessed.
+type account struct {
+	exists   bool
+	balance  tosca.Value
+	nonce    uint64
+	code     tosca.Code
+	codeHash tosca.Hash
+	storage  map[tosca.Key]tosca.Word // slots written by the bundle
+	cleared  bool                     // true if slots not in storage are zero
+}
+
+var emptyCodeHash = tosca.Hash(crypto.Keccak256(nil))
+
+type slot struct {
+	address tosca.Address
+	key     tosca.Key
+}
+
+// bundleContext is a tosca.TransactionContext buffering all modifications
+// of a sequence of transactions on top of a read-only underlying context.
+// Like the state of a block, it needs to be informed about the end of each
+// transaction to commit its modifications and reset transaction-local state.
+type bundleContext struct {
+	base     tosca.ReadOnlyContext
+	revision tosca.Revision
+	accounts map[tosca.Address]*account
+
+	// -- transaction-local state, reset by endTransaction --
+	committed  map[slot]tosca.Word // values of slots modified by the current transaction
+	transient  map[slot]tosca.Word
+	accessed   map[tosca.Address]map[tosca.Key]bool
+	created    map[tosca.Address]bool
+	destructed map[tosca.Address]bool
+	logs       []tosca.Log
+	undo       []func()
+}
+
+func newBundleContext(base tosca.ReadOnlyContext, revision tosca.Revision) *bundleContext {
+	context := &bundleContext{
+		base:     base,
+		revision: revision,
+		accounts: map[tosca.Address]*account{},
+	}
+	context.endTransaction()
+	return context
+}
+
+// endTransaction commits the modifications of the current transaction and
+// resets the transaction-local state. Destructed accounts are removed,
+// starting with Cancun only if they got created by the same transaction
+// (EIP-6780).
+func (c *bundleContext) endTransaction() {
+	for address := range c.destructed {
+		if c.revision >= tosca.R13_Cancun && !c.created[address] {
+			continue
+		}
+		*c.get(address) = account{
+			storage: map[tosca.Key]tosca.Word{},
+			cleared: true,
+		}
+	}
+	c.committed = map[slot]tosca.Word{}
+	c.transient = map[slot]tosca.Word{}
+	c.accessed = map[tosca.Address]map[tosca.Key]bool{}
+	c.created = map[tosca.Address]bool{}
+	c.destructed = map[tosca.Address]bool{}
+	c.logs = nil
+	c.undo = nil
+}
+
+// get returns the buffered state of the given account, loading it from the
+// underlying state if it is accessed for the first time.
+func (c *bundleContext) get(address tosca.Address) *account {
+	acc, found := c.accounts[address]
+	if !found {
+		acc = &account{
+			exists:   c.base.AccountExists(address),
+			balance:  c.base.GetBalance(address),
+			nonce:    c.base.GetNonce(address),
+			code:     c.base.GetCode(address),
+			codeHash: c.base.GetCodeHash(address),
+			storage:  map[tosca.Key]tosca.Word{},
+		}
+		c.accounts[address] = acc
+	}
+	return acc
+}
+
+// getOrCreate returns the buffered state of the given account, creating the
+// account if it does not exist.
+func (c *bundleContext) getOrCreate(address tosca.Address) *account {
+	acc := c.get(address)
+	if !acc.exists {
+		acc.exists = true
+		c.created[address] = true
+		c.undo = append(c.undo, func() {
+			acc.exists = false
+			delete(c.created, address)
+		})
+	}
+	return acc
+}
+
+func (c *bundleContext) AccountExists(address tosca.Address) bool {
+	return c.get(address).exists
+}
+
+// RemoveAccount removes the given account, including its storage, from the
+// state. It implements tosca.AccountRemover.
+func (c *bundleContext) RemoveAccount(address tosca.Address) {
+	acc := c.get(address)
+	previous := *acc
+	*acc = account{
+		storage: map[tosca.Key]tosca.Word{},
+		cleared: true,
+	}
+	c.undo = append(c.undo, func() { *acc = previous })
+}
+
+func (c *bundleContext) GetBalance(address tosca.Address) tosca.Value {
+	return c.get(address).balance
+}
+
+func (c *bundleContext) SetBalance(address tosca.Address, value tosca.Value) {
+	acc := c.getOrCreate(address)
+	previous := acc.balance
+	acc.balance = value
+	c.undo = append(c.undo, func() { acc.balance = previous })
+}
+
+func (c *bundleContext) GetNonce(address tosca.Address) uint64 {
+	return c.get(address).nonce
+}
+
+func (c *bundleContext) SetNonce(address tosca.Address, nonce uint64) {
+	acc := c.getOrCreate(address)
+	previous := acc.nonce
+	acc.nonce = nonce
+	c.undo = append(c.undo, func() { acc.nonce = previous })
+}
+
+func (c *bundleContext) GetCode(address tosca.Address) tosca.Code {
+	return c.get(address).code
+}
+
+func (c *bundleContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	acc := c.get(address)
+	if !acc.exists {
+		return tosca.Hash{}
+	}
+	if acc.codeHash == (tosca.Hash{}) {
+		return emptyCodeHash
+	}
+	return acc.codeHash
+}
+
+func (c *bundleContext) GetCodeSize(address tosca.Address) int {
+	return len(c.get(address).code)
+}
+
+func (c *bundleContext) SetCode(address tosca.Address, code tosca.Code) {
+	acc := c.getOrCreate(address)
+	previousCode, previousHash := acc.code, acc.codeHash
+	acc.code = bytes.Clone(code)
+	acc.codeHash = tosca.Hash(crypto.Keccak256Hash(code))
+	c.undo = append(c.undo, func() {
+		acc.code, acc.codeHash = previousCode, previousHash
+	})
+}
+
+func (c *bundleContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	acc := c.get(address)
+	if value, found := acc.storage[key]; found || acc.cleared {
+		return value
+	}
+	return c.base.GetStorage(address, key)
+}
+
+func (c *bundleContext) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	if value, found := c.committed[slot{address, key}]; found {
+		return value
+	}
+	return c.GetStorage(address, key)
+}
+
+func (c *bundleContext) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	original := c.GetCommittedStorage(address, key)
+	if _, found := c.committed[slot{address, key}]; !found {
+		c.committed[slot{address, key}] = original
+	}
+	current := c.GetStorage(address, key)
+	acc := c.getOrCreate(address)
+	previous, present := acc.storage[key]
+	acc.storage[key] = value
+	c.undo = append(c.undo, func() {
+		if present {
+			acc.storage[key] = previous
+		} else {
+			delete(acc.storage, key)
+		}
+	})
+	return tosca.GetStorageStatus(original, current, value)
+}
+
+func (c *bundleContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	balance := c.GetBalance(address)
+	c.SetBalance(address, tosca.Value{})
+	c.SetBalance(beneficiary, tosca.Add(c.GetBalance(beneficiary), balance))
+	if c.destructed[address] {
+		return false
+	}
+	c.destructed[address] = true
+	c.undo = append(c.undo, func() { delete(c.destructed, address) })
+	return true
+}
+
+func (c *bundleContext) HasSelfDestructed(address tosca.Address) bool {
+	return c.destructed[address]
+}
+
+func (c *bundleContext) CreateSnapshot() tosca.Snapshot {
+	return tosca.Snapshot(len(c.undo))
+}
+
+func (c *bundleContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	for len(c.undo) > int(snapshot) {
+		c.undo[len(c.undo)-1]()
+		c.undo = c.undo[:len(c.undo)-1]
+	}
+}
+
+func (c *bundleContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return c.transient[slot{address, key}]
+}
+
+func (c *bundleContext) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	previous, found := c.transient[slot{address, key}]
+	c.transient[slot{address, key}] = value
+	c.undo = append(c.undo, func() {
+		if found {
+			c.transient[slot{address, key}] = previous
+		} else {
+			delete(c.transient, slot{address, key})
+		}
+	})
+}
+
+func (c *bundleContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	if c.IsAddressInAccessList(address) {
+		return tosca.WarmAccess
+	}
+	c.accessed[address] = map[tosca.Key]bool{}
+	c.undo = append(c.undo, func() { delete(c.accessed, address) })
+	return tosca.ColdAccess
+}
+
+func (c *bundleContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	if _, present := c.IsSlotInAccessList(address, key); present {
+		return tosca.WarmAccess
+	}
+	if !c.IsAddressInAccessList(address) {
+		c.AccessAccount(address)
+	}
+	c.accessed[address][key] = true
+	c.undo = append(c.undo, func() { delete(c.accessed[address], key) })
+	return tosca.ColdAccess
+}
+
+func (c *bundleContext) IsAddressInAccessList(address tosca.Address) bool {
+	_, found := c.accessed[address]
+	return found
+}
+
+func (c *bundleContext) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	keys, found := c.accessed[address]
+	return found, keys[key]
+}
+
+func (c *bundleContext) EmitLog(log tosca.Log) {
+	length := len(c.logs)
+	c.logs = append(c.logs, log)
+	c.undo = append(c.undo, func() { c.logs = c.logs[:length] })
+}
+
+func (c *bundleContext) GetLogs() []tosca.Log {
+	return slices.Clone(c.logs)
+}
+
+func (c *bundleContext) GetBlockHash(number int64) tosca.Hash {
+	return c.base.GetBlockHash(number)
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bundle
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// Trace executes the given transaction using the given processor on top of
+// the given read-only state, informing the given tracer about the progress
+// of the execution. Like for bundles, all modifications are buffered in
+// memory and discarded once the execution is complete.
+//
+// Trace may be called concurrently for many transactions sharing the same
+// state, as long as the state is safe for concurrent use. Thus, to trace the
+// transactions of a historical block in parallel, each transaction may be
+// traced on the archive state preceding it, e.g. obtained through
+// tosca.NewArchiveContext, without the transactions interfering.
+//
+// The given context.Context may be used to abort the execution, in which case
+// the context's error is returned.
+func Trace(
+	ctx context.Context,
+	processor tosca.SimulatingProcessor,
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	state tosca.ReadOnlyContext,
+	tracer tosca.Tracer,
+) (tosca.Receipt, error) {
+	overlay := newBundleContext(state, blockParameters.Revision)
+	options := tosca.SimulationOptions{Tracer: tracer}
+	result, err := processor.Simulate(ctx, blockParameters, transaction, overlay, options)
+	if err != nil {
+		return tosca.Receipt{}, err
+	}
+	if result.Aborted {
+		return tosca.Receipt{}, fmt.Errorf("trace aborted: %w", ctx.Err())
+	}
+	return result.Receipt, nil
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTrace_ExecutesTransactionWithTracerOnTopOfState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockSimulatingProcessor(ctrl)
+	tracer := tosca.NewMockTracer(ctrl)
+
+	address := tosca.Address{1}
+	base := &baseState{
+		balances: map[tosca.Address]tosca.Value{address: tosca.NewValue(10)},
+	}
+
+	processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), tosca.SimulationOptions{Tracer: tracer}).
+		DoAndReturn(func(_ context.Context, _ tosca.BlockParameters, _ tosca.Transaction, state tosca.TransactionContext, _ tosca.SimulationOptions) (tosca.SimulationResult, error) {
+			if want, got := tosca.NewValue(10), state.GetBalance(address); want != got {
+				t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+			}
+			state.SetBalance(address, tosca.NewValue(5))
+			return tosca.SimulationResult{Receipt: tosca.Receipt{Success: true, GasUsed: 1}}, nil
+		})
+
+	receipt, err := Trace(context.Background(), processor, tosca.BlockParameters{}, tosca.Transaction{}, base, tracer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !receipt.Success || receipt.GasUsed != 1 {
+		t.Errorf("unexpected receipt: %v", receipt)
+	}
+	if want, got := tosca.NewValue(10), base.GetBalance(address); want != got {
+		t.Errorf("state got modified, wanted balance %v, got %v", want, got)
+	}
+}
+
+func TestTrace_ReportsErrorsAndAbortedExecutions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	processor := tosca.NewMockSimulatingProcessor(ctrl)
+
+	injected := errors.New("injected")
+	processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tosca.SimulationResult{}, injected)
+	_, err := Trace(context.Background(), processor, tosca.BlockParameters{}, tosca.Transaction{}, &baseState{}, nil)
+	if !errors.Is(err, injected) {
+		t.Errorf("unexpected error, wanted %v, got %v", injected, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	processor.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tosca.SimulationResult{Aborted: true}, nil)
+	_, err = Trace(ctx, processor, tosca.BlockParameters{}, tosca.Transaction{}, &baseState{}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error, wanted %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package ecrecover provides a cache for the recovery of the signers of
+// secp256k1 signatures. The recovery is among the most expensive operations
+// conducted while processing blocks, and replaying blocks recovers the same
+// signers over and over again, both for the senders of transactions and in
+// calls to the ECRECOVER precompiled contract. A process-wide cache shared
+// by both is provided by GetSharedCache.
+package ecrecover
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Signature is a secp256k1 signature of a hash, the input of a recovery.
+type Signature struct {
+	Hash tosca.Hash
+	R    [32]byte
+	S    [32]byte
+	V    byte // the recovery id, 0 or 1
+}
+
+// Cache retains the signers recovered from signatures. Only successful
+// recoveries are retained. Caches are safe for concurrent use.
+type Cache struct {
+	cache    *lru.Cache[Signature, tosca.Address]
+	capacity int
+	hits     atomic.Uint64
+	misses   atomic.Uint64
+}
+
+// CacheStatistics summarizes the usage of a cache. If the cache is shared,
+// the counters cover the recoveries of all its users.
+type CacheStatistics struct {
+	Hits     uint64 // number of recoveries served from the cache
+	Misses   uint64 // number of recoveries not found in the cache
+	Entries  int    // number of signers retained by the cache
+	Capacity int    // maximum number of signers retained by the cache
+}
+
+// HitRate returns the share of recoveries served from the cache, or 0 if no
+// recoveries have been conducted.
+func (s CacheStatistics) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// DefaultCacheCapacity is the number of signers retained by the shared cache.
+const DefaultCacheCapacity = 1 << 16
+
+var sharedCache = mustNewCache(DefaultCacheCapacity)
+
+// GetSharedCache returns the process-wide cache shared by the recovery of
+// transaction senders and the ECRECOVER precompiled contract.
+func GetSharedCache() *Cache {
+	return sharedCache
+}
+
+// NewCache creates an empty cache retaining up to capacity signers.
+func NewCache(capacity int) (*Cache, error) {
+	cache, err := lru.New[Signature, tosca.Address](capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{cache: cache, capacity: capacity}, nil
+}
+
+func mustNewCache(capacity int) *Cache {
+	cache, err := NewCache(capacity)
+	if err != nil {
+		panic(err)
+	}
+	return cache
+}
+
+// Recover returns the address of the signer of the given signature. The
+// signature values are not validated beyond what is required for the
+// recovery; checks like the restriction of S to the lower half of the curve
+// order for transaction signatures are the responsibility of the caller.
+func (c *Cache) Recover(signature Signature) (tosca.Address, error) {
+	if signer, found := c.cache.Get(signature); found {
+		c.hits.Add(1)
+		return signer, nil
+	}
+	c.misses.Add(1)
+	signer, err := Recover(signature)
+	if err != nil {
+		return tosca.Address{}, err
+	}
+	c.cache.Add(signature, signer)
+	return signer, nil
+}
+
+// Statistics returns a summary of the usage of this cache.
+func (c *Cache) Statistics() CacheStatistics {
+	return CacheStatistics{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Entries:  c.cache.Len(),
+		Capacity: c.capacity,
+	}
+}
+
+// Recover returns the address of the signer of the given signature without
+// consulting any cache.
+func Recover(signature Signature) (tosca.Address, error) {
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig[0:32], signature.R[:])
+	copy(sig[32:64], signature.S[:])
+	sig[64] = signature.V
+	publicKey, err := crypto.Ecrecover(signature.Hash[:], sig)
+	if err != nil {
+		return tosca.Address{}, err
+	}
+	if len(publicKey) == 0 || publicKey[0] != 4 {
+		return tosca.Address{}, errInvalidPublicKey
+	}
+	return tosca.Address(crypto.Keccak256(publicKey[1:])[12:]), nil
+}
+
+var errInvalidPublicKey = errors.New("invalid public key")
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package ecrecover
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func sign(t *testing.T, message string) (Signature, tosca.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	hash := crypto.Keccak256([]byte(message))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	signature := Signature{
+		Hash: tosca.Hash(hash),
+		R:    [32]byte(sig[0:32]),
+		S:    [32]byte(sig[32:64]),
+		V:    sig[64],
+	}
+	return signature, tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+}
+
+func TestRecover_RecoversSigner(t *testing.T) {
+	signature, want := sign(t, "message")
+	got, err := Recover(signature)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if want != got {
+		t.Errorf("unexpected signer, wanted %v, got %v", want, got)
+	}
+}
+
+func TestRecover_FailsForInvalidSignature(t *testing.T) {
+	signature, _ := sign(t, "message")
+	signature.V = 2
+	if _, err := Recover(signature); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestCache_RecoveredSignersAreCached(t *testing.T) {
+	cache, err := NewCache(10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	signature, want := sign(t, "message")
+	for range 3 {
+		got, err := cache.Recover(signature)
+		if err != nil {
+			t.Fatalf("failed to recover signer: %v", err)
+		}
+		if want != got {
+			t.Errorf("unexpected signer, wanted %v, got %v", want, got)
+		}
+	}
+	if want, got := (CacheStatistics{Hits: 2, Misses: 1, Entries: 1, Capacity: 10}), cache.Statistics(); want != got {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, got)
+	}
+}
+
+func TestCache_FailedRecoveriesAreNotCached(t *testing.T) {
+	cache, err := NewCache(10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	signature, _ := sign(t, "message")
+	signature.V = 2
+	for range 2 {
+		if _, err := cache.Recover(signature); err == nil {
+			t.Errorf("expected an error")
+		}
+	}
+	if want, got := (CacheStatistics{Misses: 2, Capacity: 10}), cache.Statistics(); want != got {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, got)
+	}
+}
+
+func TestCache_LeastRecentlyUsedSignersAreEvicted(t *testing.T) {
+	cache, err := NewCache(2)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	for _, message := range []string{"a", "b", "c"} {
+		signature, _ := sign(t, message)
+		if _, err := cache.Recover(signature); err != nil {
+			t.Fatalf("failed to recover signer: %v", err)
+		}
+	}
+	if want, got := 2, cache.Statistics().Entries; want != got {
+		t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+	}
+}
+
+func TestCacheStatistics_HitRate(t *testing.T) {
+	tests := map[CacheStatistics]float64{
+		{}:                     0,
+		{Hits: 1}:              1,
+		{Misses: 1}:            0,
+		{Hits: 3, Misses: 1}:   0.75,
+		{Hits: 1, Misses: 499}: 0.002,
+	}
+	for stats, want := range tests {
+		if got := stats.HitRate(); want != got {
+			t.Errorf("unexpected hit rate of %+v, wanted %v, got %v", stats, want, got)
+		}
+	}
+}
+
+func TestNewCache_RejectsInvalidCapacity(t *testing.T) {
+	if _, err := NewCache(0); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestGetSharedCache_ReturnsSameCache(t *testing.T) {
+	if GetSharedCache() != GetSharedCache() {
+		t.Errorf("shared cache is not unique")
+	}
+	if want, got := DefaultCacheCapacity, GetSharedCache().Statistics().Capacity; want != got {
+		t.Errorf("unexpected capacity, wanted %d, got %d", want, got)
+	}
+}
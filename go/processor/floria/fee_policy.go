@@ -0,0 +1,119 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// FeePolicy determines how the fees paid by a transaction are distributed
+// among the validator of the block, contract developers, and the part being
+// burned. A policy is consulted once for every executed transaction after the
+// unused gas got refunded to the sender. If no policy is configured, fees are
+// not credited to any account, leaving their distribution to the client.
+type FeePolicy interface {
+	// GetFeeDistribution computes the distribution of the fees described by
+	// the given summary. The shares of the resulting distribution must add
+	// up to the total fees of the transaction.
+	GetFeeDistribution(summary FeeSummary) FeeDistribution
+}
+
+// FeePolicyFunc is an adapter enabling the use of plain functions as fee
+// policies.
+type FeePolicyFunc func(summary FeeSummary) FeeDistribution
+
+func (f FeePolicyFunc) GetFeeDistribution(summary FeeSummary) FeeDistribution {
+	return f(summary)
+}
+
+// FeeSummary describes the fees paid by a transaction.
+type FeeSummary struct {
+	BlockParameters tosca.BlockParameters
+	Transaction     tosca.Transaction
+	Receipt         tosca.Receipt
+
+	// Fees is the total amount paid for the gas used by the transaction.
+	Fees tosca.Value
+	// BaseFees is the share of Fees covered by the base fee of the block.
+	BaseFees tosca.Value
+	// Tips is the share of Fees exceeding the base fee of the block.
+	Tips tosca.Value
+}
+
+// FeeDistribution lists the recipients of the fees of a transaction.
+type FeeDistribution struct {
+	// Burned is the share not credited to any account.
+	Burned tosca.Value
+	// Validator is the share credited to the coinbase of the block.
+	Validator tosca.Value
+	// Developers lists the shares credited to contract developers.
+	Developers []FeeShare
+}
+
+// FeeShare is a share of the fees of a transaction credited to an account.
+type FeeShare struct {
+	Recipient tosca.Address
+	Amount    tosca.Value
+}
+
+// distributeFees credits the fees of the given transaction according to the
+// configured fee policy, if any.
+func (p *processor) distributeFees(
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	receipt tosca.Receipt,
+	context tosca.TransactionContext,
+) error {
+	policy := p.config.FeePolicy
+	if policy == nil {
+		return nil
+	}
+
+	gasUsed := uint64(receipt.GasUsed)
+	baseFee := blockParameters.BaseFee
+	if transaction.GasPrice.Cmp(baseFee) < 0 {
+		baseFee = transaction.GasPrice
+	}
+	summary := FeeSummary{
+		BlockParameters: blockParameters,
+		Transaction:     transaction,
+		Receipt:         receipt,
+		Fees:            transaction.GasPrice.Scale(gasUsed),
+		BaseFees:        baseFee.Scale(gasUsed),
+		Tips:            tosca.Sub(transaction.GasPrice, baseFee).Scale(gasUsed),
+	}
+	distribution := policy.GetFeeDistribution(summary)
+
+	total, overflow := tosca.AddOverflow(distribution.Burned, distribution.Validator)
+	for _, share := range distribution.Developers {
+		var o bool
+		total, o = tosca.AddOverflow(total, share.Amount)
+		overflow = overflow || o
+	}
+	if overflow || total != summary.Fees {
+		return fmt.Errorf("invalid fee distribution: shares add up to %v, fees are %v", total, summary.Fees)
+	}
+
+	credit(context, blockParameters.Coinbase, distribution.Validator)
+	for _, share := range distribution.Developers {
+		credit(context, share.Recipient, share.Amount)
+	}
+	return nil
+}
+
+func credit(context tosca.TransactionContext, account tosca.Address, amount tosca.Value) {
+	if amount == (tosca.Value{}) {
+		return
+	}
+	context.SetBalance(account, tosca.Add(context.GetBalance(account), amount))
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestFeePolicy_NoPolicyDoesNotCreditFees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+
+	p := &processor{}
+	err := p.distributeFees(tosca.BlockParameters{}, tosca.Transaction{GasPrice: tosca.NewValue(10)}, tosca.Receipt{GasUsed: 100}, context)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFeePolicy_PolicyIsProvidedWithFeeSummary(t *testing.T) {
+	blockParameters := tosca.BlockParameters{BaseFee: tosca.NewValue(7)}
+	transaction := tosca.Transaction{GasPrice: tosca.NewValue(10)}
+	receipt := tosca.Receipt{GasUsed: 100}
+
+	var got FeeSummary
+	p := &processor{config: Config{FeePolicy: FeePolicyFunc(func(summary FeeSummary) FeeDistribution {
+		got = summary
+		return FeeDistribution{Burned: summary.Fees}
+	})}}
+
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	if err := p.distributeFees(blockParameters, transaction, receipt, context); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want, got := tosca.NewValue(1000), got.Fees; want != got {
+		t.Errorf("unexpected fees, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.NewValue(700), got.BaseFees; want != got {
+		t.Errorf("unexpected base fees, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.NewValue(300), got.Tips; want != got {
+		t.Errorf("unexpected tips, wanted %v, got %v", want, got)
+	}
+	if want, got := receipt.GasUsed, got.Receipt.GasUsed; want != got {
+		t.Errorf("unexpected receipt, wanted gas used %d, got %d", want, got)
+	}
+}
+
+func TestFeePolicy_BaseFeesAreLimitedByGasPrice(t *testing.T) {
+	var got FeeSummary
+	p := &processor{config: Config{FeePolicy: FeePolicyFunc(func(summary FeeSummary) FeeDistribution {
+		got = summary
+		return FeeDistribution{Burned: summary.Fees}
+	})}}
+
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	blockParameters := tosca.BlockParameters{BaseFee: tosca.NewValue(20)}
+	transaction := tosca.Transaction{GasPrice: tosca.NewValue(10)}
+	if err := p.distributeFees(blockParameters, transaction, tosca.Receipt{GasUsed: 10}, context); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := tosca.NewValue(100), got.BaseFees; want != got {
+		t.Errorf("unexpected base fees, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Value{}), got.Tips; want != got {
+		t.Errorf("unexpected tips, wanted %v, got %v", want, got)
+	}
+}
+
+func TestFeePolicy_SharesAreCredited(t *testing.T) {
+	coinbase := tosca.Address{0xc0}
+	developer1 := tosca.Address{0xd1}
+	developer2 := tosca.Address{0xd2}
+
+	p := &processor{config: Config{FeePolicy: FeePolicyFunc(func(summary FeeSummary) FeeDistribution {
+		return FeeDistribution{
+			Burned:    tosca.NewValue(500),
+			Validator: tosca.NewValue(300),
+			Developers: []FeeShare{
+				{Recipient: developer1, Amount: tosca.NewValue(150)},
+				{Recipient: developer2, Amount: tosca.NewValue(50)},
+			},
+		}
+	})}}
+
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	context.EXPECT().GetBalance(coinbase).Return(tosca.NewValue(1))
+	context.EXPECT().SetBalance(coinbase, tosca.NewValue(301))
+	context.EXPECT().GetBalance(developer1).Return(tosca.NewValue(0))
+	context.EXPECT().SetBalance(developer1, tosca.NewValue(150))
+	context.EXPECT().GetBalance(developer2).Return(tosca.NewValue(2))
+	context.EXPECT().SetBalance(developer2, tosca.NewValue(52))
+
+	blockParameters := tosca.BlockParameters{Coinbase: coinbase}
+	transaction := tosca.Transaction{GasPrice: tosca.NewValue(10)}
+	if err := p.distributeFees(blockParameters, transaction, tosca.Receipt{GasUsed: 100}, context); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFeePolicy_InvalidDistributionsAreReported(t *testing.T) {
+	tests := map[string]FeeDistribution{
+		"too little": {Burned: tosca.NewValue(999)},
+		"too much":   {Burned: tosca.NewValue(1000), Validator: tosca.NewValue(1)},
+		"overflow": {
+			Burned: tosca.NewValue(1000),
+			Developers: []FeeShare{
+				{Amount: tosca.NewValue(1<<63, 0, 0, 0)},
+				{Amount: tosca.NewValue(1<<63, 0, 0, 0)},
+			},
+		},
+	}
+
+	for name, distribution := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &processor{config: Config{FeePolicy: FeePolicyFunc(func(FeeSummary) FeeDistribution {
+				return distribution
+			})}}
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			transaction := tosca.Transaction{GasPrice: tosca.NewValue(10)}
+			if err := p.distributeFees(tosca.BlockParameters{}, transaction, tosca.Receipt{GasUsed: 100}, context); err == nil {
+				t.Errorf("invalid distribution was not detected")
+			}
+		})
+	}
+}
+
+func TestFeePolicy_IsConsultedForExecutedTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	sender := tosca.Address{1}
+	state.EXPECT().GetNonce(sender).Return(uint64(0)).AnyTimes()
+	state.EXPECT().SetNonce(sender, uint64(1))
+	state.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+	state.EXPECT().GetCode(gomock.Any()).AnyTimes()
+	state.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+	state.EXPECT().GetBalance(gomock.Any()).Return(tosca.NewValue(1_000_000)).AnyTimes()
+	state.EXPECT().SetBalance(gomock.Any(), gomock.Any()).AnyTimes()
+	state.EXPECT().CreateSnapshot().AnyTimes()
+	state.EXPECT().GetLogs().AnyTimes()
+	interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true}, nil)
+
+	calls := 0
+	p := NewProcessor(interpreter, Config{FeePolicy: FeePolicyFunc(func(summary FeeSummary) FeeDistribution {
+		calls++
+		if want, got := tosca.Gas(TxGas), summary.Receipt.GasUsed; want != got {
+			t.Errorf("unexpected gas used, wanted %d, got %d", want, got)
+		}
+		return FeeDistribution{Burned: summary.Fees}
+	})})
+
+	receipt, err := p.Run(context.Background(), tosca.BlockParameters{}, tosca.Transaction{
+		Sender:    sender,
+		Recipient: &tosca.Address{2},
+		GasLimit:  TxGas,
+		GasPrice:  tosca.NewValue(1),
+	}, state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !receipt.Success {
+		t.Errorf("transaction failed")
+	}
+	if calls != 1 {
+		t.Errorf("unexpected number of fee policy calls, wanted 1, got %d", calls)
+	}
+}
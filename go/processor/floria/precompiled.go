@@ -11,9 +11,18 @@
 package floria
 
 import (
+	"maps"
+	"math"
+	"math/big"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/ecrecover"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
 	"github.com/ethereum/go-ethereum/common"
 	geth "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 func isPrecompiled(address tosca.Address, revision tosca.Revision) bool {
@@ -47,16 +56,178 @@ func getPrecompiledContract(address tosca.Address, revision tosca.Revision) (get
 }
 
 func getPrecompiledContracts(revision tosca.Revision) map[common.Address]geth.PrecompiledContract {
-	var precompiles map[common.Address]geth.PrecompiledContract
-	switch revision {
-	case tosca.R13_Cancun:
-		precompiles = geth.PrecompiledContractsCancun
-	case tosca.R12_Shanghai, tosca.R11_Paris, tosca.R10_London, tosca.R09_Berlin:
-		precompiles = geth.PrecompiledContractsBerlin
+	switch {
+	case revisions.IsActive(revision, revisions.EIP7883):
+		return precompiledContractsOsaka
+	case revisions.IsActive(revision, revisions.EIP4844):
+		return precompiledContractsCancun
+	case revisions.IsActive(revision, revisions.EIP2565):
+		return precompiledContractsBerlin
 	default: // Istanbul is the oldest supported revision supported by Sonic
-		precompiles = geth.PrecompiledContractsIstanbul
+		return precompiledContractsIstanbul
+	}
+}
+
+var (
+	precompiledContractsIstanbul = withEcRecover(geth.PrecompiledContractsIstanbul)
+	precompiledContractsBerlin   = withEcRecover(withModExp(geth.PrecompiledContractsBerlin, modExp{}))
+	precompiledContractsCancun   = withEcRecover(withModExp(geth.PrecompiledContractsCancun, modExp{}))
+	precompiledContractsOsaka    = withEcRecover(withModExp(geth.PrecompiledContractsCancun, modExp{eip7883: true}))
+)
+
+// withEcRecover returns a copy of the given set of precompiled contracts with
+// the ECRECOVER contract replaced by one using the shared signature cache.
+func withEcRecover(
+	contracts map[common.Address]geth.PrecompiledContract,
+) map[common.Address]geth.PrecompiledContract {
+	res := maps.Clone(contracts)
+	res[ecRecoverAddress] = ecRecover{cache: ecrecover.GetSharedCache()}
+	return res
+}
+
+var ecRecoverAddress = common.BytesToAddress([]byte{0x01})
+
+// ecRecover is the ECRECOVER precompiled contract, recovering signers through
+// a cache shared with the recovery of transaction senders.
+type ecRecover struct {
+	cache *ecrecover.Cache
+}
+
+func (ecRecover) RequiredGas([]byte) uint64 {
+	return params.EcrecoverGas
+}
+
+func (c ecRecover) Run(input []byte) ([]byte, error) {
+	// The input is (hash, v, r, s), each 32 bytes, padded with zeros.
+	input = getPaddedData(input, 0, 128)
+	v := input[63] - 27
+	r := new(big.Int).SetBytes(input[64:96])
+	s := new(big.Int).SetBytes(input[96:128])
+
+	// In contrast to transaction signatures, the S value is not restricted to
+	// the lower half of the curve order. Invalid signatures produce no output.
+	if slices.ContainsFunc(input[32:63], func(b byte) bool { return b != 0 }) ||
+		!crypto.ValidateSignatureValues(v, r, s, false) {
+		return nil, nil
+	}
+	signature := ecrecover.Signature{
+		Hash: tosca.Hash(input[0:32]),
+		R:    [32]byte(input[64:96]),
+		S:    [32]byte(input[96:128]),
+		V:    v,
+	}
+	signer, err := c.cache.Recover(signature)
+	if err != nil {
+		return nil, nil
+	}
+	return common.LeftPadBytes(signer[:], 32), nil
+}
+
+// withModExp returns a copy of the given set of precompiled contracts with
+// the MODEXP contract replaced by the given one.
+func withModExp(
+	contracts map[common.Address]geth.PrecompiledContract,
+	modExp modExp,
+) map[common.Address]geth.PrecompiledContract {
+	res := make(map[common.Address]geth.PrecompiledContract, len(contracts))
+	for address, contract := range contracts {
+		res[address] = contract
+	}
+	res[modExpAddress] = modExp
+	return res
+}
+
+var modExpAddress = common.BytesToAddress([]byte{0x05})
+
+// modExp is the MODEXP precompiled contract introduced by EIP-198, priced
+// according to EIP-2565 or, if eip7883 is set, according to EIP-7883. The
+// computation itself is the same for all revisions.
+type modExp struct {
+	eip7883 bool
+}
+
+func (c modExp) RequiredGas(input []byte) uint64 {
+	var (
+		baseLen = new(big.Int).SetBytes(getPaddedData(input, 0, 32))
+		expLen  = new(big.Int).SetBytes(getPaddedData(input, 32, 32))
+		modLen  = new(big.Int).SetBytes(getPaddedData(input, 64, 32))
+	)
+	if len(input) > 96 {
+		input = input[96:]
+	} else {
+		input = input[:0]
+	}
+
+	// The iteration count is derived from the first 32 bytes of the exponent
+	// and the length of the exponent exceeding those bytes.
+	expHead := new(big.Int)
+	if big.NewInt(int64(len(input))).Cmp(baseLen) > 0 {
+		headLen := uint64(32)
+		if expLen.IsUint64() && expLen.Uint64() < headLen {
+			headLen = expLen.Uint64()
+		}
+		expHead.SetBytes(getPaddedData(input, baseLen.Uint64(), headLen))
+	}
+	iterations := new(big.Int)
+	if expLen.Cmp(big.NewInt(32)) > 0 {
+		multiplier := int64(8)
+		if c.eip7883 {
+			multiplier = 16
+		}
+		iterations.Sub(expLen, big.NewInt(32))
+		iterations.Mul(iterations, big.NewInt(multiplier))
+	}
+	if bitLen := expHead.BitLen(); bitLen > 0 {
+		iterations.Add(iterations, big.NewInt(int64(bitLen-1)))
+	}
+	if iterations.Sign() == 0 {
+		iterations.SetInt64(1)
+	}
+
+	// The multiplication complexity is derived from the number of 64-bit
+	// words of the larger of the base and the modulus.
+	maxLen := baseLen
+	if modLen.Cmp(baseLen) > 0 {
+		maxLen = modLen
+	}
+	words := new(big.Int).Add(maxLen, big.NewInt(7))
+	words.Rsh(words, 3)
+	complexity := new(big.Int).Mul(words, words)
+
+	var minGas uint64
+	gas := new(big.Int)
+	if c.eip7883 {
+		minGas = 500
+		if maxLen.Cmp(big.NewInt(32)) <= 0 {
+			complexity.SetInt64(16)
+		} else {
+			complexity.Lsh(complexity, 1)
+		}
+		gas.Mul(complexity, iterations)
+	} else {
+		minGas = 200
+		gas.Mul(complexity, iterations)
+		gas.Div(gas, big.NewInt(3))
+	}
+
+	if !gas.IsUint64() {
+		return math.MaxUint64
+	}
+	return max(gas.Uint64(), minGas)
+}
+
+func (modExp) Run(input []byte) ([]byte, error) {
+	return geth.PrecompiledContractsBerlin[modExpAddress].Run(input)
+}
+
+// getPaddedData returns size bytes of data starting at the given offset,
+// padded with zeros where the range exceeds the data.
+func getPaddedData(data []byte, offset, size uint64) []byte {
+	res := make([]byte, size)
+	if offset < uint64(len(data)) {
+		copy(res, data[offset:])
 	}
-	return precompiles
+	return res
 }
 
 func getPrecompiledAddresses(revision tosca.Revision) []tosca.Address {
@@ -11,11 +11,18 @@
 package floria
 
 import (
+	"bytes"
+	"math"
+	"math/big"
 	"strings"
 	"testing"
 
 	test_utils "github.com/Fantom-foundation/Tosca/go/processor"
+	"github.com/Fantom-foundation/Tosca/go/processor/ecrecover"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	geth "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 func TestPrecompiled_RightNumberOfContractsDependingOnRevision(t *testing.T) {
@@ -83,3 +90,210 @@ func TestPrecompiled_AddressesAreHandledCorrectly(t *testing.T) {
 		})
 	}
 }
+
+func TestPrecompiled_ModExpPricingDependsOnRevision(t *testing.T) {
+	tests := map[tosca.Revision]geth.PrecompiledContract{
+		tosca.R07_Istanbul: geth.PrecompiledContractsIstanbul[modExpAddress],
+		tosca.R09_Berlin:   modExp{},
+		tosca.R10_London:   modExp{},
+		tosca.R11_Paris:    modExp{},
+		tosca.R12_Shanghai: modExp{},
+		tosca.R13_Cancun:   modExp{},
+		tosca.R14_Prague:   modExp{},
+		tosca.R15_Osaka:    modExp{eip7883: true},
+	}
+	for revision, want := range tests {
+		got, found := getPrecompiledContract(tosca.Address(modExpAddress), revision)
+		if !found {
+			t.Fatalf("MODEXP not found in revision %v", revision)
+		}
+		if want != got {
+			t.Errorf("unexpected MODEXP contract in revision %v, wanted %v, got %v", revision, want, got)
+		}
+	}
+}
+
+func TestModExp_RequiredGasMatchesOfficialVectors(t *testing.T) {
+	// The gas costs are taken from the test vectors of EIP-2565 and EIP-7883.
+	// Gas costs only depend on the lengths of the operands and the exponent,
+	// which is why the base and modulus of the nagydani vectors are filled
+	// with arbitrary values.
+	square, qube, pow0x10001 := []byte{2}, []byte{3}, []byte{1, 0, 1}
+	tests := map[string]struct {
+		input  []byte
+		berlin uint64
+		osaka  uint64
+	}{
+		"eip_example1": {
+			common.FromHex("0000000000000000000000000000000000000000000000000000000000000001000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000200" +
+				"3fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2efffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"),
+			1360, 4080,
+		},
+		"eip_example2": {
+			common.FromHex("000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000000000000000000020" +
+				"fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2efffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f"),
+			1360, 4080,
+		},
+		"nagydani-1-square":     {makeModExpInput(64, square), 200, 500},
+		"nagydani-1-qube":       {makeModExpInput(64, qube), 200, 500},
+		"nagydani-1-pow0x10001": {makeModExpInput(64, pow0x10001), 341, 2048},
+		"nagydani-2-square":     {makeModExpInput(128, square), 200, 512},
+		"nagydani-2-qube":       {makeModExpInput(128, qube), 200, 512},
+		"nagydani-2-pow0x10001": {makeModExpInput(128, pow0x10001), 1365, 8192},
+		"nagydani-3-square":     {makeModExpInput(256, square), 341, 2048},
+		"nagydani-3-qube":       {makeModExpInput(256, qube), 341, 2048},
+		"nagydani-3-pow0x10001": {makeModExpInput(256, pow0x10001), 5461, 32768},
+		"nagydani-4-square":     {makeModExpInput(512, square), 1365, 8192},
+		"nagydani-4-qube":       {makeModExpInput(512, qube), 1365, 8192},
+		"nagydani-4-pow0x10001": {makeModExpInput(512, pow0x10001), 21845, 131072},
+		"nagydani-5-square":     {makeModExpInput(1024, square), 5461, 32768},
+		"nagydani-5-qube":       {makeModExpInput(1024, qube), 5461, 32768},
+		"nagydani-5-pow0x10001": {makeModExpInput(1024, pow0x10001), 87381, 524288},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, got := test.berlin, (modExp{}).RequiredGas(test.input); want != got {
+				t.Errorf("unexpected EIP-2565 costs, wanted %d, got %d", want, got)
+			}
+			if want, got := test.osaka, (modExp{eip7883: true}).RequiredGas(test.input); want != got {
+				t.Errorf("unexpected EIP-7883 costs, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestModExp_BerlinPricingMatchesGeth(t *testing.T) {
+	reference := geth.PrecompiledContractsBerlin[modExpAddress]
+	inputs := [][]byte{
+		nil,
+		{1},
+		makeModExpInput(0, nil),
+		makeModExpInput(1, []byte{0}),
+		makeModExpInput(31, make([]byte, 32)),
+		makeModExpInput(33, append([]byte{0x80}, make([]byte, 40)...)),
+		makeModExpInput(100, append(make([]byte, 33), 0x01)),
+		// The exponent is truncated by the end of the input.
+		makeModExpInput(16, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})[:96+16+3],
+		// Lengths exceeding the input.
+		append(common.LeftPadBytes([]byte{0xff, 0xff}, 32), common.LeftPadBytes([]byte{0xff, 0xff, 0xff}, 32)...),
+	}
+	for i, input := range inputs {
+		if want, got := reference.RequiredGas(input), (modExp{}).RequiredGas(input); want != got {
+			t.Errorf("unexpected costs for input %d, wanted %d, got %d", i, want, got)
+		}
+		wantOutput, wantErr := reference.Run(input)
+		gotOutput, gotErr := modExp{}.Run(input)
+		if !bytes.Equal(wantOutput, gotOutput) || wantErr != gotErr {
+			t.Errorf("unexpected result for input %d, wanted %x/%v, got %x/%v", i, wantOutput, wantErr, gotOutput, gotErr)
+		}
+	}
+}
+
+func TestModExp_RequiredGasSaturatesForHugeOperands(t *testing.T) {
+	huge := bytes.Repeat([]byte{0xff}, 32)
+	input := append(append(append([]byte{}, huge...), huge...), huge...)
+	for _, contract := range []modExp{{}, {eip7883: true}} {
+		if want, got := uint64(math.MaxUint64), contract.RequiredGas(input); want != got {
+			t.Errorf("unexpected costs for %v, wanted %d, got %d", contract, want, got)
+		}
+	}
+}
+
+// makeModExpInput creates a MODEXP input with the given exponent and a base
+// and modulus of the given length.
+func makeModExpInput(length int, exponent []byte) []byte {
+	res := common.LeftPadBytes(big.NewInt(int64(length)).Bytes(), 32)
+	res = append(res, common.LeftPadBytes(big.NewInt(int64(len(exponent))).Bytes(), 32)...)
+	res = append(res, common.LeftPadBytes(big.NewInt(int64(length)).Bytes(), 32)...)
+	res = append(res, bytes.Repeat([]byte{0xab}, length)...)
+	res = append(res, exponent...)
+	res = append(res, bytes.Repeat([]byte{0xcd}, length)...)
+	return res
+}
+
+func TestEcRecover_ResultsMatchGeth(t *testing.T) {
+	reference := geth.PrecompiledContractsBerlin[ecRecoverAddress]
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("message"))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	makeInput := func(v byte, r, s []byte) []byte {
+		input := append([]byte{}, hash...)
+		input = append(input, common.LeftPadBytes([]byte{v}, 32)...)
+		input = append(input, r...)
+		return append(input, s...)
+	}
+	highS := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+
+	inputs := map[string][]byte{
+		"empty":         nil,
+		"valid":         makeInput(sig[64]+27, sig[:32], sig[32:64]),
+		"flipped v":     makeInput((sig[64]^1)+27, sig[:32], sig[32:64]),
+		"high s":        makeInput((sig[64]^1)+27, sig[:32], highS.FillBytes(make([]byte, 32))),
+		"invalid v":     makeInput(29, sig[:32], sig[32:64]),
+		"zero r":        makeInput(sig[64]+27, make([]byte, 32), sig[32:64]),
+		"truncated":     makeInput(sig[64]+27, sig[:32], sig[32:64])[:100],
+		"dirty v":       append(append(append([]byte{}, hash...), bytes.Repeat([]byte{1}, 32)...), sig[:64]...),
+		"extra content": append(makeInput(sig[64]+27, sig[:32], sig[32:64]), 1, 2, 3),
+	}
+	contract := ecRecover{cache: ecrecover.GetSharedCache()}
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			if want, got := reference.RequiredGas(input), contract.RequiredGas(input); want != got {
+				t.Errorf("unexpected costs, wanted %d, got %d", want, got)
+			}
+			wantOutput, wantErr := reference.Run(input)
+			gotOutput, gotErr := contract.Run(input)
+			if !bytes.Equal(wantOutput, gotOutput) || wantErr != gotErr {
+				t.Errorf("unexpected result, wanted %x/%v, got %x/%v", wantOutput, wantErr, gotOutput, gotErr)
+			}
+		})
+	}
+}
+
+func TestEcRecover_RecoveredSignersAreCached(t *testing.T) {
+	cache, err := ecrecover.NewCache(10)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	hash := crypto.Keccak256([]byte("message"))
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+	input := append(append(hash, common.LeftPadBytes([]byte{sig[64] + 27}, 32)...), sig[:64]...)
+
+	contract := ecRecover{cache: cache}
+	want := common.LeftPadBytes(crypto.PubkeyToAddress(key.PublicKey).Bytes(), 32)
+	for range 3 {
+		got, err := contract.Run(input)
+		if err != nil || !bytes.Equal(want, got) {
+			t.Fatalf("unexpected result, wanted %x, got %x/%v", want, got, err)
+		}
+	}
+	if want, got := (ecrecover.CacheStatistics{Hits: 2, Misses: 1, Entries: 1, Capacity: 10}), cache.Statistics(); want != got {
+		t.Errorf("unexpected cache statistics, wanted %+v, got %+v", want, got)
+	}
+}
+
+func TestPrecompiled_EcRecoverUsesSharedCache(t *testing.T) {
+	for _, revision := range []tosca.Revision{tosca.R07_Istanbul, tosca.R09_Berlin, tosca.R13_Cancun, tosca.R15_Osaka} {
+		contract, found := getPrecompiledContract(tosca.Address(ecRecoverAddress), revision)
+		if !found {
+			t.Fatalf("ECRECOVER not found in revision %v", revision)
+		}
+		if want, got := (ecRecover{cache: ecrecover.GetSharedCache()}), contract; want != got {
+			t.Errorf("unexpected ECRECOVER contract in revision %v, got %v", revision, got)
+		}
+	}
+}
@@ -11,9 +11,14 @@
 package floria
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/address"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
 )
 
 const (
@@ -23,86 +28,252 @@ const (
 	TxDataZeroGasEIP2028      = 4
 	TxAccessListAddressGas    = 2400
 	TxAccessListStorageKeyGas = 1900
+	InitCodeWordGas           = 2
 
-	createGasCostPerByte = 200
-	maxCodeSize          = 24576
-	maxInitCodeSize      = 2 * maxCodeSize
+	// MaxInitCodeSize is the default limit for the size of init codes of
+	// contract creation transactions introduced by EIP-3860 with Shanghai.
+	MaxInitCodeSize = 2 * tosca.MaxCodeSize
 
 	MaxRecursiveDepth = 1024 // Maximum depth of call/create stack.
 )
 
+// ErrNonceOverflow is reported if the nonce of an account can not be
+// incremented since it has reached the maximum value of 2^64-1. As defined by
+// EIP-2681, such accounts can neither send transactions nor create contracts.
+const ErrNonceOverflow = tosca.ConstError("nonce overflow")
+
+const (
+	// ErrNonceTooLow is reported if the nonce of a transaction is lower than
+	// the nonce of its sender, indicating an already included transaction.
+	ErrNonceTooLow = tosca.ConstError("nonce too low")
+
+	// ErrNonceTooHigh is reported if the nonce of a transaction is higher
+	// than the nonce of its sender. Such transactions may become executable
+	// once the gap is filled by other transactions of the same sender.
+	ErrNonceTooHigh = tosca.ConstError("nonce too high")
+)
+
 func init() {
-	tosca.RegisterProcessorFactory("floria", newProcessor)
+	tosca.RegisterProcessorFactory("floria", newProcessor, tosca.ImplementationMetadata{
+		Language:       "Go",
+		OldestRevision: tosca.R07_Istanbul,
+		NewestRevision: tosca.R13_Cancun,
+	})
 }
 
 func newProcessor(interpreter tosca.Interpreter) tosca.Processor {
-	return &processor{
+	return NewProcessor(interpreter, Config{})
+}
+
+// Config contains chain specific parameters of the floria processor. The zero
+// value configures the processor to follow Ethereum's rules.
+type Config struct {
+	// MaxCodeSize is the maximum size of the code of created contracts. If
+	// zero, the limit of EIP-170 is used.
+	MaxCodeSize int
+
+	// MaxInitCodeSize is the maximum size of the init code of contract
+	// creation transactions enforced since Shanghai. If zero, twice the
+	// maximum code size is used, following EIP-3860. Limits for init codes
+	// of contracts created by other contracts are enforced by the
+	// interpreter.
+	MaxInitCodeSize int
+
+	// FeePolicy, if set, distributes the fees of executed transactions. If
+	// nil, fees are not credited to any account.
+	FeePolicy FeePolicy
+
+	// RefundPolicy, if set, caps the gas refunds of executed transactions.
+	// If nil, the caps defined by Ethereum are applied.
+	RefundPolicy tosca.RefundPolicy
+
+	// Metrics, if set, receives the number, duration, gas usage, and
+	// failures of executed transactions.
+	Metrics tosca.Metrics
+
+	// Profiler, if set, records the wall-clock time spent on executed
+	// transactions and contracts, enabling the identification of contracts
+	// with an unusually high time per gas ratio.
+	Profiler *tosca.ExecutionProfiler
+
+	// Logger, if set, receives notes on skipped transactions and warnings on
+	// interpreter errors, which are otherwise only reported as failed calls
+	// or transactions.
+	Logger *slog.Logger
+}
+
+// NewProcessor creates a floria processor using the given interpreter and
+// chain configuration. Processors created through the processor registry use
+// the default configuration.
+func NewProcessor(interpreter tosca.Interpreter, config Config) tosca.Processor {
+	config = config.withDefaults()
+	if config.Profiler != nil {
+		interpreter = tosca.NewProfilingInterpreter(interpreter, config.Profiler)
+	}
+	if config.Logger != nil {
+		interpreter = tosca.NewLoggingInterpreter(interpreter, config.Logger)
+	}
+	processor := &processor{
 		interpreter: interpreter,
+		config:      config,
+	}
+	var res tosca.Processor = processor
+	if config.Profiler != nil {
+		res = tosca.NewProfilingProcessor(res, config.Profiler)
+	}
+	if config.Metrics != nil {
+		return tosca.NewMeasuredProcessor(res, config.Metrics, "floria")
 	}
+	return res
+}
+
+// withDefaults returns a copy of the configuration with unset limits
+// replaced by their defaults.
+func (c Config) withDefaults() Config {
+	if c.MaxCodeSize == 0 {
+		c.MaxCodeSize = tosca.MaxCodeSize
+	}
+	if c.MaxInitCodeSize == 0 {
+		c.MaxInitCodeSize = 2 * c.MaxCodeSize
+	}
+	return c
 }
 
 type processor struct {
 	interpreter tosca.Interpreter
+	config      Config
 }
 
 func (p *processor) Run(
+	ctx context.Context,
 	blockParameters tosca.BlockParameters,
 	transaction tosca.Transaction,
-	context tosca.TransactionContext,
+	txContext tosca.TransactionContext,
 ) (tosca.Receipt, error) {
+	return p.run(ctx, blockParameters, transaction, txContext, tosca.SimulationOptions{})
+}
+
+func (p *processor) Simulate(
+	ctx context.Context,
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	txContext tosca.TransactionContext,
+	options tosca.SimulationOptions,
+) (tosca.SimulationResult, error) {
+	return tosca.SimulateWithinLimits(ctx, options, func(ctx context.Context, options tosca.SimulationOptions) (tosca.SimulationResult, error) {
+		receipt, err := p.run(ctx, blockParameters, transaction, txContext, options)
+		if err != nil && errors.Is(err, ctx.Err()) {
+			return tosca.SimulationResult{Aborted: true}, nil
+		}
+		return tosca.SimulationResult{Receipt: receipt}, err
+	})
+}
+
+func (p *processor) run(
+	ctx context.Context,
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	txContext tosca.TransactionContext,
+	options tosca.SimulationOptions,
+) (receipt tosca.Receipt, err error) {
+	options.Apply(&blockParameters, &transaction)
+	if options.UnlimitedGas {
+		options.NoBalanceCheck = true
+	}
+	txContext, err = options.StateOverrides.Apply(txContext)
+	if err != nil {
+		return tosca.Receipt{}, err
+	}
+
+	var statistics *tosca.AccessStatistics
+	if options.AccessStatistics {
+		statistics = &tosca.AccessStatistics{}
+		txContext = tosca.NewAccessStatisticsTransactionContext(txContext, statistics)
+	}
+
+	if listener := options.LogListener; listener != nil {
+		txContext = tosca.NewLogStreamingTransactionContext(txContext, listener)
+	}
+
+	if tracer := options.Tracer; tracer != nil {
+		txContext = tosca.NewTracingTransactionContext(txContext, tracer)
+		tracer.OnTxStart(blockParameters, transaction)
+		defer func() { tracer.OnTxEnd(receipt, err) }()
+	}
+
 	errorReceipt := tosca.Receipt{
 		Success: false,
 		GasUsed: transaction.GasLimit,
 	}
 	gas := transaction.GasLimit
 
-	if nonceCheck(transaction.Nonce, context.GetNonce(transaction.Sender)) != nil {
+	if err := nonceCheck(transaction.Nonce, txContext.GetNonce(transaction.Sender)); err != nil {
+		p.logSkipped(transaction, err)
 		return tosca.Receipt{}, nil
 	}
 
-	if eoaCheck(transaction.Sender, context) != nil {
+	if err := eoaCheck(transaction.Sender, txContext); err != nil {
+		p.logSkipped(transaction, err)
 		return tosca.Receipt{}, nil
 	}
 
-	if err := buyGas(transaction, context); err != nil {
+	if err := initCodeCheck(transaction, blockParameters.Revision, p.config.MaxInitCodeSize); err != nil {
+		p.logSkipped(transaction, err)
 		return tosca.Receipt{}, nil
 	}
 
-	setupGas := calculateSetupGas(transaction)
-	if gas < setupGas {
+	if !options.NoBalanceCheck {
+		if err := buyGas(transaction, txContext); err != nil {
+			p.logSkipped(transaction, err)
+			return tosca.Receipt{}, nil
+		}
+	}
+
+	setupGas := calculateSetupGas(transaction, blockParameters.Revision)
+	floorGas := calldataFloorGas(transaction, blockParameters.Revision)
+	if gas < setupGas || gas < floorGas {
+		if !options.NoBalanceCheck {
+			if err := p.distributeFees(blockParameters, transaction, errorReceipt, txContext); err != nil {
+				return errorReceipt, err
+			}
+		}
 		return errorReceipt, nil
 	}
 	gas -= setupGas
 
-	if blockParameters.Revision >= tosca.R12_Shanghai && transaction.Recipient == nil &&
-		len(transaction.Input) > maxInitCodeSize {
-		return tosca.Receipt{}, nil
+	var usage *tosca.ResourceUsage
+	if options.ResourceUsage {
+		usage = &tosca.ResourceUsage{}
 	}
 
 	transactionParameters := tosca.TransactionParameters{
-		Origin:     transaction.Sender,
-		GasPrice:   transaction.GasPrice,
-		BlobHashes: []tosca.Hash{}, // ?
+		Origin:        transaction.Sender,
+		GasPrice:      transaction.GasPrice,
+		BlobHashes:    []tosca.Hash{}, // ?
+		Interrupt:     ctx,
+		Tracer:        options.Tracer,
+		ResourceUsage: usage,
 	}
 
+	touched := &touchedAccounts{}
 	runContext := runContext{
-		context,
+		txContext,
 		p.interpreter,
 		blockParameters,
 		transactionParameters,
 		0,
 		false,
+		p.config.MaxCodeSize,
+		touched,
 	}
 
-	if blockParameters.Revision >= tosca.R09_Berlin {
-		setUpAccessList(transaction, &runContext, blockParameters.Revision)
-	}
+	PrepareAccessList(&runContext, blockParameters, transaction)
 
 	callParameters := callParameters(transaction, gas)
 	kind := callKind(transaction)
 
 	if kind == tosca.Call {
-		context.SetNonce(transaction.Sender, context.GetNonce(transaction.Sender)+1)
+		txContext.SetNonce(transaction.Sender, txContext.GetNonce(transaction.Sender)+1)
 	}
 
 	result, err := runContext.Call(kind, callParameters)
@@ -110,46 +281,135 @@ func (p *processor) Run(
 		return errorReceipt, err
 	}
 
+	// Nested calls aborted by an interrupt are reported as failed calls to
+	// the calling contract, which may complete its execution afterwards. Thus,
+	// the interrupt needs to be checked once more for the overall transaction.
+	if err := ctx.Err(); err != nil {
+		return errorReceipt, err
+	}
+
+	// Like in geth, the address of the contract is reported for creation
+	// transactions even if the creation failed.
 	var createdAddress *tosca.Address
 	if kind == tosca.Create {
-		createdAddress = &result.CreatedAddress
+		created := address.Create(transaction.Sender, transaction.Nonce)
+		createdAddress = &created
 	}
 
-	gasLeft := calculateGasLeft(transaction, result, blockParameters.Revision)
-	refundGas(transaction, context, gasLeft)
+	var penalty tosca.Gas
+	if !options.UnlimitedGas {
+		penalty = unusedGasPenalty(transaction, result.GasLeft)
+	}
+	gasLeft := calculateGasLeft(transaction, result, penalty, blockParameters.Revision, p.config.RefundPolicy)
+	var surcharge tosca.Gas
+	if used := transaction.GasLimit - gasLeft; used < floorGas {
+		surcharge = floorGas - used
+		gasLeft -= surcharge
+	}
+	if !options.NoBalanceCheck {
+		refundGas(transaction, txContext, gasLeft)
+	}
+
+	logs := txContext.GetLogs()
+
+	var breakdown *tosca.GasBreakdown
+	if options.GasBreakdown {
+		breakdown = &tosca.GasBreakdown{
+			IntrinsicGas:     setupGas,
+			ExecutionGas:     gas - result.GasLeft,
+			UnusedGasPenalty: penalty,
+			Refund:           gasLeft + surcharge - (result.GasLeft - penalty),
+			FloorSurcharge:   surcharge,
+			Returned:         gasLeft,
+		}
+	}
+
+	receipt = tosca.Receipt{
+		Success:          result.Success,
+		GasUsed:          transaction.GasLimit - gasLeft,
+		CalldataFloorGas: floorGas,
+		ContractAddress:  createdAddress,
+		Output:           result.Output,
+		Logs:             logs,
+		GasBreakdown:     breakdown,
+		ResourceUsage:    usage,
+		AccessStatistics: statistics,
+	}
+	if !options.NoBalanceCheck {
+		if err := p.distributeFees(blockParameters, transaction, receipt, txContext); err != nil {
+			return errorReceipt, err
+		}
+	}
+	removeDeadAccounts(txContext, touched)
+	return receipt, nil
+}
 
-	logs := context.GetLogs()
+// calldataFloorGas returns the calldata floor of the given transaction, which
+// is zero before the activation of EIP-7623.
+func calldataFloorGas(transaction tosca.Transaction, revision tosca.Revision) tosca.Gas {
+	if !revisions.IsActive(revision, revisions.EIP7623) {
+		return 0
+	}
+	return tosca.CalldataFloorGas(transaction.Input)
+}
 
-	return tosca.Receipt{
-		Success:         result.Success,
-		GasUsed:         transaction.GasLimit - gasLeft,
-		ContractAddress: createdAddress,
-		Output:          result.Output,
-		Logs:            logs,
-	}, nil
+// logSkipped notes that the given transaction is skipped for the given reason.
+// Skipped transactions are not reported as errors, but result in an empty
+// receipt.
+func (p *processor) logSkipped(transaction tosca.Transaction, reason error) {
+	if p.config.Logger == nil {
+		return
+	}
+	p.config.Logger.Info("transaction skipped",
+		"sender", transaction.Sender,
+		"nonce", transaction.Nonce,
+		"reason", reason,
+	)
 }
 
 func nonceCheck(transactionNonce uint64, stateNonce uint64) error {
-	if transactionNonce != stateNonce {
-		return fmt.Errorf("nonce mismatch: %v != %v", transactionNonce, stateNonce)
+	if transactionNonce < stateNonce {
+		return fmt.Errorf("%w, nonce mismatch: %v != %v", ErrNonceTooLow, transactionNonce, stateNonce)
+	}
+	if transactionNonce > stateNonce {
+		return fmt.Errorf("%w, nonce mismatch: %v != %v", ErrNonceTooHigh, transactionNonce, stateNonce)
 	}
 	if stateNonce+1 < stateNonce {
-		return fmt.Errorf("nonce overflow")
+		return ErrNonceOverflow
 	}
 	return nil
 }
 
 // Only accept transactions from externally owned accounts (EOAs) and not from contracts
-func eoaCheck(sender tosca.Address, context tosca.TransactionContext) error {
-	codehash := context.GetCodeHash(sender)
+func eoaCheck(sender tosca.Address, state tosca.WorldState) error {
+	codehash := state.GetCodeHash(sender)
 	if codehash != (tosca.Hash{}) && codehash != emptyCodeHash {
 		return fmt.Errorf("sender is not an EOA")
 	}
 	return nil
 }
 
-func setUpAccessList(transaction tosca.Transaction, context tosca.TransactionContext, revision tosca.Revision) {
-	if transaction.AccessList == nil {
+// initCodeCheck rejects contract creation transactions with init codes
+// exceeding the configured limit, as required by EIP-3860 since Shanghai.
+func initCodeCheck(transaction tosca.Transaction, revision tosca.Revision, limit int) error {
+	if !revisions.IsActive(revision, revisions.EIP3860) || transaction.Recipient != nil {
+		return nil
+	}
+	if size := len(transaction.Input); size > limit {
+		return fmt.Errorf("init code size %d exceeds limit %d", size, limit)
+	}
+	return nil
+}
+
+// PrepareAccessList marks the accounts and storage slots considered accessed
+// at the start of the given transaction as warm. Starting with Berlin, those
+// are the sender, the recipient, the precompiled contracts (EIP-2929), and the
+// entries of the transaction's access list (EIP-2930). Since Shanghai, the
+// coinbase of the block is included as well (EIP-3651). Before Berlin, no
+// accounts are pre-warmed.
+func PrepareAccessList(context tosca.TransactionContext, blockParameters tosca.BlockParameters, transaction tosca.Transaction) {
+	revision := blockParameters.Revision
+	if !revisions.IsActive(revision, revisions.EIP2929) {
 		return
 	}
 
@@ -163,6 +423,10 @@ func setUpAccessList(transaction tosca.Transaction, context tosca.TransactionCon
 		context.AccessAccount(address)
 	}
 
+	if revisions.IsActive(revision, revisions.EIP3651) {
+		context.AccessAccount(blockParameters.Coinbase)
+	}
+
 	for _, accessTuple := range transaction.AccessList {
 		context.AccessAccount(accessTuple.Address)
 		for _, key := range accessTuple.Keys {
@@ -191,36 +455,33 @@ func callParameters(transaction tosca.Transaction, gas tosca.Gas) tosca.CallPara
 	return callParameters
 }
 
-func calculateGasLeft(transaction tosca.Transaction, result tosca.CallResult, revision tosca.Revision) tosca.Gas {
-	gasLeft := result.GasLeft
-
-	// 10% of remaining gas is charged for non-internal transactions
-	if transaction.Sender != (tosca.Address{}) {
-		gasLeft -= gasLeft / 10
-	}
+func calculateGasLeft(
+	transaction tosca.Transaction,
+	result tosca.CallResult,
+	penalty tosca.Gas,
+	revision tosca.Revision,
+	refundPolicy tosca.RefundPolicy,
+) tosca.Gas {
+	gasLeft := result.GasLeft - penalty
 
 	if result.Success {
 		gasUsed := transaction.GasLimit - gasLeft
-		refund := result.GasRefund
-
-		maxRefund := tosca.Gas(0)
-		if revision < tosca.R10_London {
-			// Before EIP-3529: refunds were capped to gasUsed / 2
-			maxRefund = gasUsed / 2
-		} else {
-			// After EIP-3529: refunds are capped to gasUsed / 5
-			maxRefund = gasUsed / 5
-		}
-
-		if refund > maxRefund {
-			refund = maxRefund
-		}
-		gasLeft += refund
+		gasLeft += revisions.CapRefund(refundPolicy, revision, gasUsed, result.GasRefund)
 	}
 
 	return gasLeft
 }
 
+// unusedGasPenalty computes the share of the gas left after the execution
+// that is charged nevertheless. 10% of remaining gas is charged for
+// non-internal transactions.
+func unusedGasPenalty(transaction tosca.Transaction, gasLeft tosca.Gas) tosca.Gas {
+	if transaction.Sender == (tosca.Address{}) {
+		return 0
+	}
+	return gasLeft / 10
+}
+
 func refundGas(transaction tosca.Transaction, context tosca.TransactionContext, gasLeft tosca.Gas) {
 	refundValue := transaction.GasPrice.Scale(uint64(gasLeft))
 	senderBalance := context.GetBalance(transaction.Sender)
@@ -228,7 +489,7 @@ func refundGas(transaction tosca.Transaction, context tosca.TransactionContext,
 	context.SetBalance(transaction.Sender, senderBalance)
 }
 
-func calculateSetupGas(transaction tosca.Transaction) tosca.Gas {
+func calculateSetupGas(transaction tosca.Transaction, revision tosca.Revision) tosca.Gas {
 	var gas tosca.Gas
 	if transaction.Recipient == nil {
 		gas = TxGasContractCreation
@@ -250,6 +511,11 @@ func calculateSetupGas(transaction tosca.Transaction) tosca.Gas {
 		// greater than 2^64 / 16 - 53000 = ~10^18, which is not possible with real world hardware
 		gas += zeroBytes * TxDataZeroGasEIP2028
 		gas += nonZeroBytes * TxDataNonZeroGasEIP2028
+
+		// EIP-3860: init code is charged per word since Shanghai
+		if transaction.Recipient == nil && revisions.IsActive(revision, revisions.EIP3860) {
+			gas += tosca.Gas(tosca.SizeInWords(uint64(len(transaction.Input)))) * InitCodeWordGas
+		}
 	}
 
 	if transaction.AccessList != nil {
@@ -265,12 +531,9 @@ func calculateSetupGas(transaction tosca.Transaction) tosca.Gas {
 }
 
 func buyGas(transaction tosca.Transaction, context tosca.TransactionContext) error {
-	gas := transaction.GasPrice.Scale(uint64(transaction.GasLimit))
-
-	// Buy gas
-	senderBalance := context.GetBalance(transaction.Sender)
-	if senderBalance.Cmp(gas) < 0 {
-		return fmt.Errorf("insufficient balance: %v < %v", senderBalance, gas)
+	gas, senderBalance, err := balanceCheck(transaction, context)
+	if err != nil {
+		return err
 	}
 
 	senderBalance = tosca.Sub(senderBalance, gas)
@@ -278,3 +541,19 @@ func buyGas(transaction tosca.Transaction, context tosca.TransactionContext) err
 
 	return nil
 }
+
+// balanceCheck verifies that the sender of the given transaction can pay for
+// the maximum gas costs of the transaction. It returns those costs and the
+// current balance of the sender.
+func balanceCheck(transaction tosca.Transaction, state tosca.WorldState) (costs, balance tosca.Value, err error) {
+	gas, overflow := tosca.MulOverflow(transaction.GasPrice, tosca.NewValue(uint64(transaction.GasLimit)))
+	if overflow {
+		return tosca.Value{}, tosca.Value{}, fmt.Errorf("insufficient balance: gas costs exceed maximum value")
+	}
+
+	senderBalance := state.GetBalance(transaction.Sender)
+	if senderBalance.Cmp(gas) < 0 {
+		return tosca.Value{}, tosca.Value{}, fmt.Errorf("insufficient balance: %v < %v", senderBalance, gas)
+	}
+	return gas, senderBalance, nil
+}
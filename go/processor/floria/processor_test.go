@@ -11,11 +11,18 @@
 package floria
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/address"
 	"go.uber.org/mock/gomock"
 )
 
@@ -27,6 +34,39 @@ func TestProcessor_NewProcessorReturnsProcessor(t *testing.T) {
 	}
 }
 
+func TestProcessor_NewProcessorWithMetricsSupportsAllExtensions(t *testing.T) {
+	interpreter := tosca.NewMockInterpreter(gomock.NewController(t))
+	measured := NewProcessor(interpreter, Config{Metrics: tosca.NoMetrics})
+	if _, ok := measured.(*processor); ok {
+		t.Errorf("processor with metrics should be measured")
+	}
+	if _, ok := measured.(tosca.SimulatingProcessor); !ok {
+		t.Errorf("measured processor should support simulations")
+	}
+	if _, ok := measured.(tosca.SystemCallProcessor); !ok {
+		t.Errorf("measured processor should support system calls")
+	}
+}
+
+func TestProcessor_NewProcessorWithProfilerSupportsAllExtensions(t *testing.T) {
+	interpreter := tosca.NewMockInterpreter(gomock.NewController(t))
+	configs := map[string]Config{
+		"profiler":             {Profiler: tosca.NewExecutionProfiler()},
+		"profiler and metrics": {Profiler: tosca.NewExecutionProfiler(), Metrics: tosca.NoMetrics},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			processor := NewProcessor(interpreter, config)
+			if _, ok := processor.(tosca.SimulatingProcessor); !ok {
+				t.Errorf("profiling processor should support simulations")
+			}
+			if _, ok := processor.(tosca.SystemCallProcessor); !ok {
+				t.Errorf("profiling processor should support system calls")
+			}
+		})
+	}
+}
+
 func TestProcessorRegistry_InitProcessor(t *testing.T) {
 	processorFactories := tosca.GetAllRegisteredProcessorFactories()
 	if len(processorFactories) == 0 {
@@ -58,8 +98,8 @@ func TestProcessor_HandleNonce(t *testing.T) {
 
 func TestProcessor_NonceOverflowIsDetected(t *testing.T) {
 	err := nonceCheck(math.MaxUint64, math.MaxUint64)
-	if err == nil {
-		t.Errorf("nonceCheck did not spot nonce overflow")
+	if !errors.Is(err, ErrNonceOverflow) {
+		t.Errorf("nonceCheck did not spot nonce overflow, got %v", err)
 	}
 }
 
@@ -151,6 +191,23 @@ func TestProcessor_BuyGasInsufficientBalance(t *testing.T) {
 	}
 }
 
+func TestProcessor_BuyGasFailsIfGasCostsOverflow(t *testing.T) {
+	transaction := tosca.Transaction{
+		Sender:   tosca.Address{1},
+		GasLimit: 2,
+		GasPrice: tosca.NewValue(1<<63, 0, 0, 0),
+	}
+
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	context.EXPECT().GetBalance(transaction.Sender).Return(tosca.NewValue(1)).AnyTimes()
+
+	err := buyGas(transaction, context)
+	if err == nil {
+		t.Errorf("buyGas did not fail for overflowing gas costs")
+	}
+}
+
 func TestGasUsed(t *testing.T) {
 	tests := map[string]struct {
 		transaction     tosca.Transaction
@@ -253,7 +310,7 @@ func TestGasUsed(t *testing.T) {
 
 	for testName, test := range tests {
 		t.Run(testName, func(t *testing.T) {
-			actualGasLeft := calculateGasLeft(test.transaction, test.result, test.revision)
+			actualGasLeft := calculateGasLeft(test.transaction, test.result, unusedGasPenalty(test.transaction, test.result.GasLeft), test.revision, nil)
 
 			if actualGasLeft != test.expectedGasLeft {
 				t.Errorf("gasUsed returned incorrect result, got: %d, want: %d", actualGasLeft, test.expectedGasLeft)
@@ -262,6 +319,47 @@ func TestGasUsed(t *testing.T) {
 	}
 }
 
+func TestProcessor_CalculateGasLeftHonorsRefundPolicy(t *testing.T) {
+	transaction := tosca.Transaction{GasLimit: 1000}
+	result := tosca.CallResult{GasLeft: 500, Success: true, GasRefund: 300}
+	tests := map[string]struct {
+		quotient uint64
+		want     tosca.Gas
+	}{
+		"no refunds":   {quotient: 0, want: 500},
+		"full refund":  {quotient: 1, want: 800},
+		"tenth refund": {quotient: 10, want: 550},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := tosca.RefundPolicyFunc(func(tosca.Revision) uint64 { return test.quotient })
+			got := calculateGasLeft(transaction, result, 0, tosca.R13_Cancun, policy)
+			if test.want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
+func TestProcessor_UnusedGasPenaltyIsOnlyChargedForNonInternalTransactions(t *testing.T) {
+	tests := map[string]struct {
+		sender tosca.Address
+		want   tosca.Gas
+	}{
+		"internal":     {sender: tosca.Address{}, want: 0},
+		"non-internal": {sender: tosca.Address{1}, want: 50},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			transaction := tosca.Transaction{Sender: test.sender}
+			if got := unusedGasPenalty(transaction, 505); test.want != got {
+				t.Errorf("unexpected penalty, wanted %d, got %d", test.want, got)
+			}
+		})
+	}
+}
+
 func TestProcessor_RefundGas(t *testing.T) {
 	gasPrice := 5
 	gasLeft := 50
@@ -289,6 +387,7 @@ func TestProcessor_SetupGasBilling(t *testing.T) {
 		recipient       *tosca.Address
 		input           []byte
 		accessList      []tosca.AccessTuple
+		revision        tosca.Revision
 		expectedGasUsed tosca.Gas
 	}{
 		"creation": {
@@ -326,6 +425,24 @@ func TestProcessor_SetupGasBilling(t *testing.T) {
 			},
 			expectedGasUsed: TxGas + TxAccessListAddressGas + 3*TxAccessListStorageKeyGas,
 		},
+		"initCodeBeforeShanghai": {
+			recipient:       nil,
+			input:           make([]byte, 33),
+			revision:        tosca.R11_Paris,
+			expectedGasUsed: TxGasContractCreation + 33*TxDataZeroGasEIP2028,
+		},
+		"initCodeSinceShanghai": {
+			recipient:       nil,
+			input:           make([]byte, 33),
+			revision:        tosca.R12_Shanghai,
+			expectedGasUsed: TxGasContractCreation + 33*TxDataZeroGasEIP2028 + 2*InitCodeWordGas,
+		},
+		"callInputSinceShanghai": {
+			recipient:       &tosca.Address{1},
+			input:           make([]byte, 33),
+			revision:        tosca.R12_Shanghai,
+			expectedGasUsed: TxGas + 33*TxDataZeroGasEIP2028,
+		},
 	}
 
 	for name, test := range tests {
@@ -336,7 +453,7 @@ func TestProcessor_SetupGasBilling(t *testing.T) {
 				AccessList: test.accessList,
 			}
 
-			actualGasUsed := calculateSetupGas(transaction)
+			actualGasUsed := calculateSetupGas(transaction, test.revision)
 			if actualGasUsed != test.expectedGasUsed {
 				t.Errorf("setupGasBilling returned incorrect gas used, got: %d, want: %d", actualGasUsed, test.expectedGasUsed)
 			}
@@ -344,6 +461,73 @@ func TestProcessor_SetupGasBilling(t *testing.T) {
 	}
 }
 
+func TestProcessor_InitCodeCheck(t *testing.T) {
+	tests := map[string]struct {
+		recipient *tosca.Address
+		size      int
+		revision  tosca.Revision
+		config    Config
+		valid     bool
+	}{
+		"atLimit": {
+			size:     MaxInitCodeSize,
+			revision: tosca.R12_Shanghai,
+			valid:    true,
+		},
+		"exceedingLimit": {
+			size:     MaxInitCodeSize + 1,
+			revision: tosca.R12_Shanghai,
+			valid:    false,
+		},
+		"exceedingLimitBeforeShanghai": {
+			size:     MaxInitCodeSize + 1,
+			revision: tosca.R11_Paris,
+			valid:    true,
+		},
+		"exceedingLimitInCall": {
+			recipient: &tosca.Address{1},
+			size:      MaxInitCodeSize + 1,
+			revision:  tosca.R12_Shanghai,
+			valid:     true,
+		},
+		"exceedingConfiguredLimit": {
+			size:     101,
+			revision: tosca.R12_Shanghai,
+			config:   Config{MaxInitCodeSize: 100},
+			valid:    false,
+		},
+		"withinConfiguredLimit": {
+			size:     MaxInitCodeSize + 1,
+			revision: tosca.R12_Shanghai,
+			config:   Config{MaxInitCodeSize: 2 * MaxInitCodeSize},
+			valid:    true,
+		},
+		"limitDerivedFromConfiguredCodeSize": {
+			size:     MaxInitCodeSize + 1,
+			revision: tosca.R12_Shanghai,
+			config:   Config{MaxCodeSize: 2 * tosca.MaxCodeSize},
+			valid:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			processor := NewProcessor(nil, test.config).(*processor)
+			transaction := tosca.Transaction{
+				Recipient: test.recipient,
+				Input:     make([]byte, test.size),
+			}
+			err := initCodeCheck(transaction, test.revision, processor.config.MaxInitCodeSize)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Errorf("oversized init code was not rejected")
+			}
+		})
+	}
+}
+
 func TestProcessor_CallKind(t *testing.T) {
 	tests := map[string]struct {
 		recipient *tosca.Address
@@ -402,7 +586,7 @@ func TestProcessor_CallParameters(t *testing.T) {
 	}
 }
 
-func TestProcessor_SetUpAccessList(t *testing.T) {
+func TestProcessor_PrepareAccessList(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	context := tosca.NewMockTransactionContext(ctrl)
 
@@ -430,21 +614,273 @@ func TestProcessor_SetUpAccessList(t *testing.T) {
 	context.EXPECT().AccessStorage(accessListAddress, tosca.Key{1})
 	context.EXPECT().AccessStorage(accessListAddress, tosca.Key{2})
 
-	setUpAccessList(transaction, context, tosca.R09_Berlin)
+	PrepareAccessList(context, tosca.BlockParameters{Revision: tosca.R09_Berlin}, transaction)
 }
 
-func TestProcessor_AccessListIsNotCreatedIfTransactionHasNone(t *testing.T) {
+func TestProcessor_PrepareAccessListWarmsAccountsOfTransactionsWithoutAccessList(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	context := tosca.NewMockTransactionContext(ctrl)
-	// No calls to context
 
 	sender := tosca.Address{1}
 	recipient := tosca.Address{2}
 
+	for _, contract := range getPrecompiledAddresses(tosca.R09_Berlin) {
+		context.EXPECT().AccessAccount(contract)
+	}
+	context.EXPECT().AccessAccount(sender)
+	context.EXPECT().AccessAccount(recipient)
+
 	transaction := tosca.Transaction{
 		Sender:    sender,
 		Recipient: &recipient,
 	}
+	PrepareAccessList(context, tosca.BlockParameters{Revision: tosca.R09_Berlin}, transaction)
+}
+
+func TestProcessor_PrepareAccessListDoesNothingBeforeBerlin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	// No calls to context
+
+	recipient := tosca.Address{2}
+	transaction := tosca.Transaction{
+		Sender:     tosca.Address{1},
+		Recipient:  &recipient,
+		AccessList: []tosca.AccessTuple{{Address: tosca.Address{3}}},
+	}
+	PrepareAccessList(context, tosca.BlockParameters{Revision: tosca.R07_Istanbul}, transaction)
+}
+
+func TestProcessor_PrepareAccessListWarmsCoinbaseSinceShanghai(t *testing.T) {
+	coinbase := tosca.Address{0xc0}
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		if revision < tosca.R09_Berlin {
+			continue
+		}
+		t.Run(revision.String(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+
+			warmed := map[tosca.Address]bool{}
+			context.EXPECT().AccessAccount(gomock.Any()).DoAndReturn(func(address tosca.Address) tosca.AccessStatus {
+				warmed[address] = true
+				return tosca.ColdAccess
+			}).AnyTimes()
+
+			blockParameters := tosca.BlockParameters{Revision: revision, Coinbase: coinbase}
+			PrepareAccessList(context, blockParameters, tosca.Transaction{Sender: tosca.Address{1}})
+
+			if want, got := revision >= tosca.R12_Shanghai, warmed[coinbase]; want != got {
+				t.Errorf("unexpected warm status of coinbase, wanted %t, got %t", want, got)
+			}
+			for _, contract := range getPrecompiledAddresses(revision) {
+				if !warmed[contract] {
+					t.Errorf("precompiled contract %v was not warmed", contract)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessor_SimulateReportsAbortedExecution(t *testing.T) {
+	interrupt, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctrl := gomock.NewController(t)
+	transactionContext := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	transactionContext.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().GetCode(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+	transactionContext.EXPECT().CreateSnapshot().AnyTimes()
+	transactionContext.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+
+	processor := &processor{interpreter: interpreter}
+	result, err := processor.Simulate(
+		interrupt,
+		tosca.BlockParameters{},
+		tosca.Transaction{
+			Sender:    tosca.Address{1},
+			Recipient: &tosca.Address{2},
+			GasLimit:  100_000,
+		},
+		transactionContext,
+		tosca.SimulationOptions{NoBalanceCheck: true},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Aborted {
+		t.Errorf("simulation was not reported as aborted")
+	}
+}
+
+func TestProcessor_ResourceUsageIsReportedIfRequested(t *testing.T) {
+	for _, requested := range []bool{false, true} {
+		t.Run(fmt.Sprintf("requested=%t", requested), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			transactionContext := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+
+			transactionContext.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetCode(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+			transactionContext.EXPECT().CreateSnapshot().AnyTimes()
+			transactionContext.EXPECT().GetLogs().AnyTimes()
+			interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params tosca.Parameters) (tosca.Result, error) {
+				if got := params.ResourceUsage != nil; requested != got {
+					t.Errorf("unexpected presence of resource usage, wanted %t, got %t", requested, got)
+				}
+				if params.ResourceUsage != nil {
+					params.ResourceUsage.Update(params.Depth, 4, 96)
+				}
+				return tosca.Result{Success: true}, nil
+			})
+
+			processor := &processor{interpreter: interpreter}
+			result, err := processor.Simulate(
+				context.Background(),
+				tosca.BlockParameters{},
+				tosca.Transaction{
+					Sender:    tosca.Address{1},
+					Recipient: &tosca.Address{2},
+					GasLimit:  100_000,
+				},
+				transactionContext,
+				tosca.SimulationOptions{NoBalanceCheck: true, ResourceUsage: requested},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
 
-	setUpAccessList(transaction, context, tosca.R09_Berlin)
+			if !requested {
+				if result.ResourceUsage != nil {
+					t.Errorf("unexpected resource usage in receipt: %+v", result.ResourceUsage)
+				}
+				return
+			}
+			want := tosca.ResourceUsage{MaxCallDepth: 1, MaxStackHeight: 4, PeakMemory: 96}
+			if result.ResourceUsage == nil || want != *result.ResourceUsage {
+				t.Errorf("unexpected resource usage, wanted %+v, got %+v", want, result.ResourceUsage)
+			}
+		})
+	}
+}
+
+func TestProcessor_ContractAddressIsReportedForCreationTransactions(t *testing.T) {
+	sender := tosca.Address{1}
+	const nonce = 7
+
+	for _, success := range []bool{true, false} {
+		t.Run(fmt.Sprintf("success=%t", success), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			state := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+
+			nonces := map[tosca.Address]uint64{sender: nonce}
+			state.EXPECT().GetNonce(gomock.Any()).DoAndReturn(func(a tosca.Address) uint64 { return nonces[a] }).AnyTimes()
+			state.EXPECT().SetNonce(gomock.Any(), gomock.Any()).Do(func(a tosca.Address, n uint64) { nonces[a] = n }).AnyTimes()
+			state.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			state.EXPECT().CreateSnapshot().AnyTimes()
+			state.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+			state.EXPECT().SetCode(gomock.Any(), gomock.Any()).AnyTimes()
+			state.EXPECT().GetLogs().AnyTimes()
+			interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: success}, nil)
+
+			p := &processor{interpreter: interpreter, config: Config{MaxInitCodeSize: MaxInitCodeSize}}
+			receipt, err := p.Simulate(
+				context.Background(),
+				tosca.BlockParameters{},
+				tosca.Transaction{
+					Sender:   sender,
+					Nonce:    nonce,
+					Input:    []byte{0},
+					GasLimit: 100_000,
+				},
+				state,
+				tosca.SimulationOptions{NoBalanceCheck: true},
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := success, receipt.Success; want != got {
+				t.Errorf("unexpected success, wanted %t, got %t", want, got)
+			}
+			want := address.Create(sender, nonce)
+			if receipt.ContractAddress == nil || *receipt.ContractAddress != want {
+				t.Errorf("unexpected contract address, wanted %v, got %v", want, receipt.ContractAddress)
+			}
+		})
+	}
+}
+
+func TestProcessor_SkippedTransactionsAreLogged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	transactionContext := tosca.NewMockTransactionContext(ctrl)
+	transactionContext.EXPECT().GetNonce(tosca.Address{1}).Return(uint64(5))
+
+	var buffer bytes.Buffer
+	processor := NewProcessor(nil, Config{Logger: slog.New(slog.NewTextHandler(&buffer, nil))})
+	receipt, err := processor.Run(
+		context.Background(),
+		tosca.BlockParameters{},
+		tosca.Transaction{Sender: tosca.Address{1}, Nonce: 4},
+		transactionContext,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.Success {
+		t.Errorf("transaction should have been skipped")
+	}
+	got := buffer.String()
+	for _, want := range []string{"level=INFO", "transaction skipped", "nonce=4", "nonce mismatch"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log should contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestProcessor_InterpreterErrorsAreLogged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	transactionContext := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	transactionContext.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().GetCode(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+	transactionContext.EXPECT().CreateSnapshot().AnyTimes()
+	transactionContext.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().GetBalance(gomock.Any()).AnyTimes()
+	transactionContext.EXPECT().SetBalance(gomock.Any(), gomock.Any()).AnyTimes()
+	interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{}, fmt.Errorf("injected error"))
+
+	var buffer bytes.Buffer
+	processor := NewProcessor(interpreter, Config{Logger: slog.New(slog.NewTextHandler(&buffer, nil))})
+	_, err := processor.Run(
+		context.Background(),
+		tosca.BlockParameters{},
+		tosca.Transaction{
+			Sender:    tosca.Address{1},
+			Recipient: &tosca.Address{2},
+			GasLimit:  100_000,
+		},
+		transactionContext,
+	)
+	if err == nil {
+		t.Fatalf("interpreter error should be reported")
+	}
+	got := buffer.String()
+	for _, want := range []string{"level=WARN", "interpreter failed", "injected error"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log should contain %q, got %q", want, got)
+		}
+	}
 }
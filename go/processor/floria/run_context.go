@@ -11,12 +11,11 @@
 package floria
 
 import (
-	"fmt"
-
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/address"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
 
 	// geth dependencies
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -29,9 +28,46 @@ type runContext struct {
 	transactionParameters tosca.TransactionParameters
 	depth                 int
 	static                bool
+	maxCodeSize           int              // < zero selects the limit of EIP-170
+	touched               *touchedAccounts // < nil if touched accounts are not tracked
+}
+
+// callSnapshot captures the state of the transaction context and of the
+// touched accounts, such that both can be restored together.
+type callSnapshot struct {
+	state   tosca.Snapshot
+	touched int
+}
+
+func (r runContext) createSnapshot() callSnapshot {
+	return callSnapshot{
+		state:   r.CreateSnapshot(),
+		touched: r.touched.mark(),
+	}
+}
+
+func (r runContext) restoreSnapshot(snapshot callSnapshot) {
+	r.RestoreSnapshot(snapshot.state)
+	r.touched.restore(snapshot.touched)
+}
+
+func (r runContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	r.touched.touch(beneficiary)
+	return r.TransactionContext.SelfDestruct(address, beneficiary)
 }
 
 func (r runContext) Call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
+	tracer := r.transactionParameters.Tracer
+	if tracer == nil {
+		return r.call(kind, parameters)
+	}
+	tracer.OnEnter(r.depth, kind, parameters)
+	result, err := r.call(kind, parameters)
+	tracer.OnExit(r.depth, result, err)
+	return result, err
+}
+
+func (r runContext) call(kind tosca.CallKind, parameters tosca.CallParameters) (tosca.CallResult, error) {
 	if kind == tosca.Create || kind == tosca.Create2 {
 		return r.executeCreate(kind, parameters)
 	}
@@ -54,21 +90,29 @@ func (r runContext) executeCall(kind tosca.CallKind, parameters tosca.CallParame
 			return errResult, nil
 		}
 	}
-	snapshot := r.CreateSnapshot()
+	snapshot := r.createSnapshot()
 	recipient := parameters.Recipient
 
 	if kind == tosca.StaticCall {
 		r.static = true
 	}
 
-	if r.blockParameters.Revision >= tosca.R09_Berlin &&
+	// Calls without value to non-existing accounts are no-ops. Since EIP-158
+	// (https://eips.ethereum.org/EIPS/eip-158), which is active in all
+	// supported revisions, they do not create the target account.
+	if parameters.Value.Cmp(tosca.Value{}) == 0 &&
 		!isPrecompiled(recipient, r.blockParameters.Revision) &&
 		!isStateContract(recipient) &&
-		!r.AccountExists(recipient) &&
-		parameters.Value.Cmp(tosca.Value{}) == 0 {
+		!r.AccountExists(recipient) {
 		return tosca.CallResult{Success: true, GasLeft: parameters.Gas}, nil
 	}
 
+	// Like in geth, calls touch their target even if no value is transferred,
+	// such that empty targets get removed at the end of the transaction.
+	if kind == tosca.Call || kind == tosca.StaticCall {
+		r.touched.touch(recipient)
+	}
+
 	if kind == tosca.Call || kind == tosca.CallCode {
 		transferValue(r, parameters.Value, parameters.Sender, recipient)
 	}
@@ -78,7 +122,7 @@ func (r runContext) executeCall(kind tosca.CallKind, parameters tosca.CallParame
 			r, parameters.Sender, recipient, parameters.Input, parameters.Gas)
 		if isStatePrecompiled {
 			if !result.Success {
-				r.RestoreSnapshot(snapshot)
+				r.restoreSnapshot(snapshot)
 				result.GasLeft = 0
 			}
 			return result, nil
@@ -89,7 +133,7 @@ func (r runContext) executeCall(kind tosca.CallKind, parameters tosca.CallParame
 		r.blockParameters.Revision, parameters.Input, recipient, parameters.Gas)
 	if isPrecompiled {
 		if !result.Success {
-			r.RestoreSnapshot(snapshot)
+			r.restoreSnapshot(snapshot)
 			result.GasLeft = 0
 		}
 		return result, nil
@@ -121,9 +165,17 @@ func (r runContext) executeCall(kind tosca.CallKind, parameters tosca.CallParame
 		Code:                  code,
 	}
 
+	if err := r.interrupted(); err != nil {
+		r.restoreSnapshot(snapshot)
+		return errResult, err
+	}
+
 	callResult, err := r.interpreter.Run(interpreterParameters)
+	if err == nil {
+		tosca.FinalizeSelfDestruction(r, recipient, callResult.SelfDestruction)
+	}
 	if err != nil || !callResult.Success {
-		r.RestoreSnapshot(snapshot)
+		r.restoreSnapshot(snapshot)
 
 		if !isRevert(callResult, err) {
 			// if the unsuccessful call was due to a revert, the gas is not consumed
@@ -153,6 +205,7 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 	if !canTransferValue(r, parameters.Value, parameters.Sender, &parameters.Recipient) {
 		return errResult, nil
 	}
+	// EIP-2681: creators with a nonce of 2^64-1 fail without consuming gas.
 	if err := incrementNonce(r, parameters.Sender); err != nil {
 		return errResult, nil
 	}
@@ -163,16 +216,15 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 	createdAddress := createAddress(kind, parameters.Sender, r.GetNonce(parameters.Sender)-1,
 		parameters.Salt, codeHash)
 
-	if r.blockParameters.Revision >= tosca.R09_Berlin {
+	if revisions.IsActive(r.blockParameters.Revision, revisions.EIP2929) {
 		r.AccessAccount(createdAddress)
 	}
 
-	if r.GetNonce(createdAddress) != 0 ||
-		(r.GetCodeHash(createdAddress) != (tosca.Hash{}) &&
-			r.GetCodeHash(createdAddress) != emptyCodeHash) {
+	// EIP-684: creations into occupied addresses fail, consuming all gas.
+	if tosca.HasCreateCollision(r, createdAddress) {
 		return tosca.CallResult{}, nil
 	}
-	snapshot := r.CreateSnapshot()
+	snapshot := r.createSnapshot()
 	r.SetNonce(createdAddress, 1)
 
 	transferValue(r, parameters.Value, parameters.Sender, createdAddress)
@@ -191,11 +243,20 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 		Value:                 parameters.Value,
 		CodeHash:              &codeHash,
 		Code:                  code,
+		InitCode:              true,
+	}
+
+	if err := r.interrupted(); err != nil {
+		r.restoreSnapshot(snapshot)
+		return tosca.CallResult{}, err
 	}
 
 	result, err := r.interpreter.Run(interpreterParameters)
+	if err == nil {
+		tosca.FinalizeSelfDestruction(r, createdAddress, result.SelfDestruction)
+	}
 	if err != nil || !result.Success {
-		r.RestoreSnapshot(snapshot)
+		r.restoreSnapshot(snapshot)
 
 		if !isRevert(result, err) {
 			// if the unsuccessful create was due to a revert, the result is still returned
@@ -205,14 +266,8 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 	}
 
 	outCode := result.Output
-	if len(outCode) > maxCodeSize {
-		result.Success = false
-	}
-	if r.blockParameters.Revision >= tosca.R10_London && len(outCode) > 0 && outCode[0] == 0xEF {
-		result.Success = false
-	}
-	createGas := tosca.Gas(len(outCode) * createGasCostPerByte)
-	if result.GasLeft < createGas {
+	createGas, err := tosca.ValidateDeployedCode(r.blockParameters.Revision, tosca.Code(outCode), r.maxCodeSize)
+	if err != nil || result.GasLeft < createGas {
 		result.Success = false
 	}
 	result.GasLeft -= createGas
@@ -220,7 +275,7 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 	if result.Success {
 		r.SetCode(createdAddress, tosca.Code(outCode))
 	} else {
-		r.RestoreSnapshot(snapshot)
+		r.restoreSnapshot(snapshot)
 		result.GasLeft = 0
 		result.Output = nil
 	}
@@ -234,6 +289,15 @@ func (r runContext) executeCreate(kind tosca.CallKind, parameters tosca.CallPara
 	}, nil
 }
 
+// interrupted returns the error of the interrupt context of the current
+// transaction if it is done, nil otherwise.
+func (r runContext) interrupted() error {
+	if r.transactionParameters.Interrupt == nil {
+		return nil
+	}
+	return r.transactionParameters.Interrupt.Err()
+}
+
 func isRevert(result tosca.Result, err error) bool {
 	if err == nil && !result.Success && (result.GasLeft > 0 || len(result.Output) > 0) {
 		return true
@@ -253,9 +317,9 @@ func createAddress(
 	initHash tosca.Hash,
 ) tosca.Address {
 	if kind == tosca.Create {
-		return tosca.Address(crypto.CreateAddress(common.Address(sender), nonce))
+		return address.Create(sender, nonce)
 	}
-	return tosca.Address(crypto.CreateAddress2(common.Address(sender), common.Hash(salt), initHash[:]))
+	return address.Create2(sender, salt, initHash)
 }
 
 func canTransferValue(
@@ -289,7 +353,7 @@ func canTransferValue(
 func incrementNonce(context tosca.TransactionContext, address tosca.Address) error {
 	nonce := context.GetNonce(address)
 	if nonce+1 < nonce {
-		return fmt.Errorf("nonce overflow")
+		return ErrNonceOverflow
 	}
 	context.SetNonce(address, nonce+1)
 	return nil
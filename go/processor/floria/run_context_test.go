@@ -11,8 +11,10 @@
 package floria
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"math"
+	"slices"
 	"testing"
 
 	"github.com/Fantom-foundation/Tosca/go/tosca"
@@ -59,6 +61,8 @@ func TestCalls_InterpreterResultIsHandledCorrectly(t *testing.T) {
 		tosca.TransactionParameters{},
 		0,
 		false,
+		0,
+		nil,
 	}
 
 	params := tosca.CallParameters{
@@ -73,6 +77,7 @@ func TestCalls_InterpreterResultIsHandledCorrectly(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			context.EXPECT().GetCodeHash(params.Recipient).Return(tosca.Hash{})
 			context.EXPECT().GetCode(params.Recipient).Return([]byte{})
+			context.EXPECT().AccountExists(params.Recipient).Return(true)
 			context.EXPECT().CreateSnapshot()
 			context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
 
@@ -92,6 +97,113 @@ func TestCalls_InterpreterResultIsHandledCorrectly(t *testing.T) {
 	}
 }
 
+func TestCalls_ReportedSelfDestructionIsFinalized(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	runContext := runContext{
+		context,
+		interpreter,
+		tosca.BlockParameters{},
+		tosca.TransactionParameters{},
+		0,
+		false,
+		0,
+		&touchedAccounts{},
+	}
+
+	recipient, beneficiary := tosca.Address{2}, tosca.Address{3}
+	context.EXPECT().GetCodeHash(recipient).Return(tosca.Hash{})
+	context.EXPECT().GetCode(recipient).Return([]byte{})
+	context.EXPECT().AccountExists(recipient).Return(true)
+	context.EXPECT().CreateSnapshot()
+	interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{
+		Success:         true,
+		SelfDestruction: &tosca.SelfDestruction{Beneficiary: beneficiary},
+	}, nil)
+	context.EXPECT().SelfDestruct(recipient, beneficiary)
+
+	result, err := runContext.Call(tosca.Call, tosca.CallParameters{
+		Sender:    tosca.Address{1},
+		Recipient: recipient,
+		Gas:       1000,
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("unexpected result: %v, %v", result, err)
+	}
+	if !slices.Contains(runContext.touched.journal, beneficiary) {
+		t.Errorf("beneficiary was not touched, got %v", runContext.touched.journal)
+	}
+}
+
+func TestCalls_InterruptedTransactionDoesNotRunInterpreter(t *testing.T) {
+	interrupt, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctrl := gomock.NewController(t)
+	transactionContext := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	runContext := runContext{
+		transactionContext,
+		interpreter,
+		tosca.BlockParameters{},
+		tosca.TransactionParameters{Interrupt: interrupt},
+		0,
+		false,
+		0,
+		nil,
+	}
+
+	transactionContext.EXPECT().GetCodeHash(tosca.Address{2}).Return(tosca.Hash{})
+	transactionContext.EXPECT().GetCode(tosca.Address{2}).Return([]byte{})
+	transactionContext.EXPECT().AccountExists(tosca.Address{2}).Return(true)
+	transactionContext.EXPECT().CreateSnapshot().Return(tosca.Snapshot(1))
+	transactionContext.EXPECT().RestoreSnapshot(tosca.Snapshot(1))
+
+	_, err := runContext.Call(tosca.Call, tosca.CallParameters{
+		Sender:    tosca.Address{1},
+		Recipient: tosca.Address{2},
+		Gas:       1000,
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error, wanted %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestCall_ZeroValueCallsToNonExistingAccountsAreNoOpsInAllRevisions(t *testing.T) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		t.Run(revision.String(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+
+			recipient := tosca.Address{0x42}
+			context.EXPECT().CreateSnapshot()
+			context.EXPECT().AccountExists(recipient).Return(false)
+
+			runContext := runContext{
+				TransactionContext: context,
+				interpreter:        interpreter,
+				blockParameters:    tosca.BlockParameters{Revision: revision},
+			}
+			params := tosca.CallParameters{
+				Sender:    tosca.Address{1},
+				Recipient: recipient,
+				Gas:       1000,
+			}
+			result, err := runContext.Call(tosca.Call, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Success || result.GasLeft != params.Gas {
+				t.Errorf("unexpected result, wanted success with %d gas left, got %+v", params.Gas, result)
+			}
+		})
+	}
+}
+
 func TestCall_TransferValueInCall(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	context := tosca.NewMockTransactionContext(ctrl)
@@ -103,6 +215,8 @@ func TestCall_TransferValueInCall(t *testing.T) {
 		tosca.TransactionParameters{},
 		0,
 		false,
+		0,
+		nil,
 	}
 
 	params := tosca.CallParameters{
@@ -141,6 +255,8 @@ func TestCall_TransferValueInCreate(t *testing.T) {
 		tosca.TransactionParameters{},
 		0,
 		false,
+		0,
+		nil,
 	}
 
 	params := tosca.CallParameters{
@@ -178,6 +294,53 @@ func TestCall_TransferValueInCreate(t *testing.T) {
 	}
 }
 
+func TestRunContext_OnlyCreatesRunInitCode(t *testing.T) {
+	tests := map[tosca.CallKind]bool{
+		tosca.Call:         false,
+		tosca.StaticCall:   false,
+		tosca.CallCode:     false,
+		tosca.DelegateCall: false,
+		tosca.Create:       true,
+		tosca.Create2:      true,
+	}
+	for kind, initCode := range tests {
+		t.Run(kind.String(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+			runContext := runContext{
+				context,
+				interpreter,
+				tosca.BlockParameters{},
+				tosca.TransactionParameters{},
+				0,
+				false,
+				0,
+				nil,
+			}
+
+			context.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+			context.EXPECT().GetNonce(gomock.Any()).Return(uint64(0)).AnyTimes()
+			context.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+			context.EXPECT().GetCode(gomock.Any()).AnyTimes()
+			context.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			context.EXPECT().SetCode(gomock.Any(), gomock.Any()).AnyTimes()
+			context.EXPECT().CreateSnapshot().AnyTimes()
+
+			interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params tosca.Parameters) (tosca.Result, error) {
+				if want, got := initCode, params.InitCode; want != got {
+					t.Errorf("unexpected init code flag, wanted %t, got %t", want, got)
+				}
+				return tosca.Result{Success: true}, nil
+			})
+
+			if _, err := runContext.Call(kind, tosca.CallParameters{Recipient: tosca.Address{1}, Gas: 1000}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
 func TestTransferValue_InCallRestoreFailed(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	context := tosca.NewMockTransactionContext(ctrl)
@@ -189,6 +352,8 @@ func TestTransferValue_InCallRestoreFailed(t *testing.T) {
 		tosca.TransactionParameters{},
 		0,
 		false,
+		0,
+		nil,
 	}
 
 	params := tosca.CallParameters{
@@ -353,6 +518,100 @@ func TestCreateAddress(t *testing.T) {
 	}
 }
 
+func TestRunContext_CreateFailsWithoutConsumingGasOnNonceOverflow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+	runContext := runContext{
+		context,
+		interpreter,
+		tosca.BlockParameters{},
+		tosca.TransactionParameters{},
+		0,
+		false,
+		0,
+		nil,
+	}
+
+	params := tosca.CallParameters{
+		Sender: tosca.Address{1},
+		Gas:    1000,
+	}
+	// Neither the nonce is updated nor is the interpreter invoked.
+	context.EXPECT().GetNonce(params.Sender).Return(uint64(math.MaxUint64)).Times(2)
+
+	for _, kind := range []tosca.CallKind{tosca.Create, tosca.Create2} {
+		result, err := runContext.Call(kind, params)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Success {
+			t.Errorf("creation with overflowing nonce should fail")
+		}
+		if want, got := params.Gas, result.GasLeft; want != got {
+			t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+		}
+	}
+}
+
+func TestRunContext_CreateIntoOccupiedAddressFailsConsumingAllGas(t *testing.T) {
+	sender := tosca.Address{1}
+	salt := tosca.Hash{2}
+	initCode := tosca.Data{0} // < STOP
+	created := tosca.ComputeCreate2Address(sender, salt, hashCode(tosca.Code(initCode)))
+
+	tests := map[string]struct {
+		nonce    uint64
+		codeHash tosca.Hash
+	}{
+		"existing nonce": {nonce: 1, codeHash: emptyCodeHash},
+		"existing code":  {codeHash: tosca.Hash{1}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+			runContext := runContext{
+				context,
+				interpreter,
+				tosca.BlockParameters{},
+				tosca.TransactionParameters{},
+				0,
+				false,
+				0,
+				nil,
+			}
+
+			// The nonce of the sender is incremented, but the interpreter is
+			// not invoked.
+			gomock.InOrder(
+				context.EXPECT().GetNonce(sender).Return(uint64(0)),
+				context.EXPECT().SetNonce(sender, uint64(1)),
+				context.EXPECT().GetNonce(sender).Return(uint64(1)),
+			)
+			context.EXPECT().GetNonce(created).Return(test.nonce)
+			context.EXPECT().GetCodeHash(created).Return(test.codeHash).AnyTimes()
+
+			result, err := runContext.Call(tosca.Create2, tosca.CallParameters{
+				Sender: sender,
+				Input:  initCode,
+				Salt:   salt,
+				Gas:    1000,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Success {
+				t.Errorf("creation into occupied address should fail")
+			}
+			if want, got := tosca.Gas(0), result.GasLeft; want != got {
+				t.Errorf("unexpected gas left, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
 func TestIncrementNonce(t *testing.T) {
 	tests := map[string]struct {
 		nonce uint64
@@ -364,7 +623,7 @@ func TestIncrementNonce(t *testing.T) {
 		},
 		"max": {
 			nonce: math.MaxUint64,
-			err:   fmt.Errorf("nonce overflow"),
+			err:   ErrNonceOverflow,
 		},
 	}
 
@@ -376,8 +635,62 @@ func TestIncrementNonce(t *testing.T) {
 			context.EXPECT().SetNonce(gomock.Any(), test.nonce+1).AnyTimes()
 
 			err := incrementNonce(context, tosca.Address{})
-			if test.err != nil && err == nil {
-				t.Errorf("incrementNonce returned an unexpected error: %v", err)
+			if !errors.Is(err, test.err) {
+				t.Errorf("unexpected error, wanted %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestRunContext_CreateEnforcesConfiguredCodeSizeLimit(t *testing.T) {
+	tests := map[string]struct {
+		maxCodeSize int
+		codeSize    int
+		success     bool
+	}{
+		"default limit":           {0, tosca.MaxCodeSize, true},
+		"exceeding default limit": {0, tosca.MaxCodeSize + 1, false},
+		"raised limit":            {2 * tosca.MaxCodeSize, tosca.MaxCodeSize + 1, true},
+		"exceeding raised limit":  {2 * tosca.MaxCodeSize, 2*tosca.MaxCodeSize + 1, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+			runContext := runContext{
+				context,
+				interpreter,
+				tosca.BlockParameters{Revision: tosca.R13_Cancun},
+				tosca.TransactionParameters{},
+				0,
+				false,
+				test.maxCodeSize,
+				nil,
+			}
+
+			context.EXPECT().GetBalance(gomock.Any()).AnyTimes()
+			context.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+			context.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+			context.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			context.EXPECT().AccessAccount(gomock.Any()).AnyTimes()
+			context.EXPECT().CreateSnapshot().AnyTimes()
+			context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+			if test.success {
+				context.EXPECT().SetCode(gomock.Any(), gomock.Any())
+			}
+
+			code := make([]byte, test.codeSize)
+			gas := tosca.Gas(len(code)*tosca.CodeDepositGasPerByte + 1)
+			interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true, Output: code, GasLeft: gas}, nil)
+
+			result, err := runContext.Call(tosca.Create, tosca.CallParameters{Gas: gas})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := test.success, result.Success; want != got {
+				t.Errorf("unexpected success, wanted %t, got %t", want, got)
 			}
 		})
 	}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func (p *processor) ProcessSystemCalls(
+	ctx context.Context,
+	parameters tosca.SystemCallParameters,
+	context tosca.TransactionContext,
+) (tosca.SystemCallResult, error) {
+	calls := tosca.GetSystemCalls(parameters.Revision, parameters.Phase)
+	return p.runSystemCalls(ctx, parameters, context, calls)
+}
+
+// runSystemCalls conducts the given system calls in order. Calls to system
+// contracts not deployed are skipped unless they are required.
+func (p *processor) runSystemCalls(
+	ctx context.Context,
+	parameters tosca.SystemCallParameters,
+	context tosca.TransactionContext,
+	calls []tosca.SystemCall,
+) (tosca.SystemCallResult, error) {
+	runContext := runContext{
+		context,
+		p.interpreter,
+		parameters.BlockParameters,
+		tosca.TransactionParameters{
+			Origin:    tosca.SystemAddress,
+			Interrupt: ctx,
+		},
+		0,
+		false,
+		p.config.MaxCodeSize,
+		nil,
+	}
+
+	result := tosca.SystemCallResult{}
+	for _, call := range calls {
+		if len(context.GetCode(call.Address)) == 0 {
+			if call.Required {
+				return tosca.SystemCallResult{}, fmt.Errorf("system contract for %s is not deployed", call.Name)
+			}
+			continue
+		}
+
+		res, err := runContext.Call(tosca.Call, tosca.CallParameters{
+			Sender:    tosca.SystemAddress,
+			Recipient: call.Address,
+			Input:     call.Input(parameters),
+			Gas:       tosca.SystemCallGas,
+		})
+		if err != nil {
+			return tosca.SystemCallResult{}, err
+		}
+		if !res.Success {
+			if call.Required {
+				return tosca.SystemCallResult{}, fmt.Errorf("system call for %s failed", call.Name)
+			}
+			continue
+		}
+
+		// Following EIP-7685, requests without data are omitted.
+		if call.RequestType >= 0 && len(res.Output) > 0 {
+			request := append(tosca.Data{byte(call.RequestType)}, res.Output...)
+			result.Requests = append(result.Requests, request)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+// pragueSystemCalls lists the system calls of Prague, which is not yet a
+// supported revision of Tosca.
+func pragueSystemCalls(phase tosca.BlockPhase) []tosca.SystemCall {
+	return tosca.GetSystemCalls(tosca.R14_Prague, phase)
+}
+
+func TestProcessor_SupportsSystemCalls(t *testing.T) {
+	var _ tosca.SystemCallProcessor = &processor{}
+}
+
+func TestProcessor_NoSystemCallsBeforeCancun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+
+	p := &processor{}
+	for _, phase := range []tosca.BlockPhase{tosca.BlockStart, tosca.BlockEnd} {
+		parameters := tosca.SystemCallParameters{Phase: phase}
+		parameters.Revision = tosca.R12_Shanghai
+		result, err := p.ProcessSystemCalls(context.Background(), parameters, state)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Requests) != 0 {
+			t.Errorf("unexpected requests: %v", result.Requests)
+		}
+	}
+}
+
+func TestProcessor_BeaconRootIsStoredSinceCancun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	parameters := tosca.SystemCallParameters{
+		Phase:                 tosca.BlockStart,
+		ParentBeaconBlockRoot: tosca.Hash{1, 2, 3},
+	}
+	parameters.Revision = tosca.R13_Cancun
+
+	code := tosca.Code{byte(0)}
+	state.EXPECT().GetCode(tosca.BeaconRootsAddress).Return(code).AnyTimes()
+	state.EXPECT().GetCodeHash(tosca.BeaconRootsAddress).AnyTimes()
+	state.EXPECT().AccountExists(tosca.BeaconRootsAddress).Return(true)
+	state.EXPECT().CreateSnapshot()
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params tosca.Parameters) (tosca.Result, error) {
+		if want, got := tosca.SystemAddress, params.Sender; want != got {
+			t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+		}
+		if want, got := tosca.SystemAddress, params.Origin; want != got {
+			t.Errorf("unexpected origin, wanted %v, got %v", want, got)
+		}
+		if want, got := tosca.SystemCallGas, params.Gas; want != got {
+			t.Errorf("unexpected gas, wanted %d, got %d", want, got)
+		}
+		if want, got := parameters.ParentBeaconBlockRoot[:], params.Input; !bytes.Equal(want, got) {
+			t.Errorf("unexpected input, wanted %x, got %x", want, got)
+		}
+		return tosca.Result{Success: true}, nil
+	})
+
+	p := &processor{interpreter: interpreter}
+	if _, err := p.ProcessSystemCalls(context.Background(), parameters, state); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessor_OptionalSystemCallsAreSkippedIfContractIsMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	state.EXPECT().GetCode(tosca.BeaconRootsAddress).Return(nil)
+	state.EXPECT().GetCode(tosca.HistoryStorageAddress).Return(nil)
+
+	p := &processor{}
+	parameters := tosca.SystemCallParameters{Phase: tosca.BlockStart}
+	if _, err := p.runSystemCalls(context.Background(), parameters, state, pragueSystemCalls(tosca.BlockStart)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestProcessor_RequiredSystemCallsFailIfContractIsMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	state.EXPECT().GetCode(tosca.WithdrawalRequestsAddress).Return(nil)
+
+	p := &processor{}
+	parameters := tosca.SystemCallParameters{Phase: tosca.BlockEnd}
+	if _, err := p.runSystemCalls(context.Background(), parameters, state, pragueSystemCalls(tosca.BlockEnd)); err == nil {
+		t.Errorf("missing system contract was not reported")
+	}
+}
+
+func TestProcessor_RequiredSystemCallsFailIfCallFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	state.EXPECT().GetCode(tosca.WithdrawalRequestsAddress).Return(tosca.Code{0}).AnyTimes()
+	state.EXPECT().GetCodeHash(tosca.WithdrawalRequestsAddress).AnyTimes()
+	state.EXPECT().AccountExists(tosca.WithdrawalRequestsAddress).Return(true)
+	state.EXPECT().CreateSnapshot()
+	state.EXPECT().RestoreSnapshot(gomock.Any())
+	interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: false}, nil)
+
+	p := &processor{interpreter: interpreter}
+	parameters := tosca.SystemCallParameters{Phase: tosca.BlockEnd}
+	if _, err := p.runSystemCalls(context.Background(), parameters, state, pragueSystemCalls(tosca.BlockEnd)); err == nil {
+		t.Errorf("failed system call was not reported")
+	}
+}
+
+func TestProcessor_SystemCallsProduceRequests(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockTransactionContext(ctrl)
+	interpreter := tosca.NewMockInterpreter(ctrl)
+
+	state.EXPECT().GetCode(gomock.Any()).Return(tosca.Code{0}).AnyTimes()
+	state.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+	state.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+	state.EXPECT().CreateSnapshot().AnyTimes()
+	gomock.InOrder(
+		interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true, Output: tosca.Data{1, 2}}, nil),
+		interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true}, nil),
+	)
+
+	p := &processor{interpreter: interpreter}
+	parameters := tosca.SystemCallParameters{Phase: tosca.BlockEnd}
+	result, err := p.runSystemCalls(context.Background(), parameters, state, pragueSystemCalls(tosca.BlockEnd))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only the withdrawal requests are reported, since the consolidation
+	// requests are empty.
+	want := []tosca.Data{{0x01, 1, 2}}
+	if len(want) != len(result.Requests) || !bytes.Equal(want[0], result.Requests[0]) {
+		t.Errorf("unexpected requests, wanted %x, got %x", want, result.Requests)
+	}
+}
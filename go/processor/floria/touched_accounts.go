@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import "github.com/Fantom-foundation/Tosca/go/tosca"
+
+// ripemdAddress is the address of the RIPEMD-160 precompiled contract.
+var ripemdAddress = tosca.Address{19: 3}
+
+// touchedAccounts records the accounts touched by a transaction in a journal,
+// such that touches of reverted calls can be dropped again. Like in geth,
+// touches of the RIPEMD-160 precompiled contract are retained even if the
+// touching call is reverted, reproducing the resolution of a consensus issue
+// on Ethereum's mainnet in 2016.
+// Methods may be called on a nil pointer, in which case touches are ignored.
+type touchedAccounts struct {
+	journal       []tosca.Address
+	ripemdTouched bool // < true if RIPEMD-160 got touched by a reverted call
+}
+
+func (t *touchedAccounts) touch(address tosca.Address) {
+	if t == nil {
+		return
+	}
+	t.journal = append(t.journal, address)
+}
+
+// mark returns a position in the journal to which it can be restored.
+func (t *touchedAccounts) mark() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.journal)
+}
+
+// restore drops all touches recorded after the given mark was taken.
+func (t *touchedAccounts) restore(mark int) {
+	if t == nil || mark >= len(t.journal) {
+		return
+	}
+	for _, address := range t.journal[mark:] {
+		if address == ripemdAddress {
+			t.ripemdTouched = true
+		}
+	}
+	t.journal = t.journal[:mark]
+}
+
+// get returns the set of touched accounts.
+func (t *touchedAccounts) get() tosca.TouchedAccounts {
+	res := tosca.TouchedAccounts{}
+	if t == nil {
+		return res
+	}
+	for _, address := range t.journal {
+		res.Touch(address)
+	}
+	if t.ripemdTouched {
+		res.Touch(ripemdAddress)
+	}
+	return res
+}
+
+// removeDeadAccounts removes the touched accounts which are dead at the end
+// of a transaction (EIP-161). The removal is skipped if the given context does
+// not support it.
+func removeDeadAccounts(context tosca.TransactionContext, touched *touchedAccounts) {
+	if _, ok := context.(tosca.AccountRemover); !ok {
+		return
+	}
+	accounts := touched.get()
+	for _, address := range accounts.GetDeadAccounts(context) {
+		tosca.RemoveAccount(context, address)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTouchedAccounts_RestoreDropsTouchesAfterMark(t *testing.T) {
+	touched := &touchedAccounts{}
+	touched.touch(tosca.Address{1})
+	mark := touched.mark()
+	touched.touch(tosca.Address{2})
+	touched.restore(mark)
+
+	accounts := touched.get()
+	if !accounts.IsTouched(tosca.Address{1}) {
+		t.Errorf("touch before mark got lost")
+	}
+	if accounts.IsTouched(tosca.Address{2}) {
+		t.Errorf("touch after mark was not dropped")
+	}
+}
+
+func TestTouchedAccounts_TouchOfRipemdSurvivesRestore(t *testing.T) {
+	touched := &touchedAccounts{}
+	mark := touched.mark()
+	touched.touch(ripemdAddress)
+	touched.touch(tosca.Address{1})
+	touched.restore(mark)
+
+	accounts := touched.get()
+	if !accounts.IsTouched(ripemdAddress) {
+		t.Errorf("touch of RIPEMD-160 was dropped")
+	}
+	if accounts.IsTouched(tosca.Address{1}) {
+		t.Errorf("touch after mark was not dropped")
+	}
+}
+
+func TestTouchedAccounts_NilTrackerIgnoresTouches(t *testing.T) {
+	var touched *touchedAccounts
+	touched.touch(tosca.Address{1})
+	touched.restore(touched.mark())
+	accounts := touched.get()
+	if accounts.IsTouched(tosca.Address{1}) {
+		t.Errorf("nil tracker should not record touches")
+	}
+}
+
+type removingContext struct {
+	*tosca.MockTransactionContext
+	removed []tosca.Address
+}
+
+func (c *removingContext) RemoveAccount(address tosca.Address) {
+	c.removed = append(c.removed, address)
+}
+
+func TestRemoveDeadAccounts_RemovesTouchedEmptyAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	context.EXPECT().GetNonce(gomock.Any()).Return(uint64(0)).AnyTimes()
+	context.EXPECT().GetCodeSize(gomock.Any()).Return(0).AnyTimes()
+	context.EXPECT().GetBalance(tosca.Address{1}).Return(tosca.Value{}).AnyTimes()
+	context.EXPECT().GetBalance(tosca.Address{2}).Return(tosca.NewValue(1)).AnyTimes()
+
+	touched := &touchedAccounts{}
+	touched.touch(tosca.Address{1})
+	touched.touch(tosca.Address{2})
+
+	remover := &removingContext{MockTransactionContext: context}
+	removeDeadAccounts(remover, touched)
+	if want, got := []tosca.Address{{1}}, remover.removed; !slices.Equal(want, got) {
+		t.Errorf("unexpected removed accounts, wanted %v, got %v", want, got)
+	}
+}
+
+func TestRunContext_CallsTouchTheirTargetUnlessReverted(t *testing.T) {
+	tests := map[string]struct {
+		kind    tosca.CallKind
+		success bool
+		touched bool
+	}{
+		"call":               {kind: tosca.Call, success: true, touched: true},
+		"static call":        {kind: tosca.StaticCall, success: true, touched: true},
+		"delegate call":      {kind: tosca.DelegateCall, success: true, touched: false},
+		"call code":          {kind: tosca.CallCode, success: true, touched: false},
+		"failed call":        {kind: tosca.Call, success: false, touched: false},
+		"failed static call": {kind: tosca.StaticCall, success: false, touched: false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			context := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+
+			target := tosca.Address{0x42}
+			context.EXPECT().CreateSnapshot().AnyTimes()
+			context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+			context.EXPECT().AccountExists(target).Return(true).AnyTimes()
+			context.EXPECT().GetCode(gomock.Any()).AnyTimes()
+			context.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: test.success}, nil)
+
+			touched := &touchedAccounts{}
+			runContext := runContext{
+				TransactionContext: context,
+				interpreter:        interpreter,
+				touched:            touched,
+			}
+			_, err := runContext.Call(test.kind, tosca.CallParameters{
+				Sender:    tosca.Address{1},
+				Recipient: target,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			accounts := touched.get()
+			if want, got := test.touched, accounts.IsTouched(target); want != got {
+				t.Errorf("unexpected touch status of target, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestRunContext_SelfDestructTouchesBeneficiary(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := tosca.NewMockTransactionContext(ctrl)
+	context.EXPECT().SelfDestruct(tosca.Address{1}, tosca.Address{2}).Return(true)
+
+	touched := &touchedAccounts{}
+	runContext := runContext{TransactionContext: context, touched: touched}
+	if !runContext.SelfDestruct(tosca.Address{1}, tosca.Address{2}) {
+		t.Errorf("unexpected result of self-destruct")
+	}
+	accounts := touched.get()
+	if !accounts.IsTouched(tosca.Address{2}) {
+		t.Errorf("beneficiary was not touched")
+	}
+}
+
+func TestProcessor_TouchedEmptyAccountsAreRemovedWithAllSimulationOptions(t *testing.T) {
+	sender := tosca.Address{1}
+	empty := tosca.Address{2}
+	options := map[string]tosca.SimulationOptions{
+		"none":         {},
+		"log listener": {LogListener: func(tosca.LogEvent) {}},
+		"state override": {StateOverrides: tosca.StateOverrides{
+			sender: {StateDiff: map[tosca.Key]tosca.Word{{1}: {2}}},
+		}},
+	}
+
+	for name, options := range options {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			transactionContext := tosca.NewMockTransactionContext(ctrl)
+			interpreter := tosca.NewMockInterpreter(ctrl)
+
+			transactionContext.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetCode(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetCodeSize(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+			transactionContext.EXPECT().GetBalance(sender).Return(tosca.NewValue(1_000_000)).AnyTimes()
+			transactionContext.EXPECT().GetBalance(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().SetBalance(gomock.Any(), gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().CreateSnapshot().AnyTimes()
+			transactionContext.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+			transactionContext.EXPECT().GetLogs().AnyTimes()
+			interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true}, nil)
+
+			remover := &removingContext{MockTransactionContext: transactionContext}
+			processor := &processor{interpreter: interpreter}
+			result, err := processor.Simulate(
+				context.Background(),
+				tosca.BlockParameters{Revision: tosca.R07_Istanbul},
+				tosca.Transaction{
+					Sender:    sender,
+					Recipient: &empty,
+					GasLimit:  100_000,
+				},
+				remover,
+				options,
+			)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("transaction failed")
+			}
+			if !slices.Contains(remover.removed, empty) {
+				t.Errorf("touched empty account was not removed, removed %v", remover.removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// ErrIntrinsicGas is reported by ValidateTransaction if the gas limit of a
+// transaction does not cover its intrinsic gas costs.
+const ErrIntrinsicGas = tosca.ConstError("intrinsic gas too low")
+
+// ValidateTransaction checks whether the given transaction would be executed
+// by a processor using this configuration on top of the given state. It runs
+// the same checks as the processor, in the same order, such that transaction
+// pools admitting transactions based on it agree with the processor:
+//   - the nonce of the transaction must match the nonce of the sender; nonce
+//     gaps are reported through ErrNonceTooHigh, outdated transactions
+//     through ErrNonceTooLow,
+//   - the sender must be an externally owned account,
+//   - the init code of creations must not exceed the configured limit,
+//   - the balance of the sender must cover the maximum gas costs, which must
+//     not overflow, and
+//   - the gas limit must cover the intrinsic gas costs and, if enabled, the
+//     calldata floor of EIP-7623 (ErrIntrinsicGas).
+//
+// Except for the last, transactions failing those checks are skipped by the
+// processor. Transactions not covering their intrinsic gas costs are charged
+// for their full gas limit without being executed, which transaction pools
+// should avoid. Signatures are not covered since transactions are processed
+// with recovered senders; see the senders package for their validation.
+//
+// The state is only read.
+func (c Config) ValidateTransaction(
+	transaction tosca.Transaction,
+	state tosca.WorldState,
+	blockParameters tosca.BlockParameters,
+) error {
+	c = c.withDefaults()
+	if err := nonceCheck(transaction.Nonce, state.GetNonce(transaction.Sender)); err != nil {
+		return err
+	}
+	if err := eoaCheck(transaction.Sender, state); err != nil {
+		return err
+	}
+	if err := initCodeCheck(transaction, blockParameters.Revision, c.MaxInitCodeSize); err != nil {
+		return err
+	}
+	if _, _, err := balanceCheck(transaction, state); err != nil {
+		return err
+	}
+	if setupGas := calculateSetupGas(transaction, blockParameters.Revision); transaction.GasLimit < setupGas {
+		return fmt.Errorf("%w: %d < %d", ErrIntrinsicGas, transaction.GasLimit, setupGas)
+	}
+	if floorGas := calldataFloorGas(transaction, blockParameters.Revision); transaction.GasLimit < floorGas {
+		return fmt.Errorf("%w: %d < %d calldata floor", ErrIntrinsicGas, transaction.GasLimit, floorGas)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package floria
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConfig_ValidateTransaction(t *testing.T) {
+	sender := tosca.Address{1}
+	recipient := tosca.Address{2}
+	valid := tosca.Transaction{
+		Sender:    sender,
+		Recipient: &recipient,
+		Nonce:     5,
+		GasLimit:  TxGas,
+		GasPrice:  tosca.NewValue(2),
+	}
+
+	tests := map[string]struct {
+		modify  func(*tosca.Transaction)
+		balance tosca.Value
+		code    tosca.Hash
+		want    error
+		skipped bool
+	}{
+		"valid": {},
+		"nonce too low": {
+			modify:  func(tx *tosca.Transaction) { tx.Nonce = 4 },
+			want:    ErrNonceTooLow,
+			skipped: true,
+		},
+		"nonce gap": {
+			modify:  func(tx *tosca.Transaction) { tx.Nonce = 7 },
+			want:    ErrNonceTooHigh,
+			skipped: true,
+		},
+		"sender is contract": {
+			code:    tosca.Hash{1, 2, 3},
+			skipped: true,
+		},
+		"init code too large": {
+			modify: func(tx *tosca.Transaction) {
+				tx.Recipient = nil
+				tx.Input = make([]byte, MaxInitCodeSize+1)
+				tx.GasLimit = 10_000_000
+				tx.GasPrice = tosca.NewValue(0)
+			},
+			skipped: true,
+		},
+		"insufficient balance": {
+			balance: tosca.NewValue(2*TxGas - 1),
+			skipped: true,
+		},
+		"gas costs overflow": {
+			modify:  func(tx *tosca.Transaction) { tx.GasPrice = tosca.NewValue(1<<64-1, 1<<64-1, 1<<64-1, 1<<64-1) },
+			skipped: true,
+		},
+		"intrinsic gas not covered": {
+			modify: func(tx *tosca.Transaction) { tx.GasLimit = TxGas - 1 },
+			want:   ErrIntrinsicGas,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			transaction := valid
+			if test.modify != nil {
+				test.modify(&transaction)
+			}
+			balance := test.balance
+			if balance == (tosca.Value{}) {
+				balance = tosca.NewValue(1 << 40)
+			}
+
+			ctrl := gomock.NewController(t)
+			state := tosca.NewMockWorldState(ctrl)
+			state.EXPECT().GetNonce(sender).Return(uint64(5)).AnyTimes()
+			state.EXPECT().GetCodeHash(sender).Return(test.code).AnyTimes()
+			state.EXPECT().GetBalance(sender).Return(balance).AnyTimes()
+
+			blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+			err := Config{}.ValidateTransaction(transaction, state, blockParameters)
+
+			shouldFail := test.skipped || test.want != nil
+			if !shouldFail && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if shouldFail && err == nil {
+				t.Fatalf("invalid transaction was not rejected")
+			}
+			if test.want != nil && !errors.Is(err, test.want) {
+				t.Errorf("unexpected error, wanted %v, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestConfig_ValidateTransactionAgreesWithProcessor(t *testing.T) {
+	sender := tosca.Address{1}
+	recipient := tosca.Address{2}
+	blockParameters := tosca.BlockParameters{Revision: tosca.R13_Cancun}
+
+	for _, nonce := range []uint64{4, 5, 6} {
+		for _, balance := range []uint64{0, 2*TxGas - 1, 2 * TxGas} {
+			transaction := tosca.Transaction{
+				Sender:    sender,
+				Recipient: &recipient,
+				Nonce:     nonce,
+				GasLimit:  TxGas,
+				GasPrice:  tosca.NewValue(2),
+			}
+
+			ctrl := gomock.NewController(t)
+			txContext := tosca.NewMockTransactionContext(ctrl)
+			txContext.EXPECT().GetNonce(sender).Return(uint64(5)).AnyTimes()
+			txContext.EXPECT().GetCodeHash(sender).Return(tosca.Hash{}).AnyTimes()
+			txContext.EXPECT().GetBalance(sender).Return(tosca.NewValue(balance)).AnyTimes()
+
+			validationErr := Config{}.ValidateTransaction(transaction, txContext, blockParameters)
+
+			// Executed transactions are detected by the purchase of gas.
+			executed := false
+			txContext.EXPECT().SetBalance(gomock.Any(), gomock.Any()).Do(func(tosca.Address, tosca.Value) {
+				executed = true
+			}).AnyTimes()
+			txContext.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+			txContext.EXPECT().AccessAccount(gomock.Any()).AnyTimes()
+			txContext.EXPECT().CreateSnapshot().AnyTimes()
+			txContext.EXPECT().GetCodeHash(gomock.Any()).AnyTimes()
+			txContext.EXPECT().GetCode(gomock.Any()).AnyTimes()
+			txContext.EXPECT().AccountExists(gomock.Any()).Return(true).AnyTimes()
+			txContext.EXPECT().GetBalance(gomock.Any()).AnyTimes()
+			txContext.EXPECT().GetLogs().AnyTimes()
+
+			interpreter := tosca.NewMockInterpreter(ctrl)
+			interpreter.EXPECT().Run(gomock.Any()).Return(tosca.Result{Success: true}, nil).AnyTimes()
+
+			processor := NewProcessor(interpreter, Config{})
+			if _, err := processor.Run(context.Background(), blockParameters, transaction, txContext); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if want, got := validationErr == nil, executed; want != got {
+				t.Errorf("disagreement for nonce %d and balance %d, valid %t, executed %t", nonce, balance, want, got)
+			}
+		}
+	}
+}
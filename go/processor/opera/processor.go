@@ -17,8 +17,10 @@ package geth
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/big"
 	"strings"
@@ -26,6 +28,7 @@ import (
 	"github.com/Fantom-foundation/Tosca/go/geth_adapter"
 	geth_interpreter "github.com/Fantom-foundation/Tosca/go/interpreter/geth"
 	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/revisions"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/tracing"
@@ -37,16 +40,63 @@ import (
 )
 
 func init() {
-	tosca.RegisterProcessorFactory("geth", newProcessor)
-	tosca.RegisterProcessorFactory("opera", newProcessor)
+	tosca.RegisterProcessorFactory("geth", newProcessor, metadata)
+	tosca.RegisterProcessorFactory("opera", newProcessor, metadata)
+}
+
+// metadata describes the processors registered by this package.
+var metadata = tosca.ImplementationMetadata{
+	Language:       "Go",
+	OldestRevision: tosca.R07_Istanbul,
+	NewestRevision: tosca.R13_Cancun,
+}
+
+// Config contains the configuration options of the opera processor.
+type Config struct {
+	// RefundPolicy, if set, caps the gas refunds of executed transactions.
+	// If nil, the caps defined by Ethereum are applied.
+	RefundPolicy tosca.RefundPolicy
+	// Metrics, if set, receives the number, duration, gas usage, and
+	// failures of executed transactions.
+	Metrics tosca.Metrics
+	// Profiler, if set, records the wall-clock time spent on executed
+	// transactions and contracts, enabling the identification of contracts
+	// with an unusually high time per gas ratio.
+	Profiler *tosca.ExecutionProfiler
+	// Logger, if set, receives warnings on interpreter errors, which are
+	// otherwise only reported as failed calls by the EVM.
+	Logger *slog.Logger
+}
+
+// NewProcessor creates an opera processor using the given interpreter and
+// configuration. Processors created through the processor registry use the
+// default configuration.
+func NewProcessor(interpreter tosca.Interpreter, config Config) tosca.Processor {
+	if config.Profiler != nil {
+		interpreter = tosca.NewProfilingInterpreter(interpreter, config.Profiler)
+	}
+	if config.Logger != nil {
+		interpreter = tosca.NewLoggingInterpreter(interpreter, config.Logger)
+	}
+	processor := &processor{
+		interpreter:      geth_adapter.NewGethInterpreterFactory(interpreter),
+		toscaInterpreter: interpreter,
+		config:           config,
+	}
+	var res tosca.Processor = processor
+	if config.Profiler != nil {
+		res = tosca.NewProfilingProcessor(res, config.Profiler)
+	}
+	if config.Metrics != nil {
+		return tosca.NewMeasuredProcessor(res, config.Metrics, "opera")
+	}
+	return res
 }
 
 // newProcessor is a factory function for the geth/opera processor implemented in this file.
 // By including this package, it gets registered in the global processor registry.
 func newProcessor(interpreter tosca.Interpreter) tosca.Processor {
-	return &processor{
-		interpreter: geth_adapter.NewGethInterpreterFactory(interpreter),
-	}
+	return NewProcessor(interpreter, Config{})
 }
 
 var (
@@ -58,6 +108,10 @@ var (
 	// next one expected based on the local chain.
 	errNonceTooHigh = errors.New("nonce too high")
 
+	// errNonceMax is returned if the nonce of a transaction sender account has
+	// maximum allowed value and would become invalid if incremented.
+	errNonceMax = errors.New("nonce has max value")
+
 	// errInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	errInsufficientFunds = errors.New("insufficient funds for gas * price + value")
@@ -70,39 +124,114 @@ var (
 	// than required to start the invocation.
 	errIntrinsicGas = errors.New("intrinsic gas too low")
 
+	// errFloorDataGas is returned if the transaction is specified to use less
+	// gas than required by the calldata floor of EIP-7623.
+	errFloorDataGas = errors.New("insufficient gas for floor data gas cost")
+
 	// errSenderNoEOA is returned if the sender of a transaction is a contract.
 	errSenderNoEOA = errors.New("sender not an eoa")
+
+	// errMaxInitCodeSizeExceeded is returned if creation transaction provides the init code bigger
+	// than init code size limit.
+	errMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
 )
 
+// processor mirrors Sonic's state transition, which covers the processing of
+// individual transactions only. Block-level processing, including the system
+// calls of tosca.SystemCallProcessor, is outside of its scope and thus not
+// supported by this processor; floria is the reference for system calls.
 type processor struct {
-	interpreter geth.InterpreterFactory
+	interpreter      geth.InterpreterFactory
+	toscaInterpreter tosca.Interpreter
+	config           Config
 }
 
 func (p *processor) Run(
+	ctx context.Context,
 	blockParams tosca.BlockParameters,
 	transaction tosca.Transaction,
-	context tosca.TransactionContext,
+	txContext tosca.TransactionContext,
 ) (tosca.Receipt, error) {
+	return p.run(ctx, blockParams, transaction, txContext, tosca.SimulationOptions{})
+}
+
+func (p *processor) Simulate(
+	ctx context.Context,
+	blockParams tosca.BlockParameters,
+	transaction tosca.Transaction,
+	txContext tosca.TransactionContext,
+	options tosca.SimulationOptions,
+) (tosca.SimulationResult, error) {
+	return tosca.SimulateWithinLimits(ctx, options, func(ctx context.Context, options tosca.SimulationOptions) (tosca.SimulationResult, error) {
+		receipt, err := p.run(ctx, blockParams, transaction, txContext, options)
+		if err != nil && errors.Is(err, ctx.Err()) {
+			return tosca.SimulationResult{Aborted: true}, nil
+		}
+		return tosca.SimulationResult{Receipt: receipt}, err
+	})
+}
+
+func (p *processor) run(
+	ctx context.Context,
+	blockParams tosca.BlockParameters,
+	transaction tosca.Transaction,
+	txContext tosca.TransactionContext,
+	options tosca.SimulationOptions,
+) (receipt tosca.Receipt, err error) {
+	options.Apply(&blockParams, &transaction)
+	if options.UnlimitedGas {
+		options.NoBalanceCheck = true
+	}
+	txContext, err = options.StateOverrides.Apply(txContext)
+	if err != nil {
+		return tosca.Receipt{}, err
+	}
+
+	var statistics *tosca.AccessStatistics
+	if options.AccessStatistics {
+		statistics = &tosca.AccessStatistics{}
+		txContext = tosca.NewAccessStatisticsTransactionContext(txContext, statistics)
+	}
+
+	interpreter := p.interpreter
+	var tracerHooks *tracing.Hooks
+	if listener := options.LogListener; listener != nil {
+		txContext = tosca.NewLogStreamingTransactionContext(txContext, listener)
+	}
+
+	if tracer := options.Tracer; tracer != nil {
+		txContext = tosca.NewTracingTransactionContext(txContext, tracer)
+		interpreter = geth_adapter.NewGethInterpreterFactoryWithTracer(p.toscaInterpreter, tracer)
+		tracerHooks = geth_interpreter.NewTracerHooks(tracer, 0, tosca.Address{})
+		tracer.OnTxStart(blockParams, transaction)
+		defer func() { tracer.OnTxEnd(receipt, err) }()
+	}
 
 	// --- setup ---
 
 	// Hashing function used in the context for BLOCKHASH instruction
 	getHash := func(num uint64) common.Hash {
-		return common.Hash(context.GetBlockHash(int64(num)))
+		return common.Hash(txContext.GetBlockHash(int64(num)))
 	}
 
+	stateDb := geth_interpreter.NewStateDbAdapter(txContext)
+
 	// Intercept the transfer function to conduct the transfer on the actual state.
 	transferFunc := func(_ geth.StateDB, from common.Address, to common.Address, amount *uint256.Int) {
+		// Like in geth, transfers touch both accounts even if no value is
+		// transferred, such that empty accounts get removed (EIP-161).
+		stateDb.Touch(from)
+		stateDb.Touch(to)
 		if amount.Sign() != 1 || from == to {
 			return
 		}
 		a := tosca.Address(from)
 		b := tosca.Address(to)
 		d := tosca.ValueFromUint256(amount)
-		curA := context.GetBalance(a)
-		curB := context.GetBalance(b)
-		context.SetBalance(a, tosca.Sub(curA, d))
-		context.SetBalance(b, tosca.Add(curB, d))
+		curA := txContext.GetBalance(a)
+		curB := txContext.GetBalance(b)
+		txContext.SetBalance(a, tosca.Sub(curA, d))
+		txContext.SetBalance(b, tosca.Add(curB, d))
 	}
 
 	// Create empty block context based on block number
@@ -126,7 +255,8 @@ func (p *processor) Run(
 
 	// Create a configuration for the geth EVM.
 	config := geth.Config{
-		Interpreter: p.interpreter,
+		Interpreter: interpreter,
+		Tracer:      tracerHooks,
 		StatePrecompiles: map[common.Address]geth.PrecompiledStateContract{
 			stateContractAddress: preCompiledStateContract{},
 		},
@@ -143,19 +273,22 @@ func (p *processor) Run(
 	chainConfig.BerlinBlock = big.NewInt(0)
 	chainConfig.LondonBlock = big.NewInt(0)
 
-	if blockParams.Revision < tosca.R10_London {
+	if !revisions.IsActive(blockParams.Revision, revisions.EIP1559) {
 		chainConfig.LondonBlock = big.NewInt(blockParams.BlockNumber + 1)
 	}
-	if blockParams.Revision < tosca.R09_Berlin {
+	if !revisions.IsActive(blockParams.Revision, revisions.EIP2929) {
 		chainConfig.BerlinBlock = big.NewInt(blockParams.BlockNumber + 1)
 	}
-	if blockParams.Revision < tosca.R07_Istanbul {
+	if !revisions.IsActive(blockParams.Revision, revisions.EIP1884) {
 		chainConfig.IstanbulBlock = big.NewInt(blockParams.BlockNumber + 1)
 	}
 
-	stateDb := geth_interpreter.NewStateDbAdapter(context)
 	evm := geth.NewEVM(blockCtx, txCtx, stateDb, &chainConfig, config)
 
+	// Abort the execution of the EVM once the context is done.
+	stopInterrupt := context.AfterFunc(ctx, evm.Cancel)
+	defer stopInterrupt()
+
 	// -- start of execution --
 
 	// This code is required to mimic the behavior of Sonic's
@@ -177,17 +310,24 @@ func (p *processor) Run(
 	gas := transaction.GasLimit
 
 	// Check clauses 1-3, buy gas if everything is correct
-	if err := preCheck(transaction, context); err != nil {
+	if err := preCheck(transaction, blockParams.Revision, txContext, !options.NoBalanceCheck); err != nil {
 		return tosca.Receipt{}, err
 	}
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	intrinsicGasCosts, err := IntrinsicGas(transaction)
+	intrinsicGasCosts, err := IntrinsicGas(transaction, revisions.IsActive(blockParams.Revision, revisions.EIP3860))
 	if err != nil {
 		return tosca.Receipt{}, err
 	}
 	if gas < intrinsicGasCosts {
 		return tosca.Receipt{GasUsed: transaction.GasLimit}, fmt.Errorf("%w: have %d, want %d", errIntrinsicGas, transaction.GasLimit, intrinsicGasCosts)
 	}
+	var floorGas tosca.Gas
+	if revisions.IsActive(blockParams.Revision, revisions.EIP7623) {
+		floorGas = tosca.CalldataFloorGas(transaction.Input)
+	}
+	if gas < floorGas {
+		return tosca.Receipt{GasUsed: transaction.GasLimit}, fmt.Errorf("%w: have %d, want %d", errFloorDataGas, transaction.GasLimit, floorGas)
+	}
 	gas -= intrinsicGasCosts
 
 	sender := geth.AccountRef(transaction.Sender)
@@ -241,34 +381,47 @@ func (p *processor) Run(
 		output, gasLeft, vmError = evm.Call(sender, common.Address(*transaction.Recipient), transaction.Input, uint64(gas), transaction.Value.ToUint256())
 	}
 
+	if err := ctx.Err(); err != nil {
+		return tosca.Receipt{}, err
+	}
+
+	executionGas := uint64(gas) - gasLeft
+
 	// For whatever reason, 10% of remaining gas is charged for non-internal transactions.
-	if !isInternal(transaction) {
-		gasLeft = gasLeft - gasLeft/10
+	penalty := uint64(0)
+	if !isInternal(transaction) && !options.UnlimitedGas {
+		penalty = gasLeft / 10
+		gasLeft = gasLeft - penalty
 	}
 
 	// Add refund to the remaining gas.
+	refund := uint64(0)
 	if vmError == nil {
-		refund := stateDb.GetRefund()
-
-		maxRefund := uint64(0)
-		gasUsed := uint64(transaction.GasLimit) - gasLeft
-		if blockParams.Revision < tosca.R10_London {
-			// Before EIP-3529: refunds were capped to gasUsed / 2
-			maxRefund = gasUsed / 2
-		} else {
-			// After EIP-3529: refunds are capped to gasUsed / 5
-			maxRefund = gasUsed / 5
-		}
-
-		if refund > maxRefund {
-			refund = maxRefund
-		}
-
+		gasUsed := transaction.GasLimit - tosca.Gas(gasLeft)
+		refund = uint64(revisions.CapRefund(
+			p.config.RefundPolicy, blockParams.Revision,
+			gasUsed, tosca.Gas(stateDb.GetRefund()),
+		))
 		gasLeft += refund
 	}
 
+	// Charge at least the calldata floor (EIP-7623).
+	surcharge := uint64(0)
+	if used := uint64(transaction.GasLimit) - gasLeft; used < uint64(floorGas) {
+		surcharge = uint64(floorGas) - used
+		gasLeft -= surcharge
+	}
+
 	// refund remaining gas
-	refundGas(transaction, tosca.Gas(gasLeft), context)
+	if !options.NoBalanceCheck {
+		refundGas(transaction, tosca.Gas(gasLeft), txContext)
+	}
+
+	// Remove touched accounts that are empty at the end of the transaction.
+	touched := stateDb.GetTouchedAccounts()
+	for _, address := range touched.GetDeadAccounts(txContext) {
+		tosca.RemoveAccount(txContext, address)
+	}
 
 	// Extract log messages.
 	logs := make([]tosca.Log, 0)
@@ -284,12 +437,27 @@ func (p *processor) Run(
 		})
 	}
 
+	var breakdown *tosca.GasBreakdown
+	if options.GasBreakdown {
+		breakdown = &tosca.GasBreakdown{
+			IntrinsicGas:     intrinsicGasCosts,
+			ExecutionGas:     tosca.Gas(executionGas),
+			UnusedGasPenalty: tosca.Gas(penalty),
+			Refund:           tosca.Gas(refund),
+			FloorSurcharge:   tosca.Gas(surcharge),
+			Returned:         tosca.Gas(gasLeft),
+		}
+	}
+
 	return tosca.Receipt{
-		Success:         vmError == nil,
-		GasUsed:         transaction.GasLimit - tosca.Gas(gasLeft),
-		ContractAddress: createdContract,
-		Output:          output,
-		Logs:            logs,
+		Success:          vmError == nil,
+		GasUsed:          transaction.GasLimit - tosca.Gas(gasLeft),
+		CalldataFloorGas: floorGas,
+		ContractAddress:  createdContract,
+		Output:           output,
+		Logs:             logs,
+		GasBreakdown:     breakdown,
+		AccessStatistics: statistics,
 	}, nil
 }
 
@@ -303,7 +471,7 @@ func keccak(data []byte) tosca.Hash {
 	return res
 }
 
-func preCheck(transaction tosca.Transaction, state tosca.WorldState) error {
+func preCheck(transaction tosca.Transaction, revision tosca.Revision, state tosca.WorldState, chargeGas bool) error {
 	// Only check transactions that are not fake
 	// TODO: add support for non-checked transactions
 
@@ -317,14 +485,24 @@ func preCheck(transaction tosca.Transaction, state tosca.WorldState) error {
 		//skippedTxsNonceTooLowMeter.Mark(1)
 		return fmt.Errorf("%w: address %v, tx: %d state: %d", errNonceTooLow,
 			transaction.Sender, msgNonce, stNonce)
+	} else if stNonce+1 < stNonce {
+		return fmt.Errorf("%w: address %v, nonce: %d", errNonceMax,
+			transaction.Sender, stNonce)
 	}
 	// Make sure the sender is an EOA (Externally Owned Account)
 	if codeHash := state.GetCodeHash(transaction.Sender); codeHash != emptyCodeHash && codeHash != (tosca.Hash{}) {
 		return fmt.Errorf("%w: address %v, codehash: %s", errSenderNoEOA,
 			transaction.Sender, codeHash)
 	}
+	// Check whether the init code size has been exceeded.
+	if revisions.IsActive(revision, revisions.EIP3860) && transaction.Recipient == nil && len(transaction.Input) > params.MaxInitCodeSize {
+		return fmt.Errorf("%w: code size %v limit %v", errMaxInitCodeSizeExceeded, len(transaction.Input), params.MaxInitCodeSize)
+	}
 
 	// Note: Opera doesn't need to check gasFeeCap >= BaseFee, because it's already checked by epochcheck
+	if !chargeGas {
+		return nil
+	}
 	return buyGas(transaction, state)
 }
 
@@ -373,7 +551,7 @@ func refundGas(tx tosca.Transaction, gasLeft tosca.Gas, state tosca.WorldState)
 }
 
 // IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(transaction tosca.Transaction) (tosca.Gas, error) {
+func IntrinsicGas(transaction tosca.Transaction, isEIP3860 bool) (tosca.Gas, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if transaction.Recipient == nil {
@@ -401,6 +579,14 @@ func IntrinsicGas(transaction tosca.Transaction) (tosca.Gas, error) {
 			return transaction.GasLimit, errGasUintOverflow
 		}
 		gas += z * params.TxDataZeroGas
+
+		if transaction.Recipient == nil && isEIP3860 {
+			lenWords := tosca.SizeInWords(uint64(len(transaction.Input)))
+			if (math.MaxUint64-gas)/params.InitCodeWordGas < lenWords {
+				return transaction.GasLimit, errGasUintOverflow
+			}
+			gas += lenWords * params.InitCodeWordGas
+		}
 	}
 	accessList := transaction.AccessList
 	if accessList != nil {
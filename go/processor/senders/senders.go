@@ -0,0 +1,255 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package senders provides the concurrent recovery of the senders of signed
+// transactions. Since the recovery of ECDSA signatures is a significant share
+// of the costs of processing a block, it is intended to be conducted for all
+// transactions of a block ahead of their sequential execution by a processor.
+//
+// Recovered signers are retained in a cache shared with the ECRECOVER
+// precompiled contract, see package ecrecover. The cache is indexed by the
+// signing hash and the signature of transactions. Since the signing hash
+// covers the chain ID of replay-protected transactions, a sender recovered
+// for one chain is never reported for a transaction checked for another.
+//
+// The signers of EIP-7702 authorizations are recovered through the same pool
+// of workers and cache. Since the set-code transaction type is not supported
+// by the go-ethereum version in use, they are taken from transactions decoded
+// by package rlp.
+package senders
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/ecrecover"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/rlp"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Config contains the configuration options of a Recoverer.
+type Config struct {
+	// Workers is the maximum number of signatures recovered concurrently. If
+	// set to 0, the number of available CPUs is used.
+	Workers int
+	// Signatures is the cache of recovered signers. If nil, the cache shared
+	// with the ECRECOVER precompiled contract is used.
+	Signatures *ecrecover.Cache
+}
+
+// Recoverer recovers the senders of signed transactions using a bounded pool
+// of workers. Recovered senders are cached, such that transactions already
+// seen, for instance while validating them for a transaction pool, do not
+// need to be recovered again when being processed as part of a block. Only
+// the senders of transactions checked by the latest signer of a chain are
+// cached, see getSignature. Recoverers are safe for concurrent use.
+type Recoverer struct {
+	workers    int
+	signatures *ecrecover.Cache
+}
+
+// NewRecoverer creates a new Recoverer with the given configuration.
+func NewRecoverer(config Config) (*Recoverer, error) {
+	if config.Workers < 0 {
+		return nil, fmt.Errorf("invalid number of workers: %d", config.Workers)
+	}
+	if config.Workers == 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	if config.Signatures == nil {
+		config.Signatures = ecrecover.GetSharedCache()
+	}
+	return &Recoverer{
+		workers:    config.Workers,
+		signatures: config.Signatures,
+	}, nil
+}
+
+// Recover recovers the senders of the given transactions using the given
+// signer. The resulting senders are listed in the order of the transactions.
+// If the sender of any transaction can not be recovered, an error is returned
+// identifying the first of those transactions. The recovery may be aborted
+// through the given context, in which case the context's error is returned.
+func (r *Recoverer) Recover(
+	ctx context.Context,
+	signer types.Signer,
+	transactions []*types.Transaction,
+) ([]tosca.Address, error) {
+	senders := make([]tosca.Address, len(transactions))
+	errs := make([]error, len(transactions))
+	err := r.forEach(ctx, len(transactions), func(i int) {
+		sender, err := r.recoverSender(signer, transactions[i])
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to recover sender of transaction %d: %w", i, err)
+			return
+		}
+		senders[i] = sender
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return senders, nil
+}
+
+// Authority is the result of the recovery of the signer of an EIP-7702
+// authorization.
+type Authority struct {
+	Address tosca.Address
+	// Err is set if the signer could not be recovered, in which case the
+	// authorization is to be skipped by the execution of its transaction.
+	Err error
+}
+
+// RecoverAuthorities recovers the signers of the authorizations of the given
+// set-code transactions, as decoded by package rlp. The authorizations of all
+// transactions are recovered concurrently and the results are listed per
+// transaction in the order of its authorization list. Since invalid
+// authorizations do not invalidate their transaction, failed recoveries are
+// reported through the Err field of the respective result. The recovery may
+// be aborted through the given context, in which case the context's error is
+// returned.
+func (r *Recoverer) RecoverAuthorities(
+	ctx context.Context,
+	transactions []rlp.SignedTransaction,
+) ([][]Authority, error) {
+	type job struct{ transaction, authorization int }
+	var jobs []job
+	authorities := make([][]Authority, len(transactions))
+	for i := range transactions {
+		list := transactions[i].AuthorizationList
+		if len(list) == 0 {
+			continue
+		}
+		authorities[i] = make([]Authority, len(list))
+		for j := range list {
+			jobs = append(jobs, job{i, j})
+		}
+	}
+
+	err := r.forEach(ctx, len(jobs), func(i int) {
+		job := jobs[i]
+		authorization := &transactions[job.transaction].AuthorizationList[job.authorization]
+		address, err := r.recoverAuthority(authorization)
+		authorities[job.transaction][job.authorization] = Authority{Address: address, Err: err}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return authorities, nil
+}
+
+// forEach runs the given function for all indices in [0, count) using the
+// workers of the recoverer. The processing of pending indices is stopped
+// once the given context is done, in which case the context's error is
+// returned.
+func (r *Recoverer) forEach(ctx context.Context, count int, run func(int)) error {
+	pending := make(chan int, count)
+	for i := range count {
+		pending <- i
+	}
+	close(pending)
+
+	var wg sync.WaitGroup
+	for range min(r.workers, count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				if ctx.Err() != nil {
+					return
+				}
+				run(i)
+			}
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// recoverSender recovers the sender of a single transaction, consulting the cache of
+// recovered signers if the signature can be validated locally.
+func (r *Recoverer) recoverSender(signer types.Signer, tx *types.Transaction) (tosca.Address, error) {
+	signature, ok := getSignature(signer, tx)
+	if !ok {
+		sender, err := types.Sender(signer, tx)
+		return tosca.Address(sender), err
+	}
+	return r.signatures.Recover(signature)
+}
+
+// getSignature extracts the signature of the given transaction, applying the
+// validation of the given signer. Since the signer's rules are replicated,
+// only the latest signer is supported. If the signature can not be extracted,
+// false is returned and the recovery is to be left to the signer, which also
+// provides the reason of the failure.
+func getSignature(signer types.Signer, tx *types.Transaction) (ecrecover.Signature, bool) {
+	chainId := signer.ChainID()
+	if chainId == nil || !signer.Equal(types.LatestSignerForChainID(chainId)) {
+		return ecrecover.Signature{}, false
+	}
+
+	v, r, s := tx.RawSignatureValues()
+	hash := signer.Hash(tx)
+	switch tx.Type() {
+	case types.LegacyTxType:
+		if !tx.Protected() {
+			v = new(big.Int).Sub(v, big.NewInt(27))
+			hash = types.HomesteadSigner{}.Hash(tx)
+			break
+		}
+		if tx.ChainId().Cmp(chainId) != 0 {
+			return ecrecover.Signature{}, false
+		}
+		v = new(big.Int).Sub(v, new(big.Int).Mul(chainId, big.NewInt(2)))
+		v.Sub(v, big.NewInt(35))
+	case types.AccessListTxType, types.DynamicFeeTxType, types.BlobTxType:
+		if tx.ChainId().Cmp(chainId) != 0 {
+			return ecrecover.Signature{}, false
+		}
+	default:
+		return ecrecover.Signature{}, false
+	}
+
+	if !v.IsUint64() || v.Uint64() > 1 || !crypto.ValidateSignatureValues(byte(v.Uint64()), r, s, true) {
+		return ecrecover.Signature{}, false
+	}
+	signature := ecrecover.Signature{Hash: tosca.Hash(hash), V: byte(v.Uint64())}
+	r.FillBytes(signature.R[:])
+	s.FillBytes(signature.S[:])
+	return signature, true
+}
+
+// recoverAuthority recovers the signer of a single authorization, consulting
+// the cache of recovered signers if the signature is valid. Otherwise, the
+// recovery is left to package rlp, which provides the reason of the failure.
+func (r *Recoverer) recoverAuthority(authorization *rlp.Authorization) (tosca.Address, error) {
+	v, rValue, sValue := authorization.V, authorization.R, authorization.S
+	if v.Cmp(tosca.NewValue(1)) > 0 || !crypto.ValidateSignatureValues(
+		v[31], rValue.ToUint256().ToBig(), sValue.ToUint256().ToBig(), true,
+	) {
+		return authorization.RecoverAuthority()
+	}
+	return r.signatures.Recover(ecrecover.Signature{
+		Hash: authorization.SigningHash(),
+		R:    rValue,
+		S:    sValue,
+		V:    v[31],
+	})
+}
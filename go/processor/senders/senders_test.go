@@ -0,0 +1,417 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package senders
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/processor/ecrecover"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/rlp"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	geth_rlp "github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
+)
+
+func signTransactions(t *testing.T, signer types.Signer, count int) ([]*types.Transaction, []tosca.Address) {
+	t.Helper()
+	transactions := make([]*types.Transaction, count)
+	senders := make([]tosca.Address, count)
+	for i := range count {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			Gas:      21_000,
+			GasPrice: big.NewInt(1),
+		})
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		transactions[i] = tx
+		senders[i] = tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return transactions, senders
+}
+
+func TestRecoverer_RecoversSendersInOrder(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	transactions, want := signTransactions(t, signer, 50)
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		recoverer, err := NewRecoverer(Config{Workers: workers})
+		if err != nil {
+			t.Fatalf("failed to create recoverer: %v", err)
+		}
+		got, err := recoverer.Recover(context.Background(), signer, transactions)
+		if err != nil {
+			t.Fatalf("failed to recover senders: %v", err)
+		}
+		if len(want) != len(got) {
+			t.Fatalf("unexpected number of senders, wanted %d, got %d", len(want), len(got))
+		}
+		for i := range want {
+			if want[i] != got[i] {
+				t.Errorf("unexpected sender of transaction %d with %d workers, wanted %v, got %v", i, workers, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestRecoverer_CachedSendersAreNotReportedForOtherChains(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	transactions, want := signTransactions(t, signer, 1)
+
+	signatures, err := ecrecover.NewCache(100)
+	if err != nil {
+		t.Fatalf("failed to create signature cache: %v", err)
+	}
+	recoverer, err := NewRecoverer(Config{Signatures: signatures})
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	got, err := recoverer.Recover(context.Background(), signer, transactions)
+	if err != nil {
+		t.Fatalf("failed to recover senders: %v", err)
+	}
+	if !slices.Equal(want, got) {
+		t.Errorf("unexpected senders, wanted %v, got %v", want, got)
+	}
+
+	other := types.LatestSignerForChainID(big.NewInt(1))
+	if _, err := recoverer.Recover(context.Background(), other, transactions); err == nil {
+		t.Errorf("expected transaction of another chain to be rejected")
+	}
+}
+
+func TestRecoverer_ReportsFirstTransactionWithInvalidSignature(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	transactions, _ := signTransactions(t, signer, 10)
+
+	// A signature for a different chain is rejected by the signer.
+	other := types.LatestSignerForChainID(big.NewInt(1))
+	invalid, _ := signTransactions(t, other, 2)
+	transactions[3] = invalid[0]
+	transactions[7] = invalid[1]
+
+	recoverer, err := NewRecoverer(Config{})
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	_, err = recoverer.Recover(context.Background(), signer, transactions)
+	if err == nil || !strings.Contains(err.Error(), "transaction 3") {
+		t.Errorf("unexpected error, got %v", err)
+	}
+}
+
+func TestRecoverer_RecoveryCanBeAborted(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	transactions, _ := signTransactions(t, signer, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recoverer, err := NewRecoverer(Config{})
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	if _, err := recoverer.Recover(ctx, signer, transactions); err != context.Canceled {
+		t.Errorf("unexpected error, wanted %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestNewRecoverer_RejectsNegativeNumberOfWorkers(t *testing.T) {
+	if _, err := NewRecoverer(Config{Workers: -1}); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestRecoverer_SignersAreRecoveredThroughSignatureCache(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	transactions, want := signTransactions(t, signer, 10)
+
+	signatures, err := ecrecover.NewCache(100)
+	if err != nil {
+		t.Fatalf("failed to create signature cache: %v", err)
+	}
+	for range 2 {
+		recoverer, err := NewRecoverer(Config{Signatures: signatures})
+		if err != nil {
+			t.Fatalf("failed to create recoverer: %v", err)
+		}
+		got, err := recoverer.Recover(context.Background(), signer, transactions)
+		if err != nil {
+			t.Fatalf("failed to recover senders: %v", err)
+		}
+		if !slices.Equal(want, got) {
+			t.Errorf("unexpected senders, wanted %v, got %v", want, got)
+		}
+	}
+
+	stats := signatures.Statistics()
+	if want, got := uint64(len(transactions)), stats.Misses; want != got {
+		t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(len(transactions)), stats.Hits; want != got {
+		t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+	}
+}
+
+func TestGetSignature_ProducesSignaturesOfAllTransactionTypes(t *testing.T) {
+	chainId := big.NewInt(250)
+	signer := types.LatestSignerForChainID(chainId)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	want := tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+
+	tests := map[string]struct {
+		signer types.Signer
+		data   types.TxData
+	}{
+		"unprotected legacy": {types.HomesteadSigner{}, &types.LegacyTx{Gas: 21_000}},
+		"protected legacy":   {signer, &types.LegacyTx{Gas: 21_000}},
+		"access list":        {signer, &types.AccessListTx{ChainID: chainId, Gas: 21_000}},
+		"dynamic fee":        {signer, &types.DynamicFeeTx{ChainID: chainId, Gas: 21_000}},
+		"blob":               {signer, &types.BlobTx{ChainID: uint256.MustFromBig(chainId), Gas: 21_000}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx, err := types.SignNewTx(key, test.signer, test.data)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			signature, ok := getSignature(signer, tx)
+			if !ok {
+				t.Fatalf("failed to get signature")
+			}
+			got, err := ecrecover.Recover(signature)
+			if err != nil {
+				t.Fatalf("failed to recover signer: %v", err)
+			}
+			if want != got {
+				t.Errorf("unexpected signer, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestGetSignature_LeavesInvalidSignaturesToSigner(t *testing.T) {
+	signer := types.LatestSignerForChainID(big.NewInt(250))
+	valid, _ := signTransactions(t, signer, 1)
+	foreign, _ := signTransactions(t, types.LatestSignerForChainID(big.NewInt(1)), 1)
+
+	// Transaction signatures are required to restrict S to the lower half of
+	// the curve order. Negating S and flipping V yields a signature of the
+	// same signer violating this restriction.
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	unsigned := types.NewTx(&types.LegacyTx{Gas: 21_000})
+	hash := signer.Hash(unsigned)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	s := new(big.Int).SetBytes(sig[32:64])
+	s.Sub(crypto.S256().Params().N, s)
+	s.FillBytes(sig[32:64])
+	sig[64] ^= 1
+	malleable, err := unsigned.WithSignature(signer, sig)
+	if err != nil {
+		t.Fatalf("failed to create transaction: %v", err)
+	}
+
+	tests := map[string]struct {
+		signer types.Signer
+		tx     *types.Transaction
+	}{
+		"foreign chain":   {signer, foreign[0]},
+		"high S value":    {signer, malleable},
+		"outdated signer": {types.NewLondonSigner(big.NewInt(250)), valid[0]},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, ok := getSignature(test.signer, test.tx); ok {
+				t.Errorf("signature should have been left to the signer")
+			}
+		})
+	}
+}
+
+// signSetCodeTransaction encodes a set-code transaction including an
+// authorization signed by each of the given keys. Since the go-ethereum
+// version in use does not support set-code transactions, the transaction is
+// encoded manually and decoded by package rlp.
+func signSetCodeTransaction(t *testing.T, keys []*ecdsa.PrivateKey) rlp.SignedTransaction {
+	t.Helper()
+	chainId := big.NewInt(250)
+	sign := func(hash []byte, key *ecdsa.PrivateKey) []any {
+		signature, err := crypto.Sign(hash, key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		return []any{
+			uint64(signature[64]),
+			new(big.Int).SetBytes(signature[0:32]),
+			new(big.Int).SetBytes(signature[32:64]),
+		}
+	}
+	encode := func(prefix byte, value any) []byte {
+		encoded, err := geth_rlp.EncodeToBytes(value)
+		if err != nil {
+			t.Fatalf("failed to encode: %v", err)
+		}
+		return append([]byte{prefix}, encoded...)
+	}
+
+	authorizations := []any{}
+	for i, key := range keys {
+		fields := []any{chainId, common.Address{0xde}, uint64(i)}
+		hash := crypto.Keccak256(encode(0x05, fields))
+		authorizations = append(authorizations, append(fields, sign(hash, key)...))
+	}
+
+	sender, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	fields := []any{
+		chainId, uint64(0), big.NewInt(1), big.NewInt(100), uint64(100_000),
+		common.Address{1}, big.NewInt(0), []byte{}, []any{}, authorizations,
+	}
+	hash := crypto.Keccak256(encode(byte(rlp.SetCodeTxType), fields))
+	encoded := encode(byte(rlp.SetCodeTxType), append(fields, sign(hash, sender)...))
+
+	transaction, err := rlp.DecodeTransaction(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode set-code transaction: %v", err)
+	}
+	return transaction
+}
+
+func generateKeys(t *testing.T, count int) ([]*ecdsa.PrivateKey, []tosca.Address) {
+	t.Helper()
+	keys := make([]*ecdsa.PrivateKey, count)
+	addresses := make([]tosca.Address, count)
+	for i := range keys {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatalf("failed to generate key: %v", err)
+		}
+		keys[i] = key
+		addresses[i] = tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+	}
+	return keys, addresses
+}
+
+func TestRecoverer_RecoversAuthoritiesOfSetCodeTransactions(t *testing.T) {
+	keys, want := generateKeys(t, 6)
+	transactions := []rlp.SignedTransaction{
+		signSetCodeTransaction(t, keys[:4]),
+		{}, // < transactions without authorizations have no results
+		signSetCodeTransaction(t, keys[4:]),
+	}
+
+	for _, workers := range []int{0, 1, 4} {
+		signatures, err := ecrecover.NewCache(100)
+		if err != nil {
+			t.Fatalf("failed to create signature cache: %v", err)
+		}
+		recoverer, err := NewRecoverer(Config{Workers: workers, Signatures: signatures})
+		if err != nil {
+			t.Fatalf("failed to create recoverer: %v", err)
+		}
+		for range 2 {
+			authorities, err := recoverer.RecoverAuthorities(context.Background(), transactions)
+			if err != nil {
+				t.Fatalf("failed to recover authorities: %v", err)
+			}
+			got := []tosca.Address{}
+			for _, list := range authorities {
+				for _, authority := range list {
+					if authority.Err != nil {
+						t.Fatalf("failed to recover authority: %v", authority.Err)
+					}
+					got = append(got, authority.Address)
+				}
+			}
+			if !slices.Equal(want, got) {
+				t.Errorf("unexpected authorities, wanted %v, got %v", want, got)
+			}
+			if authorities[1] != nil {
+				t.Errorf("unexpected authorities of plain transaction: %v", authorities[1])
+			}
+		}
+
+		stats := signatures.Statistics()
+		if want, got := uint64(len(keys)), stats.Misses; want != got {
+			t.Errorf("unexpected number of misses, wanted %d, got %d", want, got)
+		}
+		if want, got := uint64(len(keys)), stats.Hits; want != got {
+			t.Errorf("unexpected number of hits, wanted %d, got %d", want, got)
+		}
+	}
+}
+
+func TestRecoverer_InvalidAuthorizationsAreReportedIndividually(t *testing.T) {
+	keys, want := generateKeys(t, 3)
+	transaction := signSetCodeTransaction(t, keys)
+	transaction.AuthorizationList[1].V = tosca.NewValue(2)
+
+	recoverer, err := NewRecoverer(Config{})
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	authorities, err := recoverer.RecoverAuthorities(context.Background(), []rlp.SignedTransaction{transaction})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, authority := range authorities[0] {
+		if i == 1 {
+			if !errors.Is(authority.Err, rlp.ErrInvalidSignature) {
+				t.Errorf("unexpected error of invalid authorization: %v", authority.Err)
+			}
+			continue
+		}
+		if authority.Err != nil || authority.Address != want[i] {
+			t.Errorf("unexpected result of authorization %d: %+v", i, authority)
+		}
+	}
+}
+
+func TestRecoverer_RecoveryOfAuthoritiesCanBeAborted(t *testing.T) {
+	keys, _ := generateKeys(t, 2)
+	transactions := []rlp.SignedTransaction{signSetCodeTransaction(t, keys)}
+
+	recoverer, err := NewRecoverer(Config{})
+	if err != nil {
+		t.Fatalf("failed to create recoverer: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := recoverer.RecoverAuthorities(ctx, transactions); !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error, wanted %v, got %v", context.Canceled, err)
+	}
+}
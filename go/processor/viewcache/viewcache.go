@@ -0,0 +1,171 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package viewcache provides the memoization of read-only calls, as issued in
+// large numbers by RPC clients through eth_call-like requests. Since the
+// result of a call only depends on the state it is executed on, the call
+// itself, and the block it is executed in, repeated calls on the same state
+// can be served from a cache instead of being executed again.
+//
+// States are identified by client-chosen identifiers, typically state roots.
+// Since the cache can not observe modifications of a state, identifiers must
+// not be reused for different states without invalidating them first.
+package viewcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// Config contains the configuration options of a Processor.
+type Config struct {
+	// CacheSize is the maximum number of receipts retained in the cache. If
+	// set to 0, a default size is used.
+	CacheSize int
+}
+
+// Processor is a tosca.Processor memoizing the receipts of read-only calls.
+// Regular transactions executed through Run are forwarded to the wrapped
+// processor without being cached; only calls issued through Call are
+// memoized. Processors are safe for concurrent use if the wrapped processor
+// is.
+type Processor struct {
+	tosca.Processor
+	cache *lru.Cache[callKey, tosca.Receipt]
+}
+
+// callKey identifies a call by the state it is executed on, the parameters
+// of the block it is executed in, and a digest of the transaction.
+type callKey struct {
+	state       tosca.Hash
+	block       tosca.BlockParameters
+	transaction [sha256.Size]byte
+}
+
+// NewProcessor creates a Processor memoizing read-only calls executed by the
+// given processor.
+func NewProcessor(processor tosca.Processor, config Config) (*Processor, error) {
+	if config.CacheSize == 0 {
+		config.CacheSize = 1 << 14
+	}
+	cache, err := lru.New[callKey, tosca.Receipt](config.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Processor{
+		Processor: processor,
+		cache:     cache,
+	}, nil
+}
+
+// Call executes the given transaction as a read-only call on the state with
+// the given identifier. If the same call has been executed before on the same
+// state with the same block parameters, the retained receipt is returned
+// without executing the call again and without accessing the transaction
+// context. Thus, Call must only be used where modifications of the context
+// are discarded after the call, as for serving eth_call-like requests.
+//
+// Receipts are only retained for calls completed without error. Returned
+// receipts are copies, such that modifying them does not affect the cache.
+func (p *Processor) Call(
+	ctx context.Context,
+	state tosca.Hash,
+	blockParameters tosca.BlockParameters,
+	transaction tosca.Transaction,
+	context tosca.TransactionContext,
+) (tosca.Receipt, error) {
+	key := callKey{
+		state:       state,
+		block:       blockParameters,
+		transaction: digest(transaction),
+	}
+	if receipt, found := p.cache.Get(key); found {
+		return cloneReceipt(receipt), nil
+	}
+	receipt, err := p.Processor.Run(ctx, blockParameters, transaction, context)
+	if err != nil {
+		return receipt, err
+	}
+	p.cache.Add(key, cloneReceipt(receipt))
+	return receipt, nil
+}
+
+// Invalidate removes all receipts of calls executed on the state with the
+// given identifier from the cache. It needs to be called before reusing the
+// identifier for a different state, after all calls on the old state have
+// completed. The costs are linear in the number of retained receipts.
+func (p *Processor) Invalidate(state tosca.Hash) {
+	for _, key := range p.cache.Keys() {
+		if key.state == state {
+			p.cache.Remove(key)
+		}
+	}
+}
+
+// Purge removes all receipts from the cache.
+func (p *Processor) Purge() {
+	p.cache.Purge()
+}
+
+// Len returns the number of receipts retained by the cache.
+func (p *Processor) Len() int {
+	return p.cache.Len()
+}
+
+// digest computes a hash covering all fields of the given transaction.
+func digest(transaction tosca.Transaction) [sha256.Size]byte {
+	hash := sha256.New()
+	write := func(data ...[]byte) {
+		for _, cur := range data {
+			hash.Write(binary.BigEndian.AppendUint64(nil, uint64(len(cur))))
+			hash.Write(cur)
+		}
+	}
+	var recipient []byte
+	if transaction.Recipient != nil {
+		recipient = transaction.Recipient[:]
+	}
+	write(
+		transaction.Sender[:],
+		recipient,
+		binary.BigEndian.AppendUint64(nil, transaction.Nonce),
+		transaction.Input,
+		transaction.Value[:],
+		binary.BigEndian.AppendUint64(nil, uint64(transaction.GasLimit)),
+		transaction.GasPrice[:],
+		binary.BigEndian.AppendUint64(nil, uint64(len(transaction.AccessList))),
+	)
+	for _, tuple := range transaction.AccessList {
+		write(tuple.Address[:])
+		for _, key := range tuple.Keys {
+			write(key[:])
+		}
+	}
+	return [sha256.Size]byte(hash.Sum(nil))
+}
+
+func cloneReceipt(receipt tosca.Receipt) tosca.Receipt {
+	receipt.Output = slices.Clone(receipt.Output)
+	receipt.Logs = slices.Clone(receipt.Logs)
+	for i := range receipt.Logs {
+		receipt.Logs[i].Topics = slices.Clone(receipt.Logs[i].Topics)
+		receipt.Logs[i].Data = slices.Clone(receipt.Logs[i].Data)
+	}
+	if receipt.ContractAddress != nil {
+		address := *receipt.ContractAddress
+		receipt.ContractAddress = &address
+	}
+	return receipt
+}
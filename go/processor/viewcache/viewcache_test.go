@@ -0,0 +1,195 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package viewcache
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProcessor_RepeatedCallsAreServedFromCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := tosca.NewMockProcessor(ctrl)
+	want := tosca.Receipt{Success: true, Output: tosca.Data{1, 2, 3}, GasUsed: 21000}
+	inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(want, nil)
+
+	processor, err := NewProcessor(inner, Config{})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	transaction := tosca.Transaction{Recipient: &tosca.Address{1}, Input: tosca.Data{4, 5}}
+	for range 3 {
+		got, err := processor.Call(context.Background(), tosca.Hash{1}, tosca.BlockParameters{}, transaction, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want.Success != got.Success || want.GasUsed != got.GasUsed || string(want.Output) != string(got.Output) {
+			t.Errorf("unexpected receipt, wanted %v, got %v", want, got)
+		}
+	}
+}
+
+func TestProcessor_CallsDifferingInAnyParameterAreExecuted(t *testing.T) {
+	transaction := tosca.Transaction{Recipient: &tosca.Address{1}, Input: tosca.Data{4, 5}}
+	tests := map[string]func(*tosca.Hash, *tosca.BlockParameters, *tosca.Transaction){
+		"state": func(state *tosca.Hash, _ *tosca.BlockParameters, _ *tosca.Transaction) {
+			state[0] = 2
+		},
+		"block": func(_ *tosca.Hash, block *tosca.BlockParameters, _ *tosca.Transaction) {
+			block.BlockNumber = 12
+		},
+		"recipient": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.Recipient = &tosca.Address{2}
+		},
+		"no recipient": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.Recipient = nil
+		},
+		"input": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.Input = tosca.Data{4, 5, 6}
+		},
+		"sender": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.Sender = tosca.Address{3}
+		},
+		"value": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.Value = tosca.NewValue(1)
+		},
+		"gas limit": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.GasLimit = 1000
+		},
+		"access list": func(_ *tosca.Hash, _ *tosca.BlockParameters, transaction *tosca.Transaction) {
+			transaction.AccessList = []tosca.AccessTuple{{Address: tosca.Address{1}}}
+		},
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			inner := tosca.NewMockProcessor(ctrl)
+			inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+			processor, err := NewProcessor(inner, Config{})
+			if err != nil {
+				t.Fatalf("failed to create processor: %v", err)
+			}
+			state, block, modified := tosca.Hash{1}, tosca.BlockParameters{}, transaction
+			if _, err := processor.Call(context.Background(), state, block, modified, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			modify(&state, &block, &modified)
+			if _, err := processor.Call(context.Background(), state, block, modified, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_FailedCallsAreNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := tosca.NewMockProcessor(ctrl)
+	injected := errors.New("injected error")
+	gomock.InOrder(
+		inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tosca.Receipt{}, injected),
+		inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(tosca.Receipt{}, nil),
+	)
+
+	processor, err := NewProcessor(inner, Config{})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	if _, err := processor.Call(context.Background(), tosca.Hash{}, tosca.BlockParameters{}, tosca.Transaction{}, nil); !errors.Is(err, injected) {
+		t.Errorf("unexpected error, wanted %v, got %v", injected, err)
+	}
+	if _, err := processor.Call(context.Background(), tosca.Hash{}, tosca.BlockParameters{}, tosca.Transaction{}, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if want, got := 1, processor.Len(); want != got {
+		t.Errorf("unexpected number of cached receipts, wanted %d, got %d", want, got)
+	}
+}
+
+func TestProcessor_InvalidateRemovesReceiptsOfState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := tosca.NewMockProcessor(ctrl)
+	inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	processor, err := NewProcessor(inner, Config{})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	call := func(state tosca.Hash) {
+		if _, err := processor.Call(context.Background(), state, tosca.BlockParameters{}, tosca.Transaction{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	call(tosca.Hash{1})
+	call(tosca.Hash{2})
+	processor.Invalidate(tosca.Hash{1})
+	if want, got := 1, processor.Len(); want != got {
+		t.Errorf("unexpected number of cached receipts, wanted %d, got %d", want, got)
+	}
+	call(tosca.Hash{1}) // < executed again
+	call(tosca.Hash{2}) // < served from the cache
+
+	processor.Purge()
+	if want, got := 0, processor.Len(); want != got {
+		t.Errorf("unexpected number of cached receipts, wanted %d, got %d", want, got)
+	}
+}
+
+func TestProcessor_ReturnedReceiptsDoNotAliasCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := tosca.NewMockProcessor(ctrl)
+	inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(tosca.Receipt{Output: tosca.Data{1}}, nil)
+
+	processor, err := NewProcessor(inner, Config{})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	for range 2 {
+		receipt, err := processor.Call(context.Background(), tosca.Hash{}, tosca.BlockParameters{}, tosca.Transaction{}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want, got := byte(1), receipt.Output[0]; want != got {
+			t.Errorf("unexpected output, wanted %d, got %d", want, got)
+		}
+		receipt.Output[0] = 2
+	}
+}
+
+func TestProcessor_RunIsNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := tosca.NewMockProcessor(ctrl)
+	inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+
+	processor, err := NewProcessor(inner, Config{})
+	if err != nil {
+		t.Fatalf("failed to create processor: %v", err)
+	}
+	for range 2 {
+		if _, err := processor.Run(context.Background(), tosca.BlockParameters{}, tosca.Transaction{}, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if want, got := 0, processor.Len(); want != got {
+		t.Errorf("unexpected number of cached receipts, wanted %d, got %d", want, got)
+	}
+}
+
+func TestNewProcessor_RejectsNegativeCacheSize(t *testing.T) {
+	if _, err := NewProcessor(nil, Config{CacheSize: -1}); err == nil {
+		t.Errorf("expected an error for a negative cache size")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package prometheus_adapter provides an implementation of the tosca.Metrics
+// interface exporting all reported metrics to Prometheus.
+package prometheus_adapter
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Config contains the configuration options of an Exporter.
+type Config struct {
+	// Registry is the registry the metrics are registered in. If nil, a new
+	// registry is created.
+	Registry *prometheus.Registry
+	// Buckets are the upper bounds of the buckets of histograms. If nil,
+	// DefaultBuckets are used.
+	Buckets []float64
+}
+
+// DefaultBuckets cover durations from one microsecond to about four seconds,
+// the range of the durations of individual calls and transactions.
+var DefaultBuckets = prometheus.ExponentialBuckets(1e-6, 4, 12)
+
+// Exporter is a tosca.Metrics implementation registering all metrics in a
+// Prometheus registry, which may be served to Prometheus through Handler.
+// Metrics are registered when first requested. Requesting metrics of the same
+// name with different kinds or label names is a programming error and causes
+// a panic, as do conflicts with other metrics of the registry.
+type Exporter struct {
+	registry *prometheus.Registry
+	buckets  []float64
+	mutex    sync.Mutex
+	vectors  map[string]vector
+}
+
+var _ tosca.Metrics = (*Exporter)(nil)
+
+type kind int
+
+const (
+	counter kind = iota
+	gauge
+	histogram
+)
+
+// vector is a registered metric with all its labeled instances.
+type vector struct {
+	kind   kind
+	labels []string
+	vector *prometheus.MetricVec
+}
+
+// NewExporter creates a new Exporter with the given configuration.
+func NewExporter(config Config) *Exporter {
+	if config.Registry == nil {
+		config.Registry = prometheus.NewRegistry()
+	}
+	if config.Buckets == nil {
+		config.Buckets = DefaultBuckets
+	}
+	return &Exporter{
+		registry: config.Registry,
+		buckets:  config.Buckets,
+		vectors:  map[string]vector{},
+	}
+}
+
+// Registry returns the registry the metrics of this exporter are registered in.
+func (e *Exporter) Registry() *prometheus.Registry {
+	return e.registry
+}
+
+// Handler returns an HTTP handler serving the metrics of the registry in the
+// Prometheus exposition format.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+func (e *Exporter) Counter(name string, labels ...tosca.Label) tosca.Counter {
+	return e.get(counter, name, labels).(prometheus.Counter)
+}
+
+func (e *Exporter) Gauge(name string, labels ...tosca.Label) tosca.Gauge {
+	return e.get(gauge, name, labels).(prometheus.Gauge)
+}
+
+func (e *Exporter) Histogram(name string, labels ...tosca.Label) tosca.Histogram {
+	return e.get(histogram, name, labels).(prometheus.Observer)
+}
+
+func (e *Exporter) get(kind kind, name string, labels []tosca.Label) prometheus.Metric {
+	names := make([]string, len(labels))
+	values := make([]string, len(labels))
+	for i, label := range labels {
+		names[i] = label.Name
+		values[i] = label.Value
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	vec, found := e.vectors[name]
+	if !found {
+		vec = e.register(kind, name, names)
+		e.vectors[name] = vec
+	}
+	if vec.kind != kind || !slices.Equal(vec.labels, names) {
+		panic(fmt.Sprintf("inconsistent use of metric %q", name))
+	}
+	metric, err := vec.vector.GetMetricWithLabelValues(values...)
+	if err != nil {
+		panic(fmt.Sprintf("invalid labels of metric %q: %v", name, err))
+	}
+	return metric
+}
+
+func (e *Exporter) register(kind kind, name string, labels []string) vector {
+	var collector prometheus.Collector
+	var vec *prometheus.MetricVec
+	switch kind {
+	case counter:
+		counters := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: name}, labels)
+		collector, vec = counters, counters.MetricVec
+	case gauge:
+		gauges := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: name}, labels)
+		collector, vec = gauges, gauges.MetricVec
+	case histogram:
+		histograms := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: name, Help: name, Buckets: e.buckets}, labels)
+		collector, vec = histograms, histograms.MetricVec
+	}
+	e.registry.MustRegister(collector)
+	return vector{kind: kind, labels: labels, vector: vec}
+}
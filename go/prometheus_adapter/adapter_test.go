@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package prometheus_adapter
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestExporter_ReportedValuesAreExported(t *testing.T) {
+	exporter := NewExporter(Config{})
+	label := tosca.Label{Name: "interpreter", Value: "lfvm"}
+
+	exporter.Counter("runs_total", label).Add(2)
+	exporter.Counter("runs_total", label).Add(3)
+	exporter.Gauge("size_bytes").Set(42)
+	exporter.Histogram("duration_seconds", label).Observe(0.5)
+
+	families, err := exporter.Registry().Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	if want, got := 3, len(families); want != got {
+		t.Fatalf("unexpected number of metric families, wanted %d, got %d", want, got)
+	}
+
+	expected := `
+		# HELP runs_total runs_total
+		# TYPE runs_total counter
+		runs_total{interpreter="lfvm"} 5
+		# HELP size_bytes size_bytes
+		# TYPE size_bytes gauge
+		size_bytes 42
+	`
+	if err := testutil.GatherAndCompare(exporter.Registry(), strings.NewReader(expected), "runs_total", "size_bytes"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+}
+
+func TestExporter_LabelsDistinguishMetrics(t *testing.T) {
+	exporter := NewExporter(Config{})
+	exporter.Counter("failures_total", tosca.Label{Name: "reason", Value: "error"}).Add(1)
+	exporter.Counter("failures_total", tosca.Label{Name: "reason", Value: "reverted"}).Add(2)
+
+	expected := `
+		# HELP failures_total failures_total
+		# TYPE failures_total counter
+		failures_total{reason="error"} 1
+		failures_total{reason="reverted"} 2
+	`
+	if err := testutil.GatherAndCompare(exporter.Registry(), strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+}
+
+func TestExporter_HistogramsUseConfiguredBuckets(t *testing.T) {
+	exporter := NewExporter(Config{Buckets: []float64{1, 2}})
+	exporter.Histogram("duration_seconds").Observe(1.5)
+
+	expected := `
+		# HELP duration_seconds duration_seconds
+		# TYPE duration_seconds histogram
+		duration_seconds_bucket{le="1"} 0
+		duration_seconds_bucket{le="2"} 1
+		duration_seconds_bucket{le="+Inf"} 1
+		duration_seconds_sum 1.5
+		duration_seconds_count 1
+	`
+	if err := testutil.GatherAndCompare(exporter.Registry(), strings.NewReader(expected)); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+}
+
+func TestExporter_MetricsAreRegisteredInGivenRegistry(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	exporter := NewExporter(Config{Registry: registry})
+	exporter.Counter("runs_total").Add(1)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	if len(families) != 1 || families[0].GetName() != "runs_total" {
+		t.Errorf("unexpected metrics in registry: %v", families)
+	}
+}
+
+func TestExporter_InconsistentUseCausesPanic(t *testing.T) {
+	tests := map[string]func(*Exporter){
+		"different kinds": func(e *Exporter) {
+			e.Gauge("metric")
+		},
+		"different labels": func(e *Exporter) {
+			e.Counter("metric", tosca.Label{Name: "other", Value: "value"})
+		},
+		"missing labels": func(e *Exporter) {
+			e.Counter("metric")
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			exporter := NewExporter(Config{})
+			exporter.Counter("metric", tosca.Label{Name: "label", Value: "value"})
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+			test(exporter)
+		})
+	}
+}
+
+func TestExporter_HandlerServesMetrics(t *testing.T) {
+	exporter := NewExporter(Config{})
+	exporter.Counter(tosca.MetricProcessorTransactions, tosca.Label{Name: "processor", Value: "floria"}).Add(7)
+
+	recorder := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(recorder.Result().Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if want := `tosca_processor_transactions_total{processor="floria"} 7`; !strings.Contains(string(body), want) {
+		t.Errorf("response does not contain %q, got %s", want, body)
+	}
+}
+
+func TestExporter_MeasuredInterpreterReportsToPrometheus(t *testing.T) {
+	exporter := NewExporter(Config{})
+	interpreter := tosca.NewMeasuredInterpreter(stoppingInterpreter{}, exporter, "stop")
+	for range 4 {
+		if _, err := interpreter.Run(tosca.Parameters{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	runs := exporter.Counter(tosca.MetricInterpreterRuns, tosca.Label{Name: "interpreter", Value: "stop"})
+	if want, got := 4.0, testutil.ToFloat64(runs.(prometheus.Counter)); want != got {
+		t.Errorf("unexpected number of runs, wanted %v, got %v", want, got)
+	}
+}
+
+type stoppingInterpreter struct{}
+
+func (stoppingInterpreter) Run(tosca.Parameters) (tosca.Result, error) {
+	return tosca.Result{Success: true}, nil
+}
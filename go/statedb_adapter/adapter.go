@@ -0,0 +1,283 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// This package adapts the state database interfaces used by Sonic, and
+// implemented by Carmen and go-ethereum, to the tosca.TransactionContext
+// interface. It enables running Tosca processors directly on the state
+// backend used in production, for instance for testing processors on the
+// state of an archive or for replaying recorded blocks. Furthermore, the
+// StateProcessor enables go-ethereum nodes to process blocks using Tosca
+// processors.
+package statedb_adapter
+
+import (
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+)
+
+// StateDB is the interface of the state databases used by Sonic for running
+// transactions. It is implemented by go-ethereum's StateDB as well as by the
+// adapters of Carmen's state databases used by Sonic.
+type StateDB interface {
+	vm.StateDB
+}
+
+// Archive is implemented by state databases retaining the state of past
+// blocks.
+type Archive interface {
+	// GetArchiveState returns the state at the end of the given block. The
+	// returned state may be modified, however, modifications are not
+	// persisted.
+	GetArchiveState(block uint64) (StateDB, error)
+}
+
+// BulkLoad is the interface of bulk loads of state databases, as offered by
+// Carmen for efficiently setting up large states, for instance the genesis
+// state of a chain.
+type BulkLoad interface {
+	CreateAccount(common.Address)
+	SetBalance(common.Address, *uint256.Int)
+	SetNonce(common.Address, uint64)
+	SetState(common.Address, common.Hash, common.Hash)
+	SetCode(common.Address, []byte)
+	Close() error
+}
+
+// BlockHashes provides the hashes of past blocks, as required for the
+// BLOCKHASH instruction.
+type BlockHashes func(number int64) tosca.Hash
+
+// NewTransactionContext creates a tosca.TransactionContext operating on the
+// given state database. The revision determines the semantics of
+// self-destructs. The provided block hashes may be nil, in which case the
+// hashes of all blocks are reported to be zero. A context is intended to be
+// used for a single transaction, and the access list as well as the transient
+// storage of the database need to be reset before, e.g., by StateDB.Prepare.
+func NewTransactionContext(db StateDB, revision tosca.Revision, blockHashes BlockHashes) tosca.TransactionContext {
+	return &transactionContext{
+		db:           db,
+		revision:     revision,
+		blockHashes:  blockHashes,
+		logSnapshots: map[tosca.Snapshot]int{},
+	}
+}
+
+// NewArchiveTransactionContext creates a tosca.TransactionContext operating
+// on the state at the end of the given block of the given archive.
+func NewArchiveTransactionContext(archive Archive, block uint64, revision tosca.Revision, blockHashes BlockHashes) (tosca.TransactionContext, error) {
+	db, err := archive.GetArchiveState(block)
+	if err != nil {
+		return nil, err
+	}
+	return NewTransactionContext(db, revision, blockHashes), nil
+}
+
+// Load adds the given accounts to the state using the given bulk load, which
+// is closed afterwards.
+func Load(load BulkLoad, accounts prestate.State) error {
+	for address, account := range accounts {
+		address := common.Address(address)
+		load.CreateAccount(address)
+		if account.Balance != nil {
+			load.SetBalance(address, uint256.MustFromBig(account.Balance.ToInt()))
+		}
+		load.SetNonce(address, account.Nonce)
+		if len(account.Code) > 0 {
+			load.SetCode(address, account.Code)
+		}
+		for key, value := range account.Storage {
+			load.SetState(address, key, value)
+		}
+	}
+	return load.Close()
+}
+
+// transactionContext implements the tosca.TransactionContext interface on top
+// of a StateDB. Snapshots are mapped to snapshots of the StateDB. Since the
+// logs of a StateDB are indexed by transaction hashes, which are not known to
+// the adapter, logs are additionally recorded locally.
+type transactionContext struct {
+	db           StateDB
+	revision     tosca.Revision
+	blockHashes  BlockHashes
+	logs         []tosca.Log
+	logSnapshots map[tosca.Snapshot]int // number of logs by snapshot
+}
+
+func (c *transactionContext) AccountExists(address tosca.Address) bool {
+	return c.db.Exist(common.Address(address))
+}
+
+func (c *transactionContext) GetBalance(address tosca.Address) tosca.Value {
+	return tosca.ValueFromUint256(c.db.GetBalance(common.Address(address)))
+}
+
+func (c *transactionContext) SetBalance(address tosca.Address, value tosca.Value) {
+	current := c.db.GetBalance(common.Address(address))
+	target := value.ToUint256()
+	switch current.Cmp(target) {
+	case -1:
+		diff := new(uint256.Int).Sub(target, current)
+		c.db.AddBalance(common.Address(address), diff, tracing.BalanceChangeUnspecified)
+	case 1:
+		diff := new(uint256.Int).Sub(current, target)
+		c.db.SubBalance(common.Address(address), diff, tracing.BalanceChangeUnspecified)
+	}
+}
+
+func (c *transactionContext) GetNonce(address tosca.Address) uint64 {
+	return c.db.GetNonce(common.Address(address))
+}
+
+func (c *transactionContext) SetNonce(address tosca.Address, nonce uint64) {
+	addr := common.Address(address)
+	// Processors signal the creation of a contract by setting the nonce of
+	// the new account to 1, while the StateDB needs to be informed about the
+	// creation explicitly. Senders of their first transaction are marked as
+	// well, which is without effect since they cannot self-destruct.
+	if nonce == 1 && c.db.GetNonce(addr) == 0 && c.db.GetCodeSize(addr) == 0 {
+		if !c.db.Exist(addr) {
+			c.db.CreateAccount(addr)
+		}
+		c.db.CreateContract(addr)
+	}
+	c.db.SetNonce(addr, nonce)
+}
+
+func (c *transactionContext) GetCode(address tosca.Address) tosca.Code {
+	return c.db.GetCode(common.Address(address))
+}
+
+func (c *transactionContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	return tosca.Hash(c.db.GetCodeHash(common.Address(address)))
+}
+
+func (c *transactionContext) GetCodeSize(address tosca.Address) int {
+	return c.db.GetCodeSize(common.Address(address))
+}
+
+func (c *transactionContext) SetCode(address tosca.Address, code tosca.Code) {
+	c.db.SetCode(common.Address(address), code)
+}
+
+func (c *transactionContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return tosca.Word(c.db.GetState(common.Address(address), common.Hash(key)))
+}
+
+func (c *transactionContext) SetStorage(address tosca.Address, key tosca.Key, value tosca.Word) tosca.StorageStatus {
+	addr, slot := common.Address(address), common.Hash(key)
+	original := tosca.Word(c.db.GetCommittedState(addr, slot))
+	current := tosca.Word(c.db.GetState(addr, slot))
+	c.db.SetState(addr, slot, common.Hash(value))
+	return tosca.GetStorageStatus(original, current, value)
+}
+
+func (c *transactionContext) GetCommittedStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return tosca.Word(c.db.GetCommittedState(common.Address(address), common.Hash(key)))
+}
+
+func (c *transactionContext) SelfDestruct(address tosca.Address, beneficiary tosca.Address) bool {
+	addr, target := common.Address(address), common.Address(beneficiary)
+	first := !c.db.HasSelfDestructed(addr)
+	balance := new(uint256.Int).Set(c.db.GetBalance(addr))
+	if c.revision >= tosca.R13_Cancun {
+		// Since EIP-6780, only contracts created in the same transaction
+		// are destructed, while the balance is transferred in any case.
+		c.db.SubBalance(addr, balance, tracing.BalanceDecreaseSelfdestruct)
+		c.db.AddBalance(target, balance, tracing.BalanceIncreaseSelfdestruct)
+		c.db.Selfdestruct6780(addr)
+	} else {
+		c.db.AddBalance(target, balance, tracing.BalanceIncreaseSelfdestruct)
+		c.db.SelfDestruct(addr)
+	}
+	return first
+}
+
+func (c *transactionContext) HasSelfDestructed(address tosca.Address) bool {
+	return c.db.HasSelfDestructed(common.Address(address))
+}
+
+func (c *transactionContext) CreateSnapshot() tosca.Snapshot {
+	snapshot := tosca.Snapshot(c.db.Snapshot())
+	c.logSnapshots[snapshot] = len(c.logs)
+	return snapshot
+}
+
+func (c *transactionContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	c.db.RevertToSnapshot(int(snapshot))
+	if length, found := c.logSnapshots[snapshot]; found && length < len(c.logs) {
+		c.logs = c.logs[:length]
+	}
+}
+
+func (c *transactionContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	return tosca.Word(c.db.GetTransientState(common.Address(address), common.Hash(key)))
+}
+
+func (c *transactionContext) SetTransientStorage(address tosca.Address, key tosca.Key, value tosca.Word) {
+	c.db.SetTransientState(common.Address(address), common.Hash(key), common.Hash(value))
+}
+
+func (c *transactionContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	addr := common.Address(address)
+	if c.db.AddressInAccessList(addr) {
+		return tosca.WarmAccess
+	}
+	c.db.AddAddressToAccessList(addr)
+	return tosca.ColdAccess
+}
+
+func (c *transactionContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	addr, slot := common.Address(address), common.Hash(key)
+	if _, present := c.db.SlotInAccessList(addr, slot); present {
+		return tosca.WarmAccess
+	}
+	c.db.AddSlotToAccessList(addr, slot)
+	return tosca.ColdAccess
+}
+
+func (c *transactionContext) IsAddressInAccessList(address tosca.Address) bool {
+	return c.db.AddressInAccessList(common.Address(address))
+}
+
+func (c *transactionContext) IsSlotInAccessList(address tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	return c.db.SlotInAccessList(common.Address(address), common.Hash(key))
+}
+
+func (c *transactionContext) EmitLog(log tosca.Log) {
+	topics := make([]common.Hash, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = common.Hash(topic)
+	}
+	c.db.AddLog(&types.Log{
+		Address: common.Address(log.Address),
+		Topics:  topics,
+		Data:    log.Data,
+	})
+	c.logs = append(c.logs, log)
+}
+
+func (c *transactionContext) GetLogs() []tosca.Log {
+	return slices.Clone(c.logs)
+}
+
+func (c *transactionContext) GetBlockHash(number int64) tosca.Hash {
+	if c.blockHashes == nil {
+		return tosca.Hash{}
+	}
+	return c.blockHashes(number)
+}
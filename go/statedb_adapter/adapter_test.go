@@ -0,0 +1,320 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package statedb_adapter
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	_ "github.com/Fantom-foundation/Tosca/go/interpreter/lfvm"
+	_ "github.com/Fantom-foundation/Tosca/go/processor/floria"
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/prestate"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+func newStateDB(t *testing.T) *state.StateDB {
+	t.Helper()
+	db, err := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatalf("failed to create state: %v", err)
+	}
+	return db
+}
+
+// commit persists the modifications of the given state and returns a new
+// state on top of it, such that the modifications are committed state.
+func commit(t *testing.T, db *state.StateDB) *state.StateDB {
+	t.Helper()
+	root, err := db.Commit(0, false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	res, err := state.New(root, db.Database(), nil)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	return res
+}
+
+func TestTransactionContext_BalancesAreAdjusted(t *testing.T) {
+	db := newStateDB(t)
+	address := tosca.Address{1}
+	context := NewTransactionContext(db, tosca.R13_Cancun, nil)
+
+	for _, balance := range []uint64{5, 7, 2, 2, 0} {
+		context.SetBalance(address, tosca.NewValue(balance))
+		if want, got := balance, db.GetBalance(common.Address(address)).Uint64(); want != got {
+			t.Errorf("unexpected balance, wanted %d, got %d", want, got)
+		}
+		if want, got := tosca.NewValue(balance), context.GetBalance(address); want != got {
+			t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+		}
+	}
+}
+
+func TestTransactionContext_StorageStatusIsDerivedFromCommittedState(t *testing.T) {
+	db := newStateDB(t)
+	address, key := tosca.Address{1}, tosca.Key{2}
+	db.SetState(common.Address(address), common.Hash(key), common.Hash{3})
+	db = commit(t, db)
+
+	context := NewTransactionContext(db, tosca.R13_Cancun, nil)
+	if want, got := tosca.StorageModified, context.SetStorage(address, key, tosca.Word{4}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.StorageModifiedDeleted, context.SetStorage(address, key, tosca.Word{}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+	if want, got := (tosca.Word{3}), context.GetCommittedStorage(address, key); want != got {
+		t.Errorf("unexpected committed value, wanted %v, got %v", want, got)
+	}
+}
+
+func TestTransactionContext_RestoreSnapshotRevertsStateAndLogs(t *testing.T) {
+	db := newStateDB(t)
+	address := tosca.Address{1}
+	context := NewTransactionContext(db, tosca.R13_Cancun, nil)
+
+	context.EmitLog(tosca.Log{Address: address})
+	snapshot := context.CreateSnapshot()
+	context.SetNonce(address, 5)
+	context.SetStorage(address, tosca.Key{1}, tosca.Word{2})
+	context.EmitLog(tosca.Log{Address: address, Topics: []tosca.Hash{{1}}})
+	if want, got := 2, len(context.GetLogs()); want != got {
+		t.Fatalf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	context.RestoreSnapshot(snapshot)
+
+	if want, got := uint64(0), context.GetNonce(address); want != got {
+		t.Errorf("unexpected nonce, wanted %d, got %d", want, got)
+	}
+	if want, got := (tosca.Word{}), context.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	if want, got := 1, len(context.GetLogs()); want != got {
+		t.Errorf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, len(db.Logs()); want != got {
+		t.Errorf("unexpected number of logs in database, wanted %d, got %d", want, got)
+	}
+}
+
+func TestTransactionContext_AccessListIsTracked(t *testing.T) {
+	context := NewTransactionContext(newStateDB(t), tosca.R13_Cancun, nil)
+	address, key := tosca.Address{1}, tosca.Key{2}
+
+	if want, got := tosca.ColdAccess, context.AccessAccount(address); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.WarmAccess, context.AccessAccount(address); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.ColdAccess, context.AccessStorage(address, key); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.WarmAccess, context.AccessStorage(address, key); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	if addressPresent, slotPresent := context.IsSlotInAccessList(address, key); !addressPresent || !slotPresent {
+		t.Errorf("slot is missing in access list")
+	}
+}
+
+func TestTransactionContext_SelfDestructFollowsRevision(t *testing.T) {
+	tests := map[string]struct {
+		revision   tosca.Revision
+		created    bool
+		destructed bool
+	}{
+		"shanghai":       {tosca.R12_Shanghai, false, true},
+		"cancun":         {tosca.R13_Cancun, false, false},
+		"cancun-created": {tosca.R13_Cancun, true, true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db := newStateDB(t)
+			address, beneficiary := tosca.Address{1}, tosca.Address{2}
+			context := NewTransactionContext(db, test.revision, nil)
+			if test.created {
+				context.SetNonce(address, 1)
+			} else {
+				db.SetNonce(common.Address(address), 1)
+				db.SetCode(common.Address(address), []byte{byte(vm.STOP)})
+			}
+			db.AddBalance(common.Address(address), uint256.NewInt(10), tracing.BalanceChangeUnspecified)
+
+			if !context.SelfDestruct(address, beneficiary) {
+				t.Errorf("first self-destruct not reported")
+			}
+			if want, got := tosca.NewValue(10), context.GetBalance(beneficiary); want != got {
+				t.Errorf("unexpected balance of beneficiary, wanted %v, got %v", want, got)
+			}
+			if want, got := (tosca.Value{}), context.GetBalance(address); want != got {
+				t.Errorf("unexpected balance of destructed account, wanted %v, got %v", want, got)
+			}
+			if want, got := test.destructed, context.HasSelfDestructed(address); want != got {
+				t.Errorf("unexpected self-destruct status, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestTransactionContext_BlockHashesAreForwarded(t *testing.T) {
+	context := NewTransactionContext(newStateDB(t), tosca.R13_Cancun, nil)
+	if want, got := (tosca.Hash{}), context.GetBlockHash(5); want != got {
+		t.Errorf("unexpected block hash, wanted %v, got %v", want, got)
+	}
+	context = NewTransactionContext(newStateDB(t), tosca.R13_Cancun, func(number int64) tosca.Hash {
+		return tosca.Hash{byte(number)}
+	})
+	if want, got := (tosca.Hash{5}), context.GetBlockHash(5); want != got {
+		t.Errorf("unexpected block hash, wanted %v, got %v", want, got)
+	}
+}
+
+type archive map[uint64]StateDB
+
+func (a archive) GetArchiveState(block uint64) (StateDB, error) {
+	if db, found := a[block]; found {
+		return db, nil
+	}
+	return nil, errors.New("block not found")
+}
+
+func TestNewArchiveTransactionContext_UsesStateOfBlock(t *testing.T) {
+	db := newStateDB(t)
+	db.SetNonce(common.Address{1}, 3)
+
+	context, err := NewArchiveTransactionContext(archive{5: db}, 5, tosca.R13_Cancun, nil)
+	if err != nil {
+		t.Fatalf("failed to create context: %v", err)
+	}
+	if want, got := uint64(3), context.GetNonce(tosca.Address{1}); want != got {
+		t.Errorf("unexpected nonce, wanted %d, got %d", want, got)
+	}
+	if _, err := NewArchiveTransactionContext(archive{}, 5, tosca.R13_Cancun, nil); err == nil {
+		t.Errorf("missing block was not reported")
+	}
+}
+
+type bulkLoad struct {
+	*state.StateDB
+	closed bool
+}
+
+func (l *bulkLoad) SetBalance(address common.Address, value *uint256.Int) {
+	l.StateDB.SetBalance(address, value, tracing.BalanceChangeUnspecified)
+}
+
+func (l *bulkLoad) Close() error {
+	l.closed = true
+	return nil
+}
+
+func TestLoad_AddsAccountsToState(t *testing.T) {
+	load := &bulkLoad{StateDB: newStateDB(t)}
+	address := tosca.Address{1}
+	err := Load(load, prestate.State{
+		address: {
+			Balance: (*hexutil.Big)(big.NewInt(5)),
+			Nonce:   2,
+			Code:    []byte{byte(vm.STOP)},
+			Storage: map[common.Hash]common.Hash{{1}: {2}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to load state: %v", err)
+	}
+	if !load.closed {
+		t.Errorf("bulk load was not closed")
+	}
+
+	context := NewTransactionContext(load.StateDB, tosca.R13_Cancun, nil)
+	if want, got := tosca.NewValue(5), context.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := uint64(2), context.GetNonce(address); want != got {
+		t.Errorf("unexpected nonce, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, context.GetCodeSize(address); want != got {
+		t.Errorf("unexpected code size, wanted %d, got %d", want, got)
+	}
+	if want, got := (tosca.Word{2}), context.GetStorage(address, tosca.Key{1}); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+}
+
+func TestTransactionContext_ProcessorCanRunTransactionsOnStateDB(t *testing.T) {
+	interpreter, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	processor := tosca.GetProcessor("floria", interpreter)
+
+	sender, contract := tosca.Address{1}, tosca.Address{2}
+	db := newStateDB(t)
+	db.AddBalance(common.Address(sender), uint256.NewInt(1_000_000), tracing.BalanceChangeUnspecified)
+	// emits a log and creates a contract deploying an empty code
+	db.SetCode(common.Address(contract), []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.LOG0),
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.CREATE),
+		byte(vm.STOP),
+	})
+	db = commit(t, db)
+
+	receipt, err := processor.Run(context.Background(), tosca.BlockParameters{Revision: tosca.R13_Cancun},
+		tosca.Transaction{
+			Sender:    sender,
+			Recipient: &contract,
+			GasLimit:  100_000,
+			GasPrice:  tosca.NewValue(1),
+		},
+		NewTransactionContext(db, tosca.R13_Cancun, nil),
+	)
+	if err != nil {
+		t.Fatalf("failed to run transaction: %v", err)
+	}
+	if !receipt.Success {
+		t.Fatalf("transaction failed")
+	}
+	if want, got := 1, len(receipt.Logs); want != got {
+		t.Errorf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(1), db.GetNonce(common.Address(sender)); want != got {
+		t.Errorf("unexpected nonce of sender, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(1), db.GetNonce(common.Address(contract)); want != got {
+		t.Errorf("unexpected nonce of contract, wanted %d, got %d", want, got)
+	}
+	created := crypto.CreateAddress(common.Address(contract), 0)
+	if !db.Exist(created) || db.GetNonce(created) != 1 {
+		t.Errorf("created contract is missing")
+	}
+	if want, got := 1_000_000-uint64(receipt.GasUsed), db.GetBalance(common.Address(sender)).Uint64(); want != got {
+		t.Errorf("unexpected balance of sender, wanted %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,375 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package statedb_adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// Chain provides the chain specific information needed for processing the
+// blocks of a chain. It is typically implemented by combining go-ethereum's
+// core.BlockChain with its consensus engine.
+type Chain interface {
+	// GetHeader returns the header with the given hash and number, or nil if
+	// the header is unknown.
+	GetHeader(hash common.Hash, number uint64) *types.Header
+	// Author returns the beneficiary of the fees of the given block.
+	Author(header *types.Header) (common.Address, error)
+	// Finalize applies consensus engine specific modifications to the state
+	// at the end of a block, e.g. the payment of block rewards.
+	Finalize(header *types.Header, state *state.StateDB, body *types.Body)
+}
+
+// StateProcessor is a drop-in replacement of go-ethereum's
+// core.StateProcessor, implementing its core.Processor interface, which
+// delegates the execution of transactions to a Tosca processor. It enables
+// full go-ethereum nodes to validate Tosca processors while syncing a chain.
+// Block level operations, namely the DAO hard fork, the beacon root system
+// call of EIP-4788, and the finalization of blocks, are still performed by
+// go-ethereum.
+//
+// The results only agree with go-ethereum's if the processor implements the
+// rules of the synced chain. Sonic specific rules, for instance the charging
+// of unused gas, lead to deviations when syncing Ethereum. Blob transactions
+// are not supported, since Tosca transactions do not carry blob hashes.
+type StateProcessor struct {
+	config    *params.ChainConfig
+	chain     Chain
+	processor tosca.Processor
+}
+
+// NewStateProcessor creates a StateProcessor running the transactions of
+// the blocks of the given chain on the given processor, which is typically
+// obtained from the registry using tosca.GetProcessor.
+func NewStateProcessor(config *params.ChainConfig, chain Chain, processor tosca.Processor) *StateProcessor {
+	return &StateProcessor{
+		config:    config,
+		chain:     chain,
+		processor: processor,
+	}
+}
+
+var (
+	errBlobTransactionsNotSupported = errors.New("blob transactions are not supported")
+	errGasLimitReached              = errors.New("gas limit reached")
+	errTransactionSkipped           = errors.New("transaction skipped by processor")
+	errWithdrawalsBeforeShanghai    = errors.New("withdrawals before shanghai")
+)
+
+// Process runs the transactions of the given block on the given state and
+// returns the resulting receipts, logs and the gas used by the block, like
+// core.StateProcessor does. Transactions skipped by the processor, e.g.
+// because of an invalid nonce, render the block invalid. Since Tosca
+// processors do not support go-ethereum's tracing hooks, the tracer of the
+// given configuration is only informed about the beacon root system call.
+func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg vm.Config) (types.Receipts, []*types.Log, uint64, error) {
+	var (
+		receipts    types.Receipts
+		allLogs     []*types.Log
+		usedGas     uint64
+		header      = block.Header()
+		blockHash   = block.Hash()
+		blockNumber = block.Number()
+		gasPool     = block.GasLimit()
+	)
+
+	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(blockNumber) == 0 {
+		misc.ApplyDAOHardFork(statedb)
+	}
+
+	blockContext := p.newBlockContext(header)
+	if beaconRoot := block.BeaconRoot(); beaconRoot != nil {
+		p.processBeaconBlockRoot(*beaconRoot, blockContext, statedb, cfg)
+	}
+
+	rules := p.config.Rules(blockNumber, blockContext.Random != nil, header.Time)
+	blockParameters, err := p.getBlockParameters(blockContext, rules)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	signer := types.MakeSigner(p.config, blockNumber, header.Time)
+	for i, tx := range block.Transactions() {
+		statedb.SetTxContext(tx.Hash(), i)
+		receipt, err := p.applyTransaction(tx, signer, rules, blockParameters, blockContext, &gasPool, statedb)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+		usedGas += receipt.GasUsed
+		receipt.CumulativeGasUsed = usedGas
+		receipt.Logs = statedb.GetLogs(tx.Hash(), blockNumber.Uint64(), blockHash)
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+		receipt.BlockHash = blockHash
+		receipt.BlockNumber = blockNumber
+		receipt.TransactionIndex = uint(i)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+
+	if len(block.Withdrawals()) > 0 && !p.config.IsShanghai(blockNumber, block.Time()) {
+		return nil, nil, 0, errWithdrawalsBeforeShanghai
+	}
+	p.chain.Finalize(header, statedb, block.Body())
+
+	return receipts, allLogs, usedGas, nil
+}
+
+// applyTransaction runs a single transaction on the processor and returns
+// its receipt, lacking the block related fields filled in by Process.
+func (p *StateProcessor) applyTransaction(
+	tx *types.Transaction,
+	signer types.Signer,
+	rules params.Rules,
+	blockParameters tosca.BlockParameters,
+	blockContext vm.BlockContext,
+	gasPool *uint64,
+	statedb *state.StateDB,
+) (*types.Receipt, error) {
+	if tx.Type() == types.BlobTxType {
+		return nil, errBlobTransactionsNotSupported
+	}
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := toToscaTransaction(tx, tosca.Address(sender), blockContext.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	if *gasPool < tx.Gas() {
+		return nil, errGasLimitReached
+	}
+	*gasPool -= tx.Gas()
+
+	// The processor maintains the access list itself, however, the access
+	// list and the transient storage of the previous transaction need to be
+	// reset.
+	statedb.Prepare(rules, sender, blockContext.Coinbase, tx.To(), nil, nil)
+
+	blockHashes := func(number int64) tosca.Hash {
+		return tosca.Hash(blockContext.GetHash(uint64(number)))
+	}
+	txContext := NewTransactionContext(statedb, blockParameters.Revision, blockHashes)
+	result, err := p.processor.Run(context.Background(), blockParameters, transaction, txContext)
+	if err != nil {
+		return nil, err
+	}
+	// Every executed transaction consumes at least the intrinsic gas.
+	if result.GasUsed == 0 {
+		return nil, errTransactionSkipped
+	}
+	*gasPool += tx.Gas() - uint64(result.GasUsed)
+
+	var root []byte
+	if rules.IsByzantium {
+		statedb.Finalise(true)
+	} else {
+		root = statedb.IntermediateRoot(rules.IsEIP158).Bytes()
+	}
+
+	receipt := &types.Receipt{
+		Type:      tx.Type(),
+		PostState: root,
+		Status:    types.ReceiptStatusFailed,
+		TxHash:    tx.Hash(),
+		GasUsed:   uint64(result.GasUsed),
+	}
+	if result.Success {
+		receipt.Status = types.ReceiptStatusSuccessful
+	}
+	if result.ContractAddress != nil {
+		receipt.ContractAddress = common.Address(*result.ContractAddress)
+	}
+	return receipt, nil
+}
+
+// processBeaconBlockRoot stores the given beacon root in the beacon roots
+// contract of EIP-4788 using go-ethereum's EVM, like go-ethereum's
+// core.ProcessBeaconBlockRoot does.
+func (p *StateProcessor) processBeaconBlockRoot(beaconRoot common.Hash, blockContext vm.BlockContext, statedb *state.StateDB, cfg vm.Config) {
+	if cfg.Tracer != nil && cfg.Tracer.OnSystemCallStart != nil {
+		cfg.Tracer.OnSystemCallStart()
+	}
+	if cfg.Tracer != nil && cfg.Tracer.OnSystemCallEnd != nil {
+		defer cfg.Tracer.OnSystemCallEnd()
+	}
+	txContext := vm.TxContext{Origin: params.SystemAddress, GasPrice: new(big.Int)}
+	evm := vm.NewEVM(blockContext, txContext, statedb, p.config, cfg)
+	statedb.AddAddressToAccessList(params.BeaconRootsAddress)
+	_, _, _ = evm.Call(vm.AccountRef(params.SystemAddress), params.BeaconRootsAddress, beaconRoot[:], 30_000_000, new(uint256.Int))
+	statedb.Finalise(true)
+}
+
+// newBlockContext creates the go-ethereum block context of the given block,
+// like go-ethereum's core.NewEVMBlockContext does.
+func (p *StateProcessor) newBlockContext(header *types.Header) vm.BlockContext {
+	res := vm.BlockContext{
+		CanTransfer: canTransfer,
+		Transfer:    transfer,
+		GetHash:     getHashFn(header, p.chain),
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        header.Time,
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+		GasLimit:    header.GasLimit,
+	}
+	// The error is ignored, since headers are validated before processing.
+	res.Coinbase, _ = p.chain.Author(header)
+	if header.BaseFee != nil {
+		res.BaseFee = new(big.Int).Set(header.BaseFee)
+	}
+	if header.ExcessBlobGas != nil {
+		res.BlobBaseFee = eip4844.CalcBlobFee(*header.ExcessBlobGas)
+	}
+	if header.Difficulty.Sign() == 0 {
+		res.Random = &header.MixDigest
+	}
+	return res
+}
+
+func (p *StateProcessor) getBlockParameters(blockContext vm.BlockContext, rules params.Rules) (tosca.BlockParameters, error) {
+	revision, err := toRevision(rules)
+	if err != nil {
+		return tosca.BlockParameters{}, err
+	}
+	res := tosca.BlockParameters{
+		BlockNumber: blockContext.BlockNumber.Int64(),
+		Timestamp:   int64(blockContext.Time),
+		Coinbase:    tosca.Address(blockContext.Coinbase),
+		GasLimit:    tosca.Gas(blockContext.GasLimit),
+		Revision:    revision,
+	}
+	if p.config.ChainID != nil {
+		res.ChainID = tosca.Word(uint256.MustFromBig(p.config.ChainID).Bytes32())
+	}
+	if blockContext.Random != nil {
+		res.PrevRandao = tosca.Hash(*blockContext.Random)
+	}
+	if blockContext.BaseFee != nil {
+		res.BaseFee = tosca.ValueFromUint256(uint256.MustFromBig(blockContext.BaseFee))
+	}
+	if blockContext.BlobBaseFee != nil {
+		res.BlobBaseFee = tosca.ValueFromUint256(uint256.MustFromBig(blockContext.BlobBaseFee))
+	}
+	return res, nil
+}
+
+// getHashFn returns a function resolving the hashes of the ancestors of the
+// given header by walking the chain backwards, like go-ethereum's
+// core.GetHashFn does.
+func getHashFn(ref *types.Header, chain Chain) vm.GetHashFunc {
+	// cache[i] is the hash of the ancestor i+1 blocks before ref.
+	var cache []common.Hash
+	return func(n uint64) common.Hash {
+		number := ref.Number.Uint64()
+		if number <= n {
+			return common.Hash{}
+		}
+		if len(cache) == 0 {
+			cache = append(cache, ref.ParentHash)
+		}
+		for number-n-1 >= uint64(len(cache)) {
+			last := uint64(len(cache))
+			header := chain.GetHeader(cache[last-1], number-last)
+			if header == nil {
+				return common.Hash{}
+			}
+			cache = append(cache, header.ParentHash)
+		}
+		return cache[number-n-1]
+	}
+}
+
+// toToscaTransaction converts the given transaction, executed in a block
+// with the given base fee, to its Tosca counterpart.
+func toToscaTransaction(tx *types.Transaction, sender tosca.Address, baseFee *big.Int) (tosca.Transaction, error) {
+	value, overflow := uint256.FromBig(tx.Value())
+	if overflow {
+		return tosca.Transaction{}, fmt.Errorf("value overflow")
+	}
+	gasPrice := tx.GasPrice()
+	if baseFee != nil {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			return tosca.Transaction{}, err
+		}
+		gasPrice = tip.Add(tip, baseFee)
+	}
+	price, overflow := uint256.FromBig(gasPrice)
+	if overflow {
+		return tosca.Transaction{}, fmt.Errorf("gas price overflow")
+	}
+	if tx.Gas() > uint64(tosca.UnlimitedGasLimit) {
+		return tosca.Transaction{}, fmt.Errorf("gas limit overflow")
+	}
+	var recipient *tosca.Address
+	if to := tx.To(); to != nil {
+		recipient = (*tosca.Address)(to)
+	}
+	accessList := []tosca.AccessTuple{}
+	for _, tuple := range tx.AccessList() {
+		keys := make([]tosca.Key, len(tuple.StorageKeys))
+		for i, key := range tuple.StorageKeys {
+			keys[i] = tosca.Key(key)
+		}
+		accessList = append(accessList, tosca.AccessTuple{Address: tosca.Address(tuple.Address), Keys: keys})
+	}
+	return tosca.Transaction{
+		Sender:     sender,
+		Recipient:  recipient,
+		Nonce:      tx.Nonce(),
+		Input:      tx.Data(),
+		Value:      tosca.ValueFromUint256(value),
+		GasLimit:   tosca.Gas(tx.Gas()),
+		GasPrice:   tosca.ValueFromUint256(price),
+		AccessList: accessList,
+	}, nil
+}
+
+func toRevision(rules params.Rules) (tosca.Revision, error) {
+	switch {
+	case rules.IsCancun:
+		return tosca.R13_Cancun, nil
+	case rules.IsShanghai:
+		return tosca.R12_Shanghai, nil
+	case rules.IsMerge:
+		return tosca.R11_Paris, nil
+	case rules.IsLondon:
+		return tosca.R10_London, nil
+	case rules.IsBerlin:
+		return tosca.R09_Berlin, nil
+	case rules.IsIstanbul:
+		return tosca.R07_Istanbul, nil
+	}
+	return 0, &tosca.ErrUnsupportedRevision{Revision: tosca.Revision(-1)}
+}
+
+func canTransfer(db vm.StateDB, address common.Address, amount *uint256.Int) bool {
+	return db.GetBalance(address).Cmp(amount) >= 0
+}
+
+func transfer(db vm.StateDB, sender, recipient common.Address, amount *uint256.Int) {
+	db.SubBalance(sender, amount, tracing.BalanceChangeTransfer)
+	db.AddBalance(recipient, amount, tracing.BalanceChangeTransfer)
+}
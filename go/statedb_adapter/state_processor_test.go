@@ -0,0 +1,232 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package statedb_adapter
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/types"
+	geth "github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// testChain is a chain of headers finalizing blocks by paying a fixed
+// reward to the author of the block.
+type testChain struct {
+	headers   map[common.Hash]*types.Header
+	finalized []*types.Header
+}
+
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, found := c.headers[hash]; found && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *testChain) Author(header *types.Header) (common.Address, error) {
+	return header.Coinbase, nil
+}
+
+func (c *testChain) Finalize(header *types.Header, state *state.StateDB, _ *types.Body) {
+	c.finalized = append(c.finalized, header)
+	state.AddBalance(header.Coinbase, uint256.NewInt(1), tracing.BalanceIncreaseRewardMineBlock)
+}
+
+// newTestChain creates a chain of the given number of headers and returns
+// the chain together with a header for the next block.
+func newTestChain(length int) (*testChain, *types.Header) {
+	chain := &testChain{headers: map[common.Hash]*types.Header{}}
+	next := &types.Header{
+		Number:     new(big.Int),
+		Difficulty: new(big.Int),
+		GasLimit:   1_000_000,
+		BaseFee:    big.NewInt(1),
+		Coinbase:   common.Address{0xc0},
+	}
+	for i := 0; i < length; i++ {
+		header := types.CopyHeader(next)
+		chain.headers[header.Hash()] = header
+		next.Number = big.NewInt(int64(i + 1))
+		next.ParentHash = header.Hash()
+		next.Time = uint64(i + 1)
+	}
+	return chain, next
+}
+
+func newStateProcessor(t *testing.T, chain Chain) *StateProcessor {
+	t.Helper()
+	interpreter, err := tosca.NewInterpreter("lfvm")
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	return NewStateProcessor(params.MergedTestChainConfig, chain, tosca.GetProcessor("floria", interpreter))
+}
+
+func signTransaction(t *testing.T, key *ecdsa.PrivateKey, tx *types.DynamicFeeTx) *types.Transaction {
+	t.Helper()
+	tx.ChainID = params.MergedTestChainConfig.ChainID
+	tx.GasTipCap = big.NewInt(1)
+	tx.GasFeeCap = big.NewInt(2)
+	signer := types.LatestSigner(params.MergedTestChainConfig)
+	res, err := types.SignNewTx(key, signer, tx)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	return res
+}
+
+func TestStateProcessor_RunsTransactionsOfBlockOnProcessor(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	recipient, contract := common.Address{1}, common.Address{2}
+
+	db := newStateDB(t)
+	db.AddBalance(sender, uint256.NewInt(1_000_000), tracing.BalanceChangeUnspecified)
+	// emits a log with the value of the block hash of block 0
+	db.SetCode(contract, []byte{
+		byte(vm.PUSH1), 0,
+		byte(vm.BLOCKHASH),
+		byte(vm.PUSH1), 0,
+		byte(vm.PUSH1), 0,
+		byte(vm.LOG1),
+	})
+	db = commit(t, db)
+
+	chain, header := newTestChain(3)
+	block := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{
+		signTransaction(t, key, &types.DynamicFeeTx{Nonce: 0, To: &recipient, Value: big.NewInt(5), Gas: 21_000}),
+		signTransaction(t, key, &types.DynamicFeeTx{Nonce: 1, To: &contract, Gas: 50_000}),
+	}})
+
+	receipts, logs, usedGas, err := newStateProcessor(t, chain).Process(block, db, geth.Config{})
+	if err != nil {
+		t.Fatalf("failed to process block: %v", err)
+	}
+
+	if want, got := 2, len(receipts); want != got {
+		t.Fatalf("unexpected number of receipts, wanted %d, got %d", want, got)
+	}
+	for i, receipt := range receipts {
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			t.Errorf("transaction %d failed", i)
+		}
+		if want, got := block.Transactions()[i].Hash(), receipt.TxHash; want != got {
+			t.Errorf("unexpected transaction hash, wanted %v, got %v", want, got)
+		}
+		if want, got := block.Hash(), receipt.BlockHash; want != got {
+			t.Errorf("unexpected block hash, wanted %v, got %v", want, got)
+		}
+		if want, got := uint(i), receipt.TransactionIndex; want != got {
+			t.Errorf("unexpected transaction index, wanted %d, got %d", want, got)
+		}
+	}
+	if want, got := receipts[0].GasUsed+receipts[1].GasUsed, usedGas; want != got {
+		t.Errorf("unexpected gas usage of block, wanted %d, got %d", want, got)
+	}
+	if want, got := usedGas, receipts[1].CumulativeGasUsed; want != got {
+		t.Errorf("unexpected cumulative gas usage, wanted %d, got %d", want, got)
+	}
+
+	if want, got := 1, len(logs); want != got {
+		t.Fatalf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	genesis := header.ParentHash
+	for chain.headers[genesis].Number.Sign() != 0 {
+		genesis = chain.headers[genesis].ParentHash
+	}
+	if want, got := genesis, logs[0].Topics[0]; want != got {
+		t.Errorf("unexpected block hash, wanted %v, got %v", want, got)
+	}
+	if want, got := receipts[1].TxHash, logs[0].TxHash; want != got {
+		t.Errorf("unexpected transaction hash of log, wanted %v, got %v", want, got)
+	}
+
+	if want, got := uint64(5), db.GetBalance(recipient).Uint64(); want != got {
+		t.Errorf("unexpected balance of recipient, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(2), db.GetNonce(sender); want != got {
+		t.Errorf("unexpected nonce of sender, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, len(chain.finalized); want != got {
+		t.Errorf("unexpected number of finalized blocks, wanted %d, got %d", want, got)
+	}
+}
+
+func TestStateProcessor_SkippedTransactionsRenderBlockInvalid(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	db := newStateDB(t)
+	db.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt(1_000_000), tracing.BalanceChangeUnspecified)
+
+	chain, header := newTestChain(1)
+	block := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{
+		signTransaction(t, key, &types.DynamicFeeTx{Nonce: 1, To: &common.Address{1}, Gas: 21_000}),
+	}})
+
+	_, _, _, err = newStateProcessor(t, chain).Process(block, db, geth.Config{})
+	if !errors.Is(err, errTransactionSkipped) {
+		t.Errorf("unexpected error, wanted %v, got %v", errTransactionSkipped, err)
+	}
+}
+
+func TestStateProcessor_GasLimitOfBlockIsEnforced(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	db := newStateDB(t)
+	db.AddBalance(crypto.PubkeyToAddress(key.PublicKey), uint256.NewInt(10_000_000), tracing.BalanceChangeUnspecified)
+
+	chain, header := newTestChain(1)
+	block := types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: []*types.Transaction{
+		signTransaction(t, key, &types.DynamicFeeTx{Nonce: 0, To: &common.Address{1}, Gas: header.GasLimit + 1}),
+	}})
+
+	_, _, _, err = newStateProcessor(t, chain).Process(block, db, geth.Config{})
+	if !errors.Is(err, errGasLimitReached) {
+		t.Errorf("unexpected error, wanted %v, got %v", errGasLimitReached, err)
+	}
+}
+
+func TestGetHashFn_ResolvesHashesOfAncestors(t *testing.T) {
+	chain, header := newTestChain(5)
+	getHash := getHashFn(header, chain)
+
+	want := map[uint64]common.Hash{}
+	for hash, ancestor := range chain.headers {
+		want[ancestor.Number.Uint64()] = hash
+	}
+	// Hashes are queried in an order exercising the cache.
+	for _, number := range []uint64{3, 0, 4, 1, 2} {
+		if got := getHash(number); want[number] != got {
+			t.Errorf("unexpected hash of block %d, wanted %v, got %v", number, want[number], got)
+		}
+	}
+	if got := getHash(header.Number.Uint64()); got != (common.Hash{}) {
+		t.Errorf("hash of current block should be unknown, got %v", got)
+	}
+}
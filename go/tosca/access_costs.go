@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+// Costs of accessing accounts and storage slots introduced with Berlin by
+// EIP-2929 (https://eips.ethereum.org/EIPS/eip-2929). An access is cold if the
+// account or slot is accessed for the first time in a transaction and warm
+// otherwise. The set of accessed accounts and slots is owned by the
+// TransactionContext, which reports the status of each access.
+const (
+	ColdAccountAccessCost Gas = 2600
+	ColdStorageAccessCost Gas = 2100
+	WarmAccessCost        Gas = 100
+)
+
+// AccessCostsApply reports whether the costs of EIP-2929 are charged for
+// accesses in the given revision.
+func AccessCostsApply(revision Revision) bool {
+	return revision >= R09_Berlin
+}
+
+// AccountAccessCost returns the costs of an account access of the given
+// status, as charged by BALANCE, EXTCODESIZE, EXTCODECOPY, EXTCODEHASH, and
+// the CALL instructions.
+func AccountAccessCost(status AccessStatus) Gas {
+	if status == WarmAccess {
+		return WarmAccessCost
+	}
+	return ColdAccountAccessCost
+}
+
+// StorageAccessCost returns the costs of a storage slot access of the given
+// status, as charged by SLOAD.
+func StorageAccessCost(status AccessStatus) Gas {
+	if status == WarmAccess {
+		return WarmAccessCost
+	}
+	return ColdStorageAccessCost
+}
+
+// ColdAccountAccessSurcharge returns the costs charged in addition to the
+// regular costs of SELFDESTRUCT for accessing its beneficiary with the given
+// status. Warm accesses are free.
+func ColdAccountAccessSurcharge(status AccessStatus) Gas {
+	if status == WarmAccess {
+		return 0
+	}
+	return ColdAccountAccessCost
+}
+
+// ColdStorageAccessSurcharge returns the costs charged in addition to the
+// costs defined by the GasSchedule for an SSTORE accessing a slot with the
+// given status. Warm accesses are covered by the schedule.
+func ColdStorageAccessSurcharge(status AccessStatus) Gas {
+	if status == WarmAccess {
+		return 0
+	}
+	return ColdStorageAccessCost
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "testing"
+
+func TestAccessCostsApply_StartingWithBerlin(t *testing.T) {
+	for _, revision := range GetAllKnownRevisions() {
+		if want, got := revision >= R09_Berlin, AccessCostsApply(revision); want != got {
+			t.Errorf("unexpected result for %v, wanted %t, got %t", revision, want, got)
+		}
+	}
+}
+
+func TestAccessCosts_DependOnAccessStatus(t *testing.T) {
+	tests := map[string]struct {
+		cost       func(AccessStatus) Gas
+		warm, cold Gas
+	}{
+		"account access":           {cost: AccountAccessCost, warm: 100, cold: 2600},
+		"storage access":           {cost: StorageAccessCost, warm: 100, cold: 2100},
+		"account access surcharge": {cost: ColdAccountAccessSurcharge, warm: 0, cold: 2600},
+		"storage access surcharge": {cost: ColdStorageAccessSurcharge, warm: 0, cold: 2100},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, got := test.warm, test.cost(WarmAccess); want != got {
+				t.Errorf("unexpected costs of warm access, wanted %d, got %d", want, got)
+			}
+			if want, got := test.cold, test.cost(ColdAccess); want != got {
+				t.Errorf("unexpected costs of cold access, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+// AccessStatistics summarizes the accesses of a transaction to the world
+// state, as needed for estimating the load a transaction puts on the state
+// database and the size of a witness for its execution. Accesses performed
+// by calls which got reverted are included, since the respective state had
+// to be loaded nevertheless.
+type AccessStatistics struct {
+	AccountsRead    int // the number of distinct accounts whose state got read
+	AccountsWritten int // the number of distinct accounts whose state got modified
+	SlotsRead       int // the number of distinct storage slots read
+	SlotsWritten    int // the number of distinct storage slots modified
+	CodeBytesLoaded int // the total size of all loaded codes, counting each account once
+
+	ColdAccountAccesses int // the number of accesses to accounts not in the access list
+	WarmAccountAccesses int // the number of accesses to accounts in the access list
+	ColdSlotAccesses    int // the number of accesses to slots not in the access list
+	WarmSlotAccesses    int // the number of accesses to slots in the access list
+}
+
+// NewAccessStatisticsTransactionContext wraps the given context such that
+// all accesses to the world state and the access list are recorded in the
+// given statistics. It is intended to be used by processors supporting the
+// AccessStatistics simulation option.
+func NewAccessStatisticsTransactionContext(context TransactionContext, statistics *AccessStatistics) TransactionContext {
+	return &accessStatisticsContext{
+		TransactionContext: context,
+		statistics:         statistics,
+		accountsRead:       map[Address]struct{}{},
+		accountsWritten:    map[Address]struct{}{},
+		slotsRead:          map[slotId]struct{}{},
+		slotsWritten:       map[slotId]struct{}{},
+		codesLoaded:        map[Address]struct{}{},
+	}
+}
+
+type slotId struct {
+	address Address
+	key     Key
+}
+
+type accessStatisticsContext struct {
+	TransactionContext
+	statistics      *AccessStatistics
+	accountsRead    map[Address]struct{}
+	accountsWritten map[Address]struct{}
+	slotsRead       map[slotId]struct{}
+	slotsWritten    map[slotId]struct{}
+	codesLoaded     map[Address]struct{}
+}
+
+// record adds the given element to the given set and increments the given
+// counter if it was not yet present.
+func record[K comparable](set map[K]struct{}, element K, counter *int) {
+	if _, found := set[element]; !found {
+		set[element] = struct{}{}
+		*counter++
+	}
+}
+
+func (c *accessStatisticsContext) readAccount(address Address) {
+	record(c.accountsRead, address, &c.statistics.AccountsRead)
+}
+
+func (c *accessStatisticsContext) writeAccount(address Address) {
+	record(c.accountsWritten, address, &c.statistics.AccountsWritten)
+}
+
+func (c *accessStatisticsContext) AccountExists(address Address) bool {
+	c.readAccount(address)
+	return c.TransactionContext.AccountExists(address)
+}
+
+func (c *accessStatisticsContext) GetBalance(address Address) Value {
+	c.readAccount(address)
+	return c.TransactionContext.GetBalance(address)
+}
+
+func (c *accessStatisticsContext) SetBalance(address Address, value Value) {
+	c.writeAccount(address)
+	c.TransactionContext.SetBalance(address, value)
+}
+
+func (c *accessStatisticsContext) RemoveAccount(address Address) {
+	c.writeAccount(address)
+	RemoveAccount(c.TransactionContext, address)
+}
+
+func (c *accessStatisticsContext) GetNonce(address Address) uint64 {
+	c.readAccount(address)
+	return c.TransactionContext.GetNonce(address)
+}
+
+func (c *accessStatisticsContext) SetNonce(address Address, nonce uint64) {
+	c.writeAccount(address)
+	c.TransactionContext.SetNonce(address, nonce)
+}
+
+func (c *accessStatisticsContext) GetCode(address Address) Code {
+	c.readAccount(address)
+	code := c.TransactionContext.GetCode(address)
+	if _, found := c.codesLoaded[address]; !found {
+		c.codesLoaded[address] = struct{}{}
+		c.statistics.CodeBytesLoaded += len(code)
+	}
+	return code
+}
+
+func (c *accessStatisticsContext) GetCodeHash(address Address) Hash {
+	c.readAccount(address)
+	return c.TransactionContext.GetCodeHash(address)
+}
+
+func (c *accessStatisticsContext) GetCodeSize(address Address) int {
+	c.readAccount(address)
+	return c.TransactionContext.GetCodeSize(address)
+}
+
+func (c *accessStatisticsContext) SetCode(address Address, code Code) {
+	c.writeAccount(address)
+	c.TransactionContext.SetCode(address, code)
+}
+
+func (c *accessStatisticsContext) GetStorage(address Address, key Key) Word {
+	record(c.slotsRead, slotId{address, key}, &c.statistics.SlotsRead)
+	return c.TransactionContext.GetStorage(address, key)
+}
+
+func (c *accessStatisticsContext) GetCommittedStorage(address Address, key Key) Word {
+	record(c.slotsRead, slotId{address, key}, &c.statistics.SlotsRead)
+	return c.TransactionContext.GetCommittedStorage(address, key)
+}
+
+func (c *accessStatisticsContext) SetStorage(address Address, key Key, value Word) StorageStatus {
+	record(c.slotsWritten, slotId{address, key}, &c.statistics.SlotsWritten)
+	return c.TransactionContext.SetStorage(address, key, value)
+}
+
+func (c *accessStatisticsContext) SelfDestruct(address Address, beneficiary Address) bool {
+	c.writeAccount(address)
+	c.writeAccount(beneficiary)
+	return c.TransactionContext.SelfDestruct(address, beneficiary)
+}
+
+func (c *accessStatisticsContext) AccessAccount(address Address) AccessStatus {
+	status := c.TransactionContext.AccessAccount(address)
+	if status == ColdAccess {
+		c.statistics.ColdAccountAccesses++
+	} else {
+		c.statistics.WarmAccountAccesses++
+	}
+	return status
+}
+
+func (c *accessStatisticsContext) AccessStorage(address Address, key Key) AccessStatus {
+	status := c.TransactionContext.AccessStorage(address, key)
+	if status == ColdAccess {
+		c.statistics.ColdSlotAccesses++
+	} else {
+		c.statistics.WarmSlotAccesses++
+	}
+	return status
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAccessStatisticsContext_CountsDistinctAccesses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockTransactionContext(ctrl)
+	inner.EXPECT().GetBalance(gomock.Any()).AnyTimes()
+	inner.EXPECT().GetNonce(gomock.Any()).AnyTimes()
+	inner.EXPECT().SetNonce(gomock.Any(), gomock.Any()).AnyTimes()
+	inner.EXPECT().GetCode(Address{2}).Return(Code{1, 2, 3}).AnyTimes()
+	inner.EXPECT().GetStorage(gomock.Any(), gomock.Any()).AnyTimes()
+	inner.EXPECT().GetCommittedStorage(gomock.Any(), gomock.Any()).AnyTimes()
+	inner.EXPECT().SetStorage(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	inner.EXPECT().SelfDestruct(gomock.Any(), gomock.Any()).AnyTimes()
+
+	statistics := &AccessStatistics{}
+	context := NewAccessStatisticsTransactionContext(inner, statistics)
+	context.GetBalance(Address{1})
+	context.GetNonce(Address{1})
+	context.SetNonce(Address{1}, 1)
+	context.GetCode(Address{2})
+	context.GetCode(Address{2})
+	context.GetStorage(Address{2}, Key{1})
+	context.GetCommittedStorage(Address{2}, Key{1})
+	context.GetStorage(Address{2}, Key{2})
+	context.SetStorage(Address{2}, Key{2}, Word{1})
+	context.SelfDestruct(Address{3}, Address{4})
+
+	want := AccessStatistics{
+		AccountsRead:    2,
+		AccountsWritten: 3,
+		SlotsRead:       2,
+		SlotsWritten:    1,
+		CodeBytesLoaded: 3,
+	}
+	if want != *statistics {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, *statistics)
+	}
+}
+
+func TestAccessStatisticsContext_CountsColdAndWarmAccesses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockTransactionContext(ctrl)
+	gomock.InOrder(
+		inner.EXPECT().AccessAccount(Address{1}).Return(ColdAccess),
+		inner.EXPECT().AccessAccount(Address{1}).Return(WarmAccess),
+		inner.EXPECT().AccessStorage(Address{1}, Key{1}).Return(ColdAccess),
+		inner.EXPECT().AccessStorage(Address{1}, Key{1}).Return(WarmAccess),
+		inner.EXPECT().AccessStorage(Address{1}, Key{1}).Return(WarmAccess),
+	)
+
+	statistics := &AccessStatistics{}
+	context := NewAccessStatisticsTransactionContext(inner, statistics)
+	for _, want := range []AccessStatus{ColdAccess, WarmAccess} {
+		if got := context.AccessAccount(Address{1}); want != got {
+			t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+		}
+	}
+	for _, want := range []AccessStatus{ColdAccess, WarmAccess, WarmAccess} {
+		if got := context.AccessStorage(Address{1}, Key{1}); want != got {
+			t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+		}
+	}
+
+	want := AccessStatistics{
+		ColdAccountAccesses: 1,
+		WarmAccountAccesses: 1,
+		ColdSlotAccesses:    1,
+		WarmSlotAccesses:    2,
+	}
+	if want != *statistics {
+		t.Errorf("unexpected statistics, wanted %+v, got %+v", want, *statistics)
+	}
+}
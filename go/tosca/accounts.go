@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "slices"
+
+// AccountState provides read access to the properties of accounts determining
+// whether an account is empty. It is implemented by any WorldState.
+type AccountState interface {
+	GetBalance(Address) Value
+	GetNonce(Address) uint64
+	GetCodeSize(Address) int
+}
+
+// IsEmptyAccount reports whether the given account is empty. As defined by
+// EIP-161 (https://eips.ethereum.org/EIPS/eip-161), an account is empty if it
+// has a zero balance, a zero nonce, and no code. Non-existing accounts are
+// empty as well. EIP-161 was introduced with Spurious Dragon and is thus in
+// effect in all revisions supported by Tosca: empty accounts are treated like
+// non-existing accounts, for instance when charging for the creation of
+// accounts through value transfers, and are removed once touched.
+func IsEmptyAccount(state AccountState, address Address) bool {
+	return state.GetNonce(address) == 0 &&
+		state.GetBalance(address) == (Value{}) &&
+		state.GetCodeSize(address) == 0
+}
+
+// AccountRemover is an optional extension of transaction contexts supporting
+// the removal of accounts from the state. Processors use it to remove the
+// dead accounts touched by a transaction at the end of its execution (see
+// TouchedAccounts).
+type AccountRemover interface {
+	// RemoveAccount removes the given account, including its storage, from
+	// the state. Removing a non-existing account has no effect.
+	RemoveAccount(Address)
+}
+
+// RemoveAccount removes the given account from the given context if the
+// context supports the removal of accounts. Otherwise, it has no effect.
+func RemoveAccount(context TransactionContext, address Address) {
+	if remover, ok := context.(AccountRemover); ok {
+		remover.RemoveAccount(address)
+	}
+}
+
+// TouchedAccounts tracks the accounts touched during the execution of a
+// transaction. Since EIP-161, touched accounts that are empty at the end of
+// a transaction are removed from the state. An account is touched when it is
+// the target of a call or a value transfer, even if the transferred value is
+// zero, or the beneficiary of a self-destruct. The zero value is an empty set
+// ready to use.
+type TouchedAccounts struct {
+	touched map[Address]struct{}
+}
+
+// Touch marks the given account as touched.
+func (t *TouchedAccounts) Touch(address Address) {
+	if t.touched == nil {
+		t.touched = map[Address]struct{}{}
+	}
+	t.touched[address] = struct{}{}
+}
+
+// IsTouched reports whether the given account has been touched.
+func (t *TouchedAccounts) IsTouched(address Address) bool {
+	_, found := t.touched[address]
+	return found
+}
+
+// GetDeadAccounts returns the touched accounts to be removed at the end of a
+// transaction, which are the touched empty accounts, sorted by address.
+func (t *TouchedAccounts) GetDeadAccounts(state AccountState) []Address {
+	res := []Address{}
+	for address := range t.touched {
+		if IsEmptyAccount(state, address) {
+			res = append(res, address)
+		}
+	}
+	slices.SortFunc(res, func(a, b Address) int {
+		return slices.Compare(a[:], b[:])
+	})
+	return res
+}
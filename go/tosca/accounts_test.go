@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestIsEmptyAccount_AccountsWithoutBalanceNonceAndCodeAreEmpty(t *testing.T) {
+	tests := map[string]struct {
+		balance  Value
+		nonce    uint64
+		codeSize int
+		want     bool
+	}{
+		"empty":    {want: true},
+		"balance":  {balance: Value{31: 1}},
+		"nonce":    {nonce: 1},
+		"code":     {codeSize: 1},
+		"all set":  {balance: Value{1}, nonce: 2, codeSize: 3},
+		"big code": {codeSize: 24_576},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			state := NewMockWorldState(ctrl)
+			address := Address{1}
+			state.EXPECT().GetBalance(address).Return(test.balance).AnyTimes()
+			state.EXPECT().GetNonce(address).Return(test.nonce).AnyTimes()
+			state.EXPECT().GetCodeSize(address).Return(test.codeSize).AnyTimes()
+
+			if want, got := test.want, IsEmptyAccount(state, address); want != got {
+				t.Errorf("unexpected result, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
+
+func TestTouchedAccounts_TracksTouchedAccounts(t *testing.T) {
+	touched := TouchedAccounts{}
+	if touched.IsTouched(Address{1}) {
+		t.Errorf("account should not be touched")
+	}
+	touched.Touch(Address{1})
+	touched.Touch(Address{1})
+	if !touched.IsTouched(Address{1}) {
+		t.Errorf("account should be touched")
+	}
+	if touched.IsTouched(Address{2}) {
+		t.Errorf("account should not be touched")
+	}
+}
+
+func TestTouchedAccounts_GetDeadAccountsReturnsTouchedEmptyAccounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := NewMockWorldState(ctrl)
+	state.EXPECT().GetBalance(gomock.Any()).Return(Value{}).AnyTimes()
+	state.EXPECT().GetCodeSize(gomock.Any()).Return(0).AnyTimes()
+	state.EXPECT().GetNonce(gomock.Any()).DoAndReturn(func(address Address) uint64 {
+		// Accounts with an even first byte are empty.
+		return uint64(address[0] % 2)
+	}).AnyTimes()
+
+	touched := TouchedAccounts{}
+	for _, address := range []Address{{4}, {3}, {2}, {1}} {
+		touched.Touch(address)
+	}
+
+	want := []Address{{2}, {4}}
+	if got := touched.GetDeadAccounts(state); !slices.Equal(want, got) {
+		t.Errorf("unexpected dead accounts, wanted %v, got %v", want, got)
+	}
+}
+
+type removingContext struct {
+	TransactionContext
+	removed []Address
+}
+
+func (c *removingContext) RemoveAccount(address Address) {
+	c.removed = append(c.removed, address)
+}
+
+func TestRemoveAccount_IsForwardedThroughWrappingContexts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	contexts := map[string]func(TransactionContext) TransactionContext{
+		"plain": func(c TransactionContext) TransactionContext { return c },
+		"tracing": func(c TransactionContext) TransactionContext {
+			return NewTracingTransactionContext(c, NewMockTracer(ctrl))
+		},
+		"access statistics": func(c TransactionContext) TransactionContext {
+			return NewAccessStatisticsTransactionContext(c, &AccessStatistics{})
+		},
+		"log streaming": func(c TransactionContext) TransactionContext {
+			return NewLogStreamingTransactionContext(c, func(LogEvent) {})
+		},
+		"state overrides": func(c TransactionContext) TransactionContext {
+			wrapped, err := StateOverrides{
+				Address{1}: {StateDiff: map[Key]Word{{1}: {2}}},
+			}.Apply(c)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return wrapped
+		},
+	}
+	for name, wrap := range contexts {
+		t.Run(name, func(t *testing.T) {
+			context := &removingContext{TransactionContext: NewMockTransactionContext(ctrl)}
+			RemoveAccount(wrap(context), Address{1})
+			if want, got := []Address{{1}}, context.removed; !slices.Equal(want, got) {
+				t.Errorf("unexpected removed accounts, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestRemoveAccount_IsIgnoredByContextsNotSupportingIt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+	// The mock fails the test on any unexpected call.
+	RemoveAccount(context, Address{1})
+	RemoveAccount(NewTracingTransactionContext(context, NewMockTracer(ctrl)), Address{1})
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package address provides the derivation of the addresses of contracts
+// created by transactions and by the CREATE and CREATE2 instructions.
+package address
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"golang.org/x/crypto/sha3"
+)
+
+// Create computes the address of a contract created by the given sender
+// using the given nonce, as done by contract creating transactions and the
+// CREATE instruction. The address is derived from the Keccak256 hash of the
+// RLP encoding of the list [sender, nonce].
+func Create(sender tosca.Address, nonce uint64) tosca.Address {
+	// The encoding is short enough for single-byte list and string headers.
+	var buffer [1 + 1 + len(sender) + 1 + 8]byte
+	data := append(buffer[:0], 0, 0x80+byte(len(sender)))
+	data = append(data, sender[:]...)
+	switch {
+	case nonce == 0:
+		data = append(data, 0x80)
+	case nonce < 0x80:
+		data = append(data, byte(nonce))
+	default:
+		var encoded [8]byte
+		binary.BigEndian.PutUint64(encoded[:], nonce)
+		trimmed := encoded[bits.LeadingZeros64(nonce)/8:]
+		data = append(data, 0x80+byte(len(trimmed)))
+		data = append(data, trimmed...)
+	}
+	data[0] = 0xc0 + byte(len(data)-1)
+	return toAddress(keccak256(data))
+}
+
+// Create2 computes the address of a contract created by the given sender
+// using the CREATE2 instruction with the given salt and the hash of the init
+// code, as defined by EIP-1014. It is equivalent to
+// tosca.ComputeCreate2Address.
+func Create2(sender tosca.Address, salt tosca.Hash, initCodeHash tosca.Hash) tosca.Address {
+	return tosca.ComputeCreate2Address(sender, salt, initCodeHash)
+}
+
+// Create2WithInitCode is like Create2, but hashes the given init code.
+func Create2WithInitCode(sender tosca.Address, salt tosca.Hash, initCode []byte) tosca.Address {
+	return Create2(sender, salt, keccak256(initCode))
+}
+
+func keccak256(data ...[]byte) (res tosca.Hash) {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, cur := range data {
+		_, _ = hasher.Write(cur) // Hash.Write never returns an error
+	}
+	hasher.Sum(res[:0])
+	return res
+}
+
+func toAddress(hash tosca.Hash) (res tosca.Address) {
+	copy(res[:], hash[12:])
+	return res
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package address
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCreate_MatchesGethDerivation(t *testing.T) {
+	senders := []tosca.Address{{}, {1}, {0xff, 2: 0x12, 19: 0x34}}
+	nonces := []uint64{0, 1, 0x7f, 0x80, 0xff, 0x100, 1 << 32, math.MaxUint64}
+	for _, sender := range senders {
+		for _, nonce := range nonces {
+			want := tosca.Address(crypto.CreateAddress(common.Address(sender), nonce))
+			if got := Create(sender, nonce); want != got {
+				t.Errorf("unexpected address for sender %v and nonce %d, wanted %v, got %v", sender, nonce, want, got)
+			}
+		}
+	}
+}
+
+func TestCreate2_MatchesGethDerivation(t *testing.T) {
+	senders := []tosca.Address{{}, {1}, {0xff, 19: 0x34}}
+	salts := []tosca.Hash{{}, {1}, {31: 0xff}}
+	initCodes := [][]byte{nil, {0}, {0x60, 0x00, 0x60, 0x00, 0xf3}}
+	for _, sender := range senders {
+		for _, salt := range salts {
+			for _, initCode := range initCodes {
+				want := tosca.Address(crypto.CreateAddress2(common.Address(sender), common.Hash(salt), crypto.Keccak256(initCode)))
+				if got := Create2(sender, salt, tosca.Hash(crypto.Keccak256(initCode))); want != got {
+					t.Errorf("unexpected address for sender %v and salt %v, wanted %v, got %v", sender, salt, want, got)
+				}
+				if got := Create2WithInitCode(sender, salt, initCode); want != got {
+					t.Errorf("unexpected address for sender %v and salt %v, wanted %v, got %v", sender, salt, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestCreate_KnownAddress(t *testing.T) {
+	// Example from the Ethereum yellow paper discussions, see
+	// https://ethereum.stackexchange.com/questions/760
+	sender := tosca.Address(common.HexToAddress("0x6ac7ea33f8831ea9dcc53393aaa88b25a785dbf0"))
+	want := tosca.Address(common.HexToAddress("0xcd234a471b72ba2f1ccf0a70fcaba648a5eecd8d"))
+	if got := Create(sender, 0); want != got {
+		t.Errorf("unexpected address, wanted %v, got %v", want, got)
+	}
+}
+
+func BenchmarkCreate(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Create(tosca.Address{1}, uint64(i))
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "sync"
+
+// ReadOnlyContext provides read access to the world state and the block
+// history, as required for tracing historical transactions. In contrast to a
+// TransactionContext, it offers no modifications, snapshots, or transaction
+// state like access lists, transient storage, and logs. Implementations must
+// be safe for concurrent use, such that many transactions can be traced in
+// parallel over a shared state.
+//
+// Every TransactionContext provides the methods of a ReadOnlyContext, but is
+// in general not safe for concurrent use. NewArchiveContext adapts it.
+type ReadOnlyContext interface {
+	AccountExists(Address) bool
+	GetBalance(Address) Value
+	GetNonce(Address) uint64
+	GetCode(Address) Code
+	GetCodeHash(Address) Hash
+	GetCodeSize(Address) int
+	GetStorage(Address, Key) Word
+
+	// GetBlockHash returns the hash of the block with the given number.
+	GetBlockHash(number int64) Hash
+}
+
+var _ ReadOnlyContext = TransactionContext(nil)
+
+// NewArchiveContext adapts the given transaction context for concurrent
+// read-only use. Since the state of an archive does not change, the results
+// of reads are cached by the returned context and repeated reads of the same
+// data, e.g. of popular contracts accessed by many traced transactions, are
+// served concurrently. Reads missing the cache are serialized, since
+// transaction contexts commonly update internal caches while reading. The
+// cache grows with the data read and is only released with the returned
+// context, which should thus be scoped to the tracing of a single block.
+//
+// The returned context only forwards reads; the given context must not be
+// modified while it is in use. Returned codes are shared and must not be
+// modified by the caller.
+func NewArchiveContext(context TransactionContext) ReadOnlyContext {
+	return &archiveContext{context: context}
+}
+
+type archiveContext struct {
+	mutex   sync.Mutex // < serializes accesses to context
+	context TransactionContext
+
+	exists      readCache[Address, bool]
+	balances    readCache[Address, Value]
+	nonces      readCache[Address, uint64]
+	codes       readCache[Address, Code]
+	codeHashes  readCache[Address, Hash]
+	codeSizes   readCache[Address, int]
+	storage     readCache[storageSlot, Word]
+	blockHashes readCache[int64, Hash]
+}
+
+type storageSlot struct {
+	address Address
+	key     Key
+}
+
+func (c *archiveContext) AccountExists(address Address) bool {
+	return c.exists.get(address, func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.AccountExists(address)
+	})
+}
+
+func (c *archiveContext) GetBalance(address Address) Value {
+	return c.balances.get(address, func() Value {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetBalance(address)
+	})
+}
+
+func (c *archiveContext) GetNonce(address Address) uint64 {
+	return c.nonces.get(address, func() uint64 {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetNonce(address)
+	})
+}
+
+func (c *archiveContext) GetCode(address Address) Code {
+	return c.codes.get(address, func() Code {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetCode(address)
+	})
+}
+
+func (c *archiveContext) GetCodeHash(address Address) Hash {
+	return c.codeHashes.get(address, func() Hash {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetCodeHash(address)
+	})
+}
+
+func (c *archiveContext) GetCodeSize(address Address) int {
+	return c.codeSizes.get(address, func() int {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetCodeSize(address)
+	})
+}
+
+func (c *archiveContext) GetStorage(address Address, key Key) Word {
+	return c.storage.get(storageSlot{address, key}, func() Word {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetStorage(address, key)
+	})
+}
+
+func (c *archiveContext) GetBlockHash(number int64) Hash {
+	return c.blockHashes.get(number, func() Hash {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		return c.context.GetBlockHash(number)
+	})
+}
+
+// readCache is a map of read results safe for concurrent use. Concurrent
+// misses of the same key may load the value more than once, which is
+// harmless since loaded values do not change.
+type readCache[K comparable, V any] struct {
+	mutex  sync.RWMutex
+	values map[K]V
+}
+
+func (c *readCache[K, V]) get(key K, load func() V) V {
+	c.mutex.RLock()
+	value, found := c.values[key]
+	c.mutex.RUnlock()
+	if found {
+		return value
+	}
+	value = load()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.values == nil {
+		c.values = map[K]V{}
+	}
+	c.values[key] = value
+	return value
+}
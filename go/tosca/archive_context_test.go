@@ -0,0 +1,143 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bytes"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestArchiveContext_ReadsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockTransactionContext(ctrl)
+	address, key := Address{1}, Key{2}
+
+	inner.EXPECT().AccountExists(address).Return(true)
+	inner.EXPECT().GetBalance(address).Return(Value{3})
+	inner.EXPECT().GetNonce(address).Return(uint64(4))
+	inner.EXPECT().GetCode(address).Return(Code{5})
+	inner.EXPECT().GetCodeHash(address).Return(Hash{6})
+	inner.EXPECT().GetCodeSize(address).Return(7)
+	inner.EXPECT().GetStorage(address, key).Return(Word{8})
+	inner.EXPECT().GetBlockHash(int64(9)).Return(Hash{10})
+
+	context := NewArchiveContext(inner)
+	if !context.AccountExists(address) {
+		t.Errorf("unexpected account existence")
+	}
+	if want, got := (Value{3}), context.GetBalance(address); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := uint64(4), context.GetNonce(address); want != got {
+		t.Errorf("unexpected nonce, wanted %d, got %d", want, got)
+	}
+	if want, got := (Code{5}), context.GetCode(address); !bytes.Equal(want, got) {
+		t.Errorf("unexpected code, wanted %x, got %x", want, got)
+	}
+	if want, got := (Hash{6}), context.GetCodeHash(address); want != got {
+		t.Errorf("unexpected code hash, wanted %v, got %v", want, got)
+	}
+	if want, got := 7, context.GetCodeSize(address); want != got {
+		t.Errorf("unexpected code size, wanted %d, got %d", want, got)
+	}
+	if want, got := (Word{8}), context.GetStorage(address, key); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	if want, got := (Hash{10}), context.GetBlockHash(9); want != got {
+		t.Errorf("unexpected block hash, wanted %v, got %v", want, got)
+	}
+}
+
+func TestArchiveContext_ProvidesNoModifications(t *testing.T) {
+	context := NewArchiveContext(NewMockTransactionContext(gomock.NewController(t)))
+	if _, ok := context.(WorldState); ok {
+		t.Errorf("archive context should not provide world state modifications")
+	}
+	if _, ok := context.(interface{ CreateSnapshot() Snapshot }); ok {
+		t.Errorf("archive context should not provide snapshots")
+	}
+}
+
+// cachingContext is a TransactionContext updating an internal cache on reads
+// and reporting reads overlapping with other reads, which is not supported.
+type cachingContext struct {
+	TransactionContext
+	active   atomic.Int32
+	overlaps atomic.Int32
+	cache    map[Address]Value
+}
+
+func (c *cachingContext) GetBalance(address Address) Value {
+	if c.active.Add(1) != 1 {
+		c.overlaps.Add(1)
+	}
+	defer c.active.Add(-1)
+	runtime.Gosched() // < give concurrent reads a chance to overlap
+	if value, found := c.cache[address]; found {
+		return value
+	}
+	value := Value{address[0]}
+	c.cache[address] = value
+	return value
+}
+
+func TestArchiveContext_ConcurrentCacheMissesAreSerialized(t *testing.T) {
+	inner := &cachingContext{cache: map[Address]Value{}}
+	context := NewArchiveContext(inner)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				address := Address{byte(j)}
+				if want, got := (Value{byte(j)}), context.GetBalance(address); want != got {
+					t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if overlaps := inner.overlaps.Load(); overlaps != 0 {
+		t.Errorf("detected %d overlapping reads", overlaps)
+	}
+}
+
+func TestArchiveContext_RepeatedReadsAreServedFromCache(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockTransactionContext(ctrl)
+	address, key := Address{1}, Key{2}
+
+	inner.EXPECT().GetBalance(address).Return(Value{3})
+	inner.EXPECT().GetStorage(address, key).Return(Word{4})
+	inner.EXPECT().GetStorage(address, Key{5}).Return(Word{6})
+
+	context := NewArchiveContext(inner)
+	for i := 0; i < 3; i++ {
+		if want, got := (Value{3}), context.GetBalance(address); want != got {
+			t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+		}
+		if want, got := (Word{4}), context.GetStorage(address, key); want != got {
+			t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+		}
+		if want, got := (Word{6}), context.GetStorage(address, Key{5}); want != got {
+			t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+const (
+	// CalldataFloorBaseGas is the base of the calldata floor of EIP-7623,
+	// which matches the base costs of transactions.
+	CalldataFloorBaseGas = 21_000
+	// CalldataFloorCostPerToken is the floor price of a calldata token as
+	// defined by EIP-7623. Zero bytes count as one token, non-zero bytes as
+	// four tokens.
+	CalldataFloorCostPerToken = 10
+)
+
+// CalldataFloorGas returns the minimum amount of gas charged for a transaction
+// with the given input as introduced by EIP-7623. Transactions are charged the
+// maximum of the gas they used and this floor. Transactions with a gas limit
+// below the floor are invalid.
+func CalldataFloorGas(input Data) Gas {
+	tokens := Gas(0)
+	for _, b := range input {
+		if b == 0 {
+			tokens++
+		} else {
+			tokens += 4
+		}
+	}
+	return CalldataFloorBaseGas + tokens*CalldataFloorCostPerToken
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "testing"
+
+func TestCalldataFloorGas_ChargesTokensOfInput(t *testing.T) {
+	tests := map[string]struct {
+		input Data
+		want  Gas
+	}{
+		"empty":        {nil, 21_000},
+		"zero byte":    {Data{0}, 21_000 + 10},
+		"nonzero byte": {Data{1}, 21_000 + 40},
+		"mixed":        {Data{0, 1, 0, 2}, 21_000 + 100},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, got := test.want, CalldataFloorGas(test.input); want != got {
+				t.Errorf("unexpected floor, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capabilities is a set of optional features a host may provide through its
+// transaction contexts. Processors requiring features beyond the mandatory
+// TransactionContext interface check for them before executing transactions,
+// such that missing features are reported as errors instead of failing in
+// the middle of a block.
+type Capabilities uint64
+
+const (
+	// CapabilityPreimages indicates that the context records the preimages
+	// of hashes computed by SHA3 instructions. Contexts providing it must
+	// implement the PreimageRecorder interface.
+	CapabilityPreimages Capabilities = 1 << iota
+)
+
+var capabilityNames = []string{
+	"preimages",
+}
+
+// Has reports whether all of the given capabilities are in this set.
+func (c Capabilities) Has(capabilities Capabilities) bool {
+	return c&capabilities == capabilities
+}
+
+func (c Capabilities) String() string {
+	names := []string{}
+	for i, name := range capabilityNames {
+		if c.Has(1 << i) {
+			names = append(names, name)
+		}
+	}
+	if unknown := c &^ (1<<len(capabilityNames) - 1); unknown != 0 {
+		names = append(names, fmt.Sprintf("unknown(%#x)", uint64(unknown)))
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
+
+// CapabilityReporter is an optional extension of the TransactionContext
+// interface for contexts providing optional capabilities.
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// PreimageRecorder is an optional extension of the TransactionContext
+// interface for contexts providing the CapabilityPreimages capability.
+type PreimageRecorder interface {
+	// AddPreimage records the preimage of the given hash.
+	AddPreimage(hash Hash, preimage []byte)
+}
+
+// GetCapabilities returns the capabilities provided by the given context.
+// Capabilities are only reported if the context implements the interfaces
+// required by them.
+func GetCapabilities(context TransactionContext) Capabilities {
+	reporter, ok := context.(CapabilityReporter)
+	if !ok {
+		return 0
+	}
+	capabilities := reporter.Capabilities()
+	if _, ok := context.(PreimageRecorder); !ok {
+		capabilities &^= CapabilityPreimages
+	}
+	return capabilities
+}
+
+// RequireCapabilities returns an ErrMissingCapabilities error if the given
+// context does not provide all of the required capabilities.
+func RequireCapabilities(context TransactionContext, required Capabilities) error {
+	if missing := required &^ GetCapabilities(context); missing != 0 {
+		return &ErrMissingCapabilities{Missing: missing}
+	}
+	return nil
+}
+
+// ErrMissingCapabilities is reported by processors if a transaction context
+// lacks capabilities required by their configuration.
+type ErrMissingCapabilities struct {
+	Missing Capabilities
+}
+
+func (e *ErrMissingCapabilities) Error() string {
+	return fmt.Sprintf("transaction context is missing required capabilities %v", e.Missing)
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestCapabilities_Has(t *testing.T) {
+	if !Capabilities(0).Has(0) {
+		t.Errorf("empty set should contain the empty set")
+	}
+	if Capabilities(0).Has(CapabilityPreimages) {
+		t.Errorf("empty set should not contain preimages")
+	}
+	if !CapabilityPreimages.Has(CapabilityPreimages) {
+		t.Errorf("preimages should contain preimages")
+	}
+}
+
+func TestCapabilities_String(t *testing.T) {
+	tests := map[Capabilities]string{
+		0:                           "{}",
+		CapabilityPreimages:         "{preimages}",
+		CapabilityPreimages | 1<<10: "{preimages, unknown(0x400)}",
+		Capabilities(1 << 63):       "{unknown(0x8000000000000000)}",
+	}
+	for capabilities, want := range tests {
+		if got := capabilities.String(); want != got {
+			t.Errorf("unexpected string, wanted %q, got %q", want, got)
+		}
+	}
+}
+
+type capabilityReportingContext struct {
+	TransactionContext
+	capabilities Capabilities
+}
+
+func (c *capabilityReportingContext) Capabilities() Capabilities {
+	return c.capabilities
+}
+
+type preimageRecordingContext struct {
+	capabilityReportingContext
+	preimages map[Hash][]byte
+}
+
+func (c *preimageRecordingContext) AddPreimage(hash Hash, preimage []byte) {
+	c.preimages[hash] = preimage
+}
+
+func TestGetCapabilities_ReportsCapabilitiesOfContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	plain := NewMockTransactionContext(ctrl)
+
+	tests := map[string]struct {
+		context TransactionContext
+		want    Capabilities
+	}{
+		"plain context": {
+			context: plain,
+			want:    0,
+		},
+		"reporter without recorder": {
+			context: &capabilityReportingContext{plain, CapabilityPreimages},
+			want:    0,
+		},
+		"reporter with recorder": {
+			context: &preimageRecordingContext{
+				capabilityReportingContext: capabilityReportingContext{plain, CapabilityPreimages},
+			},
+			want: CapabilityPreimages,
+		},
+		"recorder not reporting": {
+			context: &preimageRecordingContext{
+				capabilityReportingContext: capabilityReportingContext{plain, 0},
+			},
+			want: 0,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, got := test.want, GetCapabilities(test.context); want != got {
+				t.Errorf("unexpected capabilities, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestRequireCapabilities_ReportsMissingCapabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	plain := NewMockTransactionContext(ctrl)
+	recording := &preimageRecordingContext{
+		capabilityReportingContext: capabilityReportingContext{plain, CapabilityPreimages},
+	}
+
+	if err := RequireCapabilities(plain, 0); err != nil {
+		t.Errorf("unexpected error for empty requirements: %v", err)
+	}
+	if err := RequireCapabilities(recording, CapabilityPreimages); err != nil {
+		t.Errorf("unexpected error for provided capabilities: %v", err)
+	}
+
+	err := RequireCapabilities(plain, CapabilityPreimages)
+	var missing *ErrMissingCapabilities
+	if !errors.As(err, &missing) {
+		t.Fatalf("unexpected error, wanted ErrMissingCapabilities, got %v", err)
+	}
+	if want, got := CapabilityPreimages, missing.Missing; want != got {
+		t.Errorf("unexpected missing capabilities, wanted %v, got %v", want, got)
+	}
+}
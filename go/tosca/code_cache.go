@@ -0,0 +1,142 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CodeCache retains artifacts derived from contract codes, like the results
+// of jump destination analyses or translated instruction streams, indexed by
+// the hash of the code they have been derived from. Different kinds of
+// artifacts for the same code are distinguished by a kind string chosen by
+// the producer of the artifact, such that a single cache can be shared among
+// interpreters and interpreter configurations.
+//
+// The memory consumed by the cache is accounted using the sizes of artifacts
+// reported when adding them. Once the total size exceeds the capacity of the
+// cache, the least recently used artifacts are evicted. A CodeCache is safe
+// for concurrent use.
+type CodeCache struct {
+	capacity int
+	size     int
+	entries  map[codeCacheKey]*list.Element
+	order    *list.List // < most recently used entries at the front
+	mutex    sync.Mutex
+}
+
+type codeCacheKey struct {
+	hash Hash
+	kind string
+}
+
+type codeCacheEntry struct {
+	key      codeCacheKey
+	artifact any
+	size     int
+}
+
+// DefaultCodeCacheCapacity is the capacity in bytes of the cache shared by
+// all interpreters not configured to use a dedicated cache.
+const DefaultCodeCacheCapacity = 1 << 30 // = 1 GiB
+
+var sharedCodeCache = NewCodeCache(DefaultCodeCacheCapacity)
+
+// GetSharedCodeCache returns the process-wide code cache shared by all
+// interpreters not configured to use a dedicated cache.
+func GetSharedCodeCache() *CodeCache {
+	return sharedCodeCache
+}
+
+// NewCodeCache creates an empty cache retaining artifacts with a total size
+// of up to capacity bytes.
+func NewCodeCache(capacity int) *CodeCache {
+	return &CodeCache{
+		capacity: capacity,
+		entries:  map[codeCacheKey]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get returns the artifact of the given kind derived from the code with the
+// given hash, if present in the cache.
+func (c *CodeCache) Get(hash Hash, kind string) (any, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, found := c.entries[codeCacheKey{hash, kind}]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*codeCacheEntry).artifact, true
+}
+
+// Add registers the artifact of the given kind derived from the code with the
+// given hash, replacing any previously registered artifact for the same code
+// and kind. The size is the number of bytes occupied by the artifact. Least
+// recently used artifacts are evicted until the total size of all artifacts
+// is within the capacity of the cache. Artifacts larger than the capacity are
+// not retained.
+func (c *CodeCache) Add(hash Hash, kind string, artifact any, size int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key := codeCacheKey{hash, kind}
+	if element, found := c.entries[key]; found {
+		c.remove(element)
+	}
+	if size > c.capacity {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&codeCacheEntry{
+		key:      key,
+		artifact: artifact,
+		size:     size,
+	})
+	c.size += size
+	for c.size > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Len returns the number of artifacts retained by the cache.
+func (c *CodeCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the total size of all artifacts retained by the cache in bytes.
+func (c *CodeCache) Size() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.size
+}
+
+// Capacity returns the maximum total size of the retained artifacts in bytes.
+func (c *CodeCache) Capacity() int {
+	return c.capacity
+}
+
+// Clear removes all artifacts from the cache.
+func (c *CodeCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = map[codeCacheKey]*list.Element{}
+	c.order.Init()
+	c.size = 0
+}
+
+func (c *CodeCache) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*codeCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
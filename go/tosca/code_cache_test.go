@@ -0,0 +1,141 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCodeCache_AddedArtifactsCanBeRetrieved(t *testing.T) {
+	cache := NewCodeCache(100)
+	cache.Add(Hash{1}, "a", 1, 10)
+	cache.Add(Hash{1}, "b", 2, 10)
+	cache.Add(Hash{2}, "a", 3, 10)
+
+	tests := []struct {
+		hash Hash
+		kind string
+		want any
+	}{
+		{Hash{1}, "a", 1},
+		{Hash{1}, "b", 2},
+		{Hash{2}, "a", 3},
+	}
+	for _, test := range tests {
+		got, found := cache.Get(test.hash, test.kind)
+		if !found || got != test.want {
+			t.Errorf("unexpected artifact for %v/%s, wanted %v, got %v", test.hash, test.kind, test.want, got)
+		}
+	}
+	if _, found := cache.Get(Hash{2}, "b"); found {
+		t.Errorf("artifact should not be present")
+	}
+}
+
+func TestCodeCache_SizeIsAccountedInBytes(t *testing.T) {
+	cache := NewCodeCache(100)
+	cache.Add(Hash{1}, "a", 1, 10)
+	cache.Add(Hash{2}, "a", 2, 20)
+	if want, got := 30, cache.Size(); want != got {
+		t.Errorf("unexpected size, wanted %d, got %d", want, got)
+	}
+
+	// Replacing an artifact updates the size.
+	cache.Add(Hash{1}, "a", 3, 5)
+	if want, got := 25, cache.Size(); want != got {
+		t.Errorf("unexpected size, wanted %d, got %d", want, got)
+	}
+	if want, got := 2, cache.Len(); want != got {
+		t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+	}
+}
+
+func TestCodeCache_LeastRecentlyUsedArtifactsAreEvicted(t *testing.T) {
+	cache := NewCodeCache(30)
+	cache.Add(Hash{1}, "a", 1, 10)
+	cache.Add(Hash{2}, "a", 2, 10)
+	cache.Add(Hash{3}, "a", 3, 10)
+
+	// Accessing the first artifact makes the second the least recently used.
+	cache.Get(Hash{1}, "a")
+	cache.Add(Hash{4}, "a", 4, 15)
+
+	for _, hash := range []Hash{{1}, {4}} {
+		if _, found := cache.Get(hash, "a"); !found {
+			t.Errorf("artifact for %v should be present", hash)
+		}
+	}
+	for _, hash := range []Hash{{2}, {3}} {
+		if _, found := cache.Get(hash, "a"); found {
+			t.Errorf("artifact for %v should have been evicted", hash)
+		}
+	}
+	if want, got := 25, cache.Size(); want != got {
+		t.Errorf("unexpected size, wanted %d, got %d", want, got)
+	}
+}
+
+func TestCodeCache_ArtifactsExceedingCapacityAreNotRetained(t *testing.T) {
+	cache := NewCodeCache(10)
+	cache.Add(Hash{1}, "a", 1, 5)
+	cache.Add(Hash{2}, "a", 2, 11)
+	if _, found := cache.Get(Hash{2}, "a"); found {
+		t.Errorf("artifact exceeding capacity should not be retained")
+	}
+	if _, found := cache.Get(Hash{1}, "a"); !found {
+		t.Errorf("existing artifact should not have been evicted")
+	}
+}
+
+func TestCodeCache_ClearRemovesAllArtifacts(t *testing.T) {
+	cache := NewCodeCache(100)
+	cache.Add(Hash{1}, "a", 1, 10)
+	cache.Add(Hash{2}, "b", 2, 10)
+	cache.Clear()
+	if want, got := 0, cache.Len(); want != got {
+		t.Errorf("unexpected number of entries, wanted %d, got %d", want, got)
+	}
+	if want, got := 0, cache.Size(); want != got {
+		t.Errorf("unexpected size, wanted %d, got %d", want, got)
+	}
+	if _, found := cache.Get(Hash{1}, "a"); found {
+		t.Errorf("artifact should have been removed")
+	}
+}
+
+func TestCodeCache_SharedCacheUsesDefaultCapacity(t *testing.T) {
+	if want, got := DefaultCodeCacheCapacity, GetSharedCodeCache().Capacity(); want != got {
+		t.Errorf("unexpected capacity, wanted %d, got %d", want, got)
+	}
+}
+
+func TestCodeCache_IsThreadSafe(t *testing.T) {
+	// This test is to be run with --race to detect concurrency issues.
+	cache := NewCodeCache(1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				hash := Hash{byte(i), byte(j)}
+				cache.Add(hash, "a", j, 10)
+				cache.Get(hash, "a")
+				cache.Size()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if got := cache.Size(); got > 1000 {
+		t.Errorf("cache exceeded its capacity, got %d bytes", got)
+	}
+}
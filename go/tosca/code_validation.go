@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+const (
+	// MaxCodeSize is the maximum size of the code of contracts in bytes as
+	// defined by EIP-170. Chains may configure a higher limit.
+	MaxCodeSize = 24576
+
+	// CodeDepositGasPerByte is the gas charged per byte of the code of a
+	// contract stored at the end of its creation.
+	CodeDepositGasPerByte = 200
+)
+
+const (
+	ErrCodeSizeExceeded  = ConstError("code size exceeds limit")
+	ErrInvalidCodePrefix = ConstError("code starts with the reserved 0xEF byte")
+)
+
+// ValidateDeployedCode checks whether the given code, produced by the init
+// code of a contract creation, may be stored as the code of the new contract
+// in the given revision. It returns the gas to be charged for depositing the
+// code, or an error if the code exceeds the given size limit or, starting
+// with London, starts with the 0xEF byte reserved by EIP-3541. A maxCodeSize
+// of zero selects the MaxCodeSize limit of Ethereum.
+//
+// Charging the returned gas is the responsibility of the caller. If the
+// remaining gas does not cover it, the creation fails as well.
+func ValidateDeployedCode(revision Revision, code Code, maxCodeSize int) (Gas, error) {
+	if maxCodeSize == 0 {
+		maxCodeSize = MaxCodeSize
+	}
+	if len(code) > maxCodeSize {
+		return 0, ErrCodeSizeExceeded
+	}
+	if revision >= R10_London && len(code) > 0 && code[0] == 0xEF {
+		return 0, ErrInvalidCodePrefix
+	}
+	return Gas(len(code) * CodeDepositGasPerByte), nil
+}
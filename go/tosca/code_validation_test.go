@@ -0,0 +1,48 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateDeployedCode_ChecksSizeAndPrefix(t *testing.T) {
+	tests := map[string]struct {
+		revision    Revision
+		code        Code
+		maxCodeSize int
+		gas         Gas
+		err         error
+	}{
+		"empty":                   {R13_Cancun, Code{}, 0, 0, nil},
+		"small code":              {R13_Cancun, Code{1, 2, 3}, 0, 600, nil},
+		"at default limit":        {R13_Cancun, make(Code, MaxCodeSize), 0, MaxCodeSize * 200, nil},
+		"exceeding default limit": {R13_Cancun, make(Code, MaxCodeSize+1), 0, 0, ErrCodeSizeExceeded},
+		"within raised limit":     {R13_Cancun, make(Code, MaxCodeSize+1), 2 * MaxCodeSize, (MaxCodeSize + 1) * 200, nil},
+		"exceeding custom limit":  {R13_Cancun, make(Code, 11), 10, 0, ErrCodeSizeExceeded},
+		"0xEF prefix in London":   {R10_London, Code{0xEF, 0}, 0, 0, ErrInvalidCodePrefix},
+		"0xEF prefix in Berlin":   {R09_Berlin, Code{0xEF, 0}, 0, 400, nil},
+		"0xEF not at start":       {R13_Cancun, Code{0, 0xEF}, 0, 400, nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			gas, err := ValidateDeployedCode(test.revision, test.code, test.maxCodeSize)
+			if !errors.Is(err, test.err) {
+				t.Errorf("unexpected error, wanted %v, got %v", test.err, err)
+			}
+			if want, got := test.gas, gas; want != got {
+				t.Errorf("unexpected gas, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "golang.org/x/crypto/sha3"
+
+// ComputeCreate2Address computes the address of a contract created by the
+// given deployer using the CREATE2 instruction with the given salt and the
+// hash of the init code, as defined by EIP-1014. Since the address does not
+// depend on the state, it may be computed ahead of the deployment.
+func ComputeCreate2Address(deployer Address, salt Hash, initCodeHash Hash) Address {
+	hasher := sha3.NewLegacyKeccak256()
+	for _, data := range [][]byte{{0xff}, deployer[:], salt[:], initCodeHash[:]} {
+		_, _ = hasher.Write(data) // Hash.Write never returns an error
+	}
+	var hash Hash
+	hasher.Sum(hash[:0])
+	return Address(hash[12:])
+}
+
+// HasCreateCollision returns true if a contract can not be created at the
+// given address because an account with a non-zero nonce or non-empty code
+// is located there, as defined by EIP-684. Creations into such addresses
+// fail, consuming all the gas provided to them.
+func HasCreateCollision(state WorldState, address Address) bool {
+	if state.GetNonce(address) != 0 {
+		return true
+	}
+	codeHash := state.GetCodeHash(address)
+	return codeHash != (Hash{}) && codeHash != emptyCodeHash
+}
+
+// emptyCodeHash is the Keccak256 hash of the empty code.
+var emptyCodeHash = Hash{
+	0xc5, 0xd2, 0x46, 0x01, 0x86, 0xf7, 0x23, 0x3c,
+	0x92, 0x7e, 0x7d, 0xb2, 0xdc, 0xc7, 0x03, 0xc0,
+	0xe5, 0x00, 0xb6, 0x53, 0xca, 0x82, 0x27, 0x3b,
+	0x7b, 0xfa, 0xd8, 0x04, 0x5d, 0x85, 0xa4, 0x70,
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+func TestComputeCreate2Address_MatchesGethDerivation(t *testing.T) {
+	deployers := []Address{{}, {1}, {0xff, 19: 0x34}}
+	salts := []Hash{{}, {1}, {31: 0xff}}
+	initCodes := [][]byte{nil, {0}, {0x60, 0x00, 0x60, 0x00, 0xf3}}
+	for _, deployer := range deployers {
+		for _, salt := range salts {
+			for _, initCode := range initCodes {
+				initCodeHash := crypto.Keccak256(initCode)
+				want := Address(crypto.CreateAddress2(common.Address(deployer), salt, initCodeHash))
+				if got := ComputeCreate2Address(deployer, salt, Hash(initCodeHash)); want != got {
+					t.Errorf("unexpected address, wanted %v, got %v", want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestEmptyCodeHash_IsHashOfEmptyCode(t *testing.T) {
+	if want, got := Hash(crypto.Keccak256(nil)), emptyCodeHash; want != got {
+		t.Errorf("unexpected hash of empty code, wanted %v, got %v", want, got)
+	}
+}
+
+func TestHasCreateCollision_DetectsAccountsWithNonceOrCode(t *testing.T) {
+	tests := map[string]struct {
+		nonce     uint64
+		codeHash  Hash
+		collision bool
+	}{
+		"non-existing account": {},
+		"account without code": {codeHash: emptyCodeHash},
+		"account with nonce":   {nonce: 1, codeHash: emptyCodeHash, collision: true},
+		"account with code":    {codeHash: Hash{1}, collision: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			state := NewMockWorldState(ctrl)
+			address := Address{1}
+			state.EXPECT().GetNonce(address).Return(test.nonce)
+			state.EXPECT().GetCodeHash(address).Return(test.codeHash).AnyTimes()
+			if want, got := test.collision, HasCreateCollision(state, address); want != got {
+				t.Errorf("unexpected collision, wanted %t, got %t", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package ctxutil provides composable wrappers of tosca.TransactionContext
+// implementations adding cross-cutting behavior like the recording of
+// accesses or the injection of latencies. The boilerplate of forwarding all
+// methods of the context interface is implemented once by Intercept, such
+// that new behavior can be added by a single function observing the calls
+// to a context.
+package ctxutil
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// Middleware wraps a transaction context, adding behavior to it.
+type Middleware func(tosca.TransactionContext) tosca.TransactionContext
+
+// Wrap applies the given middlewares to the given context. The first
+// middleware is the outermost one, observing calls before all others.
+func Wrap(context tosca.TransactionContext, middlewares ...Middleware) tosca.TransactionContext {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		context = middlewares[i](context)
+	}
+	return context
+}
+
+//go:generate go run ./internal/gen -dir .. -interface TransactionContext -extensions AccountRemover -type interceptingContext -output intercepting_context.go
+
+// Operation identifies a method of the tosca.TransactionContext interface or
+// of one of its optional extensions. The operations are generated from the
+// definition of the interface, see intercepting_context.go.
+type Operation int
+
+// GetAllOperations returns all operations in the order of their definition.
+func GetAllOperations() []Operation {
+	res := make([]Operation, 0, numOperations)
+	for op := Operation(0); op < numOperations; op++ {
+		res = append(res, op)
+	}
+	return res
+}
+
+func (o Operation) String() string {
+	if o >= 0 && o < numOperations {
+		return operationNames[o]
+	}
+	return fmt.Sprintf("Operation(%d)", int(o))
+}
+
+// IsWrite returns true if the operation modifies the state of the context.
+// Snapshot operations and accesses to the access list are not considered
+// modifications.
+func (o Operation) IsWrite() bool {
+	switch o {
+	case OpSetBalance, OpSetNonce, OpSetCode, OpSetStorage, OpSelfDestruct,
+		OpRemoveAccount, OpSetTransientStorage, OpEmitLog:
+		return true
+	}
+	return false
+}
+
+// Call describes an invocation of a method of a transaction context.
+type Call struct {
+	Operation Operation
+	Address   tosca.Address // the targeted account, zero for operations not targeting an account
+	Key       tosca.Key     // the targeted storage slot, zero for operations not targeting a slot
+}
+
+func (c Call) String() string {
+	return fmt.Sprintf("%v(%v, %v)", c.Operation, c.Address, c.Key)
+}
+
+// Interceptor is informed about calls to an intercepted context. It is
+// invoked before the call is forwarded to the wrapped context and may block
+// to delay the call.
+type Interceptor func(Call)
+
+// Intercept wraps the given context such that the given interceptor is
+// invoked for every call to one of its methods. The optional extensions
+// tosca.AccountRemover and tosca.PreimageRecorder, as well as the
+// capabilities of the wrapped context, are forwarded.
+func Intercept(context tosca.TransactionContext, interceptor Interceptor) tosca.TransactionContext {
+	return &interceptingContext{
+		context:     context,
+		interceptor: interceptor,
+	}
+}
+
+// InterceptorMiddleware returns a Middleware intercepting calls using the
+// given interceptor.
+func InterceptorMiddleware(interceptor Interceptor) Middleware {
+	return func(context tosca.TransactionContext) tosca.TransactionContext {
+		return Intercept(context, interceptor)
+	}
+}
+
+type interceptingContext struct {
+	context     tosca.TransactionContext
+	interceptor Interceptor
+}
+
+func (c *interceptingContext) intercept(op Operation, address tosca.Address) {
+	c.interceptor(Call{Operation: op, Address: address})
+}
+
+func (c *interceptingContext) interceptSlot(op Operation, address tosca.Address, key tosca.Key) {
+	c.interceptor(Call{Operation: op, Address: address, Key: key})
+}
+
+// The methods delegating the calls of the tosca.TransactionContext interface
+// are generated, see intercepting_context.go.
+
+// Capabilities reports the capabilities of the wrapped context.
+func (c *interceptingContext) Capabilities() tosca.Capabilities {
+	return tosca.GetCapabilities(c.context)
+}
+
+// AddPreimage forwards preimages to the wrapped context if it records them.
+func (c *interceptingContext) AddPreimage(hash tosca.Hash, preimage []byte) {
+	if recorder, ok := c.context.(tosca.PreimageRecorder); ok {
+		recorder.AddPreimage(hash, preimage)
+	}
+}
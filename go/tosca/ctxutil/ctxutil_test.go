@@ -0,0 +1,258 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package ctxutil
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+// callsToTest lists for each operation a call on a context and the expected
+// forwarding of this call to the wrapped mock.
+var callsToTest = map[Operation]struct {
+	call   func(tosca.TransactionContext)
+	expect func(*tosca.MockTransactionContext)
+	want   Call
+}{
+	OpAccountExists: {
+		func(c tosca.TransactionContext) { c.AccountExists(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().AccountExists(tosca.Address{1}) },
+		Call{OpAccountExists, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetBalance: {
+		func(c tosca.TransactionContext) { c.GetBalance(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetBalance(tosca.Address{1}) },
+		Call{OpGetBalance, tosca.Address{1}, tosca.Key{}},
+	},
+	OpSetBalance: {
+		func(c tosca.TransactionContext) { c.SetBalance(tosca.Address{1}, tosca.Value{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().SetBalance(tosca.Address{1}, tosca.Value{2}) },
+		Call{OpSetBalance, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetNonce: {
+		func(c tosca.TransactionContext) { c.GetNonce(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetNonce(tosca.Address{1}) },
+		Call{OpGetNonce, tosca.Address{1}, tosca.Key{}},
+	},
+	OpSetNonce: {
+		func(c tosca.TransactionContext) { c.SetNonce(tosca.Address{1}, 2) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().SetNonce(tosca.Address{1}, uint64(2)) },
+		Call{OpSetNonce, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetCode: {
+		func(c tosca.TransactionContext) { c.GetCode(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetCode(tosca.Address{1}) },
+		Call{OpGetCode, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetCodeHash: {
+		func(c tosca.TransactionContext) { c.GetCodeHash(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetCodeHash(tosca.Address{1}) },
+		Call{OpGetCodeHash, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetCodeSize: {
+		func(c tosca.TransactionContext) { c.GetCodeSize(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetCodeSize(tosca.Address{1}) },
+		Call{OpGetCodeSize, tosca.Address{1}, tosca.Key{}},
+	},
+	OpSetCode: {
+		func(c tosca.TransactionContext) { c.SetCode(tosca.Address{1}, tosca.Code{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().SetCode(tosca.Address{1}, tosca.Code{2}) },
+		Call{OpSetCode, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetStorage: {
+		func(c tosca.TransactionContext) { c.GetStorage(tosca.Address{1}, tosca.Key{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetStorage(tosca.Address{1}, tosca.Key{2}) },
+		Call{OpGetStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpSetStorage: {
+		func(c tosca.TransactionContext) { c.SetStorage(tosca.Address{1}, tosca.Key{2}, tosca.Word{3}) },
+		func(m *tosca.MockTransactionContext) {
+			m.EXPECT().SetStorage(tosca.Address{1}, tosca.Key{2}, tosca.Word{3})
+		},
+		Call{OpSetStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpGetCommittedStorage: {
+		func(c tosca.TransactionContext) { c.GetCommittedStorage(tosca.Address{1}, tosca.Key{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetCommittedStorage(tosca.Address{1}, tosca.Key{2}) },
+		Call{OpGetCommittedStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpSelfDestruct: {
+		func(c tosca.TransactionContext) { c.SelfDestruct(tosca.Address{1}, tosca.Address{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().SelfDestruct(tosca.Address{1}, tosca.Address{2}) },
+		Call{OpSelfDestruct, tosca.Address{1}, tosca.Key{}},
+	},
+	OpHasSelfDestructed: {
+		func(c tosca.TransactionContext) { c.HasSelfDestructed(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().HasSelfDestructed(tosca.Address{1}) },
+		Call{OpHasSelfDestructed, tosca.Address{1}, tosca.Key{}},
+	},
+	OpRemoveAccount: {
+		func(c tosca.TransactionContext) { tosca.RemoveAccount(c, tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) {
+			// The mock does not implement the remover extension, so the
+			// call is expected to have no effect on it.
+		},
+		Call{OpRemoveAccount, tosca.Address{1}, tosca.Key{}},
+	},
+	OpCreateSnapshot: {
+		func(c tosca.TransactionContext) { c.CreateSnapshot() },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().CreateSnapshot() },
+		Call{OpCreateSnapshot, tosca.Address{}, tosca.Key{}},
+	},
+	OpRestoreSnapshot: {
+		func(c tosca.TransactionContext) { c.RestoreSnapshot(1) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().RestoreSnapshot(tosca.Snapshot(1)) },
+		Call{OpRestoreSnapshot, tosca.Address{}, tosca.Key{}},
+	},
+	OpGetTransientStorage: {
+		func(c tosca.TransactionContext) { c.GetTransientStorage(tosca.Address{1}, tosca.Key{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetTransientStorage(tosca.Address{1}, tosca.Key{2}) },
+		Call{OpGetTransientStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpSetTransientStorage: {
+		func(c tosca.TransactionContext) {
+			c.SetTransientStorage(tosca.Address{1}, tosca.Key{2}, tosca.Word{3})
+		},
+		func(m *tosca.MockTransactionContext) {
+			m.EXPECT().SetTransientStorage(tosca.Address{1}, tosca.Key{2}, tosca.Word{3})
+		},
+		Call{OpSetTransientStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpAccessAccount: {
+		func(c tosca.TransactionContext) { c.AccessAccount(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().AccessAccount(tosca.Address{1}) },
+		Call{OpAccessAccount, tosca.Address{1}, tosca.Key{}},
+	},
+	OpAccessStorage: {
+		func(c tosca.TransactionContext) { c.AccessStorage(tosca.Address{1}, tosca.Key{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().AccessStorage(tosca.Address{1}, tosca.Key{2}) },
+		Call{OpAccessStorage, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpIsAddressInAccessList: {
+		func(c tosca.TransactionContext) { c.IsAddressInAccessList(tosca.Address{1}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().IsAddressInAccessList(tosca.Address{1}) },
+		Call{OpIsAddressInAccessList, tosca.Address{1}, tosca.Key{}},
+	},
+	OpIsSlotInAccessList: {
+		func(c tosca.TransactionContext) { c.IsSlotInAccessList(tosca.Address{1}, tosca.Key{2}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().IsSlotInAccessList(tosca.Address{1}, tosca.Key{2}) },
+		Call{OpIsSlotInAccessList, tosca.Address{1}, tosca.Key{2}},
+	},
+	OpEmitLog: {
+		func(c tosca.TransactionContext) { c.EmitLog(tosca.Log{Address: tosca.Address{1}}) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().EmitLog(tosca.Log{Address: tosca.Address{1}}) },
+		Call{OpEmitLog, tosca.Address{1}, tosca.Key{}},
+	},
+	OpGetLogs: {
+		func(c tosca.TransactionContext) { c.GetLogs() },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetLogs() },
+		Call{OpGetLogs, tosca.Address{}, tosca.Key{}},
+	},
+	OpGetBlockHash: {
+		func(c tosca.TransactionContext) { c.GetBlockHash(12) },
+		func(m *tosca.MockTransactionContext) { m.EXPECT().GetBlockHash(int64(12)) },
+		Call{OpGetBlockHash, tosca.Address{}, tosca.Key{}},
+	},
+}
+
+func TestIntercept_AllOperationsAreCoveredByTests(t *testing.T) {
+	for _, op := range GetAllOperations() {
+		if _, found := callsToTest[op]; !found {
+			t.Errorf("missing test for operation %v", op)
+		}
+	}
+}
+
+func TestIntercept_CallsAreReportedAndForwarded(t *testing.T) {
+	for op, test := range callsToTest {
+		t.Run(op.String(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mock := tosca.NewMockTransactionContext(ctrl)
+			test.expect(mock)
+
+			var seen []Call
+			context := Intercept(mock, func(call Call) {
+				seen = append(seen, call)
+			})
+			test.call(context)
+
+			if want := []Call{test.want}; !slices.Equal(seen, want) {
+				t.Errorf("unexpected intercepted calls, wanted %v, got %v", want, seen)
+			}
+		})
+	}
+}
+
+func TestWrap_FirstMiddlewareIsOutermost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetNonce(tosca.Address{1})
+
+	order := []string{}
+	tag := func(name string) Middleware {
+		return InterceptorMiddleware(func(Call) {
+			order = append(order, name)
+		})
+	}
+
+	context := Wrap(mock, tag("a"), tag("b"), tag("c"))
+	context.GetNonce(tosca.Address{1})
+
+	if want := []string{"a", "b", "c"}; !slices.Equal(order, want) {
+		t.Errorf("unexpected order of middlewares, wanted %v, got %v", want, order)
+	}
+}
+
+func TestWrap_WithoutMiddlewaresReturnsContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	if got := Wrap(mock); got != mock {
+		t.Errorf("expected unmodified context, got %v", got)
+	}
+}
+
+func TestOperation_String(t *testing.T) {
+	for _, op := range GetAllOperations() {
+		if operationNames[op] == "" {
+			t.Errorf("missing name for operation %d", op)
+		}
+		if got, want := op.String(), operationNames[op]; got != want {
+			t.Errorf("unexpected name, wanted %v, got %v", want, got)
+		}
+	}
+	if got, want := numOperations.String(), "Operation(26)"; got != want {
+		t.Errorf("unexpected name, wanted %v, got %v", want, got)
+	}
+}
+
+func TestOperation_IsWrite(t *testing.T) {
+	writes := []Operation{
+		OpSetBalance, OpSetNonce, OpSetCode, OpSetStorage, OpSelfDestruct,
+		OpRemoveAccount, OpSetTransientStorage, OpEmitLog,
+	}
+	for _, op := range GetAllOperations() {
+		if got, want := op.IsWrite(), slices.Contains(writes, op); got != want {
+			t.Errorf("unexpected write classification of %v, wanted %t, got %t", op, want, got)
+		}
+	}
+}
+
+func TestIntercept_ForwardsCapabilities(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	context := Intercept(mock, func(Call) {})
+	if got, want := tosca.GetCapabilities(context), tosca.GetCapabilities(mock); got != want {
+		t.Errorf("unexpected capabilities, wanted %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Code generated by ctxutil/internal/gen. DO NOT EDIT.
+// Source: tosca.TransactionContext
+
+package ctxutil
+
+import "github.com/Fantom-foundation/Tosca/go/tosca"
+
+const (
+	OpAccountExists Operation = iota
+	OpGetBalance
+	OpSetBalance
+	OpGetNonce
+	OpSetNonce
+	OpGetCode
+	OpGetCodeHash
+	OpGetCodeSize
+	OpSetCode
+	OpGetStorage
+	OpSetStorage
+	OpSelfDestruct
+	OpCreateSnapshot
+	OpRestoreSnapshot
+	OpGetTransientStorage
+	OpSetTransientStorage
+	OpAccessAccount
+	OpAccessStorage
+	OpEmitLog
+	OpGetLogs
+	OpGetBlockHash
+	OpGetCommittedStorage
+	OpIsAddressInAccessList
+	OpIsSlotInAccessList
+	OpHasSelfDestructed
+	OpRemoveAccount
+	numOperations
+)
+
+var operationNames = [numOperations]string{
+	OpAccountExists:         "AccountExists",
+	OpGetBalance:            "GetBalance",
+	OpSetBalance:            "SetBalance",
+	OpGetNonce:              "GetNonce",
+	OpSetNonce:              "SetNonce",
+	OpGetCode:               "GetCode",
+	OpGetCodeHash:           "GetCodeHash",
+	OpGetCodeSize:           "GetCodeSize",
+	OpSetCode:               "SetCode",
+	OpGetStorage:            "GetStorage",
+	OpSetStorage:            "SetStorage",
+	OpSelfDestruct:          "SelfDestruct",
+	OpCreateSnapshot:        "CreateSnapshot",
+	OpRestoreSnapshot:       "RestoreSnapshot",
+	OpGetTransientStorage:   "GetTransientStorage",
+	OpSetTransientStorage:   "SetTransientStorage",
+	OpAccessAccount:         "AccessAccount",
+	OpAccessStorage:         "AccessStorage",
+	OpEmitLog:               "EmitLog",
+	OpGetLogs:               "GetLogs",
+	OpGetBlockHash:          "GetBlockHash",
+	OpGetCommittedStorage:   "GetCommittedStorage",
+	OpIsAddressInAccessList: "IsAddressInAccessList",
+	OpIsSlotInAccessList:    "IsSlotInAccessList",
+	OpHasSelfDestructed:     "HasSelfDestructed",
+	OpRemoveAccount:         "RemoveAccount",
+}
+
+func (c *interceptingContext) AccountExists(address tosca.Address) bool {
+	c.intercept(OpAccountExists, address)
+	return c.context.AccountExists(address)
+}
+
+func (c *interceptingContext) GetBalance(address tosca.Address) tosca.Value {
+	c.intercept(OpGetBalance, address)
+	return c.context.GetBalance(address)
+}
+
+func (c *interceptingContext) SetBalance(address tosca.Address, value tosca.Value) {
+	c.intercept(OpSetBalance, address)
+	c.context.SetBalance(address, value)
+}
+
+func (c *interceptingContext) GetNonce(address tosca.Address) uint64 {
+	c.intercept(OpGetNonce, address)
+	return c.context.GetNonce(address)
+}
+
+func (c *interceptingContext) SetNonce(address tosca.Address, value uint64) {
+	c.intercept(OpSetNonce, address)
+	c.context.SetNonce(address, value)
+}
+
+func (c *interceptingContext) GetCode(address tosca.Address) tosca.Code {
+	c.intercept(OpGetCode, address)
+	return c.context.GetCode(address)
+}
+
+func (c *interceptingContext) GetCodeHash(address tosca.Address) tosca.Hash {
+	c.intercept(OpGetCodeHash, address)
+	return c.context.GetCodeHash(address)
+}
+
+func (c *interceptingContext) GetCodeSize(address tosca.Address) int {
+	c.intercept(OpGetCodeSize, address)
+	return c.context.GetCodeSize(address)
+}
+
+func (c *interceptingContext) SetCode(address tosca.Address, code tosca.Code) {
+	c.intercept(OpSetCode, address)
+	c.context.SetCode(address, code)
+}
+
+func (c *interceptingContext) GetStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	c.interceptSlot(OpGetStorage, address, key)
+	return c.context.GetStorage(address, key)
+}
+
+func (c *interceptingContext) SetStorage(address tosca.Address, key tosca.Key, word tosca.Word) tosca.StorageStatus {
+	c.interceptSlot(OpSetStorage, address, key)
+	return c.context.SetStorage(address, key, word)
+}
+
+func (c *interceptingContext) SelfDestruct(addr tosca.Address, beneficiary tosca.Address) bool {
+	c.intercept(OpSelfDestruct, addr)
+	return c.context.SelfDestruct(addr, beneficiary)
+}
+
+func (c *interceptingContext) CreateSnapshot() tosca.Snapshot {
+	c.intercept(OpCreateSnapshot, tosca.Address{})
+	return c.context.CreateSnapshot()
+}
+
+func (c *interceptingContext) RestoreSnapshot(snapshot tosca.Snapshot) {
+	c.intercept(OpRestoreSnapshot, tosca.Address{})
+	c.context.RestoreSnapshot(snapshot)
+}
+
+func (c *interceptingContext) GetTransientStorage(address tosca.Address, key tosca.Key) tosca.Word {
+	c.interceptSlot(OpGetTransientStorage, address, key)
+	return c.context.GetTransientStorage(address, key)
+}
+
+func (c *interceptingContext) SetTransientStorage(address tosca.Address, key tosca.Key, word tosca.Word) {
+	c.interceptSlot(OpSetTransientStorage, address, key)
+	c.context.SetTransientStorage(address, key, word)
+}
+
+func (c *interceptingContext) AccessAccount(address tosca.Address) tosca.AccessStatus {
+	c.intercept(OpAccessAccount, address)
+	return c.context.AccessAccount(address)
+}
+
+func (c *interceptingContext) AccessStorage(address tosca.Address, key tosca.Key) tosca.AccessStatus {
+	c.interceptSlot(OpAccessStorage, address, key)
+	return c.context.AccessStorage(address, key)
+}
+
+func (c *interceptingContext) EmitLog(log tosca.Log) {
+	c.intercept(OpEmitLog, log.Address)
+	c.context.EmitLog(log)
+}
+
+func (c *interceptingContext) GetLogs() []tosca.Log {
+	c.intercept(OpGetLogs, tosca.Address{})
+	return c.context.GetLogs()
+}
+
+func (c *interceptingContext) GetBlockHash(number int64) tosca.Hash {
+	c.intercept(OpGetBlockHash, tosca.Address{})
+	return c.context.GetBlockHash(number)
+}
+
+func (c *interceptingContext) GetCommittedStorage(addr tosca.Address, key tosca.Key) tosca.Word {
+	c.interceptSlot(OpGetCommittedStorage, addr, key)
+	return c.context.GetCommittedStorage(addr, key)
+}
+
+func (c *interceptingContext) IsAddressInAccessList(addr tosca.Address) bool {
+	c.intercept(OpIsAddressInAccessList, addr)
+	return c.context.IsAddressInAccessList(addr)
+}
+
+func (c *interceptingContext) IsSlotInAccessList(addr tosca.Address, key tosca.Key) (addressPresent, slotPresent bool) {
+	c.interceptSlot(OpIsSlotInAccessList, addr, key)
+	return c.context.IsSlotInAccessList(addr, key)
+}
+
+func (c *interceptingContext) HasSelfDestructed(addr tosca.Address) bool {
+	c.intercept(OpHasSelfDestructed, addr)
+	return c.context.HasSelfDestructed(addr)
+}
+
+func (c *interceptingContext) RemoveAccount(address tosca.Address) {
+	c.intercept(OpRemoveAccount, address)
+	tosca.RemoveAccount(c.context, address)
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Command gen generates the delegation boilerplate of the ctxutil package
+// from the definition of the tosca.TransactionContext interface. For each
+// method of the interface and of the listed extensions an Operation constant
+// and an intercepting method forwarding the call to the wrapped context are
+// produced, such that the wrappers stay in sync when the interface grows.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+func main() {
+	config := Config{}
+	flag.StringVar(&config.Dir, "dir", "..", "directory of the package defining the interfaces")
+	flag.StringVar(&config.Interface, "interface", "TransactionContext", "name of the interface to delegate")
+	extensions := flag.String("extensions", "", "comma separated list of optional extension interfaces")
+	flag.StringVar(&config.Type, "type", "interceptingContext", "name of the type implementing the delegation")
+	flag.StringVar(&config.Package, "package", os.Getenv("GOPACKAGE"), "name of the generated package")
+	output := flag.String("output", "", "output file, stdout if empty")
+	flag.Parse()
+
+	if *extensions != "" {
+		config.Extensions = strings.Split(*extensions, ",")
+	}
+
+	code, err := Generate(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(code)
+	} else {
+		err = os.WriteFile(*output, code, 0644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Config defines the input of the generator.
+type Config struct {
+	Dir        string   // < directory of the package defining the interfaces
+	Interface  string   // < the interface to delegate
+	Extensions []string // < optional extensions delegated through package-level functions
+	Type       string   // < the type receiving the generated methods
+	Package    string   // < the package of the generated code
+}
+
+const header = `// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+`
+
+// Generate produces the formatted source code of the delegation boilerplate
+// defined by the given configuration.
+func Generate(config Config) ([]byte, error) {
+	source, err := parsePackage(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	methods, err := source.methods(config.Interface, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, extension := range config.Extensions {
+		extensionMethods, err := source.methods(extension, true)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, extensionMethods...)
+	}
+
+	qualifier := source.name
+	var out bytes.Buffer
+	out.WriteString(header)
+	fmt.Fprintf(&out, "// Code generated by ctxutil/internal/gen. DO NOT EDIT.\n")
+	fmt.Fprintf(&out, "// Source: %s.%s\n\n", source.name, config.Interface)
+	fmt.Fprintf(&out, "package %s\n\n", config.Package)
+	fmt.Fprintf(&out, "import %q\n\n", source.importPath)
+
+	out.WriteString("const (\n")
+	for i, method := range methods {
+		if i == 0 {
+			fmt.Fprintf(&out, "Op%s Operation = iota\n", method.name)
+		} else {
+			fmt.Fprintf(&out, "Op%s\n", method.name)
+		}
+	}
+	out.WriteString("numOperations\n)\n\n")
+
+	out.WriteString("var operationNames = [numOperations]string{\n")
+	for _, method := range methods {
+		fmt.Fprintf(&out, "Op%s: %q,\n", method.name, method.name)
+	}
+	out.WriteString("}\n")
+
+	for _, method := range methods {
+		if err := method.write(&out, config.Type, qualifier, source.types); err != nil {
+			return nil, err
+		}
+	}
+
+	return format.Source(out.Bytes())
+}
+
+// sourcePackage summarizes the declarations of the package defining the
+// interfaces to be delegated.
+type sourcePackage struct {
+	name       string
+	importPath string
+	interfaces map[string]*ast.InterfaceType
+	types      map[string]bool
+}
+
+func parsePackage(dir string) (*sourcePackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	res := &sourcePackage{
+		interfaces: map[string]*ast.InterfaceType{},
+		types:      map[string]bool{},
+	}
+	fileSet := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		res.name = file.Name.Name
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				res.types[spec.Name.Name] = true
+				if iface, ok := spec.Type.(*ast.InterfaceType); ok {
+					res.interfaces[spec.Name.Name] = iface
+				}
+			}
+		}
+	}
+	if res.name == "" {
+		return nil, fmt.Errorf("no Go source files found in %s", dir)
+	}
+	res.importPath, err = importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// importPath derives the import path of the package in the given directory
+// from the module declaration of the enclosing go.mod file.
+func importPath(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for path := []string{}; ; {
+		content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(content), "\n") {
+				if module, found := strings.CutPrefix(strings.TrimSpace(line), "module "); found {
+					return strings.Join(append([]string{strings.TrimSpace(module)}, path...), "/"), nil
+				}
+			}
+			return "", fmt.Errorf("no module declaration in %s", filepath.Join(dir, "go.mod"))
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no go.mod file found")
+		}
+		path = append([]string{filepath.Base(dir)}, path...)
+		dir = parent
+	}
+}
+
+// method describes a single method of a delegated interface.
+type method struct {
+	name      string
+	signature *ast.FuncType
+	extension bool // < extensions are delegated through package-level functions
+}
+
+// methods lists the methods of the given interface, including the methods of
+// embedded interfaces, in the order of their declaration.
+func (p *sourcePackage) methods(name string, extension bool) ([]method, error) {
+	iface, found := p.interfaces[name]
+	if !found {
+		return nil, fmt.Errorf("interface %s not found in package %s", name, p.name)
+	}
+	res := []method{}
+	for _, field := range iface.Methods.List {
+		if len(field.Names) == 0 {
+			embedded, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("unsupported embedded type in %s", name)
+			}
+			inner, err := p.methods(embedded.Name, extension)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, inner...)
+			continue
+		}
+		for _, ident := range field.Names {
+			res = append(res, method{
+				name:      ident.Name,
+				signature: field.Type.(*ast.FuncType),
+				extension: extension,
+			})
+		}
+	}
+	return res, nil
+}
+
+// parameter is a named parameter of a generated method.
+type parameter struct {
+	name     string
+	typeName string // < the unqualified type name, empty for composite types
+	typ      string // < the qualified type
+}
+
+func (m *method) write(out *bytes.Buffer, receiver, qualifier string, types map[string]bool) error {
+	params, err := parameters(m.signature.Params, qualifier, types)
+	if err != nil {
+		return err
+	}
+	results, err := parameters(m.signature.Results, qualifier, types)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(params))
+	declarations := make([]string, 0, len(params))
+	for _, param := range params {
+		names = append(names, param.name)
+		declarations = append(declarations, param.name+" "+param.typ)
+	}
+
+	fmt.Fprintf(out, "\nfunc (c *%s) %s(%s) %s {\n", receiver, m.name, strings.Join(declarations, ", "), resultList(m.signature.Results, results))
+
+	// The interceptor is informed about the account and storage slot
+	// targeted by the call, if any.
+	address, key := qualifier+".Address{}", ""
+	for i := len(params) - 1; i >= 0; i-- {
+		switch params[i].typeName {
+		case "Address":
+			address = params[i].name
+		case "Key":
+			key = params[i].name
+		case "Log":
+			address = params[i].name + ".Address"
+		}
+	}
+	if key == "" {
+		fmt.Fprintf(out, "c.intercept(Op%s, %s)\n", m.name, address)
+	} else {
+		fmt.Fprintf(out, "c.interceptSlot(Op%s, %s, %s)\n", m.name, address, key)
+	}
+
+	var call string
+	if m.extension {
+		call = fmt.Sprintf("%s.%s(%s)", qualifier, m.name, strings.Join(append([]string{"c.context"}, names...), ", "))
+	} else {
+		call = fmt.Sprintf("c.context.%s(%s)", m.name, strings.Join(names, ", "))
+	}
+	if len(results) > 0 {
+		out.WriteString("return ")
+	}
+	out.WriteString(call + "\n}\n")
+	return nil
+}
+
+// parameters converts the given field list into a list of named parameters.
+// Unnamed parameters are named after their type.
+func parameters(fields *ast.FieldList, qualifier string, types map[string]bool) ([]parameter, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	res := []parameter{}
+	used := map[string]bool{}
+	for _, field := range fields.List {
+		typ, err := qualify(field.Type, qualifier, types)
+		if err != nil {
+			return nil, err
+		}
+		typeName := ""
+		if ident, ok := field.Type.(*ast.Ident); ok {
+			typeName = ident.Name
+		}
+		if len(field.Names) > 0 {
+			for _, ident := range field.Names {
+				res = append(res, parameter{name: ident.Name, typeName: typeName, typ: typ})
+				used[ident.Name] = true
+			}
+			continue
+		}
+		name := "value"
+		if typeName != "" && types[typeName] {
+			name = string(unicode.ToLower(rune(typeName[0]))) + typeName[1:]
+		}
+		for i := 1; used[name]; i++ {
+			name = fmt.Sprintf("%s%d", strings.TrimRight(name, "0123456789"), i)
+		}
+		used[name] = true
+		res = append(res, parameter{name: name, typeName: typeName, typ: typ})
+	}
+	return res, nil
+}
+
+// resultList renders the results of a method signature.
+func resultList(fields *ast.FieldList, results []parameter) string {
+	if len(results) == 0 {
+		return ""
+	}
+	named := len(fields.List[0].Names) > 0
+	if len(results) == 1 && !named {
+		return results[0].typ
+	}
+	parts := make([]string, 0, len(fields.List))
+	for _, field := range fields.List {
+		typ := results[len(parts)].typ
+		if named {
+			idents := make([]string, 0, len(field.Names))
+			for _, ident := range field.Names {
+				idents = append(idents, ident.Name)
+			}
+			parts = append(parts, strings.Join(idents, ", ")+" "+typ)
+		} else {
+			parts = append(parts, typ)
+		}
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// qualify renders the given type expression, qualifying references to the
+// types declared in the source package by the name of the package.
+func qualify(expr ast.Expr, qualifier string, types map[string]bool) (string, error) {
+	var rewrite func(ast.Expr) ast.Expr
+	rewrite = func(expr ast.Expr) ast.Expr {
+		switch expr := expr.(type) {
+		case *ast.Ident:
+			if types[expr.Name] {
+				return &ast.SelectorExpr{X: ast.NewIdent(qualifier), Sel: ast.NewIdent(expr.Name)}
+			}
+		case *ast.ArrayType:
+			return &ast.ArrayType{Len: expr.Len, Elt: rewrite(expr.Elt)}
+		case *ast.StarExpr:
+			return &ast.StarExpr{X: rewrite(expr.X)}
+		case *ast.MapType:
+			return &ast.MapType{Key: rewrite(expr.Key), Value: rewrite(expr.Value)}
+		}
+		return expr
+	}
+	var out bytes.Buffer
+	if err := printer.Fprint(&out, token.NewFileSet(), rewrite(expr)); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
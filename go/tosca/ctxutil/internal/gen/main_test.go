@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_GeneratedCodeIsUpToDate(t *testing.T) {
+	want, err := os.ReadFile(filepath.Join("..", "..", "intercepting_context.go"))
+	if err != nil {
+		t.Fatalf("failed to read generated code: %v", err)
+	}
+	got, err := Generate(Config{
+		Dir:        filepath.Join("..", "..", ".."),
+		Interface:  "TransactionContext",
+		Extensions: []string{"AccountRemover"},
+		Type:       "interceptingContext",
+		Package:    "ctxutil",
+	})
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated code is outdated, run go generate ./tosca/ctxutil")
+	}
+}
+
+func TestGenerate_UnknownInterfaceIsReported(t *testing.T) {
+	_, err := Generate(Config{
+		Dir:       filepath.Join("..", "..", ".."),
+		Interface: "UnknownInterface",
+		Package:   "ctxutil",
+	})
+	if err == nil || !strings.Contains(err.Error(), "UnknownInterface") {
+		t.Errorf("expected error reporting the unknown interface, got %v", err)
+	}
+}
+
+func TestGenerate_MissingSourcesAreReported(t *testing.T) {
+	_, err := Generate(Config{
+		Dir:       t.TempDir(),
+		Interface: "TransactionContext",
+		Package:   "ctxutil",
+	})
+	if err == nil {
+		t.Errorf("expected error for directory without sources")
+	}
+}
+
+func TestParameters_UnnamedParametersAreNamedAfterTheirType(t *testing.T) {
+	expr, err := parser.ParseExpr("func(Address, Address, uint64, []Log, Key)")
+	if err != nil {
+		t.Fatalf("failed to parse signature: %v", err)
+	}
+	types := map[string]bool{"Address": true, "Log": true, "Key": true}
+	params, err := parameters(expr.(*ast.FuncType).Params, "tosca", types)
+	if err != nil {
+		t.Fatalf("failed to convert parameters: %v", err)
+	}
+
+	want := []parameter{
+		{name: "address", typeName: "Address", typ: "tosca.Address"},
+		{name: "address1", typeName: "Address", typ: "tosca.Address"},
+		{name: "value", typeName: "uint64", typ: "uint64"},
+		{name: "value1", typeName: "", typ: "[]tosca.Log"},
+		{name: "key", typeName: "Key", typ: "tosca.Key"},
+	}
+	if len(params) != len(want) {
+		t.Fatalf("unexpected number of parameters, wanted %d, got %d", len(want), len(params))
+	}
+	for i := range want {
+		if params[i] != want[i] {
+			t.Errorf("unexpected parameter %d, wanted %+v, got %+v", i, want[i], params[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package ctxutil
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// ReadOnlyGuard rejects all modifications of the context it wraps, using a
+// tosca.ReadOnlyTransactionContext. A guard is intended to wrap a single
+// context; the first rejected modification is reported by Err.
+type ReadOnlyGuard struct {
+	context *tosca.ReadOnlyTransactionContext
+}
+
+// Wrap is a Middleware wrapping the given context in a read-only context.
+func (g *ReadOnlyGuard) Wrap(context tosca.TransactionContext) tosca.TransactionContext {
+	g.context = tosca.NewReadOnlyTransactionContext(context)
+	return g.context
+}
+
+// Err returns the first rejected modification, or nil if no modification
+// was attempted.
+func (g *ReadOnlyGuard) Err() error {
+	if g.context == nil {
+		return nil
+	}
+	return g.context.Err()
+}
+
+// Recorder records the calls to the contexts it wraps in the order they got
+// issued. The zero value is an empty recorder ready to use. Recorders are
+// safe for concurrent use.
+type Recorder struct {
+	mutex sync.Mutex
+	calls []Call
+}
+
+// Wrap is a Middleware recording the calls to the given context.
+func (r *Recorder) Wrap(context tosca.TransactionContext) tosca.TransactionContext {
+	return Intercept(context, func(call Call) {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.calls = append(r.calls, call)
+	})
+}
+
+// Calls returns the recorded calls.
+func (r *Recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.calls)
+}
+
+// Reset removes all recorded calls.
+func (r *Recorder) Reset() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.calls = nil
+}
+
+// Counter counts the calls to the contexts it wraps by operation. The zero
+// value is a counter ready to use. Counters are safe for concurrent use.
+type Counter struct {
+	mutex  sync.Mutex
+	counts [numOperations]int
+}
+
+// Wrap is a Middleware counting the calls to the given context.
+func (c *Counter) Wrap(context tosca.TransactionContext) tosca.TransactionContext {
+	return Intercept(context, func(call Call) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.counts[call.Operation]++
+	})
+}
+
+// Count returns the number of calls of the given operation.
+func (c *Counter) Count(op Operation) int {
+	if op < 0 || op >= numOperations {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.counts[op]
+}
+
+// Reads returns the number of calls of operations not modifying the state.
+func (c *Counter) Reads() int {
+	return c.sum(func(op Operation) bool { return !op.IsWrite() })
+}
+
+// Writes returns the number of calls of operations modifying the state.
+func (c *Counter) Writes() int {
+	return c.sum(Operation.IsWrite)
+}
+
+func (c *Counter) sum(filter func(Operation) bool) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	res := 0
+	for op, count := range c.counts {
+		if filter(Operation(op)) {
+			res += count
+		}
+	}
+	return res
+}
+
+// Latency returns a Middleware delaying the given operations by the given
+// duration, simulating a slow state backend. If no operations are listed,
+// all operations are delayed.
+func Latency(delay time.Duration, operations ...Operation) Middleware {
+	delayed := [numOperations]bool{}
+	for _, op := range operations {
+		if op >= 0 && op < numOperations {
+			delayed[op] = true
+		}
+	}
+	all := len(operations) == 0
+	return InterceptorMiddleware(func(call Call) {
+		if all || delayed[call.Operation] {
+			time.Sleep(delay)
+		}
+	})
+}
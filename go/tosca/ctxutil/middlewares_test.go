@@ -0,0 +1,156 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package ctxutil
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReadOnlyGuard_ReadsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetBalance(tosca.Address{1}).Return(tosca.Value{2})
+
+	guard := &ReadOnlyGuard{}
+	context := guard.Wrap(mock)
+
+	if got, want := context.GetBalance(tosca.Address{1}), (tosca.Value{2}); got != want {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if err := guard.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReadOnlyGuard_ModificationsAreRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	// The wrapped context is not expected to receive any calls.
+	mock := tosca.NewMockTransactionContext(ctrl)
+
+	guard := &ReadOnlyGuard{}
+	context := Wrap(mock, guard.Wrap)
+	context.SetNonce(tosca.Address{1}, 2)
+
+	var violation *tosca.ErrReadOnlyContextViolation
+	if !errors.As(guard.Err(), &violation) {
+		t.Fatalf("expected read-only context violation, got %v", guard.Err())
+	}
+}
+
+func TestReadOnlyGuard_UnusedGuardReportsNoError(t *testing.T) {
+	guard := &ReadOnlyGuard{}
+	if err := guard.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRecorder_RecordsCallsInOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetNonce(tosca.Address{1})
+	mock.EXPECT().SetStorage(tosca.Address{2}, tosca.Key{3}, tosca.Word{4})
+
+	recorder := &Recorder{}
+	context := Wrap(mock, recorder.Wrap)
+	context.GetNonce(tosca.Address{1})
+	context.SetStorage(tosca.Address{2}, tosca.Key{3}, tosca.Word{4})
+
+	want := []Call{
+		{Operation: OpGetNonce, Address: tosca.Address{1}},
+		{Operation: OpSetStorage, Address: tosca.Address{2}, Key: tosca.Key{3}},
+	}
+	if got := recorder.Calls(); !slices.Equal(got, want) {
+		t.Errorf("unexpected calls, wanted %v, got %v", want, got)
+	}
+
+	recorder.Reset()
+	if got := recorder.Calls(); len(got) != 0 {
+		t.Errorf("expected no calls after reset, got %v", got)
+	}
+}
+
+func TestCounter_CountsCallsPerOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetBalance(gomock.Any()).Times(3)
+	mock.EXPECT().SetBalance(gomock.Any(), gomock.Any()).Times(2)
+
+	counter := &Counter{}
+	context := Wrap(mock, counter.Wrap)
+	for i := 0; i < 3; i++ {
+		context.GetBalance(tosca.Address{byte(i)})
+	}
+	for i := 0; i < 2; i++ {
+		context.SetBalance(tosca.Address{byte(i)}, tosca.Value{})
+	}
+
+	if got, want := counter.Count(OpGetBalance), 3; got != want {
+		t.Errorf("unexpected number of GetBalance calls, wanted %d, got %d", want, got)
+	}
+	if got, want := counter.Count(OpSetBalance), 2; got != want {
+		t.Errorf("unexpected number of SetBalance calls, wanted %d, got %d", want, got)
+	}
+	if got, want := counter.Count(OpGetCode), 0; got != want {
+		t.Errorf("unexpected number of GetCode calls, wanted %d, got %d", want, got)
+	}
+	if got, want := counter.Count(numOperations), 0; got != want {
+		t.Errorf("unexpected number of invalid calls, wanted %d, got %d", want, got)
+	}
+	if got, want := counter.Reads(), 3; got != want {
+		t.Errorf("unexpected number of reads, wanted %d, got %d", want, got)
+	}
+	if got, want := counter.Writes(), 2; got != want {
+		t.Errorf("unexpected number of writes, wanted %d, got %d", want, got)
+	}
+}
+
+func TestLatency_DelaysSelectedOperations(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetStorage(gomock.Any(), gomock.Any())
+	mock.EXPECT().GetNonce(gomock.Any())
+
+	context := Wrap(mock, Latency(delay, OpGetStorage))
+
+	start := time.Now()
+	context.GetStorage(tosca.Address{}, tosca.Key{})
+	if got := time.Since(start); got < delay {
+		t.Errorf("expected GetStorage to be delayed by at least %v, got %v", delay, got)
+	}
+
+	start = time.Now()
+	context.GetNonce(tosca.Address{})
+	if got := time.Since(start); got >= delay {
+		t.Errorf("expected GetNonce not to be delayed, got %v", got)
+	}
+}
+
+func TestLatency_WithoutOperationsDelaysAllOperations(t *testing.T) {
+	const delay = 10 * time.Millisecond
+	ctrl := gomock.NewController(t)
+	mock := tosca.NewMockTransactionContext(ctrl)
+	mock.EXPECT().GetNonce(gomock.Any())
+
+	context := Wrap(mock, Latency(delay))
+
+	start := time.Now()
+	context.GetNonce(tosca.Address{})
+	if got := time.Since(start); got < delay {
+		t.Errorf("expected GetNonce to be delayed by at least %v, got %v", delay, got)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// DispatchConfig defines the routing of calls by an interpreter created
+// through NewDispatchingInterpreter.
+type DispatchConfig struct {
+	// Default is the interpreter used for all calls not matched by any of
+	// the other routes. It is required.
+	Default Interpreter
+	// ByCodeHash routes codes with the given hashes to the associated
+	// interpreters. Routes by code hash take precedence over address routes.
+	ByCodeHash map[Hash]Interpreter
+	// ByAddress routes calls by the address of the account executing the
+	// code. If multiple ranges contain an address, the first one is used.
+	ByAddress []AddressRoute
+}
+
+// AddressRoute routes calls with a recipient in the inclusive range of
+// addresses [First, Last] to the given interpreter.
+type AddressRoute struct {
+	First, Last Address
+	Interpreter Interpreter
+}
+
+// Contains reports whether the given address is covered by this route.
+func (r AddressRoute) Contains(address Address) bool {
+	return bytes.Compare(r.First[:], address[:]) <= 0 &&
+		bytes.Compare(address[:], r.Last[:]) <= 0
+}
+
+// NewDispatchingInterpreter creates an interpreter forwarding each call to
+// one of the configured interpreters, selected by the hash of the executed
+// code or the address of the account executing it. This way, selected
+// contracts may be run by an interpreter optimized for them, while all other
+// contracts are run by the default interpreter.
+//
+// Calls are routed individually, such that nested calls may be run by a
+// different interpreter than their caller. For code executed through
+// DELEGATECALL or CALLCODE, the address used for routing is the address of
+// the calling account, not the account providing the code. Since init codes
+// have no code hash, creations may only be routed by address.
+func NewDispatchingInterpreter(config DispatchConfig) (Interpreter, error) {
+	if config.Default == nil {
+		return nil, fmt.Errorf("invalid dispatch configuration: no default interpreter")
+	}
+	for hash, interpreter := range config.ByCodeHash {
+		if interpreter == nil {
+			return nil, fmt.Errorf("invalid dispatch configuration: no interpreter for code hash %v", hash)
+		}
+	}
+	for i, route := range config.ByAddress {
+		if route.Interpreter == nil {
+			return nil, fmt.Errorf("invalid dispatch configuration: no interpreter for address route %d", i)
+		}
+		if bytes.Compare(route.First[:], route.Last[:]) > 0 {
+			return nil, fmt.Errorf("invalid dispatch configuration: empty address route %d", i)
+		}
+	}
+	return &dispatchingInterpreter{config: config}, nil
+}
+
+type dispatchingInterpreter struct {
+	config DispatchConfig
+}
+
+func (i *dispatchingInterpreter) Run(params Parameters) (Result, error) {
+	return i.route(params).Run(params)
+}
+
+// route selects the interpreter to run the given call.
+func (i *dispatchingInterpreter) route(params Parameters) Interpreter {
+	if params.CodeHash != nil {
+		if interpreter, found := i.config.ByCodeHash[*params.CodeHash]; found {
+			return interpreter
+		}
+	}
+	for _, route := range i.config.ByAddress {
+		if route.Contains(params.Recipient) {
+			return route.Interpreter
+		}
+	}
+	return i.config.Default
+}
+
+// WarmUp forwards the given codes to all configured interpreters supporting
+// warm-ups. Since the route of a code may depend on the address it is run
+// by, codes are not filtered by their routes.
+func (i *dispatchingInterpreter) WarmUp(codes ...[]byte) {
+	interpreters := []Interpreter{i.config.Default}
+	for _, interpreter := range i.config.ByCodeHash {
+		interpreters = append(interpreters, interpreter)
+	}
+	for _, route := range i.config.ByAddress {
+		interpreters = append(interpreters, route.Interpreter)
+	}
+	for _, interpreter := range interpreters {
+		if target, ok := interpreter.(WarmUpTarget); ok {
+			target.WarmUp(codes...)
+		}
+	}
+}
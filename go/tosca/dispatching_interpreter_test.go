@@ -0,0 +1,92 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestDispatchingInterpreter_CallsAreRoutedToConfiguredInterpreters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	fallback := NewMockInterpreter(ctrl)
+	byHash := NewMockInterpreter(ctrl)
+	byLowRange := NewMockInterpreter(ctrl)
+	byHighRange := NewMockInterpreter(ctrl)
+
+	hash := Hash{1}
+	interpreter, err := NewDispatchingInterpreter(DispatchConfig{
+		Default:    fallback,
+		ByCodeHash: map[Hash]Interpreter{hash: byHash},
+		ByAddress: []AddressRoute{
+			{First: Address{0x10}, Last: Address{0x1f}, Interpreter: byLowRange},
+			{First: Address{0x18}, Last: Address{0x2f}, Interpreter: byHighRange},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+
+	otherHash := Hash{2}
+	tests := map[string]struct {
+		recipient Address
+		codeHash  *Hash
+		want      *MockInterpreter
+	}{
+		"unmatched":                 {Address{0x01}, &otherHash, fallback},
+		"unmatched without hash":    {Address{0x01}, nil, fallback},
+		"code hash":                 {Address{0x01}, &hash, byHash},
+		"code hash before address":  {Address{0x10}, &hash, byHash},
+		"lower bound":               {Address{0x10}, nil, byLowRange},
+		"upper bound":               {Address{0x1f}, &otherHash, byLowRange},
+		"first matching range":      {Address{0x18}, nil, byLowRange},
+		"second range":              {Address{0x20}, nil, byHighRange},
+		"last address of range":     {Address{0x2f}, nil, byHighRange},
+		"beyond last address range": {Address{0x2f, 1}, nil, fallback},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := Parameters{Recipient: test.recipient, CodeHash: test.codeHash}
+			test.want.EXPECT().Run(params).Return(Result{Success: true}, nil)
+			result, err := interpreter.Run(params)
+			if err != nil || !result.Success {
+				t.Errorf("unexpected result: %v, %v", result, err)
+			}
+		})
+	}
+}
+
+func TestDispatchingInterpreter_InvalidConfigurationsAreRejected(t *testing.T) {
+	interpreter := NewMockInterpreter(gomock.NewController(t))
+	tests := map[string]DispatchConfig{
+		"no default": {},
+		"nil interpreter for code hash": {
+			Default:    interpreter,
+			ByCodeHash: map[Hash]Interpreter{{1}: nil},
+		},
+		"nil interpreter for address range": {
+			Default:   interpreter,
+			ByAddress: []AddressRoute{{First: Address{1}, Last: Address{2}}},
+		},
+		"empty address range": {
+			Default:   interpreter,
+			ByAddress: []AddressRoute{{First: Address{2}, Last: Address{1}, Interpreter: interpreter}},
+		},
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewDispatchingInterpreter(config); err == nil {
+				t.Errorf("expected configuration to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxFailureStackSize is the maximum number of stack elements recorded by an
+// ExecutionFailure. Deeper elements are omitted.
+const MaxFailureStackSize = 8
+
+// ExecutionFailure describes the state of an interpreter at the point an
+// execution failed, e.g. due to running out of gas or a stack underflow.
+// Interpreters supporting diagnostics report it through Result.Failure.
+type ExecutionFailure struct {
+	Pc        uint64 // < the position of the failing instruction in the code
+	Operation string // < the name of the failing instruction
+	GasLeft   Gas    // < the gas left when the instruction failed
+	Stack     []Word // < the top-most elements of the stack, top first, truncated to MaxFailureStackSize
+	StackSize int    // < the total number of elements on the stack
+	Cause     error  // < the reason of the failure
+}
+
+func (f *ExecutionFailure) Error() string {
+	var stack strings.Builder
+	for i, value := range f.Stack {
+		if i > 0 {
+			stack.WriteString(", ")
+		}
+		fmt.Fprintf(&stack, "%x", value[:])
+	}
+	if omitted := f.StackSize - len(f.Stack); omitted > 0 {
+		fmt.Fprintf(&stack, ", ... %d more", omitted)
+	}
+	return fmt.Sprintf("execution failed at pc %d (%s) with %d gas left and stack [%s]: %v",
+		f.Pc, f.Operation, f.GasLeft, stack.String(), f.Cause)
+}
+
+func (f *ExecutionFailure) Unwrap() error {
+	return f.Cause
+}
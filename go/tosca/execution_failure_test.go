@@ -0,0 +1,42 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestExecutionFailure_ErrorDescribesFailure(t *testing.T) {
+	cause := ConstError("out of gas")
+	failure := &ExecutionFailure{
+		Pc:        12,
+		Operation: "SSTORE",
+		GasLeft:   7,
+		Stack:     []Word{{31: 1}, {31: 2}},
+		StackSize: 5,
+		Cause:     cause,
+	}
+	msg := failure.Error()
+	for _, want := range []string{
+		"pc 12", "SSTORE", "7 gas left",
+		strings.Repeat("0", 62) + "01, " + strings.Repeat("0", 62) + "02, ... 3 more",
+		"out of gas",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error message %q does not contain %q", msg, want)
+		}
+	}
+	if !errors.Is(failure, cause) {
+		t.Errorf("failure should wrap its cause")
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ExecutionProfiler records the wall-clock time spent on the execution of
+// transactions and contracts. It is intended to be used by block builders to
+// identify contracts consuming an unusually high amount of time per unit of
+// gas. Interpreters and processors report to a profiler when wrapped through
+// NewProfilingInterpreter and NewProfilingProcessor. Profiles are accumulated
+// until the profiler is reset. An ExecutionProfiler is safe for concurrent use.
+type ExecutionProfiler struct {
+	mutex        sync.Mutex
+	transactions []TransactionProfile
+	contracts    map[Address]*ContractProfile
+}
+
+// TransactionProfile summarizes the processing of a single transaction.
+type TransactionProfile struct {
+	Sender    Address
+	Recipient *Address // < nil for contract creations
+	Nonce     uint64
+	GasUsed   Gas
+	Time      time.Duration
+}
+
+// ContractProfile summarizes the executions of the code of a single account.
+// Time and gas only cover the code of the account itself, not the contracts
+// called by it. Executions of code through DELEGATECALL and CALLCODE are
+// attributed to the calling account, whose storage is accessed.
+type ContractProfile struct {
+	Address Address
+	Calls   int
+	GasUsed Gas
+	Time    time.Duration
+}
+
+// TimePerGas returns the average time spent per unit of gas used by the
+// contract, or zero if no gas was used.
+func (p ContractProfile) TimePerGas() time.Duration {
+	if p.GasUsed <= 0 {
+		return 0
+	}
+	return p.Time / time.Duration(p.GasUsed)
+}
+
+// NewExecutionProfiler creates an empty profiler.
+func NewExecutionProfiler() *ExecutionProfiler {
+	return &ExecutionProfiler{
+		contracts: map[Address]*ContractProfile{},
+	}
+}
+
+// Transactions returns the profiles of the transactions processed since the
+// last reset, in the order of their completion.
+func (p *ExecutionProfiler) Transactions() []TransactionProfile {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return slices.Clone(p.transactions)
+}
+
+// Contracts returns the profiles of all contracts executed since the last
+// reset, ordered by descending time per gas.
+func (p *ExecutionProfiler) Contracts() []ContractProfile {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	res := make([]ContractProfile, 0, len(p.contracts))
+	for _, profile := range p.contracts {
+		res = append(res, *profile)
+	}
+	slices.SortFunc(res, func(a, b ContractProfile) int {
+		if c := cmp.Compare(b.TimePerGas(), a.TimePerGas()); c != 0 {
+			return c
+		}
+		return slices.Compare(a.Address[:], b.Address[:])
+	})
+	return res
+}
+
+// Reset discards all recorded profiles.
+func (p *ExecutionProfiler) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.transactions = nil
+	p.contracts = map[Address]*ContractProfile{}
+}
+
+func (p *ExecutionProfiler) addTransaction(profile TransactionProfile) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.transactions = append(p.transactions, profile)
+}
+
+func (p *ExecutionProfiler) addExecution(address Address, gas Gas, duration time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	profile, found := p.contracts[address]
+	if !found {
+		profile = &ContractProfile{Address: address}
+		p.contracts[address] = profile
+	}
+	profile.Calls++
+	profile.GasUsed += gas
+	profile.Time += duration
+}
+
+// NewProfilingInterpreter wraps the given interpreter such that the time and
+// gas used by each of its executions, excluding nested calls, is recorded by
+// the given profiler. Nested calls are only recognized if they are conducted
+// through the RunContext; interpreters conducting them internally, like the
+// geth interpreter, attribute them to the calling contract.
+func NewProfilingInterpreter(interpreter Interpreter, profiler *ExecutionProfiler) Interpreter {
+	return &profilingInterpreter{
+		Interpreter: interpreter,
+		profiler:    profiler,
+	}
+}
+
+type profilingInterpreter struct {
+	Interpreter
+	profiler *ExecutionProfiler
+}
+
+func (i *profilingInterpreter) Run(params Parameters) (Result, error) {
+	context := &profilingRunContext{RunContext: params.Context}
+	params.Context = context
+	start := time.Now()
+	result, err := i.Interpreter.Run(params)
+	duration := time.Since(start) - context.nestedTime
+	gas := params.Gas - result.GasLeft - context.nestedGas
+	i.profiler.addExecution(params.Recipient, max(gas, 0), duration)
+	return result, err
+}
+
+// profilingRunContext accumulates the time and gas used by nested calls,
+// which are excluded from the profile of the calling contract.
+type profilingRunContext struct {
+	RunContext
+	nestedTime time.Duration
+	nestedGas  Gas
+}
+
+func (c *profilingRunContext) Call(kind CallKind, parameter CallParameters) (CallResult, error) {
+	start := time.Now()
+	result, err := c.RunContext.Call(kind, parameter)
+	c.nestedTime += time.Since(start)
+	c.nestedGas += parameter.Gas - result.GasLeft
+	return result, err
+}
+
+// NewProfilingProcessor wraps the given processor such that the time spent on
+// each of its transactions is recorded by the given profiler. All optional
+// extensions of the processor, like SimulatingProcessor and
+// SystemCallProcessor, are supported by the resulting processor as well;
+// their calls are not profiled. However, contracts executed by simulated
+// transactions are still recorded by profiling interpreters.
+func NewProfilingProcessor(processor Processor, profiler *ExecutionProfiler) Processor {
+	profiling := &profilingProcessor{
+		Processor: processor,
+		profiler:  profiler,
+	}
+	return forwardExtensions(profiling, processor)
+}
+
+type profilingProcessor struct {
+	Processor
+	profiler *ExecutionProfiler
+}
+
+func (p *profilingProcessor) Run(
+	ctx context.Context,
+	blockParameters BlockParameters,
+	transaction Transaction,
+	txContext TransactionContext,
+) (Receipt, error) {
+	start := time.Now()
+	receipt, err := p.Processor.Run(ctx, blockParameters, transaction, txContext)
+	p.profiler.addTransaction(TransactionProfile{
+		Sender:    transaction.Sender,
+		Recipient: transaction.Recipient,
+		Nonce:     transaction.Nonce,
+		GasUsed:   receipt.GasUsed,
+		Time:      time.Since(start),
+	})
+	return receipt, err
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestProfilingInterpreter_NestedCallsAreExcludedFromCaller(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	runContext := NewMockRunContext(ctrl)
+	inner := NewMockInterpreter(ctrl)
+
+	caller := Address{1}
+	callee := Address{2}
+
+	profiler := NewExecutionProfiler()
+	interpreter := NewProfilingInterpreter(inner, profiler)
+
+	// The callee uses 30 gas, the caller 100 gas in addition to the 50 gas
+	// provided to the callee, of which 20 are returned.
+	runContext.EXPECT().Call(Call, gomock.Any()).DoAndReturn(func(_ CallKind, parameters CallParameters) (CallResult, error) {
+		result, err := interpreter.Run(Parameters{Context: runContext, Recipient: callee, Gas: parameters.Gas})
+		return CallResult{Success: result.Success, GasLeft: result.GasLeft}, err
+	})
+	inner.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		if params.Recipient == callee {
+			time.Sleep(10 * time.Millisecond)
+			return Result{Success: true, GasLeft: params.Gas - 30}, nil
+		}
+		if _, err := params.Context.Call(Call, CallParameters{Gas: 50}); err != nil {
+			return Result{}, err
+		}
+		return Result{Success: true, GasLeft: params.Gas - 130}, nil
+	}).Times(2)
+
+	if _, err := interpreter.Run(Parameters{Context: runContext, Recipient: caller, Gas: 1000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	profiles := map[Address]ContractProfile{}
+	for _, profile := range profiler.Contracts() {
+		profiles[profile.Address] = profile
+	}
+	if want, got := 2, len(profiles); want != got {
+		t.Fatalf("unexpected number of profiled contracts, wanted %d, got %d", want, got)
+	}
+	if want, got := Gas(100), profiles[caller].GasUsed; want != got {
+		t.Errorf("unexpected gas of caller, wanted %d, got %d", want, got)
+	}
+	if want, got := Gas(30), profiles[callee].GasUsed; want != got {
+		t.Errorf("unexpected gas of callee, wanted %d, got %d", want, got)
+	}
+	if profiles[callee].Time < 10*time.Millisecond {
+		t.Errorf("time of callee is too short, got %v", profiles[callee].Time)
+	}
+	if profiles[caller].Time >= profiles[callee].Time {
+		t.Errorf("time of callee should be excluded from caller, got %v for caller and %v for callee", profiles[caller].Time, profiles[callee].Time)
+	}
+	for _, profile := range profiles {
+		if want, got := 1, profile.Calls; want != got {
+			t.Errorf("unexpected number of calls of %v, wanted %d, got %d", profile.Address, want, got)
+		}
+	}
+}
+
+func TestExecutionProfiler_ContractsAreSortedByTimePerGas(t *testing.T) {
+	profiler := NewExecutionProfiler()
+	profiler.addExecution(Address{1}, 100, 100*time.Microsecond)
+	profiler.addExecution(Address{2}, 100, 300*time.Microsecond)
+	profiler.addExecution(Address{3}, 100, 200*time.Microsecond)
+	profiler.addExecution(Address{1}, 100, 100*time.Microsecond)
+
+	contracts := profiler.Contracts()
+	want := []ContractProfile{
+		{Address: Address{2}, Calls: 1, GasUsed: 100, Time: 300 * time.Microsecond},
+		{Address: Address{3}, Calls: 1, GasUsed: 100, Time: 200 * time.Microsecond},
+		{Address: Address{1}, Calls: 2, GasUsed: 200, Time: 200 * time.Microsecond},
+	}
+	if len(want) != len(contracts) {
+		t.Fatalf("unexpected number of contracts, wanted %d, got %d", len(want), len(contracts))
+	}
+	for i := range want {
+		if want[i] != contracts[i] {
+			t.Errorf("unexpected profile at position %d, wanted %v, got %v", i, want[i], contracts[i])
+		}
+	}
+	if want, got := 3*time.Microsecond, contracts[0].TimePerGas(); want != got {
+		t.Errorf("unexpected time per gas, wanted %v, got %v", want, got)
+	}
+}
+
+func TestContractProfile_TimePerGasIsZeroWithoutGas(t *testing.T) {
+	profile := ContractProfile{Time: time.Second}
+	if want, got := time.Duration(0), profile.TimePerGas(); want != got {
+		t.Errorf("unexpected time per gas, wanted %v, got %v", want, got)
+	}
+}
+
+func TestProfilingProcessor_TransactionsAreRecorded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockProcessor(ctrl)
+	inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Receipt{Success: true, GasUsed: 21000}, nil)
+
+	profiler := NewExecutionProfiler()
+	processor := NewProfilingProcessor(inner, profiler)
+	if _, ok := processor.(SimulatingProcessor); ok {
+		t.Errorf("processor should not support simulations if the wrapped one does not")
+	}
+
+	recipient := Address{2}
+	transaction := Transaction{Sender: Address{1}, Recipient: &recipient, Nonce: 7}
+	if _, err := processor.Run(context.Background(), BlockParameters{}, transaction, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	transactions := profiler.Transactions()
+	if want, got := 1, len(transactions); want != got {
+		t.Fatalf("unexpected number of transactions, wanted %d, got %d", want, got)
+	}
+	got := transactions[0]
+	if got.Sender != transaction.Sender || got.Recipient != transaction.Recipient || got.Nonce != 7 || got.GasUsed != 21000 {
+		t.Errorf("unexpected transaction profile, got %+v", got)
+	}
+
+	profiler.Reset()
+	if want, got := 0, len(profiler.Transactions()); want != got {
+		t.Errorf("unexpected number of transactions after reset, wanted %d, got %d", want, got)
+	}
+}
+
+func TestProfilingProcessor_SimulationsAreForwardedWithoutProfiling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockSimulatingProcessor(ctrl)
+	inner.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(SimulationResult{}, nil)
+
+	profiler := NewExecutionProfiler()
+	processor, ok := NewProfilingProcessor(inner, profiler).(SimulatingProcessor)
+	if !ok {
+		t.Fatalf("profiling processor does not support simulations")
+	}
+	if _, err := processor.Simulate(context.Background(), BlockParameters{}, Transaction{}, nil, SimulationOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := 0, len(profiler.Transactions()); want != got {
+		t.Errorf("simulations should not be profiled, got %d transactions", got)
+	}
+}
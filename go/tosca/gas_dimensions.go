@@ -0,0 +1,93 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "fmt"
+
+// GasDimension identifies a resource consumed by transactions that is
+// accounted for separately. Besides the gas used for the execution, blob
+// transactions consume blob gas (EIP-4844), and starting with EIP-7623 the
+// input of transactions is subject to a minimum charge. New dimensions may be
+// added by future revisions.
+type GasDimension int
+
+const (
+	// GasDimensionExecution is the gas used by the execution of a
+	// transaction, including its intrinsic costs. It is the gas paid for
+	// at the gas price of the transaction.
+	GasDimensionExecution GasDimension = iota
+	// GasDimensionBlob is the gas used by the blobs of a transaction, which
+	// is priced by a separate fee market (EIP-4844).
+	GasDimensionBlob
+	// GasDimensionCalldataFloor is the minimum amount of execution gas
+	// charged for the input of a transaction (EIP-7623). It is zero for
+	// revisions not enforcing such a floor.
+	GasDimensionCalldataFloor
+
+	numGasDimensions
+)
+
+// AllGasDimensions returns all known gas dimensions.
+func AllGasDimensions() []GasDimension {
+	res := make([]GasDimension, 0, numGasDimensions)
+	for d := GasDimension(0); d < numGasDimensions; d++ {
+		res = append(res, d)
+	}
+	return res
+}
+
+func (d GasDimension) String() string {
+	switch d {
+	case GasDimensionExecution:
+		return "execution"
+	case GasDimensionBlob:
+		return "blob"
+	case GasDimensionCalldataFloor:
+		return "calldata-floor"
+	}
+	return fmt.Sprintf("GasDimension(%d)", int(d))
+}
+
+// MultiGas lists an amount of gas for each gas dimension. The zero value
+// lists no gas in any dimension.
+type MultiGas [numGasDimensions]Gas
+
+// Get returns the gas of the given dimension, which is zero for unknown
+// dimensions.
+func (g MultiGas) Get(dimension GasDimension) Gas {
+	if dimension < 0 || dimension >= numGasDimensions {
+		return 0
+	}
+	return g[dimension]
+}
+
+// Set updates the gas of the given dimension. Updates of unknown dimensions
+// are ignored.
+func (g *MultiGas) Set(dimension GasDimension, gas Gas) {
+	if dimension < 0 || dimension >= numGasDimensions {
+		return
+	}
+	g[dimension] = gas
+}
+
+// Add returns the dimension-wise sum of both gas amounts.
+func (g MultiGas) Add(other MultiGas) MultiGas {
+	for i := range g {
+		g[i] += other[i]
+	}
+	return g
+}
+
+func (g MultiGas) String() string {
+	return fmt.Sprintf("{execution: %d, blob: %d, calldata-floor: %d}",
+		g[GasDimensionExecution], g[GasDimensionBlob], g[GasDimensionCalldataFloor],
+	)
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "testing"
+
+func TestGasDimension_String(t *testing.T) {
+	tests := map[GasDimension]string{
+		GasDimensionExecution:     "execution",
+		GasDimensionBlob:          "blob",
+		GasDimensionCalldataFloor: "calldata-floor",
+		GasDimension(42):          "GasDimension(42)",
+	}
+	for dimension, want := range tests {
+		if got := dimension.String(); want != got {
+			t.Errorf("unexpected string, wanted %q, got %q", want, got)
+		}
+	}
+}
+
+func TestAllGasDimensions_ListsEachDimensionOnce(t *testing.T) {
+	seen := map[GasDimension]bool{}
+	for _, dimension := range AllGasDimensions() {
+		if seen[dimension] {
+			t.Errorf("dimension %v listed twice", dimension)
+		}
+		seen[dimension] = true
+	}
+	if want, got := int(numGasDimensions), len(seen); want != got {
+		t.Errorf("unexpected number of dimensions, wanted %d, got %d", want, got)
+	}
+}
+
+func TestMultiGas_SetAndGet(t *testing.T) {
+	var gas MultiGas
+	for i, dimension := range AllGasDimensions() {
+		gas.Set(dimension, Gas(i+1))
+	}
+	for i, dimension := range AllGasDimensions() {
+		if want, got := Gas(i+1), gas.Get(dimension); want != got {
+			t.Errorf("unexpected gas of dimension %v, wanted %d, got %d", dimension, want, got)
+		}
+	}
+}
+
+func TestMultiGas_UnknownDimensionsAreIgnored(t *testing.T) {
+	var gas MultiGas
+	gas.Set(numGasDimensions, 12)
+	gas.Set(-1, 12)
+	if gas != (MultiGas{}) {
+		t.Errorf("unexpected update of gas, got %v", gas)
+	}
+	if want, got := Gas(0), gas.Get(numGasDimensions); want != got {
+		t.Errorf("unexpected gas of unknown dimension, wanted %d, got %d", want, got)
+	}
+}
+
+func TestMultiGas_Add(t *testing.T) {
+	a := MultiGas{1, 2, 3}
+	b := MultiGas{10, 20, 30}
+	if want, got := (MultiGas{11, 22, 33}), a.Add(b); want != got {
+		t.Errorf("unexpected sum, wanted %v, got %v", want, got)
+	}
+	if want, got := (MultiGas{1, 2, 3}), a; want != got {
+		t.Errorf("summand was modified, wanted %v, got %v", want, got)
+	}
+}
+
+func TestReceipt_GasUsageCoversAllDimensions(t *testing.T) {
+	receipt := Receipt{GasUsed: 1, BlobGasUsed: 2, CalldataFloorGas: 3}
+	usage := receipt.GasUsage()
+	if want, got := (MultiGas{1, 2, 3}), usage; want != got {
+		t.Errorf("unexpected gas usage, wanted %v, got %v", want, got)
+	}
+
+	restored := Receipt{}
+	restored.SetGasUsage(usage)
+	if want, got := receipt.GasUsage(), restored.GasUsage(); want != got {
+		t.Errorf("unexpected gas usage after update, wanted %v, got %v", want, got)
+	}
+}
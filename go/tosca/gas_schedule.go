@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"math"
+	"math/bits"
+)
+
+// GasSchedule defines the dynamic gas costs of EVM instructions, which depend
+// on the operands of an instruction or the state it operates on. All costs
+// are parameterized by the revision the instruction is executed in.
+//
+// EthereumGasSchedule provides the costs defined by Ethereum. Chains using
+// different costs can embed it in their own schedule and override individual
+// methods.
+type GasSchedule interface {
+	// MemoryCost returns the total cost of a memory of the given number of
+	// 32-byte words. Expanding the memory is charged by the difference
+	// between the costs of the new and the old size. Costs exceeding the
+	// range of Gas saturate at math.MaxInt64.
+	MemoryCost(revision Revision, words uint64) Gas
+
+	// CopyCost returns the cost of copying the given number of words, as
+	// conducted by CALLDATACOPY, CODECOPY, EXTCODECOPY, RETURNDATACOPY, and
+	// MCOPY. It is charged in addition to the static costs and any memory
+	// expansion costs.
+	CopyCost(revision Revision, words uint64) Gas
+
+	// HashCost returns the cost of hashing the given number of words, as
+	// conducted by SHA3 and CREATE2.
+	HashCost(revision Revision, words uint64) Gas
+
+	// ExpCost returns the cost of an EXP instruction for an exponent with the
+	// given number of bytes, not including leading zero bytes.
+	ExpCost(revision Revision, exponentBytes int) Gas
+
+	// SstoreCost returns the cost of an SSTORE instruction causing the given
+	// storage status, not including the costs of cold storage accesses.
+	SstoreCost(revision Revision, status StorageStatus) Gas
+
+	// SstoreRefund returns the gas refund granted for an SSTORE instruction
+	// causing the given storage status. The result may be negative if a
+	// refund granted by an earlier SSTORE is to be revoked.
+	SstoreRefund(revision Revision, status StorageStatus) Gas
+
+	// NewAccountCost returns the cost of creating an account by transferring
+	// value to an empty account through CALL or SELFDESTRUCT, as defined by
+	// IsEmptyAccount.
+	NewAccountCost(revision Revision) Gas
+}
+
+// EthereumGasSchedule is the GasSchedule defined by Ethereum.
+type EthereumGasSchedule struct{}
+
+func (EthereumGasSchedule) MemoryCost(_ Revision, words uint64) Gas {
+	// The quadratic term overflows uint64 for sizes beyond 2^32 words. It is
+	// thus computed with 128 bits, and costs exceeding the range of Gas,
+	// which are far beyond any realistic gas limit, saturate.
+	squareHigh, squareLow := bits.Mul64(words, words)
+	if squareHigh >= 512 {
+		return math.MaxInt64
+	}
+	quadratic := squareHigh<<55 | squareLow>>9
+	linearHigh, linear := bits.Mul64(3, words)
+	cost, carry := bits.Add64(quadratic, linear, 0)
+	if linearHigh != 0 || carry != 0 || cost > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return Gas(cost)
+}
+
+func (EthereumGasSchedule) CopyCost(_ Revision, words uint64) Gas {
+	return Gas(3 * words)
+}
+
+func (EthereumGasSchedule) HashCost(_ Revision, words uint64) Gas {
+	return Gas(6 * words)
+}
+
+func (EthereumGasSchedule) ExpCost(_ Revision, exponentBytes int) Gas {
+	return Gas(50 * exponentBytes)
+}
+
+func (EthereumGasSchedule) SstoreCost(revision Revision, status StorageStatus) Gas {
+	switch status {
+	case StorageAdded:
+		return 20000
+	case StorageModified,
+		StorageDeleted:
+		if revision >= R09_Berlin {
+			return 2900
+		}
+		return 5000
+	default:
+		if revision >= R09_Berlin {
+			return 100
+		}
+		return 800
+	}
+}
+
+func (EthereumGasSchedule) SstoreRefund(revision Revision, status StorageStatus) Gas {
+	switch status {
+	case StorageDeleted,
+		StorageModifiedDeleted:
+		if revision >= R10_London {
+			return 4800
+		}
+		return 15000
+	case StorageDeletedAdded:
+		if revision >= R10_London {
+			return -4800
+		}
+		return -15000
+	case StorageDeletedRestored:
+		if revision >= R10_London {
+			return -4800 + 5000 - 2100 - 100
+		} else if revision >= R09_Berlin {
+			return -15000 + 5000 - 2100 - 100
+		}
+		return -15000 + 4200
+	case StorageAddedDeleted:
+		if revision >= R09_Berlin {
+			return 19900
+		}
+		return 19200
+	case StorageModifiedRestored:
+		if revision >= R09_Berlin {
+			return 5000 - 2100 - 100
+		}
+		return 4200
+	default:
+		return 0
+	}
+}
+
+func (EthereumGasSchedule) NewAccountCost(Revision) Gas {
+	return 25000
+}
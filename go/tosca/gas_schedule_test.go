@@ -0,0 +1,129 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestEthereumGasSchedule_ProducesWordBasedCosts(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	tests := map[uint64]struct{ memory, copy, hash Gas }{
+		0:    {0, 0, 0},
+		1:    {3, 3, 6},
+		2:    {6, 6, 12},
+		32:   {98, 96, 192},
+		1024: {5120, 3072, 6144},
+	}
+	for _, revision := range GetAllKnownRevisions() {
+		for words, want := range tests {
+			if got := schedule.MemoryCost(revision, words); want.memory != got {
+				t.Errorf("unexpected memory cost for %d words, wanted %d, got %d", words, want.memory, got)
+			}
+			if got := schedule.CopyCost(revision, words); want.copy != got {
+				t.Errorf("unexpected copy cost for %d words, wanted %d, got %d", words, want.copy, got)
+			}
+			if got := schedule.HashCost(revision, words); want.hash != got {
+				t.Errorf("unexpected hash cost for %d words, wanted %d, got %d", words, want.hash, got)
+			}
+		}
+	}
+}
+
+func TestEthereumGasSchedule_MemoryCostSaturatesForHugeSizes(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	tests := map[uint64]Gas{
+		1 << 32:               1<<55 + 3<<32,
+		1 << 36:               math.MaxInt64,
+		math.MaxUint64/32 + 1: math.MaxInt64,
+		math.MaxUint64:        math.MaxInt64,
+	}
+	for _, revision := range GetAllKnownRevisions() {
+		for words, want := range tests {
+			if got := schedule.MemoryCost(revision, words); want != got {
+				t.Errorf("unexpected memory cost for %d words, wanted %d, got %d", words, want, got)
+			}
+		}
+	}
+}
+
+func TestEthereumGasSchedule_MemoryCostMatchesExactComputation(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	maxGas := new(big.Int).SetInt64(math.MaxInt64)
+	for shift := 0; shift < 64; shift++ {
+		for _, words := range []uint64{1<<shift - 1, 1 << shift, 1<<shift + 1, math.MaxUint64 >> shift} {
+			w := new(big.Int).SetUint64(words)
+			exact := new(big.Int).Mul(w, w)
+			exact.Div(exact, big.NewInt(512))
+			exact.Add(exact, new(big.Int).Mul(w, big.NewInt(3)))
+			if exact.Cmp(maxGas) > 0 {
+				exact = maxGas
+			}
+			if want, got := exact.Int64(), schedule.MemoryCost(R13_Cancun, words); Gas(want) != got {
+				t.Errorf("unexpected memory cost for %d words, wanted %d, got %d", words, want, got)
+			}
+		}
+	}
+}
+
+func TestEthereumGasSchedule_MemoryCostIsMonotonic(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	previous := Gas(0)
+	for shift := 2; shift < 64; shift++ {
+		for _, words := range []uint64{1<<shift - 1, 1 << shift, 1<<shift + 1} {
+			cost := schedule.MemoryCost(R13_Cancun, words)
+			if cost < previous {
+				t.Fatalf("costs for %d words decreased, from %d to %d", words, previous, cost)
+			}
+			previous = cost
+		}
+	}
+}
+
+func TestEthereumGasSchedule_ExpCostIsChargedPerExponentByte(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	for _, revision := range GetAllKnownRevisions() {
+		for _, bytes := range []int{0, 1, 2, 32} {
+			if want, got := Gas(50*bytes), schedule.ExpCost(revision, bytes); want != got {
+				t.Errorf("unexpected costs for %d exponent bytes, wanted %d, got %d", bytes, want, got)
+			}
+		}
+	}
+}
+
+type cheapExpSchedule struct {
+	EthereumGasSchedule
+}
+
+func (cheapExpSchedule) ExpCost(Revision, int) Gas {
+	return 1
+}
+
+func TestGasSchedule_IndividualCostsCanBeOverridden(t *testing.T) {
+	var schedule GasSchedule = cheapExpSchedule{}
+	if want, got := Gas(1), schedule.ExpCost(R13_Cancun, 32); want != got {
+		t.Errorf("unexpected overridden costs, wanted %d, got %d", want, got)
+	}
+	if want, got := Gas(20000), schedule.SstoreCost(R13_Cancun, StorageAdded); want != got {
+		t.Errorf("unexpected inherited costs, wanted %d, got %d", want, got)
+	}
+}
+
+func TestEthereumGasSchedule_NewAccountCostIsConstant(t *testing.T) {
+	schedule := EthereumGasSchedule{}
+	for _, revision := range GetAllKnownRevisions() {
+		if want, got := Gas(25000), schedule.NewAccountCost(revision); want != got {
+			t.Errorf("unexpected new account cost in %v, wanted %d, got %d", revision, want, got)
+		}
+	}
+}
@@ -10,7 +10,10 @@
 
 package tosca
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 //go:generate mockgen -source interpreter.go -destination interpreter_mock.go -package tosca
 
@@ -26,7 +29,9 @@ type Interpreter interface {
 	// a code-internal issue). The error is not nil if some problem within the
 	// interpreter caused the execution to fail to correctly process the provided
 	// program. In such a case the result is undefined. During a call with an
-	// unsupported Revision an ErrUnsupportedRevision Error is returned.
+	// unsupported Revision an ErrUnsupportedRevision Error is returned. If the
+	// execution was aborted through the Interrupt context of the transaction
+	// parameters, the error of this context is returned.
 	// Interpreters are required to be thread-safe. Thus, multiple runs may be
 	// conducted in parallel.
 	Run(Parameters) (Result, error)
@@ -47,6 +52,7 @@ type Parameters struct {
 	Value     Value
 	CodeHash  *Hash
 	Code      Code
+	InitCode  bool // < true if Code is the init code of a contract creation
 }
 
 // BlockParameters contains information about the current block.
@@ -67,6 +73,25 @@ type TransactionParameters struct {
 	Origin     Address
 	GasPrice   Value
 	BlobHashes []Hash
+
+	// Interrupt, if not nil, is used to abort long-running executions. Once
+	// the context is done, interpreters stop processing the current code at
+	// the next opportunity and report the context's error as the result of
+	// the Run call. Interpreters are expected to check for interrupts in
+	// bounded intervals, not necessarily after every instruction.
+	// Interpreters backed by evmc (evmone, evmzero, and evmrs) can not be
+	// interrupted, since evmc offers no means to abort an execution; they
+	// ignore this field and run until the code completes or runs out of gas.
+	// Processors still observe interrupts between nested calls.
+	Interrupt context.Context
+
+	// Tracer, if not nil, is informed by interpreters about the execution
+	// of individual instructions.
+	Tracer Tracer
+
+	// ResourceUsage, if not nil, is updated by interpreters supporting it
+	// with the resources used by each call of the transaction.
+	ResourceUsage *ResourceUsage
 }
 
 // RunContext provides an interface to access and manipulate state and transaction
@@ -128,6 +153,51 @@ type Result struct {
 	Output    Data
 	GasLeft   Gas
 	GasRefund Gas
+
+	// SelfDestruction is set if the execution ended with a SELFDESTRUCT that
+	// has not been applied to the context, nil otherwise. Callers have to
+	// finalize the destruction using FinalizeSelfDestruction before any other
+	// modification of the context. Interpreters applying SELFDESTRUCT through
+	// the context themselves do not report it.
+	SelfDestruction *SelfDestruction
+
+	// Failure optionally describes why an unsuccessful execution failed. It
+	// is only set by interpreters supporting diagnostics if those are enabled
+	// and is nil for reverted executions.
+	Failure *ExecutionFailure
+}
+
+// SelfDestruction describes a SELFDESTRUCT ending the execution of a contract.
+type SelfDestruction struct {
+	Beneficiary Address // < the account receiving the balance of the destructed account
+	Value       Value   // < the balance of the destructed account at the time of the destruction
+}
+
+// FinalizeSelfDestruction applies the given self-destruction of the account
+// with the given address to the given context. The context's SelfDestruct
+// implements the rules of the current revision, e.g. EIP-6780. Nil
+// self-destructions are ignored.
+func FinalizeSelfDestruction(context WorldState, address Address, destruction *SelfDestruction) {
+	if destruction != nil {
+		context.SelfDestruct(address, destruction.Beneficiary)
+	}
+}
+
+// ResourceUsage summarizes the high-water marks of the resources used by the
+// execution of a transaction. It is shared by all calls of a transaction and
+// updated by interpreters at the end of each call.
+type ResourceUsage struct {
+	MaxCallDepth   int    // the maximum depth of calls, 1 for the outermost call
+	MaxStackHeight int    // the maximum number of stack elements of any call
+	PeakMemory     uint64 // the maximum memory size in bytes of any call
+}
+
+// Update raises the high-water marks to cover the resources observed for a
+// call at the given depth, where 0 is the depth of the outermost call.
+func (u *ResourceUsage) Update(depth int, stackHeight int, memorySize uint64) {
+	u.MaxCallDepth = max(u.MaxCallDepth, depth+1)
+	u.MaxStackHeight = max(u.MaxStackHeight, stackHeight)
+	u.PeakMemory = max(u.PeakMemory, memorySize)
 }
 
 // Data represents the input or output of contract invocations.
@@ -193,6 +263,15 @@ const (
 	numRevisions int = iota
 )
 
+// Revisions scheduled after the newest supported revision. They are not yet
+// supported by interpreters and are thus not listed by GetAllKnownRevisions,
+// but they identify the activation of features already implemented by some
+// components, e.g. the system calls and precompiled contracts of processors.
+const (
+	R14_Prague Revision = Revision(numRevisions) + iota
+	R15_Osaka
+)
+
 // Error for runs with unsupported Revision
 type ErrUnsupportedRevision struct {
 	Revision Revision
@@ -69,6 +69,14 @@ func GetInterpreterFactory(name string) InterpreterFactory {
 	return interpreterRegistry[strings.ToLower(name)]
 }
 
+// ListInterpreters lists all registered interpreter factories, sorted by
+// name, including their metadata if provided during the registration.
+func ListInterpreters() []ImplementationInfo {
+	interpreterRegistryLock.Lock()
+	defer interpreterRegistryLock.Unlock()
+	return listImplementations(interpreterRegistry, interpreterMetadata)
+}
+
 // GetAllRegisteredInterpreters obtains all registered implementations.
 func GetAllRegisteredInterpreters() map[string]InterpreterFactory {
 	interpreterRegistryLock.Lock()
@@ -95,19 +103,27 @@ func RegisterInterpreter(name string, impl Interpreter) {
 // RegisterInterpreterFactory registers a new Interpreter implementation
 // to be exported for general use in the binary. The name is not case-sensitive,
 // and a panic is triggered if a factory was bound to the same name before, or
-// the factory is nil. This function is mainly intended to be used by package
-// initialization code.
-func RegisterInterpreterFactory(name string, factory InterpreterFactory) error {
+// the factory is nil. Optionally, metadata describing the implementation may
+// be provided, which is reported by ListInterpreters. This function is mainly
+// intended to be used by package initialization code.
+func RegisterInterpreterFactory(name string, factory InterpreterFactory, metadata ...ImplementationMetadata) error {
 	key := strings.ToLower(name)
 	if factory == nil {
 		return fmt.Errorf("invalid initialization: cannot register nil-factory using `%s`", key)
 	}
+	data, err := getOptionalMetadata(metadata)
+	if err != nil {
+		return fmt.Errorf("invalid initialization of `%s`: %w", key, err)
+	}
 	interpreterRegistryLock.Lock()
 	defer interpreterRegistryLock.Unlock()
 	if _, found := interpreterRegistry[key]; found {
 		return fmt.Errorf("invalid initialization: multiple factories registered for `%s`", key)
 	}
 	interpreterRegistry[key] = factory
+	if data != nil {
+		interpreterMetadata[key] = data
+	}
 	return nil
 }
 
@@ -116,8 +132,8 @@ func RegisterInterpreterFactory(name string, factory InterpreterFactory) error {
 // This function panics if the registration fails. This function is intended to
 // be used exclusively in package initialization code, where error handling is
 // limited.
-func MustRegisterInterpreterFactory(name string, factory InterpreterFactory) {
-	if err := RegisterInterpreterFactory(name, factory); err != nil {
+func MustRegisterInterpreterFactory(name string, factory InterpreterFactory, metadata ...ImplementationMetadata) {
+	if err := RegisterInterpreterFactory(name, factory, metadata...); err != nil {
 		panic(fmt.Errorf("failed to register interpreter factory: %s", err))
 	}
 }
@@ -130,5 +146,9 @@ type InterpreterFactory func(config any) (Interpreter, error)
 // different implementations and configurations.
 var interpreterRegistry = map[string]InterpreterFactory{}
 
+// interpreterMetadata contains the metadata provided for registered
+// interpreter factories.
+var interpreterMetadata = map[string]*ImplementationMetadata{}
+
 // interpreterRegistryLock to protect access to the registry.
 var interpreterRegistryLock sync.Mutex
@@ -10,7 +10,11 @@
 
 package tosca
 
-import "testing"
+import (
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestInterpreterRegistry_NameCollisionsAreDetected(t *testing.T) {
 	const name = "something-just-for-this-test"
@@ -31,3 +35,77 @@ func TestInterpreterRegistry_NilFactoriesAreRejected(t *testing.T) {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestInterpreterRegistry_ListInterpretersReportsMetadata(t *testing.T) {
+	factory := func(any) (Interpreter, error) {
+		return nil, nil
+	}
+	metadata := ImplementationMetadata{
+		Language:       "C++",
+		RequiresCgo:    true,
+		OldestRevision: R07_Istanbul,
+		NewestRevision: R12_Shanghai,
+	}
+	if err := RegisterInterpreterFactory("Interpreter-With-Metadata", factory, metadata); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RegisterInterpreterFactory("interpreter-without-metadata", factory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	infos := ListInterpreters()
+	if !slices.IsSortedFunc(infos, func(a, b ImplementationInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	}) {
+		t.Errorf("interpreters are not sorted by name: %v", infos)
+	}
+
+	found := 0
+	for _, info := range infos {
+		switch info.Name {
+		case "interpreter-with-metadata":
+			found++
+			if info.Metadata == nil || *info.Metadata != metadata {
+				t.Errorf("unexpected metadata, wanted %v, got %v", metadata, info.Metadata)
+			}
+		case "interpreter-without-metadata":
+			found++
+			if info.Metadata != nil {
+				t.Errorf("unexpected metadata, got %v", info.Metadata)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("registered interpreters not listed, got %v", infos)
+	}
+}
+
+func TestInterpreterRegistry_InvalidMetadataIsRejected(t *testing.T) {
+	factory := func(any) (Interpreter, error) {
+		return nil, nil
+	}
+	tests := map[string][]ImplementationMetadata{
+		"too many":          {{}, {}},
+		"invalid revisions": {{OldestRevision: R13_Cancun, NewestRevision: R07_Istanbul}},
+	}
+	for name, metadata := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := RegisterInterpreterFactory("invalid-metadata-"+name, factory, metadata...); err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if GetInterpreterFactory("invalid-metadata-"+name) != nil {
+				t.Errorf("interpreter with invalid metadata was registered")
+			}
+		})
+	}
+}
+
+func TestImplementationMetadata_SupportsRevision(t *testing.T) {
+	metadata := ImplementationMetadata{OldestRevision: R09_Berlin, NewestRevision: R12_Shanghai}
+	for _, revision := range GetAllKnownRevisions() {
+		want := R09_Berlin <= revision && revision <= R12_Shanghai
+		if got := metadata.SupportsRevision(revision); want != got {
+			t.Errorf("unexpected support of %v, wanted %t, got %t", revision, want, got)
+		}
+	}
+}
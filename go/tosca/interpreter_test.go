@@ -0,0 +1,51 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestResourceUsage_UpdateRaisesHighWaterMarks(t *testing.T) {
+	usage := ResourceUsage{}
+
+	usage.Update(0, 5, 64)
+	if want, got := (ResourceUsage{MaxCallDepth: 1, MaxStackHeight: 5, PeakMemory: 64}), usage; want != got {
+		t.Errorf("unexpected usage, wanted %+v, got %+v", want, got)
+	}
+
+	usage.Update(3, 2, 128)
+	if want, got := (ResourceUsage{MaxCallDepth: 4, MaxStackHeight: 5, PeakMemory: 128}), usage; want != got {
+		t.Errorf("unexpected usage, wanted %+v, got %+v", want, got)
+	}
+
+	usage.Update(1, 7, 32)
+	if want, got := (ResourceUsage{MaxCallDepth: 4, MaxStackHeight: 7, PeakMemory: 128}), usage; want != got {
+		t.Errorf("unexpected usage, wanted %+v, got %+v", want, got)
+	}
+}
+
+func TestFinalizeSelfDestruction_DestructsAccountInContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockWorldState(ctrl)
+	context.EXPECT().SelfDestruct(Address{1}, Address{2})
+
+	FinalizeSelfDestruction(context, Address{1}, &SelfDestruction{Beneficiary: Address{2}})
+}
+
+func TestFinalizeSelfDestruction_IgnoresMissingSelfDestruction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockWorldState(ctrl)
+
+	FinalizeSelfDestruction(context, Address{1}, nil)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+// LogEvent is reported to LogListeners for each log emitted during the
+// execution of a transaction. Since logs are streamed as they are emitted,
+// they may still be discarded if the call emitting them gets reverted later
+// on. In such a case, an event with the same log and Removed set to true is
+// reported. Consumers are thus able to maintain an accurate list of pending
+// logs without waiting for the end of a transaction.
+type LogEvent struct {
+	Log     Log
+	Removed bool
+}
+
+// LogListener is a callback informed about logs as they are emitted. It is
+// called synchronously by the thread executing the transaction and should
+// thus return quickly.
+type LogListener func(LogEvent)
+
+// NewLogChannel creates a LogListener forwarding all events to the given
+// channel, enabling asynchronous consumers. Sending blocks if the channel is
+// full, so the channel should be buffered or served by a concurrently
+// running consumer. Closing the channel is the responsibility of the caller.
+func NewLogChannel(channel chan<- LogEvent) LogListener {
+	return func(event LogEvent) {
+		channel <- event
+	}
+}
+
+// NewLogStreamingTransactionContext wraps the given context such that logs
+// are reported to the given listener when being emitted. Logs discarded by
+// restoring a snapshot are reported as removed, in the reverse order of their
+// emission. It is intended to be used by processors supporting log listeners.
+func NewLogStreamingTransactionContext(context TransactionContext, listener LogListener) TransactionContext {
+	return &logStreamingContext{
+		TransactionContext: context,
+		listener:           listener,
+		marks:              map[Snapshot]int{},
+	}
+}
+
+type logStreamingContext struct {
+	TransactionContext
+	listener LogListener
+	logs     []Log            // logs reported so far and not removed
+	marks    map[Snapshot]int // number of reported logs when snapshots got taken
+}
+
+func (c *logStreamingContext) EmitLog(log Log) {
+	c.TransactionContext.EmitLog(log)
+	c.logs = append(c.logs, log)
+	c.listener(LogEvent{Log: log})
+}
+
+func (c *logStreamingContext) RemoveAccount(address Address) {
+	RemoveAccount(c.TransactionContext, address)
+}
+
+func (c *logStreamingContext) CreateSnapshot() Snapshot {
+	snapshot := c.TransactionContext.CreateSnapshot()
+	c.marks[snapshot] = len(c.logs)
+	return snapshot
+}
+
+func (c *logStreamingContext) RestoreSnapshot(snapshot Snapshot) {
+	c.TransactionContext.RestoreSnapshot(snapshot)
+	mark, found := c.marks[snapshot]
+	if !found {
+		return
+	}
+	for i := len(c.logs) - 1; i >= mark; i-- {
+		c.listener(LogEvent{Log: c.logs[i], Removed: true})
+	}
+	c.logs = c.logs[:mark]
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestLogStreamingContext_LogsAreReportedWhenEmitted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	log := Log{Address: Address{1}, Data: []byte{2}}
+	var events []LogEvent
+	context.EXPECT().EmitLog(log).Do(func(Log) {
+		if len(events) != 0 {
+			t.Errorf("log reported before it was emitted")
+		}
+	})
+
+	streaming := NewLogStreamingTransactionContext(context, func(event LogEvent) {
+		events = append(events, event)
+	})
+	streaming.EmitLog(log)
+
+	want := []LogEvent{{Log: log}}
+	if !slices.EqualFunc(want, events, equalLogEvents) {
+		t.Errorf("unexpected events, wanted %v, got %v", want, events)
+	}
+}
+
+func TestLogStreamingContext_RevertedLogsAreReportedAsRemoved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	log1 := Log{Address: Address{1}}
+	log2 := Log{Address: Address{2}}
+	log3 := Log{Address: Address{3}}
+	context.EXPECT().EmitLog(gomock.Any()).Times(4)
+	context.EXPECT().CreateSnapshot().Return(Snapshot(1))
+	context.EXPECT().CreateSnapshot().Return(Snapshot(2))
+	context.EXPECT().RestoreSnapshot(Snapshot(2))
+	context.EXPECT().RestoreSnapshot(Snapshot(1))
+
+	var events []LogEvent
+	streaming := NewLogStreamingTransactionContext(context, func(event LogEvent) {
+		events = append(events, event)
+	})
+
+	streaming.EmitLog(log1)
+	outer := streaming.CreateSnapshot()
+	streaming.EmitLog(log2)
+	inner := streaming.CreateSnapshot()
+	streaming.RestoreSnapshot(inner) // nothing to remove
+	streaming.EmitLog(log3)
+	streaming.RestoreSnapshot(outer)
+	streaming.EmitLog(log3)
+
+	want := []LogEvent{
+		{Log: log1},
+		{Log: log2},
+		{Log: log3},
+		{Log: log3, Removed: true},
+		{Log: log2, Removed: true},
+		{Log: log3},
+	}
+	if !slices.EqualFunc(want, events, equalLogEvents) {
+		t.Errorf("unexpected events, wanted %v, got %v", want, events)
+	}
+}
+
+func TestLogChannel_ForwardsEventsToChannel(t *testing.T) {
+	channel := make(chan LogEvent, 2)
+	listener := NewLogChannel(channel)
+
+	event := LogEvent{Log: Log{Address: Address{1}}, Removed: true}
+	listener(event)
+
+	if got := <-channel; !equalLogEvents(event, got) {
+		t.Errorf("unexpected event, wanted %v, got %v", event, got)
+	}
+}
+
+func equalLogEvents(a, b LogEvent) bool {
+	return a.Removed == b.Removed &&
+		a.Log.Address == b.Log.Address &&
+		slices.Equal(a.Log.Topics, b.Log.Topics) &&
+		slices.Equal(a.Log.Data, b.Log.Data)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"log/slog"
+)
+
+// NewLoggingInterpreter wraps the given interpreter such that errors returned
+// by its runs are logged as warnings. Processors treat those errors as failed
+// calls, which otherwise would go unnoticed by operators.
+func NewLoggingInterpreter(interpreter Interpreter, logger *slog.Logger) Interpreter {
+	return &loggingInterpreter{
+		Interpreter: interpreter,
+		logger:      logger,
+	}
+}
+
+type loggingInterpreter struct {
+	Interpreter
+	logger *slog.Logger
+}
+
+func (i *loggingInterpreter) Run(params Parameters) (Result, error) {
+	result, err := i.Interpreter.Run(params)
+	if err != nil {
+		i.logger.Warn("interpreter failed",
+			"revision", params.Revision,
+			"depth", params.Depth,
+			"recipient", params.Recipient,
+			"error", err,
+		)
+	}
+	return result, err
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestLoggingInterpreter_ErrorsAreLoggedAsWarnings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockInterpreter(ctrl)
+	gomock.InOrder(
+		inner.EXPECT().Run(gomock.Any()).Return(Result{Success: true}, nil),
+		inner.EXPECT().Run(gomock.Any()).Return(Result{}, fmt.Errorf("injected error")),
+	)
+
+	var buffer bytes.Buffer
+	interpreter := NewLoggingInterpreter(inner, slog.New(slog.NewTextHandler(&buffer, nil)))
+
+	if _, err := interpreter.Run(Parameters{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("successful runs should not be logged, got %q", buffer.String())
+	}
+
+	if _, err := interpreter.Run(Parameters{Depth: 3}); err == nil {
+		t.Fatalf("error should be forwarded")
+	}
+	got := buffer.String()
+	for _, want := range []string{"level=WARN", "interpreter failed", "depth=3", `error="injected error"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log should contain %q, got %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"time"
+)
+
+// Metrics is a registry of metrics reported by interpreters and processors.
+// Instruments are identified by their name and labels; requesting the same
+// instrument twice yields instruments reporting into the same metric.
+// Implementations must be safe for concurrent use. See package
+// prometheus_adapter for an implementation exporting metrics to Prometheus.
+type Metrics interface {
+	// Counter returns a counter of the given name and labels.
+	Counter(name string, labels ...Label) Counter
+	// Gauge returns a gauge of the given name and labels.
+	Gauge(name string, labels ...Label) Gauge
+	// Histogram returns a histogram of the given name and labels.
+	Histogram(name string, labels ...Label) Histogram
+}
+
+// Label is a name/value pair distinguishing metrics of the same name, for
+// instance the metrics of different interpreters.
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Counter is a monotonically increasing metric.
+type Counter interface {
+	Add(delta float64)
+}
+
+// Gauge is a metric which may arbitrarily go up and down.
+type Gauge interface {
+	Set(value float64)
+}
+
+// Histogram is a metric sampling the distribution of observed values.
+type Histogram interface {
+	Observe(value float64)
+}
+
+// NoMetrics is a Metrics implementation discarding all reported values.
+var NoMetrics Metrics = noMetrics{}
+
+type noMetrics struct{}
+
+func (noMetrics) Counter(string, ...Label) Counter     { return noInstrument{} }
+func (noMetrics) Gauge(string, ...Label) Gauge         { return noInstrument{} }
+func (noMetrics) Histogram(string, ...Label) Histogram { return noInstrument{} }
+
+type noInstrument struct{}
+
+func (noInstrument) Add(float64)     {}
+func (noInstrument) Set(float64)     {}
+func (noInstrument) Observe(float64) {}
+
+// Names of the metrics reported by Tosca's subsystems. Interpreters and
+// processors are distinguished by a label named after their kind, caches by
+// a label named "cache". Gas throughput is to be derived from the rate of
+// the gas counter of processors.
+const (
+	MetricInterpreterRuns     = "tosca_interpreter_runs_total"
+	MetricInterpreterFailures = "tosca_interpreter_failures_total"
+	MetricInterpreterDuration = "tosca_interpreter_run_duration_seconds"
+
+	MetricProcessorTransactions = "tosca_processor_transactions_total"
+	MetricProcessorFailures     = "tosca_processor_failures_total"
+	MetricProcessorDuration     = "tosca_processor_run_duration_seconds"
+	MetricProcessorGasUsed      = "tosca_processor_gas_used_total"
+
+	MetricCacheHits   = "tosca_cache_hits_total"
+	MetricCacheMisses = "tosca_cache_misses_total"
+	MetricCacheSize   = "tosca_cache_size_bytes"
+)
+
+// Reasons reported through the "reason" label of failure metrics.
+const (
+	FailureReasonError    = "error"    // the run returned an error
+	FailureReasonReverted = "reverted" // the execution ended unsuccessfully
+)
+
+// NewMeasuredInterpreter wraps the given interpreter such that the number,
+// duration, and failures of its runs are reported to the given metrics,
+// labeled with the given name. Since interpreters are invoked for each
+// nested call, the duration of a run includes the durations of its nested
+// calls.
+func NewMeasuredInterpreter(interpreter Interpreter, metrics Metrics, name string) Interpreter {
+	label := Label{Name: "interpreter", Value: name}
+	return &measuredInterpreter{
+		Interpreter: interpreter,
+		metrics:     metrics,
+		label:       label,
+		runs:        metrics.Counter(MetricInterpreterRuns, label),
+		duration:    metrics.Histogram(MetricInterpreterDuration, label),
+	}
+}
+
+type measuredInterpreter struct {
+	Interpreter
+	metrics  Metrics
+	label    Label
+	runs     Counter
+	duration Histogram
+}
+
+func (i *measuredInterpreter) Run(params Parameters) (Result, error) {
+	start := time.Now()
+	result, err := i.Interpreter.Run(params)
+	i.duration.Observe(time.Since(start).Seconds())
+	i.runs.Add(1)
+	if reason, failed := getFailureReason(err, result.Success); failed {
+		i.metrics.Counter(MetricInterpreterFailures, i.label, Label{Name: "reason", Value: reason}).Add(1)
+	}
+	return result, err
+}
+
+// NewMeasuredProcessor wraps the given processor such that the number,
+// duration, gas usage, and failures of its transactions are reported to the
+// given metrics, labeled with the given name. All optional extensions of the
+// processor, like SimulatingProcessor and SystemCallProcessor, are supported
+// by the resulting processor as well; their calls are not measured.
+func NewMeasuredProcessor(processor Processor, metrics Metrics, name string) Processor {
+	label := Label{Name: "processor", Value: name}
+	measured := &measuredProcessor{
+		Processor:    processor,
+		metrics:      metrics,
+		label:        label,
+		transactions: metrics.Counter(MetricProcessorTransactions, label),
+		gasUsed:      metrics.Counter(MetricProcessorGasUsed, label),
+		duration:     metrics.Histogram(MetricProcessorDuration, label),
+	}
+	return forwardExtensions(measured, processor)
+}
+
+type measuredProcessor struct {
+	Processor
+	metrics      Metrics
+	label        Label
+	transactions Counter
+	gasUsed      Counter
+	duration     Histogram
+}
+
+func (p *measuredProcessor) Run(
+	ctx context.Context,
+	blockParameters BlockParameters,
+	transaction Transaction,
+	txContext TransactionContext,
+) (Receipt, error) {
+	start := time.Now()
+	receipt, err := p.Processor.Run(ctx, blockParameters, transaction, txContext)
+	p.duration.Observe(time.Since(start).Seconds())
+	p.transactions.Add(1)
+	p.gasUsed.Add(float64(receipt.GasUsed))
+	if reason, failed := getFailureReason(err, receipt.Success); failed {
+		p.metrics.Counter(MetricProcessorFailures, p.label, Label{Name: "reason", Value: reason}).Add(1)
+	}
+	return receipt, err
+}
+
+func getFailureReason(err error, success bool) (string, bool) {
+	if err != nil {
+		return FailureReasonError, true
+	}
+	if !success {
+		return FailureReasonReverted, true
+	}
+	return "", false
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+// recordedMetrics is a Metrics implementation recording the reported values
+// indexed by the names and labels of the metrics.
+type recordedMetrics struct {
+	mutex  sync.Mutex
+	values map[string]float64
+	counts map[string]int
+}
+
+func newRecordedMetrics() *recordedMetrics {
+	return &recordedMetrics{values: map[string]float64{}, counts: map[string]int{}}
+}
+
+func (m *recordedMetrics) Counter(name string, labels ...Label) Counter {
+	return recordedInstrument{m, metricKey(name, labels)}
+}
+
+func (m *recordedMetrics) Gauge(name string, labels ...Label) Gauge {
+	return recordedInstrument{m, metricKey(name, labels)}
+}
+
+func (m *recordedMetrics) Histogram(name string, labels ...Label) Histogram {
+	return recordedInstrument{m, metricKey(name, labels)}
+}
+
+func (m *recordedMetrics) get(name string, labels ...Label) (float64, int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	key := metricKey(name, labels)
+	return m.values[key], m.counts[key]
+}
+
+func metricKey(name string, labels []Label) string {
+	var res strings.Builder
+	res.WriteString(name)
+	for _, label := range labels {
+		fmt.Fprintf(&res, ",%s=%s", label.Name, label.Value)
+	}
+	return res.String()
+}
+
+type recordedInstrument struct {
+	metrics *recordedMetrics
+	key     string
+}
+
+func (i recordedInstrument) Add(delta float64) {
+	i.metrics.mutex.Lock()
+	defer i.metrics.mutex.Unlock()
+	i.metrics.values[i.key] += delta
+	i.metrics.counts[i.key]++
+}
+
+func (i recordedInstrument) Set(value float64) {
+	i.metrics.mutex.Lock()
+	defer i.metrics.mutex.Unlock()
+	i.metrics.values[i.key] = value
+	i.metrics.counts[i.key]++
+}
+
+func (i recordedInstrument) Observe(value float64) {
+	i.Add(value)
+}
+
+func TestNoMetrics_DiscardsValues(t *testing.T) {
+	NoMetrics.Counter("counter").Add(1)
+	NoMetrics.Gauge("gauge", Label{"a", "b"}).Set(1)
+	NoMetrics.Histogram("histogram").Observe(1)
+}
+
+func TestMeasuredInterpreter_RunsAndFailuresAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockInterpreter(ctrl)
+	gomock.InOrder(
+		inner.EXPECT().Run(gomock.Any()).Return(Result{Success: true}, nil),
+		inner.EXPECT().Run(gomock.Any()).Return(Result{Success: false}, nil),
+		inner.EXPECT().Run(gomock.Any()).Return(Result{}, fmt.Errorf("injected error")),
+	)
+
+	metrics := newRecordedMetrics()
+	interpreter := NewMeasuredInterpreter(inner, metrics, "test")
+	for range 3 {
+		interpreter.Run(Parameters{})
+	}
+
+	label := Label{"interpreter", "test"}
+	if runs, _ := metrics.get(MetricInterpreterRuns, label); runs != 3 {
+		t.Errorf("unexpected number of runs, wanted 3, got %v", runs)
+	}
+	if _, observations := metrics.get(MetricInterpreterDuration, label); observations != 3 {
+		t.Errorf("unexpected number of observed durations, wanted 3, got %v", observations)
+	}
+	for _, reason := range []string{FailureReasonReverted, FailureReasonError} {
+		if failures, _ := metrics.get(MetricInterpreterFailures, label, Label{"reason", reason}); failures != 1 {
+			t.Errorf("unexpected number of failures with reason %q, wanted 1, got %v", reason, failures)
+		}
+	}
+}
+
+func TestMeasuredProcessor_TransactionsGasAndFailuresAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockProcessor(ctrl)
+	gomock.InOrder(
+		inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Receipt{Success: true, GasUsed: 21000}, nil),
+		inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Receipt{Success: false, GasUsed: 30000}, nil),
+		inner.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Receipt{}, fmt.Errorf("injected error")),
+	)
+
+	metrics := newRecordedMetrics()
+	processor := NewMeasuredProcessor(inner, metrics, "test")
+	if _, ok := processor.(SimulatingProcessor); ok {
+		t.Errorf("measured processor should not support simulations if the wrapped processor does not")
+	}
+	for range 3 {
+		processor.Run(context.Background(), BlockParameters{}, Transaction{}, nil)
+	}
+
+	label := Label{"processor", "test"}
+	if transactions, _ := metrics.get(MetricProcessorTransactions, label); transactions != 3 {
+		t.Errorf("unexpected number of transactions, wanted 3, got %v", transactions)
+	}
+	if gas, _ := metrics.get(MetricProcessorGasUsed, label); gas != 51000 {
+		t.Errorf("unexpected gas used, wanted 51000, got %v", gas)
+	}
+	if _, observations := metrics.get(MetricProcessorDuration, label); observations != 3 {
+		t.Errorf("unexpected number of observed durations, wanted 3, got %v", observations)
+	}
+	for _, reason := range []string{FailureReasonReverted, FailureReasonError} {
+		if failures, _ := metrics.get(MetricProcessorFailures, label, Label{"reason", reason}); failures != 1 {
+			t.Errorf("unexpected number of failures with reason %q, wanted 1, got %v", reason, failures)
+		}
+	}
+}
+
+func TestMeasuredProcessor_SimulationsAreForwardedWithoutMeasurement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockSimulatingProcessor(ctrl)
+	want := SimulationResult{Receipt: Receipt{Success: true, GasUsed: 21000}}
+	inner.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(want, nil)
+
+	metrics := newRecordedMetrics()
+	processor, ok := NewMeasuredProcessor(inner, metrics, "test").(SimulatingProcessor)
+	if !ok {
+		t.Fatalf("measured processor does not support simulations")
+	}
+	got, err := processor.Simulate(context.Background(), BlockParameters{}, Transaction{}, nil, SimulationOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.Receipt.GasUsed != got.Receipt.GasUsed {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+	if transactions, _ := metrics.get(MetricProcessorTransactions, Label{"processor", "test"}); transactions != 0 {
+		t.Errorf("simulations should not be measured, got %v transactions", transactions)
+	}
+}
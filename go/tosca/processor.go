@@ -10,6 +10,8 @@
 
 package tosca
 
+import "context"
+
 //go:generate mockgen -source processor.go -destination processor_mock.go -package tosca
 
 // Processor is an interface for a component capable of executing transactions.
@@ -18,8 +20,108 @@ package tosca
 // nonces, the execution of transactions using (potentially) recursive calls of contracts,
 // the integration of precompiled contracts, and the creation of new contracts.
 type Processor interface {
-	// Run executes the transaction provided by the parameters in the specified context.
-	Run(BlockParameters, Transaction, TransactionContext) (Receipt, error)
+	// Run executes the transaction provided by the parameters in the specified
+	// transaction context. The given context.Context may be used to abort the
+	// execution, e.g. to enforce a time limit on the simulation of a transaction.
+	// If the execution got aborted, the context's error is returned and the
+	// resulting receipt as well as the state of the transaction context are
+	// undefined.
+	Run(context.Context, BlockParameters, Transaction, TransactionContext) (Receipt, error)
+}
+
+// SimulatingProcessor is an optional extension of the Processor interface for
+// processors supporting the simulation of transactions, as required for
+// serving eth_call-like RPC requests. Simulations are conducted like regular
+// transaction executions, with some checks relaxed as configured by the
+// provided options.
+type SimulatingProcessor interface {
+	Processor
+
+	// Simulate executes the given transaction like Run, applying the given
+	// simulation options. Unlike Run, an execution aborted through the given
+	// context.Context is not reported as an error but as an aborted result,
+	// enabling clients to distinguish executions running out of time from
+	// reverted executions.
+	Simulate(context.Context, BlockParameters, Transaction, TransactionContext, SimulationOptions) (SimulationResult, error)
+}
+
+// SimulationOptions summarizes the relaxations of the transaction processing
+// rules applied when simulating the execution of a transaction.
+type SimulationOptions struct {
+	// GasCap, if positive, is an upper limit for the gas limit of the
+	// simulated transaction. Gas limits exceeding this cap are reduced to it.
+	GasCap Gas
+	// NoBaseFee disables the base fee for transactions with a zero gas price,
+	// such that the base fee reported to executed contracts is zero as well.
+	NoBaseFee bool
+	// NoBalanceCheck disables the charging of gas fees, such that senders are
+	// not required to have a balance covering the costs of the transaction.
+	NoBalanceCheck bool
+	// StateOverrides are applied to the transaction context before the
+	// execution of the simulated transaction.
+	StateOverrides StateOverrides
+	// Tracer, if not nil, is informed about the progress of the execution.
+	// Since tracing is not altering the execution, it may be combined with
+	// otherwise zero-valued options to trace regular transactions.
+	Tracer Tracer
+	// LogListener, if not nil, is informed about logs as they are emitted
+	// and about logs being removed due to reverted calls. Like tracing, it
+	// does not alter the execution and may be used for regular transactions.
+	LogListener LogListener
+	// GasBreakdown requests the receipt of the transaction to include a
+	// breakdown of its gas usage. Like tracing, it does not alter the
+	// execution and may be used for regular transactions.
+	GasBreakdown bool
+	// ResourceUsage requests the receipt of the transaction to include the
+	// high-water marks of the resources used by the execution. Like tracing,
+	// it does not alter the execution and may be used for regular
+	// transactions.
+	ResourceUsage bool
+	// AccessStatistics requests the receipt of the transaction to include
+	// statistics on the accesses to the world state. Like tracing, it does
+	// not alter the execution and may be used for regular transactions.
+	AccessStatistics bool
+	// UnlimitedGas executes the transaction with the gas limit set to
+	// UnlimitedGasLimit, ignoring its own limit and the GasCap. Gas is still
+	// metered and the receipt reports the gas the execution would consume,
+	// without the penalty for unused gas. Since such gas can not be paid
+	// for, this option implies NoBalanceCheck. Intended for view calls, whose
+	// executions should be bounded through the context instead.
+	UnlimitedGas bool
+	// Limits bounds the resources the simulated transaction may use. The
+	// zero value imposes no limits besides the gas limit.
+	Limits SimulationLimits
+}
+
+// UnlimitedGasLimit is the gas limit of transactions simulated with the
+// UnlimitedGas option. It exceeds the gas any realistic execution may
+// consume while leaving room for gas computations not to overflow.
+const UnlimitedGasLimit Gas = 1 << 60
+
+// Apply adapts the parameters of a transaction execution to the relaxations
+// requested by the options. It is intended to be used by processors before
+// starting the execution of a simulated transaction.
+func (o SimulationOptions) Apply(blockParameters *BlockParameters, transaction *Transaction) {
+	if o.UnlimitedGas {
+		transaction.GasLimit = UnlimitedGasLimit
+	} else if o.GasCap > 0 && transaction.GasLimit > o.GasCap {
+		transaction.GasLimit = o.GasCap
+	}
+	if o.NoBaseFee && transaction.GasPrice == (Value{}) {
+		blockParameters.BaseFee = Value{}
+	}
+}
+
+// SimulationResult summarizes the result of the simulation of a transaction.
+type SimulationResult struct {
+	Receipt
+	// Aborted is true if the simulation was aborted before it completed, for
+	// instance, because it ran out of time. In such a case, the receipt is
+	// undefined.
+	Aborted bool
+	// LimitExceeded is set if the simulation got aborted because it exceeded
+	// one of the configured SimulationLimits.
+	LimitExceeded *ErrSimulationLimitExceeded
 }
 
 // Transaction summarizes the parameters of a transaction to be executed on a chain.
@@ -51,4 +153,113 @@ type Receipt struct {
 	GasUsed         Gas      // gas used by contract calls
 	BlobGasUsed     Gas      // gas used for blob transactions
 	Logs            []Log    // logs produced by the transaction
+
+	// CalldataFloorGas is the minimum gas charged for the input of the
+	// transaction as introduced by EIP-7623. It is zero for revisions not
+	// enforcing such a floor, which currently includes all supported ones.
+	CalldataFloorGas Gas
+
+	// GasBreakdown details the composition of GasUsed. It is only filled if
+	// requested through SimulationOptions.GasBreakdown and if the transaction
+	// got executed.
+	GasBreakdown *GasBreakdown
+
+	// ResourceUsage lists the high-water marks of the resources used by the
+	// execution. It is only filled if requested through
+	// SimulationOptions.ResourceUsage and if the transaction got executed.
+	// Interpreters not supporting the tracking of resources leave it zero.
+	ResourceUsage *ResourceUsage
+
+	// AccessStatistics summarizes the accesses of the transaction to the
+	// world state. It is only filled if requested through
+	// SimulationOptions.AccessStatistics and if the transaction got executed.
+	AccessStatistics *AccessStatistics
+}
+
+// GasUsage returns the gas used by the transaction in all gas dimensions.
+// Fee logic is encouraged to use it instead of the individual fields, since
+// future revisions may introduce additional dimensions.
+func (r *Receipt) GasUsage() MultiGas {
+	var usage MultiGas
+	usage.Set(GasDimensionExecution, r.GasUsed)
+	usage.Set(GasDimensionBlob, r.BlobGasUsed)
+	usage.Set(GasDimensionCalldataFloor, r.CalldataFloorGas)
+	return usage
+}
+
+// SetGasUsage updates the gas fields of the receipt to the given usage.
+func (r *Receipt) SetGasUsage(usage MultiGas) {
+	r.GasUsed = usage.Get(GasDimensionExecution)
+	r.BlobGasUsed = usage.Get(GasDimensionBlob)
+	r.CalldataFloorGas = usage.Get(GasDimensionCalldataFloor)
+}
+
+// GasBreakdown describes how the gas used by a transaction is composed. The
+// components are related by
+//
+//	GasUsed  = IntrinsicGas + ExecutionGas + UnusedGasPenalty - Refund + FloorSurcharge
+//	Returned = GasLimit - GasUsed
+type GasBreakdown struct {
+	IntrinsicGas     Gas // the gas charged before starting the execution
+	ExecutionGas     Gas // the gas consumed by the execution of the transaction
+	UnusedGasPenalty Gas // the share of unused gas charged by Sonic for non-internal transactions
+	Refund           Gas // the refund applied after the execution, after capping
+	FloorSurcharge   Gas // the gas charged in addition to reach the calldata floor of EIP-7623
+	Returned         Gas // the gas returned to the sender
+}
+
+// forwardExtensions returns the given wrapper, extended by all optional
+// Processor extensions supported by the wrapped processor. Calls to those
+// extensions are forwarded unmodified to the wrapped processor. Wrappers
+// altering only Run should use this function to not hide any extension,
+// e.g. the SystemCallProcessor required for processing blocks.
+func forwardExtensions(wrapper Processor, wrapped Processor) Processor {
+	simulating, isSimulating := wrapped.(SimulatingProcessor)
+	systemCalls, isSystemCalls := wrapped.(SystemCallProcessor)
+	switch {
+	case isSimulating && isSystemCalls:
+		return &struct {
+			Processor
+			simulationForwarder
+			systemCallForwarder
+		}{wrapper, simulationForwarder{simulating}, systemCallForwarder{systemCalls}}
+	case isSimulating:
+		return &struct {
+			Processor
+			simulationForwarder
+		}{wrapper, simulationForwarder{simulating}}
+	case isSystemCalls:
+		return &struct {
+			Processor
+			systemCallForwarder
+		}{wrapper, systemCallForwarder{systemCalls}}
+	default:
+		return wrapper
+	}
+}
+
+type simulationForwarder struct {
+	simulating SimulatingProcessor
+}
+
+func (f simulationForwarder) Simulate(
+	ctx context.Context,
+	blockParameters BlockParameters,
+	transaction Transaction,
+	txContext TransactionContext,
+	options SimulationOptions,
+) (SimulationResult, error) {
+	return f.simulating.Simulate(ctx, blockParameters, transaction, txContext, options)
+}
+
+type systemCallForwarder struct {
+	systemCalls SystemCallProcessor
+}
+
+func (f systemCallForwarder) ProcessSystemCalls(
+	ctx context.Context,
+	parameters SystemCallParameters,
+	txContext TransactionContext,
+) (SystemCallResult, error) {
+	return f.systemCalls.ProcessSystemCalls(ctx, parameters, txContext)
 }
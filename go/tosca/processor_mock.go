@@ -20,6 +20,7 @@
 package tosca
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "go.uber.org/mock/gomock"
@@ -49,16 +50,69 @@ func (m *MockProcessor) EXPECT() *MockProcessorMockRecorder {
 }
 
 // Run mocks base method.
-func (m *MockProcessor) Run(arg0 BlockParameters, arg1 Transaction, arg2 TransactionContext) (Receipt, error) {
+func (m *MockProcessor) Run(arg0 context.Context, arg1 BlockParameters, arg2 Transaction, arg3 TransactionContext) (Receipt, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Run", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "Run", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(Receipt)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Run indicates an expected call of Run.
-func (mr *MockProcessorMockRecorder) Run(arg0, arg1, arg2 any) *gomock.Call {
+func (mr *MockProcessorMockRecorder) Run(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockProcessor)(nil).Run), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockProcessor)(nil).Run), arg0, arg1, arg2, arg3)
+}
+
+// MockSimulatingProcessor is a mock of SimulatingProcessor interface.
+type MockSimulatingProcessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockSimulatingProcessorMockRecorder
+}
+
+// MockSimulatingProcessorMockRecorder is the mock recorder for MockSimulatingProcessor.
+type MockSimulatingProcessorMockRecorder struct {
+	mock *MockSimulatingProcessor
+}
+
+// NewMockSimulatingProcessor creates a new mock instance.
+func NewMockSimulatingProcessor(ctrl *gomock.Controller) *MockSimulatingProcessor {
+	mock := &MockSimulatingProcessor{ctrl: ctrl}
+	mock.recorder = &MockSimulatingProcessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSimulatingProcessor) EXPECT() *MockSimulatingProcessorMockRecorder {
+	return m.recorder
+}
+
+// Run mocks base method.
+func (m *MockSimulatingProcessor) Run(arg0 context.Context, arg1 BlockParameters, arg2 Transaction, arg3 TransactionContext) (Receipt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(Receipt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockSimulatingProcessorMockRecorder) Run(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockSimulatingProcessor)(nil).Run), arg0, arg1, arg2, arg3)
+}
+
+// Simulate mocks base method.
+func (m *MockSimulatingProcessor) Simulate(arg0 context.Context, arg1 BlockParameters, arg2 Transaction, arg3 TransactionContext, arg4 SimulationOptions) (SimulationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Simulate", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(SimulationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Simulate indicates an expected call of Simulate.
+func (mr *MockSimulatingProcessorMockRecorder) Simulate(arg0, arg1, arg2, arg3, arg4 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Simulate", reflect.TypeOf((*MockSimulatingProcessor)(nil).Simulate), arg0, arg1, arg2, arg3, arg4)
 }
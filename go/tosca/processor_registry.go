@@ -47,6 +47,14 @@ func GetProcessorFactory(name string) ProcessorFactory {
 	return processorRegistry[strings.ToLower(name)]
 }
 
+// ListProcessors lists all registered processor factories, sorted by name,
+// including their metadata if provided during the registration.
+func ListProcessors() []ImplementationInfo {
+	processorRegistryLock.Lock()
+	defer processorRegistryLock.Unlock()
+	return listImplementations(processorRegistry, processorMetadata)
+}
+
 // GetAllRegisteredProcessorFactories obtains all registered implementations.
 func GetAllRegisteredProcessorFactories() map[string]ProcessorFactory {
 	processorRegistryLock.Lock()
@@ -57,19 +65,27 @@ func GetAllRegisteredProcessorFactories() map[string]ProcessorFactory {
 // RegisterProcessorFactory can be used to register a new Processor implementation
 // to be exported for general use in the binary. The name is not case-sensitive,
 // and a panic is triggered if an implementation was bound to the same name
-// before, or the implementation is nil. This function is mainly intended to be
-// used by package initialization code.
-func RegisterProcessorFactory(name string, impl ProcessorFactory) {
+// before, or the implementation is nil. Optionally, metadata describing the
+// implementation may be provided, which is reported by ListProcessors. This
+// function is mainly intended to be used by package initialization code.
+func RegisterProcessorFactory(name string, impl ProcessorFactory, metadata ...ImplementationMetadata) {
 	key := strings.ToLower(name)
 	if impl == nil {
 		panic(fmt.Sprintf("invalid initialization: cannot register nil-processor using `%s`", key))
 	}
+	data, err := getOptionalMetadata(metadata)
+	if err != nil {
+		panic(fmt.Sprintf("invalid initialization of `%s`: %v", key, err))
+	}
 	processorRegistryLock.Lock()
 	defer processorRegistryLock.Unlock()
 	if _, found := processorRegistry[key]; found {
 		panic(fmt.Sprintf("invalid initialization: multiple Processors registered for `%s`", key))
 	}
 	processorRegistry[key] = impl
+	if data != nil {
+		processorMetadata[key] = data
+	}
 }
 
 // ProcessorFactory is the type of a function that creates a new Processor
@@ -80,5 +96,9 @@ type ProcessorFactory func(Interpreter) Processor
 // different implementations and configurations.
 var processorRegistry = map[string]ProcessorFactory{}
 
+// processorMetadata contains the metadata provided for registered processor
+// factories.
+var processorMetadata = map[string]*ImplementationMetadata{}
+
 // processorRegistryLock to protect access to the registry.
 var processorRegistryLock sync.Mutex
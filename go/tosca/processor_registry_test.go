@@ -12,6 +12,7 @@ package tosca
 
 import (
 	"slices"
+	"strings"
 	"testing"
 
 	gomock "go.uber.org/mock/gomock"
@@ -97,3 +98,63 @@ func TestProcessorRegistry_FailToRegisterSameNameMultipleTimes(t *testing.T) {
 	}()
 	RegisterProcessorFactory(name, myFactory)
 }
+
+func TestProcessorRegistry_ListProcessorsReportsMetadata(t *testing.T) {
+	myFactory := func(Interpreter) Processor { return nil }
+	metadata := ImplementationMetadata{
+		Language:       "Go",
+		OldestRevision: R09_Berlin,
+		NewestRevision: R13_Cancun,
+	}
+	RegisterProcessorFactory("Test-With-Metadata", myFactory, metadata)
+	RegisterProcessorFactory("test-without-metadata", myFactory)
+
+	infos := ListProcessors()
+	if !slices.IsSortedFunc(infos, func(a, b ImplementationInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	}) {
+		t.Errorf("processors are not sorted by name: %v", infos)
+	}
+
+	found := 0
+	for _, info := range infos {
+		switch info.Name {
+		case "test-with-metadata":
+			found++
+			if info.Metadata == nil || *info.Metadata != metadata {
+				t.Errorf("unexpected metadata, wanted %v, got %v", metadata, info.Metadata)
+			}
+		case "test-without-metadata":
+			found++
+			if info.Metadata != nil {
+				t.Errorf("unexpected metadata, got %v", info.Metadata)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("registered processors not listed, got %v", infos)
+	}
+}
+
+func TestProcessorRegistry_FailToRegisterInvalidMetadata(t *testing.T) {
+	myFactory := func(Interpreter) Processor { return nil }
+	tests := map[string][]ImplementationMetadata{
+		"too many":          {{}, {}},
+		"invalid revisions": {{OldestRevision: R13_Cancun, NewestRevision: R07_Istanbul}},
+	}
+	for name, metadata := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("expected panic, got nil")
+				}
+			}()
+			RegisterProcessorFactory("test-invalid-metadata-"+name, myFactory, metadata...)
+		})
+	}
+	for _, info := range ListProcessors() {
+		if strings.HasPrefix(info.Name, "test-invalid-metadata-") {
+			t.Errorf("processor with invalid metadata was registered: %v", info)
+		}
+	}
+}
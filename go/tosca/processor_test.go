@@ -0,0 +1,161 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSimulationOptions_Apply_CapsGasLimit(t *testing.T) {
+	tests := map[string]struct {
+		gasCap   Gas
+		gasLimit Gas
+		want     Gas
+	}{
+		"no cap":         {gasCap: 0, gasLimit: 100, want: 100},
+		"negative cap":   {gasCap: -1, gasLimit: 100, want: 100},
+		"limit below":    {gasCap: 200, gasLimit: 100, want: 100},
+		"limit equal":    {gasCap: 100, gasLimit: 100, want: 100},
+		"limit exceeded": {gasCap: 50, gasLimit: 100, want: 50},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			options := SimulationOptions{GasCap: test.gasCap}
+			blockParameters := BlockParameters{}
+			transaction := Transaction{GasLimit: test.gasLimit}
+			options.Apply(&blockParameters, &transaction)
+			if want, got := test.want, transaction.GasLimit; want != got {
+				t.Errorf("unexpected gas limit, wanted %d, got %d", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulationOptions_Apply_UnlimitedGasOverridesLimitAndCap(t *testing.T) {
+	for _, gasCap := range []Gas{0, 50} {
+		options := SimulationOptions{GasCap: gasCap, UnlimitedGas: true}
+		blockParameters := BlockParameters{}
+		transaction := Transaction{GasLimit: 100}
+		options.Apply(&blockParameters, &transaction)
+		if want, got := UnlimitedGasLimit, transaction.GasLimit; want != got {
+			t.Errorf("unexpected gas limit, wanted %d, got %d", want, got)
+		}
+	}
+}
+
+func TestSimulationOptions_Apply_DisablesBaseFeeForZeroGasPrice(t *testing.T) {
+	tests := map[string]struct {
+		noBaseFee bool
+		gasPrice  Value
+		want      Value
+	}{
+		"base fee enabled":            {noBaseFee: false, gasPrice: Value{}, want: NewValue(10)},
+		"disabled with zero price":    {noBaseFee: true, gasPrice: Value{}, want: Value{}},
+		"disabled with nonzero price": {noBaseFee: true, gasPrice: NewValue(1), want: NewValue(10)},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			options := SimulationOptions{NoBaseFee: test.noBaseFee}
+			blockParameters := BlockParameters{BaseFee: NewValue(10)}
+			transaction := Transaction{GasPrice: test.gasPrice}
+			options.Apply(&blockParameters, &transaction)
+			if want, got := test.want, blockParameters.BaseFee; want != got {
+				t.Errorf("unexpected base fee, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+// systemCallProcessor is a processor supporting system calls, and optionally
+// simulations through an embedded SimulatingProcessor.
+type systemCallProcessor struct {
+	Processor
+	result SystemCallResult
+}
+
+func (p systemCallProcessor) ProcessSystemCalls(context.Context, SystemCallParameters, TransactionContext) (SystemCallResult, error) {
+	return p.result, nil
+}
+
+type simulatingSystemCallProcessor struct {
+	SimulatingProcessor
+	systemCallProcessor
+}
+
+func (p simulatingSystemCallProcessor) Run(
+	ctx context.Context,
+	blockParameters BlockParameters,
+	transaction Transaction,
+	txContext TransactionContext,
+) (Receipt, error) {
+	return p.SimulatingProcessor.Run(ctx, blockParameters, transaction, txContext)
+}
+
+func TestForwardExtensions_AllCombinationsOfExtensionsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	requests := SystemCallResult{Requests: []Data{{1}}}
+	simulation := SimulationResult{Receipt: Receipt{GasUsed: 21000}}
+	simulating := NewMockSimulatingProcessor(ctrl)
+	simulating.EXPECT().Simulate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(simulation, nil).AnyTimes()
+
+	tests := map[string]struct {
+		processor   Processor
+		simulations bool
+		systemCalls bool
+	}{
+		"plain":        {NewMockProcessor(ctrl), false, false},
+		"simulations":  {simulating, true, false},
+		"system calls": {systemCallProcessor{NewMockProcessor(ctrl), requests}, false, true},
+		"both": {
+			simulatingSystemCallProcessor{simulating, systemCallProcessor{result: requests}},
+			true, true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wrapper := NewMockProcessor(ctrl)
+			wrapper.EXPECT().Run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(Receipt{GasUsed: 1}, nil)
+			processor := forwardExtensions(wrapper, test.processor)
+
+			if receipt, _ := processor.Run(context.Background(), BlockParameters{}, Transaction{}, nil); receipt.GasUsed != 1 {
+				t.Errorf("Run should be handled by the wrapper, got receipt %v", receipt)
+			}
+
+			simulator, ok := processor.(SimulatingProcessor)
+			if ok != test.simulations {
+				t.Fatalf("unexpected support of simulations, wanted %t, got %t", test.simulations, ok)
+			}
+			if ok {
+				got, err := simulator.Simulate(context.Background(), BlockParameters{}, Transaction{}, nil, SimulationOptions{})
+				if err != nil || got.Receipt.GasUsed != simulation.Receipt.GasUsed {
+					t.Errorf("unexpected simulation result %v, error %v", got, err)
+				}
+			}
+
+			systemCaller, ok := processor.(SystemCallProcessor)
+			if ok != test.systemCalls {
+				t.Fatalf("unexpected support of system calls, wanted %t, got %t", test.systemCalls, ok)
+			}
+			if ok {
+				got, err := systemCaller.ProcessSystemCalls(context.Background(), SystemCallParameters{}, nil)
+				if err != nil || len(got.Requests) != 1 {
+					t.Errorf("unexpected system call result %v, error %v", got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "fmt"
+
+// ErrReadOnlyContextViolation is reported by a ReadOnlyTransactionContext if
+// a modification of the state was attempted through it.
+type ErrReadOnlyContextViolation struct {
+	Operation string  // the rejected TransactionContext method, e.g. SetStorage
+	Address   Address // the account whose state was to be modified
+}
+
+func (e *ErrReadOnlyContextViolation) Error() string {
+	return fmt.Sprintf("read-only context violation: %s on account %v", e.Operation, e.Address)
+}
+
+// ReadOnlyTransactionContext is a TransactionContext wrapper rejecting all
+// modifications of the wrapped context. Rejected are updates of balances,
+// nonces, codes, storage, and transient storage, as well as logs and self-
+// destructs. Rejected operations are not forwarded to the wrapped context;
+// the first of them is retained and reported by Err.
+//
+// Read operations, snapshots, and the tracking of accessed accounts and
+// storage slots are forwarded unmodified. The latter are transaction-local
+// and do not alter the shared state.
+//
+// It is intended for serving pure view calls and for guaranteeing that
+// execution paths like gas estimations do not leak writes into shared state.
+type ReadOnlyTransactionContext struct {
+	TransactionContext
+	violation *ErrReadOnlyContextViolation
+}
+
+// NewReadOnlyTransactionContext wraps the given context such that all
+// modifications are rejected.
+func NewReadOnlyTransactionContext(context TransactionContext) *ReadOnlyTransactionContext {
+	return &ReadOnlyTransactionContext{TransactionContext: context}
+}
+
+// Err returns the first rejected modification as an
+// *ErrReadOnlyContextViolation, or nil if no modification was attempted.
+func (c *ReadOnlyTransactionContext) Err() error {
+	if c.violation == nil {
+		return nil
+	}
+	return c.violation
+}
+
+func (c *ReadOnlyTransactionContext) reject(operation string, address Address) {
+	if c.violation == nil {
+		c.violation = &ErrReadOnlyContextViolation{
+			Operation: operation,
+			Address:   address,
+		}
+	}
+}
+
+func (c *ReadOnlyTransactionContext) SetBalance(address Address, _ Value) {
+	c.reject("SetBalance", address)
+}
+
+func (c *ReadOnlyTransactionContext) SetNonce(address Address, _ uint64) {
+	c.reject("SetNonce", address)
+}
+
+func (c *ReadOnlyTransactionContext) SetCode(address Address, _ Code) {
+	c.reject("SetCode", address)
+}
+
+func (c *ReadOnlyTransactionContext) SetStorage(address Address, _ Key, _ Word) StorageStatus {
+	c.reject("SetStorage", address)
+	return StorageAssigned
+}
+
+func (c *ReadOnlyTransactionContext) SetTransientStorage(address Address, _ Key, _ Word) {
+	c.reject("SetTransientStorage", address)
+}
+
+func (c *ReadOnlyTransactionContext) EmitLog(log Log) {
+	c.reject("EmitLog", log.Address)
+}
+
+func (c *ReadOnlyTransactionContext) SelfDestruct(address Address, _ Address) bool {
+	c.reject("SelfDestruct", address)
+	return false
+}
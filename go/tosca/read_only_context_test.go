@@ -0,0 +1,118 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestReadOnlyTransactionContext_ModificationsAreRejected(t *testing.T) {
+	tests := map[string]struct {
+		operation string
+		address   Address
+		run       func(TransactionContext)
+	}{
+		"SetBalance": {"SetBalance", Address{1}, func(c TransactionContext) {
+			c.SetBalance(Address{1}, Value{1})
+		}},
+		"SetNonce": {"SetNonce", Address{2}, func(c TransactionContext) {
+			c.SetNonce(Address{2}, 1)
+		}},
+		"SetCode": {"SetCode", Address{3}, func(c TransactionContext) {
+			c.SetCode(Address{3}, Code{1})
+		}},
+		"SetStorage": {"SetStorage", Address{4}, func(c TransactionContext) {
+			c.SetStorage(Address{4}, Key{}, Word{1})
+		}},
+		"SetTransientStorage": {"SetTransientStorage", Address{5}, func(c TransactionContext) {
+			c.SetTransientStorage(Address{5}, Key{}, Word{1})
+		}},
+		"EmitLog": {"EmitLog", Address{6}, func(c TransactionContext) {
+			c.EmitLog(Log{Address: Address{6}})
+		}},
+		"SelfDestruct": {"SelfDestruct", Address{7}, func(c TransactionContext) {
+			c.SelfDestruct(Address{7}, Address{8})
+		}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			// The wrapped context is not expected to receive any calls.
+			context := NewReadOnlyTransactionContext(NewMockTransactionContext(ctrl))
+
+			test.run(context)
+
+			var violation *ErrReadOnlyContextViolation
+			if !errors.As(context.Err(), &violation) {
+				t.Fatalf("expected read-only context violation, got %v", context.Err())
+			}
+			want := ErrReadOnlyContextViolation{Operation: test.operation, Address: test.address}
+			if want != *violation {
+				t.Errorf("unexpected violation, wanted %v, got %v", &want, violation)
+			}
+		})
+	}
+}
+
+func TestReadOnlyTransactionContext_FirstViolationIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewReadOnlyTransactionContext(NewMockTransactionContext(ctrl))
+
+	context.SetNonce(Address{1}, 1)
+	context.SetBalance(Address{2}, Value{1})
+
+	var violation *ErrReadOnlyContextViolation
+	if !errors.As(context.Err(), &violation) {
+		t.Fatalf("expected read-only context violation, got %v", context.Err())
+	}
+	if want, got := "SetNonce", violation.Operation; want != got {
+		t.Errorf("unexpected operation, wanted %s, got %s", want, got)
+	}
+}
+
+func TestReadOnlyTransactionContext_ReadsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	inner := NewMockTransactionContext(ctrl)
+	context := NewReadOnlyTransactionContext(inner)
+
+	inner.EXPECT().GetBalance(Address{1}).Return(Value{2})
+	inner.EXPECT().GetStorage(Address{1}, Key{3}).Return(Word{4})
+	inner.EXPECT().AccessAccount(Address{1}).Return(WarmAccess)
+	inner.EXPECT().CreateSnapshot().Return(Snapshot(5))
+	inner.EXPECT().RestoreSnapshot(Snapshot(5))
+
+	if want, got := (Value{2}), context.GetBalance(Address{1}); want != got {
+		t.Errorf("unexpected balance, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{4}), context.GetStorage(Address{1}, Key{3}); want != got {
+		t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+	}
+	if want, got := WarmAccess, context.AccessAccount(Address{1}); want != got {
+		t.Errorf("unexpected access status, wanted %v, got %v", want, got)
+	}
+	context.RestoreSnapshot(context.CreateSnapshot())
+
+	if err := context.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestErrReadOnlyContextViolation_ErrorDescribesViolation(t *testing.T) {
+	err := &ErrReadOnlyContextViolation{Operation: "SetNonce", Address: Address{0x12}}
+	want := "read-only context violation: SetNonce on account 0x1200000000000000000000000000000000000000"
+	if got := err.Error(); want != got {
+		t.Errorf("unexpected error message, wanted %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+// RefundPolicy determines the cap applied to the gas refunded to the sender
+// of a transaction at the end of its execution, as accumulated by clearing
+// storage slots. Processors consult their policy for every successfully
+// executed transaction. If no policy is configured, the policy defined by
+// Ethereum is used, see revisions.EthereumRefundPolicy.
+type RefundPolicy interface {
+	// GetMaxRefundQuotient returns the quotient q limiting the refund of
+	// transactions executed in the given revision to gasUsed / q. A quotient
+	// of zero disables refunds.
+	GetMaxRefundQuotient(revision Revision) uint64
+}
+
+// RefundPolicyFunc is an adapter enabling the use of plain functions as
+// refund policies.
+type RefundPolicyFunc func(revision Revision) uint64
+
+func (f RefundPolicyFunc) GetMaxRefundQuotient(revision Revision) uint64 {
+	return f(revision)
+}
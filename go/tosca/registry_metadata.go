@@ -0,0 +1,91 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ImplementationMetadata describes properties of an interpreter or processor
+// implementation relevant for selecting and validating configurations. It may
+// optionally be provided when registering a factory.
+type ImplementationMetadata struct {
+	Language       string   // the implementation language, e.g. Go, C++, or Rust
+	RequiresCgo    bool     // true if the implementation depends on cgo
+	OldestRevision Revision // the oldest revision supported by the implementation
+	NewestRevision Revision // the newest revision supported by the implementation
+}
+
+// SupportsRevision reports whether the given revision is in the range of
+// revisions supported by the described implementation.
+func (m ImplementationMetadata) SupportsRevision(revision Revision) bool {
+	return m.OldestRevision <= revision && revision <= m.NewestRevision
+}
+
+// ImplementationInfo describes a factory registered in one of the registries.
+type ImplementationInfo struct {
+	Name     string                  // the (lower-case) name of the factory
+	Metadata *ImplementationMetadata // nil if not provided during registration
+}
+
+func (i ImplementationInfo) String() string {
+	if i.Metadata == nil {
+		return i.Name
+	}
+	return fmt.Sprintf(
+		"%s (%s, cgo: %t, revisions: %v-%v)",
+		i.Name, i.Metadata.Language, i.Metadata.RequiresCgo,
+		i.Metadata.OldestRevision, i.Metadata.NewestRevision,
+	)
+}
+
+// getOptionalMetadata extracts the metadata passed as an optional argument
+// to the registration functions.
+func getOptionalMetadata(metadata []ImplementationMetadata) (*ImplementationMetadata, error) {
+	switch len(metadata) {
+	case 0:
+		return nil, nil
+	case 1:
+		res := metadata[0]
+		if res.OldestRevision > res.NewestRevision {
+			return nil, fmt.Errorf(
+				"invalid metadata: oldest revision %v is newer than newest revision %v",
+				res.OldestRevision, res.NewestRevision,
+			)
+		}
+		return &res, nil
+	default:
+		return nil, fmt.Errorf("invalid metadata: too many arguments")
+	}
+}
+
+// listImplementations produces infos for the given registered names, sorted
+// by name.
+func listImplementations[F any](
+	registry map[string]F,
+	metadata map[string]*ImplementationMetadata,
+) []ImplementationInfo {
+	res := make([]ImplementationInfo, 0, len(registry))
+	for name := range registry {
+		info := ImplementationInfo{Name: name}
+		if data := metadata[name]; data != nil {
+			clone := *data
+			info.Metadata = &clone
+		}
+		res = append(res, info)
+	}
+	slices.SortFunc(res, func(a, b ImplementationInfo) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return res
+}
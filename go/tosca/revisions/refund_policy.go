@@ -0,0 +1,37 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package revisions
+
+import "github.com/Fantom-foundation/Tosca/go/tosca"
+
+// EthereumRefundPolicy caps refunds as defined by Ethereum. Refunds are capped
+// to half of the gas used, until EIP-3529 reduces the cap to a fifth of the
+// gas used.
+var EthereumRefundPolicy tosca.RefundPolicy = tosca.RefundPolicyFunc(func(revision tosca.Revision) uint64 {
+	if IsActive(revision, EIP3529) {
+		return 5
+	}
+	return 2
+})
+
+// CapRefund limits the given refund of a transaction which used the given
+// amount of gas according to the given policy. If the policy is nil,
+// EthereumRefundPolicy is used.
+func CapRefund(policy tosca.RefundPolicy, revision tosca.Revision, gasUsed tosca.Gas, refund tosca.Gas) tosca.Gas {
+	if policy == nil {
+		policy = EthereumRefundPolicy
+	}
+	quotient := policy.GetMaxRefundQuotient(revision)
+	if quotient == 0 {
+		return 0
+	}
+	return min(refund, gasUsed/tosca.Gas(quotient))
+}
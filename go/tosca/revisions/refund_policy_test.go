@@ -0,0 +1,55 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package revisions
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestEthereumRefundPolicy_QuotientIsReducedSinceLondon(t *testing.T) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		want := uint64(5)
+		if revision < tosca.R10_London {
+			want = 2
+		}
+		if got := EthereumRefundPolicy.GetMaxRefundQuotient(revision); want != got {
+			t.Errorf("unexpected quotient for %v, wanted %d, got %d", revision, want, got)
+		}
+	}
+}
+
+func TestCapRefund_LimitsRefundToFractionOfGasUsed(t *testing.T) {
+	tests := map[string]struct {
+		policy   tosca.RefundPolicy
+		revision tosca.Revision
+		refund   tosca.Gas
+		want     tosca.Gas
+	}{
+		"nil policy before london":    {policy: nil, revision: tosca.R09_Berlin, refund: 100, want: 50},
+		"nil policy since london":     {policy: nil, revision: tosca.R10_London, refund: 100, want: 20},
+		"refund below cap":            {policy: nil, revision: tosca.R10_London, refund: 10, want: 10},
+		"custom quotient":             {policy: tosca.RefundPolicyFunc(func(tosca.Revision) uint64 { return 10 }), revision: tosca.R10_London, refund: 100, want: 10},
+		"quotient of one":             {policy: tosca.RefundPolicyFunc(func(tosca.Revision) uint64 { return 1 }), revision: tosca.R10_London, refund: 200, want: 100},
+		"zero quotient disables":      {policy: tosca.RefundPolicyFunc(func(tosca.Revision) uint64 { return 0 }), revision: tosca.R10_London, refund: 100, want: 0},
+		"ethereum policy is explicit": {policy: EthereumRefundPolicy, revision: tosca.R13_Cancun, refund: 100, want: 20},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := CapRefund(test.policy, test.revision, 100, test.refund)
+			if test.want != got {
+				t.Errorf("unexpected refund, wanted %d, got %d", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package revisions describes the Ethereum Improvement Proposals (EIPs)
+// activated by each revision supported by Tosca, including the changes of gas
+// costs they introduced. It enables implementations to check for the
+// activation of a feature by naming the EIP introducing it instead of the
+// revision, and tools to list the differences between revisions.
+//
+// Istanbul is the oldest supported revision. The EIPs listed for Istanbul are
+// the ones it activated on top of Petersburg. For revisions scheduled after
+// the newest supported revision, only EIPs already implemented are listed.
+package revisions
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+// EIP identifies an Ethereum Improvement Proposal by its number.
+type EIP int
+
+const (
+	// Istanbul
+	EIP152  EIP = 152  // BLAKE2 compression function precompile
+	EIP1108 EIP = 1108 // reduced alt_bn128 precompile gas costs
+	EIP1344 EIP = 1344 // CHAINID instruction
+	EIP1884 EIP = 1884 // repricing of trie-size-dependent instructions
+	EIP2028 EIP = 2028 // reduced calldata gas costs
+	EIP2200 EIP = 2200 // structured definitions for net gas metering
+
+	// Berlin
+	EIP2565 EIP = 2565 // MODEXP gas costs
+	EIP2718 EIP = 2718 // typed transaction envelope
+	EIP2929 EIP = 2929 // gas cost increases for state access instructions
+	EIP2930 EIP = 2930 // optional access lists
+
+	// London
+	EIP1559 EIP = 1559 // fee market change
+	EIP3198 EIP = 3198 // BASEFEE instruction
+	EIP3529 EIP = 3529 // reduction in refunds
+	EIP3541 EIP = 3541 // reject new contracts starting with the 0xEF byte
+
+	// Paris
+	EIP3675 EIP = 3675 // upgrade consensus to proof-of-stake
+	EIP4399 EIP = 4399 // supplant DIFFICULTY instruction with PREVRANDAO
+
+	// Shanghai
+	EIP3651 EIP = 3651 // warm COINBASE
+	EIP3855 EIP = 3855 // PUSH0 instruction
+	EIP3860 EIP = 3860 // limit and meter init code
+	EIP4895 EIP = 4895 // beacon chain push withdrawals as operations
+
+	// Cancun
+	EIP1153 EIP = 1153 // transient storage opcodes
+	EIP4788 EIP = 4788 // beacon block root in the EVM
+	EIP4844 EIP = 4844 // shard blob transactions
+	EIP5656 EIP = 5656 // MCOPY instruction
+	EIP6780 EIP = 6780 // SELFDESTRUCT only in same transaction
+	EIP7516 EIP = 7516 // BLOBBASEFEE instruction
+
+	// Prague
+	EIP2935 EIP = 2935 // serve historical block hashes from state
+	EIP7002 EIP = 7002 // execution layer triggerable withdrawals
+	EIP7251 EIP = 7251 // increase the MAX_EFFECTIVE_BALANCE
+	EIP7623 EIP = 7623 // increase calldata cost
+
+	// Osaka
+	EIP7883 EIP = 7883 // MODEXP gas cost increase
+)
+
+func (e EIP) String() string {
+	return fmt.Sprintf("EIP-%d", int(e))
+}
+
+// GasChange describes a change of the gas costs of a single operation or
+// charge. Operations introduced by an EIP have zero costs before the change.
+type GasChange struct {
+	EIP       EIP
+	Operation string    // < the affected instruction or charge, e.g. "SLOAD"
+	Note      string    // < optional details, e.g. the access state the costs apply to
+	Before    tosca.Gas // < the costs before the change
+	After     tosca.Gas // < the costs after the change
+}
+
+// Info summarizes an EIP activated by a revision.
+type Info struct {
+	EIP        EIP
+	Revision   tosca.Revision
+	Title      string
+	GasChanges []GasChange
+}
+
+// GetEIPs returns the EIPs activated by the given revision, ordered by their
+// numbers. The result is empty for unknown revisions.
+func GetEIPs(revision tosca.Revision) []Info {
+	var res []Info
+	for _, info := range eips {
+		if info.Revision == revision {
+			res = append(res, info)
+		}
+	}
+	return res
+}
+
+// GetInfo returns the description of the given EIP and whether it is known.
+func GetInfo(eip EIP) (Info, bool) {
+	for _, info := range eips {
+		if info.EIP == eip {
+			return info, true
+		}
+	}
+	return Info{}, false
+}
+
+// IsActive returns whether the given EIP is active in the given revision.
+// Unknown EIPs are never active.
+func IsActive(revision tosca.Revision, eip EIP) bool {
+	info, found := GetInfo(eip)
+	return found && revision >= info.Revision
+}
+
+// GetGasChanges lists the changes of gas costs conducted by the revisions
+// following the revision from up to and including the revision to, in the
+// order of their activation.
+func GetGasChanges(from, to tosca.Revision) []GasChange {
+	var res []GasChange
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		if revision <= from || revision > to {
+			continue
+		}
+		for _, info := range GetEIPs(revision) {
+			res = append(res, info.GasChanges...)
+		}
+	}
+	return res
+}
+
+var eips = []Info{
+	// --- Istanbul ---
+	{EIP: EIP152, Revision: tosca.R07_Istanbul, Title: "Add BLAKE2 compression function F precompile",
+		GasChanges: []GasChange{
+			{EIP: EIP152, Operation: "BLAKE2F precompile", Note: "per round", After: 1},
+		},
+	},
+	{EIP: EIP1108, Revision: tosca.R07_Istanbul, Title: "Reduce alt_bn128 precompile gas costs",
+		GasChanges: []GasChange{
+			{EIP: EIP1108, Operation: "ECADD precompile", Before: 500, After: 150},
+			{EIP: EIP1108, Operation: "ECMUL precompile", Before: 40000, After: 6000},
+			{EIP: EIP1108, Operation: "ECPAIRING precompile", Note: "base", Before: 100000, After: 45000},
+			{EIP: EIP1108, Operation: "ECPAIRING precompile", Note: "per pair", Before: 80000, After: 34000},
+		},
+	},
+	{EIP: EIP1344, Revision: tosca.R07_Istanbul, Title: "ChainID opcode",
+		GasChanges: []GasChange{
+			{EIP: EIP1344, Operation: "CHAINID", After: 2},
+		},
+	},
+	{EIP: EIP1884, Revision: tosca.R07_Istanbul, Title: "Repricing for trie-size-dependent opcodes",
+		GasChanges: []GasChange{
+			{EIP: EIP1884, Operation: "SLOAD", Before: 200, After: 800},
+			{EIP: EIP1884, Operation: "BALANCE", Before: 400, After: 700},
+			{EIP: EIP1884, Operation: "EXTCODEHASH", Before: 400, After: 700},
+			{EIP: EIP1884, Operation: "SELFBALANCE", After: 5},
+		},
+	},
+	{EIP: EIP2028, Revision: tosca.R07_Istanbul, Title: "Transaction data gas cost reduction",
+		GasChanges: []GasChange{
+			{EIP: EIP2028, Operation: "calldata", Note: "per non-zero byte", Before: 68, After: 16},
+		},
+	},
+	{EIP: EIP2200, Revision: tosca.R07_Istanbul, Title: "Structured Definitions for Net Gas Metering",
+		GasChanges: []GasChange{
+			{EIP: EIP2200, Operation: "SSTORE", Note: "no-op or dirty slot", Before: 5000, After: 800},
+		},
+	},
+
+	// --- Berlin ---
+	{EIP: EIP2565, Revision: tosca.R09_Berlin, Title: "ModExp Gas Cost",
+		GasChanges: []GasChange{
+			{EIP: EIP2565, Operation: "MODEXP precompile", Note: "minimum", After: 200},
+		},
+	},
+	{EIP: EIP2718, Revision: tosca.R09_Berlin, Title: "Typed Transaction Envelope"},
+	{EIP: EIP2929, Revision: tosca.R09_Berlin, Title: "Gas cost increases for state access opcodes",
+		GasChanges: []GasChange{
+			{EIP: EIP2929, Operation: "SLOAD", Note: "cold", Before: 800, After: 2100},
+			{EIP: EIP2929, Operation: "SLOAD", Note: "warm", Before: 800, After: 100},
+			{EIP: EIP2929, Operation: "SSTORE", Note: "no-op or dirty slot", Before: 800, After: 100},
+			{EIP: EIP2929, Operation: "SSTORE", Note: "modifying a clean slot", Before: 5000, After: 2900},
+			{EIP: EIP2929, Operation: "account access", Note: "cold", Before: 700, After: 2600},
+			{EIP: EIP2929, Operation: "account access", Note: "warm", Before: 700, After: 100},
+		},
+	},
+	{EIP: EIP2930, Revision: tosca.R09_Berlin, Title: "Optional access lists",
+		GasChanges: []GasChange{
+			{EIP: EIP2930, Operation: "access list", Note: "per address", After: 2400},
+			{EIP: EIP2930, Operation: "access list", Note: "per storage key", After: 1900},
+		},
+	},
+
+	// --- London ---
+	{EIP: EIP1559, Revision: tosca.R10_London, Title: "Fee market change for ETH 1.0 chain"},
+	{EIP: EIP3198, Revision: tosca.R10_London, Title: "BASEFEE opcode",
+		GasChanges: []GasChange{
+			{EIP: EIP3198, Operation: "BASEFEE", After: 2},
+		},
+	},
+	{EIP: EIP3529, Revision: tosca.R10_London, Title: "Reduction in refunds",
+		GasChanges: []GasChange{
+			{EIP: EIP3529, Operation: "SSTORE refund", Note: "clearing a slot", Before: 15000, After: 4800},
+			{EIP: EIP3529, Operation: "SELFDESTRUCT refund", Before: 24000, After: 0},
+		},
+	},
+	{EIP: EIP3541, Revision: tosca.R10_London, Title: "Reject new contract code starting with the 0xEF byte"},
+
+	// --- Paris ---
+	{EIP: EIP3675, Revision: tosca.R11_Paris, Title: "Upgrade consensus to Proof-of-Stake"},
+	{EIP: EIP4399, Revision: tosca.R11_Paris, Title: "Supplant DIFFICULTY opcode with PREVRANDAO"},
+
+	// --- Shanghai ---
+	{EIP: EIP3651, Revision: tosca.R12_Shanghai, Title: "Warm COINBASE",
+		GasChanges: []GasChange{
+			{EIP: EIP3651, Operation: "account access", Note: "first access of the coinbase", Before: 2600, After: 100},
+		},
+	},
+	{EIP: EIP3855, Revision: tosca.R12_Shanghai, Title: "PUSH0 instruction",
+		GasChanges: []GasChange{
+			{EIP: EIP3855, Operation: "PUSH0", After: 2},
+		},
+	},
+	{EIP: EIP3860, Revision: tosca.R12_Shanghai, Title: "Limit and meter initcode",
+		GasChanges: []GasChange{
+			{EIP: EIP3860, Operation: "init code", Note: "per word", After: 2},
+		},
+	},
+	{EIP: EIP4895, Revision: tosca.R12_Shanghai, Title: "Beacon chain push withdrawals as operations"},
+
+	// --- Cancun ---
+	{EIP: EIP1153, Revision: tosca.R13_Cancun, Title: "Transient storage opcodes",
+		GasChanges: []GasChange{
+			{EIP: EIP1153, Operation: "TLOAD", After: 100},
+			{EIP: EIP1153, Operation: "TSTORE", After: 100},
+		},
+	},
+	{EIP: EIP4788, Revision: tosca.R13_Cancun, Title: "Beacon block root in the EVM"},
+	{EIP: EIP4844, Revision: tosca.R13_Cancun, Title: "Shard Blob Transactions",
+		GasChanges: []GasChange{
+			{EIP: EIP4844, Operation: "BLOBHASH", After: 3},
+			{EIP: EIP4844, Operation: "point evaluation precompile", After: 50000},
+		},
+	},
+	{EIP: EIP5656, Revision: tosca.R13_Cancun, Title: "MCOPY - Memory copying instruction",
+		GasChanges: []GasChange{
+			{EIP: EIP5656, Operation: "MCOPY", Note: "plus 3 per word", After: 3},
+		},
+	},
+	{EIP: EIP6780, Revision: tosca.R13_Cancun, Title: "SELFDESTRUCT only in same transaction"},
+	{EIP: EIP7516, Revision: tosca.R13_Cancun, Title: "BLOBBASEFEE instruction",
+		GasChanges: []GasChange{
+			{EIP: EIP7516, Operation: "BLOBBASEFEE", After: 2},
+		},
+	},
+
+	// --- Prague ---
+	{EIP: EIP2935, Revision: tosca.R14_Prague, Title: "Serve historical block hashes from state"},
+	{EIP: EIP7002, Revision: tosca.R14_Prague, Title: "Execution layer triggerable withdrawals"},
+	{EIP: EIP7251, Revision: tosca.R14_Prague, Title: "Increase the MAX_EFFECTIVE_BALANCE"},
+	{EIP: EIP7623, Revision: tosca.R14_Prague, Title: "Increase calldata cost",
+		GasChanges: []GasChange{
+			{EIP: EIP7623, Operation: "calldata", Note: "floor per token", After: 10},
+		},
+	},
+
+	// --- Osaka ---
+	{EIP: EIP7883, Revision: tosca.R15_Osaka, Title: "ModExp Gas Cost Increase",
+		GasChanges: []GasChange{
+			{EIP: EIP7883, Operation: "MODEXP precompile", Note: "minimum", Before: 200, After: 500},
+		},
+	},
+}
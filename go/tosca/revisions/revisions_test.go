@@ -0,0 +1,147 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package revisions
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+func TestRevisions_EIPsAreUniqueAndSorted(t *testing.T) {
+	revisions := append(tosca.GetAllKnownRevisions(), tosca.R14_Prague, tosca.R15_Osaka)
+	seen := map[EIP]bool{}
+	for _, info := range eips {
+		if seen[info.EIP] {
+			t.Errorf("duplicate entry for %v", info.EIP)
+		}
+		seen[info.EIP] = true
+		if !slices.Contains(revisions, info.Revision) {
+			t.Errorf("%v is activated by unknown revision %v", info.EIP, info.Revision)
+		}
+		for _, change := range info.GasChanges {
+			if change.EIP != info.EIP {
+				t.Errorf("gas change of %v is attributed to %v", info.EIP, change.EIP)
+			}
+		}
+	}
+	for _, revision := range revisions {
+		list := GetEIPs(revision)
+		if len(list) == 0 {
+			t.Errorf("no EIPs listed for revision %v", revision)
+		}
+		if !slices.IsSortedFunc(list, func(a, b Info) int { return int(a.EIP - b.EIP) }) {
+			t.Errorf("EIPs of revision %v are not sorted", revision)
+		}
+	}
+}
+
+func TestIsActive_ReportsActivationByRevision(t *testing.T) {
+	tests := []struct {
+		eip        EIP
+		activation tosca.Revision
+	}{
+		{EIP1884, tosca.R07_Istanbul},
+		{EIP2929, tosca.R09_Berlin},
+		{EIP3529, tosca.R10_London},
+		{EIP3675, tosca.R11_Paris},
+		{EIP3860, tosca.R12_Shanghai},
+		{EIP1153, tosca.R13_Cancun},
+		{EIP2935, tosca.R14_Prague},
+		{EIP7623, tosca.R14_Prague},
+		{EIP7883, tosca.R15_Osaka},
+	}
+	for _, test := range tests {
+		for _, revision := range append(tosca.GetAllKnownRevisions(), tosca.R14_Prague, tosca.R15_Osaka) {
+			want := revision >= test.activation
+			if got := IsActive(revision, test.eip); want != got {
+				t.Errorf("unexpected activation of %v in %v, wanted %t, got %t", test.eip, revision, want, got)
+			}
+		}
+	}
+	if IsActive(tosca.R13_Cancun, EIP(1)) {
+		t.Errorf("unknown EIPs should not be active")
+	}
+}
+
+func TestGetInfo_ReturnsDescriptionOfKnownEIPs(t *testing.T) {
+	info, found := GetInfo(EIP3855)
+	if !found {
+		t.Fatalf("EIP-3855 should be known")
+	}
+	if want, got := tosca.R12_Shanghai, info.Revision; want != got {
+		t.Errorf("unexpected revision, wanted %v, got %v", want, got)
+	}
+	if _, found := GetInfo(EIP(1)); found {
+		t.Errorf("EIP-1 should not be known")
+	}
+}
+
+func TestGetGasChanges_ListsChangesBetweenRevisions(t *testing.T) {
+	changes := GetGasChanges(tosca.R09_Berlin, tosca.R12_Shanghai)
+	eipsOf := func(changes []GasChange) []EIP {
+		var res []EIP
+		for _, change := range changes {
+			if !slices.Contains(res, change.EIP) {
+				res = append(res, change.EIP)
+			}
+		}
+		return res
+	}
+	want := []EIP{EIP3198, EIP3529, EIP3651, EIP3855, EIP3860}
+	if got := eipsOf(changes); !slices.Equal(want, got) {
+		t.Errorf("unexpected EIPs, wanted %v, got %v", want, got)
+	}
+
+	if changes := GetGasChanges(tosca.R13_Cancun, tosca.R07_Istanbul); len(changes) != 0 {
+		t.Errorf("unexpected changes for inverted range: %v", changes)
+	}
+	if changes := GetGasChanges(tosca.R10_London, tosca.R10_London); len(changes) != 0 {
+		t.Errorf("unexpected changes for empty range: %v", changes)
+	}
+}
+
+func TestEIP_String(t *testing.T) {
+	if want, got := "EIP-2929", EIP2929.String(); want != got {
+		t.Errorf("unexpected string, wanted %q, got %q", want, got)
+	}
+}
+
+func TestAccessCostsApply_MatchesActivationOfEIP2929(t *testing.T) {
+	for _, revision := range tosca.GetAllKnownRevisions() {
+		if want, got := IsActive(revision, EIP2929), tosca.AccessCostsApply(revision); want != got {
+			t.Errorf("unexpected result for %v, wanted %t, got %t", revision, want, got)
+		}
+	}
+}
+
+func TestSystemCalls_AreConductedOnceTheirEIPIsActive(t *testing.T) {
+	eips := map[tosca.Address]EIP{
+		tosca.BeaconRootsAddress:           EIP4788,
+		tosca.HistoryStorageAddress:        EIP2935,
+		tosca.WithdrawalRequestsAddress:    EIP7002,
+		tosca.ConsolidationRequestsAddress: EIP7251,
+	}
+	for _, revision := range append(tosca.GetAllKnownRevisions(), tosca.R14_Prague, tosca.R15_Osaka) {
+		conducted := map[tosca.Address]bool{}
+		for _, phase := range []tosca.BlockPhase{tosca.BlockStart, tosca.BlockEnd} {
+			for _, call := range tosca.GetSystemCalls(revision, phase) {
+				conducted[call.Address] = true
+			}
+		}
+		for address, eip := range eips {
+			if want, got := IsActive(revision, eip), conducted[address]; want != got {
+				t.Errorf("unexpected system call of %v in %v, wanted %t, got %t", eip, revision, want, got)
+			}
+		}
+	}
+}
@@ -25,6 +25,8 @@ func TestRevisions_Marshal(t *testing.T) {
 		R11_Paris:    "\"Paris\"",
 		R12_Shanghai: "\"Shanghai\"",
 		R13_Cancun:   "\"Cancun\"",
+		R14_Prague:   "\"Prague\"",
+		R15_Osaka:    "\"Osaka\"",
 		Revision(42): "\"Revision(42)\"",
 	}
 
@@ -47,6 +49,8 @@ func TestRevisions_Unmarshal(t *testing.T) {
 		"\"Paris\"":        R11_Paris,
 		"\"Shanghai\"":     R12_Shanghai,
 		"\"Cancun\"":       R13_Cancun,
+		"\"Prague\"":       R14_Prague,
+		"\"Osaka\"":        R15_Osaka,
 		"\"Revision(42)\"": Revision(42),
 	}
 
@@ -81,7 +85,8 @@ func TestAllKnownRevisions(t *testing.T) {
 		}
 		existing = append(existing, r)
 	}
-	all := GetAllKnownRevisions()
+	// Scheduled revisions are named, but not yet known.
+	all := append(GetAllKnownRevisions(), R14_Prague, R15_Osaka)
 	slices.Sort(existing)
 	slices.Sort(all)
 	if !slices.Equal(existing, all) {
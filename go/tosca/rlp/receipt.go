@@ -0,0 +1,81 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Bloom is the 2048-bit bloom filter summarizing the addresses and topics of
+// the logs of a receipt or a block.
+type Bloom [256]byte
+
+// Add adds the given entry to the bloom filter by setting three bits derived
+// from its Keccak256 hash.
+func (b *Bloom) Add(data []byte) {
+	hash := crypto.Keccak256(data)
+	for i := 0; i < 6; i += 2 {
+		bit := (uint(hash[i])<<8 | uint(hash[i+1])) & 2047
+		b[len(b)-1-int(bit/8)] |= 1 << (bit % 8)
+	}
+}
+
+// GetLogsBloom computes the bloom filter of the given logs.
+func GetLogsBloom(logs []tosca.Log) Bloom {
+	var res Bloom
+	for _, log := range logs {
+		res.Add(log.Address[:])
+		for _, topic := range log.Topics {
+			res.Add(topic[:])
+		}
+	}
+	return res
+}
+
+// EncodeReceipt produces the consensus encoding of the receipt of a
+// transaction of the given type. Receipts include the gas used cumulatively
+// by all transactions of a block up to and including the receipt's
+// transaction, which needs to be provided by the caller. Receipts of typed
+// transactions are prefixed by their type byte according to EIP-2718.
+func EncodeReceipt(txType TransactionType, receipt tosca.Receipt, cumulativeGasUsed tosca.Gas) []byte {
+	var status uint64
+	if receipt.Success {
+		status = 1
+	}
+	bloom := GetLogsBloom(receipt.Logs)
+
+	fields := appendUint(nil, status)
+	fields = appendUint(fields, uint64(cumulativeGasUsed))
+	fields = appendString(fields, bloom[:])
+	fields = appendList(fields, encodeLogs(receipt.Logs))
+
+	var res []byte
+	if txType != LegacyTxType {
+		res = append(res, byte(txType))
+	}
+	return appendList(res, fields)
+}
+
+func encodeLogs(logs []tosca.Log) []byte {
+	var res []byte
+	for _, log := range logs {
+		var topics []byte
+		for _, topic := range log.Topics {
+			topics = appendString(topics, topic[:])
+		}
+		fields := appendString(nil, log.Address[:])
+		fields = appendList(fields, topics)
+		fields = appendString(fields, log.Data)
+		res = appendList(res, fields)
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestEncodeReceipt_MatchesGethEncoding(t *testing.T) {
+	logs := [][]tosca.Log{
+		nil,
+		{{Address: tosca.Address{1}}},
+		{
+			{Address: tosca.Address{1}, Topics: []tosca.Hash{{2}, {3}}, Data: tosca.Data{4, 5}},
+			{Address: tosca.Address{6}, Topics: []tosca.Hash{{7}}, Data: make(tosca.Data, 100)},
+		},
+	}
+
+	txTypes := []TransactionType{LegacyTxType, AccessListTxType, DynamicFeeTxType, BlobTxType}
+	for _, txType := range txTypes {
+		for _, success := range []bool{true, false} {
+			for i, logs := range logs {
+				t.Run(fmt.Sprintf("type=%d/success=%t/logs=%d", txType, success, i), func(t *testing.T) {
+					receipt := tosca.Receipt{Success: success, GasUsed: 21_000, Logs: logs}
+
+					reference := &types.Receipt{
+						Type:              byte(txType),
+						CumulativeGasUsed: 42_000,
+						Logs:              []*types.Log{},
+					}
+					if success {
+						reference.Status = types.ReceiptStatusSuccessful
+					}
+					for _, log := range logs {
+						topics := []common.Hash{}
+						for _, topic := range log.Topics {
+							topics = append(topics, common.Hash(topic))
+						}
+						reference.Logs = append(reference.Logs, &types.Log{
+							Address: common.Address(log.Address),
+							Topics:  topics,
+							Data:    log.Data,
+						})
+					}
+					reference.Bloom = types.CreateBloom(types.Receipts{reference})
+					want, err := reference.MarshalBinary()
+					if err != nil {
+						t.Fatalf("failed to encode reference receipt: %v", err)
+					}
+
+					if got := EncodeReceipt(txType, receipt, 42_000); !bytes.Equal(want, got) {
+						t.Errorf("unexpected encoding, wanted %x, got %x", want, got)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestGetLogsBloom_ContainsAddressesAndTopics(t *testing.T) {
+	logs := []tosca.Log{{Address: tosca.Address{1}, Topics: []tosca.Hash{{2}}}}
+	bloom := GetLogsBloom(logs)
+	reference := types.BytesToBloom(bloom[:])
+	if !reference.Test(common.Address{1}.Bytes()) {
+		t.Errorf("address missing in bloom filter")
+	}
+	if !reference.Test(common.Hash{2}.Bytes()) {
+		t.Errorf("topic missing in bloom filter")
+	}
+	if reference.Test(common.Hash{3}.Bytes()) {
+		t.Errorf("unexpected topic in bloom filter")
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package rlp provides the encoding and decoding of the Ethereum wire formats
+// of transactions and receipts based on Tosca types. It covers the recursive
+// length prefix (RLP) encoding as well as the typed envelopes of EIP-2718.
+//
+// Only canonical encodings are accepted by the decoder. Thus, any successfully
+// decoded input is re-encoded to the same byte sequence.
+package rlp
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+)
+
+const (
+	ErrUnexpectedEnd    = tosca.ConstError("rlp: unexpected end of input")
+	ErrNonCanonical     = tosca.ConstError("rlp: non-canonical encoding")
+	ErrExpectedString   = tosca.ConstError("rlp: expected string, got list")
+	ErrExpectedList     = tosca.ConstError("rlp: expected list, got string")
+	ErrValueTooLarge    = tosca.ConstError("rlp: value too large")
+	ErrInvalidSize      = tosca.ConstError("rlp: invalid size of fixed-size value")
+	ErrTrailingElements = tosca.ConstError("rlp: trailing elements in list")
+	ErrTrailingBytes    = tosca.ConstError("rlp: trailing bytes after value")
+)
+
+// --- Encoding ---
+
+// appendString appends the RLP encoding of the given byte string to dst.
+func appendString(dst []byte, data []byte) []byte {
+	if len(data) == 1 && data[0] < 0x80 {
+		return append(dst, data[0])
+	}
+	dst = appendHeader(dst, 0x80, len(data))
+	return append(dst, data...)
+}
+
+// appendList appends the RLP encoding of a list with the given, already
+// encoded, elements to dst.
+func appendList(dst []byte, elements []byte) []byte {
+	dst = appendHeader(dst, 0xc0, len(elements))
+	return append(dst, elements...)
+}
+
+func appendHeader(dst []byte, offset byte, size int) []byte {
+	if size < 56 {
+		return append(dst, offset+byte(size))
+	}
+	length := trimmedBigEndian(uint64(size))
+	dst = append(dst, offset+55+byte(len(length)))
+	return append(dst, length...)
+}
+
+func appendUint(dst []byte, value uint64) []byte {
+	return appendString(dst, trimmedBigEndian(value))
+}
+
+func appendValue(dst []byte, value tosca.Value) []byte {
+	i := 0
+	for i < len(value) && value[i] == 0 {
+		i++
+	}
+	return appendString(dst, value[i:])
+}
+
+func appendAddress(dst []byte, address *tosca.Address) []byte {
+	if address == nil {
+		return appendString(dst, nil)
+	}
+	return appendString(dst, address[:])
+}
+
+// trimmedBigEndian returns the big-endian representation of the given value
+// without leading zero bytes.
+func trimmedBigEndian(value uint64) []byte {
+	var buffer [8]byte
+	binary.BigEndian.PutUint64(buffer[:], value)
+	return buffer[bits.LeadingZeros64(value)/8:]
+}
+
+// --- Decoding ---
+
+// split decodes the header of the first RLP item in data. It returns whether
+// the item is a list, the payload of the item, and the bytes following the
+// item.
+func split(data []byte) (isList bool, payload []byte, rest []byte, err error) {
+	if len(data) == 0 {
+		return false, nil, nil, ErrUnexpectedEnd
+	}
+	var offset, size uint64
+	switch prefix := data[0]; {
+	case prefix < 0x80:
+		return false, data[:1], data[1:], nil
+	case prefix < 0xb8:
+		offset, size = 1, uint64(prefix-0x80)
+		if size == 1 && len(data) > 1 && data[1] < 0x80 {
+			return false, nil, nil, ErrNonCanonical
+		}
+	case prefix < 0xc0:
+		offset, size, err = readLongSize(data, prefix-0xb7)
+	case prefix < 0xf8:
+		isList = true
+		offset, size = 1, uint64(prefix-0xc0)
+	default:
+		isList = true
+		offset, size, err = readLongSize(data, prefix-0xf7)
+	}
+	if err != nil {
+		return false, nil, nil, err
+	}
+	if size > uint64(len(data))-offset {
+		return false, nil, nil, ErrUnexpectedEnd
+	}
+	end := offset + size
+	return isList, data[offset:end], data[end:], nil
+}
+
+func readLongSize(data []byte, lengthOfSize byte) (offset uint64, size uint64, err error) {
+	if uint64(len(data)) < 1+uint64(lengthOfSize) {
+		return 0, 0, ErrUnexpectedEnd
+	}
+	if data[1] == 0 {
+		return 0, 0, ErrNonCanonical
+	}
+	for _, cur := range data[1 : 1+lengthOfSize] {
+		if size>>56 != 0 {
+			return 0, 0, ErrValueTooLarge
+		}
+		size = size<<8 | uint64(cur)
+	}
+	if size < 56 {
+		return 0, 0, ErrNonCanonical
+	}
+	return 1 + uint64(lengthOfSize), size, nil
+}
+
+// listReader decodes the elements of an RLP list one after another. The first
+// error encountered is retained and all subsequent reads return zero values.
+type listReader struct {
+	rest []byte
+	err  error
+}
+
+// newListReader creates a reader for the elements of the list encoded in
+// data. The list is required to cover all of data.
+func newListReader(data []byte) *listReader {
+	isList, payload, rest, err := split(data)
+	if err == nil && !isList {
+		err = ErrExpectedList
+	}
+	if err == nil && len(rest) != 0 {
+		err = ErrTrailingBytes
+	}
+	return &listReader{rest: payload, err: err}
+}
+
+// raw returns the complete encoding of the next element.
+func (r *listReader) raw() []byte {
+	if r.err != nil {
+		return nil
+	}
+	_, _, rest, err := split(r.rest)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	res := r.rest[:len(r.rest)-len(rest)]
+	r.rest = rest
+	return res
+}
+
+func (r *listReader) bytes() []byte {
+	if r.err != nil {
+		return nil
+	}
+	isList, payload, rest, err := split(r.rest)
+	if err == nil && isList {
+		err = ErrExpectedString
+	}
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	r.rest = rest
+	return payload
+}
+
+func (r *listReader) list() *listReader {
+	if r.err != nil {
+		return &listReader{err: r.err}
+	}
+	isList, payload, rest, err := split(r.rest)
+	if err == nil && !isList {
+		err = ErrExpectedList
+	}
+	if err != nil {
+		r.err = err
+		return &listReader{err: err}
+	}
+	r.rest = rest
+	return &listReader{rest: payload}
+}
+
+// integer reads the next element as a big-endian integer of at most the given
+// number of bytes and returns it right-aligned in a 32-byte buffer.
+func (r *listReader) integer(maxSize int) (res [32]byte) {
+	data := r.bytes()
+	if r.err != nil {
+		return res
+	}
+	if len(data) > maxSize {
+		r.err = ErrValueTooLarge
+		return res
+	}
+	if len(data) > 0 && data[0] == 0 {
+		r.err = ErrNonCanonical
+		return res
+	}
+	copy(res[32-len(data):], data)
+	return res
+}
+
+func (r *listReader) uint64() uint64 {
+	res := r.integer(8)
+	return binary.BigEndian.Uint64(res[24:])
+}
+
+func (r *listReader) value() tosca.Value {
+	return tosca.Value(r.integer(32))
+}
+
+func (r *listReader) fixed(dst []byte) {
+	data := r.bytes()
+	if r.err != nil {
+		return
+	}
+	if len(data) != len(dst) {
+		r.err = ErrInvalidSize
+		return
+	}
+	copy(dst, data)
+}
+
+func (r *listReader) hash() (res tosca.Hash) {
+	r.fixed(res[:])
+	return res
+}
+
+// optionalAddress reads an address which may be encoded as an empty string
+// to indicate its absence.
+func (r *listReader) optionalAddress() *tosca.Address {
+	if r.err != nil {
+		return nil
+	}
+	isList, payload, _, err := split(r.rest)
+	if err == nil && !isList && len(payload) == 0 {
+		r.bytes()
+		return nil
+	}
+	res := new(tosca.Address)
+	r.fixed(res[:])
+	return res
+}
+
+func (r *listReader) hasMore() bool {
+	return r.err == nil && len(r.rest) > 0
+}
+
+// end finishes the reading of the list and reports the first error
+// encountered, if any.
+func (r *listReader) end() error {
+	if r.err == nil && len(r.rest) != 0 {
+		r.err = ErrTrailingElements
+	}
+	return r.err
+}
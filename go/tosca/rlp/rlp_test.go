@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+func TestEncoding_MatchesReferenceImplementation(t *testing.T) {
+	strings := [][]byte{
+		nil, {0}, {1}, {0x7f}, {0x80}, {0xff},
+		bytes.Repeat([]byte{1}, 55),
+		bytes.Repeat([]byte{2}, 56),
+		bytes.Repeat([]byte{3}, 1024),
+	}
+	for _, data := range strings {
+		want, err := rlp.EncodeToBytes(data)
+		if err != nil {
+			t.Fatalf("failed to encode reference: %v", err)
+		}
+		if got := appendString(nil, data); !bytes.Equal(want, got) {
+			t.Errorf("unexpected encoding of string of length %d, wanted %x, got %x", len(data), want, got)
+		}
+	}
+
+	for _, value := range []uint64{0, 1, 0x7f, 0x80, 0xff, 0x100, 1 << 63} {
+		want, err := rlp.EncodeToBytes(value)
+		if err != nil {
+			t.Fatalf("failed to encode reference: %v", err)
+		}
+		if got := appendUint(nil, value); !bytes.Equal(want, got) {
+			t.Errorf("unexpected encoding of %d, wanted %x, got %x", value, want, got)
+		}
+		if got := appendValue(nil, tosca.NewValue(value)); !bytes.Equal(want, got) {
+			t.Errorf("unexpected encoding of value %d, wanted %x, got %x", value, want, got)
+		}
+	}
+
+	for _, size := range []int{0, 1, 10, 100} {
+		elements := make([]uint64, size)
+		want, err := rlp.EncodeToBytes(elements)
+		if err != nil {
+			t.Fatalf("failed to encode reference: %v", err)
+		}
+		var content []byte
+		for _, element := range elements {
+			content = appendUint(content, element)
+		}
+		if got := appendList(nil, content); !bytes.Equal(want, got) {
+			t.Errorf("unexpected encoding of list of length %d, wanted %x, got %x", size, want, got)
+		}
+	}
+}
+
+func TestListReader_DecodesEncodedElements(t *testing.T) {
+	address := tosca.Address{1, 2, 3}
+	content := appendUint(nil, 42)
+	content = appendValue(content, tosca.NewValue(1, 2, 3, 4))
+	content = appendString(content, []byte("hello"))
+	content = appendAddress(content, &address)
+	content = appendAddress(content, nil)
+	content = appendList(content, appendUint(nil, 7))
+
+	r := newListReader(appendList(nil, content))
+	if want, got := uint64(42), r.uint64(); want != got {
+		t.Errorf("unexpected integer, wanted %d, got %d", want, got)
+	}
+	if want, got := tosca.NewValue(1, 2, 3, 4), r.value(); want != got {
+		t.Errorf("unexpected value, wanted %v, got %v", want, got)
+	}
+	if want, got := "hello", string(r.bytes()); want != got {
+		t.Errorf("unexpected string, wanted %s, got %s", want, got)
+	}
+	if got := r.optionalAddress(); got == nil || *got != address {
+		t.Errorf("unexpected address, wanted %v, got %v", address, got)
+	}
+	if got := r.optionalAddress(); got != nil {
+		t.Errorf("unexpected address, wanted nil, got %v", got)
+	}
+	inner := r.list()
+	if want, got := uint64(7), inner.uint64(); want != got {
+		t.Errorf("unexpected integer in nested list, wanted %d, got %d", want, got)
+	}
+	if err := inner.end(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := r.end(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestListReader_RejectsInvalidEncodings(t *testing.T) {
+	tests := map[string]struct {
+		input []byte
+		read  func(r *listReader)
+		want  error
+	}{
+		"empty input": {
+			input: []byte{},
+			read:  func(r *listReader) {},
+			want:  ErrUnexpectedEnd,
+		},
+		"string instead of list": {
+			input: []byte{0x80},
+			read:  func(r *listReader) {},
+			want:  ErrExpectedList,
+		},
+		"trailing bytes": {
+			input: []byte{0xc0, 0x01},
+			read:  func(r *listReader) {},
+			want:  ErrTrailingBytes,
+		},
+		"truncated list": {
+			input: []byte{0xc2, 0x01},
+			read:  func(r *listReader) {},
+			want:  ErrUnexpectedEnd,
+		},
+		"single byte with string header": {
+			input: []byte{0xc2, 0x81, 0x01},
+			read:  func(r *listReader) { r.bytes() },
+			want:  ErrNonCanonical,
+		},
+		"short string with long header": {
+			input: []byte{0xc3, 0xb8, 0x01, 0x80},
+			read:  func(r *listReader) { r.bytes() },
+			want:  ErrNonCanonical,
+		},
+		"integer with leading zero": {
+			input: []byte{0xc3, 0x82, 0x00, 0x01},
+			read:  func(r *listReader) { r.uint64() },
+			want:  ErrNonCanonical,
+		},
+		"integer too large": {
+			input: append([]byte{0xca, 0x89}, bytes.Repeat([]byte{1}, 9)...),
+			read:  func(r *listReader) { r.uint64() },
+			want:  ErrValueTooLarge,
+		},
+		"list instead of string": {
+			input: []byte{0xc1, 0xc0},
+			read:  func(r *listReader) { r.bytes() },
+			want:  ErrExpectedString,
+		},
+		"address of wrong size": {
+			input: []byte{0xc2, 0x81, 0x81},
+			read:  func(r *listReader) { r.optionalAddress() },
+			want:  ErrInvalidSize,
+		},
+		"trailing elements": {
+			input: []byte{0xc2, 0x01, 0x02},
+			read:  func(r *listReader) { r.uint64() },
+			want:  ErrTrailingElements,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newListReader(test.input)
+			test.read(r)
+			if err := r.end(); !errors.Is(err, test.want) {
+				t.Errorf("unexpected error, wanted %v, got %v", test.want, err)
+			}
+		})
+	}
+}
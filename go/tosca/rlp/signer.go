@@ -0,0 +1,130 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+const ErrInvalidChainId = tosca.ConstError("rlp: invalid chain ID")
+
+// Signer decodes raw transactions of a single chain and recovers their
+// senders. Transactions signed for other chains are rejected, while legacy
+// transactions without replay protection are accepted on any chain.
+//
+// Recovered senders are cached by transaction hash, such that transactions
+// seen before, for instance while validating them for a transaction pool, do
+// not need to be recovered again when being processed as part of a block.
+// Signers are safe for concurrent use.
+type Signer struct {
+	chainId tosca.Value
+	cache   *lru.Cache[tosca.Hash, tosca.Address]
+}
+
+// defaultSenderCacheSize is the number of senders retained by the cache
+// shared by all signers created through NewSigner.
+const defaultSenderCacheSize = 1 << 16
+
+// sharedSenderCache is the cache shared by all signers created through
+// NewSigner. Since a transaction hash covers the chain ID of the transaction
+// and the chain ID is re-checked for each transaction, sharing the cache among
+// signers of different chains is safe.
+var sharedSenderCache = func() *lru.Cache[tosca.Hash, tosca.Address] {
+	cache, err := lru.New[tosca.Hash, tosca.Address](defaultSenderCacheSize)
+	if err != nil {
+		panic(fmt.Sprintf("failed to create sender cache: %v", err))
+	}
+	return cache
+}()
+
+// NewSigner creates a signer for the given chain using a process-wide cache
+// of recovered senders.
+func NewSigner(chainId tosca.Value) *Signer {
+	return &Signer{chainId: chainId, cache: sharedSenderCache}
+}
+
+// NewSignerWithCacheSize creates a signer for the given chain using a cache
+// of the given size dedicated to this signer. If the size is not positive,
+// no senders are cached.
+func NewSignerWithCacheSize(chainId tosca.Value, cacheSize int) (*Signer, error) {
+	res := &Signer{chainId: chainId}
+	if cacheSize > 0 {
+		cache, err := lru.New[tosca.Hash, tosca.Address](cacheSize)
+		if err != nil {
+			return nil, err
+		}
+		res.cache = cache
+	}
+	return res, nil
+}
+
+// ChainId returns the ID of the chain transactions are accepted for.
+func (s *Signer) ChainId() tosca.Value {
+	return s.chainId
+}
+
+// Decode decodes the given raw transaction, checks that it is signed for the
+// signer's chain, and recovers its sender.
+func (s *Signer) Decode(raw []byte) (SignedTransaction, error) {
+	tx, signingHash, err := decodeTransaction(raw)
+	if err != nil {
+		return SignedTransaction{}, err
+	}
+	if isReplayProtected(&tx) && tx.ChainId != s.chainId {
+		return SignedTransaction{}, fmt.Errorf("%w: wanted %v, got %v", ErrInvalidChainId, s.chainId, tx.ChainId)
+	}
+
+	if s.cache != nil {
+		if sender, found := s.cache.Get(tx.Hash); found {
+			tx.Sender = sender
+			return tx, nil
+		}
+	}
+	sender, err := recoverSender(signingHash, &tx)
+	if err != nil {
+		return SignedTransaction{}, err
+	}
+	tx.Sender = sender
+	if s.cache != nil {
+		s.cache.Add(tx.Hash, sender)
+	}
+	return tx, nil
+}
+
+// RecoverSender recovers the sender of the given raw transaction.
+func (s *Signer) RecoverSender(raw []byte) (tosca.Address, error) {
+	tx, err := s.Decode(raw)
+	if err != nil {
+		return tosca.Address{}, err
+	}
+	return tx.Sender, nil
+}
+
+// RecoverSender recovers the sender of the given raw transaction after
+// checking that it is signed for the given chain. Recovered senders are
+// cached in a process-wide cache.
+func RecoverSender(raw []byte, chainId tosca.Value) (tosca.Address, error) {
+	return NewSigner(chainId).RecoverSender(raw)
+}
+
+// isReplayProtected returns true if the signature of the given transaction
+// covers a chain ID, which is the case for all but legacy transactions signed
+// according to the Homestead rules.
+func isReplayProtected(tx *SignedTransaction) bool {
+	if tx.Type != LegacyTxType {
+		return true
+	}
+	v := tx.V.ToUint256()
+	return !(v.IsUint64() && (v.Uint64() == 27 || v.Uint64() == 28))
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func signAndEncode(t *testing.T, signer types.Signer, data types.TxData) ([]byte, tosca.Address) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	tx, err := types.SignNewTx(key, signer, data)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	return encoded, tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+}
+
+func TestRecoverSender_RecoversSendersOfAllTransactionTypes(t *testing.T) {
+	signer := types.NewCancunSigner(big.NewInt(250))
+	for name, data := range getTestTransactions() {
+		t.Run(name, func(t *testing.T) {
+			encoded, want := signAndEncode(t, signer, data)
+			got, err := RecoverSender(encoded, tosca.NewValue(250))
+			if err != nil {
+				t.Fatalf("failed to recover sender: %v", err)
+			}
+			if want != got {
+				t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestRecoverSender_RejectsTransactionsOfOtherChains(t *testing.T) {
+	signer := types.NewCancunSigner(big.NewInt(250))
+	for name, data := range getTestTransactions() {
+		t.Run(name, func(t *testing.T) {
+			encoded, _ := signAndEncode(t, signer, data)
+			_, err := RecoverSender(encoded, tosca.NewValue(1))
+			if !errors.Is(err, ErrInvalidChainId) {
+				t.Errorf("unexpected error, wanted %v, got %v", ErrInvalidChainId, err)
+			}
+		})
+	}
+}
+
+func TestRecoverSender_AcceptsUnprotectedLegacyTransactionsOnAnyChain(t *testing.T) {
+	encoded, want := signAndEncode(t, types.HomesteadSigner{}, &types.LegacyTx{
+		GasPrice: big.NewInt(1),
+		Gas:      21_000,
+	})
+	for _, chainId := range []uint64{0, 1, 250} {
+		got, err := RecoverSender(encoded, tosca.NewValue(chainId))
+		if err != nil {
+			t.Fatalf("failed to recover sender on chain %d: %v", chainId, err)
+		}
+		if want != got {
+			t.Errorf("unexpected sender on chain %d, wanted %v, got %v", chainId, want, got)
+		}
+	}
+}
+
+func TestSigner_RecoveredSendersAreCached(t *testing.T) {
+	encoded, want := signAndEncode(t, types.NewCancunSigner(big.NewInt(250)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(250),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21_000,
+	})
+	signer, err := NewSignerWithCacheSize(tosca.NewValue(250), 10)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	tx, err := signer.Decode(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if want != tx.Sender {
+		t.Fatalf("unexpected sender, wanted %v, got %v", want, tx.Sender)
+	}
+	if cached, found := signer.cache.Get(tx.Hash); !found || cached != want {
+		t.Fatalf("sender was not cached, got %v, found %t", cached, found)
+	}
+
+	// A manipulated cache entry reveals that the recovery is skipped.
+	signer.cache.Add(tx.Hash, tosca.Address{42})
+	got, err := signer.RecoverSender(encoded)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if want, got := (tosca.Address{42}), got; want != got {
+		t.Errorf("sender was not fetched from cache, wanted %v, got %v", want, got)
+	}
+}
+
+func TestSigner_CacheCanBeDisabled(t *testing.T) {
+	signer, err := NewSignerWithCacheSize(tosca.NewValue(250), 0)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	if signer.cache != nil {
+		t.Errorf("signer without cache has a cache")
+	}
+	encoded, want := signAndEncode(t, types.HomesteadSigner{}, &types.LegacyTx{Gas: 21_000})
+	got, err := signer.RecoverSender(encoded)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if want != got {
+		t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"fmt"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransactionType enumerates the envelope types of EIP-2718.
+type TransactionType byte
+
+const (
+	LegacyTxType     TransactionType = 0x00
+	AccessListTxType TransactionType = 0x01 // EIP-2930
+	DynamicFeeTxType TransactionType = 0x02 // EIP-1559
+	BlobTxType       TransactionType = 0x03 // EIP-4844
+	SetCodeTxType    TransactionType = 0x04 // EIP-7702
+)
+
+const (
+	ErrEmptyTransaction       = tosca.ConstError("rlp: empty transaction")
+	ErrUnsupportedType        = tosca.ConstError("rlp: unsupported transaction type")
+	ErrInvalidSignature       = tosca.ConstError("rlp: invalid transaction signature")
+	ErrMissingBlobRecipient   = tosca.ConstError("rlp: blob transaction without recipient")
+	ErrMissingSetCodeTarget   = tosca.ConstError("rlp: set-code transaction without recipient")
+	ErrEmptyAuthorizationList = tosca.ConstError("rlp: set-code transaction without authorizations")
+	ErrUnexpectedTypedEncoded = tosca.ConstError("rlp: typed transaction encoded as list")
+)
+
+// SignedTransaction is the decoded form of a signed transaction as it is
+// transmitted on the wire. Fields not used by the type of the transaction
+// are left at their zero value.
+type SignedTransaction struct {
+	Type    TransactionType
+	ChainId tosca.Value // zero for legacy transactions without replay protection
+	Nonce   uint64
+
+	GasPrice  tosca.Value // for legacy and access list transactions
+	GasTipCap tosca.Value // for dynamic fee and blob transactions
+	GasFeeCap tosca.Value // for dynamic fee and blob transactions
+	GasLimit  tosca.Gas
+
+	Recipient  *tosca.Address // nil for contract creations
+	Value      tosca.Value
+	Input      tosca.Data
+	AccessList []tosca.AccessTuple
+
+	BlobGasFeeCap tosca.Value // for blob transactions
+	BlobHashes    []tosca.Hash
+
+	AuthorizationList []Authorization // for set-code transactions
+
+	// V, R, and S are the signature values. For typed transactions, V is the
+	// y-parity of the signature, for legacy transactions the raw V value.
+	V, R, S tosca.Value
+
+	// Sender is the account recovered from the signature.
+	Sender tosca.Address
+	// Hash is the Keccak256 hash of the canonical encoding.
+	Hash tosca.Hash
+}
+
+// Authorization is a signed permission of an account to install a delegation
+// to the code of the given address, as introduced by EIP-7702. Invalid
+// authorizations do not invalidate the including transaction but are skipped
+// during its execution. Thus, the signer is not recovered while decoding.
+type Authorization struct {
+	ChainId tosca.Value // zero if valid on any chain
+	Address tosca.Address
+	Nonce   uint64
+	V, R, S tosca.Value // V is the y-parity of the signature
+}
+
+// RecoverAuthority recovers the account that signed the authorization.
+func (a *Authorization) RecoverAuthority() (tosca.Address, error) {
+	if a.V.Cmp(tosca.NewValue(1)) > 0 {
+		return tosca.Address{}, ErrInvalidSignature
+	}
+	return recoverSigner(a.SigningHash(), a.V[31], a.R, a.S)
+}
+
+// SigningHash returns the hash signed by the authority of the authorization.
+func (a *Authorization) SigningHash() tosca.Hash {
+	fields := appendValue(nil, a.ChainId)
+	fields = appendString(fields, a.Address[:])
+	fields = appendUint(fields, a.Nonce)
+	return tosca.Hash(crypto.Keccak256([]byte{authorizationMagic}, appendList(nil, fields)))
+}
+
+// authorizationMagic is the domain separator of the authorization signatures.
+const authorizationMagic = 0x05
+
+// GetEffectiveGasPrice computes the price per unit of gas paid by the
+// transaction in a block with the given base fee. For transactions with fee
+// caps this is the base fee plus the tip, limited by the fee cap.
+func (t *SignedTransaction) GetEffectiveGasPrice(baseFee tosca.Value) tosca.Value {
+	if t.Type == LegacyTxType || t.Type == AccessListTxType {
+		return t.GasPrice
+	}
+	price := tosca.SaturatingAdd(baseFee, t.GasTipCap)
+	if price.Cmp(t.GasFeeCap) > 0 {
+		return t.GasFeeCap
+	}
+	return price
+}
+
+// ToTransaction converts the signed transaction into the transaction format
+// consumed by Tosca processors, using the given base fee to determine the
+// effective gas price.
+func (t *SignedTransaction) ToTransaction(baseFee tosca.Value) tosca.Transaction {
+	return tosca.Transaction{
+		Sender:     t.Sender,
+		Recipient:  t.Recipient,
+		Nonce:      t.Nonce,
+		Input:      t.Input,
+		Value:      t.Value,
+		GasLimit:   t.GasLimit,
+		GasPrice:   t.GetEffectiveGasPrice(baseFee),
+		AccessList: t.AccessList,
+	}
+}
+
+// DecodeTransaction decodes a signed transaction in its canonical binary
+// representation, as produced for instance by go-ethereum's MarshalBinary,
+// and recovers its sender. Legacy transactions are plain RLP lists while all
+// other types are prefixed by their type byte. The chain ID of the transaction
+// is not checked, use a Signer to restrict transactions to a given chain.
+func DecodeTransaction(data []byte) (SignedTransaction, error) {
+	res, signingHash, err := decodeTransaction(data)
+	if err != nil {
+		return SignedTransaction{}, err
+	}
+	sender, err := recoverSender(signingHash, &res)
+	if err != nil {
+		return SignedTransaction{}, err
+	}
+	res.Sender = sender
+	return res, nil
+}
+
+// decodeTransaction decodes the given transaction without recovering its
+// sender. Besides the transaction, the hash signed by the sender is returned.
+func decodeTransaction(data []byte) (SignedTransaction, tosca.Hash, error) {
+	if len(data) == 0 {
+		return SignedTransaction{}, tosca.Hash{}, ErrEmptyTransaction
+	}
+	var res SignedTransaction
+	var signingHash tosca.Hash
+	var err error
+	if data[0] >= 0xc0 {
+		res.Type = LegacyTxType
+		signingHash, err = decodeLegacy(data, &res)
+	} else {
+		res.Type = TransactionType(data[0])
+		signingHash, err = decodeTyped(data[1:], &res)
+	}
+	if err != nil {
+		return SignedTransaction{}, tosca.Hash{}, err
+	}
+	res.Hash = tosca.Hash(crypto.Keccak256(data))
+	return res, signingHash, nil
+}
+
+func decodeLegacy(data []byte, tx *SignedTransaction) (tosca.Hash, error) {
+	r := newListReader(data)
+	start := r.rest
+	tx.Nonce = r.uint64()
+	tx.GasPrice = r.value()
+	tx.GasLimit = readGas(r)
+	tx.Recipient = r.optionalAddress()
+	tx.Value = r.value()
+	tx.Input = tosca.Data(r.bytes())
+	fields := append([]byte{}, start[:len(start)-len(r.rest)]...)
+	tx.V = r.value()
+	tx.R = r.value()
+	tx.S = r.value()
+	if err := r.end(); err != nil {
+		return tosca.Hash{}, err
+	}
+
+	// Replay protected transactions according to EIP-155 encode the chain ID
+	// in V and include it in the signed payload.
+	v := tx.V.ToUint256()
+	if v.IsUint64() && (v.Uint64() == 27 || v.Uint64() == 28) {
+		return hashList(fields), nil
+	}
+	if v.LtUint64(35) {
+		return tosca.Hash{}, ErrInvalidSignature
+	}
+	chainId := v.SubUint64(v, 35)
+	chainId.Rsh(chainId, 1)
+	tx.ChainId = tosca.ValueFromUint256(chainId)
+	fields = appendValue(fields, tx.ChainId)
+	fields = appendString(fields, nil)
+	fields = appendString(fields, nil)
+	return hashList(fields), nil
+}
+
+func decodeTyped(data []byte, tx *SignedTransaction) (tosca.Hash, error) {
+	if tx.Type == LegacyTxType {
+		return tosca.Hash{}, ErrUnexpectedTypedEncoded
+	}
+	if tx.Type > SetCodeTxType {
+		return tosca.Hash{}, fmt.Errorf("%w: %d", ErrUnsupportedType, tx.Type)
+	}
+
+	r := newListReader(data)
+	start := r.rest
+	tx.ChainId = r.value()
+	tx.Nonce = r.uint64()
+	if tx.Type == AccessListTxType {
+		tx.GasPrice = r.value()
+	} else {
+		tx.GasTipCap = r.value()
+		tx.GasFeeCap = r.value()
+	}
+	tx.GasLimit = readGas(r)
+	tx.Recipient = r.optionalAddress()
+	tx.Value = r.value()
+	tx.Input = tosca.Data(r.bytes())
+	tx.AccessList = readAccessList(r)
+	if tx.Type == BlobTxType {
+		tx.BlobGasFeeCap = r.value()
+		tx.BlobHashes = readHashes(r)
+		if r.err == nil && tx.Recipient == nil {
+			return tosca.Hash{}, ErrMissingBlobRecipient
+		}
+	}
+	if tx.Type == SetCodeTxType {
+		tx.AuthorizationList = readAuthorizationList(r)
+		if r.err == nil && tx.Recipient == nil {
+			return tosca.Hash{}, ErrMissingSetCodeTarget
+		}
+		if r.err == nil && len(tx.AuthorizationList) == 0 {
+			return tosca.Hash{}, ErrEmptyAuthorizationList
+		}
+	}
+	signed := start[:len(start)-len(r.rest)]
+	tx.V = r.value()
+	tx.R = r.value()
+	tx.S = r.value()
+	if err := r.end(); err != nil {
+		return tosca.Hash{}, err
+	}
+	if tx.V.Cmp(tosca.NewValue(1)) > 0 {
+		return tosca.Hash{}, ErrInvalidSignature
+	}
+
+	return tosca.Hash(crypto.Keccak256([]byte{byte(tx.Type)}, appendList(nil, signed))), nil
+}
+
+func readGas(r *listReader) tosca.Gas {
+	gas := r.uint64()
+	if r.err == nil && gas > uint64(1<<63-1) {
+		r.err = ErrValueTooLarge
+	}
+	return tosca.Gas(gas)
+}
+
+func readAccessList(r *listReader) []tosca.AccessTuple {
+	list := r.list()
+	res := []tosca.AccessTuple{}
+	for list.hasMore() {
+		tuple := list.list()
+		entry := tosca.AccessTuple{Keys: []tosca.Key{}}
+		tuple.fixed(entry.Address[:])
+		keys := tuple.list()
+		for keys.hasMore() {
+			entry.Keys = append(entry.Keys, tosca.Key(keys.hash()))
+		}
+		if err := keys.end(); err != nil && tuple.err == nil {
+			tuple.err = err
+		}
+		if err := tuple.end(); err != nil && list.err == nil {
+			list.err = err
+		}
+		res = append(res, entry)
+	}
+	if err := list.end(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return res
+}
+
+func readHashes(r *listReader) []tosca.Hash {
+	list := r.list()
+	res := []tosca.Hash{}
+	for list.hasMore() {
+		res = append(res, list.hash())
+	}
+	if err := list.end(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return res
+}
+
+func readAuthorizationList(r *listReader) []Authorization {
+	list := r.list()
+	res := []Authorization{}
+	for list.hasMore() {
+		entry := list.list()
+		authorization := Authorization{}
+		authorization.ChainId = entry.value()
+		entry.fixed(authorization.Address[:])
+		authorization.Nonce = entry.uint64()
+		authorization.V = entry.value()
+		authorization.R = entry.value()
+		authorization.S = entry.value()
+		if err := entry.end(); err != nil && list.err == nil {
+			list.err = err
+		}
+		res = append(res, authorization)
+	}
+	if err := list.end(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return res
+}
+
+func hashList(elements []byte) tosca.Hash {
+	return tosca.Hash(crypto.Keccak256(appendList(nil, elements)))
+}
+
+// recoverSender recovers the address of the account that signed the given
+// hash with the signature included in the transaction.
+func recoverSender(hash tosca.Hash, tx *SignedTransaction) (tosca.Address, error) {
+	var recoveryId byte
+	if tx.Type == LegacyTxType {
+		v := tx.V.ToUint256()
+		if v.Uint64() == 27 || v.Uint64() == 28 {
+			recoveryId = byte(v.Uint64() - 27)
+		} else {
+			recoveryId = byte(v.Uint64()-35) & 1
+		}
+	} else {
+		recoveryId = tx.V[31]
+	}
+	return recoverSigner(hash, recoveryId, tx.R, tx.S)
+}
+
+// recoverSigner recovers the address of the account that produced the given
+// signature for the given hash.
+func recoverSigner(hash tosca.Hash, recoveryId byte, r, s tosca.Value) (tosca.Address, error) {
+	// Signatures with high S values are rejected since Homestead (EIP-2).
+	if !crypto.ValidateSignatureValues(recoveryId, r.ToUint256().ToBig(), s.ToUint256().ToBig(), true) {
+		return tosca.Address{}, ErrInvalidSignature
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature[0:32], r[:])
+	copy(signature[32:64], s[:])
+	signature[64] = recoveryId
+	key, err := crypto.Ecrecover(hash[:], signature)
+	if err != nil {
+		return tosca.Address{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return tosca.Address(crypto.Keccak256(key[1:])[12:]), nil
+}
@@ -0,0 +1,357 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package rlp
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+func getTestTransactions() map[string]types.TxData {
+	recipient := common.Address{1, 2, 3}
+	accessList := types.AccessList{
+		{Address: common.Address{4}, StorageKeys: []common.Hash{{5}, {6}}},
+		{Address: common.Address{7}, StorageKeys: []common.Hash{}},
+	}
+	return map[string]types.TxData{
+		"legacy": &types.LegacyTx{
+			Nonce:    1,
+			GasPrice: big.NewInt(100),
+			Gas:      21_000,
+			To:       &recipient,
+			Value:    big.NewInt(1000),
+			Data:     []byte{1, 2, 3},
+		},
+		"legacy creation": &types.LegacyTx{
+			Nonce:    2,
+			GasPrice: big.NewInt(100),
+			Gas:      100_000,
+			Data:     make([]byte, 100),
+		},
+		"access list": &types.AccessListTx{
+			ChainID:    big.NewInt(250),
+			Nonce:      3,
+			GasPrice:   big.NewInt(200),
+			Gas:        50_000,
+			To:         &recipient,
+			Value:      big.NewInt(1),
+			AccessList: accessList,
+		},
+		"dynamic fee": &types.DynamicFeeTx{
+			ChainID:    big.NewInt(250),
+			Nonce:      4,
+			GasTipCap:  big.NewInt(2),
+			GasFeeCap:  big.NewInt(300),
+			Gas:        60_000,
+			To:         &recipient,
+			Value:      big.NewInt(12),
+			Data:       []byte("input"),
+			AccessList: accessList,
+		},
+		"blob": &types.BlobTx{
+			ChainID:    uint256.NewInt(250),
+			Nonce:      5,
+			GasTipCap:  uint256.NewInt(3),
+			GasFeeCap:  uint256.NewInt(400),
+			Gas:        70_000,
+			To:         recipient,
+			Value:      uint256.NewInt(13),
+			AccessList: accessList,
+			BlobFeeCap: uint256.NewInt(7),
+			BlobHashes: []common.Hash{{8}, {9}},
+		},
+	}
+}
+
+func TestDecodeTransaction_DecodesSignedTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	sender := tosca.Address(crypto.PubkeyToAddress(key.PublicKey))
+
+	signers := map[string]types.Signer{
+		"unprotected": types.HomesteadSigner{},
+		"protected":   types.NewCancunSigner(big.NewInt(250)),
+	}
+
+	for name, data := range getTestTransactions() {
+		for signerName, signer := range signers {
+			tx := types.NewTx(data)
+			if _, unprotected := signer.(types.HomesteadSigner); unprotected && tx.Type() != types.LegacyTxType {
+				continue
+			}
+			t.Run(name+"/"+signerName, func(t *testing.T) {
+				tx, err := types.SignTx(tx, signer, key)
+				if err != nil {
+					t.Fatalf("failed to sign transaction: %v", err)
+				}
+				encoded, err := tx.MarshalBinary()
+				if err != nil {
+					t.Fatalf("failed to encode transaction: %v", err)
+				}
+
+				got, err := DecodeTransaction(encoded)
+				if err != nil {
+					t.Fatalf("failed to decode transaction: %v", err)
+				}
+
+				if want, got := TransactionType(tx.Type()), got.Type; want != got {
+					t.Errorf("unexpected type, wanted %v, got %v", want, got)
+				}
+				if want, got := sender, got.Sender; want != got {
+					t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+				}
+				if want, got := tosca.Hash(tx.Hash()), got.Hash; want != got {
+					t.Errorf("unexpected hash, wanted %v, got %v", want, got)
+				}
+				if want, got := tosca.ValueFromUint256(uint256.MustFromBig(tx.ChainId())), got.ChainId; want != got {
+					t.Errorf("unexpected chain ID, wanted %v, got %v", want, got)
+				}
+				if want, got := tx.Nonce(), got.Nonce; want != got {
+					t.Errorf("unexpected nonce, wanted %d, got %d", want, got)
+				}
+				if want, got := tosca.Gas(tx.Gas()), got.GasLimit; want != got {
+					t.Errorf("unexpected gas limit, wanted %d, got %d", want, got)
+				}
+				if want, got := (*tosca.Address)(tx.To()), got.Recipient; !reflect.DeepEqual(want, got) {
+					t.Errorf("unexpected recipient, wanted %v, got %v", want, got)
+				}
+				if want, got := tosca.ValueFromUint256(uint256.MustFromBig(tx.Value())), got.Value; want != got {
+					t.Errorf("unexpected value, wanted %v, got %v", want, got)
+				}
+				if want, got := tx.Data(), got.Input; string(want) != string(got) {
+					t.Errorf("unexpected input, wanted %x, got %x", want, got)
+				}
+				if want, got := len(tx.AccessList()), len(got.AccessList); want != got {
+					t.Errorf("unexpected access list length, wanted %d, got %d", want, got)
+				}
+				if want, got := len(tx.BlobHashes()), len(got.BlobHashes); want != got {
+					t.Errorf("unexpected number of blob hashes, wanted %d, got %d", want, got)
+				}
+
+				baseFee := big.NewInt(50)
+				wantPrice, err := tx.EffectiveGasTip(baseFee)
+				if err != nil {
+					t.Fatalf("failed to compute effective tip: %v", err)
+				}
+				wantPrice.Add(wantPrice, baseFee)
+				if want, got := tosca.ValueFromUint256(uint256.MustFromBig(wantPrice)), got.GetEffectiveGasPrice(tosca.NewValue(50)); want != got {
+					t.Errorf("unexpected effective gas price, wanted %v, got %v", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeTransaction_ConvertsToToscaTransaction(t *testing.T) {
+	recipient := tosca.Address{1}
+	signed := SignedTransaction{
+		Type:       DynamicFeeTxType,
+		Nonce:      12,
+		GasTipCap:  tosca.NewValue(2),
+		GasFeeCap:  tosca.NewValue(10),
+		GasLimit:   21_000,
+		Recipient:  &recipient,
+		Value:      tosca.NewValue(5),
+		Input:      tosca.Data{1, 2},
+		AccessList: []tosca.AccessTuple{{Address: tosca.Address{2}}},
+		Sender:     tosca.Address{3},
+	}
+
+	want := tosca.Transaction{
+		Sender:     signed.Sender,
+		Recipient:  &recipient,
+		Nonce:      12,
+		Input:      tosca.Data{1, 2},
+		Value:      tosca.NewValue(5),
+		GasLimit:   21_000,
+		GasPrice:   tosca.NewValue(7),
+		AccessList: signed.AccessList,
+	}
+	if got := signed.ToTransaction(tosca.NewValue(5)); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected transaction, wanted %v, got %v", want, got)
+	}
+
+	want.GasPrice = tosca.NewValue(10)
+	if got := signed.ToTransaction(tosca.NewValue(9)); !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected transaction with capped gas price, wanted %v, got %v", want, got)
+	}
+}
+
+func TestDecodeTransaction_RejectsInvalidTransactions(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer := types.NewCancunSigner(big.NewInt(250))
+	tx, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+		ChainID:   big.NewInt(250),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(1),
+		Gas:       21_000,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	valid, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+
+	_, r, _ := tx.RawSignatureValues()
+	highS := new(big.Int).Sub(crypto.S256().Params().N, big.NewInt(1))
+	highSTx, err := tx.WithSignature(signer, append(append(common.LeftPadBytes(r.Bytes(), 32), common.LeftPadBytes(highS.Bytes(), 32)...), 0))
+	if err != nil {
+		t.Fatalf("failed to replace signature: %v", err)
+	}
+	highSEncoded, err := highSTx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+
+	tests := map[string]struct {
+		input []byte
+		want  error
+	}{
+		"empty":            {nil, ErrEmptyTransaction},
+		"unsupported type": {append([]byte{0x05}, valid[1:]...), ErrUnsupportedType},
+		"typed legacy":     {append([]byte{0x00}, valid[1:]...), ErrUnexpectedTypedEncoded},
+		"truncated":        {valid[:len(valid)-1], ErrUnexpectedEnd},
+		"trailing bytes":   {append(valid, 0), ErrTrailingBytes},
+		"high s value":     {highSEncoded, ErrInvalidSignature},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeTransaction(test.input); !errors.Is(err, test.want) {
+				t.Errorf("unexpected error, wanted %v, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestDecodeTransaction_AlteredSignatureYieldsDifferentSender(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	legacy := &types.LegacyTx{
+		GasPrice: big.NewInt(1),
+		Gas:      21_000,
+	}
+	tx, err := types.SignNewTx(key, types.HomesteadSigner{}, legacy)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	encoded, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	original, err := DecodeTransaction(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+
+	// Flip the recovery ID from 27 to 28 or vice versa.
+	v, r, s := tx.RawSignatureValues()
+	legacy.V = new(big.Int).Xor(v, big.NewInt(27^28))
+	legacy.R, legacy.S = r, s
+	encoded, err = types.NewTx(legacy).MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+
+	altered, err := DecodeTransaction(encoded)
+	if err == nil && altered.Sender == original.Sender {
+		t.Errorf("altered signature recovered the original sender")
+	}
+}
+
+func TestDecodeTransaction_DecodesSetCodeTransactions(t *testing.T) {
+	// The go-ethereum version in use does not support set-code transactions,
+	// so they are encoded and signed manually.
+	senderKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	authorityKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	sign := func(hash []byte, key *ecdsa.PrivateKey) []byte {
+		signature, err := crypto.Sign(hash, key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		res := appendUint(nil, uint64(signature[64]))
+		res = appendString(res, bytes.TrimLeft(signature[0:32], "\x00"))
+		return appendString(res, bytes.TrimLeft(signature[32:64], "\x00"))
+	}
+
+	delegate := tosca.Address{0xde}
+	authorization := appendValue(nil, tosca.NewValue(250))
+	authorization = appendString(authorization, delegate[:])
+	authorization = appendUint(authorization, 7)
+	authorizationHash := crypto.Keccak256([]byte{authorizationMagic}, appendList(nil, authorization))
+	authorization = append(authorization, sign(authorizationHash, authorityKey)...)
+
+	recipient := tosca.Address{1}
+	fields := appendValue(nil, tosca.NewValue(250))
+	fields = appendUint(fields, 3)
+	fields = appendValue(fields, tosca.NewValue(1))
+	fields = appendValue(fields, tosca.NewValue(100))
+	fields = appendUint(fields, 50_000)
+	fields = appendAddress(fields, &recipient)
+	fields = appendValue(fields, tosca.NewValue(0))
+	fields = appendString(fields, nil)
+	fields = appendList(fields, nil)
+	fields = appendList(fields, appendList(nil, authorization))
+	signingHash := crypto.Keccak256([]byte{byte(SetCodeTxType)}, appendList(nil, fields))
+	fields = append(fields, sign(signingHash, senderKey)...)
+	encoded := appendList([]byte{byte(SetCodeTxType)}, fields)
+
+	tx, err := DecodeTransaction(encoded)
+	if err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if want, got := SetCodeTxType, tx.Type; want != got {
+		t.Errorf("unexpected type, wanted %v, got %v", want, got)
+	}
+	if want, got := tosca.Address(crypto.PubkeyToAddress(senderKey.PublicKey)), tx.Sender; want != got {
+		t.Errorf("unexpected sender, wanted %v, got %v", want, got)
+	}
+	if want, got := 1, len(tx.AuthorizationList); want != got {
+		t.Fatalf("unexpected number of authorizations, wanted %d, got %d", want, got)
+	}
+	auth := tx.AuthorizationList[0]
+	if auth.ChainId != tosca.NewValue(250) || auth.Address != delegate || auth.Nonce != 7 {
+		t.Errorf("unexpected authorization %v", auth)
+	}
+	authority, err := auth.RecoverAuthority()
+	if err != nil {
+		t.Fatalf("failed to recover authority: %v", err)
+	}
+	if want, got := tosca.Address(crypto.PubkeyToAddress(authorityKey.PublicKey)), authority; want != got {
+		t.Errorf("unexpected authority, wanted %v, got %v", want, got)
+	}
+}
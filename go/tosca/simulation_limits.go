@@ -0,0 +1,133 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"fmt"
+)
+
+// SimulationLimits bounds the resources a simulated transaction may use
+// beyond its gas limit, as required by public simulation endpoints. A zero
+// value disables the respective limit. Once a limit is exceeded, the
+// simulation is aborted and the exceeded limit is reported through the
+// LimitExceeded field of the SimulationResult.
+type SimulationLimits struct {
+	// MaxCallDepth is the maximum depth of nested calls and creates, where
+	// the top-level call of a transaction has depth 0. It is only effective
+	// below the protocol's limit of 1024.
+	MaxCallDepth int
+	// MaxCreatedContracts is the maximum number of contract creations,
+	// including the creation performed by a contract creating transaction.
+	MaxCreatedContracts int
+	// MaxLogs is the maximum number of emitted logs, including logs that
+	// got reverted later on.
+	MaxLogs int
+	// MaxStateWrites is the maximum number of storage updates and balance
+	// changes, including the balance changes of the fee handling.
+	MaxStateWrites int
+}
+
+// Names of the limits reported by ErrSimulationLimitExceeded.
+const (
+	LimitCallDepth        = "call depth"
+	LimitCreatedContracts = "created contracts"
+	LimitLogs             = "logs"
+	LimitStateWrites      = "state writes"
+)
+
+// ErrSimulationLimitExceeded describes a limit of SimulationLimits exceeded
+// by a simulated transaction.
+type ErrSimulationLimitExceeded struct {
+	Limit string // one of the Limit* constants
+	Max   int    // the configured maximum
+}
+
+func (e *ErrSimulationLimitExceeded) Error() string {
+	return fmt.Sprintf("simulation limit exceeded: %s exceeds %d", e.Limit, e.Max)
+}
+
+// SimulateWithinLimits runs the given simulation function such that the
+// limits configured in the options are enforced. The limits are observed
+// through a tracer wrapping the tracer of the options, if any, and enforced
+// by cancelling the context passed to the simulation function. It is intended
+// to be used by processors implementing the SimulatingProcessor interface.
+func SimulateWithinLimits(
+	ctx context.Context,
+	options SimulationOptions,
+	simulate func(context.Context, SimulationOptions) (SimulationResult, error),
+) (SimulationResult, error) {
+	if options.Limits == (SimulationLimits{}) {
+		return simulate(ctx, options)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	inner := options.Tracer
+	if inner == nil {
+		inner = NoOpTracer{}
+	}
+	tracer := &limitingTracer{Tracer: inner, limits: options.Limits, cancel: cancel}
+	options.Tracer = tracer
+
+	result, err := simulate(ctx, options)
+	if tracer.exceeded != nil {
+		return SimulationResult{Aborted: true, LimitExceeded: tracer.exceeded}, nil
+	}
+	return result, err
+}
+
+// limitingTracer is a Tracer counting the resources used by a transaction,
+// cancelling the execution once a limit is exceeded. All events are
+// forwarded to the wrapped tracer.
+type limitingTracer struct {
+	Tracer
+	limits   SimulationLimits
+	cancel   context.CancelFunc
+	exceeded *ErrSimulationLimitExceeded
+	creates  int
+	logs     int
+	writes   int
+}
+
+func (t *limitingTracer) check(limit string, value, maximum int) {
+	if maximum > 0 && value > maximum && t.exceeded == nil {
+		t.exceeded = &ErrSimulationLimitExceeded{Limit: limit, Max: maximum}
+		t.cancel()
+	}
+}
+
+func (t *limitingTracer) OnEnter(depth int, kind CallKind, parameters CallParameters) {
+	t.check(LimitCallDepth, depth, t.limits.MaxCallDepth)
+	if kind == Create || kind == Create2 {
+		t.creates++
+		t.check(LimitCreatedContracts, t.creates, t.limits.MaxCreatedContracts)
+	}
+	t.Tracer.OnEnter(depth, kind, parameters)
+}
+
+func (t *limitingTracer) OnStorageChange(address Address, key Key, previous, current Word) {
+	t.writes++
+	t.check(LimitStateWrites, t.writes, t.limits.MaxStateWrites)
+	t.Tracer.OnStorageChange(address, key, previous, current)
+}
+
+func (t *limitingTracer) OnBalanceChange(address Address, previous, current Value) {
+	t.writes++
+	t.check(LimitStateWrites, t.writes, t.limits.MaxStateWrites)
+	t.Tracer.OnBalanceChange(address, previous, current)
+}
+
+func (t *limitingTracer) OnLog(log Log) {
+	t.logs++
+	t.check(LimitLogs, t.logs, t.limits.MaxLogs)
+	t.Tracer.OnLog(log)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSimulateWithinLimits_WithoutLimitsOptionsArePassedUnmodified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tracer := NewMockTracer(ctrl)
+	options := SimulationOptions{Tracer: tracer, GasCap: 12}
+	ctx := context.Background()
+	_, err := SimulateWithinLimits(ctx, options, func(got context.Context, opts SimulationOptions) (SimulationResult, error) {
+		if got != ctx {
+			t.Errorf("unexpected context")
+		}
+		if opts.Tracer != tracer || opts.GasCap != 12 {
+			t.Errorf("unexpected options: %v", opts)
+		}
+		return SimulationResult{}, nil
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSimulateWithinLimits_ExceededLimitsAbortSimulation(t *testing.T) {
+	tests := map[string]struct {
+		limits SimulationLimits
+		events func(Tracer)
+		limit  string
+	}{
+		"call depth": {
+			limits: SimulationLimits{MaxCallDepth: 1},
+			events: func(tracer Tracer) {
+				tracer.OnEnter(0, Call, CallParameters{})
+				tracer.OnEnter(1, Call, CallParameters{})
+				tracer.OnEnter(2, Call, CallParameters{})
+			},
+			limit: LimitCallDepth,
+		},
+		"created contracts": {
+			limits: SimulationLimits{MaxCreatedContracts: 1},
+			events: func(tracer Tracer) {
+				tracer.OnEnter(0, Create, CallParameters{})
+				tracer.OnEnter(1, Call, CallParameters{})
+				tracer.OnEnter(1, Create2, CallParameters{})
+			},
+			limit: LimitCreatedContracts,
+		},
+		"logs": {
+			limits: SimulationLimits{MaxLogs: 2},
+			events: func(tracer Tracer) {
+				for range 3 {
+					tracer.OnLog(Log{})
+				}
+			},
+			limit: LimitLogs,
+		},
+		"state writes": {
+			limits: SimulationLimits{MaxStateWrites: 2},
+			events: func(tracer Tracer) {
+				tracer.OnBalanceChange(Address{}, Value{}, Value{1})
+				tracer.OnStorageChange(Address{}, Key{}, Word{}, Word{1})
+				tracer.OnStorageChange(Address{}, Key{}, Word{1}, Word{2})
+			},
+			limit: LimitStateWrites,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			options := SimulationOptions{Limits: test.limits}
+			result, err := SimulateWithinLimits(context.Background(), options, func(ctx context.Context, options SimulationOptions) (SimulationResult, error) {
+				test.events(options.Tracer)
+				if !errors.Is(ctx.Err(), context.Canceled) {
+					t.Errorf("context was not cancelled")
+				}
+				return SimulationResult{Aborted: true}, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !result.Aborted {
+				t.Errorf("simulation was not aborted")
+			}
+			if result.LimitExceeded == nil {
+				t.Fatalf("missing exceeded limit")
+			}
+			if want, got := test.limit, result.LimitExceeded.Limit; want != got {
+				t.Errorf("unexpected exceeded limit, wanted %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestSimulateWithinLimits_LimitsThatAreNotExceededDoNotAbortSimulation(t *testing.T) {
+	limits := SimulationLimits{MaxCallDepth: 1, MaxCreatedContracts: 1, MaxLogs: 1, MaxStateWrites: 1}
+	want := SimulationResult{Receipt: Receipt{Success: true}}
+	result, err := SimulateWithinLimits(context.Background(), SimulationOptions{Limits: limits}, func(ctx context.Context, options SimulationOptions) (SimulationResult, error) {
+		options.Tracer.OnEnter(0, Create, CallParameters{})
+		options.Tracer.OnEnter(1, Call, CallParameters{})
+		options.Tracer.OnLog(Log{})
+		options.Tracer.OnStorageChange(Address{}, Key{}, Word{}, Word{1})
+		if err := ctx.Err(); err != nil {
+			t.Errorf("unexpected context error: %v", err)
+		}
+		return want, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.Success != result.Success || result.Aborted || result.LimitExceeded != nil {
+		t.Errorf("unexpected result, wanted %v, got %v", want, result)
+	}
+}
+
+func TestSimulateWithinLimits_EventsAreForwardedToTracerOfOptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tracer := NewMockTracer(ctrl)
+	gomock.InOrder(
+		tracer.EXPECT().OnTxStart(BlockParameters{}, Transaction{}),
+		tracer.EXPECT().OnEnter(0, Call, CallParameters{}),
+		tracer.EXPECT().OnLog(Log{}),
+		tracer.EXPECT().OnBalanceChange(Address{}, Value{}, Value{1}),
+		tracer.EXPECT().OnStorageChange(Address{}, Key{}, Word{}, Word{1}),
+		tracer.EXPECT().OnExit(0, CallResult{}, nil),
+		tracer.EXPECT().OnTxEnd(Receipt{}, nil),
+	)
+
+	options := SimulationOptions{Tracer: tracer, Limits: SimulationLimits{MaxLogs: 10}}
+	_, err := SimulateWithinLimits(context.Background(), options, func(_ context.Context, options SimulationOptions) (SimulationResult, error) {
+		options.Tracer.OnTxStart(BlockParameters{}, Transaction{})
+		options.Tracer.OnEnter(0, Call, CallParameters{})
+		options.Tracer.OnLog(Log{})
+		options.Tracer.OnBalanceChange(Address{}, Value{}, Value{1})
+		options.Tracer.OnStorageChange(Address{}, Key{}, Word{}, Word{1})
+		options.Tracer.OnExit(0, CallResult{}, nil)
+		options.Tracer.OnTxEnd(Receipt{}, nil)
+		return SimulationResult{}, nil
+	})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestErrSimulationLimitExceeded_ErrorNamesLimit(t *testing.T) {
+	err := &ErrSimulationLimitExceeded{Limit: LimitLogs, Max: 5}
+	if want, got := "simulation limit exceeded: logs exceeds 5", err.Error(); want != got {
+		t.Errorf("unexpected error message, wanted %q, got %q", want, got)
+	}
+}
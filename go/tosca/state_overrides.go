@@ -0,0 +1,177 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "fmt"
+
+// StateOverrides is a set of temporary modifications of accounts applied on
+// top of a TransactionContext before simulating the execution of a
+// transaction. The semantic matches the state override set of geth's eth_call
+// RPC method.
+type StateOverrides map[Address]AccountOverride
+
+// AccountOverride lists the modifications of a single account. Nil fields are
+// not modified. At most one of State and StateDiff may be set.
+type AccountOverride struct {
+	Balance *Value
+	Nonce   *uint64
+	Code    Code
+	// State, if not nil, replaces the full storage of the account. Storage
+	// slots not listed are considered to be zero.
+	State map[Key]Word
+	// StateDiff, if not nil, modifies the listed storage slots of the
+	// account, keeping all other slots unchanged.
+	StateDiff map[Key]Word
+}
+
+// Apply applies the overrides to the given transaction context. Balances,
+// nonces, and codes are updated in the given context, while storage
+// overrides are realized by a wrapper around the given context which is
+// returned. Overridden storage values are reported as committed values, as
+// if they had been part of the state before the transaction.
+func (o StateOverrides) Apply(context TransactionContext) (TransactionContext, error) {
+	if len(o) == 0 {
+		return context, nil
+	}
+	var storage map[Address]*storageOverride
+	for address, account := range o {
+		if account.State != nil && account.StateDiff != nil {
+			return nil, fmt.Errorf("account %v has both state and state diff overrides", address)
+		}
+		if account.Balance != nil {
+			context.SetBalance(address, *account.Balance)
+		}
+		if account.Nonce != nil {
+			context.SetNonce(address, *account.Nonce)
+		}
+		if account.Code != nil {
+			context.SetCode(address, account.Code)
+		}
+
+		values := account.StateDiff
+		if account.State != nil {
+			values = account.State
+		}
+		if values == nil {
+			continue
+		}
+		if storage == nil {
+			storage = map[Address]*storageOverride{}
+		}
+		override := &storageOverride{
+			replaced:  account.State != nil,
+			committed: make(map[Key]Word, len(values)),
+			current:   make(map[Key]Word, len(values)),
+		}
+		for key, value := range values {
+			override.committed[key] = value
+			override.current[key] = value
+		}
+		storage[address] = override
+	}
+	if storage == nil {
+		return context, nil
+	}
+	return &overriddenContext{
+		TransactionContext: context,
+		storage:            storage,
+		snapshots:          map[Snapshot]int{},
+	}, nil
+}
+
+// storageOverride is the overridden storage of a single account.
+type storageOverride struct {
+	replaced  bool         // true if slots not listed are zero
+	committed map[Key]Word // the overridden values before the transaction
+	current   map[Key]Word // the current values of overridden slots
+}
+
+// overriddenContext is a TransactionContext realizing storage overrides on
+// top of a wrapped context. Updates of overridden storage slots are tracked
+// locally and are reverted together with the snapshots of the wrapped
+// context.
+type overriddenContext struct {
+	TransactionContext
+	storage   map[Address]*storageOverride
+	undo      []func()
+	snapshots map[Snapshot]int // length of the undo log by snapshot
+}
+
+func (c *overriddenContext) GetStorage(address Address, key Key) Word {
+	if override, found := c.storage[address]; found {
+		if value, found := override.current[key]; found || override.replaced {
+			return value
+		}
+	}
+	return c.TransactionContext.GetStorage(address, key)
+}
+
+func (c *overriddenContext) GetCommittedStorage(address Address, key Key) Word {
+	if override, found := c.storage[address]; found {
+		if value, found := override.committed[key]; found || override.replaced {
+			return value
+		}
+	}
+	return c.TransactionContext.GetCommittedStorage(address, key)
+}
+
+func (c *overriddenContext) SetStorage(address Address, key Key, value Word) StorageStatus {
+	override, found := c.storage[address]
+	if !found {
+		return c.TransactionContext.SetStorage(address, key, value)
+	}
+	if _, found := override.current[key]; !found && !override.replaced {
+		return c.TransactionContext.SetStorage(address, key, value)
+	}
+	original := override.committed[key]
+	current, present := override.current[key]
+	override.current[key] = value
+	c.undo = append(c.undo, func() {
+		if present {
+			override.current[key] = current
+		} else {
+			delete(override.current, key)
+		}
+	})
+	return GetStorageStatus(original, current, value)
+}
+
+// RemoveAccount removes the given account from the wrapped context and drops
+// the overrides of its storage, such that its storage reads as empty.
+func (c *overriddenContext) RemoveAccount(address Address) {
+	RemoveAccount(c.TransactionContext, address)
+	override, found := c.storage[address]
+	if !found {
+		return
+	}
+	delete(c.storage, address)
+	c.undo = append(c.undo, func() {
+		c.storage[address] = override
+	})
+}
+
+func (c *overriddenContext) CreateSnapshot() Snapshot {
+	snapshot := c.TransactionContext.CreateSnapshot()
+	c.snapshots[snapshot] = len(c.undo)
+	return snapshot
+}
+
+func (c *overriddenContext) RestoreSnapshot(snapshot Snapshot) {
+	c.TransactionContext.RestoreSnapshot(snapshot)
+	length, found := c.snapshots[snapshot]
+	if !found {
+		return
+	}
+	for len(c.undo) > length {
+		c.undo[len(c.undo)-1]()
+		c.undo = c.undo[:len(c.undo)-1]
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"slices"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestStateOverrides_EmptyOverridesDoNotWrapContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	got, err := StateOverrides{}.Apply(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != context {
+		t.Errorf("context was wrapped without need")
+	}
+}
+
+func TestStateOverrides_AccountFieldsAreUpdated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	address := Address{1}
+	balance := NewValue(42)
+	nonce := uint64(12)
+	code := Code{1, 2, 3}
+
+	context.EXPECT().SetBalance(address, balance)
+	context.EXPECT().SetNonce(address, nonce)
+	context.EXPECT().SetCode(address, code)
+
+	got, err := StateOverrides{
+		address: {Balance: &balance, Nonce: &nonce, Code: code},
+	}.Apply(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != context {
+		t.Errorf("context was wrapped without need")
+	}
+}
+
+func TestStateOverrides_StateAndStateDiffAreMutuallyExclusive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	_, err := StateOverrides{
+		{1}: {State: map[Key]Word{}, StateDiff: map[Key]Word{}},
+	}.Apply(context)
+	if err == nil {
+		t.Errorf("expected an error for conflicting storage overrides")
+	}
+}
+
+func TestStateOverrides_StateReplacesStorage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	address := Address{1}
+	other := Address{2}
+	context.EXPECT().GetStorage(other, Key{1}).Return(Word{3})
+	context.EXPECT().GetCommittedStorage(other, Key{1}).Return(Word{4})
+
+	wrapped, err := StateOverrides{
+		address: {State: map[Key]Word{{1}: {2}}},
+	}.Apply(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		address   Address
+		key       Key
+		current   Word
+		committed Word
+	}{
+		{address, Key{1}, Word{2}, Word{2}},
+		{address, Key{2}, Word{}, Word{}},
+		{other, Key{1}, Word{3}, Word{4}},
+	}
+	for _, test := range tests {
+		if want, got := test.current, wrapped.GetStorage(test.address, test.key); want != got {
+			t.Errorf("unexpected value of %v/%v, wanted %v, got %v", test.address, test.key, want, got)
+		}
+		if want, got := test.committed, wrapped.GetCommittedStorage(test.address, test.key); want != got {
+			t.Errorf("unexpected committed value of %v/%v, wanted %v, got %v", test.address, test.key, want, got)
+		}
+	}
+}
+
+func TestStateOverrides_StateDiffModifiesListedSlotsOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	address := Address{1}
+	context.EXPECT().GetStorage(address, Key{2}).Return(Word{3})
+	context.EXPECT().SetStorage(address, Key{2}, Word{4}).Return(StorageModified)
+
+	wrapped, err := StateOverrides{
+		address: {StateDiff: map[Key]Word{{1}: {2}}},
+	}.Apply(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want, got := (Word{2}), wrapped.GetStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected value of overridden slot, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{3}), wrapped.GetStorage(address, Key{2}); want != got {
+		t.Errorf("unexpected value of other slot, wanted %v, got %v", want, got)
+	}
+	if want, got := StorageModified, wrapped.SetStorage(address, Key{2}, Word{4}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+}
+
+func TestStateOverrides_StorageUpdatesReportStatusAndCanBeReverted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	address := Address{1}
+	context.EXPECT().CreateSnapshot().Return(Snapshot(7))
+	context.EXPECT().RestoreSnapshot(Snapshot(7))
+
+	wrapped, err := StateOverrides{
+		address: {State: map[Key]Word{{1}: {2}}},
+	}.Apply(context)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snapshot := wrapped.CreateSnapshot()
+	if want, got := StorageModified, wrapped.SetStorage(address, Key{1}, Word{3}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+	if want, got := StorageAdded, wrapped.SetStorage(address, Key{2}, Word{3}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{3}), wrapped.GetStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected value after update, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{2}), wrapped.GetCommittedStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected committed value after update, wanted %v, got %v", want, got)
+	}
+
+	wrapped.RestoreSnapshot(snapshot)
+	if want, got := (Word{2}), wrapped.GetStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected value after revert, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{}), wrapped.GetStorage(address, Key{2}); want != got {
+		t.Errorf("unexpected value after revert, wanted %v, got %v", want, got)
+	}
+}
+
+func TestStateOverrides_RemovedAccountsLoseStorageOverridesUntilReverted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+
+	address := Address{1}
+	context.EXPECT().CreateSnapshot().Return(Snapshot(1))
+	context.EXPECT().GetStorage(address, Key{1}).Return(Word{})
+	context.EXPECT().RestoreSnapshot(Snapshot(1))
+
+	remover := &removingContext{TransactionContext: context}
+	wrapped, err := StateOverrides{
+		address: {State: map[Key]Word{{1}: {2}}},
+	}.Apply(remover)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snapshot := wrapped.CreateSnapshot()
+	RemoveAccount(wrapped, address)
+	if want, got := []Address{address}, remover.removed; !slices.Equal(want, got) {
+		t.Errorf("unexpected removed accounts, wanted %v, got %v", want, got)
+	}
+	if want, got := (Word{}), wrapped.GetStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected value of removed account, wanted %v, got %v", want, got)
+	}
+
+	wrapped.RestoreSnapshot(snapshot)
+	if want, got := (Word{2}), wrapped.GetStorage(address, Key{1}); want != got {
+		t.Errorf("unexpected value after revert, wanted %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "fmt"
+
+// ErrStaticContextViolation is reported by interpreters wrapped using
+// NewStaticAuditInterpreter if the wrapped interpreter attempted to modify
+// the state while running in a static context.
+type ErrStaticContextViolation struct {
+	Operation string  // the offending RunContext method, e.g. SetStorage or Call(create)
+	Address   Address // the account whose state was to be modified
+	Depth     int     // the depth of the call in which the violation happened
+}
+
+func (e *ErrStaticContextViolation) Error() string {
+	return fmt.Sprintf(
+		"static context violation: %s on account %v at depth %d",
+		e.Operation, e.Address, e.Depth,
+	)
+}
+
+// NewStaticAuditInterpreter wraps the given interpreter such that state
+// modifications requested by it while running in a static context are
+// detected. This covers storage and transient storage updates, logs, self-
+// destructs, balance, nonce, and code changes, value-bearing calls, and
+// contract creations. Offending operations are not forwarded to the run
+// context; instead, the run is completed with an ErrStaticContextViolation.
+//
+// Interpreters are required to reject such operations themselves. This audit
+// mode is intended for validating interpreter implementations, for instance
+// when integrating third-party interpreters through an adapter, and is not
+// meant to be used in production.
+func NewStaticAuditInterpreter(interpreter Interpreter) Interpreter {
+	return &staticAuditInterpreter{interpreter: interpreter}
+}
+
+type staticAuditInterpreter struct {
+	interpreter Interpreter
+}
+
+func (i *staticAuditInterpreter) Run(params Parameters) (Result, error) {
+	if !params.Static {
+		return i.interpreter.Run(params)
+	}
+	context := &staticAuditContext{RunContext: params.Context, depth: params.Depth}
+	params.Context = context
+	result, err := i.interpreter.Run(params)
+	if context.violation != nil {
+		return Result{}, context.violation
+	}
+	return result, err
+}
+
+// staticAuditContext is a RunContext intercepting all state modifications,
+// retaining the first of them as a violation.
+type staticAuditContext struct {
+	RunContext
+	depth     int
+	violation *ErrStaticContextViolation
+}
+
+func (c *staticAuditContext) report(operation string, address Address) {
+	if c.violation == nil {
+		c.violation = &ErrStaticContextViolation{
+			Operation: operation,
+			Address:   address,
+			Depth:     c.depth,
+		}
+	}
+}
+
+func (c *staticAuditContext) SetStorage(address Address, _ Key, _ Word) StorageStatus {
+	c.report("SetStorage", address)
+	return StorageAssigned
+}
+
+func (c *staticAuditContext) SetTransientStorage(address Address, _ Key, _ Word) {
+	c.report("SetTransientStorage", address)
+}
+
+func (c *staticAuditContext) SetBalance(address Address, _ Value) {
+	c.report("SetBalance", address)
+}
+
+func (c *staticAuditContext) SetNonce(address Address, _ uint64) {
+	c.report("SetNonce", address)
+}
+
+func (c *staticAuditContext) SetCode(address Address, _ Code) {
+	c.report("SetCode", address)
+}
+
+func (c *staticAuditContext) EmitLog(log Log) {
+	c.report("EmitLog", log.Address)
+}
+
+func (c *staticAuditContext) SelfDestruct(address Address, _ Address) bool {
+	c.report("SelfDestruct", address)
+	return false
+}
+
+func (c *staticAuditContext) Call(kind CallKind, parameters CallParameters) (CallResult, error) {
+	switch {
+	case kind == Create || kind == Create2:
+		c.report(fmt.Sprintf("Call(%v)", kind), parameters.Sender)
+		return CallResult{}, nil
+	case kind == Call && parameters.Value != (Value{}):
+		c.report(fmt.Sprintf("Call(%v)", kind), parameters.Recipient)
+		return CallResult{}, nil
+	}
+	return c.RunContext.Call(kind, parameters)
+}
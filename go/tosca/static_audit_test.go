@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestStaticAuditInterpreter_NonStaticRunsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	context := NewMockRunContext(ctrl)
+
+	params := Parameters{Context: context, Gas: 10}
+	want := Result{Success: true, GasLeft: 5}
+	interpreter.EXPECT().Run(params).Return(want, nil)
+
+	got, err := NewStaticAuditInterpreter(interpreter).Run(params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.Success != got.Success || want.GasLeft != got.GasLeft {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+}
+
+func TestStaticAuditInterpreter_StateModificationsAreReportedAsViolations(t *testing.T) {
+	tests := map[string]struct {
+		operation string
+		address   Address
+		run       func(RunContext)
+	}{
+		"SetStorage": {"SetStorage", Address{1}, func(c RunContext) {
+			c.SetStorage(Address{1}, Key{}, Word{1})
+		}},
+		"SetTransientStorage": {"SetTransientStorage", Address{1}, func(c RunContext) {
+			c.SetTransientStorage(Address{1}, Key{}, Word{1})
+		}},
+		"SetBalance": {"SetBalance", Address{2}, func(c RunContext) {
+			c.SetBalance(Address{2}, Value{1})
+		}},
+		"SetNonce": {"SetNonce", Address{3}, func(c RunContext) {
+			c.SetNonce(Address{3}, 1)
+		}},
+		"SetCode": {"SetCode", Address{4}, func(c RunContext) {
+			c.SetCode(Address{4}, Code{1})
+		}},
+		"EmitLog": {"EmitLog", Address{5}, func(c RunContext) {
+			c.EmitLog(Log{Address: Address{5}})
+		}},
+		"SelfDestruct": {"SelfDestruct", Address{6}, func(c RunContext) {
+			c.SelfDestruct(Address{6}, Address{7})
+		}},
+		"Call with value": {"Call(call)", Address{8}, func(c RunContext) {
+			_, _ = c.Call(Call, CallParameters{Recipient: Address{8}, Value: Value{1}})
+		}},
+		"Create": {"Call(create)", Address{9}, func(c RunContext) {
+			_, _ = c.Call(Create, CallParameters{Sender: Address{9}})
+		}},
+		"Create2": {"Call(create2)", Address{9}, func(c RunContext) {
+			_, _ = c.Call(Create2, CallParameters{Sender: Address{9}})
+		}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			interpreter := NewMockInterpreter(ctrl)
+			// The context is not expected to receive any calls.
+			context := NewMockRunContext(ctrl)
+
+			interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+				test.run(params.Context)
+				return Result{Success: true}, nil
+			})
+
+			params := Parameters{Context: context, Static: true, Depth: 2}
+			_, err := NewStaticAuditInterpreter(interpreter).Run(params)
+			var violation *ErrStaticContextViolation
+			if !errors.As(err, &violation) {
+				t.Fatalf("expected static context violation, got %v", err)
+			}
+			want := ErrStaticContextViolation{Operation: test.operation, Address: test.address, Depth: 2}
+			if want != *violation {
+				t.Errorf("unexpected violation, wanted %v, got %v", &want, violation)
+			}
+		})
+	}
+}
+
+func TestStaticAuditInterpreter_FirstViolationIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	context := NewMockRunContext(ctrl)
+
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		params.Context.EmitLog(Log{Address: Address{1}})
+		params.Context.SetStorage(Address{2}, Key{}, Word{})
+		return Result{}, nil
+	})
+
+	_, err := NewStaticAuditInterpreter(interpreter).Run(Parameters{Context: context, Static: true})
+	var violation *ErrStaticContextViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("expected static context violation, got %v", err)
+	}
+	if want, got := "EmitLog", violation.Operation; want != got {
+		t.Errorf("unexpected operation, wanted %s, got %s", want, got)
+	}
+}
+
+func TestStaticAuditInterpreter_ReadOnlyOperationsAreForwarded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	context := NewMockRunContext(ctrl)
+
+	context.EXPECT().GetStorage(Address{1}, Key{2}).Return(Word{3})
+	context.EXPECT().Call(StaticCall, CallParameters{Recipient: Address{4}}).Return(CallResult{Success: true}, nil)
+	context.EXPECT().Call(Call, CallParameters{Recipient: Address{5}}).Return(CallResult{Success: true}, nil)
+	context.EXPECT().Call(CallCode, CallParameters{Recipient: Address{6}, Value: Value{1}}).Return(CallResult{Success: true}, nil)
+
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		if want, got := (Word{3}), params.Context.GetStorage(Address{1}, Key{2}); want != got {
+			t.Errorf("unexpected storage value, wanted %v, got %v", want, got)
+		}
+		calls := []struct {
+			kind       CallKind
+			parameters CallParameters
+		}{
+			{StaticCall, CallParameters{Recipient: Address{4}}},
+			{Call, CallParameters{Recipient: Address{5}}},
+			{CallCode, CallParameters{Recipient: Address{6}, Value: Value{1}}},
+		}
+		for _, call := range calls {
+			if res, err := params.Context.Call(call.kind, call.parameters); err != nil || !res.Success {
+				t.Errorf("unexpected result of %v, got %v, %v", call.kind, res, err)
+			}
+		}
+		return Result{Success: true}, nil
+	})
+
+	if _, err := NewStaticAuditInterpreter(interpreter).Run(Parameters{Context: context, Static: true}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestErrStaticContextViolation_ErrorDescribesViolation(t *testing.T) {
+	err := &ErrStaticContextViolation{Operation: "SetStorage", Address: Address{0x12}, Depth: 3}
+	want := "static context violation: SetStorage on account 0x1200000000000000000000000000000000000000 at depth 3"
+	if got := err.Error(); want != got {
+		t.Errorf("unexpected error message, wanted %q, got %q", want, got)
+	}
+}
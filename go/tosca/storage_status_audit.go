@@ -0,0 +1,94 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "fmt"
+
+// ErrStorageStatusMismatch is reported by interpreters wrapped using
+// NewStorageStatusAuditInterpreter if the StorageStatus returned by the
+// run context for a storage update differs from the expected transition.
+type ErrStorageStatusMismatch struct {
+	Address  Address       // the account whose storage was updated
+	Key      Key           // the updated storage slot
+	Original Word          // the committed value of the slot before the transaction
+	Current  Word          // the value of the slot before the update
+	New      Word          // the value written to the slot
+	Expected StorageStatus // the status derived from the values above
+	Got      StorageStatus // the status returned by the run context
+	Depth    int           // the depth of the call in which the update happened
+}
+
+func (e *ErrStorageStatusMismatch) Error() string {
+	return fmt.Sprintf(
+		"storage status mismatch for slot %v of account %v at depth %d: "+
+			"%v -> %v -> %v should be %v, got %v",
+		e.Key, e.Address, e.Depth, e.Original, e.Current, e.New, e.Expected, e.Got,
+	)
+}
+
+// NewStorageStatusAuditInterpreter wraps the given interpreter such that the
+// StorageStatus returned by the run context for each storage update is
+// cross-checked against the transition computed independently from the
+// committed, current, and new value of the slot using GetStorageStatus, the
+// model also used by the conformance tests. On a mismatch, the expected
+// status is passed to the interpreter, such that gas and refunds are
+// computed correctly, and the run is completed with an
+// ErrStorageStatusMismatch.
+//
+// This audit mode is intended for validating host implementations of the
+// run context, for instance when integrating Tosca interpreters into a new
+// client, and is not meant to be used in production since it doubles the
+// number of storage reads.
+func NewStorageStatusAuditInterpreter(interpreter Interpreter) Interpreter {
+	return &storageStatusAuditInterpreter{interpreter: interpreter}
+}
+
+type storageStatusAuditInterpreter struct {
+	interpreter Interpreter
+}
+
+func (i *storageStatusAuditInterpreter) Run(params Parameters) (Result, error) {
+	context := &storageStatusAuditContext{RunContext: params.Context, depth: params.Depth}
+	params.Context = context
+	result, err := i.interpreter.Run(params)
+	if context.mismatch != nil {
+		return Result{}, context.mismatch
+	}
+	return result, err
+}
+
+// storageStatusAuditContext is a RunContext checking the status of storage
+// updates, retaining the first mismatch.
+type storageStatusAuditContext struct {
+	RunContext
+	depth    int
+	mismatch *ErrStorageStatusMismatch
+}
+
+func (c *storageStatusAuditContext) SetStorage(address Address, key Key, value Word) StorageStatus {
+	original := c.RunContext.GetCommittedStorage(address, key)
+	current := c.RunContext.GetStorage(address, key)
+	got := c.RunContext.SetStorage(address, key, value)
+	expected := GetStorageStatus(original, current, value)
+	if got != expected && c.mismatch == nil {
+		c.mismatch = &ErrStorageStatusMismatch{
+			Address:  address,
+			Key:      key,
+			Original: original,
+			Current:  current,
+			New:      value,
+			Expected: expected,
+			Got:      got,
+			Depth:    c.depth,
+		}
+	}
+	return expected
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestStorageStatusAuditInterpreter_CorrectStatusIsAccepted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	context := NewMockRunContext(ctrl)
+
+	address, key := Address{1}, Key{2}
+	context.EXPECT().GetCommittedStorage(address, key).Return(Word{1})
+	context.EXPECT().GetStorage(address, key).Return(Word{1})
+	context.EXPECT().SetStorage(address, key, Word{}).Return(StorageDeleted)
+
+	want := Result{Success: true, GasLeft: 5}
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		if got := params.Context.SetStorage(address, key, Word{}); got != StorageDeleted {
+			t.Errorf("unexpected storage status, wanted %v, got %v", StorageDeleted, got)
+		}
+		return want, nil
+	})
+
+	got, err := NewStorageStatusAuditInterpreter(interpreter).Run(Parameters{Context: context})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.Success != got.Success || want.GasLeft != got.GasLeft {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+}
+
+func TestStorageStatusAuditInterpreter_WrongStatusIsReportedAsMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	context := NewMockRunContext(ctrl)
+
+	address, key := Address{1}, Key{2}
+	context.EXPECT().GetCommittedStorage(address, key).Return(Word{1}).Times(2)
+	context.EXPECT().GetStorage(address, key).Return(Word{2}).Times(2)
+	context.EXPECT().SetStorage(address, key, Word{1}).Return(StorageAssigned)
+	context.EXPECT().SetStorage(address, key, Word{}).Return(StorageAssigned)
+
+	interpreter.EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		// The expected status is forwarded to the interpreter.
+		if want, got := StorageModifiedRestored, params.Context.SetStorage(address, key, Word{1}); want != got {
+			t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+		}
+		params.Context.SetStorage(address, key, Word{})
+		return Result{Success: true}, nil
+	})
+
+	_, err := NewStorageStatusAuditInterpreter(interpreter).Run(Parameters{Context: context, Depth: 3})
+	var mismatch *ErrStorageStatusMismatch
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected storage status mismatch, got %v", err)
+	}
+	want := ErrStorageStatusMismatch{
+		Address:  address,
+		Key:      key,
+		Original: Word{1},
+		Current:  Word{2},
+		New:      Word{1},
+		Expected: StorageModifiedRestored,
+		Got:      StorageAssigned,
+		Depth:    3,
+	}
+	if *mismatch != want {
+		t.Errorf("unexpected mismatch, wanted %v, got %v", want, *mismatch)
+	}
+}
+
+func TestStorageStatusAuditInterpreter_ErrorsOfInterpreterArePreserved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	injected := errors.New("injected error")
+	interpreter.EXPECT().Run(gomock.Any()).Return(Result{}, injected)
+
+	_, err := NewStorageStatusAuditInterpreter(interpreter).Run(Parameters{})
+	if !errors.Is(err, injected) {
+		t.Errorf("unexpected error, wanted %v, got %v", injected, err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "context"
+
+// SystemCallProcessor is an optional extension of the Processor interface
+// for processors supporting the system calls conducted at the boundaries of
+// blocks. System calls are calls to system contracts issued by the chain
+// itself, not by users, and are neither paying for gas nor incrementing any
+// nonce.
+type SystemCallProcessor interface {
+	Processor
+
+	// ProcessSystemCalls runs all system calls required by the revision of
+	// the given block in the given phase of the block. For calls at the end
+	// of a block, the resulting execution layer requests are returned.
+	ProcessSystemCalls(context.Context, SystemCallParameters, TransactionContext) (SystemCallResult, error)
+}
+
+// BlockPhase identifies the point of the processing of a block at which
+// system calls are conducted.
+type BlockPhase int
+
+const (
+	// BlockStart is the phase before the first transaction of a block.
+	BlockStart BlockPhase = iota
+	// BlockEnd is the phase after the last transaction of a block.
+	BlockEnd
+)
+
+func (p BlockPhase) String() string {
+	switch p {
+	case BlockStart:
+		return "BlockStart"
+	case BlockEnd:
+		return "BlockEnd"
+	default:
+		return "BlockPhase(?)"
+	}
+}
+
+// SystemCallParameters summarizes the inputs of the system calls of a block.
+type SystemCallParameters struct {
+	BlockParameters
+	Phase BlockPhase
+	// ParentBeaconBlockRoot is the root stored by the EIP-4788 system call.
+	ParentBeaconBlockRoot Hash
+	// ParentBlockHash is the hash stored by the EIP-2935 system call.
+	ParentBlockHash Hash
+}
+
+// SystemCallResult summarizes the outcome of the system calls of a block.
+type SystemCallResult struct {
+	// Requests lists the execution layer requests of EIP-7685 produced by
+	// the system calls, each prefixed by its request type.
+	Requests []Data
+}
+
+// SystemCall describes a call to a system contract conducted by the chain at
+// the start or the end of every block starting with a given revision.
+type SystemCall struct {
+	Name     string
+	Address  Address
+	Phase    BlockPhase
+	Revision Revision // the first revision conducting the call
+	// Input computes the input of the call from the block's parameters.
+	Input func(SystemCallParameters) Data
+	// Required calls render a block invalid if the system contract is not
+	// deployed or the call fails. Failures of other calls are ignored.
+	Required bool
+	// RequestType, if not negative, identifies the type of the execution
+	// layer requests returned by the call.
+	RequestType int
+}
+
+// SystemCallGas is the gas provided to system calls.
+const SystemCallGas Gas = 30_000_000
+
+var (
+	// SystemAddress is the sender of system calls.
+	SystemAddress = Address{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe,
+	}
+	// BeaconRootsAddress is the address of the EIP-4788 contract.
+	BeaconRootsAddress = Address{0x00, 0x0f, 0x3d, 0xf6, 0xd7, 0x32, 0x80, 0x7e, 0xf1, 0x31, 0x9f, 0xb7, 0xb8, 0xbb, 0x85, 0x22, 0xd0, 0xbe, 0xac, 0x02}
+	// HistoryStorageAddress is the address of the EIP-2935 contract.
+	HistoryStorageAddress = Address{0x00, 0x00, 0xf9, 0x08, 0x27, 0xf1, 0xc5, 0x3a, 0x10, 0xcb, 0x7a, 0x02, 0x33, 0x5b, 0x17, 0x53, 0x20, 0x00, 0x29, 0x35}
+	// WithdrawalRequestsAddress is the address of the EIP-7002 contract.
+	WithdrawalRequestsAddress = Address{0x00, 0x00, 0x09, 0x61, 0xef, 0x48, 0x0e, 0xb5, 0x5e, 0x80, 0xd1, 0x9a, 0xd8, 0x35, 0x79, 0xa6, 0x4c, 0x00, 0x70, 0x02}
+	// ConsolidationRequestsAddress is the address of the EIP-7251 contract.
+	ConsolidationRequestsAddress = Address{0x00, 0x00, 0xbb, 0xdd, 0xc7, 0xce, 0x48, 0x86, 0x42, 0xfb, 0x57, 0x9f, 0x8b, 0x00, 0xf3, 0xa5, 0x90, 0x00, 0x72, 0x51}
+)
+
+var systemCalls = []SystemCall{
+	{
+		Name:        "EIP-4788 beacon block root",
+		Address:     BeaconRootsAddress,
+		Phase:       BlockStart,
+		Revision:    R13_Cancun,
+		Input:       func(p SystemCallParameters) Data { return p.ParentBeaconBlockRoot[:] },
+		RequestType: -1,
+	},
+	{
+		Name:        "EIP-2935 historical block hash",
+		Address:     HistoryStorageAddress,
+		Phase:       BlockStart,
+		Revision:    R14_Prague,
+		Input:       func(p SystemCallParameters) Data { return p.ParentBlockHash[:] },
+		RequestType: -1,
+	},
+	{
+		Name:        "EIP-7002 withdrawal requests",
+		Address:     WithdrawalRequestsAddress,
+		Phase:       BlockEnd,
+		Revision:    R14_Prague,
+		Input:       func(SystemCallParameters) Data { return nil },
+		Required:    true,
+		RequestType: 0x01,
+	},
+	{
+		Name:        "EIP-7251 consolidation requests",
+		Address:     ConsolidationRequestsAddress,
+		Phase:       BlockEnd,
+		Revision:    R14_Prague,
+		Input:       func(SystemCallParameters) Data { return nil },
+		Required:    true,
+		RequestType: 0x02,
+	},
+}
+
+// GetSystemCalls lists the system calls to be conducted in the given phase of
+// blocks of the given revision, in the order they are to be executed.
+func GetSystemCalls(revision Revision, phase BlockPhase) []SystemCall {
+	res := []SystemCall{}
+	for _, call := range systemCalls {
+		if call.Phase == phase && revision >= call.Revision {
+			res = append(res, call)
+		}
+	}
+	return res
+}
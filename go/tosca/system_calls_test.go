@@ -0,0 +1,73 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetSystemCalls_CallsAreGatedByRevisionAndPhase(t *testing.T) {
+	tests := []struct {
+		revision Revision
+		phase    BlockPhase
+		want     []Address
+	}{
+		{R07_Istanbul, BlockStart, []Address{}},
+		{R12_Shanghai, BlockStart, []Address{}},
+		{R12_Shanghai, BlockEnd, []Address{}},
+		{R13_Cancun, BlockStart, []Address{BeaconRootsAddress}},
+		{R13_Cancun, BlockEnd, []Address{}},
+		{R14_Prague, BlockStart, []Address{BeaconRootsAddress, HistoryStorageAddress}},
+		{R14_Prague, BlockEnd, []Address{WithdrawalRequestsAddress, ConsolidationRequestsAddress}},
+	}
+
+	for _, test := range tests {
+		got := []Address{}
+		for _, call := range GetSystemCalls(test.revision, test.phase) {
+			got = append(got, call.Address)
+		}
+		if !slices.Equal(test.want, got) {
+			t.Errorf("unexpected system calls for %v at %v, wanted %v, got %v", test.revision, test.phase, test.want, got)
+		}
+	}
+}
+
+func TestGetSystemCalls_InputsAreDerivedFromParameters(t *testing.T) {
+	parameters := SystemCallParameters{
+		ParentBeaconBlockRoot: Hash{1},
+		ParentBlockHash:       Hash{2},
+	}
+	want := map[Address]Data{
+		BeaconRootsAddress:           parameters.ParentBeaconBlockRoot[:],
+		HistoryStorageAddress:        parameters.ParentBlockHash[:],
+		WithdrawalRequestsAddress:    nil,
+		ConsolidationRequestsAddress: nil,
+	}
+	for _, call := range systemCalls {
+		if got := call.Input(parameters); !slices.Equal(want[call.Address], got) {
+			t.Errorf("unexpected input of %s, wanted %x, got %x", call.Name, want[call.Address], got)
+		}
+	}
+}
+
+func TestBlockPhase_String(t *testing.T) {
+	tests := map[BlockPhase]string{
+		BlockStart:    "BlockStart",
+		BlockEnd:      "BlockEnd",
+		BlockPhase(5): "BlockPhase(?)",
+	}
+	for phase, want := range tests {
+		if got := phase.String(); want != got {
+			t.Errorf("unexpected string, wanted %s, got %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import "github.com/Fantom-foundation/Tosca/go/tosca/vm"
+
+//go:generate mockgen -source tracer.go -destination tracer_mock.go -package tosca
+
+// Tracer is an interface for components observing the execution of
+// transactions. Tracers are informed by processors about the start and end of
+// transactions and calls, by interpreters about the execution of individual
+// instructions, and by the transaction context about modifications of the
+// world state. Since all interpreters and processors report to the same
+// interface, a single tracer implementation works with any configuration.
+//
+// Tracers are provided to processors through the SimulationOptions and
+// forwarded to interpreters through the TransactionParameters.
+type Tracer interface {
+	// OnTxStart is called before a transaction is processed.
+	OnTxStart(BlockParameters, Transaction)
+	// OnTxEnd is called after a transaction got processed.
+	OnTxEnd(Receipt, error)
+
+	// OnEnter is called when a call or create is started, including the
+	// top-level call of a transaction. The depth of the top-level call is 0.
+	OnEnter(depth int, kind CallKind, parameters CallParameters)
+	// OnExit is called when the call or create started by the matching
+	// OnEnter call on the same depth is completed.
+	OnExit(depth int, result CallResult, err error)
+
+	// OnOpcode is called by interpreters before executing an instruction.
+	OnOpcode(OpCodeState)
+
+	// OnStorageChange is called after a storage slot got updated.
+	OnStorageChange(address Address, key Key, previous, current Word)
+	// OnBalanceChange is called after the balance of an account got updated.
+	OnBalanceChange(address Address, previous, current Value)
+	// OnLog is called after a log got emitted.
+	OnLog(Log)
+}
+
+// OpCodeState describes the state of an execution before an instruction is
+// executed. The stack and memory are only valid for the duration of the
+// OnOpcode call and must not be modified or retained by tracers.
+type OpCodeState struct {
+	Pc      int       // the position of the instruction in the EVM byte code
+	OpCode  vm.OpCode // the instruction to be executed
+	Gas     Gas       // the gas available before executing the instruction
+	Depth   int       // the depth of the call executing the code, starting at 0
+	Address Address   // the account whose storage is accessed by the code
+	Stack   []Word    // the stack content, the last element is the top of the stack
+	Memory  []byte    // the memory content
+	// InitCode is true if the executed code is the init code of a contract
+	// creation, enabling tracers to distinguish constructor executions.
+	InitCode bool
+}
+
+// NoOpTracer is a Tracer ignoring all events. It is intended to be embedded
+// into tracers only interested in a subset of the events.
+type NoOpTracer struct{}
+
+func (NoOpTracer) OnTxStart(BlockParameters, Transaction)   {}
+func (NoOpTracer) OnTxEnd(Receipt, error)                   {}
+func (NoOpTracer) OnEnter(int, CallKind, CallParameters)    {}
+func (NoOpTracer) OnExit(int, CallResult, error)            {}
+func (NoOpTracer) OnOpcode(OpCodeState)                     {}
+func (NoOpTracer) OnStorageChange(Address, Key, Word, Word) {}
+func (NoOpTracer) OnBalanceChange(Address, Value, Value)    {}
+func (NoOpTracer) OnLog(Log)                                {}
+
+// NewTracingTransactionContext wraps the given context such that modifications
+// of storage slots and balances as well as emitted logs are reported to the
+// given tracer. It is intended to be used by processors supporting tracers.
+func NewTracingTransactionContext(context TransactionContext, tracer Tracer) TransactionContext {
+	return &tracingContext{TransactionContext: context, tracer: tracer}
+}
+
+type tracingContext struct {
+	TransactionContext
+	tracer Tracer
+}
+
+func (c *tracingContext) SetStorage(address Address, key Key, value Word) StorageStatus {
+	previous := c.TransactionContext.GetStorage(address, key)
+	status := c.TransactionContext.SetStorage(address, key, value)
+	c.tracer.OnStorageChange(address, key, previous, value)
+	return status
+}
+
+func (c *tracingContext) SetBalance(address Address, value Value) {
+	previous := c.TransactionContext.GetBalance(address)
+	c.TransactionContext.SetBalance(address, value)
+	c.tracer.OnBalanceChange(address, previous, value)
+}
+
+func (c *tracingContext) SelfDestruct(address Address, beneficiary Address) bool {
+	previous := c.TransactionContext.GetBalance(address)
+	previousBeneficiary := c.TransactionContext.GetBalance(beneficiary)
+	res := c.TransactionContext.SelfDestruct(address, beneficiary)
+	if current := c.TransactionContext.GetBalance(address); current != previous {
+		c.tracer.OnBalanceChange(address, previous, current)
+	}
+	if address != beneficiary {
+		if current := c.TransactionContext.GetBalance(beneficiary); current != previousBeneficiary {
+			c.tracer.OnBalanceChange(beneficiary, previousBeneficiary, current)
+		}
+	}
+	return res
+}
+
+func (c *tracingContext) RemoveAccount(address Address) {
+	RemoveAccount(c.TransactionContext, address)
+}
+
+func (c *tracingContext) EmitLog(log Log) {
+	c.TransactionContext.EmitLog(log)
+	c.tracer.OnLog(log)
+}
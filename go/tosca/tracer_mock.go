@@ -0,0 +1,145 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: tracer.go
+//
+// Generated by this command:
+//
+//	mockgen -source tracer.go -destination tracer_mock.go -package tosca
+//
+
+// Package tosca is a generated GoMock package.
+package tosca
+
+import (
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTracer is a mock of Tracer interface.
+type MockTracer struct {
+	ctrl     *gomock.Controller
+	recorder *MockTracerMockRecorder
+}
+
+// MockTracerMockRecorder is the mock recorder for MockTracer.
+type MockTracerMockRecorder struct {
+	mock *MockTracer
+}
+
+// NewMockTracer creates a new mock instance.
+func NewMockTracer(ctrl *gomock.Controller) *MockTracer {
+	mock := &MockTracer{ctrl: ctrl}
+	mock.recorder = &MockTracerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTracer) EXPECT() *MockTracerMockRecorder {
+	return m.recorder
+}
+
+// OnBalanceChange mocks base method.
+func (m *MockTracer) OnBalanceChange(address Address, previous, current Value) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnBalanceChange", address, previous, current)
+}
+
+// OnBalanceChange indicates an expected call of OnBalanceChange.
+func (mr *MockTracerMockRecorder) OnBalanceChange(address, previous, current any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnBalanceChange", reflect.TypeOf((*MockTracer)(nil).OnBalanceChange), address, previous, current)
+}
+
+// OnEnter mocks base method.
+func (m *MockTracer) OnEnter(depth int, kind CallKind, parameters CallParameters) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnEnter", depth, kind, parameters)
+}
+
+// OnEnter indicates an expected call of OnEnter.
+func (mr *MockTracerMockRecorder) OnEnter(depth, kind, parameters any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnEnter", reflect.TypeOf((*MockTracer)(nil).OnEnter), depth, kind, parameters)
+}
+
+// OnExit mocks base method.
+func (m *MockTracer) OnExit(depth int, result CallResult, err error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnExit", depth, result, err)
+}
+
+// OnExit indicates an expected call of OnExit.
+func (mr *MockTracerMockRecorder) OnExit(depth, result, err any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnExit", reflect.TypeOf((*MockTracer)(nil).OnExit), depth, result, err)
+}
+
+// OnLog mocks base method.
+func (m *MockTracer) OnLog(arg0 Log) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnLog", arg0)
+}
+
+// OnLog indicates an expected call of OnLog.
+func (mr *MockTracerMockRecorder) OnLog(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnLog", reflect.TypeOf((*MockTracer)(nil).OnLog), arg0)
+}
+
+// OnOpcode mocks base method.
+func (m *MockTracer) OnOpcode(arg0 OpCodeState) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnOpcode", arg0)
+}
+
+// OnOpcode indicates an expected call of OnOpcode.
+func (mr *MockTracerMockRecorder) OnOpcode(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnOpcode", reflect.TypeOf((*MockTracer)(nil).OnOpcode), arg0)
+}
+
+// OnStorageChange mocks base method.
+func (m *MockTracer) OnStorageChange(address Address, key Key, previous, current Word) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnStorageChange", address, key, previous, current)
+}
+
+// OnStorageChange indicates an expected call of OnStorageChange.
+func (mr *MockTracerMockRecorder) OnStorageChange(address, key, previous, current any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnStorageChange", reflect.TypeOf((*MockTracer)(nil).OnStorageChange), address, key, previous, current)
+}
+
+// OnTxEnd mocks base method.
+func (m *MockTracer) OnTxEnd(arg0 Receipt, arg1 error) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnTxEnd", arg0, arg1)
+}
+
+// OnTxEnd indicates an expected call of OnTxEnd.
+func (mr *MockTracerMockRecorder) OnTxEnd(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnTxEnd", reflect.TypeOf((*MockTracer)(nil).OnTxEnd), arg0, arg1)
+}
+
+// OnTxStart mocks base method.
+func (m *MockTracer) OnTxStart(arg0 BlockParameters, arg1 Transaction) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "OnTxStart", arg0, arg1)
+}
+
+// OnTxStart indicates an expected call of OnTxStart.
+func (mr *MockTracerMockRecorder) OnTxStart(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnTxStart", reflect.TypeOf((*MockTracer)(nil).OnTxStart), arg0, arg1)
+}
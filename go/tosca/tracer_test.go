@@ -0,0 +1,89 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestNoOpTracer_ImplementsTracer(t *testing.T) {
+	var _ Tracer = NoOpTracer{}
+}
+
+func TestTracingContext_StorageChangesAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+	tracer := NewMockTracer(ctrl)
+
+	address, key := Address{1}, Key{2}
+	gomock.InOrder(
+		context.EXPECT().GetStorage(address, key).Return(Word{3}),
+		context.EXPECT().SetStorage(address, key, Word{4}).Return(StorageModified),
+		tracer.EXPECT().OnStorageChange(address, key, Word{3}, Word{4}),
+	)
+
+	traced := NewTracingTransactionContext(context, tracer)
+	if want, got := StorageModified, traced.SetStorage(address, key, Word{4}); want != got {
+		t.Errorf("unexpected storage status, wanted %v, got %v", want, got)
+	}
+}
+
+func TestTracingContext_BalanceChangesAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+	tracer := NewMockTracer(ctrl)
+
+	address := Address{1}
+	gomock.InOrder(
+		context.EXPECT().GetBalance(address).Return(NewValue(1)),
+		context.EXPECT().SetBalance(address, NewValue(2)),
+		tracer.EXPECT().OnBalanceChange(address, NewValue(1), NewValue(2)),
+	)
+
+	NewTracingTransactionContext(context, tracer).SetBalance(address, NewValue(2))
+}
+
+func TestTracingContext_SelfDestructReportsBalanceTransfer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+	tracer := NewMockTracer(ctrl)
+
+	address, beneficiary := Address{1}, Address{2}
+	gomock.InOrder(
+		context.EXPECT().GetBalance(address).Return(NewValue(5)),
+		context.EXPECT().GetBalance(beneficiary).Return(NewValue(1)),
+		context.EXPECT().SelfDestruct(address, beneficiary).Return(true),
+		context.EXPECT().GetBalance(address).Return(NewValue(0)),
+		tracer.EXPECT().OnBalanceChange(address, NewValue(5), NewValue(0)),
+		context.EXPECT().GetBalance(beneficiary).Return(NewValue(6)),
+		tracer.EXPECT().OnBalanceChange(beneficiary, NewValue(1), NewValue(6)),
+	)
+
+	if !NewTracingTransactionContext(context, tracer).SelfDestruct(address, beneficiary) {
+		t.Errorf("unexpected result of self-destruct")
+	}
+}
+
+func TestTracingContext_LogsAreReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockTransactionContext(ctrl)
+	tracer := NewMockTracer(ctrl)
+
+	log := Log{Address: Address{1}, Data: []byte{2}}
+	gomock.InOrder(
+		context.EXPECT().EmitLog(log),
+		tracer.EXPECT().OnLog(log),
+	)
+
+	NewTracingTransactionContext(context, tracer).EmitLog(log)
+}
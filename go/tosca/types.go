@@ -16,6 +16,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math"
 	"math/big"
 	"math/bits"
 	"strings"
@@ -116,6 +117,68 @@ func Sub(a, b Value) (z Value) {
 	return z
 }
 
+// Mul returns the product of a and b, truncated to 256 bits.
+func Mul(a, b Value) Value {
+	return ValueFromUint256(new(uint256.Int).Mul(a.ToUint256(), b.ToUint256()))
+}
+
+// Div returns the quotient of a and b, rounded towards zero. Following the
+// semantics of the EVM, the result is zero if b is zero.
+func Div(a, b Value) Value {
+	return ValueFromUint256(new(uint256.Int).Div(a.ToUint256(), b.ToUint256()))
+}
+
+// AddOverflow returns the sum of a and b, truncated to 256 bits, and whether
+// the addition overflowed.
+func AddOverflow(a, b Value) (Value, bool) {
+	res, overflow := new(uint256.Int).AddOverflow(a.ToUint256(), b.ToUint256())
+	return ValueFromUint256(res), overflow
+}
+
+// SubOverflow returns the difference of a and b, wrapped around if b is
+// greater than a, and whether the subtraction underflowed.
+func SubOverflow(a, b Value) (Value, bool) {
+	res, underflow := new(uint256.Int).SubOverflow(a.ToUint256(), b.ToUint256())
+	return ValueFromUint256(res), underflow
+}
+
+// MulOverflow returns the product of a and b, truncated to 256 bits, and
+// whether the multiplication overflowed.
+func MulOverflow(a, b Value) (Value, bool) {
+	res, overflow := new(uint256.Int).MulOverflow(a.ToUint256(), b.ToUint256())
+	return ValueFromUint256(res), overflow
+}
+
+// SaturatingAdd returns the sum of a and b, or the maximum Value if the sum
+// exceeds it.
+func SaturatingAdd(a, b Value) Value {
+	if res, overflow := AddOverflow(a, b); !overflow {
+		return res
+	}
+	return maxValue
+}
+
+// SaturatingSub returns the difference of a and b, or zero if b is greater
+// than a.
+func SaturatingSub(a, b Value) Value {
+	if res, underflow := SubOverflow(a, b); !underflow {
+		return res
+	}
+	return Value{}
+}
+
+// SaturatingMul returns the product of a and b, or the maximum Value if the
+// product exceeds it.
+func SaturatingMul(a, b Value) Value {
+	if res, overflow := MulOverflow(a, b); !overflow {
+		return res
+	}
+	return maxValue
+}
+
+// maxValue is the largest value representable by a Value.
+var maxValue = NewValue(math.MaxUint64, math.MaxUint64, math.MaxUint64, math.MaxUint64)
+
 func (v Value) Scale(s uint64) Value {
 	sU256 := new(uint256.Int).SetUint64(s)
 	return ValueFromUint256(new(uint256.Int).Mul(v.ToUint256(), sU256))
@@ -288,6 +288,91 @@ func TestValue_Arithmetic(t *testing.T) {
 	}
 }
 
+func TestValue_ArithmeticMulAndDiv(t *testing.T) {
+	values := []Value{
+		{}, {1}, {2},
+		NewValue(1), NewValue(2), NewValue(3),
+		NewValue(math.MaxInt64),
+		NewValue(math.MaxUint64),
+		NewValue(1, 0),
+		maxValue,
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			want := new(uint256.Int).Mul(a.ToUint256(), b.ToUint256())
+			got := Mul(a, b).ToUint256()
+			if want.Cmp(got) != 0 {
+				t.Errorf("unexpected multiplication result for %v and %v, wanted %v, got %v", a, b, want, got)
+			}
+
+			want = new(uint256.Int).Div(a.ToUint256(), b.ToUint256())
+			got = Div(a, b).ToUint256()
+			if want.Cmp(got) != 0 {
+				t.Errorf("unexpected division result for %v and %v, wanted %v, got %v", a, b, want, got)
+			}
+		}
+	}
+}
+
+func TestValue_DivisionByZeroIsZero(t *testing.T) {
+	if want, got := (Value{}), Div(NewValue(42), Value{}); want != got {
+		t.Errorf("unexpected division result, wanted %v, got %v", want, got)
+	}
+}
+
+func TestValue_OverflowDetectingArithmetic(t *testing.T) {
+	const max64 = math.MaxUint64
+	tests := map[string]struct {
+		op       func(a, b Value) (Value, bool)
+		a, b     Value
+		want     Value
+		overflow bool
+	}{
+		"add":           {AddOverflow, NewValue(1), NewValue(2), NewValue(3), false},
+		"add carry":     {AddOverflow, NewValue(max64), NewValue(1), NewValue(1, 0), false},
+		"add overflow":  {AddOverflow, maxValue, NewValue(1), Value{}, true},
+		"sub":           {SubOverflow, NewValue(3), NewValue(2), NewValue(1), false},
+		"sub to zero":   {SubOverflow, NewValue(3), NewValue(3), Value{}, false},
+		"sub underflow": {SubOverflow, NewValue(0), NewValue(1), maxValue, true},
+		"mul":           {MulOverflow, NewValue(3), NewValue(2), NewValue(6), false},
+		"mul carry":     {MulOverflow, NewValue(max64), NewValue(2), NewValue(1, max64-1), false},
+		"mul overflow":  {MulOverflow, NewValue(1<<63, 0, 0, 0), NewValue(2), Value{}, true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, overflow := test.op(test.a, test.b)
+			if got != test.want || overflow != test.overflow {
+				t.Errorf("unexpected result, wanted %v (overflow %t), got %v (overflow %t)", test.want, test.overflow, got, overflow)
+			}
+		})
+	}
+}
+
+func TestValue_SaturatingArithmetic(t *testing.T) {
+	tests := map[string]struct {
+		op   func(a, b Value) Value
+		a, b Value
+		want Value
+	}{
+		"add":           {SaturatingAdd, NewValue(1), NewValue(2), NewValue(3)},
+		"add saturates": {SaturatingAdd, maxValue, NewValue(2), maxValue},
+		"sub":           {SaturatingSub, NewValue(3), NewValue(2), NewValue(1)},
+		"sub saturates": {SaturatingSub, NewValue(2), NewValue(3), Value{}},
+		"mul":           {SaturatingMul, NewValue(3), NewValue(2), NewValue(6)},
+		"mul saturates": {SaturatingMul, NewValue(1, 0, 0, 0), NewValue(1, 0), maxValue},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if want, got := test.want, test.op(test.a, test.b); want != got {
+				t.Errorf("unexpected result, wanted %v, got %v", want, got)
+			}
+		})
+	}
+}
+
 func TestValue_ArithmeticAddCarry(t *testing.T) {
 
 	const max64 = math.MaxUint64
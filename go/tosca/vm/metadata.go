@@ -0,0 +1,154 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package vm
+
+// Revision identifies a revision of the EVM. Its values match those of
+// tosca.Revision, which can not be referenced by this package since the tosca
+// package depends on it. Values of tosca.Revision may thus be converted using
+// vm.Revision(revision).
+type Revision int
+
+const (
+	Istanbul Revision = iota
+	Berlin
+	London
+	Paris
+	Shanghai
+	Cancun
+)
+
+// OpCodeInfo summarizes the properties of an operation shared by all
+// interpreters. It is intended to be used by tools processing EVM code, like
+// tracers, disassemblers, and test generators, instead of maintaining their
+// own tables.
+type OpCodeInfo struct {
+	Name       string
+	Introduced Revision // the first revision supporting the operation
+	Inputs     int      // the number of elements taken from the stack
+	Outputs    int      // the number of elements pushed on the stack
+	Terminal   bool     // true if the operation ends the execution of the code
+
+	staticGas       uint64
+	staticGasBerlin uint64 // the static gas since Berlin (EIP-2929)
+}
+
+// Info returns the properties of the given operation. Properties are
+// available for all operations considered valid by IsValid and for
+// INVALID. For all other op codes, false is returned.
+func (op OpCode) Info() (OpCodeInfo, bool) {
+	info := _opCodeInfos[op]
+	if info == nil {
+		return OpCodeInfo{}, false
+	}
+	return *info, true
+}
+
+// IsAvailableIn determines whether the operation is supported by the given
+// revision.
+func (i OpCodeInfo) IsAvailableIn(revision Revision) bool {
+	return i.Introduced <= revision
+}
+
+// StaticGas returns the gas charged for the operation in the given revision
+// independently of its operands and the state. Costs depending on those,
+// including the costs for accessing accounts and storage slots introduced
+// by EIP-2929 with Berlin, are not included.
+func (i OpCodeInfo) StaticGas(revision Revision) uint64 {
+	if revision >= Berlin {
+		return i.staticGasBerlin
+	}
+	return i.staticGas
+}
+
+var _opCodeInfos = initOpCodeInfos()
+
+func initOpCodeInfos() [256]*OpCodeInfo {
+	res := [256]*OpCodeInfo{}
+	add := func(gas uint64, inputs, outputs int, ops ...OpCode) {
+		for _, op := range ops {
+			res[op] = &OpCodeInfo{
+				Name:            op.String(),
+				Inputs:          inputs,
+				Outputs:         outputs,
+				staticGas:       gas,
+				staticGasBerlin: gas,
+			}
+		}
+	}
+
+	add(0, 0, 0, STOP, INVALID)
+	add(0, 2, 0, RETURN, REVERT)
+	add(5000, 1, 0, SELFDESTRUCT)
+
+	add(3, 2, 1, ADD, SUB, LT, GT, SLT, SGT, EQ, AND, OR, XOR, BYTE, SHL, SHR, SAR)
+	add(3, 1, 1, ISZERO, NOT)
+	add(5, 2, 1, MUL, DIV, SDIV, MOD, SMOD, SIGNEXTEND)
+	add(8, 3, 1, ADDMOD, MULMOD)
+	add(10, 2, 1, EXP)
+	add(30, 2, 1, SHA3)
+
+	add(2, 0, 1, ADDRESS, ORIGIN, CALLER, CALLVALUE, CALLDATASIZE, CODESIZE,
+		GASPRICE, RETURNDATASIZE, COINBASE, TIMESTAMP, NUMBER, PREVRANDAO,
+		GASLIMIT, CHAINID, BASEFEE, BLOBBASEFEE, PC, MSIZE, GAS, PUSH0)
+	add(3, 1, 1, CALLDATALOAD, MLOAD, BLOBHASH)
+	add(3, 3, 0, CALLDATACOPY, CODECOPY, RETURNDATACOPY, MCOPY)
+	add(5, 0, 1, SELFBALANCE)
+	add(20, 1, 1, BLOCKHASH)
+	add(700, 1, 1, BALANCE, EXTCODESIZE, EXTCODEHASH)
+	add(700, 4, 0, EXTCODECOPY)
+
+	add(2, 1, 0, POP)
+	add(3, 2, 0, MSTORE, MSTORE8)
+	add(800, 1, 1, SLOAD)
+	add(0, 2, 0, SSTORE)
+	add(100, 1, 1, TLOAD)
+	add(100, 2, 0, TSTORE)
+	add(8, 1, 0, JUMP)
+	add(10, 2, 0, JUMPI)
+	add(1, 0, 0, JUMPDEST)
+
+	for op := PUSH1; op <= PUSH32; op++ {
+		add(3, 0, 1, op)
+	}
+	for i := 0; i < 16; i++ {
+		add(3, i+1, i+2, DUP1+OpCode(i))
+		add(3, i+2, i+2, SWAP1+OpCode(i))
+	}
+	for i := 0; i <= 4; i++ {
+		add(375*uint64(i+1), i+2, 0, LOG0+OpCode(i))
+	}
+
+	add(32000, 3, 1, CREATE)
+	add(32000, 4, 1, CREATE2)
+	add(700, 7, 1, CALL, CALLCODE)
+	add(700, 6, 1, DELEGATECALL, STATICCALL)
+
+	// Operations introduced after Istanbul.
+	res[BASEFEE].Introduced = London
+	res[PUSH0].Introduced = Shanghai
+	for _, op := range []OpCode{BLOBHASH, BLOBBASEFEE, TLOAD, TSTORE, MCOPY} {
+		res[op].Introduced = Cancun
+	}
+
+	// Accessing accounts and storage slots is charged dynamically since
+	// Berlin (EIP-2929).
+	for _, op := range []OpCode{
+		BALANCE, EXTCODESIZE, EXTCODECOPY, EXTCODEHASH, SLOAD,
+		CALL, CALLCODE, DELEGATECALL, STATICCALL,
+	} {
+		res[op].staticGasBerlin = 0
+	}
+
+	for _, op := range []OpCode{STOP, RETURN, REVERT, INVALID, SELFDESTRUCT} {
+		res[op].Terminal = true
+	}
+	return res
+}
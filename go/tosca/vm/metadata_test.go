@@ -0,0 +1,104 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package vm_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+)
+
+func TestRevision_MatchesToscaRevisions(t *testing.T) {
+	revisions := map[vm.Revision]tosca.Revision{
+		vm.Istanbul: tosca.R07_Istanbul,
+		vm.Berlin:   tosca.R09_Berlin,
+		vm.London:   tosca.R10_London,
+		vm.Paris:    tosca.R11_Paris,
+		vm.Shanghai: tosca.R12_Shanghai,
+		vm.Cancun:   tosca.R13_Cancun,
+	}
+	if want, got := len(tosca.GetAllKnownRevisions()), len(revisions); want != got {
+		t.Errorf("unexpected number of revisions, wanted %d, got %d", want, got)
+	}
+	for revision, want := range revisions {
+		if got := tosca.Revision(revision); want != got {
+			t.Errorf("unexpected value of %v, wanted %d, got %d", want, int(want), int(got))
+		}
+	}
+}
+
+func TestOpCode_InfoIsAvailableForAllValidOpCodes(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		info, found := op.Info()
+		if want := vm.IsValid(op) || op == vm.INVALID; want != found {
+			t.Errorf("unexpected availability of info for %v, wanted %t, got %t", op, want, found)
+		}
+		if found && info.Name != op.String() {
+			t.Errorf("unexpected name of %v, got %s", op, info.Name)
+		}
+	}
+}
+
+func TestOpCodeInfo_IsAvailableInRevisionsStartingWithIntroduction(t *testing.T) {
+	introduced := map[vm.OpCode]vm.Revision{
+		vm.ADD:         vm.Istanbul,
+		vm.SELFBALANCE: vm.Istanbul,
+		vm.BASEFEE:     vm.London,
+		vm.PUSH0:       vm.Shanghai,
+		vm.TSTORE:      vm.Cancun,
+		vm.MCOPY:       vm.Cancun,
+	}
+	for op, revision := range introduced {
+		info, _ := op.Info()
+		if want, got := revision, info.Introduced; want != got {
+			t.Errorf("unexpected introduction of %v, wanted %v, got %v", op, want, got)
+		}
+		for _, r := range tosca.GetAllKnownRevisions() {
+			if want, got := vm.Revision(r) >= revision, info.IsAvailableIn(vm.Revision(r)); want != got {
+				t.Errorf("unexpected availability of %v in %v, wanted %t, got %t", op, r, want, got)
+			}
+		}
+	}
+}
+
+func TestOpCodeInfo_StaticGasOfAccessingOperationsChangesWithBerlin(t *testing.T) {
+	tests := map[vm.OpCode][2]uint64{
+		vm.ADD:         {3, 3},
+		vm.SLOAD:       {800, 0},
+		vm.BALANCE:     {700, 0},
+		vm.CALL:        {700, 0},
+		vm.LOG2:        {1125, 1125},
+		vm.SELFBALANCE: {5, 5},
+	}
+	for op, gas := range tests {
+		info, _ := op.Info()
+		if want, got := gas[0], info.StaticGas(vm.Istanbul); want != got {
+			t.Errorf("unexpected static gas of %v in Istanbul, wanted %d, got %d", op, want, got)
+		}
+		if want, got := gas[1], info.StaticGas(vm.Cancun); want != got {
+			t.Errorf("unexpected static gas of %v in Cancun, wanted %d, got %d", op, want, got)
+		}
+	}
+}
+
+func TestOpCodeInfo_TerminalOperations(t *testing.T) {
+	terminal := []vm.OpCode{vm.STOP, vm.RETURN, vm.REVERT, vm.INVALID, vm.SELFDESTRUCT}
+	for i := 0; i < 256; i++ {
+		op := vm.OpCode(i)
+		info, _ := op.Info()
+		if want := slices.Contains(terminal, op); want != info.Terminal {
+			t.Errorf("unexpected terminal flag of %v, wanted %t, got %t", op, want, info.Terminal)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"log/slog"
+)
+
+// VotingConfig configures an interpreter created by NewVotingInterpreter.
+type VotingConfig struct {
+	// Interpreters lists the names of the registered interpreters taking
+	// part in the vote. The order is relevant for breaking ties and for
+	// selecting the interpreter executing calls that are not voted on.
+	Interpreters []string
+	// Quorum is the minimum number of interpreters required to agree on
+	// the result of a call. If zero, a simple majority is required.
+	Quorum int
+	// VotingDepth, if positive, limits the vote to calls with a depth below
+	// the given value. Deeper calls are only executed by the first
+	// interpreter. Since every candidate re-runs all nested calls, the costs
+	// of voting grow exponentially with the call depth otherwise.
+	VotingDepth int
+	// OnDivergence, if not nil, is called for every interpreter disagreeing
+	// with the accepted result. If nil, divergences are logged as warnings.
+	OnDivergence func(VotingDivergence)
+	// Logger is the logger divergences are reported to if no OnDivergence
+	// callback is set. If nil, the default logger of package slog is used.
+	Logger *slog.Logger
+}
+
+// VotingDivergence describes the disagreement of a single interpreter with
+// the result accepted by the quorum.
+type VotingDivergence struct {
+	Interpreter string // the name of the diverging interpreter
+	Depth       int    // the depth of the call in which the divergence happened
+	Accepted    Result // the result agreed upon by the quorum
+	Result      Result // the result produced by the diverging interpreter
+	Err         error  // the error produced by the diverging interpreter
+}
+
+func (d VotingDivergence) String() string {
+	return fmt.Sprintf(
+		"interpreter %s diverged at depth %d: accepted %v, got %v, error %v",
+		d.Interpreter, d.Depth, d.Accepted, d.Result, d.Err,
+	)
+}
+
+// ErrNoQuorum is reported by voting interpreters if the participating
+// interpreters failed to reach the quorum for the result of a call.
+type ErrNoQuorum struct {
+	Depth     int // the depth of the call without a quorum
+	Agreement int // the size of the largest group of agreeing interpreters
+	Quorum    int // the required number of agreeing interpreters
+}
+
+func (e *ErrNoQuorum) Error() string {
+	return fmt.Sprintf(
+		"no quorum at depth %d: %d interpreters agreed, %d required",
+		e.Depth, e.Agreement, e.Quorum,
+	)
+}
+
+// NewVotingInterpreter creates an interpreter running each call on all of the
+// configured interpreters and accepting a result only if a quorum of them
+// agrees on it. Results agree if they match in their success, output, gas
+// left, gas refund, and error, and if the interpreters performed the same
+// sequence of state modifications and nested calls on the run context.
+//
+// Each interpreter runs on a snapshot of the run context, which is restored
+// afterwards, such that only the modifications of an interpreter in the
+// quorum are retained. If the last interpreter to run is not part of the
+// quorum, the call is executed once more by the first interpreter of the
+// quorum. Interpreters disagreeing with the quorum are reported through the
+// OnDivergence callback of the configuration. If no quorum is reached, the
+// call fails with an ErrNoQuorum.
+//
+// Voting is intended for high-assurance deployments willing to pay the costs
+// of running multiple interpreters. Interpreters are run sequentially, in the
+// configured order, so that the outcome is deterministic.
+func NewVotingInterpreter(config VotingConfig) (Interpreter, error) {
+	candidates := make([]votingCandidate, 0, len(config.Interpreters))
+	for _, name := range config.Interpreters {
+		interpreter, err := NewInterpreter(name)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, votingCandidate{name, interpreter})
+	}
+	return newVotingInterpreter(config, candidates)
+}
+
+type votingCandidate struct {
+	name        string
+	interpreter Interpreter
+}
+
+func newVotingInterpreter(config VotingConfig, candidates []votingCandidate) (*votingInterpreter, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("invalid voting configuration: no interpreters")
+	}
+	quorum := config.Quorum
+	if quorum == 0 {
+		quorum = len(candidates)/2 + 1
+	}
+	if quorum < 0 || quorum > len(candidates) {
+		return nil, fmt.Errorf(
+			"invalid voting configuration: quorum %d for %d interpreters",
+			config.Quorum, len(candidates),
+		)
+	}
+	onDivergence := config.OnDivergence
+	if onDivergence == nil {
+		logger := config.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		onDivergence = func(d VotingDivergence) {
+			logger.Warn("voting interpreter diverged",
+				"interpreter", d.Interpreter,
+				"depth", d.Depth,
+				"accepted", d.Accepted,
+				"result", d.Result,
+				"error", d.Err,
+			)
+		}
+	}
+	return &votingInterpreter{
+		candidates:   candidates,
+		quorum:       quorum,
+		depth:        config.VotingDepth,
+		onDivergence: onDivergence,
+	}, nil
+}
+
+type votingInterpreter struct {
+	candidates   []votingCandidate
+	quorum       int
+	depth        int
+	onDivergence func(VotingDivergence)
+}
+
+// vote is the outcome of running a call on a single interpreter.
+type vote struct {
+	result Result
+	err    error
+	digest [sha256.Size]byte
+}
+
+func (i *votingInterpreter) Run(params Parameters) (Result, error) {
+	if i.depth > 0 && params.Depth >= i.depth {
+		return i.candidates[0].interpreter.Run(params)
+	}
+
+	votes := make([]vote, len(i.candidates))
+	context := params.Context
+	var snapshot Snapshot
+	for j, candidate := range i.candidates {
+		snapshot = context.CreateSnapshot()
+		journal := &journalingContext{RunContext: context, hash: sha256.New()}
+		params.Context = journal
+		result, err := runAndFinalize(candidate.interpreter, params)
+		votes[j] = vote{result: result, err: err, digest: journal.digest(result, err)}
+		if j < len(i.candidates)-1 {
+			context.RestoreSnapshot(snapshot)
+		}
+	}
+
+	// Select the largest group of agreeing votes. On ties, the group of the
+	// earliest interpreter wins.
+	counts := map[[sha256.Size]byte]int{}
+	for _, vote := range votes {
+		counts[vote.digest]++
+	}
+	winner := 0
+	for j, vote := range votes {
+		if counts[vote.digest] > counts[votes[winner].digest] {
+			winner = j
+		}
+	}
+	accepted := votes[winner]
+	if agreement := counts[accepted.digest]; agreement < i.quorum {
+		return Result{}, &ErrNoQuorum{
+			Depth:     params.Depth,
+			Agreement: agreement,
+			Quorum:    i.quorum,
+		}
+	}
+
+	for j, vote := range votes {
+		if vote.digest != accepted.digest {
+			i.onDivergence(VotingDivergence{
+				Interpreter: i.candidates[j].name,
+				Depth:       params.Depth,
+				Accepted:    accepted.result,
+				Result:      vote.result,
+				Err:         vote.err,
+			})
+		}
+	}
+
+	// Only the effects of the last run are retained in the run context.
+	if last := votes[len(votes)-1]; last.digest != accepted.digest {
+		context.RestoreSnapshot(snapshot)
+		params.Context = context
+		return runAndFinalize(i.candidates[winner].interpreter, params)
+	}
+	return accepted.result, accepted.err
+}
+
+// runAndFinalize runs the given interpreter and applies a reported
+// self-destruction to the context, such that the effects of interpreters
+// reporting self-destructions and interpreters applying them agree.
+func runAndFinalize(interpreter Interpreter, params Parameters) (Result, error) {
+	result, err := interpreter.Run(params)
+	if err == nil {
+		FinalizeSelfDestruction(params.Context, params.Recipient, result.SelfDestruction)
+		result.SelfDestruction = nil
+	}
+	return result, err
+}
+
+// journalingContext is a RunContext summarizing all state modifications and
+// nested calls performed through it in a hash.
+type journalingContext struct {
+	RunContext
+	hash hash.Hash
+}
+
+func (c *journalingContext) write(operation string, data ...[]byte) {
+	c.hash.Write([]byte(operation))
+	for _, cur := range data {
+		c.hash.Write(binary.BigEndian.AppendUint64(nil, uint64(len(cur))))
+		c.hash.Write(cur)
+	}
+}
+
+func (c *journalingContext) digest(result Result, err error) [sha256.Size]byte {
+	success := []byte{0}
+	if result.Success {
+		success[0] = 1
+	}
+	c.write("Result", success, result.Output,
+		binary.BigEndian.AppendUint64(nil, uint64(result.GasLeft)),
+		binary.BigEndian.AppendUint64(nil, uint64(result.GasRefund)),
+	)
+	if err != nil {
+		c.write("Error", []byte(err.Error()))
+	}
+	return [sha256.Size]byte(c.hash.Sum(nil))
+}
+
+func (c *journalingContext) SetBalance(address Address, value Value) {
+	c.write("SetBalance", address[:], value[:])
+	c.RunContext.SetBalance(address, value)
+}
+
+func (c *journalingContext) SetNonce(address Address, nonce uint64) {
+	c.write("SetNonce", address[:], binary.BigEndian.AppendUint64(nil, nonce))
+	c.RunContext.SetNonce(address, nonce)
+}
+
+func (c *journalingContext) SetCode(address Address, code Code) {
+	c.write("SetCode", address[:], code)
+	c.RunContext.SetCode(address, code)
+}
+
+func (c *journalingContext) SetStorage(address Address, key Key, value Word) StorageStatus {
+	c.write("SetStorage", address[:], key[:], value[:])
+	return c.RunContext.SetStorage(address, key, value)
+}
+
+func (c *journalingContext) SetTransientStorage(address Address, key Key, value Word) {
+	c.write("SetTransientStorage", address[:], key[:], value[:])
+	c.RunContext.SetTransientStorage(address, key, value)
+}
+
+func (c *journalingContext) EmitLog(log Log) {
+	data := [][]byte{log.Address[:], log.Data}
+	for _, topic := range log.Topics {
+		data = append(data, topic[:])
+	}
+	c.write("EmitLog", data...)
+	c.RunContext.EmitLog(log)
+}
+
+func (c *journalingContext) SelfDestruct(address Address, beneficiary Address) bool {
+	c.write("SelfDestruct", address[:], beneficiary[:])
+	return c.RunContext.SelfDestruct(address, beneficiary)
+}
+
+func (c *journalingContext) RestoreSnapshot(snapshot Snapshot) {
+	// Snapshot identifiers differ between runs and are thus not recorded.
+	c.write("RestoreSnapshot")
+	c.RunContext.RestoreSnapshot(snapshot)
+}
+
+func (c *journalingContext) Call(kind CallKind, parameters CallParameters) (CallResult, error) {
+	c.write("Call",
+		binary.BigEndian.AppendUint64(nil, uint64(kind)),
+		parameters.Sender[:],
+		parameters.Recipient[:],
+		parameters.Value[:],
+		parameters.Input,
+		binary.BigEndian.AppendUint64(nil, uint64(parameters.Gas)),
+		parameters.Salt[:],
+		parameters.CodeAddress[:],
+	)
+	return c.RunContext.Call(kind, parameters)
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+func newVotingTestCandidates(ctrl *gomock.Controller, names ...string) ([]votingCandidate, []*MockInterpreter) {
+	candidates := []votingCandidate{}
+	mocks := []*MockInterpreter{}
+	for _, name := range names {
+		mock := NewMockInterpreter(ctrl)
+		candidates = append(candidates, votingCandidate{name, mock})
+		mocks = append(mocks, mock)
+	}
+	return candidates, mocks
+}
+
+func TestVotingInterpreter_AgreeingResultIsAccepted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	want := Result{Success: true, Output: Data{1, 2}, GasLeft: 5}
+	for _, interpreter := range interpreters {
+		interpreter.EXPECT().Run(gomock.Any()).Return(want, nil)
+	}
+	gomock.InOrder(
+		context.EXPECT().CreateSnapshot().Return(Snapshot(1)),
+		context.EXPECT().RestoreSnapshot(Snapshot(1)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(2)),
+		context.EXPECT().RestoreSnapshot(Snapshot(2)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(3)),
+	)
+
+	voting, err := newVotingInterpreter(VotingConfig{
+		OnDivergence: func(d VotingDivergence) {
+			t.Errorf("unexpected divergence: %v", d)
+		},
+	}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	got, err := voting.Run(Parameters{Context: context})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.Success != got.Success || want.GasLeft != got.GasLeft || !slices.Equal(want.Output, got.Output) {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+}
+
+func TestVotingInterpreter_MinorityDivergenceIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	want := Result{Success: true, GasLeft: 5}
+	diverging := Result{Success: true, GasLeft: 4}
+	interpreters[0].EXPECT().Run(gomock.Any()).Return(diverging, nil)
+	interpreters[1].EXPECT().Run(gomock.Any()).Return(want, nil)
+	interpreters[2].EXPECT().Run(gomock.Any()).Return(want, nil)
+	context.EXPECT().CreateSnapshot().Return(Snapshot(1)).Times(3)
+	context.EXPECT().RestoreSnapshot(Snapshot(1)).Times(2)
+
+	divergences := []VotingDivergence{}
+	voting, err := newVotingInterpreter(VotingConfig{
+		OnDivergence: func(d VotingDivergence) {
+			divergences = append(divergences, d)
+		},
+	}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	got, err := voting.Run(Parameters{Context: context, Depth: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.GasLeft != got.GasLeft {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+	if want, got := 1, len(divergences); want != got {
+		t.Fatalf("unexpected number of divergences, wanted %d, got %d", want, got)
+	}
+	divergence := divergences[0]
+	if divergence.Interpreter != "a" || divergence.Depth != 1 ||
+		divergence.Accepted.GasLeft != want.GasLeft || divergence.Result.GasLeft != diverging.GasLeft {
+		t.Errorf("unexpected divergence: %v", divergence)
+	}
+}
+
+func TestVotingInterpreter_DivergencesAreLoggedIfNoCallbackIsSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	want := Result{Success: true, GasLeft: 5}
+	interpreters[0].EXPECT().Run(gomock.Any()).Return(Result{Success: true, GasLeft: 4}, nil)
+	interpreters[1].EXPECT().Run(gomock.Any()).Return(want, nil)
+	interpreters[2].EXPECT().Run(gomock.Any()).Return(want, nil)
+	context.EXPECT().CreateSnapshot().Return(Snapshot(1)).Times(3)
+	context.EXPECT().RestoreSnapshot(Snapshot(1)).Times(2)
+
+	var buffer bytes.Buffer
+	voting, err := newVotingInterpreter(VotingConfig{
+		Logger: slog.New(slog.NewTextHandler(&buffer, nil)),
+	}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	if _, err := voting.Run(Parameters{Context: context}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := buffer.String()
+	for _, want := range []string{"level=WARN", "voting interpreter diverged", "interpreter=a"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log should contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestVotingInterpreter_ResultOfLastInterpreterIsRestoredIfNotAccepted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	want := Result{Success: true, GasLeft: 5}
+	gomock.InOrder(
+		context.EXPECT().CreateSnapshot().Return(Snapshot(1)),
+		interpreters[0].EXPECT().Run(gomock.Any()).Return(Result{}, nil),
+		context.EXPECT().RestoreSnapshot(Snapshot(1)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(2)),
+		interpreters[1].EXPECT().Run(gomock.Any()).Return(want, nil),
+		context.EXPECT().RestoreSnapshot(Snapshot(2)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(3)),
+		interpreters[2].EXPECT().Run(gomock.Any()).Return(Result{}, nil),
+		// The first interpreter is agreeing with the last one, so this
+		// result is accepted without re-running any interpreter.
+	)
+
+	voting, err := newVotingInterpreter(VotingConfig{OnDivergence: func(VotingDivergence) {}}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	if _, err := voting.Run(Parameters{Context: context}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Reorder the votes such that the last interpreter is in the minority.
+	gomock.InOrder(
+		context.EXPECT().CreateSnapshot().Return(Snapshot(4)),
+		interpreters[0].EXPECT().Run(gomock.Any()).Return(want, nil),
+		context.EXPECT().RestoreSnapshot(Snapshot(4)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(5)),
+		interpreters[1].EXPECT().Run(gomock.Any()).Return(want, nil),
+		context.EXPECT().RestoreSnapshot(Snapshot(5)),
+		context.EXPECT().CreateSnapshot().Return(Snapshot(6)),
+		interpreters[2].EXPECT().Run(gomock.Any()).Return(Result{}, nil),
+		context.EXPECT().RestoreSnapshot(Snapshot(6)),
+		interpreters[0].EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+			if params.Context != context {
+				t.Errorf("re-run is expected to be performed on the original context")
+			}
+			return want, nil
+		}),
+	)
+	got, err := voting.Run(Parameters{Context: context})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want.GasLeft != got.GasLeft {
+		t.Errorf("unexpected result, wanted %v, got %v", want, got)
+	}
+}
+
+func TestVotingInterpreter_MissingQuorumIsReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	for i, interpreter := range interpreters {
+		interpreter.EXPECT().Run(gomock.Any()).Return(Result{GasLeft: Gas(i)}, nil)
+	}
+	context.EXPECT().CreateSnapshot().AnyTimes()
+	context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+
+	voting, err := newVotingInterpreter(VotingConfig{
+		Quorum:       2,
+		OnDivergence: func(VotingDivergence) {},
+	}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	_, err = voting.Run(Parameters{Context: context, Depth: 3})
+	var noQuorum *ErrNoQuorum
+	if !errors.As(err, &noQuorum) {
+		t.Fatalf("expected missing quorum to be reported, got %v", err)
+	}
+	want := ErrNoQuorum{Depth: 3, Agreement: 1, Quorum: 2}
+	if want != *noQuorum {
+		t.Errorf("unexpected error, wanted %v, got %v", &want, noQuorum)
+	}
+}
+
+func TestVotingInterpreter_StateModificationsAreVotedOn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b")
+
+	context.EXPECT().CreateSnapshot().AnyTimes()
+	context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+	context.EXPECT().SetStorage(Address{1}, Key{2}, Word{3})
+	context.EXPECT().SetStorage(Address{1}, Key{2}, Word{4})
+
+	interpreters[0].EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		params.Context.SetStorage(Address{1}, Key{2}, Word{3})
+		return Result{Success: true}, nil
+	})
+	interpreters[1].EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		params.Context.SetStorage(Address{1}, Key{2}, Word{4})
+		return Result{Success: true}, nil
+	})
+
+	voting, err := newVotingInterpreter(VotingConfig{}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	var noQuorum *ErrNoQuorum
+	if _, err := voting.Run(Parameters{Context: context}); !errors.As(err, &noQuorum) {
+		t.Errorf("expected diverging state modifications to prevent a quorum, got %v", err)
+	}
+}
+
+func TestVotingInterpreter_ReportedAndAppliedSelfDestructionsAgree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "reporting", "applying")
+
+	recipient, beneficiary := Address{1}, Address{2}
+	context.EXPECT().CreateSnapshot().AnyTimes()
+	context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+	context.EXPECT().SelfDestruct(recipient, beneficiary).Times(2)
+
+	interpreters[0].EXPECT().Run(gomock.Any()).Return(Result{
+		Success:         true,
+		SelfDestruction: &SelfDestruction{Beneficiary: beneficiary},
+	}, nil)
+	interpreters[1].EXPECT().Run(gomock.Any()).DoAndReturn(func(params Parameters) (Result, error) {
+		params.Context.SelfDestruct(params.Recipient, beneficiary)
+		return Result{Success: true}, nil
+	})
+
+	voting, err := newVotingInterpreter(VotingConfig{
+		OnDivergence: func(d VotingDivergence) {
+			t.Errorf("unexpected divergence: %v", d)
+		},
+	}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	result, err := voting.Run(Parameters{Context: context, Recipient: recipient})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.SelfDestruction != nil {
+		t.Errorf("applied self-destruction should not be reported, got %v", result.SelfDestruction)
+	}
+}
+
+func TestVotingInterpreter_ErrorsAreVotedOn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	injectedErr := errors.New("injected error")
+	interpreters[0].EXPECT().Run(gomock.Any()).Return(Result{}, injectedErr)
+	interpreters[1].EXPECT().Run(gomock.Any()).Return(Result{}, nil)
+	interpreters[2].EXPECT().Run(gomock.Any()).Return(Result{}, injectedErr)
+	context.EXPECT().CreateSnapshot().AnyTimes()
+	context.EXPECT().RestoreSnapshot(gomock.Any()).AnyTimes()
+
+	voting, err := newVotingInterpreter(VotingConfig{OnDivergence: func(VotingDivergence) {}}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	if _, err := voting.Run(Parameters{Context: context}); !errors.Is(err, injectedErr) {
+		t.Errorf("unexpected error, wanted %v, got %v", injectedErr, err)
+	}
+}
+
+func TestVotingInterpreter_CallsBeyondVotingDepthAreRunByFirstInterpreter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	context := NewMockRunContext(ctrl)
+	candidates, interpreters := newVotingTestCandidates(ctrl, "a", "b", "c")
+
+	params := Parameters{Context: context, Depth: 2}
+	interpreters[0].EXPECT().Run(params).Return(Result{Success: true}, nil)
+
+	voting, err := newVotingInterpreter(VotingConfig{VotingDepth: 2}, candidates)
+	if err != nil {
+		t.Fatalf("failed to create voting interpreter: %v", err)
+	}
+	if _, err := voting.Run(params); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestVotingInterpreter_InvalidConfigurationsAreRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	candidates, _ := newVotingTestCandidates(ctrl, "a", "b")
+
+	tests := map[string]struct {
+		config     VotingConfig
+		candidates []votingCandidate
+	}{
+		"no interpreters":  {VotingConfig{}, nil},
+		"negative quorum":  {VotingConfig{Quorum: -1}, candidates},
+		"excessive quorum": {VotingConfig{Quorum: 3}, candidates},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := newVotingInterpreter(test.config, test.candidates); err == nil {
+				t.Errorf("expected configuration to be rejected")
+			}
+		})
+	}
+}
+
+func TestNewVotingInterpreter_UnknownInterpretersAreReported(t *testing.T) {
+	config := VotingConfig{Interpreters: []string{"unknown-interpreter"}}
+	if _, err := NewVotingInterpreter(config); err == nil {
+		t.Errorf("expected unknown interpreter to be reported")
+	}
+}
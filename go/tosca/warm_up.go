@@ -0,0 +1,98 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// WarmUpTarget is implemented by interpreters able to prepare codes for their
+// execution in advance, e.g. by filling caches of analysis or conversion
+// results.
+type WarmUpTarget interface {
+	WarmUp(codes ...[]byte)
+}
+
+// CodeProvider provides access to contract codes by their hashes, e.g. backed
+// by the state database of a node.
+type CodeProvider interface {
+	// GetCodeByHash returns the code with the given hash, and false if the
+	// code is not known.
+	GetCodeByHash(Hash) (Code, bool)
+}
+
+// WarmUp prepares the given interpreter for the execution of the codes with
+// the given hashes, which are obtained from the given code provider. Node
+// operators may use this at startup to avoid latency spikes on the first
+// calls of frequently used contracts. The result is the number of codes
+// passed to the interpreter; codes unknown to the provider are skipped. An
+// error is returned if the interpreter does not support warm-ups.
+func WarmUp(interpreter Interpreter, provider CodeProvider, hashes []Hash) (int, error) {
+	target, ok := interpreter.(WarmUpTarget)
+	if !ok {
+		return 0, fmt.Errorf("interpreter of type %T does not support warm-ups", interpreter)
+	}
+	codes := make([][]byte, 0, len(hashes))
+	for _, hash := range hashes {
+		if code, found := provider.GetCodeByHash(hash); found {
+			codes = append(codes, code)
+		}
+	}
+	target.WarmUp(codes...)
+	return len(codes), nil
+}
+
+// ReadCodeHashes parses a list of code hashes. The expected format lists one
+// 0x-prefixed, hex-encoded hash per line. Empty lines and lines starting with
+// '#' are ignored, as is any text following a hash after a '#'.
+//
+//	# top contracts
+//	0x1234...abcd
+//	0x5678...ef01  # some token
+func ReadCodeHashes(reader io.Reader) ([]Hash, error) {
+	var res []Hash
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		var hash Hash
+		if err := textToBytes(hash[:], []byte(text)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		res = append(res, hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ReadCodeHashFile parses the code hashes listed in the given file using the
+// format described by ReadCodeHashes.
+func ReadCodeHashFile(path string) ([]Hash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hashes, err := ReadCodeHashes(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return hashes, nil
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package tosca
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+)
+
+type warmUpInterpreter struct {
+	Interpreter
+	codes [][]byte
+}
+
+func (i *warmUpInterpreter) WarmUp(codes ...[]byte) {
+	i.codes = append(i.codes, codes...)
+}
+
+type codeMap map[Hash]Code
+
+func (m codeMap) GetCodeByHash(hash Hash) (Code, bool) {
+	code, found := m[hash]
+	return code, found
+}
+
+func TestWarmUp_PassesKnownCodesToInterpreter(t *testing.T) {
+	provider := codeMap{
+		{1}: Code{1, 2, 3},
+		{2}: Code{4, 5},
+	}
+	interpreter := &warmUpInterpreter{}
+	count, err := WarmUp(interpreter, provider, []Hash{{1}, {3}, {2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := 2, count; want != got {
+		t.Errorf("unexpected number of codes, wanted %d, got %d", want, got)
+	}
+	want := [][]byte{{1, 2, 3}, {4, 5}}
+	if !slices.EqualFunc(want, interpreter.codes, bytes.Equal) {
+		t.Errorf("unexpected codes, wanted %v, got %v", want, interpreter.codes)
+	}
+}
+
+func TestWarmUp_FailsForInterpretersNotSupportingWarmUps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	interpreter := NewMockInterpreter(ctrl)
+	if _, err := WarmUp(interpreter, codeMap{}, nil); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestWarmUp_DispatchingInterpreterForwardsToAllInterpreters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	first := &warmUpInterpreter{}
+	second := &warmUpInterpreter{}
+	interpreter, err := NewDispatchingInterpreter(DispatchConfig{
+		Default:    first,
+		ByCodeHash: map[Hash]Interpreter{{1}: NewMockInterpreter(ctrl)},
+		ByAddress:  []AddressRoute{{Interpreter: second}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create interpreter: %v", err)
+	}
+	if _, err := WarmUp(interpreter, codeMap{{1}: Code{1}}, []Hash{{1}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, target := range []*warmUpInterpreter{first, second} {
+		if want, got := 1, len(target.codes); want != got {
+			t.Errorf("unexpected number of codes, wanted %d, got %d", want, got)
+		}
+	}
+}
+
+func TestReadCodeHashes_ParsesListOfHashes(t *testing.T) {
+	hash1 := "0x" + strings.Repeat("01", 32)
+	hash2 := "0x" + strings.Repeat("ab", 32)
+	input := "# hot contracts\n\n" + hash1 + "\n  " + hash2 + "  # a token\n"
+	hashes, err := ReadCodeHashes(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var want1, want2 Hash
+	for i := range want1 {
+		want1[i], want2[i] = 0x01, 0xab
+	}
+	if want := []Hash{want1, want2}; !slices.Equal(want, hashes) {
+		t.Errorf("unexpected hashes, wanted %v, got %v", want, hashes)
+	}
+}
+
+func TestReadCodeHashes_ReportsLinesWithInvalidHashes(t *testing.T) {
+	inputs := []string{
+		"0x1234",
+		strings.Repeat("01", 32),
+		"0x" + strings.Repeat("zz", 32),
+	}
+	for _, input := range inputs {
+		_, err := ReadCodeHashes(strings.NewReader("# comment\n" + input))
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("unexpected error for %q: %v", input, err)
+		}
+	}
+}
+
+func TestReadCodeHashFile_ReadsHashesFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	if err := os.WriteFile(path, []byte("0x"+strings.Repeat("00", 32)+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	hashes, err := ReadCodeHashFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want, got := []Hash{{}}, hashes; !slices.Equal(want, got) {
+		t.Errorf("unexpected hashes, wanted %v, got %v", want, got)
+	}
+	if _, err := ReadCodeHashFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
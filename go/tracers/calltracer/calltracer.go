@@ -0,0 +1,272 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package calltracer provides a tosca.Tracer reconstructing the tree of calls
+// performed by a transaction. The produced call frames are encoded in the
+// same JSON format as the call frames of geth's native callTracer, such that
+// tools consuming traces of geth nodes can process them unmodified.
+package calltracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/sink"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Config controls the level of detail of recorded traces. Its JSON encoding
+// matches the configuration of geth's callTracer.
+type Config struct {
+	OnlyTopCall bool `json:"onlyTopCall"` // if true, nested calls are not recorded
+	WithLog     bool `json:"withLog"`     // if true, emitted logs are recorded
+
+	// MaxSize limits the approximate size of the JSON encoding of recorded
+	// frames in bytes. Once exceeded, the recorded frames are dropped and
+	// GetResult reports sink.ErrLimitExceeded. Zero disables the limit.
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+// Frame describes a single call or create performed during a transaction
+// including all calls nested within it.
+type Frame struct {
+	Type         string         `json:"type"`
+	From         tosca.Address  `json:"from"`
+	Gas          hexutil.Uint64 `json:"gas"`
+	GasUsed      hexutil.Uint64 `json:"gasUsed"`
+	To           *tosca.Address `json:"to,omitempty"`
+	Input        hexutil.Bytes  `json:"input"`
+	Output       hexutil.Bytes  `json:"output,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        []Frame        `json:"calls,omitempty"`
+	Logs         []Log          `json:"logs,omitempty"`
+	Value        *hexutil.Big   `json:"value,omitempty"`
+}
+
+// Log is a log emitted by the code executed in a call frame.
+type Log struct {
+	Address tosca.Address `json:"address"`
+	Topics  []common.Hash `json:"topics"`
+	Data    hexutil.Bytes `json:"data"`
+	// Position is the number of nested calls of the emitting frame completed
+	// before the log got emitted.
+	Position hexutil.Uint `json:"position"`
+}
+
+const (
+	errReverted = "execution reverted"
+	errFailed   = "execution failed"
+)
+
+const (
+	// Approximate sizes of the JSON encodings of frames and logs, excluding
+	// their variable-sized data, used for enforcing the size limit.
+	frameSize = 384
+	logSize   = 128
+	topicSize = 70
+)
+
+// Tracer is a tosca.Tracer recording the call frames of a transaction. A
+// tracer may be used for tracing multiple transactions sequentially, in
+// which case only the frames of the last transaction are retained.
+type Tracer struct {
+	tosca.NoOpTracer
+	config   Config
+	gasLimit tosca.Gas
+	depth    int // the depth of the currently executed call
+	stack    []Frame
+	result   *Frame
+	size     int   // the approximate size of the recorded frames
+	err      error // set once the size limit got exceeded
+}
+
+// New creates a tracer using the given configuration.
+func New(config Config) *Tracer {
+	return &Tracer{config: config}
+}
+
+func (t *Tracer) OnTxStart(_ tosca.BlockParameters, transaction tosca.Transaction) {
+	t.gasLimit = transaction.GasLimit
+	t.stack = t.stack[:0]
+	t.result = nil
+	t.size = 0
+	t.err = nil
+}
+
+func (t *Tracer) OnTxEnd(receipt tosca.Receipt, err error) {
+	// Transactions failing before the top-level call are not producing frames.
+	if err != nil || t.result == nil || t.err != nil {
+		return
+	}
+	t.result.Gas = hexutil.Uint64(t.gasLimit)
+	t.result.GasUsed = hexutil.Uint64(receipt.GasUsed)
+	if t.config.WithLog {
+		clearFailedLogs(t.result, false)
+	}
+}
+
+func (t *Tracer) OnEnter(depth int, kind tosca.CallKind, parameters tosca.CallParameters) {
+	t.depth = depth
+	if (t.config.OnlyTopCall && depth > 0) || !t.record(frameSize+2*len(parameters.Input)) {
+		return
+	}
+	frame := Frame{
+		Type:  typeName(kind),
+		From:  parameters.Sender,
+		Gas:   hexutil.Uint64(parameters.Gas),
+		Input: bytes.Clone(parameters.Input),
+	}
+	switch kind {
+	case tosca.Call, tosca.StaticCall:
+		frame.To = &parameters.Recipient
+	case tosca.CallCode:
+		frame.To = &parameters.CodeAddress
+	case tosca.DelegateCall:
+		frame.From = parameters.Recipient
+		frame.To = &parameters.CodeAddress
+	}
+	if kind != tosca.StaticCall {
+		frame.Value = (*hexutil.Big)(parameters.Value.ToBig())
+	}
+	t.stack = append(t.stack, frame)
+}
+
+func (t *Tracer) OnExit(depth int, result tosca.CallResult, err error) {
+	t.depth = depth - 1
+	if (t.config.OnlyTopCall && depth > 0) || len(t.stack) == 0 {
+		return
+	}
+	if !t.record(2 * len(result.Output)) {
+		return
+	}
+	frame := t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+	frame.GasUsed = frame.Gas - hexutil.Uint64(result.GasLeft)
+
+	switch {
+	case err != nil:
+		frame.Error = err.Error()
+	case result.Success:
+		frame.Output = bytes.Clone(result.Output)
+		if frame.Type == "CREATE" || frame.Type == "CREATE2" {
+			frame.To = &result.CreatedAddress
+		}
+	case result.GasLeft > 0:
+		// Results do not carry the reason of a failure. Since all failures
+		// but reverts consume all gas, returned gas indicates a revert.
+		frame.Error = errReverted
+		if len(result.Output) > 0 {
+			frame.Output = bytes.Clone(result.Output)
+			if reason, err := abi.UnpackRevert(result.Output); err == nil {
+				frame.RevertReason = reason
+			}
+		}
+	default:
+		frame.Error = errFailed
+	}
+
+	if len(t.stack) == 0 {
+		t.result = &frame
+		return
+	}
+	parent := &t.stack[len(t.stack)-1]
+	parent.Calls = append(parent.Calls, frame)
+}
+
+func (t *Tracer) OnLog(log tosca.Log) {
+	if !t.config.WithLog || (t.config.OnlyTopCall && t.depth > 0) || len(t.stack) == 0 {
+		return
+	}
+	if !t.record(logSize + topicSize*len(log.Topics) + 2*len(log.Data)) {
+		return
+	}
+	topics := make([]common.Hash, len(log.Topics))
+	for i, topic := range log.Topics {
+		topics[i] = common.Hash(topic)
+	}
+	frame := &t.stack[len(t.stack)-1]
+	frame.Logs = append(frame.Logs, Log{
+		Address:  log.Address,
+		Topics:   topics,
+		Data:     bytes.Clone(log.Data),
+		Position: hexutil.Uint(len(frame.Calls)),
+	})
+}
+
+// record accounts for the given number of bytes to be recorded. It reports
+// false if the size limit is exceeded, in which case all recorded frames are
+// dropped to release their memory.
+func (t *Tracer) record(size int) bool {
+	if t.err != nil {
+		return false
+	}
+	t.size += size
+	if t.config.MaxSize > 0 && t.size > t.config.MaxSize {
+		t.err = sink.ErrLimitExceeded
+		t.stack = nil
+		t.result = nil
+		return false
+	}
+	return true
+}
+
+// Result returns the top-level call frame of the last traced transaction or
+// nil if no call has been completed or the size limit has been exceeded.
+func (t *Tracer) Result() *Frame {
+	return t.result
+}
+
+// GetResult returns the JSON encoding of the top-level call frame of the
+// last traced transaction.
+func (t *Tracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.result == nil {
+		return nil, errors.New("no call frame recorded")
+	}
+	return json.Marshal(t.result)
+}
+
+// clearFailedLogs removes the logs of failed frames and their nested frames,
+// since their emission got reverted.
+func clearFailedLogs(frame *Frame, parentFailed bool) {
+	failed := parentFailed || frame.Error != ""
+	if failed {
+		frame.Logs = nil
+	}
+	for i := range frame.Calls {
+		clearFailedLogs(&frame.Calls[i], failed)
+	}
+}
+
+func typeName(kind tosca.CallKind) string {
+	switch kind {
+	case tosca.Call:
+		return "CALL"
+	case tosca.StaticCall:
+		return "STATICCALL"
+	case tosca.DelegateCall:
+		return "DELEGATECALL"
+	case tosca.CallCode:
+		return "CALLCODE"
+	case tosca.Create:
+		return "CREATE"
+	case tosca.Create2:
+		return "CREATE2"
+	default:
+		return "UNKNOWN"
+	}
+}
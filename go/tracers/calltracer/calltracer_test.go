@@ -0,0 +1,197 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package calltracer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/sink"
+)
+
+func TestTracer_NestedCallsAreRecorded(t *testing.T) {
+	tracer := New(Config{})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{GasLimit: 100_000})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{
+		Sender:    tosca.Address{1},
+		Recipient: tosca.Address{2},
+		Input:     []byte{1, 2},
+		Gas:       80_000,
+	})
+	tracer.OnEnter(1, tosca.DelegateCall, tosca.CallParameters{
+		Sender:      tosca.Address{1},
+		Recipient:   tosca.Address{2},
+		CodeAddress: tosca.Address{3},
+		Gas:         1000,
+	})
+	tracer.OnExit(1, tosca.CallResult{Success: true, GasLeft: 400, Output: []byte{3}}, nil)
+	tracer.OnEnter(1, tosca.StaticCall, tosca.CallParameters{
+		Sender:    tosca.Address{2},
+		Recipient: tosca.Address{4},
+		Gas:       500,
+	})
+	tracer.OnExit(1, tosca.CallResult{}, nil)
+	tracer.OnExit(0, tosca.CallResult{Success: true, GasLeft: 70_000}, nil)
+	tracer.OnTxEnd(tosca.Receipt{Success: true, GasUsed: 31_000}, nil)
+
+	got, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	want := `{"type":"CALL","from":"0x0100000000000000000000000000000000000000","gas":"0x186a0","gasUsed":"0x7918",` +
+		`"to":"0x0200000000000000000000000000000000000000","input":"0x0102","calls":[` +
+		`{"type":"DELEGATECALL","from":"0x0200000000000000000000000000000000000000","gas":"0x3e8","gasUsed":"0x258",` +
+		`"to":"0x0300000000000000000000000000000000000000","input":"0x","output":"0x03","value":"0x0"},` +
+		`{"type":"STATICCALL","from":"0x0200000000000000000000000000000000000000","gas":"0x1f4","gasUsed":"0x1f4",` +
+		`"to":"0x0400000000000000000000000000000000000000","input":"0x","error":"execution failed"}],"value":"0x0"}`
+	if want != string(got) {
+		t.Errorf("unexpected result,\nwanted %s\n   got %s", want, got)
+	}
+}
+
+func TestTracer_RevertReasonIsDecoded(t *testing.T) {
+	// ABI encoding of Error("fail")
+	output, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000004" +
+		"6661696c00000000000000000000000000000000000000000000000000000000")
+
+	tracer := New(Config{})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Gas: 100})
+	tracer.OnExit(0, tosca.CallResult{GasLeft: 50, Output: output}, nil)
+
+	frame := tracer.Result()
+	if frame == nil {
+		t.Fatalf("no frame recorded")
+	}
+	if want, got := "execution reverted", frame.Error; want != got {
+		t.Errorf("unexpected error, wanted %q, got %q", want, got)
+	}
+	if want, got := "fail", frame.RevertReason; want != got {
+		t.Errorf("unexpected revert reason, wanted %q, got %q", want, got)
+	}
+	if want, got := len(output), len(frame.Output); want != got {
+		t.Errorf("unexpected output length, wanted %d, got %d", want, got)
+	}
+}
+
+func TestTracer_CreatedAddressIsOnlyReportedForSuccessfulCreates(t *testing.T) {
+	tests := map[string]struct {
+		result tosca.CallResult
+		want   *tosca.Address
+	}{
+		"success": {tosca.CallResult{Success: true, CreatedAddress: tosca.Address{5}}, &tosca.Address{5}},
+		"failure": {tosca.CallResult{}, nil},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tracer := New(Config{})
+			tracer.OnEnter(0, tosca.Create2, tosca.CallParameters{Sender: tosca.Address{1}})
+			tracer.OnExit(0, test.result, nil)
+
+			frame := tracer.Result()
+			if want, got := "CREATE2", frame.Type; want != got {
+				t.Errorf("unexpected type, wanted %s, got %s", want, got)
+			}
+			if (test.want == nil) != (frame.To == nil) || (test.want != nil && *test.want != *frame.To) {
+				t.Errorf("unexpected target, wanted %v, got %v", test.want, frame.To)
+			}
+		})
+	}
+}
+
+func TestTracer_OnlyTopCallIgnoresNestedCallsAndLogs(t *testing.T) {
+	tracer := New(Config{OnlyTopCall: true, WithLog: true})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{})
+	tracer.OnEnter(1, tosca.Call, tosca.CallParameters{})
+	tracer.OnLog(tosca.Log{Address: tosca.Address{1}})
+	tracer.OnExit(1, tosca.CallResult{Success: true}, nil)
+	tracer.OnLog(tosca.Log{Address: tosca.Address{2}})
+	tracer.OnExit(0, tosca.CallResult{Success: true}, nil)
+
+	frame := tracer.Result()
+	if len(frame.Calls) != 0 {
+		t.Errorf("nested calls were recorded: %v", frame.Calls)
+	}
+	if len(frame.Logs) != 1 || frame.Logs[0].Address != (tosca.Address{2}) {
+		t.Errorf("unexpected logs: %v", frame.Logs)
+	}
+}
+
+func TestTracer_LogsOfFailedCallsAreCleared(t *testing.T) {
+	tracer := New(Config{WithLog: true})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Gas: 100})
+	tracer.OnLog(tosca.Log{Address: tosca.Address{1}})
+	tracer.OnEnter(1, tosca.Call, tosca.CallParameters{Gas: 50})
+	tracer.OnLog(tosca.Log{Address: tosca.Address{2}})
+	tracer.OnExit(1, tosca.CallResult{GasLeft: 10}, nil)
+	tracer.OnLog(tosca.Log{Address: tosca.Address{3}, Topics: []tosca.Hash{{4}}})
+	tracer.OnExit(0, tosca.CallResult{Success: true}, nil)
+	tracer.OnTxEnd(tosca.Receipt{}, nil)
+
+	frame := tracer.Result()
+	if want, got := 2, len(frame.Logs); want != got {
+		t.Fatalf("unexpected number of logs, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, int(frame.Logs[1].Position); want != got {
+		t.Errorf("unexpected log position, wanted %d, got %d", want, got)
+	}
+	if len(frame.Calls) != 1 || len(frame.Calls[0].Logs) != 0 {
+		t.Errorf("logs of failed call were not cleared: %v", frame.Calls)
+	}
+
+	encoded, err := json.Marshal(frame.Logs[1])
+	if err != nil {
+		t.Fatalf("failed to encode log: %v", err)
+	}
+	want := `{"address":"0x0300000000000000000000000000000000000000",` +
+		`"topics":["0x0400000000000000000000000000000000000000000000000000000000000000"],"data":"0x","position":"0x1"}`
+	if want != string(encoded) {
+		t.Errorf("unexpected log encoding,\nwanted %s\n   got %s", want, encoded)
+	}
+}
+
+func TestTracer_GetResultFailsWithoutRecordedCall(t *testing.T) {
+	tracer := New(Config{})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+	tracer.OnTxEnd(tosca.Receipt{}, nil)
+	if _, err := tracer.GetResult(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+func TestTracer_FramesExceedingMaxSizeAreDropped(t *testing.T) {
+	tracer := New(Config{MaxSize: 10_000})
+	for _, inputSize := range []int{1000, 10_000, 1000} {
+		tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{GasLimit: 100_000})
+		tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Input: make([]byte, inputSize)})
+		tracer.OnEnter(1, tosca.Call, tosca.CallParameters{Input: make([]byte, inputSize)})
+		tracer.OnExit(1, tosca.CallResult{Success: true}, nil)
+		tracer.OnExit(0, tosca.CallResult{Success: true}, nil)
+		tracer.OnTxEnd(tosca.Receipt{Success: true}, nil)
+
+		_, err := tracer.GetResult()
+		if inputSize > 1000 {
+			if !errors.Is(err, sink.ErrLimitExceeded) {
+				t.Errorf("unexpected error, wanted %v, got %v", sink.ErrLimitExceeded, err)
+			}
+			if tracer.Result() != nil {
+				t.Errorf("frames exceeding the size limit are reported")
+			}
+		} else if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
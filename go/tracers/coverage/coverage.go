@@ -0,0 +1,196 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package coverage provides a tosca.Tracer recording which instructions of
+// the executed codes are reached, identified by their byte offsets within
+// codes indexed by their hashes. Coverage can be reported per transaction or
+// aggregated over all traced transactions, for instance to support audit
+// tooling or the detection of dead code based on replayed transactions.
+package coverage
+
+import (
+	"encoding/json"
+	"math/bits"
+	"slices"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Coverage maps the hashes of executed codes to the byte offsets of the
+// instructions executed within them. Init codes of contract creations are
+// covered under the hash of the init code. Its JSON encoding maps the hashes
+// to sorted lists of offsets.
+type Coverage map[tosca.Hash]*Offsets
+
+// Merge adds all offsets covered by the other coverage to this coverage.
+func (c Coverage) Merge(other Coverage) {
+	for hash, offsets := range other {
+		if offsets.Len() > 0 {
+			c.get(hash).Merge(offsets)
+		}
+	}
+}
+
+func (c Coverage) get(hash tosca.Hash) *Offsets {
+	offsets, found := c[hash]
+	if !found {
+		offsets = &Offsets{}
+		c[hash] = offsets
+	}
+	return offsets
+}
+
+func (c Coverage) MarshalJSON() ([]byte, error) {
+	res := make(map[common.Hash][]int, len(c))
+	for hash, offsets := range c {
+		if offsets.Len() > 0 {
+			res[common.Hash(hash)] = offsets.List()
+		}
+	}
+	return json.Marshal(res)
+}
+
+// Offsets is a set of byte offsets within a code.
+type Offsets struct {
+	bits []uint64
+}
+
+// Add adds the given offset to the set. Negative offsets are ignored.
+func (o *Offsets) Add(offset int) {
+	if offset < 0 {
+		return
+	}
+	index := offset / 64
+	if index >= len(o.bits) {
+		o.bits = append(o.bits, make([]uint64, index+1-len(o.bits))...)
+	}
+	o.bits[index] |= 1 << (offset % 64)
+}
+
+// Contains returns true if the given offset is in the set.
+func (o *Offsets) Contains(offset int) bool {
+	index := offset / 64
+	return offset >= 0 && index < len(o.bits) && o.bits[index]&(1<<(offset%64)) != 0
+}
+
+// Len returns the number of offsets in the set.
+func (o *Offsets) Len() int {
+	res := 0
+	for _, cur := range o.bits {
+		res += bits.OnesCount64(cur)
+	}
+	return res
+}
+
+// List returns the offsets in the set in ascending order.
+func (o *Offsets) List() []int {
+	res := make([]int, 0, o.Len())
+	for i, cur := range o.bits {
+		for cur != 0 {
+			res = append(res, i*64+bits.TrailingZeros64(cur))
+			cur &= cur - 1
+		}
+	}
+	return res
+}
+
+// Merge adds all offsets of the other set to this set.
+func (o *Offsets) Merge(other *Offsets) {
+	if len(other.bits) > len(o.bits) {
+		o.bits = append(o.bits, make([]uint64, len(other.bits)-len(o.bits))...)
+	}
+	for i, cur := range other.bits {
+		o.bits[i] |= cur
+	}
+}
+
+// Tracer is a tosca.Tracer recording the coverage of executed codes. The
+// hashes of called codes are read from the world state the tracer is created
+// for, which must be the state the traced transactions are executed on,
+// including their modifications, such that codes deployed during a
+// transaction are covered under their hash. A tracer may be used for tracing
+// multiple transactions sequentially. The coverage of the last transaction
+// and the coverage aggregated over all transactions are retained.
+type Tracer struct {
+	tosca.NoOpTracer
+	state       tosca.WorldState
+	transaction Coverage
+	aggregated  Coverage
+	frames      []*Offsets // < the offsets of the codes of the active calls
+}
+
+// New creates a tracer reading code hashes from the given world state.
+func New(state tosca.WorldState) *Tracer {
+	return &Tracer{
+		state:       state,
+		transaction: Coverage{},
+		aggregated:  Coverage{},
+	}
+}
+
+func (t *Tracer) OnTxStart(tosca.BlockParameters, tosca.Transaction) {
+	t.transaction = Coverage{}
+	t.frames = t.frames[:0]
+}
+
+func (t *Tracer) OnTxEnd(tosca.Receipt, error) {
+	t.aggregated.Merge(t.transaction)
+}
+
+func (t *Tracer) OnEnter(depth int, kind tosca.CallKind, parameters tosca.CallParameters) {
+	var hash tosca.Hash
+	switch kind {
+	case tosca.Create, tosca.Create2:
+		hash = tosca.Hash(crypto.Keccak256(parameters.Input))
+	case tosca.Call, tosca.StaticCall:
+		hash = t.state.GetCodeHash(parameters.Recipient)
+	default:
+		hash = t.state.GetCodeHash(parameters.CodeAddress)
+	}
+	t.frames = append(t.frames[:depth], t.transaction.get(hash))
+}
+
+func (t *Tracer) OnExit(depth int, _ tosca.CallResult, _ error) {
+	t.frames = t.frames[:depth]
+}
+
+func (t *Tracer) OnOpcode(state tosca.OpCodeState) {
+	if state.Depth < len(t.frames) {
+		t.frames[state.Depth].Add(state.Pc)
+	}
+}
+
+// Transaction returns the coverage of the last traced transaction.
+func (t *Tracer) Transaction() Coverage {
+	return clone(t.transaction)
+}
+
+// Aggregated returns the coverage of all traced transactions.
+func (t *Tracer) Aggregated() Coverage {
+	return clone(t.aggregated)
+}
+
+// GetResult returns the JSON encoding of the coverage of the last traced
+// transaction.
+func (t *Tracer) GetResult() (json.RawMessage, error) {
+	return json.Marshal(t.transaction)
+}
+
+func clone(coverage Coverage) Coverage {
+	res := make(Coverage, len(coverage))
+	for hash, offsets := range coverage {
+		if offsets.Len() > 0 {
+			res[hash] = &Offsets{bits: slices.Clone(offsets.bits)}
+		}
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package coverage
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+func TestOffsets_AddContainsAndList(t *testing.T) {
+	offsets := &Offsets{}
+	for _, offset := range []int{130, 0, 63, 64, 0, -1} {
+		offsets.Add(offset)
+	}
+	if want, got := []int{0, 63, 64, 130}, offsets.List(); !slices.Equal(want, got) {
+		t.Errorf("unexpected offsets, wanted %v, got %v", want, got)
+	}
+	if want, got := 4, offsets.Len(); want != got {
+		t.Errorf("unexpected length, wanted %d, got %d", want, got)
+	}
+	for _, offset := range []int{-1, 1, 65, 129, 1000} {
+		if offsets.Contains(offset) {
+			t.Errorf("offset %d should not be contained", offset)
+		}
+	}
+}
+
+func TestOffsets_Merge(t *testing.T) {
+	a, b := &Offsets{}, &Offsets{}
+	a.Add(1)
+	b.Add(2)
+	b.Add(200)
+	a.Merge(b)
+	if want, got := []int{1, 2, 200}, a.List(); !slices.Equal(want, got) {
+		t.Errorf("unexpected offsets, wanted %v, got %v", want, got)
+	}
+}
+
+func TestTracer_RecordsExecutedOffsetsPerCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockWorldState(ctrl)
+	outer, inner := tosca.Address{1}, tosca.Address{2}
+	outerHash, innerHash := tosca.Hash{1}, tosca.Hash{2}
+	state.EXPECT().GetCodeHash(outer).Return(outerHash)
+	state.EXPECT().GetCodeHash(inner).Return(innerHash)
+
+	initCode := tosca.Data{1, 2, 3}
+	initHash := tosca.Hash(crypto.Keccak256(initCode))
+
+	tracer := New(state)
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: outer})
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 0, Depth: 0})
+	tracer.OnEnter(1, tosca.DelegateCall, tosca.CallParameters{Recipient: outer, CodeAddress: inner})
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 5, Depth: 1})
+	tracer.OnExit(1, tosca.CallResult{}, nil)
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 2, Depth: 0})
+	tracer.OnEnter(1, tosca.Create, tosca.CallParameters{Input: initCode})
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 7, Depth: 1})
+	tracer.OnExit(1, tosca.CallResult{}, nil)
+	tracer.OnExit(0, tosca.CallResult{}, nil)
+	tracer.OnTxEnd(tosca.Receipt{}, nil)
+
+	coverage := tracer.Transaction()
+	want := map[tosca.Hash][]int{
+		outerHash: {0, 2},
+		innerHash: {5},
+		initHash:  {7},
+	}
+	if len(want) != len(coverage) {
+		t.Errorf("unexpected number of covered codes, wanted %d, got %d", len(want), len(coverage))
+	}
+	for hash, offsets := range want {
+		if got := coverage[hash]; got == nil || !slices.Equal(offsets, got.List()) {
+			t.Errorf("unexpected coverage of %v, wanted %v, got %v", hash, offsets, got)
+		}
+	}
+}
+
+func TestTracer_AggregatesCoverageOfTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockWorldState(ctrl)
+	address, hash := tosca.Address{1}, tosca.Hash{1}
+	state.EXPECT().GetCodeHash(address).Return(hash).Times(2)
+
+	tracer := New(state)
+	for _, pc := range []int{3, 1} {
+		tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+		tracer.OnEnter(0, tosca.Call, tosca.CallParameters{Recipient: address})
+		tracer.OnOpcode(tosca.OpCodeState{Pc: pc})
+		tracer.OnExit(0, tosca.CallResult{}, nil)
+		tracer.OnTxEnd(tosca.Receipt{}, nil)
+	}
+
+	if want, got := []int{1}, tracer.Transaction()[hash].List(); !slices.Equal(want, got) {
+		t.Errorf("unexpected coverage of last transaction, wanted %v, got %v", want, got)
+	}
+	if want, got := []int{1, 3}, tracer.Aggregated()[hash].List(); !slices.Equal(want, got) {
+		t.Errorf("unexpected aggregated coverage, wanted %v, got %v", want, got)
+	}
+}
+
+func TestTracer_CodesWithoutExecutedInstructionsAreNotReported(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockWorldState(ctrl)
+	state.EXPECT().GetCodeHash(gomock.Any()).Return(tosca.Hash{1})
+
+	tracer := New(state)
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{})
+	tracer.OnExit(0, tosca.CallResult{}, nil)
+	tracer.OnTxEnd(tosca.Receipt{}, nil)
+
+	if coverage := tracer.Aggregated(); len(coverage) != 0 {
+		t.Errorf("unexpected coverage: %v", coverage)
+	}
+	result, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	if want, got := "{}", string(result); want != got {
+		t.Errorf("unexpected result, wanted %s, got %s", want, got)
+	}
+}
+
+func TestTracer_GetResultEncodesOffsetsByCodeHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	state := tosca.NewMockWorldState(ctrl)
+	state.EXPECT().GetCodeHash(gomock.Any()).Return(tosca.Hash{31: 1})
+
+	tracer := New(state)
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{})
+	tracer.OnEnter(0, tosca.Call, tosca.CallParameters{})
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 2})
+	tracer.OnOpcode(tosca.OpCodeState{Pc: 0})
+	tracer.OnExit(0, tosca.CallResult{}, nil)
+	tracer.OnTxEnd(tosca.Receipt{}, nil)
+
+	result, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	want := `{"0x0000000000000000000000000000000000000000000000000000000000000001":[0,2]}`
+	if want != string(result) {
+		t.Errorf("unexpected result, wanted %s, got %s", want, result)
+	}
+}
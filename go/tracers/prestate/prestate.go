@@ -0,0 +1,247 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package prestate provides a tosca.Tracer collecting the state of all
+// accounts and storage slots touched by a transaction before its execution.
+// Optionally, the tracer may be configured to only report modified state,
+// including its values before and after the transaction. Results are encoded
+// in the same JSON format as the results of geth's native prestateTracer.
+package prestate
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/address"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+// Config controls the kind of state reported by the tracer. Its JSON encoding
+// matches the configuration of geth's prestateTracer.
+type Config struct {
+	// DiffMode, if true, restricts the result to modified accounts and slots
+	// and reports their values before and after the transaction.
+	DiffMode bool `json:"diffMode"`
+}
+
+// State maps accounts to their state. Only touched storage slots are listed.
+type State map[tosca.Address]*Account
+
+// Account summarizes the state of an account. Zero-valued fields are omitted
+// in the JSON encoding.
+type Account struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+	empty   bool
+}
+
+// Diff lists the state of modified accounts and slots before and after a
+// transaction, as reported in diff mode.
+type Diff struct {
+	Post State `json:"post"`
+	Pre  State `json:"pre"`
+}
+
+// Tracer is a tosca.Tracer collecting the state touched by a transaction. The
+// state is read from the world state the tracer is created for, which must be
+// the state the traced transaction is executed on. Since state is read
+// whenever it is touched for the first time, before it is modified by the
+// transaction, the collected values are the values before the transaction.
+// A tracer is intended to be used for tracing a single transaction.
+type Tracer struct {
+	tosca.NoOpTracer
+	state   tosca.WorldState
+	config  Config
+	pre     State
+	post    State
+	created map[tosca.Address]bool
+	deleted map[tosca.Address]bool
+}
+
+// New creates a tracer reading the touched state from the given world state.
+func New(state tosca.WorldState, config Config) *Tracer {
+	return &Tracer{
+		state:   state,
+		config:  config,
+		pre:     State{},
+		post:    State{},
+		created: map[tosca.Address]bool{},
+		deleted: map[tosca.Address]bool{},
+	}
+}
+
+func (t *Tracer) OnTxStart(block tosca.BlockParameters, transaction tosca.Transaction) {
+	var recipient tosca.Address
+	if transaction.Recipient == nil {
+		nonce := t.state.GetNonce(transaction.Sender)
+		recipient = address.Create(transaction.Sender, nonce)
+		t.created[recipient] = true
+	} else {
+		recipient = *transaction.Recipient
+	}
+	t.lookupAccount(transaction.Sender)
+	t.lookupAccount(recipient)
+	t.lookupAccount(block.Coinbase)
+}
+
+func (t *Tracer) OnTxEnd(_ tosca.Receipt, err error) {
+	if err != nil {
+		return
+	}
+	if t.config.DiffMode {
+		t.processDiff()
+	}
+	// Accounts created by the transaction did not exist before.
+	for address := range t.created {
+		if account := t.pre[address]; account != nil && account.empty {
+			delete(t.pre, address)
+		}
+	}
+}
+
+func (t *Tracer) OnOpcode(state tosca.OpCodeState) {
+	stack := state.Stack
+	top := func(i int) tosca.Word { return stack[len(stack)-1-i] }
+	switch op := state.OpCode; {
+	case len(stack) >= 1 && (op == vm.SLOAD || op == vm.SSTORE):
+		t.lookupStorage(state.Address, tosca.Key(top(0)))
+	case len(stack) >= 1 && (op == vm.EXTCODECOPY || op == vm.EXTCODEHASH || op == vm.EXTCODESIZE ||
+		op == vm.BALANCE || op == vm.SELFDESTRUCT):
+		t.lookupAccount(toAddress(top(0)))
+		if op == vm.SELFDESTRUCT {
+			t.deleted[state.Address] = true
+		}
+	case len(stack) >= 5 && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL):
+		t.lookupAccount(toAddress(top(1)))
+	case op == vm.CREATE:
+		nonce := t.state.GetNonce(state.Address)
+		created := address.Create(state.Address, nonce)
+		t.lookupAccount(created)
+		t.created[created] = true
+	case len(stack) >= 4 && op == vm.CREATE2:
+		offset, size := toUint64(top(1)), toUint64(top(2))
+		if offset > uint64(len(state.Memory)) || size > uint64(len(state.Memory))-offset {
+			return // < the instruction is going to fail
+		}
+		initCode := state.Memory[offset : offset+size]
+		created := address.Create2WithInitCode(state.Address, tosca.Hash(top(3)), initCode)
+		t.lookupAccount(created)
+		t.created[created] = true
+	}
+}
+
+// Result returns the state collected for the traced transaction. In diff
+// mode, the result is a Diff, otherwise a State.
+func (t *Tracer) Result() any {
+	if t.config.DiffMode {
+		return Diff{Post: t.post, Pre: t.pre}
+	}
+	return t.pre
+}
+
+// GetResult returns the JSON encoding of the result of the tracer.
+func (t *Tracer) GetResult() (json.RawMessage, error) {
+	return json.Marshal(t.Result())
+}
+
+// processDiff compares the collected state with the state after the
+// transaction, restricting the pre-state to modified accounts and slots and
+// collecting their values in the post-state.
+func (t *Tracer) processDiff() {
+	for address, pre := range t.pre {
+		// The state of deleted accounts is only reported in the pre-state.
+		if t.deleted[address] {
+			continue
+		}
+		modified := false
+		post := &Account{Storage: map[common.Hash]common.Hash{}}
+
+		balance := t.state.GetBalance(address).ToBig()
+		if balance.Cmp(pre.Balance.ToInt()) != 0 {
+			modified = true
+			post.Balance = (*hexutil.Big)(balance)
+		}
+		if nonce := t.state.GetNonce(address); nonce != pre.Nonce {
+			modified = true
+			post.Nonce = nonce
+		}
+		if code := t.state.GetCode(address); !bytes.Equal(code, pre.Code) {
+			modified = true
+			post.Code = hexutil.Bytes(code)
+		}
+		for key, value := range pre.Storage {
+			if value == (common.Hash{}) {
+				delete(pre.Storage, key)
+			}
+			current := common.Hash(t.state.GetStorage(address, tosca.Key(key)))
+			if current == value {
+				delete(pre.Storage, key)
+				continue
+			}
+			modified = true
+			if current != (common.Hash{}) {
+				post.Storage[key] = current
+			}
+		}
+
+		if modified {
+			t.post[address] = post
+		} else {
+			delete(t.pre, address)
+		}
+	}
+}
+
+// lookupAccount adds the current state of the given account to the collected
+// pre-state, if it is not yet present.
+func (t *Tracer) lookupAccount(address tosca.Address) {
+	if _, found := t.pre[address]; found {
+		return
+	}
+	account := &Account{
+		Balance: (*hexutil.Big)(t.state.GetBalance(address).ToBig()),
+		Nonce:   t.state.GetNonce(address),
+		Code:    hexutil.Bytes(t.state.GetCode(address)),
+		Storage: map[common.Hash]common.Hash{},
+	}
+	account.empty = account.Nonce == 0 && len(account.Code) == 0 && account.Balance.ToInt().Sign() == 0
+	t.pre[address] = account
+}
+
+// lookupStorage adds the current value of the given slot to the collected
+// pre-state, if it is not yet present.
+func (t *Tracer) lookupStorage(address tosca.Address, key tosca.Key) {
+	t.lookupAccount(address)
+	storage := t.pre[address].Storage
+	if _, found := storage[common.Hash(key)]; found {
+		return
+	}
+	storage[common.Hash(key)] = common.Hash(t.state.GetStorage(address, key))
+}
+
+func toAddress(word tosca.Word) tosca.Address {
+	return tosca.Address(word[12:])
+}
+
+// toUint64 converts the given word to an uint64, saturating at the maximum.
+func toUint64(word tosca.Word) uint64 {
+	value := new(uint256.Int).SetBytes32(word[:])
+	if !value.IsUint64() {
+		return math.MaxUint64
+	}
+	return value.Uint64()
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package prestate
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/mock/gomock"
+)
+
+type account struct {
+	balance tosca.Value
+	nonce   uint64
+	code    tosca.Code
+	storage map[tosca.Key]tosca.Word
+}
+
+// newWorldState creates a world state mock backed by the given accounts, such
+// that modifications of the accounts are visible to the tracer.
+func newWorldState(ctrl *gomock.Controller, accounts map[tosca.Address]*account) *tosca.MockWorldState {
+	get := func(address tosca.Address) *account {
+		if acc, found := accounts[address]; found {
+			return acc
+		}
+		return &account{}
+	}
+	state := tosca.NewMockWorldState(ctrl)
+	state.EXPECT().GetBalance(gomock.Any()).DoAndReturn(func(address tosca.Address) tosca.Value {
+		return get(address).balance
+	}).AnyTimes()
+	state.EXPECT().GetNonce(gomock.Any()).DoAndReturn(func(address tosca.Address) uint64 {
+		return get(address).nonce
+	}).AnyTimes()
+	state.EXPECT().GetCode(gomock.Any()).DoAndReturn(func(address tosca.Address) tosca.Code {
+		return get(address).code
+	}).AnyTimes()
+	state.EXPECT().GetStorage(gomock.Any(), gomock.Any()).DoAndReturn(func(address tosca.Address, key tosca.Key) tosca.Word {
+		return get(address).storage[key]
+	}).AnyTimes()
+	return state
+}
+
+func TestTracer_CollectsTouchedState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sender, recipient, other := tosca.Address{1}, tosca.Address{2}, tosca.Address{3}
+	state := newWorldState(ctrl, map[tosca.Address]*account{
+		sender:    {balance: tosca.NewValue(100), nonce: 4},
+		recipient: {code: tosca.Code{byte(vm.STOP)}, storage: map[tosca.Key]tosca.Word{{1}: {2}}},
+		other:     {balance: tosca.NewValue(5)},
+	})
+
+	tracer := New(state, Config{})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{Sender: sender, Recipient: &recipient})
+	tracer.OnOpcode(tosca.OpCodeState{OpCode: vm.SLOAD, Address: recipient, Stack: []tosca.Word{{1}}})
+	tracer.OnOpcode(tosca.OpCodeState{OpCode: vm.BALANCE, Address: recipient, Stack: []tosca.Word{tosca.Word(common.LeftPadBytes(other[:], 32))}})
+	tracer.OnTxEnd(tosca.Receipt{Success: true}, nil)
+
+	got, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get result: %v", err)
+	}
+	want := `{"0x0000000000000000000000000000000000000000":{"balance":"0x0"},` +
+		`"0x0100000000000000000000000000000000000000":{"balance":"0x64","nonce":4},` +
+		`"0x0200000000000000000000000000000000000000":{"balance":"0x0","code":"0x00",` +
+		`"storage":{"0x0100000000000000000000000000000000000000000000000000000000000000":` +
+		`"0x0200000000000000000000000000000000000000000000000000000000000000"}},` +
+		`"0x0300000000000000000000000000000000000000":{"balance":"0x5"}}`
+	if want != string(got) {
+		t.Errorf("unexpected result,\nwanted %s\n   got %s", want, got)
+	}
+}
+
+func TestTracer_DiffModeReportsModifiedState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sender, recipient := tosca.Address{1}, tosca.Address{2}
+	accounts := map[tosca.Address]*account{
+		sender: {balance: tosca.NewValue(100)},
+		recipient: {code: tosca.Code{byte(vm.STOP)}, storage: map[tosca.Key]tosca.Word{
+			{1}: {1}, // < modified
+			{2}: {2}, // < unmodified
+		}},
+	}
+	state := newWorldState(ctrl, accounts)
+
+	tracer := New(state, Config{DiffMode: true})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{Sender: sender, Recipient: &recipient})
+	tracer.OnOpcode(tosca.OpCodeState{OpCode: vm.SLOAD, Address: recipient, Stack: []tosca.Word{{2}}})
+	tracer.OnOpcode(tosca.OpCodeState{OpCode: vm.SSTORE, Address: recipient, Stack: []tosca.Word{{3}, {1}}})
+	accounts[sender].balance = tosca.NewValue(90)
+	accounts[sender].nonce = 1
+	accounts[recipient].storage[tosca.Key{1}] = tosca.Word{3}
+	tracer.OnTxEnd(tosca.Receipt{Success: true}, nil)
+
+	diff, ok := tracer.Result().(Diff)
+	if !ok {
+		t.Fatalf("unexpected result type %T", tracer.Result())
+	}
+	if want, got := 2, len(diff.Pre); want != got {
+		t.Errorf("unexpected number of accounts in pre-state, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(90), diff.Post[sender].Balance.ToInt().Uint64(); want != got {
+		t.Errorf("unexpected balance in post-state, wanted %d, got %d", want, got)
+	}
+	if want, got := uint64(1), diff.Post[sender].Nonce; want != got {
+		t.Errorf("unexpected nonce in post-state, wanted %d, got %d", want, got)
+	}
+	if want, got := 1, len(diff.Pre[recipient].Storage); want != got {
+		t.Errorf("unexpected number of slots in pre-state, wanted %d, got %d", want, got)
+	}
+	key := common.Hash{1}
+	if want, got := (common.Hash{1}), diff.Pre[recipient].Storage[key]; want != got {
+		t.Errorf("unexpected slot value in pre-state, wanted %v, got %v", want, got)
+	}
+	if want, got := (common.Hash{3}), diff.Post[recipient].Storage[key]; want != got {
+		t.Errorf("unexpected slot value in post-state, wanted %v, got %v", want, got)
+	}
+	if diff.Post[recipient].Code != nil || diff.Post[recipient].Balance != nil {
+		t.Errorf("unmodified fields reported in post-state: %v", diff.Post[recipient])
+	}
+}
+
+func TestTracer_CreatedAccountsAreNotPartOfPreState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sender := tosca.Address{1}
+	state := newWorldState(ctrl, map[tosca.Address]*account{
+		sender: {balance: tosca.NewValue(100), nonce: 7},
+	})
+
+	tracer := New(state, Config{})
+	tracer.OnTxStart(tosca.BlockParameters{}, tosca.Transaction{Sender: sender})
+	tracer.OnTxEnd(tosca.Receipt{Success: true}, nil)
+
+	created := tosca.Address(crypto.CreateAddress(common.Address(sender), 7))
+	result := tracer.Result().(State)
+	if _, found := result[created]; found {
+		t.Errorf("created account is part of the pre-state")
+	}
+	if _, found := result[sender]; !found {
+		t.Errorf("sender is missing in the pre-state")
+	}
+}
+
+func TestTracer_Create2TargetIsCollected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	creator := tosca.Address{1}
+	initCode := []byte{byte(vm.STOP)}
+	target := tosca.Address(crypto.CreateAddress2(common.Address(creator), tosca.Word{9}, crypto.Keccak256(initCode)))
+	state := newWorldState(ctrl, map[tosca.Address]*account{
+		target: {balance: tosca.NewValue(1)},
+	})
+
+	tracer := New(state, Config{})
+	tracer.OnOpcode(tosca.OpCodeState{
+		OpCode:  vm.CREATE2,
+		Address: creator,
+		Stack:   []tosca.Word{{9}, tosca.Word(common.LeftPadBytes([]byte{1}, 32)), {}, {}},
+		Memory:  initCode,
+	})
+
+	if _, found := tracer.Result().(State)[target]; !found {
+		t.Errorf("target of CREATE2 is missing in the pre-state")
+	}
+}
+
+func TestTracer_Create2WithMemoryOutOfBoundsIsIgnored(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tracer := New(tosca.NewMockWorldState(ctrl), Config{})
+	tracer.OnOpcode(tosca.OpCodeState{
+		OpCode: vm.CREATE2,
+		Stack:  []tosca.Word{{}, tosca.Word(common.LeftPadBytes([]byte{1}, 32)), {0xff}, {}},
+	})
+	if want, got := 0, len(tracer.Result().(State)); want != got {
+		t.Errorf("unexpected number of accounts, wanted %d, got %d", want, got)
+	}
+}
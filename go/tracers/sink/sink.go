@@ -0,0 +1,185 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package sink provides destinations for the output of tracers producing
+// large amounts of data, like the logs of all executed instructions. Instead
+// of accumulating all entries of a trace in memory, entries are forwarded to
+// a sink as soon as they are produced. Sinks limit the size of traces and
+// apply backpressure to tracers by blocking them while their consumer is
+// falling behind, such that tracing large transactions is running with
+// bounded memory.
+package sink
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrLimitExceeded is reported by sinks and tracers once a trace exceeds its
+// configured size limit.
+var ErrLimitExceeded = errors.New("trace size limit exceeded")
+
+// Sink consumes the entries of a trace. Sinks are not required to be safe for
+// concurrent use, since tracers are invoked sequentially.
+type Sink interface {
+	// Write adds the given entry to the trace. The entry must not be modified
+	// after it has been written. Implementations may block until they are
+	// ready to accept more entries. Once an error is reported, all subsequent
+	// writes fail with the same error.
+	Write(entry any) error
+
+	// Close completes the trace. It must be called once all entries have been
+	// written and reports the first error encountered by the sink.
+	Close() error
+}
+
+// NewJSONSink creates a sink writing the entries of a trace as a JSON array
+// to the given writer. If limit is positive, the size of the trace is limited
+// to the given number of bytes; any entry exceeding this limit is dropped and
+// ErrLimitExceeded is reported. Writes are forwarded to the given writer
+// directly, such that slow writers, for instance network connections, are
+// throttling the tracer.
+func NewJSONSink(out io.Writer, limit int) Sink {
+	return &jsonSink{out: out, limit: limit}
+}
+
+type jsonSink struct {
+	out     io.Writer
+	limit   int
+	written int
+	started bool
+	err     error
+}
+
+func (s *jsonSink) Write(entry any) error {
+	if s.err != nil {
+		return s.err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.err = err
+		return err
+	}
+	// Reserve space for the separator and the closing bracket.
+	if s.limit > 0 && s.written+len(data)+2 > s.limit {
+		s.err = ErrLimitExceeded
+		// The trace is terminated to remain a valid JSON document.
+		if err := s.finish(); err != nil {
+			s.err = err
+		}
+		return s.err
+	}
+	separator := []byte{','}
+	if !s.started {
+		separator[0] = '['
+		s.started = true
+	}
+	if err := s.write(separator); err != nil {
+		return err
+	}
+	return s.write(data)
+}
+
+func (s *jsonSink) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if err := s.finish(); err != nil {
+		return err
+	}
+	s.err = errors.New("sink closed")
+	return nil
+}
+
+func (s *jsonSink) finish() error {
+	if !s.started {
+		s.started = true
+		return s.write([]byte("[]"))
+	}
+	return s.write([]byte{']'})
+}
+
+func (s *jsonSink) write(data []byte) error {
+	n, err := s.out.Write(data)
+	s.written += n
+	if err != nil {
+		s.err = err
+	}
+	return err
+}
+
+// NewBufferedSink creates a sink decoupling the tracer from the given sink,
+// which is fed by a background goroutine. Up to size entries are buffered;
+// once the buffer is full, writes are blocking until the given sink catches
+// up. Errors of the given sink are reported by subsequent writes.
+func NewBufferedSink(sink Sink, size int) Sink {
+	res := &bufferedSink{
+		entries: make(chan any, size),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(res.done)
+		for entry := range res.entries {
+			if err := sink.Write(entry); err != nil {
+				res.setErr(err)
+				// Drain remaining entries to unblock the tracer. The error of
+				// the failed write is retained instead of the one of Close.
+				for range res.entries {
+				}
+				_ = sink.Close()
+				return
+			}
+		}
+		res.setErr(sink.Close())
+	}()
+	return res
+}
+
+type bufferedSink struct {
+	entries chan any
+	done    chan struct{}
+	closed  bool
+	mutex   sync.Mutex
+	err     error
+}
+
+func (s *bufferedSink) Write(entry any) error {
+	if err := s.getErr(); err != nil {
+		return err
+	}
+	if s.closed {
+		return errors.New("sink closed")
+	}
+	s.entries <- entry
+	return nil
+}
+
+func (s *bufferedSink) Close() error {
+	if !s.closed {
+		s.closed = true
+		close(s.entries)
+	}
+	<-s.done
+	return s.getErr()
+}
+
+func (s *bufferedSink) setErr(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+}
+
+func (s *bufferedSink) getErr() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.err
+}
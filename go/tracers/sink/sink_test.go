@@ -0,0 +1,169 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONSink_WritesEntriesAsArray(t *testing.T) {
+	tests := map[string]struct {
+		entries []any
+		want    string
+	}{
+		"empty":    {nil, `[]`},
+		"single":   {[]any{1}, `[1]`},
+		"multiple": {[]any{1, "a", map[string]int{"b": 2}}, `[1,"a",{"b":2}]`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			sink := NewJSONSink(&out, 0)
+			for _, entry := range test.entries {
+				if err := sink.Write(entry); err != nil {
+					t.Fatalf("failed to write entry: %v", err)
+				}
+			}
+			if err := sink.Close(); err != nil {
+				t.Fatalf("failed to close sink: %v", err)
+			}
+			if want, got := test.want, out.String(); want != got {
+				t.Errorf("unexpected output, wanted %s, got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestJSONSink_EntriesExceedingLimitAreDropped(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewJSONSink(&out, 10)
+	for i := 0; i < 2; i++ {
+		if err := sink.Write(100 + i); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if want, got := ErrLimitExceeded, sink.Write(102); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if want, got := ErrLimitExceeded, sink.Write(1); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if want, got := ErrLimitExceeded, sink.Close(); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+
+	var entries []int
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("output is not a valid JSON array: %v", err)
+	}
+	if want, got := "[100,101]", out.String(); want != got {
+		t.Errorf("unexpected output, wanted %s, got %s", want, got)
+	}
+}
+
+func TestJSONSink_ReportsErrorsOfWriter(t *testing.T) {
+	injectedErr := errors.New("injected error")
+	sink := NewJSONSink(failingWriter{injectedErr}, 0)
+	if want, got := injectedErr, sink.Write(1); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if want, got := injectedErr, sink.Close(); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+}
+
+func TestBufferedSink_ForwardsEntriesInOrder(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewBufferedSink(NewJSONSink(&out, 0), 2)
+	for i := 0; i < 10; i++ {
+		if err := sink.Write(i); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+	if want, got := "[0,1,2,3,4,5,6,7,8,9]", out.String(); want != got {
+		t.Errorf("unexpected output, wanted %s, got %s", want, got)
+	}
+}
+
+func TestBufferedSink_WritesBlockWhileBufferIsFull(t *testing.T) {
+	target := &blockingSink{release: make(chan struct{}), received: make(chan any, 10)}
+	sink := NewBufferedSink(target, 1)
+
+	// The first entry is consumed by the blocked target, the second is buffered.
+	for i := 0; i < 2; i++ {
+		if err := sink.Write(i); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	<-target.received
+
+	written := make(chan struct{})
+	go func() {
+		_ = sink.Write(2)
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatalf("write did not block while buffer was full")
+	default:
+	}
+
+	close(target.release)
+	<-written
+	if err := sink.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+}
+
+func TestBufferedSink_ReportsErrorsOfTargetSink(t *testing.T) {
+	var out bytes.Buffer
+	sink := NewBufferedSink(NewJSONSink(&out, 5), 1)
+	var err error
+	for i := 0; i < 100 && err == nil; i++ {
+		err = sink.Write(1000 + i)
+	}
+	if want, got := ErrLimitExceeded, err; !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if want, got := ErrLimitExceeded, sink.Close(); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+// blockingSink accepts entries only after the release channel got closed.
+type blockingSink struct {
+	release  chan struct{}
+	received chan any
+}
+
+func (s *blockingSink) Write(entry any) error {
+	s.received <- entry
+	<-s.release
+	return nil
+}
+
+func (s *blockingSink) Close() error {
+	return nil
+}
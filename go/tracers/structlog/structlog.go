@@ -0,0 +1,100 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+// Package structlog provides a tosca.Tracer logging the state of the
+// execution before each instruction, like geth's struct logger. Since logs of
+// large transactions may contain millions of entries, they are not collected
+// in memory but streamed to a sink.
+package structlog
+
+import (
+	"encoding/hex"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tracers/sink"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/holiman/uint256"
+)
+
+// Config controls the content of logged entries. Its JSON encoding matches
+// the corresponding options of geth's struct logger.
+type Config struct {
+	EnableMemory bool `json:"enableMemory"` // if true, the memory is logged
+	DisableStack bool `json:"disableStack"` // if true, the stack is not logged
+	Limit        int  `json:"limit"`        // maximum number of entries, unlimited if zero
+}
+
+// Entry describes the state before the execution of an instruction. Its JSON
+// encoding matches the struct logs produced by geth.
+type Entry struct {
+	Pc     uint64         `json:"pc"`
+	Op     string         `json:"op"`
+	Gas    hexutil.Uint64 `json:"gas"`
+	Depth  int            `json:"depth"` // starting at 1 for the top-level call
+	Stack  []string       `json:"stack,omitempty"`
+	Memory []string       `json:"memory,omitempty"`
+}
+
+// Tracer is a tosca.Tracer writing an Entry to a sink for each executed
+// instruction. Once the sink reports an error, or the configured limit of
+// entries is reached, the tracer stops logging and retains the error.
+type Tracer struct {
+	tosca.NoOpTracer
+	sink    sink.Sink
+	config  Config
+	entries int
+	err     error
+}
+
+// New creates a tracer writing its entries to the given sink. The sink is not
+// closed by the tracer.
+func New(sink sink.Sink, config Config) *Tracer {
+	return &Tracer{sink: sink, config: config}
+}
+
+func (t *Tracer) OnOpcode(state tosca.OpCodeState) {
+	if t.err != nil {
+		return
+	}
+	if t.config.Limit > 0 && t.entries >= t.config.Limit {
+		t.err = sink.ErrLimitExceeded
+		return
+	}
+	t.entries++
+
+	entry := &Entry{
+		Pc:    uint64(state.Pc),
+		Op:    state.OpCode.String(),
+		Gas:   hexutil.Uint64(state.Gas),
+		Depth: state.Depth + 1,
+	}
+	// Entries are retained by sinks, thus the state is copied.
+	if !t.config.DisableStack {
+		entry.Stack = make([]string, len(state.Stack))
+		for i, word := range state.Stack {
+			entry.Stack[i] = new(uint256.Int).SetBytes32(word[:]).Hex()
+		}
+	}
+	if t.config.EnableMemory {
+		entry.Memory = make([]string, 0, (len(state.Memory)+31)/32)
+		for i := 0; i < len(state.Memory); i += 32 {
+			var word [32]byte
+			copy(word[:], state.Memory[i:])
+			entry.Memory = append(entry.Memory, hex.EncodeToString(word[:]))
+		}
+	}
+	t.err = t.sink.Write(entry)
+}
+
+// Err returns the error that stopped the tracer, or nil if all entries have
+// been logged.
+func (t *Tracer) Err() error {
+	return t.err
+}
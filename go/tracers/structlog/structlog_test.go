@@ -0,0 +1,106 @@
+// Copyright (c) 2024 Fantom Foundation
+//
+// Use of this software is governed by the Business Source License included
+// in the LICENSE file and at fantom.foundation/bsl11.
+//
+// Change Date: 2028-4-16
+//
+// On the date above, in accordance with the Business Source License, use of
+// this software will be governed by the GNU Lesser General Public License v3.
+
+package structlog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/Fantom-foundation/Tosca/go/tosca"
+	"github.com/Fantom-foundation/Tosca/go/tosca/vm"
+	"github.com/Fantom-foundation/Tosca/go/tracers/sink"
+)
+
+func TestTracer_EntriesAreWrittenToSink(t *testing.T) {
+	tests := map[string]struct {
+		config Config
+		want   string
+	}{
+		"default": {
+			config: Config{},
+			want: `[{"pc":0,"op":"PUSH1","gas":"0x64","depth":1},` +
+				`{"pc":2,"op":"MSTORE","gas":"0x61","depth":1,"stack":["0x2a","0x0"]}]`,
+		},
+		"with memory": {
+			config: Config{EnableMemory: true},
+			want: `[{"pc":0,"op":"PUSH1","gas":"0x64","depth":1},` +
+				`{"pc":2,"op":"MSTORE","gas":"0x61","depth":1,"stack":["0x2a","0x0"],` +
+				`"memory":["0000000000000000000000000000000000000000000000000000000000000001",` +
+				`"0200000000000000000000000000000000000000000000000000000000000000"]}]`,
+		},
+		"without stack": {
+			config: Config{DisableStack: true},
+			want: `[{"pc":0,"op":"PUSH1","gas":"0x64","depth":1},` +
+				`{"pc":2,"op":"MSTORE","gas":"0x61","depth":1}]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			tracer := New(sink.NewJSONSink(&out, 0), test.config)
+
+			memory := make([]byte, 33)
+			memory[31], memory[32] = 1, 2
+			tracer.OnOpcode(tosca.OpCodeState{Pc: 0, OpCode: vm.PUSH1, Gas: 100})
+			tracer.OnOpcode(tosca.OpCodeState{
+				Pc:     2,
+				OpCode: vm.MSTORE,
+				Gas:    97,
+				Stack:  []tosca.Word{{31: 42}, {}},
+				Memory: memory,
+			})
+
+			if err := tracer.Err(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := tracer.sink.Close(); err != nil {
+				t.Fatalf("failed to close sink: %v", err)
+			}
+			if want, got := test.want, out.String(); want != got {
+				t.Errorf("unexpected trace,\nwanted %s\n   got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestTracer_LoggingStopsAtLimit(t *testing.T) {
+	var out bytes.Buffer
+	tracer := New(sink.NewJSONSink(&out, 0), Config{Limit: 2})
+	for i := 0; i < 5; i++ {
+		tracer.OnOpcode(tosca.OpCodeState{Pc: i, OpCode: vm.JUMPDEST})
+	}
+	if want, got := sink.ErrLimitExceeded, tracer.Err(); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if err := tracer.sink.Close(); err != nil {
+		t.Fatalf("failed to close sink: %v", err)
+	}
+	want := `[{"pc":0,"op":"JUMPDEST","gas":"0x0","depth":1},{"pc":1,"op":"JUMPDEST","gas":"0x0","depth":1}]`
+	if got := out.String(); want != got {
+		t.Errorf("unexpected trace,\nwanted %s\n   got %s", want, got)
+	}
+}
+
+func TestTracer_LoggingStopsOnSinkError(t *testing.T) {
+	var out bytes.Buffer
+	tracer := New(sink.NewJSONSink(&out, 64), Config{})
+	for i := 0; i < 5; i++ {
+		tracer.OnOpcode(tosca.OpCodeState{Pc: i, OpCode: vm.JUMPDEST})
+	}
+	if want, got := sink.ErrLimitExceeded, tracer.Err(); !errors.Is(got, want) {
+		t.Errorf("unexpected error, wanted %v, got %v", want, got)
+	}
+	if want := `[{"pc":0,"op":"JUMPDEST","gas":"0x0","depth":1}]`; want != out.String() {
+		t.Errorf("unexpected trace,\nwanted %s\n   got %s", want, out.String())
+	}
+}